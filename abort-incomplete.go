@@ -0,0 +1,72 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// abortAttempts - attempts to abort an incomplete upload failing with a retryable error.
+const abortAttempts = 3
+
+var (
+	// abortTimeout - longest wait for a single attempt, exit is never held up by an unresponsive server.
+	abortTimeout = 30 * time.Second
+	// abortRetryDelay - delay before the second attempt, doubled for every further attempt.
+	abortRetryDelay = time.Second
+)
+
+// abortIncompleteUpload - remove the incomplete upload a failed multipart upload to targetClnt
+// left behind. Returns err, which reports whether the incomplete upload was removed.
+func abortIncompleteUpload(targetClnt client.Client, err *probe.Error) *probe.Error {
+	incomplete, ok := err.ToGoError().(client.IncompleteUpload)
+	if !ok {
+		return err
+	}
+	delay := abortRetryDelay
+	for attempt := 1; ; attempt++ {
+		abortErr := removeIncompleteUpload(targetClnt)
+		if abortErr == nil {
+			incomplete.Aborted = true
+			break
+		}
+		if attempt >= abortAttempts || !isRetryable(abortErr) {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	err.Cause = incomplete
+	return err
+}
+
+// removeIncompleteUpload - a single attempt to remove the incomplete upload, given up after abortTimeout.
+func removeIncompleteUpload(targetClnt client.Client) *probe.Error {
+	errCh := make(chan *probe.Error, 1)
+	go func() {
+		errCh <- targetClnt.Remove(true, "")
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(abortTimeout):
+		return probe.NewError(client.Timeout{Op: "Remove", URL: targetClnt.GetURL().String()})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+// failingPartsHandler is an http.Handler that starts multipart uploads of /bucket/object and
+// rejects their parts, counting aborted uploads.
+type failingPartsHandler struct {
+	mutex     sync.Mutex
+	initiated bool
+	aborted   int
+}
+
+func (h *failingPartsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	query := r.URL.Query()
+	_, isUploads := query["uploads"]
+	var response bytes.Buffer
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/bucket" && isUploads:
+		response.WriteString("<ListMultipartUploadsResult><Bucket>bucket</Bucket>")
+		if h.initiated {
+			response.WriteString("<Upload><Key>object</Key><UploadId>upload-1</UploadId></Upload>")
+		}
+		response.WriteString("<IsTruncated>false</IsTruncated></ListMultipartUploadsResult>")
+	case r.Method == "POST" && r.URL.Path == "/bucket/object" && isUploads:
+		h.initiated = true
+		response.WriteString("<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key>" +
+			"<UploadId>upload-1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == "PUT" && query.Get("partNumber") != "":
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("<Error><Code>InvalidRequest</Code><Message>Part rejected.</Message></Error>"))
+		return
+	case r.Method == "DELETE" && query.Get("uploadId") == "upload-1":
+		h.initiated = false
+		h.aborted++
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Write(response.Bytes())
+}
+
+func (s *TestSuite) TestCopyAbortIncomplete(c *C) {
+	defer useTempMcConfig(c)()
+	handler := &failingPartsHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("abort", hostConfigV7{URL: server.URL, API: "S3v4"}), IsNil)
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-abort-incomplete-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	// Large enough to be uploaded in parts.
+	source := filepath.Join(root, "object")
+	c.Assert(ioutil.WriteFile(source, bytes.Repeat([]byte("a"), 6*1024*1024), 0600), IsNil)
+
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+
+	for _, isNoAbort := range []bool{false, true} {
+		handler.aborted = 0
+		session := newTestSession()
+		session.Header.CommandBoolFlags = map[string]bool{"overwrite": true, "no-abort-incomplete": isNoAbort}
+		cpURLs := copyURLs{
+			SourceContent: &client.Content{URL: *client.NewURL(source), Size: 6 * 1024 * 1024},
+			TargetAlias:   "abort",
+			TargetContent: &client.Content{URL: *client.NewURL(server.URL + "/bucket/object")},
+		}
+		cpQueue := make(chan bool, 1)
+		cpQueue <- true
+		statusCh := make(chan copyURLs, 1)
+		wg := new(sync.WaitGroup)
+		wg.Add(1)
+		doCopy(context.Background(), cpURLs, session, nil, nil, nil, cpQueue, wg, statusCh)
+
+		status := <-statusCh
+		c.Assert(status.Error, Not(IsNil))
+		incomplete, ok := status.Error.ToGoError().(client.IncompleteUpload)
+		c.Assert(ok, Equals, true, Commentf("%s", status.Error))
+		if isNoAbort {
+			// Upload is left to be resumed, the error tells how to remove it.
+			c.Assert(handler.aborted, Equals, 0)
+			c.Assert(incomplete.Aborted, Equals, false)
+			c.Assert(strings.Contains(incomplete.Error(), "mc rm --incomplete"), Equals, true)
+		} else {
+			c.Assert(handler.aborted, Equals, 1)
+			c.Assert(incomplete.Aborted, Equals, true)
+		}
+	}
+}
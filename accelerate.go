@@ -0,0 +1,60 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-go"
+)
+
+// isAmazonS3Host - host of Amazon S3, the only one offering Transfer Acceleration.
+func isAmazonS3Host(host string) bool {
+	s3Path, _ := filepath.Match("s3*.amazonaws.com", host)
+	s3Virtual, _ := filepath.Match("*.s3*.amazonaws.com", host)
+	return s3Path || s3Virtual
+}
+
+// warnedNotAccelerated - buckets warned about not being accelerated, each is warned about once.
+var warnedNotAccelerated = struct {
+	sync.Mutex
+	buckets map[string]bool
+}{buckets: make(map[string]bool)}
+
+// checkAccelerateBucket - warn once objects of the bucket of urlStr are transferred without
+// Transfer Acceleration, which is not available to buckets with dots in their name.
+func checkAccelerateBucket(urlStr string) {
+	bucket, _ := url2BucketAndPrefix(client.NewURL(urlStr).Path)
+	if bucket == "" || minio.IsAccelerateBucket(bucket) {
+		return
+	}
+	warnedNotAccelerated.Lock()
+	defer warnedNotAccelerated.Unlock()
+	if warnedNotAccelerated.buckets[bucket] {
+		return
+	}
+	warnedNotAccelerated.buckets[bucket] = true
+	err := errNotAccelerated(bucket)
+	if globalJSON {
+		printErrorJSON(err, "Objects are transferred without acceleration.", "warning", exitFailure)
+		return
+	}
+	console.Errorln(err.ToGoError().Error() + " Objects are transferred without acceleration.")
+}
@@ -179,6 +179,9 @@ func mainAccess(ctx *cli.Context) {
 					"Unable to set access permission ‘"+string(perms)+"’ for ‘"+targetURL+"’.")
 				continue
 			}
+			if globalDryRun { // Already printed by the dry run client.
+				continue
+			}
 			printMsg(accessMessage{
 				Status:    "success",
 				Operation: "set",
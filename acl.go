@@ -0,0 +1,83 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// checkACLSyntax - a copy either gets the canned ACL of ‘--acl’ or the ACL of its source.
+func checkACLSyntax(ctx *cli.Context) {
+	acl := ctx.String("acl")
+	if acl != "" && !minio.IsValidObjectCannedACL(acl) {
+		fatalIf(errInvalidArgument().Trace(acl), "Canned ACL ‘"+acl+"’ is invalid, must be one of ‘"+strings.Join(minio.ObjectCannedACLs, "’, ‘")+"’.")
+	}
+	if acl != "" && ctx.Bool("preserve-acl") {
+		fatalIf(errInvalidArgument().Trace(), "Option --acl cannot be used with --preserve-acl.")
+	}
+}
+
+// applyObjectACL - give the copy of targetClnt the ACL of its source with ‘--preserve-acl’. Object storage
+// gets the canned ACL of ‘--acl’ with the upload itself, a local file its permissions here. ACLs
+// either side cannot express are left alone.
+func applyObjectACL(session *sessionV6, sourceClnt, targetClnt client.Client) *probe.Error {
+	var acl client.ACL
+	switch {
+	case session.Header.CommandBoolFlags["preserve-acl"]:
+		var err *probe.Error
+		if acl, err = sourceClnt.GetObjectACL(); err != nil {
+			if _, ok := err.ToGoError().(client.APINotImplemented); ok {
+				return nil
+			}
+			return err.Trace(sourceClnt.GetURL().String())
+		}
+	case session.Header.CommandStringFlags["acl"] != "" && targetClnt.GetURL().Type == client.Filesystem:
+		acl.Canned = session.Header.CommandStringFlags["acl"]
+	default:
+		return nil
+	}
+	if err := targetClnt.SetObjectACL(acl); err != nil {
+		if _, ok := err.ToGoError().(client.APINotImplemented); ok {
+			return nil
+		}
+		return err.Trace(targetClnt.GetURL().String())
+	}
+	return nil
+}
+
+// applyMirroredACL - applyObjectACL to the copy of sURLs.
+func applyMirroredACL(session *sessionV6, sURLs mirrorURLs) *probe.Error {
+	// Without an ACL to apply no clients are needed.
+	acl := session.Header.CommandStringFlags["acl"]
+	if !session.Header.CommandBoolFlags["preserve-acl"] && (acl == "" || sURLs.TargetContent.URL.Type != client.Filesystem) {
+		return nil
+	}
+	sourceClnt, err := newClientFromAlias(sURLs.SourceAlias, sURLs.SourceContent.URL.String())
+	if err != nil {
+		return err.Trace(sURLs.SourceContent.URL.String())
+	}
+	targetClnt, err := newClientFromAlias(sURLs.TargetAlias, sURLs.TargetContent.URL.String())
+	if err != nil {
+		return err.Trace(sURLs.TargetContent.URL.String())
+	}
+	return applyObjectACL(session, sourceClnt, targetClnt).Trace()
+}
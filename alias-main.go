@@ -0,0 +1,404 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	aliasFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of alias.",
+		},
+		cli.StringFlag{
+			Name:  "api",
+			Value: "S3v4",
+			Usage: "API signature of the host. Valid options are [S3v4, S3v2, auto].",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "Signing region of the host, looked up automatically if not set.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-multipart",
+			Usage: "Upload objects of any size with a single PUT, for hosts without multipart upload support.",
+		},
+		cli.BoolFlag{
+			Name:  "accelerate",
+			Usage: "Transfer objects through Amazon S3 Transfer Acceleration, enabled on the bucket.",
+		},
+		cli.BoolFlag{
+			Name:  "skip-check",
+			Usage: "Skip verifying connectivity to the host while setting an alias.",
+		},
+		cli.BoolFlag{
+			Name:  "write-test",
+			Usage: "Test writing to the bucket with a throwaway object while testing an alias.",
+		},
+		cli.BoolFlag{
+			Name:  "show-secret",
+			Usage: "Show secret keys while listing aliases, only allowed with ‘--json’.",
+		},
+	}
+)
+
+// Manage host aliases.
+var aliasCmd = cli.Command{
+	Name:   "alias",
+	Usage:  "Set, list and remove host aliases.",
+	Action: mainAlias,
+	Flags:  append(aliasFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] set ALIAS URL ACCESS-KEY SECRET-KEY
+   mc {{.Name}} [FLAGS] list
+   mc {{.Name}} [FLAGS] remove ALIAS
+   mc {{.Name}} [FLAGS] test ALIAS[/BUCKET]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Set Amazon S3 storage service under "myphotos" alias. For security reasons turn off bash history momentarily.
+      $ set +o history
+      $ mc {{.Name}} set myphotos https://s3.amazonaws.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+      $ set -o history
+
+   2. Set Google Cloud Storage service under "goodisk" alias, without verifying connectivity.
+      $ mc {{.Name}} set --api S3v2 --skip-check goodisk https://storage.googleapis.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   3. List all aliases, secret keys are masked.
+      $ mc {{.Name}} list
+
+   4. List all aliases along with their secret keys as JSON.
+      $ mc --json {{.Name}} list --show-secret
+
+   5. Remove "goodisk" alias.
+      $ mc {{.Name}} remove goodisk
+
+   6. Set a gateway serving S3 under the path "/s3" in region "eu-west-1" under "gw" alias.
+      $ mc {{.Name}} set --region eu-west-1 gw https://gw.example.com/s3 BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   7. Set a gateway without multipart upload support under "legacy" alias.
+      $ mc {{.Name}} set --disable-multipart legacy https://legacy.example.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   8. Set a host of unknown signature version under "ceph" alias. The signature version the host accepts is
+      negotiated on first use and recorded in the alias.
+      $ mc {{.Name}} set --api auto ceph https://ceph.example.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   9. Set Amazon S3 cloud storage in region "eu-west-1" under "fast" alias, transferring objects through Transfer
+      Acceleration. Acceleration has to be enabled on the bucket.
+      $ mc {{.Name}} set --accelerate --region eu-west-1 fast https://s3.amazonaws.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   10. Troubleshoot "myphotos" alias step by step, from DNS and TLS to clock skew, signature, region and
+       permissions to list, read and write bucket "vacation".
+      $ mc {{.Name}} --write-test test myphotos/vacation
+`,
+}
+
+// aliasMessage container for alias messages.
+type aliasMessage struct {
+	op        string
+	Status    string `json:"status"`
+	Alias     string `json:"alias"`
+	URL       string `json:"URL,omitempty"`
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+	API       string `json:"api,omitempty"`
+	Region    string `json:"region,omitempty"`
+
+	DisableMultipart bool `json:"disableMultipart,omitempty"`
+	Accelerate       bool `json:"accelerate,omitempty"`
+}
+
+// String colorized alias message.
+func (a aliasMessage) String() string {
+	switch a.op {
+	case "list":
+		message := console.Colorize("Alias", fmt.Sprintf("%s: ", a.Alias))
+		message += console.Colorize("URL", a.URL)
+		if a.AccessKey != "" || a.SecretKey != "" {
+			message += " | " + console.Colorize("AccessKey", a.AccessKey)
+			message += " | " + console.Colorize("SecretKey", a.SecretKey)
+		}
+		message += " | " + console.Colorize("API", a.API)
+		if a.Region != "" {
+			message += " | " + console.Colorize("Region", a.Region)
+		}
+		if a.DisableMultipart {
+			message += " | " + console.Colorize("API", "single PUT")
+		}
+		if a.Accelerate {
+			message += " | " + console.Colorize("API", "accelerated")
+		}
+		return message
+	case "set":
+		return console.Colorize("AliasMessage", "Added ‘"+a.Alias+"’ successfully.")
+	case "remove":
+		return console.Colorize("AliasMessage", "Removed ‘"+a.Alias+"’ successfully.")
+	}
+	return ""
+}
+
+// JSON jsonified alias message.
+func (a aliasMessage) JSON() string {
+	a.Status = "success"
+	aliasMessageBytes, e := json.Marshal(a)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(aliasMessageBytes)
+}
+
+// apiSignatureAuto - signature of hosts whose signature version is negotiated on first use.
+const apiSignatureAuto = "auto"
+
+// normalizeAPISignature - maps user input such as ‘s3v4’ to the signature names used in config.
+func normalizeAPISignature(api string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(api)) {
+	case "s3v4", "":
+		return "S3v4", true
+	case "s3v2":
+		return "S3v2", true
+	case apiSignatureAuto:
+		return apiSignatureAuto, true
+	}
+	return "", false
+}
+
+// maskSecret - mask a secret key for display.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return strings.Repeat("*", 8)
+}
+
+// checkAliasSyntax - validate all the passed arguments.
+func checkAliasSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "alias", 1) // last argument is exit code
+	}
+	tailArgs := ctx.Args().Tail()
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "set":
+		if len(tailArgs) != 4 {
+			fatalIf(errInvalidArgument().Trace(tailArgs...), "Incorrect number of arguments for alias set command.")
+		}
+		alias, url, accessKey, secretKey := tailArgs.Get(0), tailArgs.Get(1), tailArgs.Get(2), tailArgs.Get(3)
+		if !isValidAlias(alias) {
+			fatalIf(errDummy().Trace(alias), "Invalid alias ‘"+alias+"’.")
+		}
+		if !isValidHostURL(url) {
+			fatalIf(errDummy().Trace(url), "Invalid URL ‘"+url+"’.")
+		}
+		if !isValidAccessKey(accessKey) {
+			fatalIf(errInvalidArgument().Trace(accessKey), "Invalid access key ‘"+accessKey+"’.")
+		}
+		if !isValidSecretKey(secretKey) {
+			fatalIf(errInvalidArgument().Trace(), "Invalid secret key.")
+		}
+		if _, ok := normalizeAPISignature(ctx.String("api")); !ok {
+			fatalIf(errInvalidArgument().Trace(ctx.String("api")),
+				"Unrecognized API signature. Valid options are ‘[ S3v4, S3v2, auto ]’.")
+		}
+		if ctx.Bool("accelerate") && !isAmazonS3Host(client.NewURL(url).Host) {
+			fatalIf(errInvalidArgument().Trace(url), "Option --accelerate is only available for Amazon S3.")
+		}
+	case "list":
+		if len(tailArgs) != 0 {
+			fatalIf(errInvalidArgument().Trace(tailArgs...), "Incorrect number of arguments for alias list command.")
+		}
+		if ctx.Bool("show-secret") && !globalJSON {
+			fatalIf(errInvalidArgument().Trace(), "‘--show-secret’ is only allowed with ‘--json’.")
+		}
+	case "remove":
+		if len(tailArgs) != 1 {
+			fatalIf(errInvalidArgument().Trace(tailArgs...), "Incorrect number of arguments for alias remove command.")
+		}
+		if !isValidAlias(tailArgs.Get(0)) {
+			fatalIf(errDummy().Trace(tailArgs.Get(0)), "Invalid alias ‘"+tailArgs.Get(0)+"’.")
+		}
+	case "test":
+		if len(tailArgs) != 1 {
+			fatalIf(errInvalidArgument().Trace(tailArgs...), "Incorrect number of arguments for alias test command.")
+		}
+		if alias, _ := splitAliasBucket(tailArgs.Get(0)); !isValidAlias(alias) {
+			fatalIf(errDummy().Trace(tailArgs.Get(0)), "Invalid alias ‘"+alias+"’.")
+		}
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "alias", 1) // last argument is exit code
+	}
+}
+
+// checkAliasConnectivity - verify host is reachable with given credentials by listing buckets.
+func checkAliasConnectivity(hostCfg hostConfigV7) *probe.Error {
+	clnt, err := newS3ClientFromHost(hostCfg, hostCfg.URL, nil)
+	if err != nil {
+		return err.Trace(hostCfg.URL)
+	}
+	if _, err = clnt.Stat(); err != nil {
+		// Anonymous hosts usually do not allow listing buckets, server replying is good enough.
+		if _, ok := err.ToGoError().(net.Error); ok || hostCfg.AccessKey != "" || hostCfg.SecretKey != "" {
+			return err.Trace(hostCfg.URL)
+		}
+	}
+	return nil
+}
+
+// setAlias - add or replace an alias in config.
+func setAlias(alias string, hostCfg hostConfigV7) *probe.Error {
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		return err.Trace(alias)
+	}
+	mcCfg.Hosts[alias] = hostCfg
+	return saveMcConfig(mcCfg).Trace(alias)
+}
+
+// removeAlias - remove an alias from config.
+func removeAlias(alias string) *probe.Error {
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		return err.Trace(alias)
+	}
+	if _, ok := mcCfg.Hosts[alias]; !ok {
+		return errNoMatchingHost(alias).Trace(alias)
+	}
+	delete(mcCfg.Hosts, alias)
+	return saveMcConfig(mcCfg).Trace(alias)
+}
+
+// listAliases - list all aliases sorted by name, secret keys are masked unless showSecret is set.
+func listAliases(showSecret bool) ([]aliasMessage, *probe.Error) {
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	var aliases []string
+	for alias := range mcCfg.Hosts {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var msgs []aliasMessage
+	for _, alias := range aliases {
+		hostCfg := mustDecryptHostConfig(mcCfg, mcCfg.Hosts[alias])
+		secretKey := hostCfg.SecretKey
+		if !showSecret {
+			secretKey = maskSecret(secretKey)
+		}
+		msgs = append(msgs, aliasMessage{
+			op:        "list",
+			Alias:     alias,
+			URL:       hostCfg.URL,
+			AccessKey: hostCfg.AccessKey,
+			SecretKey: secretKey,
+			API:       hostCfg.API,
+			Region:    hostCfg.Region,
+
+			DisableMultipart: hostCfg.DisableMultipart,
+			Accelerate:       hostCfg.Accelerate,
+		})
+	}
+	return msgs, nil
+}
+
+// mainAlias - main handler for mc alias command.
+func mainAlias(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'alias' cli arguments.
+	checkAliasSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("AliasMessage", color.New(color.FgGreen))
+	console.SetColor("Alias", color.New(color.FgCyan, color.Bold))
+	console.SetColor("URL", color.New(color.FgCyan))
+	console.SetColor("AccessKey", color.New(color.FgBlue))
+	console.SetColor("SecretKey", color.New(color.FgBlue))
+	console.SetColor("API", color.New(color.FgYellow))
+	console.SetColor("Region", color.New(color.FgYellow))
+
+	args := ctx.Args().Tail()
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "set":
+		alias := args.Get(0)
+		api, _ := normalizeAPISignature(ctx.String("api"))
+		hostCfg := hostConfigV7{
+			URL:       args.Get(1),
+			AccessKey: args.Get(2),
+			SecretKey: args.Get(3),
+			API:       api,
+			Region:    ctx.String("region"),
+
+			DisableMultipart: ctx.Bool("disable-multipart"),
+			Accelerate:       ctx.Bool("accelerate"),
+		}
+		if !ctx.Bool("skip-check") {
+			fatalIf(checkAliasConnectivity(hostCfg).Trace(alias),
+				"Unable to verify connectivity to ‘"+hostCfg.URL+"’. Use ‘--skip-check’ to override this behavior.")
+		}
+		fatalIf(setAlias(alias, hostCfg).Trace(alias), "Unable to set alias ‘"+alias+"’ in config ‘"+mustGetMcConfigPath()+"’.")
+		printMsg(aliasMessage{op: "set", Alias: alias, URL: hostCfg.URL, API: hostCfg.API})
+	case "list":
+		msgs, err := listAliases(globalJSON && ctx.Bool("show-secret"))
+		fatalIf(err.Trace(), "Unable to list aliases in config ‘"+mustGetMcConfigPath()+"’.")
+		for _, msg := range msgs {
+			printMsg(msg)
+		}
+	case "remove":
+		alias := args.Get(0)
+		fatalIf(removeAlias(alias).Trace(alias), "Unable to remove alias ‘"+alias+"’ from config ‘"+mustGetMcConfigPath()+"’.")
+		printMsg(aliasMessage{op: "remove", Alias: alias})
+	case "test":
+		console.SetColor("AliasTestPass", color.New(color.FgGreen, color.Bold))
+		console.SetColor("AliasTestWarn", color.New(color.FgYellow, color.Bold))
+		console.SetColor("AliasTestFail", color.New(color.FgRed, color.Bold))
+		console.SetColor("AliasTestSkip", color.New(color.FgWhite))
+		console.SetColor("AliasTestHint", color.New(color.FgCyan))
+		alias, bucket := splitAliasBucket(args.Get(0))
+		hostCfg := mustGetHostConfig(alias)
+		if hostCfg == nil {
+			fatalIf(errNoMatchingHost(alias).Trace(alias), "Unable to find alias ‘"+alias+"’.")
+		}
+		failed := 0
+		for _, msg := range testAlias(alias, *hostCfg, bucket, ctx.Bool("write-test")) {
+			printMsg(msg)
+			if msg.Result == aliasTestFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			fatalIf(errAliasTestFailed(alias, failed).Trace(alias), "Alias ‘"+alias+"’ is not working.")
+		}
+	}
+}
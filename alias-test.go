@@ -0,0 +1,425 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Results of a check of ‘mc alias test’.
+const (
+	aliasTestPass = "pass"
+	aliasTestWarn = "warn"
+	aliasTestFail = "fail"
+	aliasTestSkip = "skip"
+)
+
+const (
+	// aliasTestTimeout - time each network check may take.
+	aliasTestTimeout = 10 * time.Second
+	// certExpiryWarning - certificates expiring sooner are warned about.
+	certExpiryWarning = 30 * 24 * time.Hour
+	// clockSkewWarning - clocks further off are warned about, requests are corrected for it.
+	clockSkewWarning = time.Minute
+	// clockSkewFailure - clocks further off fail, S3 rejects presigned URLs this far off.
+	clockSkewFailure = 15 * time.Minute
+)
+
+// aliasTestMessage container for the result of a check of an alias.
+type aliasTestMessage struct {
+	Status string `json:"status"`
+	Alias  string `json:"alias"`
+	Check  string `json:"check"`
+	Result string `json:"result"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// aliasTestColors - theme of each result of a check.
+var aliasTestColors = map[string]string{
+	aliasTestPass: "AliasTestPass",
+	aliasTestWarn: "AliasTestWarn",
+	aliasTestFail: "AliasTestFail",
+	aliasTestSkip: "AliasTestSkip",
+}
+
+// String colorized result of a check.
+func (a aliasTestMessage) String() string {
+	message := console.Colorize(aliasTestColors[a.Result], fmt.Sprintf("%-4s", strings.ToUpper(a.Result)))
+	message += fmt.Sprintf(" %-9s %s", a.Check, a.Detail)
+	if a.Hint != "" {
+		message += "\n     " + console.Colorize("AliasTestHint", "hint: "+a.Hint)
+	}
+	return message
+}
+
+// JSON jsonified result of a check.
+func (a aliasTestMessage) JSON() string {
+	a.Status = "success"
+	jsonMessageBytes, e := json.Marshal(a)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// splitAliasBucket - alias and bucket of ‘ALIAS[/BUCKET]’.
+func splitAliasBucket(arg string) (alias, bucket string) {
+	parts := strings.SplitN(strings.Trim(arg, "/"), "/", 2)
+	if len(parts) == 2 {
+		return parts[0], strings.SplitN(parts[1], "/", 2)[0]
+	}
+	return parts[0], ""
+}
+
+// aliasTester runs the checks of an alias in order, checks depending on a failed one are skipped.
+type aliasTester struct {
+	alias   string
+	hostCfg hostConfigV7
+	results []aliasTestMessage
+	failed  string // first failed check, later checks depending on it are skipped.
+}
+
+// report - record the result of check.
+func (t *aliasTester) report(check, result, detail, hint string) {
+	t.results = append(t.results, aliasTestMessage{Alias: t.alias, Check: check, Result: result, Detail: detail, Hint: hint})
+	if result == aliasTestFail && t.failed == "" {
+		t.failed = check
+	}
+}
+
+// skipped - skip check if an earlier check failed, reporting it.
+func (t *aliasTester) skipped(check string) bool {
+	if t.failed == "" {
+		return false
+	}
+	t.report(check, aliasTestSkip, "Skipped, check ‘"+t.failed+"’ failed.", "")
+	return true
+}
+
+// testAlias - check the host of alias step by step, from resolving its name to writing an object with
+// isWriteTest. Bucket checks use bucket, or the first bucket listed if empty.
+func testAlias(alias string, hostCfg hostConfigV7, bucket string, isWriteTest bool) []aliasTestMessage {
+	t := &aliasTester{alias: alias, hostCfg: hostCfg}
+	hostURL, e := url.Parse(hostCfg.URL)
+	if e != nil || hostURL.Host == "" {
+		t.report("dns", aliasTestFail, "Invalid URL ‘"+hostCfg.URL+"’.", "Set the alias again with a URL such as https://s3.amazonaws.com.")
+		return t.results
+	}
+	host, port := hostURL.Hostname(), hostURL.Port()
+	if port == "" {
+		port = "80"
+		if hostURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	t.testDNS(host)
+	t.testTCP(net.JoinHostPort(host, port))
+	if hostURL.Scheme == "https" {
+		t.testTLS(net.JoinHostPort(host, port), host)
+	} else {
+		t.report("tls", aliasTestWarn, "Host is not served over TLS, objects are transferred unencrypted.", "Use an https URL if the host offers one.")
+	}
+	t.testClock()
+	clnt := t.testSignature()
+	bucket = t.testRegion(clnt, bucket)
+	object := t.testList(bucket)
+	t.testRead(bucket, object)
+	t.testWrite(bucket, isWriteTest)
+	return t.results
+}
+
+func (t *aliasTester) testDNS(host string) {
+	if net.ParseIP(host) != nil {
+		t.report("dns", aliasTestPass, "Host is an IP address, nothing to resolve.", "")
+		return
+	}
+	addrs, e := net.LookupHost(host)
+	if e != nil {
+		t.report("dns", aliasTestFail, "Unable to resolve ‘"+host+"’: "+e.Error(), "Check the host name of the alias and the DNS servers of this machine.")
+		return
+	}
+	t.report("dns", aliasTestPass, "Resolved ‘"+host+"’ to "+strings.Join(addrs, ", ")+".", "")
+}
+
+func (t *aliasTester) testTCP(addr string) {
+	if t.skipped("tcp") {
+		return
+	}
+	start := time.Now()
+	conn, e := net.DialTimeout("tcp", addr, aliasTestTimeout)
+	if e != nil {
+		t.report("tcp", aliasTestFail, "Unable to connect to ‘"+addr+"’: "+e.Error(), "Check the port of the alias, and that no firewall or proxy is in the way.")
+		return
+	}
+	conn.Close()
+	t.report("tcp", aliasTestPass, fmt.Sprintf("Connected to ‘%s’ in %s.", addr, time.Since(start).Round(time.Millisecond)), "")
+}
+
+func (t *aliasTester) testTLS(addr, host string) {
+	if t.skipped("tls") {
+		return
+	}
+	conn, e := tls.DialWithDialer(&net.Dialer{Timeout: aliasTestTimeout}, "tcp", addr, &tls.Config{ServerName: host})
+	if e != nil {
+		t.report("tls", aliasTestFail, "TLS handshake with ‘"+addr+"’ failed: "+e.Error(),
+			"The certificate of the host must be valid for ‘"+host+"’ and issued by an authority this machine trusts.")
+		return
+	}
+	defer conn.Close()
+	notAfter := conn.ConnectionState().PeerCertificates[0].NotAfter
+	detail := "Certificate is valid until " + notAfter.UTC().Format(time.RFC1123) + "."
+	if time.Until(notAfter) < certExpiryWarning {
+		t.report("tls", aliasTestWarn, detail, "Renew the certificate of the host before it expires.")
+		return
+	}
+	t.report("tls", aliasTestPass, detail, "")
+}
+
+// testClock - local clock against the Date of a response of the host, any response will do.
+func (t *aliasTester) testClock() {
+	if t.skipped("clock") {
+		return
+	}
+	httpClient := &http.Client{Timeout: aliasTestTimeout}
+	start := time.Now()
+	resp, e := httpClient.Get(t.hostCfg.URL)
+	if e != nil {
+		t.report("clock", aliasTestFail, "Unable to reach ‘"+t.hostCfg.URL+"’: "+e.Error(), "")
+		return
+	}
+	resp.Body.Close()
+	serverTime, e := http.ParseTime(resp.Header.Get("Date"))
+	if e != nil {
+		t.report("clock", aliasTestWarn, "Host sends no Date, clock skew is unknown.", "")
+		return
+	}
+	// The Date is truncated to seconds, skew within a second is no skew.
+	skew := start.Add(time.Since(start) / 2).Sub(serverTime).Round(time.Second)
+	if skew > -time.Second && skew < time.Second {
+		skew = 0
+	}
+	detail := fmt.Sprintf("Local clock is off by %s from the clock of the host.", skew)
+	switch {
+	case skew > clockSkewFailure || skew < -clockSkewFailure:
+		t.report("clock", aliasTestFail, detail, "Synchronize the local clock with NTP, presigned URLs of ‘mc share’ are rejected this far off.")
+	case skew > clockSkewWarning || skew < -clockSkewWarning:
+		t.report("clock", aliasTestWarn, detail, "Synchronize the local clock with NTP, requests are corrected for the skew meanwhile.")
+	default:
+		t.report("clock", aliasTestPass, detail, "")
+	}
+}
+
+// testSignature - probe which signature versions the host accepts, the client of the alias if its
+// signature version is accepted.
+func (t *aliasTester) testSignature() client.Client {
+	if t.skipped("signature") {
+		return nil
+	}
+	if t.hostCfg.AccessKey == "" && t.hostCfg.SecretKey == "" {
+		t.report("signature", aliasTestSkip, "Alias has no credentials, requests are not signed.", "")
+		clnt, err := newS3ClientFromHost(t.hostCfg, t.hostCfg.URL, nil)
+		if err != nil {
+			t.report("signature", aliasTestFail, err.ToGoError().Error(), "")
+			return nil
+		}
+		return clnt
+	}
+	var accepted []string
+	var lastErr *probe.Error
+	for _, api := range []string{"S3v4", "S3v2"} {
+		hostCfg := t.hostCfg
+		hostCfg.API = api
+		clnt, err := newS3ClientFromHost(hostCfg, hostCfg.URL, nil)
+		if err == nil {
+			_, err = clnt.Stat()
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		accepted = append(accepted, api)
+	}
+	configured := t.hostCfg.API
+	switch {
+	case len(accepted) == 0:
+		hint := ""
+		if exitCode(lastErr) == exitAccessDenied {
+			hint = "Check the access and secret keys of the alias."
+		}
+		t.report("signature", aliasTestFail, "Host accepts neither S3v4 nor S3v2 signatures: "+lastErr.ToGoError().Error(), hint)
+		return nil
+	case configured != apiSignatureAuto && !containsString(accepted, configured):
+		t.report("signature", aliasTestFail, "Host accepts "+strings.Join(accepted, ", ")+", alias signs with "+configured+".",
+			"Set the alias again with ‘--api "+accepted[0]+"’.")
+		return nil
+	}
+	t.report("signature", aliasTestPass, "Host accepts "+strings.Join(accepted, ", ")+".", "")
+	clnt, err := newS3ClientFromHost(t.hostCfg, t.hostCfg.URL, nil)
+	if err != nil {
+		t.report("signature", aliasTestFail, err.ToGoError().Error(), "")
+		return nil
+	}
+	return clnt
+}
+
+// testRegion - requests for bucket are signed for its region, bucket is the first one listed if empty.
+// The bucket tested, empty if there is none.
+func (t *aliasTester) testRegion(hostClnt client.Client, bucket string) string {
+	if t.skipped("region") {
+		return ""
+	}
+	if bucket == "" {
+		doneCh := make(chan struct{})
+		for content := range hostClnt.List(false, false, doneCh) {
+			if content.Err == nil && content.Type.IsDir() {
+				bucket = path.Base(strings.TrimSuffix(content.URL.Path, "/"))
+			}
+			break
+		}
+		close(doneCh)
+		if bucket == "" {
+			t.report("region", aliasTestWarn, "Host has no bucket to test.", "Test a bucket with ‘mc alias test "+t.alias+"/BUCKET’.")
+			return ""
+		}
+	}
+	clnt, err := newS3ClientFromHost(t.hostCfg, urlJoinPath(t.hostCfg.URL, bucket), nil)
+	if err == nil {
+		if verifier, ok := clnt.(interface {
+			VerifyRegion() *probe.Error
+		}); ok {
+			err = verifier.VerifyRegion()
+		}
+	}
+	if err != nil {
+		if mismatch, ok := err.ToGoError().(client.RegionMismatch); ok {
+			t.report("region", aliasTestFail, "Bucket ‘"+bucket+"’ is in region ‘"+mismatch.Region+"’, requests are signed for ‘"+mismatch.SigningRegion+"’.",
+				"Set the alias again with ‘--region "+mismatch.Region+"’.")
+			return bucket
+		}
+		t.report("region", aliasTestWarn, "Unable to verify the region of bucket ‘"+bucket+"’: "+err.ToGoError().Error(), "")
+		return bucket
+	}
+	t.report("region", aliasTestPass, "Requests for bucket ‘"+bucket+"’ are signed for its region.", "")
+	return bucket
+}
+
+// permissionHint - remediation of a failed permission check.
+func permissionHint(err *probe.Error, action string) string {
+	if exitCode(err) == exitAccessDenied {
+		return "The credentials of the alias are not allowed to " + action + ", check the policy of its user."
+	}
+	return ""
+}
+
+// testList - list bucket, the key of the first object listed, empty if there is none.
+func (t *aliasTester) testList(bucket string) string {
+	if t.skipped("list") || bucket == "" {
+		return ""
+	}
+	clnt, err := newS3ClientFromHost(t.hostCfg, urlJoinPath(t.hostCfg.URL, bucket)+"/", nil)
+	if err != nil {
+		t.report("list", aliasTestFail, err.ToGoError().Error(), "")
+		return ""
+	}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for content := range clnt.List(true, false, doneCh) {
+		if content.Err != nil {
+			t.report("list", aliasTestFail, "Unable to list bucket ‘"+bucket+"’: "+content.Err.ToGoError().Error(), permissionHint(content.Err, "list the bucket"))
+			return ""
+		}
+		object := strings.TrimPrefix(content.URL.Path, clnt.GetURL().Path)
+		t.report("list", aliasTestPass, "Listed bucket ‘"+bucket+"’.", "")
+		return object
+	}
+	t.report("list", aliasTestPass, "Listed bucket ‘"+bucket+"’, it is empty.", "")
+	return ""
+}
+
+// testRead - read the first byte of object.
+func (t *aliasTester) testRead(bucket, object string) {
+	if t.skipped("read") || bucket == "" {
+		return
+	}
+	if object == "" {
+		t.report("read", aliasTestSkip, "Bucket ‘"+bucket+"’ has no object to read.", "")
+		return
+	}
+	objectURL := urlJoinPath(urlJoinPath(t.hostCfg.URL, bucket), object)
+	clnt, err := newS3ClientFromHost(t.hostCfg, objectURL, nil)
+	var reader io.ReadSeeker
+	if err == nil {
+		reader, err = clnt.Get(0, 0, "")
+	}
+	if err == nil {
+		if _, e := reader.Read(make([]byte, 1)); e != nil && e != io.EOF {
+			err = probe.NewError(e)
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	if err != nil {
+		t.report("read", aliasTestFail, "Unable to read ‘"+bucket+"/"+object+"’: "+err.ToGoError().Error(), permissionHint(err, "read objects"))
+		return
+	}
+	t.report("read", aliasTestPass, "Read ‘"+bucket+"/"+object+"’.", "")
+}
+
+// testWrite - upload and remove a throwaway object with isWriteTest.
+func (t *aliasTester) testWrite(bucket string, isWriteTest bool) {
+	if t.skipped("write") || bucket == "" {
+		return
+	}
+	if !isWriteTest {
+		t.report("write", aliasTestSkip, "Writing is tested with ‘--write-test’.", "")
+		return
+	}
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	object := ".mc-alias-test-" + hex.EncodeToString(suffix)
+	clnt, err := newS3ClientFromHost(t.hostCfg, urlJoinPath(urlJoinPath(t.hostCfg.URL, bucket), object), nil)
+	if err == nil {
+		data := []byte("mc alias test")
+		err = clnt.Put(bytes.NewReader(data), int64(len(data)), "text/plain", nil)
+	}
+	if err != nil {
+		t.report("write", aliasTestFail, "Unable to write ‘"+bucket+"/"+object+"’: "+err.ToGoError().Error(), permissionHint(err, "write objects"))
+		return
+	}
+	if err = clnt.Remove(false, ""); err != nil {
+		t.report("write", aliasTestWarn, "Wrote ‘"+bucket+"/"+object+"’, unable to remove it: "+err.ToGoError().Error(), permissionHint(err, "remove objects"))
+		return
+	}
+	t.report("write", aliasTestPass, "Wrote and removed ‘"+bucket+"/"+object+"’.", "")
+}
@@ -0,0 +1,194 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	. "gopkg.in/check.v1"
+)
+
+// useTempMcConfig - switch mc config to a fresh config in a temporary folder, returns a function to restore.
+func useTempMcConfig(c *C) func() {
+	tmpDir, e := ioutil.TempDir("", "mc-config-")
+	c.Assert(e, IsNil)
+
+	setMcConfigDir(tmpDir)
+	cacheCfgV7 = nil
+	c.Assert(saveMcConfig(newMcConfig()), IsNil)
+
+	return func() {
+		setMcConfigDir("")
+		cacheCfgV7 = nil
+		loadMcConfig = loadMcConfigFactory()
+		os.RemoveAll(tmpDir)
+	}
+}
+
+func (s *TestSuite) TestAliasRoundTrip(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	hostCfg := hostConfigV7{
+		URL:       "https://s3.amazonaws.com",
+		AccessKey: "BKIKJAA5BMMU2RHO6IBB",
+		SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12",
+		API:       "S3v2",
+		Region:    "eu-west-1",
+	}
+	c.Assert(setAlias("myphotos", hostCfg), IsNil)
+
+	// Alias is persisted and resolves through the same config newClientFromAlias reads.
+	cacheCfgV7 = nil
+	loadMcConfig = loadMcConfigFactory()
+	savedCfg, err := getHostConfig("myphotos")
+	c.Assert(err, IsNil)
+	c.Assert(*savedCfg, DeepEquals, hostCfg)
+
+	msgs, err := listAliases(false)
+	c.Assert(err, IsNil)
+	found := false
+	for _, msg := range msgs {
+		if msg.Alias == "myphotos" {
+			found = true
+			c.Assert(msg.URL, Equals, hostCfg.URL)
+			c.Assert(msg.AccessKey, Equals, hostCfg.AccessKey)
+			c.Assert(msg.SecretKey, Equals, maskSecret(hostCfg.SecretKey))
+			c.Assert(msg.API, Equals, "S3v2")
+			c.Assert(msg.Region, Equals, "eu-west-1")
+		}
+	}
+	c.Assert(found, Equals, true)
+
+	msgs, err = listAliases(true)
+	c.Assert(err, IsNil)
+	for _, msg := range msgs {
+		if msg.Alias == "myphotos" {
+			c.Assert(msg.SecretKey, Equals, hostCfg.SecretKey)
+		}
+	}
+
+	c.Assert(removeAlias("myphotos"), IsNil)
+	_, err = getHostConfig("myphotos")
+	c.Assert(err, Not(IsNil))
+	c.Assert(removeAlias("myphotos"), Not(IsNil))
+}
+
+func (s *TestSuite) TestNormalizeAPISignature(c *C) {
+	api, ok := normalizeAPISignature("s3v4")
+	c.Assert(ok, Equals, true)
+	c.Assert(api, Equals, "S3v4")
+	api, ok = normalizeAPISignature("S3V2")
+	c.Assert(ok, Equals, true)
+	c.Assert(api, Equals, "S3v2")
+	api, ok = normalizeAPISignature("Auto")
+	c.Assert(ok, Equals, true)
+	c.Assert(api, Equals, "auto")
+	_, ok = normalizeAPISignature("s3v3")
+	c.Assert(ok, Equals, false)
+
+	c.Assert(maskSecret(""), Equals, "")
+	c.Assert(maskSecret("V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12"), Not(Matches), ".*V7f1.*")
+}
+
+// v2OnlyHandler - server rejecting signature version '4', counts the rejected requests.
+type v2OnlyHandler struct {
+	v4Requests int32
+}
+
+func (h *v2OnlyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		atomic.AddInt32(&h.v4Requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("<Error><Code>InvalidArgument</Code><Message>Unsupported Authorization Type</Message></Error>"))
+		return
+	}
+	w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`))
+}
+
+func (s *TestSuite) TestAliasSignatureAuto(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	handler := &v2OnlyHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	hostCfg := hostConfigV7{
+		URL:       server.URL,
+		AccessKey: "BKIKJAA5BMMU2RHO6IBB",
+		SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12",
+		API:       "auto",
+	}
+	c.Assert(setAlias("autosig", hostCfg), IsNil)
+
+	for i := 0; i < 2; i++ {
+		_, err := newClientFromAlias("autosig", server.URL+"/bucket")
+		c.Assert(err, IsNil)
+	}
+	c.Assert(atomic.LoadInt32(&handler.v4Requests), Equals, int32(1))
+
+	// Negotiated signature is recorded in the alias for later runs.
+	cacheCfgV7 = nil
+	loadMcConfig = loadMcConfigFactory()
+	savedCfg, err := getHostConfig("autosig")
+	c.Assert(err, IsNil)
+	c.Assert(savedCfg.API, Equals, "S3v2")
+
+	// Explicit signatures are used as is.
+	hostCfg.API = "S3v4"
+	c.Assert(setAlias("v4sig", hostCfg), IsNil)
+	_, err = newClientFromAlias("v4sig", server.URL+"/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(atomic.LoadInt32(&handler.v4Requests), Equals, int32(1))
+}
+
+func (s *TestSuite) TestAccelerateAlias(c *C) {
+	c.Assert(isAmazonS3Host("s3.amazonaws.com"), Equals, true)
+	c.Assert(isAmazonS3Host("s3-eu-west-1.amazonaws.com"), Equals, true)
+	c.Assert(isAmazonS3Host("bucket.s3.amazonaws.com"), Equals, true)
+	c.Assert(isAmazonS3Host("bucket.storage.googleapis.com"), Equals, false)
+	c.Assert(isAmazonS3Host("play.minio.io:9000"), Equals, false)
+
+	restore := useTempMcConfig(c)
+	defer restore()
+	hostCfg := hostConfigV7{
+		URL:        "https://s3.amazonaws.com",
+		AccessKey:  "BKIKJAA5BMMU2RHO6IBB",
+		SecretKey:  "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12",
+		API:        "S3v4",
+		Accelerate: true,
+	}
+	c.Assert(setAlias("fast", hostCfg), IsNil)
+	cacheCfgV7 = nil
+	loadMcConfig = loadMcConfigFactory()
+	savedCfg, err := getHostConfig("fast")
+	c.Assert(err, IsNil)
+	c.Assert(savedCfg.Accelerate, Equals, true)
+
+	s3Config, err := newS3Config(*savedCfg, "https://s3.amazonaws.com/my.bucket/object", nil)
+	c.Assert(err, IsNil)
+	c.Assert(s3Config.Accelerate, Equals, true)
+	warnedNotAccelerated.Lock()
+	c.Assert(warnedNotAccelerated.buckets["my.bucket"], Equals, true)
+	warnedNotAccelerated.Unlock()
+}
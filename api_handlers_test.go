@@ -19,6 +19,7 @@ package main
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"regexp"
@@ -166,3 +167,90 @@ func (h objectAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.putHandler(w, r)
 	}
 }
+
+// storedObject - object kept in memory by objectStoreHandler.
+type storedObject struct {
+	data   []byte
+	header http.Header
+}
+
+// objectStoreHandler is the in-memory object storage command tests talk to through an alias. It
+// stores objects along with their content type, cache control and user metadata. Metadata is
+// replaced by copying an object onto itself, unless copyUnsupported. Tests needing other server
+// behavior embed it, and handle the requests they are about before passing on the others.
+type objectStoreHandler struct {
+	mutex           sync.Mutex
+	objects         map[string]storedObject
+	copyUnsupported bool
+	etags           func(path string, data []byte) string // ETag of objects, "etag" for all if nil.
+	copies          []http.Header // headers of copy requests received.
+	uploads         int           // plain uploads received.
+}
+
+func (h *objectStoreHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	// Listings find no folders, objects are only looked up by name.
+	if r.Method == "GET" && strings.Count(r.URL.Path, "/") == 1 {
+		w.Write([]byte("<ListBucketResult><Name>" + strings.TrimPrefix(r.URL.Path, "/") + "</Name></ListBucketResult>"))
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		data, e := ioutil.ReadAll(r.Body)
+		if e != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if source := r.Header.Get("x-amz-copy-source"); source != "" {
+			if h.copyUnsupported {
+				w.WriteHeader(http.StatusNotImplemented)
+				return
+			}
+			h.copies = append(h.copies, r.Header)
+			data = h.objects[source].data
+		} else {
+			h.uploads++
+		}
+		header := make(http.Header)
+		for key := range r.Header {
+			if key == "Content-Type" || key == "Cache-Control" || strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") {
+				header.Set(key, r.Header.Get(key))
+			}
+		}
+		// A copy keeps the metadata of its source unless it is replaced.
+		if source := r.Header.Get("x-amz-copy-source"); source != "" && r.Header.Get("x-amz-metadata-directive") != "REPLACE" {
+			header = h.objects[source].header
+		}
+		h.objects[r.URL.Path] = storedObject{data: data, header: header}
+		w.Header().Set("ETag", "\""+h.etag(r.URL.Path)+"\"")
+	case "HEAD", "GET":
+		object, ok := h.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for key := range object.header {
+			w.Header().Set(key, object.header.Get(key))
+		}
+		w.Header().Set("ETag", "\""+h.etag(r.URL.Path)+"\"")
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", time.Unix(1445000000, 0).UTC().Format(http.TimeFormat))
+		if r.Method == "GET" {
+			w.Write(object.data)
+		}
+	case "DELETE":
+		delete(h.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// etag - ETag of the object at path.
+func (h *objectStoreHandler) etag(path string) string {
+	if h.etags == nil {
+		return "etag"
+	}
+	return h.etags(path, h.objects[path].data)
+}
@@ -0,0 +1,172 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-xl/pkg/atomic"
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/minio-xl/pkg/quick"
+)
+
+// backupFileSuffix - suffix of the copy kept from the previous successful save.
+const backupFileSuffix = ".bak"
+
+// getBackupFile - backup file name for a given file.
+func getBackupFile(filename string) string {
+	return filename + backupFileSuffix
+}
+
+// Steps of atomic saves which reach the disk, tests replace them to fail a save at each step.
+var (
+	writeFile  = (*os.File).Write
+	syncFile   = (*os.File).Sync
+	renameFile = os.Rename
+)
+
+// writeFileAtomic - writes data to a temporary file in the same folder, syncs it and renames it over
+// filename. The folder is synced as well, the rename is on disk once it returns.
+func writeFileAtomic(filename string, data []byte) *probe.Error {
+	file, e := atomic.FileCreate(filename)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if _, e = writeFile(file.File, data); e != nil {
+		// Never leave a partial temporary file behind.
+		file.CloseAndPurge()
+		return probe.NewError(e)
+	}
+	if e = syncFile(file.File); e != nil {
+		file.CloseAndPurge()
+		return probe.NewError(e)
+	}
+	if e = file.File.Close(); e != nil {
+		os.Remove(file.Name())
+		return probe.NewError(e)
+	}
+	if e = renameFile(file.Name(), filename); e != nil {
+		os.Remove(file.Name())
+		return probe.NewError(e)
+	}
+	return syncDir(filepath.Dir(filename)).Trace(filename)
+}
+
+// syncDir - sync the entries of folder dir, folders can not be synced on Windows.
+func syncDir(dir string) *probe.Error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	folder, e := os.Open(dir)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer folder.Close()
+	if e = syncFile(folder); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// saveFileAtomic - saves data as JSON atomically, previous contents of filename are kept as a backup if valid.
+func saveFileAtomic(filename string, data interface{}) *probe.Error {
+	if err := quick.CheckData(data); err != nil {
+		return err.Trace(filename)
+	}
+	jsonData, e := json.MarshalIndent(data, "", "\t")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if runtime.GOOS == "windows" {
+		jsonData = []byte(strings.Replace(string(jsonData), "\n", "\r\n", -1))
+	}
+
+	// Keep the previous good state around, a corrupt file should never replace a good backup.
+	if previous, e := ioutil.ReadFile(filename); e == nil && json.Valid(previous) {
+		if err := writeFileAtomic(getBackupFile(filename), previous); err != nil {
+			return err.Trace(filename)
+		}
+	}
+	return writeFileAtomic(filename, jsonData).Trace(filename)
+}
+
+// loadFileWithBackup - loads filename using load, recovers from backup if filename is missing or corrupt.
+func loadFileWithBackup(filename string, load func(string) *probe.Error) *probe.Error {
+	_, e := os.Stat(filename)
+	if e == nil {
+		err := load(filename)
+		if err == nil {
+			return nil
+		}
+		if _, e := os.Stat(getBackupFile(filename)); e != nil {
+			return err.Trace(filename)
+		}
+		// Primary file is corrupt, fall through to recover from backup.
+	} else if _, be := os.Stat(getBackupFile(filename)); be != nil {
+		return probe.NewError(e)
+	}
+
+	backupFile := getBackupFile(filename)
+	if err := load(backupFile); err != nil {
+		return err.Trace(filename, backupFile)
+	}
+	// Restore the primary file from the recovered backup.
+	backupData, e := ioutil.ReadFile(backupFile)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return writeFileAtomic(filename, backupData).Trace(filename)
+}
+
+// lock file related constants.
+const (
+	lockFileSuffix    = ".lock"
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	// A lock older than this was left behind by a crashed process.
+	lockStaleAge = time.Minute
+)
+
+// lockFile - takes an exclusive lock on filename shared across processes, call the returned func to release it.
+func lockFile(filename string) (func(), *probe.Error) {
+	lockName := filename + lockFileSuffix
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, e := os.OpenFile(lockName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if e == nil {
+			file.Close()
+			return func() { os.Remove(lockName) }, nil
+		}
+		if !os.IsExist(e) {
+			return nil, probe.NewError(e)
+		}
+		if st, e := os.Stat(lockName); e == nil && time.Since(st.ModTime()) > lockStaleAge {
+			os.Remove(lockName)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errLockTimeout(lockName).Trace(filename)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestSaveFileAtomic(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "atomic-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "downloads.json")
+	shareDB := newShareDBV2()
+	shareDB.Set("https://first", shareEntryV2{URL: "s3/bucket/first", Expiry: time.Hour})
+	c.Assert(shareDB.Save(shareFile), IsNil)
+	shareDB.Set("https://second", shareEntryV2{URL: "s3/bucket/second", Expiry: time.Hour})
+	c.Assert(shareDB.Save(shareFile), IsNil)
+
+	// Crash while writing the temporary file leaves the saved state untouched.
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "$deleteme.downloads.json123"), []byte(`{"version": "1", "sha`), 0600), IsNil)
+	loadedDB := newShareDBV2()
+	c.Assert(loadedDB.Load(shareFile), IsNil)
+	c.Assert(len(loadedDB.Shares), Equals, 2)
+
+	// Saving a corrupt file must not overwrite a good backup.
+	c.Assert(ioutil.WriteFile(shareFile, []byte(`{"version": "1", "sha`), 0600), IsNil)
+	c.Assert(shareDB.Save(shareFile), IsNil)
+	backupDB := newShareDBV2()
+	c.Assert(backupDB.Load(getBackupFile(shareFile)), IsNil)
+	// Save merges with the state recovered from the backup, which is the state before the last save.
+	c.Assert(len(backupDB.Shares), Equals, 1)
+	c.Assert(loadedDB.Load(shareFile), IsNil)
+	c.Assert(len(loadedDB.Shares), Equals, 2)
+}
+
+func (s *TestSuite) TestLoadFileWithBackup(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "atomic-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "uploads.json")
+	shareDB := newShareDBV2()
+	shareDB.Set("https://first", shareEntryV2{URL: "s3/bucket/first", Expiry: time.Hour})
+	c.Assert(shareDB.Save(shareFile), IsNil)
+	shareDB.Set("https://second", shareEntryV2{URL: "s3/bucket/second", Expiry: time.Hour})
+	c.Assert(shareDB.Save(shareFile), IsNil)
+
+	// Simulate an interrupted non-atomic write truncating the primary file.
+	c.Assert(ioutil.WriteFile(shareFile, []byte(`{"version": "1", "sha`), 0600), IsNil)
+
+	loadedDB := newShareDBV2()
+	c.Assert(loadedDB.Load(shareFile), IsNil)
+	c.Assert(len(loadedDB.Shares), Equals, 1)
+	_, ok := loadedDB.Shares["https://first"]
+	c.Assert(ok, Equals, true)
+
+	// Primary file is restored from backup.
+	loadedDB = newShareDBV2()
+	c.Assert(loadedDB.Load(shareFile), IsNil)
+	c.Assert(len(loadedDB.Shares), Equals, 1)
+
+	// Missing primary file is recovered from backup as well.
+	c.Assert(os.Remove(shareFile), IsNil)
+	loadedDB = newShareDBV2()
+	c.Assert(loadedDB.Load(shareFile), IsNil)
+	c.Assert(len(loadedDB.Shares), Equals, 1)
+
+	// Without a backup the error is reported.
+	c.Assert(os.Remove(shareFile), IsNil)
+	c.Assert(os.Remove(getBackupFile(shareFile)), IsNil)
+	c.Assert(newShareDBV2().Load(shareFile), Not(IsNil))
+}
+
+func (s *TestSuite) TestSessionRecovery(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	session.Header.CommandType = "cp"
+	c.Assert(session.Save(), IsNil)
+	session.Header.LastCopied = "s3/bucket/object"
+	c.Assert(session.Save(), IsNil)
+
+	sessionFile, err := getSessionFile(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(sessionFile, []byte(`{"version": "6", "when`), 0600), IsNil)
+
+	savedSession, err := loadSessionV6(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(savedSession.Header.CommandType, Equals, "cp")
+	c.Assert(savedSession.Header.LastCopied, Equals, "")
+
+	c.Assert(savedSession.Close(), IsNil)
+	c.Assert(savedSession.Delete(), IsNil)
+	_, e := os.Stat(getBackupFile(sessionFile))
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
@@ -0,0 +1,58 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"sync"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// auditLog - file of ‘--audit’, one line of JSON for each signed request. Lines of
+// concurrent requests are written one at a time.
+type auditLog struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func (a *auditLog) Write(p []byte) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.file.Write(p)
+}
+
+// setAuditFile - record signed requests to filename with ‘--audit’, appended to what it
+// holds. An empty filename stops recording.
+func setAuditFile(filename string) {
+	if filename == globalAuditFile {
+		return
+	}
+	if globalAudit != nil {
+		globalAudit.file.Close()
+		globalAudit = nil
+	}
+	globalAuditFile = filename
+	if filename == "" {
+		return
+	}
+	file, e := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if e != nil {
+		fatalIf(probe.NewError(e).Trace(filename), "Unable to open audit file ‘"+filename+"’.")
+	}
+	globalAudit = &auditLog{file: file}
+}
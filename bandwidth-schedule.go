@@ -0,0 +1,203 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// bandwidthWindow - cap in bytes/s from start to end, times of day as offsets from midnight.
+// A window whose end is not after its start wraps midnight.
+type bandwidthWindow struct {
+	start time.Duration
+	end   time.Duration
+	limit int64 // zero is unlimited.
+}
+
+// contains - true if the time of day tod is within the window.
+func (w bandwidthWindow) contains(tod time.Duration) bool {
+	if w.start < w.end {
+		return tod >= w.start && tod < w.end
+	}
+	return tod >= w.start || tod < w.end
+}
+
+// bandwidthSchedule - caps of ‘--bw-schedule’, the first window containing a time applies.
+type bandwidthSchedule []bandwidthWindow
+
+// parseTimeOfDay - parse ‘HH:MM’ into an offset from midnight.
+func parseTimeOfDay(str string) (time.Duration, *probe.Error) {
+	t, e := time.Parse("15:04", strings.TrimSpace(str))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseBandwidthSchedule - parse windows such as ‘09:00-17:00=2MB,17:00-09:00=0’ separated by
+// commas, caps are bytes/s and ‘0’ is unlimited. Times outside of all windows are unlimited.
+func parseBandwidthSchedule(scheduleStr string) (bandwidthSchedule, *probe.Error) {
+	var schedule bandwidthSchedule
+	for _, windowStr := range strings.Split(scheduleStr, ",") {
+		timesAndLimit := strings.SplitN(windowStr, "=", 2)
+		if len(timesAndLimit) != 2 {
+			return nil, errInvalidArgument().Trace(windowStr)
+		}
+		times := strings.SplitN(timesAndLimit[0], "-", 2)
+		if len(times) != 2 {
+			return nil, errInvalidArgument().Trace(windowStr)
+		}
+		var window bandwidthWindow
+		var err *probe.Error
+		if window.start, err = parseTimeOfDay(times[0]); err != nil {
+			return nil, err.Trace(windowStr)
+		}
+		if window.end, err = parseTimeOfDay(times[1]); err != nil {
+			return nil, err.Trace(windowStr)
+		}
+		limit, e := humanize.ParseBytes(strings.TrimSuffix(strings.TrimSpace(timesAndLimit[1]), "/s"))
+		if e != nil {
+			return nil, probe.NewError(e).Trace(windowStr)
+		}
+		window.limit = int64(limit)
+		schedule = append(schedule, window)
+	}
+	return schedule, nil
+}
+
+// limitAt - cap in bytes/s at t in local time, zero is unlimited.
+func (s bandwidthSchedule) limitAt(t time.Time) int64 {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	tod := t.Sub(midnight)
+	for _, window := range s {
+		if window.contains(tod) {
+			return window.limit
+		}
+	}
+	return 0
+}
+
+// bandwidthRecheck - longest a transfer waits before the active window is looked up again.
+const bandwidthRecheck = time.Second
+
+// bandwidthLimiter - token bucket shared by all transfers of a command, refilled at the
+// cap of the window active at the time. Transfers waiting for tokens look up the active
+// window at least every bandwidthRecheck, a new cap applies to transfers in progress.
+type bandwidthLimiter struct {
+	schedule bandwidthSchedule
+	now      func() time.Time
+	sleep    func(time.Duration)
+
+	mutex  sync.Mutex
+	limit  int64
+	tokens float64
+	last   time.Time
+}
+
+// newBandwidthLimiter - limiter following schedule on the wall clock.
+func newBandwidthLimiter(schedule bandwidthSchedule) *bandwidthLimiter {
+	return &bandwidthLimiter{schedule: schedule, now: time.Now, sleep: time.Sleep}
+}
+
+// refill - add tokens for the time passed since the last refill, at most a second worth of them.
+func (l *bandwidthLimiter) refill() {
+	now := l.now()
+	l.limit = l.schedule.limitAt(now)
+	if l.limit > 0 && !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.limit)
+		if l.tokens > float64(l.limit) {
+			l.tokens = float64(l.limit)
+		}
+	}
+	l.last = now
+}
+
+// wait - take n tokens, waiting until the bucket is no longer in debt for them.
+func (l *bandwidthLimiter) wait(n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill()
+	l.tokens -= float64(n)
+	for l.tokens < 0 && l.limit > 0 {
+		wait := time.Duration(-l.tokens / float64(l.limit) * float64(time.Second))
+		if wait > bandwidthRecheck {
+			wait = bandwidthRecheck
+		}
+		l.sleep(wait)
+		l.refill()
+	}
+	if l.limit == 0 {
+		// Unlimited transfers leave no debt behind for the next window.
+		l.tokens = 0
+	}
+}
+
+// bandwidthChunk - most bytes read at once by a limited transfer, for an even pace.
+const bandwidthChunk = 64 * 1024
+
+// bandwidthReader - source of a transfer paced by a bandwidth limiter.
+type bandwidthReader struct {
+	io.ReadSeeker
+	limiter *bandwidthLimiter
+}
+
+// newBandwidthReader - wrap source to be paced by limiter, source is returned as is without a limiter.
+func newBandwidthReader(source io.ReadSeeker, limiter *bandwidthLimiter) io.ReadSeeker {
+	if limiter == nil {
+		return source
+	}
+	return bandwidthReader{ReadSeeker: source, limiter: limiter}
+}
+
+func (r bandwidthReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunk {
+		p = p[:bandwidthChunk]
+	}
+	n, e := r.ReadSeeker.Read(p)
+	if n > 0 {
+		r.limiter.wait(n)
+	}
+	return n, e
+}
+
+// slowest - lowest cap other than unlimited, zero if all windows are unlimited.
+func (s bandwidthSchedule) slowest() int64 {
+	var slowest int64
+	for _, window := range s {
+		if window.limit > 0 && (slowest == 0 || window.limit < slowest) {
+			slowest = window.limit
+		}
+	}
+	return slowest
+}
+
+// getSessionBandwidthLimiter - limiter of ‘--bw-schedule’ saved in session, nil without a schedule.
+func getSessionBandwidthLimiter(session *sessionV6) *bandwidthLimiter {
+	scheduleStr := session.Header.CommandStringFlags["bw-schedule"]
+	if scheduleStr == "" {
+		return nil
+	}
+	schedule, err := parseBandwidthSchedule(scheduleStr)
+	fatalIf(err.Trace(scheduleStr), "Invalid bandwidth schedule ‘"+scheduleStr+"’.")
+	return newBandwidthLimiter(schedule)
+}
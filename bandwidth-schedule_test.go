@@ -0,0 +1,113 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseBandwidthSchedule(c *C) {
+	schedule, err := parseBandwidthSchedule("09:00-17:00=2MB, 17:00-09:00=0")
+	c.Assert(err, IsNil)
+	c.Assert(schedule, DeepEquals, bandwidthSchedule{
+		{start: 9 * time.Hour, end: 17 * time.Hour, limit: 2000000},
+		{start: 17 * time.Hour, end: 9 * time.Hour, limit: 0},
+	})
+	c.Assert(schedule.slowest(), Equals, int64(2000000))
+
+	schedule, err = parseBandwidthSchedule("22:30-06:00=512KiB/s")
+	c.Assert(err, IsNil)
+	c.Assert(schedule[0].limit, Equals, int64(512*1024))
+
+	for _, scheduleStr := range []string{"", "09:00-17:00", "09:00=2MB", "9-17=2MB", "09:00-25:00=2MB", "09:00-17:00=fast"} {
+		_, err = parseBandwidthSchedule(scheduleStr)
+		c.Assert(err, NotNil, Commentf("%s", scheduleStr))
+	}
+}
+
+func (s *TestSuite) TestBandwidthScheduleLimitAt(c *C) {
+	schedule, err := parseBandwidthSchedule("09:00-17:00=2MB,22:00-06:00=1MB")
+	c.Assert(err, IsNil)
+	at := func(hour, minute int) time.Time {
+		return time.Date(2015, time.October, 12, hour, minute, 0, 0, time.Local)
+	}
+	c.Assert(schedule.limitAt(at(9, 0)), Equals, int64(2000000))
+	c.Assert(schedule.limitAt(at(16, 59)), Equals, int64(2000000))
+	c.Assert(schedule.limitAt(at(17, 0)), Equals, int64(0))
+	// The second window wraps midnight.
+	c.Assert(schedule.limitAt(at(23, 0)), Equals, int64(1000000))
+	c.Assert(schedule.limitAt(at(0, 0)), Equals, int64(1000000))
+	c.Assert(schedule.limitAt(at(5, 59)), Equals, int64(1000000))
+	c.Assert(schedule.limitAt(at(6, 0)), Equals, int64(0))
+}
+
+func (s *TestSuite) TestBandwidthLimiterWindowChange(c *C) {
+	schedule, err := parseBandwidthSchedule("09:00-17:00=2MB,17:00-09:00=0")
+	c.Assert(err, IsNil)
+	start := time.Date(2015, time.October, 12, 16, 59, 50, 0, time.Local)
+	now := start
+	var slept time.Duration
+	limiter := newBandwidthLimiter(schedule)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	// 18MB take nine seconds at 2MB/s, before 17:00.
+	reader := newBandwidthReader(bytes.NewReader(make([]byte, 18000000)), limiter)
+	n, e := io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	c.Assert(n, Equals, int64(18000000))
+	c.Assert(now.Sub(start) >= 8*time.Second && now.Sub(start) <= 9*time.Second, Equals, true, Commentf("%s", now.Sub(start)))
+	c.Assert(limiter.limit, Equals, int64(2000000))
+
+	// After 17:00 transfers are no longer capped.
+	now = start.Add(10 * time.Second)
+	slept = 0
+	reader = newBandwidthReader(bytes.NewReader(make([]byte, 20000000)), limiter)
+	_, e = io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	c.Assert(slept, Equals, time.Duration(0))
+	c.Assert(limiter.limit, Equals, int64(0))
+}
+
+func (s *TestSuite) TestBandwidthLimiterRecheck(c *C) {
+	schedule, err := parseBandwidthSchedule("09:00-17:00=1KB,17:00-09:00=0")
+	c.Assert(err, IsNil)
+	now := time.Date(2015, time.October, 12, 16, 59, 58, 0, time.Local)
+	limiter := newBandwidthLimiter(schedule)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) {
+		c.Assert(d <= bandwidthRecheck, Equals, true)
+		now = now.Add(d)
+	}
+	// A read waiting for an hour at 1KB/s resumes once the window ends at 17:00.
+	limiter.wait(3600 * 1000)
+	c.Assert(now, Equals, time.Date(2015, time.October, 12, 17, 0, 0, 0, time.Local))
+	c.Assert(limiter.tokens, Equals, float64(0))
+}
+
+func (s *TestSuite) TestBandwidthReaderUnlimited(c *C) {
+	source := bytes.NewReader([]byte("hello"))
+	c.Assert(newBandwidthReader(source, nil), Equals, source)
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/minio-xl/pkg/probe"
 )
@@ -34,6 +35,19 @@ var (
 			Name:  "help, h",
 			Usage: "Help of cat",
 		},
+		cli.BoolFlag{
+			Name:  "cache",
+			Usage: "Serve objects from a local cache while their ETag is unchanged.",
+		},
+		cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "Fetch objects from their host, refreshing their cached copies.",
+		},
+		cli.StringFlag{
+			Name:  "cache-max-size",
+			Value: "64MiB",
+			Usage: "Size of the local cache, least recently used objects are evicted first.",
+		},
 	}
 )
 
@@ -62,6 +76,9 @@ EXAMPLES:
    3. Concantenate multiple files to one.
       $ mc {{.Name}} part.* > complete.img
 
+   4. Display a frequently read config object, served from the local cache while it is unchanged.
+      $ mc {{.Name}} --cache s3/ferenginar/settings.json
+
 `,
 }
 
@@ -76,10 +93,28 @@ func checkCatSyntax(ctx *cli.Context) {
 			fatalIf(probe.NewError(errors.New("")), fmt.Sprintf("Unknown flag ‘%s’ passed.", arg))
 		}
 	}
+	if ctx.Bool("cache") && ctx.Bool("no-cache") {
+		fatalIf(errInvalidArgument().Trace(), "‘--cache’ and ‘--no-cache’ cannot be used together.")
+	}
+	if _, err := parseCacheMaxSize(ctx.String("cache-max-size")); err != nil {
+		fatalIf(err.Trace(ctx.String("cache-max-size")), "Invalid cache size ‘"+ctx.String("cache-max-size")+"’.")
+	}
 }
 
-// catURL displays contents of a URL to stdout.
-func catURL(sourceURL string) *probe.Error {
+// parseCacheMaxSize - parse a human readable cache size such as ‘64MiB’.
+func parseCacheMaxSize(sizeStr string) (int64, *probe.Error) {
+	size, e := humanize.ParseBytes(strings.TrimSpace(sizeStr))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	if size == 0 {
+		return 0, errInvalidArgument().Trace(sizeStr)
+	}
+	return int64(size), nil
+}
+
+// catURL displays contents of a URL to stdout, read through cache if set.
+func catURL(sourceURL string, cache *contentCache) *probe.Error {
 	var reader io.ReadSeeker
 	switch sourceURL {
 	case "-":
@@ -88,7 +123,12 @@ func catURL(sourceURL string) *probe.Error {
 		// Ignore size, since os.Stat() would not return proper size all the
 		// time for local filesystem for example /proc files.
 		var err *probe.Error
-		if reader, err = getSource(sourceURL); err != nil {
+		if cache != nil {
+			reader, err = getCachedSource(sourceURL, cache)
+		} else {
+			reader, err = getSource(sourceURL)
+		}
+		if err != nil {
 			return err.Trace(sourceURL)
 		}
 	}
@@ -145,8 +185,18 @@ func mainCat(ctx *cli.Context) {
 		}
 	}
 
+	var cache *contentCache
+	if ctx.Bool("cache") || ctx.Bool("no-cache") {
+		maxSize, err := parseCacheMaxSize(ctx.String("cache-max-size"))
+		fatalIf(err.Trace(ctx.String("cache-max-size")), "Invalid cache size.")
+		cacheDir, err := getContentCacheDir()
+		fatalIf(err.Trace(), "Unable to determine cache folder.")
+		cache, err = newContentCache(cacheDir, maxSize, ctx.Bool("no-cache"))
+		fatalIf(err.Trace(cacheDir), "Unable to create cache folder ‘"+cacheDir+"’.")
+	}
+
 	// Convert arguments to URLs: expand alias, fix format.
 	for _, url := range args {
-		fatalIf(catURL(url).Trace(url), "Unable to read from ‘"+url+"’.")
+		fatalIf(catURL(url, cache).Trace(url), "Unable to read from ‘"+url+"’.")
 	}
 }
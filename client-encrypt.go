@@ -0,0 +1,651 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Modes of ‘--encrypt-mode’.
+const (
+	// Keys are sent along with requests, the server encrypts objects (SSE-C).
+	encryptModeSSEC = "sse-c"
+	// Keys never leave the client, objects are encrypted before upload and decrypted after download.
+	encryptModeClient = "client"
+)
+
+// setEncryptMode - set how keys of ‘--encrypt-key-file’ and the keystore encrypt objects, sse-c if empty.
+func setEncryptMode(mode string) {
+	switch mode {
+	case "":
+		mode = encryptModeSSEC
+	case encryptModeSSEC, encryptModeClient:
+	default:
+		fatalIf(errInvalidArgument().Trace(mode), "Option --encrypt-mode must be ‘sse-c’ or ‘client’.")
+	}
+	globalEncryptMode = mode
+}
+
+// Metadata of client side encrypted objects. Every object has its own random data key, it is
+// stored sealed by the key configured for the object, the master key.
+const (
+	metadataCSEAlgorithm = "cse-algorithm"
+	metadataCSEKey       = "cse-key" // base64 encoded nonce and sealed data key.
+	metadataCSEIV        = "cse-iv"  // base64 encoded, nonces of all segments are derived from it.
+)
+
+// Objects are sealed by AES-256-GCM in segments of cseSegmentSize, each followed by its tag.
+// Segments are authenticated along with their sequence number and whether they are the last,
+// so reordered, truncated or extended objects fail just like modified ones.
+const (
+	cseAlgorithm   = "AES256-GCM-64K"
+	cseSegmentSize = 64 * 1024
+	cseTagSize     = 16
+	cseNonceSize   = 12
+	cseSealedSize  = cseSegmentSize + cseTagSize
+)
+
+// errCSEAuthentication - returned by reads of client side encrypted objects failing the GCM tag.
+var errCSEAuthentication = errors.New("Client side encrypted data failed authentication, it was modified or the key does not match.")
+
+// cseEncryptedSize - size of an object of size bytes once encrypted, even empty objects have a tag.
+func cseEncryptedSize(size int64) int64 {
+	segments := (size + cseSegmentSize - 1) / cseSegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return size + segments*cseTagSize
+}
+
+// cseDecryptedSize - size of an object encrypted to size bytes, negative if no object encrypts to size.
+func cseDecryptedSize(size int64) int64 {
+	if size < cseTagSize {
+		return -1
+	}
+	segments := (size + cseSealedSize - 1) / cseSealedSize
+	return size - segments*cseTagSize
+}
+
+// cseCipher - seals and opens the segments of one object.
+type cseCipher struct {
+	aead cipher.AEAD
+	iv   []byte
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, e := aes.NewCipher(key)
+	if e != nil {
+		return nil, e
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce - IV of the object with the sequence number of the segment added to its last 8 bytes.
+func (c cseCipher) nonce(seq int64) []byte {
+	nonce := make([]byte, cseNonceSize)
+	copy(nonce, c.iv)
+	binary.BigEndian.PutUint64(nonce[4:], binary.BigEndian.Uint64(nonce[4:])^uint64(seq))
+	return nonce
+}
+
+func cseAdditionalData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func (c cseCipher) seal(dst []byte, seq int64, final bool, plain []byte) []byte {
+	return c.aead.Seal(dst, c.nonce(seq), plain, cseAdditionalData(final))
+}
+
+func (c cseCipher) open(dst []byte, seq int64, final bool, sealed []byte) ([]byte, error) {
+	plain, e := c.aead.Open(dst, c.nonce(seq), sealed, cseAdditionalData(final))
+	if e != nil {
+		return nil, errCSEAuthentication
+	}
+	return plain, nil
+}
+
+// newCSECipher - cipher of a new object with a random data key, along with its metadata.
+func newCSECipher(masterKey []byte) (cseCipher, map[string]string, *probe.Error) {
+	dataKey := make([]byte, encryptKeySize)
+	iv := make([]byte, cseNonceSize)
+	keyNonce := make([]byte, cseNonceSize)
+	for _, b := range [][]byte{dataKey, iv, keyNonce} {
+		if _, e := io.ReadFull(rand.Reader, b); e != nil {
+			return cseCipher{}, nil, probe.NewError(e)
+		}
+	}
+	master, e := newAESGCM(masterKey)
+	if e != nil {
+		return cseCipher{}, nil, probe.NewError(e)
+	}
+	aead, e := newAESGCM(dataKey)
+	if e != nil {
+		return cseCipher{}, nil, probe.NewError(e)
+	}
+	sealedKey := master.Seal(keyNonce, keyNonce, dataKey, []byte(cseAlgorithm))
+	metadata := map[string]string{
+		metadataCSEAlgorithm: cseAlgorithm,
+		metadataCSEKey:       base64.StdEncoding.EncodeToString(sealedKey),
+		metadataCSEIV:        base64.StdEncoding.EncodeToString(iv),
+	}
+	return cseCipher{aead: aead, iv: iv}, metadata, nil
+}
+
+// isCSEMetadata - reports if metadata is of a client side encrypted object.
+func isCSEMetadata(metadata map[string]string) bool {
+	_, ok := metadata[metadataCSEKey]
+	return ok
+}
+
+// openCSECipher - cipher of the object at urlStr with metadata, its data key unsealed by masterKey.
+func openCSECipher(urlStr string, masterKey []byte, metadata map[string]string) (cseCipher, *probe.Error) {
+	if !isCSEMetadata(metadata) {
+		return cseCipher{}, errNotClientEncrypted(urlStr).Trace(urlStr)
+	}
+	if metadata[metadataCSEAlgorithm] != cseAlgorithm {
+		return cseCipher{}, errInvalidClientEncryption(urlStr, "unsupported algorithm ‘"+metadata[metadataCSEAlgorithm]+"’").Trace(urlStr)
+	}
+	sealedKey, e := base64.StdEncoding.DecodeString(metadata[metadataCSEKey])
+	if e != nil || len(sealedKey) < cseNonceSize {
+		return cseCipher{}, errInvalidClientEncryption(urlStr, "malformed data key").Trace(urlStr)
+	}
+	iv, e := base64.StdEncoding.DecodeString(metadata[metadataCSEIV])
+	if e != nil || len(iv) != cseNonceSize {
+		return cseCipher{}, errInvalidClientEncryption(urlStr, "malformed IV").Trace(urlStr)
+	}
+	master, e := newAESGCM(masterKey)
+	if e != nil {
+		return cseCipher{}, probe.NewError(e)
+	}
+	dataKey, e := master.Open(nil, sealedKey[:cseNonceSize], sealedKey[cseNonceSize:], []byte(cseAlgorithm))
+	if e != nil {
+		return cseCipher{}, errInvalidClientEncryption(urlStr, "data key does not open with the configured key").Trace(urlStr)
+	}
+	aead, e := newAESGCM(dataKey)
+	if e != nil {
+		return cseCipher{}, probe.NewError(e)
+	}
+	return cseCipher{aead: aead, iv: iv}, nil
+}
+
+// cseReader - reads segments of source. The last one is told by the size of source, sources of
+// unknown size are peeked a byte ahead, object bodies fail reads past their end.
+type cseReader struct {
+	source io.Reader
+	reader *bufio.Reader
+	size   int64 // of all of source, negative if unknown.
+	seq    int64
+	buf    []byte // rest of the current segment.
+	done   bool   // last segment was read.
+	offset int64
+}
+
+func newCSEReader(source io.Reader, size int64) cseReader {
+	return cseReader{source: source, reader: bufio.NewReader(source), size: size}
+}
+
+// fill - read segment seq of source, segments are len(segment) bytes.
+func (r *cseReader) fill(segment []byte) (n int, final bool, e error) {
+	if r.size >= 0 {
+		start := r.seq * int64(len(segment))
+		if left := r.size - start; left < 0 {
+			return 0, false, io.ErrUnexpectedEOF
+		} else if left < int64(len(segment)) {
+			segment = segment[:left]
+		}
+		if n, e = io.ReadFull(r.reader, segment); e != nil {
+			return 0, false, e
+		}
+		return n, start+int64(n) >= r.size, nil
+	}
+	n, e = io.ReadFull(r.reader, segment)
+	if e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+		return 0, false, e
+	}
+	if n < len(segment) {
+		return n, true, nil
+	}
+	if _, e = r.reader.Peek(1); e == io.EOF {
+		return n, true, nil
+	} else if e != nil {
+		return 0, false, e
+	}
+	return n, false, nil
+}
+
+// rewind - continue reading source, seeked to segment seq.
+func (r *cseReader) rewind(seq int64) {
+	r.reader.Reset(r.source)
+	r.seq = seq
+	r.buf = nil
+	r.done = false
+}
+
+// seekOffset - absolute offset of a Seek, size is only needed for io.SeekEnd.
+func (r *cseReader) seekOffset(offset int64, whence int, size func() (int64, error)) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		end, e := size()
+		if e != nil {
+			return 0, e
+		}
+		offset += end
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek to a negative offset.")
+	}
+	return offset, nil
+}
+
+// cseEncryptReader - ciphertext of a plaintext source.
+type cseEncryptReader struct {
+	cseReader
+	cipher cseCipher
+	plain  []byte
+	sealed []byte
+}
+
+func newCSEEncryptReader(source io.Reader, size int64, c cseCipher) *cseEncryptReader {
+	return &cseEncryptReader{
+		cseReader: newCSEReader(source, size),
+		cipher:    c,
+		plain:     make([]byte, cseSegmentSize),
+		sealed:    make([]byte, 0, cseSealedSize),
+	}
+}
+
+func (r *cseEncryptReader) next() error {
+	n, final, e := r.fill(r.plain)
+	if e != nil {
+		return e
+	}
+	r.buf = r.cipher.seal(r.sealed[:0], r.seq, final, r.plain[:n])
+	r.seq++
+	r.done = final
+	return nil
+}
+
+func (r *cseEncryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if e := r.next(); e != nil {
+			return 0, e
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.offset += int64(n)
+	return n, nil
+}
+
+// Seek - seek the ciphertext, the source is seeked to the start of the segment holding offset.
+func (r *cseEncryptReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.source.(io.Seeker)
+	if !ok || r.size < 0 {
+		return 0, errors.New("Seek of an encrypted stream of unknown size.")
+	}
+	encryptedSize := cseEncryptedSize(r.size)
+	offset, e := r.seekOffset(offset, whence, func() (int64, error) { return encryptedSize, nil })
+	if e != nil {
+		return 0, e
+	}
+	if offset >= encryptedSize {
+		r.buf, r.done, r.offset = nil, true, offset
+		return offset, nil
+	}
+	seq := offset / cseSealedSize
+	if _, e = seeker.Seek(seq*cseSegmentSize, io.SeekStart); e != nil {
+		return 0, e
+	}
+	r.rewind(seq)
+	if skip := offset - seq*cseSealedSize; skip > 0 {
+		if e = r.next(); e != nil {
+			return 0, e
+		}
+		r.buf = r.buf[skip:]
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// cseDecryptReader - plaintext of a ciphertext source, every segment is authenticated before
+// any of it is returned.
+type cseDecryptReader struct {
+	cseReader
+	cipher cseCipher
+	start  int64 // ciphertext offset source starts at, always that of a segment.
+	end    int64 // plaintext offset reads stop at, zero reads to the end.
+	sealed []byte
+	plain  []byte
+}
+
+// newCSEDecryptReader - plaintext from offset of an object of size bytes, source starts at the
+// segment holding offset.
+func newCSEDecryptReader(source io.ReadSeeker, size int64, c cseCipher, offset int64) (*cseDecryptReader, error) {
+	seq := offset / cseSegmentSize
+	r := &cseDecryptReader{
+		cseReader: newCSEReader(source, size),
+		cipher:    c,
+		start:     seq * cseSealedSize,
+		sealed:    make([]byte, cseSealedSize),
+		plain:     make([]byte, 0, cseSegmentSize),
+	}
+	r.seq = seq
+	if offset >= cseDecryptedSize(size) {
+		r.done, r.offset = true, offset
+		return r, nil
+	}
+	if e := r.skipTo(offset); e != nil {
+		return nil, e
+	}
+	return r, nil
+}
+
+// skipTo - skip to offset within the current segment.
+func (r *cseDecryptReader) skipTo(offset int64) error {
+	if skip := offset - r.seq*cseSegmentSize; skip > 0 {
+		if e := r.next(); e != nil {
+			return e
+		}
+		if skip > int64(len(r.buf)) {
+			skip = int64(len(r.buf))
+		}
+		r.buf = r.buf[skip:]
+	}
+	r.offset = offset
+	return nil
+}
+
+func (r *cseDecryptReader) next() error {
+	n, final, e := r.fill(r.sealed)
+	if e != nil {
+		return e
+	}
+	if r.buf, e = r.cipher.open(r.plain[:0], r.seq, final, r.sealed[:n]); e != nil {
+		return e
+	}
+	r.seq++
+	r.done = final
+	return nil
+}
+
+func (r *cseDecryptReader) Read(p []byte) (int, error) {
+	if r.end > 0 {
+		if r.offset >= r.end {
+			return 0, io.EOF
+		}
+		if int64(len(p)) > r.end-r.offset {
+			p = p[:r.end-r.offset]
+		}
+	}
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if e := r.next(); e != nil {
+			return 0, e
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.offset += int64(n)
+	return n, nil
+}
+
+// Seek - seek the plaintext, the source is seeked to the start of the segment holding offset.
+func (r *cseDecryptReader) Seek(offset int64, whence int) (int64, error) {
+	seeker := r.source.(io.Seeker)
+	offset, e := r.seekOffset(offset, whence, func() (int64, error) {
+		return cseDecryptedSize(r.size), nil
+	})
+	if e != nil {
+		return 0, e
+	}
+	if offset >= cseDecryptedSize(r.size) {
+		r.buf, r.done, r.offset = nil, true, offset
+		return offset, nil
+	}
+	seq := offset / cseSegmentSize
+	if seq*cseSealedSize < r.start {
+		return 0, errors.New("Seek before the start of an encrypted range.")
+	}
+	if _, e = seeker.Seek(seq*cseSealedSize-r.start, io.SeekStart); e != nil {
+		return 0, e
+	}
+	r.rewind(seq)
+	if e = r.skipTo(offset); e != nil {
+		return 0, e
+	}
+	return offset, nil
+}
+
+// clientEncryptClient wraps an object storage client, objects are encrypted before they are
+// uploaded and decrypted once downloaded by the key configured for them. Objects without a key
+// are passed through, objects with a key must be client side encrypted to be read.
+type clientEncryptClient struct {
+	client.Client
+	// keyOf - key configured for the URL, nil if none.
+	keyOf func(urlStr string) ([]byte, *probe.Error)
+}
+
+// newClientEncryptClient - wrap clnt, keys are looked up by keyOf.
+func newClientEncryptClient(clnt client.Client, keyOf func(urlStr string) ([]byte, *probe.Error)) client.Client {
+	return clientEncryptClient{Client: clnt, keyOf: keyOf}
+}
+
+// Stat - size of the plaintext, metadata of the encryption is left out, it is never copied
+// along to other objects.
+func (c clientEncryptClient) Stat() (*client.Content, *probe.Error) {
+	content, err := c.Client.Stat()
+	if err != nil || !isCSEMetadata(content.Metadata) {
+		return content, err
+	}
+	content.Size = cseDecryptedSize(content.Size)
+	metadata := make(map[string]string)
+	for k, v := range content.Metadata {
+		if k != metadataCSEAlgorithm && k != metadataCSEKey && k != metadataCSEIV {
+			metadata[k] = v
+		}
+	}
+	content.Metadata = metadata
+	return content, nil
+}
+
+// decryptedContent - size of the plaintext of listed objects which have a key, listings carry no
+// metadata, such objects are taken to be encrypted.
+func (c clientEncryptClient) decryptedContent(content *client.Content) *client.Content {
+	if content.Err != nil || !content.Type.IsRegular() || content.UploadID != "" {
+		return content
+	}
+	if key, err := c.keyOf(content.URL.String()); err == nil && key != nil {
+		if size := cseDecryptedSize(content.Size); size >= 0 {
+			content.Size = size
+		}
+	}
+	return content
+}
+
+func (c clientEncryptClient) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *client.Content {
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer close(contentCh)
+		for content := range c.Client.List(recursive, incomplete, doneCh) {
+			contentCh <- c.decryptedContent(content)
+		}
+	}()
+	return contentCh
+}
+
+func (c clientEncryptClient) ListPage(recursive bool, startAfter string, maxKeys int) ([]*client.Content, string, *probe.Error) {
+	contents, marker, err := c.Client.ListPage(recursive, startAfter, maxKeys)
+	for _, content := range contents {
+		c.decryptedContent(content)
+	}
+	return contents, marker, err
+}
+
+// objectCipher - cipher of the object and its encrypted size, nil without a key.
+func (c clientEncryptClient) objectCipher() (*cseCipher, int64, *probe.Error) {
+	urlStr := c.GetURL().String()
+	key, err := c.keyOf(urlStr)
+	if err != nil || key == nil {
+		return nil, 0, err
+	}
+	content, err := c.Client.Stat()
+	if err != nil {
+		return nil, 0, err.Trace(urlStr)
+	}
+	objCipher, err := openCSECipher(urlStr, key, content.Metadata)
+	if err != nil {
+		return nil, 0, err.Trace(urlStr)
+	}
+	return &objCipher, content.Size, nil
+}
+
+func (c clientEncryptClient) Get(offset, length int64, versionID string) (io.ReadSeeker, *probe.Error) {
+	objCipher, size, err := c.objectCipher()
+	if err != nil {
+		return nil, err.Trace(c.GetURL().String())
+	}
+	if objCipher == nil {
+		return c.Client.Get(offset, length, versionID)
+	}
+	// Metadata is only looked up for the latest version.
+	if versionID != "" {
+		return nil, probe.NewError(client.APINotImplemented{API: "Get of versions of client side encrypted objects", APIType: "s3"})
+	}
+	// Segments are decrypted whole, read from the start of the one holding offset.
+	body, err := c.Client.Get(offset/cseSegmentSize*cseSealedSize, 0, "")
+	if err != nil {
+		return nil, err.Trace(c.GetURL().String())
+	}
+	reader, e := newCSEDecryptReader(body, size, *objCipher, offset)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if length > 0 {
+		reader.end = offset + length
+	}
+	return reader, nil
+}
+
+func (c clientEncryptClient) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, *probe.Error) {
+	objCipher, size, err := c.objectCipher()
+	if err != nil {
+		return nil, err.Trace(c.GetURL().String())
+	}
+	body, err := c.Client.GetIfChanged(etag, modTime)
+	if err != nil || objCipher == nil {
+		return body, err
+	}
+	reader, e := newCSEDecryptReader(body, size, *objCipher, 0)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return reader, nil
+}
+
+func (c clientEncryptClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	urlStr := c.GetURL().String()
+	key, err := c.keyOf(urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	if key == nil {
+		return c.Client.Put(data, size, contentType, metadata)
+	}
+	if err = c.abortUploads(size); err != nil {
+		return err.Trace(urlStr)
+	}
+	objCipher, encryptMetadata, err := newCSECipher(key)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	for k, v := range metadata {
+		if _, ok := encryptMetadata[k]; !ok {
+			encryptMetadata[k] = v
+		}
+	}
+	encryptedSize := size
+	if size >= 0 {
+		encryptedSize = cseEncryptedSize(size)
+	}
+	return c.Client.Put(newCSEEncryptReader(data, size, objCipher), encryptedSize, contentType, encryptMetadata).Trace(urlStr)
+}
+
+// cseMultipartSize - uploads of this size and larger, and of unknown size, may be multipart.
+const cseMultipartSize = 5 * 1024 * 1024
+
+// abortUploads - abort incomplete uploads of the object before a multipart upload of size. A
+// resumed upload keeps the metadata it was started with, the data key of every upload is new.
+func (c clientEncryptClient) abortUploads(size int64) *probe.Error {
+	if size >= 0 && cseEncryptedSize(size) < cseMultipartSize {
+		return nil
+	}
+	return c.Client.Remove(true, "")
+}
+
+// PutStream - streams are uploaded by Put of unknown size, PutStream stores no metadata.
+func (c clientEncryptClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	urlStr := c.GetURL().String()
+	key, err := c.keyOf(urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	if key == nil {
+		return c.Client.PutStream(data, partSize, contentType)
+	}
+	if err = c.abortUploads(-1); err != nil {
+		return err.Trace(urlStr)
+	}
+	objCipher, metadata, err := newCSECipher(key)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	return c.Client.Put(newCSEEncryptReader(data, -1, objCipher), -1, contentType, metadata).Trace(urlStr)
+}
+
+// Copy - copies keep the metadata the data key of the object is sealed in, it is never replaced.
+func (c clientEncryptClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	if options.MetadataDirective == client.DirectiveReplace {
+		return probe.NewError(client.APINotImplemented{API: "Copy replacing metadata", APIType: "client side encryption"})
+	}
+	return c.Client.Copy(source, options).Trace(source.String())
+}
+
+func (c clientEncryptClient) WithContext(ctx context.Context) client.Client {
+	return clientEncryptClient{Client: c.Client.WithContext(ctx), keyOf: c.keyOf}
+}
@@ -18,13 +18,15 @@ package main
 
 import (
 	"io"
-	"os"
-	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/client/fs"
 	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/client/web"
+	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -62,7 +64,7 @@ func getSourceFromAlias(alias string, urlStr string) (reader io.ReadSeeker, err
 	if err != nil {
 		return nil, err.Trace(alias, urlStr)
 	}
-	return sourceClnt.Get(0, 0)
+	return sourceClnt.Get(0, 0, "")
 }
 
 // putTarget writes to URL from reader. If length=-1, read until EOF.
@@ -81,45 +83,283 @@ func putTargetFromAlias(alias string, urlStr string, reader io.ReadSeeker, size
 		return err.Trace(alias, urlStr)
 	}
 	contentType := guessURLContentType(urlStr)
-	err = targetClnt.Put(reader, size, contentType)
+	err = targetClnt.Put(reader, size, contentType, nil)
 	if err != nil {
 		return err.Trace(alias, urlStr)
 	}
 	return nil
 }
 
+// putTargetStream writes a stream of unknown length to URL, holding at most partSize bytes in memory.
+func putTargetStream(urlStr string, reader io.Reader, partSize int64) *probe.Error {
+	alias, urlStrFull, _, err := expandAlias(urlStr)
+	if err != nil {
+		return err
+	}
+	targetClnt, err := newClientFromAlias(alias, urlStrFull)
+	if err != nil {
+		return err.Trace(alias, urlStrFull)
+	}
+	contentType := guessURLContentType(urlStrFull)
+	if err = targetClnt.PutStream(reader, partSize, contentType); err != nil {
+		return err.Trace(alias, urlStrFull)
+	}
+	return nil
+}
+
 // newClientFromAlias gives a new client interface for matching
 // alias entry in the mc config file. If no matching host config entry
-// is found, fs client is returned.
+// is found, a web client is returned for plain http(s) URLs and fs
+// client for anything else.
 func newClientFromAlias(alias string, urlStr string) (client.Client, *probe.Error) {
 	hostCfg := mustGetHostConfig(alias)
+	if hostCfg == nil && web.IsWebURL(urlStr) {
+		webClient, err := web.New(urlStr)
+		if err != nil {
+			return nil, err.Trace(alias, urlStr)
+		}
+		return wrapClient(alias, webClient), nil
+	}
 	if hostCfg == nil {
 		// No matching host config. So we treat it like a
 		// filesystem.
-		fsClient, err := fs.New(urlStr)
+		fsClient, err := fs.NewWithSymlinks(urlStr, globalSymlinks)
+		if err != nil {
+			return nil, err.Trace(alias, urlStr)
+		}
+		return wrapClient(alias, fsClient), nil
+	}
+
+	if hostCfg.API == apiSignatureAuto {
+		api, err := negotiateAliasSignature(alias, *hostCfg)
+		if err != nil {
+			return nil, err.Trace(alias, urlStr)
+		}
+		hostCfg.API = api
+	}
+
+	// Keys of client side encryption never reach the server, they are looked up by the wrapper.
+	if globalEncryptMode == encryptModeClient {
+		s3Client, err := newS3ClientFromHost(*hostCfg, urlStr, nil)
 		if err != nil {
 			return nil, err.Trace(alias, urlStr)
 		}
-		return fsClient, nil
+		keyOf := func(objectURL string) ([]byte, *probe.Error) {
+			return getURLEncryptionKey(alias, *hostCfg, objectURL)
+		}
+		return wrapClient(alias, newClientEncryptClient(s3Client, keyOf)), nil
+	}
+
+	// We have a valid alias and hostConfig, look up the SSE-C key of the object.
+	encryptionKey, err := getURLEncryptionKey(alias, *hostCfg, urlStr)
+	if err != nil {
+		return nil, err.Trace(alias, urlStr)
 	}
+	s3Client, err := newS3ClientFromHost(*hostCfg, urlStr, encryptionKey)
+	if err != nil {
+		return nil, err.Trace(alias, urlStr)
+	}
+	return wrapClient(alias, s3Client), nil
+}
+
+// getURLEncryptionKey - SSE-C key of urlStr on the host of alias, looked up by the path below the host.
+func getURLEncryptionKey(alias string, hostCfg hostConfigV7, urlStr string) ([]byte, *probe.Error) {
+	objectPath := strings.TrimPrefix(client.NewURL(urlStr).Path, client.NewURL(hostCfg.URL).Path)
+	return getEncryptionKey(alias, objectPath)
+}
 
-	// We have a valid alias and hostConfig. We populate the
-	// credentials from the match found in the config file.
+// negotiatedSignatures - signature versions negotiated for ‘auto’ aliases so far.
+var negotiatedSignatures = struct {
+	sync.Mutex
+	apis map[string]string
+}{apis: make(map[string]string)}
+
+// negotiateAliasSignature - signature version the host of an ‘auto’ alias accepts.
+// It is recorded in the alias, so later runs do not negotiate again.
+func negotiateAliasSignature(alias string, hostCfg hostConfigV7) (string, *probe.Error) {
+	negotiatedSignatures.Lock()
+	defer negotiatedSignatures.Unlock()
+	if api, ok := negotiatedSignatures.apis[alias]; ok {
+		return api, nil
+	}
+	s3Config, err := newS3Config(hostCfg, hostCfg.URL, nil)
+	if err != nil {
+		return "", err.Trace(alias)
+	}
+	api, err := s3.NegotiateSignature(s3Config)
+	if err != nil {
+		return "", err.Trace(alias, hostCfg.URL)
+	}
+	negotiatedSignatures.apis[alias] = api
+	hostCfg.API = api
+	errorIf(setAlias(alias, hostCfg).Trace(alias, api),
+		"Unable to record signature ‘"+api+"’ for alias ‘"+alias+"’ in config ‘"+mustGetMcConfigPath()+"’.")
+	return api, nil
+}
+
+// newS3ClientFromHost - s3 client for urlStr, credentials and settings are
+// populated from the host config. Objects are encrypted with encryptionKey if set.
+func newS3ClientFromHost(hostCfg hostConfigV7, urlStr string, encryptionKey []byte) (client.Client, *probe.Error) {
+	s3Config, err := newS3Config(hostCfg, urlStr, encryptionKey)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	if s3Config.Signature == apiSignatureAuto {
+		if s3Config.Signature, err = s3.NegotiateSignature(s3Config); err != nil {
+			return nil, err.Trace(urlStr)
+		}
+	}
+	s3Client, err := s3.New(s3Config)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	return s3Client, nil
+}
+
+// warnClockSkew - warn the local clock is off by offset from the clock of endpoint, requests are corrected.
+func warnClockSkew(endpoint string, offset time.Duration) {
+	err := errClockSkewed(endpoint, offset)
+	if globalJSON {
+		printErrorJSON(err, "Request times are corrected.", "warning", exitFailure)
+		return
+	}
+	console.Errorln(err.ToGoError().Error() + " Request times are corrected.")
+}
+
+// verifiedRegions - buckets whose region was verified so far, by alias and bucket.
+var verifiedRegions = struct {
+	sync.Mutex
+	buckets map[string]bool
+}{buckets: make(map[string]bool)}
+
+// verifyAliasRegion - fail fast if requests for the bucket of aliasedURL are signed for
+// a region the bucket is not in, rather than with signature errors deep into a job.
+// Each bucket is verified once, URLs of no alias are not verified.
+func verifyAliasRegion(aliasedURL string) *probe.Error {
+	alias, urlStr, hostCfg, err := expandAlias(aliasedURL)
+	if err != nil || hostCfg == nil {
+		return nil
+	}
+	_, path := url2Alias(aliasedURL)
+	bucket := strings.SplitN(path, "/", 2)[0]
+	if bucket == "" {
+		return nil
+	}
+	verifiedRegions.Lock()
+	defer verifiedRegions.Unlock()
+	if verifiedRegions.buckets[alias+"/"+bucket] {
+		return nil
+	}
+	clnt, err := newS3ClientFromHost(*hostCfg, urlStr, nil)
+	if err != nil {
+		return nil
+	}
+	verifier, ok := clnt.(interface {
+		VerifyRegion() *probe.Error
+	})
+	if !ok {
+		return nil
+	}
+	if err = verifier.VerifyRegion(); err != nil {
+		if mismatch, ok := err.ToGoError().(client.RegionMismatch); ok {
+			if globalRegion != "" {
+				return errRegionFlagMismatch(mismatch.Bucket, mismatch.Region, mismatch.SigningRegion).Trace(aliasedURL)
+			}
+			return errRegionMismatch(alias, mismatch.Bucket, mismatch.Region, mismatch.SigningRegion).Trace(aliasedURL)
+		}
+		return err.Trace(aliasedURL)
+	}
+	verifiedRegions.buckets[alias+"/"+bucket] = true
+	return nil
+}
+
+// newS3Config - s3 client config for urlStr populated from the host config.
+func newS3Config(hostCfg hostConfigV7, urlStr string, encryptionKey []byte) (*client.Config, *probe.Error) {
 	s3Config := new(client.Config)
 	s3Config.AccessKey = hostCfg.AccessKey
 	s3Config.SecretKey = hostCfg.SecretKey
 	s3Config.Signature = hostCfg.API
 	s3Config.AppName = "mc"
 	s3Config.AppVersion = mcVersion
-	s3Config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+	s3Config.AppComments = mcAppComments()
+	s3Config.UserAgentSuffix = globalUserAgentSuffix
+	if !globalNoCorrelationID {
+		s3Config.CorrelationID = globalCorrelationID
+	}
 	s3Config.HostURL = urlStr
 	s3Config.Debug = globalDebug
+	s3Config.DebugCompact = globalDebugFormat == debugFormatCompact
+	if globalAudit != nil {
+		s3Config.Audit = globalAudit
+	}
+	if globalTransferStats != nil {
+		s3Config.Phases = globalTransferStats
+	}
+	s3Config.Region = hostCfg.Region
+	if globalRegion != "" {
+		s3Config.Region = globalRegion
+	}
+	s3Config.PartConcurrency = globalPartConcurrency
+	s3Config.KeepAliveTimeout = globalKeepAliveTimeout
+	s3Config.MaxConnsPerHost = globalMaxConnsPerHost
+	s3Config.DisableDNSCache = globalNoDNSCache
+	s3Config.ClockSkewed = warnClockSkew
+	s3Config.DisableMultipart = globalDisableMultipart || hostCfg.DisableMultipart
+	if s3Config.Accelerate = hostCfg.Accelerate; s3Config.Accelerate {
+		checkAccelerateBucket(urlStr)
+	}
+	s3Config.StorageClass = globalStorageClass
+	s3Config.CannedACL = globalCannedACL
+	s3Config.MetadataCharset = globalMetadataCharset
+	s3Config.ChecksumAlgorithm = globalChecksumAlgorithm
+	s3Config.EncryptionKey = encryptionKey
+	// Path of the host URL, if any, is the base path S3 is served under.
+	s3Config.BasePath = client.NewURL(hostCfg.URL).Path
+	if err := setHostTimeouts(s3Config, hostCfg); err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	return s3Config, nil
+}
 
-	s3Client, err := s3.New(s3Config)
-	if err != nil {
-		return nil, err.Trace(alias, urlStr)
+// wrapClient applies the client wrappers requested by global flags.
+func wrapClient(alias string, clnt client.Client) client.Client {
+	if globalDryRun {
+		clnt = newDryRunClient(alias, clnt)
 	}
-	return s3Client, nil
+	if globalStatCache != nil {
+		clnt = newStatCacheClient(clnt, globalStatCache)
+	}
+	return clnt
+}
+
+// setHostTimeouts populates client timeouts, timeouts set on the command
+// line take precedence over the ones configured for the host. Unset
+// timeouts are left to client defaults.
+func setHostTimeouts(s3Config *client.Config, hostCfg hostConfigV7) *probe.Error {
+	timeouts := []struct {
+		global time.Duration
+		host   string
+		value  *time.Duration
+	}{
+		{globalConnTimeout, hostCfg.ConnTimeout, &s3Config.ConnTimeout},
+		{globalReadTimeout, hostCfg.ReadTimeout, &s3Config.ReadTimeout},
+		{globalIdleTimeout, hostCfg.IdleTimeout, &s3Config.IdleTimeout},
+	}
+	for _, t := range timeouts {
+		if t.global > 0 {
+			*t.value = t.global
+			continue
+		}
+		if t.host == "" {
+			continue
+		}
+		d, e := time.ParseDuration(t.host)
+		if e != nil {
+			return probe.NewError(e).Trace(hostCfg.URL, t.host)
+		}
+		*t.value = d
+	}
+	return nil
 }
 
 // newClient gives a new client interface
@@ -0,0 +1,26 @@
+// +build !linux,!darwin,!freebsd,!openbsd,!netbsd,!windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "errors"
+
+// disableEcho - not supported on this platform, input is echoed.
+func disableEcho(fd uintptr) (restore func(), e error) {
+	return nil, errors.New("disabling terminal echo is not supported")
+}
@@ -0,0 +1,47 @@
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+// Console input mode flag echoing characters read.
+const enableEchoInput = 0x0004
+
+// disableEcho - stop the console at fd from echoing input, until restore is called.
+func disableEcho(fd uintptr) (restore func(), e error) {
+	var mode uint32
+	if r, _, errno := syscall.Syscall(procGetConsoleMode.Addr(), 2, fd, uintptr(unsafe.Pointer(&mode)), 0); r == 0 {
+		return nil, errno
+	}
+	if r, _, errno := syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(mode&^enableEchoInput), 0); r == 0 {
+		return nil, errno
+	}
+	return func() {
+		syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(mode), 0)
+	}, nil
+}
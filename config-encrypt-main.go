@@ -0,0 +1,138 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	configEncryptFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of config encrypt and decrypt.",
+		},
+	}
+)
+
+var configEncryptCmd = cli.Command{
+	Name:   "encrypt",
+	Usage:  "Encrypt access and secret keys of all hosts in the config with a passphrase.",
+	Flags:  append(configEncryptFlags, globalFlags...),
+	Action: mainConfigEncrypt,
+	CustomHelpTemplate: `NAME:
+   mc config {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc config {{.Name}}
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+PASSPHRASE:
+   The passphrase is read from MC_CONFIG_PASSPHRASE, or asked for on a terminal. Every command
+   reading keys of an encrypted config needs it, hosts added later are encrypted as well. Keys
+   are encrypted with AES-256-GCM under a key derived from the passphrase with scrypt.
+
+EXAMPLES:
+   1. Encrypt the keys in the config, entering the passphrase on the terminal.
+      $ mc config {{.Name}}
+
+   2. List a bucket with the keys of an encrypted config, from a script.
+      $ MC_CONFIG_PASSPHRASE="$(cat ~/.mc-passphrase)" mc ls s3/backup
+`,
+}
+
+var configDecryptCmd = cli.Command{
+	Name:   "decrypt",
+	Usage:  "Decrypt access and secret keys of all hosts in the config, they are stored in plain text again.",
+	Flags:  append(configEncryptFlags, globalFlags...),
+	Action: mainConfigDecrypt,
+	CustomHelpTemplate: `NAME:
+   mc config {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc config {{.Name}}
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Decrypt the keys in the config, with the passphrase in the environment.
+      $ MC_CONFIG_PASSPHRASE=secret mc config {{.Name}}
+`,
+}
+
+// configEncryptMessage container for config encryption messages.
+type configEncryptMessage struct {
+	op     string
+	Status string `json:"status"`
+	Config string `json:"config"`
+}
+
+// String colorized config encryption message.
+func (m configEncryptMessage) String() string {
+	return console.Colorize("ConfigEncrypt", m.op+"ed config ‘"+m.Config+"’ successfully.")
+}
+
+// JSON jsonified config encryption message.
+func (m configEncryptMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.Marshal(m)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// mainConfigEncrypt - encrypt the keys of the config.
+func mainConfigEncrypt(ctx *cli.Context) {
+	setGlobalsFromContext(ctx)
+	if ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "encrypt", 1) // last argument is exit code
+	}
+	console.SetColor("ConfigEncrypt", color.New(color.FgGreen))
+
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(), "Unable to load config ‘"+mustGetMcConfigPath()+"’.")
+	passphrase, err := readConfigPassphrase(true)
+	fatalIf(err.Trace(), "Unable to read the passphrase.")
+	fatalIf(encryptConfig(mcCfg, passphrase).Trace(), "Unable to encrypt config ‘"+mustGetMcConfigPath()+"’.")
+	fatalIf(saveMcConfig(mcCfg).Trace(), "Unable to save config ‘"+mustGetMcConfigPath()+"’.")
+
+	printMsg(configEncryptMessage{op: "Encrypt", Config: mustGetMcConfigPath()})
+}
+
+// mainConfigDecrypt - decrypt the keys of the config.
+func mainConfigDecrypt(ctx *cli.Context) {
+	setGlobalsFromContext(ctx)
+	if ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "decrypt", 1) // last argument is exit code
+	}
+	console.SetColor("ConfigEncrypt", color.New(color.FgGreen))
+
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(), "Unable to load config ‘"+mustGetMcConfigPath()+"’.")
+	fatalIf(decryptConfig(mcCfg).Trace(), "Unable to decrypt config ‘"+mustGetMcConfigPath()+"’.")
+	fatalIf(saveMcConfig(mcCfg).Trace(), "Unable to save config ‘"+mustGetMcConfigPath()+"’.")
+
+	printMsg(configEncryptMessage{op: "Decrypt", Config: mustGetMcConfigPath()})
+}
@@ -0,0 +1,349 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/minio/minio-xl/pkg/probe"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Secrets of an encrypted config are prefixed by this, followed by the base64 encoded nonce and
+// AES-256-GCM sealed secret. Secrets without it are plain, such as those of hosts added since.
+const encryptedSecretPrefix = "enc:"
+
+// Passphrase of an encrypted config, asked for on a terminal if not set.
+const configPassphraseEnv = "MC_CONFIG_PASSPHRASE"
+
+// Value sealed into configEncryption.Verifier, opening it tells whether a passphrase is right.
+const configVerifierValue = "mc config"
+
+// Cost parameters of scrypt for new encrypted configs, as recommended for interactive logins.
+const (
+	configScryptN = 32768
+	configScryptR = 8
+	configScryptP = 1
+)
+
+// configEncryption - how the secrets of a config are encrypted. The key is derived from a
+// passphrase with scrypt and Salt, secrets are sealed with AES-256-GCM.
+type configEncryption struct {
+	KDF      string `json:"kdf"`
+	Salt     string `json:"salt"`
+	N        int    `json:"n"`
+	R        int    `json:"r"`
+	P        int    `json:"p"`
+	Cipher   string `json:"cipher"`
+	Verifier string `json:"verifier"`
+}
+
+// configKeyCache - key derived last, scrypt is slow on purpose. A key is only reused for the same
+// salt and, if one was set, passphrase in the environment.
+var configKeyCache struct {
+	mutex      sync.Mutex
+	salt       string
+	passphrase string
+	key        []byte
+}
+
+// readConfigPassphrase - passphrase of the config from the environment, or asked for on a
+// terminal, twice if isNew to rule out typos.
+func readConfigPassphrase(isNew bool) (string, *probe.Error) {
+	if passphrase := os.Getenv(configPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", errConfigPassphraseMissing().Trace()
+	}
+	ask := func(prompt string) string {
+		fmt.Fprint(os.Stderr, prompt)
+		return readPassphraseLine(os.Stdin)
+	}
+	passphrase := ask("Config passphrase: ")
+	if passphrase == "" {
+		return "", errConfigPassphraseMissing().Trace()
+	}
+	if isNew && ask("Repeat passphrase: ") != passphrase {
+		return "", errInvalidArgument().Trace()
+	}
+	return passphrase, nil
+}
+
+// readPassphraseLine - a line typed on the terminal, not echoed where the platform allows. It
+// is read byte by byte, nothing typed ahead for a following prompt is consumed.
+func readPassphraseLine(terminal *os.File) string {
+	if restore, e := disableEcho(terminal.Fd()); e == nil {
+		defer fmt.Fprintln(os.Stderr)
+		defer restore()
+	}
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, e := terminal.Read(b)
+		if n == 1 && b[0] == '\n' {
+			break
+		}
+		if n == 1 {
+			line = append(line, b[0])
+		}
+		if e != nil {
+			break
+		}
+	}
+	return strings.TrimRight(string(line), "\r")
+}
+
+// newConfigEncryption - encryption of a config under passphrase with a new random salt.
+func newConfigEncryption(passphrase string) (*configEncryption, []byte, *probe.Error) {
+	salt := make([]byte, 32)
+	if _, e := rand.Read(salt); e != nil {
+		return nil, nil, probe.NewError(e)
+	}
+	enc := &configEncryption{
+		KDF:    "scrypt",
+		Salt:   base64.StdEncoding.EncodeToString(salt),
+		N:      configScryptN,
+		R:      configScryptR,
+		P:      configScryptP,
+		Cipher: "AES-256-GCM",
+	}
+	key, err := enc.deriveKey(passphrase)
+	if err != nil {
+		return nil, nil, err.Trace()
+	}
+	if enc.Verifier, err = sealSecret(key, configVerifierValue); err != nil {
+		return nil, nil, err.Trace()
+	}
+	// Saving the config right after does not ask for the passphrase again.
+	configKeyCache.mutex.Lock()
+	configKeyCache.salt, configKeyCache.passphrase, configKeyCache.key = enc.Salt, os.Getenv(configPassphraseEnv), key
+	configKeyCache.mutex.Unlock()
+	return enc, key, nil
+}
+
+// deriveKey - AES-256 key of passphrase.
+func (enc *configEncryption) deriveKey(passphrase string) ([]byte, *probe.Error) {
+	if enc.KDF != "scrypt" || enc.Cipher != "AES-256-GCM" {
+		return nil, errConfigEncryption(enc.KDF + " and " + enc.Cipher + " are not supported").Trace()
+	}
+	// Cost parameters come from the config file, costlier ones than mc uses would stall every command.
+	if enc.N > configScryptN || enc.R > configScryptR || enc.P > configScryptP {
+		return nil, errConfigEncryption("scrypt parameters above n=32768, r=8 and p=1 are not supported").Trace()
+	}
+	salt, e := base64.StdEncoding.DecodeString(enc.Salt)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	key, e := scrypt.Key([]byte(passphrase), salt, enc.N, enc.R, enc.P, 32)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return key, nil
+}
+
+// key - key of the config, from the passphrase in the environment or asked for. A wrong passphrase
+// fails, it never gives a key which decrypts secrets to garbage.
+func (enc *configEncryption) key() ([]byte, *probe.Error) {
+	configKeyCache.mutex.Lock()
+	defer configKeyCache.mutex.Unlock()
+
+	envPassphrase := os.Getenv(configPassphraseEnv)
+	if configKeyCache.key != nil && configKeyCache.salt == enc.Salt && configKeyCache.passphrase == envPassphrase {
+		return configKeyCache.key, nil
+	}
+	passphrase, err := readConfigPassphrase(false)
+	if err != nil {
+		return nil, err.Trace()
+	}
+	key, err := enc.deriveKey(passphrase)
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if value, err := openSecret(key, enc.Verifier); err != nil || value != configVerifierValue {
+		return nil, errConfigPassphraseWrong().Trace()
+	}
+	configKeyCache.salt, configKeyCache.passphrase, configKeyCache.key = enc.Salt, envPassphrase, key
+	return key, nil
+}
+
+// sealSecret - secret encrypted with key, in the form kept in the config.
+func sealSecret(key []byte, secret string) (string, *probe.Error) {
+	gcm, err := newConfigGCM(key)
+	if err != nil {
+		return "", err.Trace()
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, e := rand.Read(nonce); e != nil {
+		return "", probe.NewError(e)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret - secret decrypted with key from the form kept in the config, values which are not
+// encrypted are returned as they are. Fails if the secret was not sealed with key.
+func openSecret(key []byte, value string) (string, *probe.Error) {
+	if !strings.HasPrefix(value, encryptedSecretPrefix) {
+		return value, nil
+	}
+	gcm, err := newConfigGCM(key)
+	if err != nil {
+		return "", err.Trace()
+	}
+	sealed, e := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedSecretPrefix))
+	if e != nil || len(sealed) < gcm.NonceSize() {
+		return "", errConfigPassphraseWrong().Trace()
+	}
+	secret, e := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if e != nil {
+		return "", errConfigPassphraseWrong().Trace()
+	}
+	return string(secret), nil
+}
+
+// newConfigGCM - AES-256-GCM of key.
+func newConfigGCM(key []byte) (cipher.AEAD, *probe.Error) {
+	block, e := aes.NewCipher(key)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	gcm, e := cipher.NewGCM(block)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return gcm, nil
+}
+
+// sealHostConfig - hostCfg with its plain access and secret key encrypted with key.
+func sealHostConfig(key []byte, hostCfg hostConfigV7) (hostConfigV7, *probe.Error) {
+	var err *probe.Error
+	for _, secret := range []*string{&hostCfg.AccessKey, &hostCfg.SecretKey} {
+		if *secret == "" || strings.HasPrefix(*secret, encryptedSecretPrefix) {
+			continue
+		}
+		if *secret, err = sealSecret(key, *secret); err != nil {
+			return hostCfg, err.Trace()
+		}
+	}
+	return hostCfg, nil
+}
+
+// openHostConfig - hostCfg with its access and secret key decrypted with key.
+func openHostConfig(key []byte, hostCfg hostConfigV7) (hostConfigV7, *probe.Error) {
+	var err *probe.Error
+	for _, secret := range []*string{&hostCfg.AccessKey, &hostCfg.SecretKey} {
+		if *secret, err = openSecret(key, *secret); err != nil {
+			return hostCfg, err.Trace()
+		}
+	}
+	return hostCfg, nil
+}
+
+// decryptHostConfig - hostCfg of conf with its secrets decrypted, as is if conf is not encrypted.
+func decryptHostConfig(conf *configV7, hostCfg hostConfigV7) (hostConfigV7, *probe.Error) {
+	if conf.Encryption == nil {
+		return hostCfg, nil
+	}
+	key, err := conf.Encryption.key()
+	if err != nil {
+		return hostCfg, err.Trace()
+	}
+	return openHostConfig(key, hostCfg)
+}
+
+// mustDecryptHostConfig - decryptHostConfig, exits if the secrets can not be decrypted.
+func mustDecryptHostConfig(conf *configV7, hostCfg hostConfigV7) hostConfigV7 {
+	hostCfg, err := decryptHostConfig(conf, hostCfg)
+	fatalIf(err.Trace(), "Unable to decrypt config ‘"+mustGetMcConfigPath()+"’.")
+	return hostCfg
+}
+
+// encryptConfig - encrypt the secrets of all hosts of conf under passphrase.
+func encryptConfig(conf *configV7, passphrase string) *probe.Error {
+	if conf.Encryption != nil {
+		return errConfigEncryption("it is encrypted already").Trace()
+	}
+	enc, key, err := newConfigEncryption(passphrase)
+	if err != nil {
+		return err.Trace()
+	}
+	hosts := make(map[string]hostConfigV7)
+	for alias, hostCfg := range conf.Hosts {
+		if hosts[alias], err = sealHostConfig(key, hostCfg); err != nil {
+			return err.Trace(alias)
+		}
+	}
+	conf.Hosts, conf.Encryption = hosts, enc
+	return nil
+}
+
+// decryptConfig - decrypt the secrets of all hosts of conf, they are kept in plain text again.
+func decryptConfig(conf *configV7) *probe.Error {
+	if conf.Encryption == nil {
+		return errConfigEncryption("it is not encrypted").Trace()
+	}
+	key, err := conf.Encryption.key()
+	if err != nil {
+		return err.Trace()
+	}
+	hosts := make(map[string]hostConfigV7)
+	for alias, hostCfg := range conf.Hosts {
+		if hosts[alias], err = openHostConfig(key, hostCfg); err != nil {
+			return err.Trace(alias)
+		}
+	}
+	conf.Hosts, conf.Encryption = hosts, nil
+	return nil
+}
+
+// isConfigSealed - reports if no access or secret key of conf is kept in plain text.
+func isConfigSealed(conf *configV7) bool {
+	for _, hostCfg := range conf.Hosts {
+		for _, secret := range []string{hostCfg.AccessKey, hostCfg.SecretKey} {
+			if secret != "" && !strings.HasPrefix(secret, encryptedSecretPrefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sealConfig - encrypt secrets of conf added in plain text since it was encrypted, before saving it.
+func sealConfig(conf *configV7) *probe.Error {
+	if conf.Encryption == nil || isConfigSealed(conf) {
+		return nil
+	}
+	key, err := conf.Encryption.key()
+	if err != nil {
+		return err.Trace()
+	}
+	for alias, hostCfg := range conf.Hosts {
+		if conf.Hosts[alias], err = sealHostConfig(key, hostCfg); err != nil {
+			return err.Trace(alias)
+		}
+	}
+	return nil
+}
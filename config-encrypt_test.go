@@ -0,0 +1,138 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// reloadMcConfig - drop cached config and keys, the next access reads the config file again.
+func reloadMcConfig() {
+	cacheCfgV7 = nil
+	loadMcConfig = loadMcConfigFactory()
+	configKeyCache.key = nil
+}
+
+func (s *TestSuite) TestConfigEncryptRoundTrip(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	defer os.Unsetenv(configPassphraseEnv)
+	defer reloadMcConfig()
+
+	secretKey := "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12"
+	c.Assert(setAlias("enc", hostConfigV7{URL: "https://s3.amazonaws.com", AccessKey: "BKIKJAA5BMMU2RHO6IBB", SecretKey: secretKey, API: "S3v4"}), IsNil)
+
+	c.Assert(os.Setenv(configPassphraseEnv, "correct horse"), IsNil)
+	mcCfg, err := loadMcConfig()
+	c.Assert(err, IsNil)
+	c.Assert(encryptConfig(mcCfg, "correct horse"), IsNil)
+	c.Assert(saveMcConfig(mcCfg), IsNil)
+	// Encrypting twice fails.
+	c.Assert(encryptConfig(mcCfg, "correct horse"), NotNil)
+
+	// Keys are not kept in plain text, they are decrypted when read.
+	data, e := ioutil.ReadFile(mustGetMcConfigPath())
+	c.Assert(e, IsNil)
+	c.Assert(strings.Contains(string(data), secretKey), Equals, false)
+	c.Assert(strings.Contains(string(data), "BKIKJAA5BMMU2RHO6IBB"), Equals, false)
+	reloadMcConfig()
+	hostCfg, err := getHostConfig("enc")
+	c.Assert(err, IsNil)
+	c.Assert(hostCfg.AccessKey, Equals, "BKIKJAA5BMMU2RHO6IBB")
+	c.Assert(hostCfg.SecretKey, Equals, secretKey)
+
+	// Hosts added later are encrypted as well.
+	c.Assert(setAlias("later", hostConfigV7{URL: "https://play.minio.io:9000", AccessKey: "Q3AM3UQ867SPQQA43P2F", SecretKey: "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG", API: "S3v4"}), IsNil)
+	data, e = ioutil.ReadFile(mustGetMcConfigPath())
+	c.Assert(e, IsNil)
+	c.Assert(strings.Contains(string(data), "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG"), Equals, false)
+
+	// Decrypting keeps the keys in plain text again.
+	reloadMcConfig()
+	mcCfg, err = loadMcConfig()
+	c.Assert(err, IsNil)
+	c.Assert(decryptConfig(mcCfg), IsNil)
+	c.Assert(saveMcConfig(mcCfg), IsNil)
+	c.Assert(decryptConfig(mcCfg), NotNil)
+	data, e = ioutil.ReadFile(mustGetMcConfigPath())
+	c.Assert(e, IsNil)
+	c.Assert(strings.Contains(string(data), secretKey), Equals, true)
+	c.Assert(strings.Contains(string(data), "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG"), Equals, true)
+	c.Assert(strings.Contains(string(data), "encryption"), Equals, false)
+}
+
+func (s *TestSuite) TestConfigEncryptWrongPassphrase(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	defer os.Unsetenv(configPassphraseEnv)
+	defer reloadMcConfig()
+
+	c.Assert(setAlias("enc", hostConfigV7{URL: "https://s3.amazonaws.com", AccessKey: "BKIKJAA5BMMU2RHO6IBB", SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12", API: "S3v4"}), IsNil)
+	mcCfg, err := loadMcConfig()
+	c.Assert(err, IsNil)
+	c.Assert(encryptConfig(mcCfg, "correct horse"), IsNil)
+	c.Assert(saveMcConfig(mcCfg), IsNil)
+
+	// A wrong passphrase fails clearly, it never gives garbage keys.
+	c.Assert(os.Setenv(configPassphraseEnv, "battery staple"), IsNil)
+	reloadMcConfig()
+	mcCfg, err = loadMcConfig()
+	c.Assert(err, IsNil)
+	_, err = decryptHostConfig(mcCfg, mcCfg.Hosts["enc"])
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError().Error(), Equals, errConfigPassphraseWrong().ToGoError().Error())
+	c.Assert(decryptConfig(mcCfg), NotNil)
+	// Looking up the alias fails as well, without exiting.
+	_, err = getHostConfig("enc")
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError().Error(), Equals, errConfigPassphraseWrong().ToGoError().Error())
+
+	// A secret tampered with fails to decrypt even with the right passphrase.
+	c.Assert(os.Setenv(configPassphraseEnv, "correct horse"), IsNil)
+	hostCfg := mcCfg.Hosts["enc"]
+	hostCfg.SecretKey = hostCfg.SecretKey[:len(hostCfg.SecretKey)-4] + "AAAA"
+	_, err = decryptHostConfig(mcCfg, hostCfg)
+	c.Assert(err, NotNil)
+	hostCfg, err = decryptHostConfig(mcCfg, mcCfg.Hosts["enc"])
+	c.Assert(err, IsNil)
+	c.Assert(hostCfg.SecretKey, Equals, "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12")
+}
+
+func (s *TestSuite) TestConfigEncryptCostLimits(c *C) {
+	enc := configEncryption{
+		KDF:    "scrypt",
+		Salt:   "c2FsdA==",
+		N:      configScryptN,
+		R:      configScryptR,
+		P:      configScryptP,
+		Cipher: "AES-256-GCM",
+	}
+	_, err := enc.deriveKey("correct horse")
+	c.Assert(err, IsNil)
+
+	// A config edited to cost more than new configs do is rejected before scrypt runs.
+	for _, costs := range [][3]int{{1 << 30, 8, 1}, {32768, 1 << 20, 1}, {32768, 8, 1 << 20}} {
+		costly := enc
+		costly.N, costly.R, costly.P = costs[0], costs[1], costs[2]
+		_, err = costly.deriveKey("correct horse")
+		c.Assert(err, NotNil, Commentf("%v", costs))
+	}
+}
@@ -19,7 +19,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
@@ -33,6 +37,18 @@ var (
 			Name:  "help, h",
 			Usage: "Help of config host",
 		},
+		cli.BoolFlag{
+			Name:  "redact",
+			Usage: "Leave out secret keys while exporting.",
+		},
+		cli.BoolFlag{
+			Name:  "merge",
+			Usage: "Keep existing hosts on conflicts while importing, this is the default.",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Replace existing hosts on conflicts while importing.",
+		},
 	}
 )
 
@@ -51,6 +67,8 @@ OPERATION:
    add ALIAS URL ACCESS-KEY SECRET-KEY [API]
    remove ALIAS
    list
+   export [FILE]
+   import FILE
 
 FLAGS:
   {{range .Flags}}{{.}}
@@ -69,6 +87,12 @@ EXAMPLES:
 
    4. Remove "goodisk" config.
       $ mc config {{.Name}} remove goodisk
+
+   5. Export all hosts without their secret keys, to move them to another machine.
+      $ mc config {{.Name}} export --redact hosts.json
+
+   6. Import hosts, replacing existing hosts with the same alias.
+      $ mc config {{.Name}} import --overwrite hosts.json
 `,
 }
 
@@ -126,9 +150,28 @@ func checkConfigHostSyntax(ctx *cli.Context) {
 	case "remove":
 		checkConfigHostRemoveSyntax(ctx)
 	case "list":
+	case "export":
+		if len(ctx.Args().Tail()) > 1 {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().Tail()...),
+				"Incorrect number of arguments for host export command.")
+		}
+	case "import":
+		if len(ctx.Args().Tail()) != 1 {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().Tail()...),
+				"Incorrect number of arguments for host import command.")
+		}
+		if ctx.Bool("merge") && ctx.Bool("overwrite") {
+			fatalIf(errInvalidArgument().Trace(), "‘--merge’ and ‘--overwrite’ can not be used together.")
+		}
 	default:
 		cli.ShowCommandHelpAndExit(ctx, "host", 1) // last argument is exit code
 	}
+	if ctx.Bool("redact") && strings.TrimSpace(ctx.Args().First()) != "export" {
+		fatalIf(errInvalidArgument().Trace(), "‘--redact’ is only allowed with export.")
+	}
+	if (ctx.Bool("merge") || ctx.Bool("overwrite")) && strings.TrimSpace(ctx.Args().First()) != "import" {
+		fatalIf(errInvalidArgument().Trace(), "‘--merge’ and ‘--overwrite’ are only allowed with import.")
+	}
 }
 
 // checkConfigHostAddSyntax - verifies input arguments to 'config host add'.
@@ -166,10 +209,10 @@ func checkConfigHostAddSyntax(ctx *cli.Context) {
 	}
 
 	switch api {
-	case "S3v2", "S3v4", "":
+	case "S3v2", "S3v4", apiSignatureAuto, "":
 	default:
 		fatalIf(errInvalidArgument().Trace(api),
-			"Unrecognized API signature. Valid options are ‘[ S3v4, S3v2 ]’.")
+			"Unrecognized API signature. Valid options are ‘[ S3v4, S3v2, auto ]’.")
 	}
 }
 
@@ -229,6 +272,10 @@ func mainConfigHost(ctx *cli.Context) {
 		removeHost(alias) // Remove a host.
 	case "list":
 		listHosts() // List all configured hosts.
+	case "export":
+		exportHostsToFile(args.Get(0), ctx.Bool("redact"))
+	case "import":
+		importHostsFromFile(args.Get(0), ctx.Bool("overwrite"))
 	}
 }
 
@@ -273,6 +320,7 @@ func listHosts() {
 	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config version ‘"+globalMCConfigVersion+"’.")
 
 	for k, v := range conf.Hosts {
+		v = mustDecryptHostConfig(conf, v)
 		printMsg(hostMessage{
 			op:        "list",
 			Alias:     k,
@@ -283,3 +331,178 @@ func listHosts() {
 		})
 	}
 }
+
+// hostImportMessage container for the outcome of importing a single host.
+type hostImportMessage struct {
+	Status string `json:"status"`
+	Alias  string `json:"alias"`
+	Result string `json:"result"` // added, replaced, unchanged, skipped or invalid.
+	Reason string `json:"reason,omitempty"`
+}
+
+// String colorized host import message.
+func (h hostImportMessage) String() string {
+	message := console.Colorize("Alias", h.Alias+": ") + console.Colorize("HostMessage", h.Result)
+	if h.Reason != "" {
+		message += ", " + h.Reason
+	}
+	return message
+}
+
+// JSON jsonified host import message.
+func (h hostImportMessage) JSON() string {
+	h.Status = "success"
+	if h.Result == "invalid" {
+		h.Status = "error"
+	}
+	jsonMessageBytes, e := json.Marshal(h)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// exportHosts - copy of all hosts in conf, secret keys are left out if redact is set.
+func exportHosts(conf *configV7, redact bool) *configV7 {
+	exported := newConfigV7()
+	for alias, hostCfg := range conf.Hosts {
+		hostCfg = mustDecryptHostConfig(conf, hostCfg)
+		if redact {
+			hostCfg.SecretKey = ""
+		}
+		exported.Hosts[alias] = hostCfg
+	}
+	return exported
+}
+
+// validateHostConfig - reason why hostCfg can not be imported under alias, empty if valid.
+func validateHostConfig(alias string, hostCfg hostConfigV7) string {
+	if !isValidAlias(alias) {
+		return "invalid alias"
+	}
+	if !isValidHostURL(hostCfg.URL) {
+		return "invalid URL ‘" + hostCfg.URL + "’"
+	}
+	if !isValidAccessKey(hostCfg.AccessKey) {
+		return "invalid access key"
+	}
+	if !isValidSecretKey(hostCfg.SecretKey) {
+		return "invalid secret key"
+	}
+	if _, ok := normalizeAPISignature(hostCfg.API); !ok {
+		return "unrecognized API signature ‘" + hostCfg.API + "’"
+	}
+	for _, timeout := range []string{hostCfg.ConnTimeout, hostCfg.ReadTimeout, hostCfg.IdleTimeout} {
+		if timeout == "" {
+			continue
+		}
+		if _, e := time.ParseDuration(timeout); e != nil {
+			return "invalid timeout ‘" + timeout + "’"
+		}
+	}
+	return ""
+}
+
+// importHosts - merge hosts of imported into conf, returns the outcome for every imported host.
+// Existing hosts are replaced on conflicts only if overwrite is set. A redacted host keeps the
+// secret key of the existing host with the same URL and access key.
+func importHosts(conf, imported *configV7, overwrite bool) []hostImportMessage {
+	var aliases []string
+	for alias := range imported.Hosts {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var msgs []hostImportMessage
+	for _, alias := range aliases {
+		hostCfg := imported.Hosts[alias]
+		if reason := validateHostConfig(alias, hostCfg); reason != "" {
+			msgs = append(msgs, hostImportMessage{Alias: alias, Result: "invalid", Reason: reason})
+			continue
+		}
+		hostCfg.API, _ = normalizeAPISignature(hostCfg.API)
+
+		existing, exists := conf.Hosts[alias]
+		if exists {
+			existing = mustDecryptHostConfig(conf, existing)
+		}
+		redacted := hostCfg.AccessKey != "" && hostCfg.SecretKey == ""
+		if redacted && exists && existing.URL == hostCfg.URL && existing.AccessKey == hostCfg.AccessKey {
+			hostCfg.SecretKey = existing.SecretKey
+			redacted = false
+		}
+		msg := hostImportMessage{Alias: alias}
+		switch {
+		case exists && existing == hostCfg:
+			msg.Result = "unchanged"
+		case exists && !overwrite:
+			msg.Result = "skipped"
+			msg.Reason = "conflicts with the existing host, use ‘--overwrite’ to replace it"
+		case exists:
+			msg.Result = "replaced"
+		default:
+			msg.Result = "added"
+		}
+		if msg.Result == "added" || msg.Result == "replaced" {
+			conf.Hosts[alias] = hostCfg
+			if redacted {
+				msg.Reason = "secret key was redacted, set it with ‘mc config host add’"
+			}
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// exportHostsToFile - write all hosts as JSON to filename, standard output if filename is empty.
+func exportHostsToFile(filename string, redact bool) {
+	conf, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config version ‘"+globalMCConfigVersion+"’.")
+
+	exportBytes, e := json.MarshalIndent(exportHosts(conf, redact), "", "\t")
+	fatalIf(probe.NewError(e), "Unable to marshal hosts into JSON.")
+
+	if filename == "" {
+		console.Println(string(exportBytes))
+		return
+	}
+	// Exported hosts may carry secret keys, only the owner may read them.
+	e = ioutil.WriteFile(filename, append(exportBytes, '\n'), 0600)
+	fatalIf(probe.NewError(e), "Unable to write hosts to ‘"+filename+"’.")
+	if !globalJSON {
+		console.Infof("Exported ‘%d’ hosts to ‘%s’.\n", len(conf.Hosts), filename)
+	}
+}
+
+// importHostsFromFile - import hosts exported to filename, invalid hosts are reported and skipped.
+func importHostsFromFile(filename string, overwrite bool) {
+	importBytes, e := ioutil.ReadFile(filename)
+	fatalIf(probe.NewError(e), "Unable to read hosts from ‘"+filename+"’.")
+
+	imported := newConfigV7()
+	e = json.Unmarshal(importBytes, imported)
+	fatalIf(probe.NewError(e), "Unable to parse hosts in ‘"+filename+"’.")
+	if imported.Version != globalMCConfigVersion {
+		fatalIf(errInvalidArgument().Trace(filename, imported.Version),
+			"Hosts in ‘"+filename+"’ are of config version ‘"+imported.Version+"’, expected ‘"+globalMCConfigVersion+"’.")
+	}
+
+	conf, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config version ‘"+globalMCConfigVersion+"’.")
+
+	msgs := importHosts(conf, imported, overwrite)
+	err = saveMcConfig(conf)
+	fatalIf(err.Trace(filename), "Unable to save imported hosts in config version ‘"+globalMCConfigVersion+"’.")
+
+	invalid := 0
+	for _, msg := range msgs {
+		printMsg(msg)
+		if msg.Result == "invalid" {
+			invalid++
+		}
+	}
+	// All valid hosts were imported, signal the ones left out.
+	if invalid > 0 {
+		flushOutput()
+		os.Exit(exitPartialFailure)
+	}
+}
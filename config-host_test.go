@@ -0,0 +1,141 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestHostsConfig - config with hosts as given.
+func newTestHostsConfig(hosts map[string]hostConfigV7) *configV7 {
+	conf := newConfigV7()
+	for alias, hostCfg := range hosts {
+		conf.Hosts[alias] = hostCfg
+	}
+	return conf
+}
+
+// importResults - result of every imported alias.
+func importResults(msgs []hostImportMessage) map[string]string {
+	results := make(map[string]string)
+	for _, msg := range msgs {
+		results[msg.Alias] = msg.Result
+	}
+	return results
+}
+
+var (
+	testHostPhotos = hostConfigV7{
+		URL:       "https://s3.amazonaws.com",
+		AccessKey: "BKIKJAA5BMMU2RHO6IBB",
+		SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12",
+		API:       "S3v4",
+	}
+	testHostBackup = hostConfigV7{
+		URL:         "https://backup.example.com",
+		AccessKey:   "Q3AM3UQ867SPQQA43P2F",
+		SecretKey:   "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG",
+		API:         "S3v2",
+		ConnTimeout: "10s",
+	}
+)
+
+func (s *TestSuite) TestImportHostsMergeOverwrite(c *C) {
+	changedBackup := testHostBackup
+	changedBackup.URL = "https://backup2.example.com"
+	imported := newTestHostsConfig(map[string]hostConfigV7{
+		"photos": testHostPhotos,
+		"backup": changedBackup,
+		"play":   {URL: "https://play.minio.io:9000", API: "s3v4"},
+	})
+
+	// Merge keeps existing hosts on conflicts.
+	conf := newTestHostsConfig(map[string]hostConfigV7{"photos": testHostPhotos, "backup": testHostBackup})
+	msgs := importHosts(conf, imported, false)
+	c.Assert(importResults(msgs), DeepEquals, map[string]string{"backup": "skipped", "photos": "unchanged", "play": "added"})
+	c.Assert(conf.Hosts["backup"], DeepEquals, testHostBackup)
+	c.Assert(conf.Hosts["play"].API, Equals, "S3v4")
+
+	// Overwrite replaces them.
+	conf = newTestHostsConfig(map[string]hostConfigV7{"photos": testHostPhotos, "backup": testHostBackup})
+	msgs = importHosts(conf, imported, true)
+	c.Assert(importResults(msgs), DeepEquals, map[string]string{"backup": "replaced", "photos": "unchanged", "play": "added"})
+	c.Assert(conf.Hosts["backup"], DeepEquals, changedBackup)
+	c.Assert(len(conf.Hosts), Equals, 3)
+}
+
+func (s *TestSuite) TestImportHostsInvalid(c *C) {
+	imported := newTestHostsConfig(map[string]hostConfigV7{
+		"photos":  testHostPhotos,
+		"1alias":  testHostPhotos,
+		"badurl":  {URL: "ftp://example.com", API: "S3v4"},
+		"badkey":  {URL: "https://example.com", AccessKey: "short", API: "S3v4"},
+		"badapi":  {URL: "https://example.com", API: "S3v3"},
+		"badtime": {URL: "https://example.com", API: "S3v4", ReadTimeout: "forever"},
+	})
+	conf := newConfigV7()
+	msgs := importHosts(conf, imported, false)
+	c.Assert(importResults(msgs), DeepEquals, map[string]string{
+		"1alias":  "invalid",
+		"badapi":  "invalid",
+		"badkey":  "invalid",
+		"badtime": "invalid",
+		"badurl":  "invalid",
+		"photos":  "added",
+	})
+	for _, msg := range msgs {
+		if msg.Result == "invalid" {
+			c.Assert(msg.Reason, Not(Equals), "")
+		}
+	}
+	// Valid hosts are imported regardless.
+	c.Assert(conf.Hosts, DeepEquals, map[string]hostConfigV7{"photos": testHostPhotos})
+}
+
+func (s *TestSuite) TestExportHostsRedactRoundTrip(c *C) {
+	conf := newTestHostsConfig(map[string]hostConfigV7{"photos": testHostPhotos, "backup": testHostBackup})
+
+	// Export is the config structure, secret keys are left out if redacted.
+	exportBytes, e := json.Marshal(exportHosts(conf, true))
+	c.Assert(e, IsNil)
+	exported := newConfigV7()
+	c.Assert(json.Unmarshal(exportBytes, exported), IsNil)
+	c.Assert(exported.Version, Equals, globalMCConfigVersion)
+	c.Assert(exported.Hosts["photos"].SecretKey, Equals, "")
+	c.Assert(exported.Hosts["backup"].ConnTimeout, Equals, "10s")
+	c.Assert(conf.Hosts["photos"].SecretKey, Equals, testHostPhotos.SecretKey)
+
+	// Importing back keeps the secret keys in place.
+	msgs := importHosts(conf, exported, true)
+	c.Assert(importResults(msgs), DeepEquals, map[string]string{"backup": "unchanged", "photos": "unchanged"})
+	c.Assert(conf.Hosts["photos"], DeepEquals, testHostPhotos)
+
+	// On another machine redacted hosts are imported without secret keys.
+	conf = newConfigV7()
+	msgs = importHosts(conf, exported, false)
+	c.Assert(importResults(msgs), DeepEquals, map[string]string{"backup": "added", "photos": "added"})
+	c.Assert(conf.Hosts["photos"].SecretKey, Equals, "")
+	for _, msg := range msgs {
+		c.Assert(msg.Reason, Not(Equals), "")
+	}
+
+	// Without redaction the export is complete.
+	conf = newTestHostsConfig(map[string]hostConfigV7{"photos": testHostPhotos})
+	c.Assert(exportHosts(conf, false).Hosts["photos"], DeepEquals, testHostPhotos)
+}
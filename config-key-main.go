@@ -0,0 +1,197 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	configKeyFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of config key",
+		},
+	}
+)
+
+var configKeyCmd = cli.Command{
+	Name:   "key",
+	Usage:  "List, import and remove SSE-C encryption keys in the keystore.",
+	Flags:  append(configKeyFlags, globalFlags...),
+	Action: mainConfigKey,
+	CustomHelpTemplate: `NAME:
+   mc config {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc config {{.Name}} OPERATION
+
+OPERATION:
+   import FILE
+   remove PATTERN
+   list
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+KEY FILE:
+   One ‘ALIAS/BUCKET/PREFIX=BASE64KEY’ per line, keys are 32 bytes. Objects take the key of the
+   longest matching pattern. Keys are never printed, key files must only be readable by their owner.
+   With ‘--encrypt-mode client’ keys seal a random AES-256-GCM data key of every object instead,
+   which is stored in its metadata. Reading such objects verifies they were not modified.
+
+EXAMPLES:
+   1. Import the keys of a key file into the keystore.
+      $ mc config {{.Name}} import keys.txt
+
+   2. List patterns of all keys in the keystore.
+      $ mc config {{.Name}} list
+
+   3. Remove the key of objects under "backup/" in bucket "mybucket" of alias "s3".
+      $ mc config {{.Name}} remove s3/mybucket/backup/
+
+   4. Copy an object with a key of a key file, without importing it.
+      $ mc --encrypt-key-file keys.txt cp s3/mybucket/backup/db.tar .
+
+   5. Encrypt an upload with the keystore key before it leaves the client, the server never sees the key.
+      $ mc --encrypt-mode client cp db.tar s3/mybucket/backup/
+`,
+}
+
+// keyMessage container for keystore messages, never carries the key itself.
+type keyMessage struct {
+	op      string
+	Status  string `json:"status"`
+	Pattern string `json:"pattern"`
+}
+
+// String colorized key message.
+func (k keyMessage) String() string {
+	switch k.op {
+	case "list":
+		return console.Colorize("Pattern", k.Pattern)
+	case "remove":
+		return console.Colorize("KeyMessage", "Removed key of ‘"+k.Pattern+"’ successfully.")
+	case "import":
+		return console.Colorize("KeyMessage", "Imported key of ‘"+k.Pattern+"’ successfully.")
+	default:
+		return ""
+	}
+}
+
+// JSON jsonified key message.
+func (k keyMessage) JSON() string {
+	k.Status = "success"
+	jsonMessageBytes, e := json.Marshal(k)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// checkConfigKeySyntax - validate command-line input args.
+func checkConfigKeySyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "key", 1) // last argument is exit code
+	}
+
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "import", "remove":
+		if len(ctx.Args().Tail()) != 1 {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().Tail()...),
+				"Incorrect number of arguments for key "+ctx.Args().First()+" command.")
+		}
+	case "list":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "key", 1) // last argument is exit code
+	}
+}
+
+func mainConfigKey(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'config key' cli arguments.
+	checkConfigKeySyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("KeyMessage", color.New(color.FgGreen))
+	console.SetColor("Pattern", color.New(color.FgCyan, color.Bold))
+
+	args := ctx.Args().Tail()
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "import":
+		importKeys(args.Get(0))
+	case "remove":
+		removeKey(args.Get(0))
+	case "list":
+		listKeys()
+	}
+}
+
+// importKeys - add keys of a key file to the keystore, replacing keys of the same pattern.
+func importKeys(filename string) {
+	keys, err := loadEncryptKeyFile(filename)
+	fatalIf(err.Trace(filename), "Unable to read keys from ‘"+filename+"’.")
+
+	err = updateEncryptKeystore(getEncryptKeysFile(), func(entries map[string]string) *probe.Error {
+		for _, key := range keys {
+			entries[key.pattern] = encodeEncryptKey(key.key)
+		}
+		return nil
+	})
+	fatalIf(err.Trace(filename), "Unable to update keystore ‘"+getEncryptKeysFile()+"’.")
+
+	for _, key := range keys {
+		printMsg(keyMessage{op: "import", Pattern: key.pattern})
+	}
+}
+
+// removeKey - remove the key of pattern from the keystore.
+func removeKey(pattern string) {
+	err := updateEncryptKeystore(getEncryptKeysFile(), func(entries map[string]string) *probe.Error {
+		if _, ok := entries[pattern]; !ok {
+			return errInvalidEncryptKey(pattern, "no such key in the keystore").Trace(pattern)
+		}
+		delete(entries, pattern)
+		return nil
+	})
+	fatalIf(err.Trace(pattern), "Unable to remove key of ‘"+pattern+"’.")
+
+	printMsg(keyMessage{op: "remove", Pattern: pattern})
+}
+
+// listKeys - list patterns of the keystore in sorted order.
+func listKeys() {
+	entries, err := readEncryptKeystore(getEncryptKeysFile())
+	fatalIf(err.Trace(), "Unable to read keystore ‘"+getEncryptKeysFile()+"’.")
+
+	var patterns []string
+	for pattern := range entries {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		printMsg(keyMessage{op: "list", Pattern: pattern})
+	}
+}
@@ -45,6 +45,10 @@ var configCmd = cli.Command{
 	Flags:  append(configFlags, globalFlags...),
 	Subcommands: []cli.Command{
 		configHostCmd,
+		configKeyCmd,
+		configEncryptCmd,
+		configDecryptCmd,
+		configShortenerCmd,
 	},
 	CustomHelpTemplate: `NAME:
    {{.Name}} - {{.Usage}}
@@ -0,0 +1,153 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	configShortenerFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of config shortener",
+		},
+	}
+)
+
+var configShortenerCmd = cli.Command{
+	Name:   "shortener",
+	Usage:  "Set or remove the link shortener shared download URLs are registered with.",
+	Flags:  append(configShortenerFlags, globalFlags...),
+	Action: mainConfigShortener,
+	CustomHelpTemplate: `NAME:
+   mc config {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc config {{.Name}} OPERATION
+
+OPERATION:
+   set URL [TOKEN]
+   remove
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+LINK SHORTENER:
+   Every URL of ‘mc share download’ is posted to URL as {"url": SHARE-URL, "expiry": SECONDS}, with
+   TOKEN as bearer token if set. The service answers {"shortURL": SHORT-URL}, a link it redirects to
+   the share URL. If it fails, the share URL is shared without a short link.
+
+EXAMPLES:
+   1. Register shared download URLs with a link shortener.
+      $ mc config {{.Name}} set https://sho.rt/api/links 9c4e1ab2f7
+
+   2. Share download URLs without short links again.
+      $ mc config {{.Name}} remove
+`,
+}
+
+// shortenerMessage container for link shortener config messages, never carries the token.
+type shortenerMessage struct {
+	op     string
+	Status string `json:"status"`
+	URL    string `json:"url,omitempty"`
+}
+
+// String colorized link shortener message.
+func (s shortenerMessage) String() string {
+	switch s.op {
+	case "set":
+		return console.Colorize("ShortenerMessage", "Link shortener set to ‘"+s.URL+"’ successfully.")
+	case "remove":
+		return console.Colorize("ShortenerMessage", "Removed link shortener successfully.")
+	default:
+		return ""
+	}
+}
+
+// JSON jsonified link shortener message.
+func (s shortenerMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// checkConfigShortenerSyntax - validate command-line input args.
+func checkConfigShortenerSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "shortener", 1) // last argument is exit code
+	}
+
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "set":
+		if len(ctx.Args().Tail()) < 1 || len(ctx.Args().Tail()) > 2 {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().Tail()...),
+				"Incorrect number of arguments for shortener set command.")
+		}
+		shortenerURL := ctx.Args().Tail().First()
+		fatalIf(checkShortenerURL(shortenerURL).Trace(shortenerURL), "Unable to set link shortener.")
+	case "remove":
+		if len(ctx.Args().Tail()) != 0 {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().Tail()...),
+				"Incorrect number of arguments for shortener remove command.")
+		}
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "shortener", 1) // last argument is exit code
+	}
+}
+
+func mainConfigShortener(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'config shortener' cli arguments.
+	checkConfigShortenerSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("ShortenerMessage", color.New(color.FgGreen))
+
+	args := ctx.Args().Tail()
+	switch strings.TrimSpace(ctx.Args().First()) {
+	case "set":
+		setShortener(&shortenerConfig{URL: args.Get(0), Token: args.Get(1)})
+	case "remove":
+		setShortener(nil)
+	}
+}
+
+// setShortener - save shortener in the config, nil removes it.
+func setShortener(shortener *shortenerConfig) {
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(), "Unable to load config ‘"+mustGetMcConfigPath()+"’.")
+	mcCfg.Shortener = shortener
+	fatalIf(saveMcConfig(mcCfg).Trace(), "Unable to save config ‘"+mustGetMcConfigPath()+"’.")
+
+	if shortener == nil {
+		printMsg(shortenerMessage{op: "remove"})
+		return
+	}
+	printMsg(shortenerMessage{op: "set", URL: shortener.URL})
+}
@@ -41,12 +41,26 @@ type hostConfigV7 struct {
 	AccessKey string `json:"accessKey"`
 	SecretKey string `json:"secretKey"`
 	API       string `json:"api"`
+	// Optional per host timeouts such as "10s", overridden by command line flags.
+	ConnTimeout string `json:"connTimeout,omitempty"`
+	ReadTimeout string `json:"readTimeout,omitempty"`
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+	// Optional signing region, looked up from the host if empty.
+	Region string `json:"region,omitempty"`
+	// Set for hosts without multipart upload support, uploads then use a single PUT.
+	DisableMultipart bool `json:"disableMultipart,omitempty"`
+	// Set to transfer objects of Amazon S3 through Transfer Acceleration.
+	Accelerate bool `json:"accelerate,omitempty"`
 }
 
 // configV7 config version.
 type configV7 struct {
 	Version string                  `json:"version"`
 	Hosts   map[string]hostConfigV7 `json:"hosts"`
+	// Set once access and secret keys of the hosts are encrypted, see ‘mc config encrypt’.
+	Encryption *configEncryption `json:"encryption,omitempty"`
+	// Set to register shared download URLs with a link shortener, see ‘mc config shortener’.
+	Shortener *shortenerConfig `json:"shortener,omitempty"`
 }
 
 // newConfigV7 - new config version.
@@ -129,11 +143,17 @@ func loadConfigV7() (*configV7, *probe.Error) {
 		return nil, errInvalidArgument().Trace()
 	}
 
-	mcCfgV7, err := quick.Load(mustGetMcConfigPath(), newConfigV7())
+	var cfgV7 *configV7
+	err := loadFileWithBackup(mustGetMcConfigPath(), func(filename string) *probe.Error {
+		mcCfgV7, err := quick.Load(filename, newConfigV7())
+		if err != nil {
+			return err.Trace(filename)
+		}
+		cfgV7 = mcCfgV7.Data().(*configV7)
+		return nil
+	})
 	fatalIf(err.Trace(), "Unable to load mc config file ‘"+mustGetMcConfigPath()+"’.")
 
-	cfgV7 := mcCfgV7.Data().(*configV7)
-
 	// cache it.
 	cacheCfgV7 = cfgV7
 
@@ -145,13 +165,16 @@ func saveConfigV7(cfgV7 *configV7) *probe.Error {
 	cfgMutex.Lock()
 	defer cfgMutex.Unlock()
 
-	qs, err := quick.New(cfgV7)
-	if err != nil {
+	if err := quick.CheckData(cfgV7); err != nil {
+		return err.Trace()
+	}
+	// Hosts added to an encrypted config are encrypted as well.
+	if err := sealConfig(cfgV7); err != nil {
 		return err.Trace()
 	}
 
 	// update the cache.
 	cacheCfgV7 = cfgV7
 
-	return qs.Save(mustGetMcConfigPath()).Trace(mustGetMcConfigPath())
+	return saveFileAtomic(mustGetMcConfigPath(), cfgV7).Trace(mustGetMcConfigPath())
 }
@@ -162,7 +162,10 @@ func getHostConfig(alias string) (*hostConfigV7, *probe.Error) {
 
 	// if host is exact return quickly.
 	if _, ok := mcCfg.Hosts[alias]; ok {
-		hostCfg := mcCfg.Hosts[alias]
+		hostCfg, err := decryptHostConfig(mcCfg, mcCfg.Hosts[alias])
+		if err != nil {
+			return nil, err.Trace(alias)
+		}
 		return &hostCfg, nil
 	}
 
@@ -171,9 +174,18 @@ func getHostConfig(alias string) (*hostConfigV7, *probe.Error) {
 }
 
 // mustGetHostConfig retrieves host specific configuration such as access keys, signature type.
+// Nil if alias is not configured, exits if its secrets can not be decrypted, an alias is never
+// taken for a local path because of a wrong passphrase.
 func mustGetHostConfig(alias string) *hostConfigV7 {
-	hostCfg, _ := getHostConfig(alias)
-	return hostCfg
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		return nil
+	}
+	if _, ok := mcCfg.Hosts[alias]; !ok {
+		return nil
+	}
+	hostCfg := mustDecryptHostConfig(mcCfg, mcCfg.Hosts[alias])
+	return &hostCfg
 }
 
 // expandAlias expands aliased URL if any match is found, returns as is otherwise.
@@ -0,0 +1,223 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Name of the lock file guarding the content cache across invocations.
+const contentCacheLock = "cache"
+
+// contentCache is a size bounded local cache of object contents, shared by
+// all invocations. Entries are keyed by URL and ETag, a changed object is
+// never served from the cache. The least recently used entries are evicted
+// first, every hit refreshes the modification time of its entry.
+type contentCache struct {
+	dir     string
+	maxSize int64
+	// refresh fetches every object from its host and replaces its cached copy.
+	refresh bool
+}
+
+// getContentCacheDir - get content cache directory.
+func getContentCacheDir() (string, *probe.Error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(configDir, globalContentCacheDir), nil
+}
+
+// newContentCache - content cache in dir holding at most maxSize bytes.
+func newContentCache(dir string, maxSize int64, refresh bool) (*contentCache, *probe.Error) {
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return &contentCache{dir: dir, maxSize: maxSize, refresh: refresh}, nil
+}
+
+// entryName - file name of the cached content of url, the URL part is shared by all versions of it.
+func (cc *contentCache) entryName(url, etag string) string {
+	urlSum := sha256.Sum256([]byte(url))
+	etagSum := sha256.Sum256([]byte(etag))
+	return hex.EncodeToString(urlSum[:]) + "-" + hex.EncodeToString(etagSum[:8])
+}
+
+// lock - takes the cache lock shared with other invocations.
+func (cc *contentCache) lock() (func(), *probe.Error) {
+	return lockFile(filepath.Join(cc.dir, contentCacheLock))
+}
+
+// lookup - cached content of url at etag, if any.
+func (cc *contentCache) lookup(url, etag string) ([]byte, bool, *probe.Error) {
+	unlock, err := cc.lock()
+	if err != nil {
+		return nil, false, err.Trace(url)
+	}
+	defer unlock()
+
+	entry := filepath.Join(cc.dir, cc.entryName(url, etag))
+	data, e := ioutil.ReadFile(entry)
+	if os.IsNotExist(e) {
+		return nil, false, nil
+	}
+	if e != nil {
+		return nil, false, probe.NewError(e)
+	}
+	now := time.Now()
+	if e = os.Chtimes(entry, now, now); e != nil {
+		return nil, false, probe.NewError(e)
+	}
+	return data, true, nil
+}
+
+// store - cache data as the content of url at etag, replacing other versions of url.
+func (cc *contentCache) store(url, etag string, data []byte) *probe.Error {
+	if int64(len(data)) > cc.maxSize {
+		return nil
+	}
+	unlock, err := cc.lock()
+	if err != nil {
+		return err.Trace(url)
+	}
+	defer unlock()
+
+	name := cc.entryName(url, etag)
+	stale, e := filepath.Glob(filepath.Join(cc.dir, name[:strings.Index(name, "-")]+"-*"))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, entry := range stale {
+		if e = os.Remove(entry); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e)
+		}
+	}
+	if err = writeFileAtomic(filepath.Join(cc.dir, name), data); err != nil {
+		return err.Trace(url)
+	}
+	return cc.evict().Trace(url)
+}
+
+// evict - remove least recently used entries until the cache fits its size, the cache lock must be held.
+func (cc *contentCache) evict() *probe.Error {
+	entries, e := ioutil.ReadDir(cc.dir)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	var size int64
+	var cached []os.FileInfo
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), lockFileSuffix) {
+			continue
+		}
+		size += entry.Size()
+		cached = append(cached, entry)
+	}
+	sort.Sort(byModTime(cached))
+	for _, entry := range cached {
+		if size <= cc.maxSize {
+			break
+		}
+		if e = os.Remove(filepath.Join(cc.dir, entry.Name())); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e)
+		}
+		size -= entry.Size()
+	}
+	return nil
+}
+
+// byModTime - sort file infos, least recently modified first.
+type byModTime []os.FileInfo
+
+func (b byModTime) Len() int           { return len(b) }
+func (b byModTime) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byModTime) Less(i, j int) bool { return b[i].ModTime().Before(b[j].ModTime()) }
+
+// get - reader of the object of clnt, served from the cache if the cached ETag
+// matches the current one. Objects larger than the cache are never cached.
+func (cc *contentCache) get(clnt client.Client) (io.ReadSeeker, *probe.Error) {
+	url := clnt.GetURL().String()
+	content, err := clnt.Stat()
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	if content.ETag == "" || content.Type.IsDir() || content.Size > cc.maxSize {
+		return clnt.Get(0, 0, "")
+	}
+	if !cc.refresh {
+		data, ok, err := cc.lookup(url, content.ETag)
+		errorIf(err.Trace(url), "Unable to read ‘"+url+"’ from cache ‘"+cc.dir+"’.")
+		if ok {
+			return bytes.NewReader(data), nil
+		}
+	}
+
+	reader, err := clnt.Get(0, 0, "")
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	data, e := ioutil.ReadAll(reader)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(url)
+	}
+	// Object replaced between stat and get, its content must not be cached under the old ETag.
+	if !etagMatches(content.ETag, data) {
+		return bytes.NewReader(data), nil
+	}
+	errorIf(cc.store(url, content.ETag, data).Trace(url), "Unable to cache ‘"+url+"’ in ‘"+cc.dir+"’.")
+	return bytes.NewReader(data), nil
+}
+
+// etagMatches - reports if data may be the content of an object with etag. Only
+// ETags of single part uploads are MD5 sums of the content, others always match.
+func etagMatches(etag string, data []byte) bool {
+	if len(etag) != md5.Size*2 {
+		return true
+	}
+	if _, e := hex.DecodeString(etag); e != nil {
+		return true
+	}
+	sum := md5.Sum(data)
+	return strings.EqualFold(etag, hex.EncodeToString(sum[:]))
+}
+
+// getCachedSource gets a reader from URL, read through cache.
+func getCachedSource(urlStr string, cache *contentCache) (io.ReadSeeker, *probe.Error) {
+	alias, urlStrFull, _, err := expandAlias(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	sourceClnt, err := newClientFromAlias(alias, urlStrFull)
+	if err != nil {
+		return nil, err.Trace(alias, urlStrFull)
+	}
+	return cache.get(sourceClnt)
+}
@@ -0,0 +1,163 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// cacheHandler - serves objects from memory, counts GETs of object data.
+type cacheHandler struct {
+	mutex   sync.Mutex
+	objects map[string]string
+	gets    int
+}
+
+func (h *cacheHandler) set(path, data string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.objects[path] = data
+}
+
+func (h *cacheHandler) getCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.gets
+}
+
+func (h *cacheHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	data, ok := h.objects[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sum := md5.Sum([]byte(data))
+	w.Header().Set("ETag", "\""+hex.EncodeToString(sum[:])+"\"")
+	w.Header().Set("Last-Modified", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == "GET" {
+		h.gets++
+		w.Write([]byte(data))
+	}
+}
+
+func (s *TestSuite) TestContentCache(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	statCache := globalStatCache
+	globalStatCache = nil
+	defer func() { globalStatCache = statCache }()
+
+	handler := &cacheHandler{objects: map[string]string{"/bucket/config.json": `{"version": 1}`}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("cached", hostConfigV7{
+		URL:       server.URL,
+		AccessKey: "BKIKJAA5BMMU2RHO6IBB",
+		SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12",
+		API:       "S3v4",
+	}), IsNil)
+
+	cacheDir, e := ioutil.TempDir("", "mc-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(cacheDir)
+	cache, err := newContentCache(cacheDir, 1024, false)
+	c.Assert(err, IsNil)
+
+	read := func(cache *contentCache) string {
+		reader, err := getCachedSource("cached/bucket/config.json", cache)
+		c.Assert(err, IsNil)
+		data, e := ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		return string(data)
+	}
+
+	// Miss fetches and populates, hit is served from the cache.
+	c.Assert(read(cache), Equals, `{"version": 1}`)
+	c.Assert(handler.getCount(), Equals, 1)
+	c.Assert(read(cache), Equals, `{"version": 1}`)
+	c.Assert(handler.getCount(), Equals, 1)
+
+	// Changed object bypasses the cache and replaces the cached copy.
+	handler.set("/bucket/config.json", `{"version": 2}`)
+	c.Assert(read(cache), Equals, `{"version": 2}`)
+	c.Assert(handler.getCount(), Equals, 2)
+	c.Assert(read(cache), Equals, `{"version": 2}`)
+	c.Assert(handler.getCount(), Equals, 2)
+	entries, e := filepath.Glob(filepath.Join(cacheDir, "*-*"))
+	c.Assert(e, IsNil)
+	c.Assert(entries, HasLen, 1)
+
+	// Refreshing always fetches.
+	refresh, err := newContentCache(cacheDir, 1024, true)
+	c.Assert(err, IsNil)
+	c.Assert(read(refresh), Equals, `{"version": 2}`)
+	c.Assert(handler.getCount(), Equals, 3)
+}
+
+func (s *TestSuite) TestContentCacheEviction(c *C) {
+	cacheDir, e := ioutil.TempDir("", "mc-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(cacheDir)
+	cache, err := newContentCache(cacheDir, 10, false)
+	c.Assert(err, IsNil)
+
+	c.Assert(cache.store("s3/bucket/a", "etag-a", []byte("aaaa")), IsNil)
+	c.Assert(cache.store("s3/bucket/b", "etag-b", []byte("bbbb")), IsNil)
+	// Age both entries, then make ‘a’ the most recently used.
+	past := time.Now().Add(-time.Hour)
+	for _, name := range []string{cache.entryName("s3/bucket/a", "etag-a"), cache.entryName("s3/bucket/b", "etag-b")} {
+		c.Assert(os.Chtimes(filepath.Join(cacheDir, name), past, past), IsNil)
+	}
+	_, ok, err := cache.lookup("s3/bucket/a", "etag-a")
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	// Storing ‘c’ exceeds the size, least recently used ‘b’ is evicted.
+	c.Assert(cache.store("s3/bucket/c", "etag-c", []byte("cccc")), IsNil)
+	_, ok, _ = cache.lookup("s3/bucket/b", "etag-b")
+	c.Assert(ok, Equals, false)
+	for _, url := range []string{"s3/bucket/a", "s3/bucket/c"} {
+		_, ok, _ = cache.lookup(url, "etag-"+url[len(url)-1:])
+		c.Assert(ok, Equals, true)
+	}
+
+	// Objects larger than the cache are not cached, a wrong ETag is a miss.
+	c.Assert(cache.store("s3/bucket/d", "etag-d", []byte("ddddddddddddd")), IsNil)
+	_, ok, _ = cache.lookup("s3/bucket/d", "etag-d")
+	c.Assert(ok, Equals, false)
+	_, ok, _ = cache.lookup("s3/bucket/a", "etag-b")
+	c.Assert(ok, Equals, false)
+
+	c.Assert(etagMatches("d41d8cd98f00b204e9800998ecf8427e", nil), Equals, true)
+	c.Assert(etagMatches("d41d8cd98f00b204e9800998ecf8427e", []byte("x")), Equals, false)
+	c.Assert(etagMatches("d41d8cd98f00b204e9800998ecf8427e-2", []byte("x")), Equals, true)
+}
@@ -0,0 +1,176 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/minio/pkg/contentdb"
+)
+
+// globalMimeTypesFile user supplied content-type overrides, in mime.types format.
+const globalMimeTypesFile = "mime.types"
+
+// defaultContentTypes - common extensions missing from the embedded contentdb.
+var defaultContentTypes = map[string]string{
+	"avif":        "image/avif",
+	"heic":        "image/heic",
+	"heif":        "image/heif",
+	"webp":        "image/webp",
+	"jxl":         "image/jxl",
+	"opus":        "audio/ogg",
+	"mjs":         "application/javascript",
+	"wasm":        "application/wasm",
+	"woff2":       "font/woff2",
+	"md":          "text/markdown",
+	"yaml":        "text/yaml",
+	"yml":         "text/yaml",
+	"zst":         "application/zstd",
+	"tgz":         "application/gzip",
+	"tar.gz":      "application/gzip",
+	"tar.bz2":     "application/x-bzip2",
+	"tar.xz":      "application/x-xz",
+	"tar.zst":     "application/zstd",
+	"warc.gz":     "application/gzip",
+	"ndjson":      "application/x-ndjson",
+	"parquet":     "application/vnd.apache.parquet",
+	"geojson":     "application/geo+json",
+	"jsonld":      "application/ld+json",
+	"webmanifest": "application/manifest+json",
+}
+
+// contentTypes - defaults merged with the user overrides, keyed by lower case extension.
+var contentTypes = newContentTypes()
+
+func newContentTypes() map[string]string {
+	types := make(map[string]string, len(defaultContentTypes))
+	for extension, contentType := range defaultContentTypes {
+		types[extension] = contentType
+	}
+	return types
+}
+
+// parseMimeTypes - reads mime.types style lines, "type/subtype ext1 ext2 ...".
+// Blank lines and everything after ‘#’ are ignored.
+func parseMimeTypes(reader io.Reader) (map[string]string, *probe.Error) {
+	types := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !strings.Contains(fields[0], "/") {
+			return nil, errInvalidArgument().Trace(fields[0])
+		}
+		for _, extension := range fields[1:] {
+			types[strings.ToLower(strings.TrimPrefix(extension, "."))] = fields[0]
+		}
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return types, nil
+}
+
+// loadContentTypes - merge the user's mime.types from the config folder over the defaults.
+// A missing file is not an error.
+func loadContentTypes() *probe.Error {
+	dir, err := getMcConfigDir()
+	if err != nil {
+		return err.Trace()
+	}
+	path := filepath.Join(dir, globalMimeTypesFile)
+	file, e := os.Open(path)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil
+		}
+		return probe.NewError(e)
+	}
+	defer file.Close()
+
+	overrides, err := parseMimeTypes(file)
+	if err != nil {
+		return err.Trace(path)
+	}
+	for extension, contentType := range overrides {
+		contentTypes[extension] = contentType
+	}
+	return nil
+}
+
+// lookupContentType - content-type of a file name, case insensitive. Compound
+// extensions are tried longest first, ‘archive.tar.gz’ checks ‘tar.gz’ before
+// ‘gz’. For each extension user overrides and defaults win over the embedded
+// contentdb. Returns "" when nothing matches.
+func lookupContentType(name string) string {
+	name = strings.ToLower(name)
+	for i := strings.Index(name, "."); i >= 0; {
+		extension := name[i+1:]
+		if contentType, ok := contentTypes[extension]; ok {
+			return contentType
+		}
+		if contentType, e := contentdb.Lookup(extension); e == nil && contentType != "" {
+			return contentType
+		}
+		next := strings.Index(extension, ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return ""
+}
+
+// sniffLength - bytes of content looked at by sniffContentType.
+const sniffLength = 512
+
+// sniffContentType - content-type detected from the first bytes of reader, which is seeked back
+// to the start. Returns "" for readers not at their start, which cannot seek, such as stdin, or
+// when the content does not tell more than "application/octet-stream".
+func sniffContentType(reader io.ReadSeeker) string {
+	if offset, e := reader.Seek(0, 1); e != nil || offset != 0 {
+		return ""
+	}
+	buf := make([]byte, sniffLength)
+	n, e := io.ReadFull(reader, buf)
+	if e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+		return ""
+	}
+	if _, e = reader.Seek(0, 0); e != nil {
+		return ""
+	}
+	if n == 0 {
+		return ""
+	}
+	contentType := http.DetectContentType(buf[:n])
+	if contentType == "application/octet-stream" {
+		return ""
+	}
+	return contentType
+}
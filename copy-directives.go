@@ -0,0 +1,86 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+)
+
+// parseCopyDirective - ‘COPY’ or ‘REPLACE’ of a directive flag in any case, empty if not set.
+func parseCopyDirective(directive string) (string, bool) {
+	switch directive = strings.ToUpper(directive); directive {
+	case "", client.DirectiveCopy, client.DirectiveReplace:
+		return directive, true
+	}
+	return "", false
+}
+
+// checkCopyDirectivesSyntax - validate ‘--metadata-directive’ and ‘--tagging-directive’ of cp and mirror.
+// Metadata is replaced with that of ‘--attr’ and ‘--cache-control-map’, one of them has to be given,
+// if only as ‘--attr ""’, to tell no metadata at all is intended.
+func checkCopyDirectivesSyntax(ctx *cli.Context) {
+	for _, option := range []string{"metadata-directive", "tagging-directive"} {
+		if _, ok := parseCopyDirective(ctx.String(option)); !ok {
+			fatalIf(errInvalidArgument().Trace(ctx.String(option)), "Option --"+option+" is either ‘COPY’ or ‘REPLACE’.")
+		}
+	}
+	if metadataDirective, _ := parseCopyDirective(ctx.String("metadata-directive")); metadataDirective == client.DirectiveReplace {
+		if !ctx.IsSet("attr") && !ctx.IsSet("cache-control-map") {
+			fatalIf(errInvalidArgument().Trace(), "Option --metadata-directive REPLACE replaces metadata with that of --attr or --cache-control-map, "+
+				"pass --attr \"\" to copy without any.")
+		}
+		if ctx.Bool("preserve") {
+			fatalIf(errInvalidArgument().Trace(), "Options --metadata-directive REPLACE and --preserve are mutually exclusive.")
+		}
+	}
+	if ctx.String("tagging-directive") != "" && !ctx.Bool("fan-out") {
+		fatalIf(errInvalidArgument().Trace(), "Option --tagging-directive applies to server side copies, it requires --fan-out.")
+	}
+}
+
+// setSessionMetadataDirective - save ‘--metadata-directive’ in session, tags are not copied by sessions.
+func setSessionMetadataDirective(session *sessionV6, ctx *cli.Context) {
+	if metadataDirective, _ := parseCopyDirective(ctx.String("metadata-directive")); metadataDirective != "" {
+		session.Header.CommandStringFlags["metadata-directive"] = metadataDirective
+	}
+}
+
+// isMetadataReplaced - objects copied between servers get metadata of their own, instead of that of their source.
+func isMetadataReplaced() bool {
+	return globalMetadataDirective == client.DirectiveReplace
+}
+
+// copyDirectives - ‘--metadata-directive’ and ‘--tagging-directive’ of server side copies.
+type copyDirectives struct {
+	metadata string
+	tagging  string
+}
+
+// options - options of a server side copy to targetURL of targetAlias. Replaced metadata is that of
+// ‘--attr’ and ‘--cache-control-map’ along with the content type guessed from the name of the target,
+// replaced tags are dropped.
+func (d copyDirectives) options(targetAlias, targetURL string) client.CopyOptions {
+	options := client.CopyOptions{MetadataDirective: d.metadata, TaggingDirective: d.tagging}
+	if d.metadata == client.DirectiveReplace {
+		options.ContentType, options.Metadata = applyUploadAttrs(metadataTargetKey(targetAlias, targetURL),
+			guessURLContentType(targetURL), map[string]string{})
+	}
+	return options
+}
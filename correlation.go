@@ -0,0 +1,63 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/console"
+)
+
+// Environment variables of ‘--user-agent-suffix’ and ‘--no-correlation-id’.
+const (
+	userAgentSuffixEnv = "MC_USER_AGENT_SUFFIX"
+	noCorrelationIDEnv = "MC_NO_CORRELATION_ID"
+)
+
+// newCorrelationID - random id tying the requests of a command together in server logs.
+func newCorrelationID() string {
+	id := make([]byte, 16)
+	if _, e := rand.Read(id); e != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// setUserAgentSuffix - append suffix to the User-Agent of every request. Characters not
+// allowed in a header are rejected.
+func setUserAgentSuffix(suffix string) {
+	if strings.IndexFunc(suffix, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		fatalIf(errInvalidArgument().Trace(suffix), "Option --user-agent-suffix cannot contain control characters.")
+	}
+	globalUserAgentSuffix = strings.TrimSpace(suffix)
+}
+
+// setCorrelationID - send the correlation id of this command with every request, unless disabled
+// for backends which reject headers they do not know. A command gets one id however often it is set.
+func setCorrelationID(disabled bool) {
+	globalNoCorrelationID = disabled
+	if globalCorrelationID != "" {
+		return
+	}
+	globalCorrelationID = newCorrelationID()
+	if !disabled {
+		console.Debugln("Correlation id " + globalCorrelationID + " is sent as " + s3.CorrelationHeader + ".")
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Members of an extracted archive up to this size are held in memory, larger members are spilled
+// to a file in the temp folder. Both are uploaded with their mode and modification time.
+const extractBufferSize = pipeMinPartSize
+
+// doCopyArchive - write all objects below the folder sourceURL to w as a tar stream, named by their
+// path relative to it. Objects are streamed one at a time, none is held in memory.
+func doCopyArchive(sourceURL string, w io.Writer) *probe.Error {
+	alias, sourceURLFull, _, err := expandAlias(sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	// Only objects below the folder are archived, not those sharing its name as prefix.
+	if u := client.NewURL(sourceURLFull); !strings.HasSuffix(u.Path, string(u.Separator)) {
+		sourceURLFull += string(u.Separator)
+	}
+	sourceClnt, err := newClientFromAlias(alias, sourceURLFull)
+	if err != nil {
+		return err.Trace(sourceURLFull)
+	}
+	sourcePath := sourceClnt.GetURL().Path
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	tw := tar.NewWriter(w)
+	for content := range sourceClnt.List(true, false, doneCh) {
+		if content.Err != nil {
+			return content.Err.Trace(sourceURLFull)
+		}
+		// Folders are implied by the objects in them.
+		if content.Type.IsDir() {
+			continue
+		}
+		name := archiveMemberName(content.URL, sourcePath)
+		if !content.Type.IsRegular() {
+			errorIf(errArchiveMemberSkipped(name, "it is not a regular file").Trace(content.URL.String()), "Unable to archive ‘"+name+"’.")
+			continue
+		}
+		if err = archiveObject(tw, alias, content.URL.String(), name); err != nil {
+			return err.Trace(content.URL.String())
+		}
+	}
+	return probe.NewError(tw.Close())
+}
+
+// archiveMemberName - name of the member for object u in an archive of the folder at sourcePath, always
+// separated by '/'.
+func archiveMemberName(u client.URL, sourcePath string) string {
+	name := strings.TrimPrefix(u.Path, sourcePath)
+	if u.Type == client.Filesystem {
+		name = strings.Replace(name, string(u.Separator), "/", -1)
+	}
+	return strings.TrimPrefix(name, "/")
+}
+
+// archiveObject - write the object at urlStr to tw as member name, with the mode and modification time
+// kept in its metadata, if any.
+func archiveObject(tw *tar.Writer, alias, urlStr, name string) *probe.Error {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	content, err := clnt.Stat()
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     content.Size,
+		Mode:     0644,
+		ModTime:  content.Time,
+	}
+	if mode, e := strconv.ParseUint(content.Metadata[client.MetadataMode], 8, 32); e == nil {
+		header.Mode = int64(mode)
+	}
+	if mtime, e := time.Parse(time.RFC3339Nano, content.Metadata[client.MetadataMtime]); e == nil {
+		header.ModTime = mtime
+	}
+
+	reader, err := clnt.Get(0, 0, "")
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if e := tw.WriteHeader(header); e != nil {
+		return probe.NewError(e)
+	}
+	if _, e := io.Copy(tw, reader); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// doCopyExtract - create an object below the folder targetURL for every file of the tar stream r.
+// Folders are skipped, so are links and members escaping targetURL, reported as errors.
+func doCopyExtract(r io.Reader, targetURL string, minFree uint64) *probe.Error {
+	alias, targetURLFull, _, err := expandAlias(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	// The size of the archive is not known up front, a local target needs room for each member as it comes.
+	targetDir := localTargetDir(targetURL)
+	tr := tar.NewReader(r)
+	for {
+		header, e := tr.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			// Folders are implied by the objects in them.
+			continue
+		case tar.TypeReg, tar.TypeRegA:
+		default:
+			errorIf(errArchiveMemberSkipped(header.Name, "it is not a regular file").Trace(), "Unable to extract ‘"+header.Name+"’.")
+			continue
+		}
+		name := path.Clean(header.Name)
+		if isArchiveMemberEscaping(name) {
+			errorIf(errArchiveMemberSkipped(header.Name, "it leads outside of the target").Trace(), "Unable to extract ‘"+header.Name+"’.")
+			continue
+		}
+		memberURL := urlJoinPath(targetURLFull, name)
+		if targetDir != "" {
+			if err = checkFreeSpace(targetDir, header.Size, minFree); err != nil {
+				return err.Trace(memberURL)
+			}
+		}
+		if err = extractMember(tr, header, alias, memberURL); err != nil {
+			return err.Trace(memberURL)
+		}
+		printMsg(copyMessage{
+			Source: header.Name,
+			Target: memberURL,
+		})
+	}
+}
+
+// isArchiveMemberEscaping - reports if the cleaned member name is outside of the folder extracted to.
+func isArchiveMemberEscaping(name string) bool {
+	return path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../")
+}
+
+// extractMember - upload the current member of tr, described by header, to urlStr.
+func extractMember(tr *tar.Reader, header *tar.Header, alias, urlStr string) *probe.Error {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	contentType := guessURLContentType(urlStr)
+	metadata := map[string]string{
+		client.MetadataMode:  fmt.Sprintf("%04o", header.FileInfo().Mode().Perm()),
+		client.MetadataMtime: header.ModTime.UTC().Format(time.RFC3339Nano),
+	}
+	if header.Size > extractBufferSize {
+		spillFile, err := spillMember(tr)
+		if err != nil {
+			return err.Trace(urlStr)
+		}
+		defer removeSpillFile(spillFile)
+		return clnt.Put(spillFile, header.Size, contentType, metadata).Trace(urlStr)
+	}
+	data, e := ioutil.ReadAll(tr)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return clnt.Put(bytes.NewReader(data), header.Size, contentType, metadata).Trace(urlStr)
+}
+
+// spillMember - the current member of tr written to a spill file, positioned at its start.
+func spillMember(tr *tar.Reader) (*os.File, *probe.Error) {
+	spillFile, err := newSpillFile()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if _, e := io.Copy(spillFile, tr); e != nil {
+		removeSpillFile(spillFile)
+		return nil, probe.NewError(e)
+	}
+	if _, e := spillFile.Seek(0, 0); e != nil {
+		removeSpillFile(spillFile)
+		return nil, probe.NewError(e)
+	}
+	return spillFile, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopyArchiveRoundTrip(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	root, e := ioutil.TempDir("", "mc-archive-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	mtime := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	source := filepath.Join(root, "source")
+	files := make(map[string][]byte)
+	writeFile := func(name string, data []byte) {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(path, data, 0600), IsNil)
+		c.Assert(os.Chmod(path, 0751), IsNil)
+		c.Assert(os.Chtimes(path, mtime, mtime), IsNil)
+		files[name] = data
+	}
+	writeFile("a.txt", []byte("hello"))
+	writeFile("docs/b.txt", []byte("world"))
+	c.Assert(os.MkdirAll(filepath.Join(source, "empty"), 0700), IsNil)
+
+	// Extracting into object storage keeps mode and modification time as metadata.
+	handler := &objectStoreHandler{objects: make(map[string]storedObject)}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("tar", hostConfigV7{URL: server.URL, AccessKey: "BKIKJAA5BMMU2RHO6IBB", SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12", API: "S3v4"}), IsNil)
+	var archive bytes.Buffer
+	c.Assert(doCopyArchive(source, &archive), IsNil)
+	c.Assert(doCopyExtract(&archive, "tar/bucket/backup/", 0), IsNil)
+	c.Assert(handler.objects["/bucket/backup/docs/b.txt"].data, DeepEquals, files["docs/b.txt"])
+	c.Assert(handler.objects["/bucket/backup/docs/b.txt"].header.Get("X-Amz-Meta-Mode"), Equals, "0751")
+	c.Assert(handler.objects["/bucket/backup/docs/b.txt"].header.Get("X-Amz-Meta-Mtime"), Equals, mtime.Format(time.RFC3339Nano))
+
+	// Members larger than held in memory are spilled to the temp folder.
+	writeFile("docs/deep/c.bin", bytes.Repeat([]byte("0123456789abcdef"), extractBufferSize/16+1))
+
+	// Members are named relative to the folder, with mode and modification time.
+	archive.Reset()
+	c.Assert(doCopyArchive(source, &archive), IsNil)
+	tr := tar.NewReader(bytes.NewReader(archive.Bytes()))
+	members := make(map[string]bool)
+	for {
+		header, e := tr.Next()
+		if e != nil {
+			break
+		}
+		members[header.Name] = true
+		c.Assert(header.Mode, Equals, int64(0751))
+		c.Assert(header.ModTime.Equal(mtime), Equals, true)
+	}
+	c.Assert(members, DeepEquals, map[string]bool{"a.txt": true, "docs/b.txt": true, "docs/deep/c.bin": true})
+
+	// Extracting into a local folder restores the tree.
+	target := filepath.Join(root, "target") + string(filepath.Separator)
+	c.Assert(doCopyExtract(bytes.NewReader(archive.Bytes()), target, 0), IsNil)
+	for name, data := range files {
+		path := filepath.Join(target, filepath.FromSlash(name))
+		extracted, e := ioutil.ReadFile(path)
+		c.Assert(e, IsNil)
+		c.Assert(extracted, DeepEquals, data)
+		st, e := os.Stat(path)
+		c.Assert(e, IsNil)
+		c.Assert(st.Mode().Perm(), Equals, os.FileMode(0751))
+		c.Assert(st.ModTime().Equal(mtime), Equals, true)
+	}
+}
+
+func (s *TestSuite) TestCopyExtractSkipped(c *C) {
+	root, e := ioutil.TempDir("", "mc-extract-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "folder/", Mode: 0755}), IsNil)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "folder/link", Linkname: "/etc/passwd"}), IsNil)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../escaped", Size: 1, Mode: 0644}), IsNil)
+	_, e = tw.Write([]byte("x"))
+	c.Assert(e, IsNil)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "folder/kept", Size: 1, Mode: 0644}), IsNil)
+	_, e = tw.Write([]byte("y"))
+	c.Assert(e, IsNil)
+	c.Assert(tw.Close(), IsNil)
+
+	// Links and members outside of the target are skipped, the others extracted.
+	target := filepath.Join(root, "target")
+	c.Assert(doCopyExtract(&archive, target+string(filepath.Separator), 0), IsNil)
+	_, e = os.Lstat(filepath.Join(target, "folder", "link"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, e = os.Stat(filepath.Join(root, "escaped"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	kept, e := ioutil.ReadFile(filepath.Join(target, "folder", "kept"))
+	c.Assert(e, IsNil)
+	c.Assert(string(kept), Equals, "y")
+}
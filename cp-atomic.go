@@ -0,0 +1,98 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// atomicSuffix - temporary objects of ‘cp --atomic’ are named after their target followed by this
+// suffix and a random tag, concurrent copies to the same target never share one.
+const atomicSuffix = ".mc-atomic-"
+
+// maxAtomicSize - largest object a single server side copy moves to its target.
+const maxAtomicSize = 5 * 1024 * 1024 * 1024
+
+// isAtomicCopy - the copy to targetClnt goes through a temporary object with ‘--atomic’. Files
+// are always written to a partial file renamed once complete, they need none.
+func isAtomicCopy(session *sessionV6, targetClnt client.Client) bool {
+	return session != nil && session.Header.CommandBoolFlags["atomic"] && targetClnt.GetURL().Type == client.Object
+}
+
+// newAtomicTempClient - client of a new temporary object next to the target of targetClnt.
+func newAtomicTempClient(targetAlias string, targetClnt client.Client, size int64) (client.Client, *probe.Error) {
+	targetURL := targetClnt.GetURL().String()
+	if size > maxAtomicSize {
+		return nil, errAtomicTooLarge(targetURL).Trace(targetURL)
+	}
+	tag := make([]byte, 8)
+	if _, e := rand.Read(tag); e != nil {
+		return nil, probe.NewError(e)
+	}
+	tempURL := targetURL + atomicSuffix + hex.EncodeToString(tag)
+	tempClnt, err := newClientFromAlias(targetAlias, tempURL)
+	if err != nil {
+		return nil, err.Trace(tempURL)
+	}
+	return tempClnt, nil
+}
+
+// commitAtomicCopy - copy the complete temporary object to the target server side, replacing it
+// at once, then remove the temporary object. The copy is done even if the removal fails. With
+// isVerify the copy is verified against the temporary object, see copyVerified.
+func commitAtomicCopy(tempClnt, targetClnt client.Client, isVerify bool) *probe.Error {
+	tempURL := tempClnt.GetURL()
+	copyFn := func() *probe.Error {
+		return targetClnt.Copy(tempURL, client.CopyOptions{})
+	}
+	var err *probe.Error
+	if isVerify {
+		err = copyVerified(tempClnt, targetClnt, copyFn)
+	} else {
+		err = copyFn()
+	}
+	if err != nil {
+		return err.Trace(tempURL.String(), targetClnt.GetURL().String())
+	}
+	errorIf(tempClnt.Remove(false, "").Trace(tempURL.String()), "Unable to remove temporary object ‘"+tempURL.String()+"’.")
+	return nil
+}
+
+// cleanupAtomicCopy - remove what the failed atomic copy to clnt, a temporary object or a file,
+// left behind. Returns err, which reports whether an incomplete upload was removed.
+func cleanupAtomicCopy(clnt client.Client, err *probe.Error) *probe.Error {
+	clntURL := clnt.GetURL()
+	if clntURL.Type == client.Filesystem {
+		errorIf(fs.RemovePartial(clntURL.Path).Trace(clntURL.Path), "Unable to remove partial file of ‘"+clntURL.Path+"’.")
+		return err
+	}
+	err = abortIncompleteUpload(clnt, err)
+	// A failed server side copy leaves the complete temporary object.
+	if removeErr := clnt.Remove(false, ""); removeErr != nil {
+		switch removeErr.ToGoError().(type) {
+		case client.PathNotFound, client.ObjectMissing:
+		default:
+			errorIf(removeErr.Trace(clntURL.String()), "Unable to remove temporary object ‘"+clntURL.String()+"’.")
+		}
+	}
+	return err
+}
@@ -0,0 +1,155 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	. "gopkg.in/check.v1"
+)
+
+// atomicHandler serves objects like objectStoreHandler and removes them, recording for every
+// request whether the target "/bucket/report.csv" existed and held all of its data.
+type atomicHandler struct {
+	objectStoreHandler
+	requests []string // "METHOD path" of every request.
+	visible  []string // data of the target as seen by every request, if it existed.
+}
+
+func (h *atomicHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	h.requests = append(h.requests, r.Method+" "+r.URL.Path)
+	if object, ok := h.objects["/bucket/report.csv"]; ok {
+		h.visible = append(h.visible, string(object.data))
+	}
+	if r.Method == "DELETE" {
+		delete(h.objects, r.URL.Path)
+		h.mutex.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.mutex.Unlock()
+	h.objectStoreHandler.ServeHTTP(w, r)
+}
+
+// copyAtomic - copy source to target of alias "meta" with ‘--atomic’, returns the status.
+func copyAtomic(source, target string, size int64) copyURLs {
+	session := newTestSession()
+	session.Header.CommandBoolFlags = map[string]bool{"overwrite": true, "atomic": true}
+	cpURLs := copyURLs{
+		SourceContent: &client.Content{URL: *client.NewURL(source), Size: size},
+		TargetAlias:   "meta",
+		TargetContent: &client.Content{URL: *client.NewURL(target)},
+	}
+	cpQueue := make(chan bool, 1)
+	cpQueue <- true
+	statusCh := make(chan copyURLs, 1)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	doCopy(context.Background(), cpURLs, session, nil, nil, nil, cpQueue, wg, statusCh)
+	return <-statusCh
+}
+
+func (s *TestSuite) TestCopyAtomic(c *C) {
+	handler := &atomicHandler{objectStoreHandler: objectStoreHandler{objects: make(map[string]storedObject)}}
+	defer useMetadataServer(c, handler)()
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-atomic-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "report.csv")
+	c.Assert(ioutil.WriteFile(source, []byte("a,b,c\n1,2,3\n"), 0600), IsNil)
+	_, target, _, err := expandAlias("meta/bucket/report.csv")
+	c.Assert(err, IsNil)
+
+	status := copyAtomic(source, target, 12)
+	c.Assert(status.Error, IsNil)
+	c.Assert(string(handler.objects["/bucket/report.csv"].data), Equals, "a,b,c\n1,2,3\n")
+	// The data goes to a temporary object, the target is created by copying it once complete.
+	var temp string
+	for _, request := range handler.requests {
+		if strings.HasPrefix(request, "PUT /bucket/report.csv"+atomicSuffix) {
+			temp = strings.TrimPrefix(request, "PUT ")
+		}
+	}
+	c.Assert(temp, Not(Equals), "")
+	c.Assert(handler.copies, HasLen, 1)
+	c.Assert(handler.copies[0].Get("x-amz-copy-source"), Equals, temp)
+	c.Assert(handler.uploads, Equals, 1)
+	for _, visible := range handler.visible {
+		c.Assert(visible, Equals, "a,b,c\n1,2,3\n")
+	}
+	c.Assert(handler.requests[len(handler.requests)-1], Equals, "DELETE "+temp)
+	c.Assert(handler.objects, HasLen, 1)
+
+	// A failed copy leaves the old target as it was, and no temporary object.
+	handler.copyUnsupported = true
+	c.Assert(ioutil.WriteFile(source, []byte("x,y,z\n"), 0600), IsNil)
+	status = copyAtomic(source, target, 6)
+	c.Assert(status.Error, Not(IsNil))
+	c.Assert(handler.objects, HasLen, 1)
+	c.Assert(string(handler.objects["/bucket/report.csv"].data), Equals, "a,b,c\n1,2,3\n")
+	c.Assert(strings.HasPrefix(handler.requests[len(handler.requests)-1], "DELETE /bucket/report.csv"+atomicSuffix), Equals, true)
+
+	// Objects larger than a server side copy fail before anything is uploaded.
+	requests := len(handler.requests)
+	status = copyAtomic(source, target, maxAtomicSize+1)
+	c.Assert(status.Error, Not(IsNil))
+	c.Assert(len(handler.requests) <= requests+1, Equals, true) // the HEAD checking for the target, if any.
+}
+
+func (s *TestSuite) TestCopyAtomicFilesystem(c *C) {
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+	root, e := ioutil.TempDir(os.TempDir(), "mc-atomic-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	c.Assert(ioutil.WriteFile(source, []byte("hello"), 0600), IsNil)
+	target := filepath.Join(root, "target")
+
+	// Files are written to a partial file and renamed, nothing else is left behind.
+	status := copyAtomic(source, target, 5)
+	c.Assert(status.Error, IsNil)
+	data, e := ioutil.ReadFile(target)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello")
+	c.Assert(fs.PartialSize(target), Equals, int64(0))
+
+	// A source shorter than expected fails the copy, its partial file is removed.
+	failed := filepath.Join(root, "failed")
+	status = copyAtomic(source, failed, 10)
+	c.Assert(status.Error, Not(IsNil))
+	_, e = os.Stat(failed)
+	c.Assert(os.IsNotExist(e), Equals, true)
+	c.Assert(fs.PartialSize(failed), Equals, int64(0))
+	entries, e := ioutil.ReadDir(root)
+	c.Assert(e, IsNil)
+	c.Assert(entries, HasLen, 2)
+}
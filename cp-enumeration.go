@@ -0,0 +1,112 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// enumerationCheckpoint - how far listing the source of a cp session got. URLs prepared up to
+// Marker take the first DataSize bytes of the session data file, an interrupted listing resumes
+// after Marker instead of listing the whole source again.
+type enumerationCheckpoint struct {
+	Marker       string `json:"marker"`
+	DataSize     int64  `json:"dataSize"`
+	TotalBytes   int64  `json:"totalBytes"`
+	TotalObjects int    `json:"totalObjects"`
+}
+
+// enumerationCheckpointInterval - listing progress is saved at most this often.
+var enumerationCheckpointInterval = 10 * time.Second
+
+// enumerationPageKeys - objects listed per request while preparing URLs.
+var enumerationPageKeys = maxListKeys
+
+// saveEnumerationCheckpoint - save the session with its listing progress, replaced by tests.
+var saveEnumerationCheckpoint = func(session *sessionV6) *probe.Error {
+	return session.Save()
+}
+
+// isEnumerationCheckpointed - URLs of session are prepared page by page with checkpoints, for a
+// recursive copy of a single folder on object storage whose listing order is kept in the data file.
+func isEnumerationCheckpointed(session *sessionV6) bool {
+	header := session.Header
+	if len(header.CommandArgs) != 2 || !header.CommandBoolFlags["recursive"] || header.CommandBoolFlags["flatten"] ||
+		header.CommandBoolFlags["files-from"] || header.CommandStringFlags["order"] != "" || isCopyRange(session) {
+		return false
+	}
+	_, sourceURL, hostCfg, err := expandAlias(header.CommandArgs[0])
+	if err != nil || hostCfg == nil || client.NewURL(sourceURL).Type != client.Object {
+		return false
+	}
+	cpType, err := guessCopyURLType(header.CommandArgs[:1], header.CommandArgs[1], true)
+	return err == nil && cpType == copyURLsTypeC
+}
+
+// prepareCopyURLsPage - URLs to copy of the page listed after startAfter, filtered as the session
+// asks. The marker returned is where listing continues, empty once the source is listed.
+func prepareCopyURLsPage(session *sessionV6, startAfter string) (<-chan copyURLs, string) {
+	args := session.Header.CommandArgs
+	sourceAlias, _, _ := mustExpandAlias(args[0])
+	targetAlias, targetURL, _ := mustExpandAlias(args[1])
+
+	var contents []*client.Content
+	var marker string
+	sourceClient, err := newClient(args[0])
+	if err == nil {
+		contents, marker, err = sourceClient.ListPage(true, startAfter, enumerationPageKeys)
+	}
+	URLsCh := make(chan copyURLs, len(contents)+1)
+	if err != nil {
+		// Listing failed, it stops here as with a full listing.
+		URLsCh <- copyURLs{Error: err.Trace(args[0])}
+		marker = ""
+	}
+	for _, content := range contents {
+		if !content.Type.IsRegular() {
+			continue
+		}
+		URLsCh <- makeCopyContentTypeC(sourceAlias, sourceClient.GetURL(), content, targetAlias, targetURL)
+	}
+	close(URLsCh)
+	return filterSessionCopyURLs(URLsCh, session), marker
+}
+
+// checkpointEnumeration - save that URLs are prepared up to marker, with their totals.
+func checkpointEnumeration(session *sessionV6, marker string, totalBytes int64, totalObjects int) {
+	dataSize, e := session.DataFP.Seek(0, os.SEEK_CUR)
+	if e == nil {
+		// URLs up to dataSize are on disk before the session says so, a resume after a crash
+		// would otherwise truncate the data file beyond what was written.
+		e = session.DataFP.Sync()
+	}
+	if e != nil {
+		errorIf(probe.NewError(e), "Unable to checkpoint listing of session ‘"+session.SessionID+"’.")
+		return
+	}
+	session.Header.Enumeration = &enumerationCheckpoint{
+		Marker:       marker,
+		DataSize:     dataSize,
+		TotalBytes:   totalBytes,
+		TotalObjects: totalObjects,
+	}
+	errorIf(saveEnumerationCheckpoint(session).Trace(session.SessionID), "Unable to checkpoint listing of session ‘"+session.SessionID+"’.")
+}
@@ -0,0 +1,257 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// fan-out related constants.
+const (
+	// Bytes read from the source and written to every streamed target at a time,
+	// the most held in memory by the tee whatever the size of the object.
+	fanOutChunkSize = 256 * 1024
+	// Part size of uploads to streamed targets.
+	fanOutPartSize = pipeMinPartSize
+)
+
+// prepareFanOutURLs - copyURLs of sourceURL to every target, a folder target gets an object of the
+// source's name. Failures to prepare a target are reported in its copyURLs.
+func prepareFanOutURLs(sourceURL string, targetURLs []string) []copyURLs {
+	var URLs []copyURLs
+	for _, targetURL := range targetURLs {
+		var cpURLs copyURLs
+		if isTargetURLDir(targetURL) {
+			cpURLs = prepareCopyURLsTypeB(sourceURL, targetURL)
+		} else {
+			cpURLs = prepareCopyURLsTypeA(sourceURL, targetURL)
+		}
+		if cpURLs.Error != nil {
+			_, sourceURLFull, _ := mustExpandAlias(sourceURL)
+			_, targetURLFull, _ := mustExpandAlias(targetURL)
+			cpURLs.SourceContent = &client.Content{URL: *client.NewURL(sourceURLFull)}
+			cpURLs.TargetContent = &client.Content{URL: *client.NewURL(targetURLFull)}
+		}
+		URLs = append(URLs, cpURLs)
+	}
+	return URLs
+}
+
+// isServerSideCopy - reports if the source is copied to the target server side, both
+// are on the same host and accessed with the same credentials.
+func isServerSideCopy(cpURLs copyURLs) bool {
+	sourceCfg, err := getHostConfig(cpURLs.SourceAlias)
+	if err != nil {
+		return false
+	}
+	targetCfg, err := getHostConfig(cpURLs.TargetAlias)
+	if err != nil {
+		return false
+	}
+	if cpURLs.SourceAlias == cpURLs.TargetAlias {
+		return true
+	}
+	return sourceCfg.URL == targetCfg.URL && sourceCfg.AccessKey == targetCfg.AccessKey
+}
+
+// copyServerSide - copy the source to the target without transferring its data, metadata and
+// tags are copied or replaced as directives say. With isVerify the copy is verified against the
+// source, see copyVerified.
+func copyServerSide(cpURLs copyURLs, isVerify bool, directives copyDirectives) *probe.Error {
+	targetURL := cpURLs.TargetContent.URL.String()
+	targetClnt, err := newClientFromAlias(cpURLs.TargetAlias, targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	options := directives.options(cpURLs.TargetAlias, targetURL)
+	copyFn := func() *probe.Error {
+		return targetClnt.Copy(cpURLs.SourceContent.URL, options)
+	}
+	if !isVerify {
+		return copyFn().Trace(targetURL)
+	}
+	sourceURL := cpURLs.SourceContent.URL.String()
+	sourceClnt, err := newClientFromAlias(cpURLs.SourceAlias, sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	return copyVerified(sourceClnt, targetClnt, copyFn).Trace(targetURL)
+}
+
+// copyStreamed - copy the source of all URLs to their targets, reading it only once. Every
+// copyURLs is sent to statusCh with Error set if its target failed.
+func copyStreamed(URLs []copyURLs, statusCh chan<- copyURLs) {
+	failAll := func(err *probe.Error) {
+		for _, cpURLs := range URLs {
+			cpURLs.Error = err
+			statusCh <- cpURLs
+		}
+	}
+	sourceAlias := URLs[0].SourceAlias
+	sourceURL := URLs[0].SourceContent.URL.String()
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURL)
+	if err != nil {
+		failAll(err.Trace(sourceURL))
+		return
+	}
+	reader, err := sourceClnt.Get(0, 0, "")
+	if err != nil {
+		failAll(err.Trace(sourceURL))
+		return
+	}
+
+	wg := new(sync.WaitGroup)
+	writers := make([]*io.PipeWriter, len(URLs))
+	for i, cpURLs := range URLs {
+		pipeReader, pipeWriter := io.Pipe()
+		writers[i] = pipeWriter
+		wg.Add(1)
+		go func(cpURLs copyURLs, pipeReader *io.PipeReader) {
+			defer wg.Done()
+			targetURL := cpURLs.TargetContent.URL.String()
+			targetClnt, err := newClientFromAlias(cpURLs.TargetAlias, targetURL)
+			if err == nil {
+				err = targetClnt.PutStream(pipeReader, fanOutPartSize, guessURLContentType(targetURL))
+			}
+			// The tee stops writing to a failed target.
+			if err != nil {
+				pipeReader.CloseWithError(err.ToGoError())
+				cpURLs.Error = err.Trace(targetURL)
+			}
+			statusCh <- cpURLs
+		}(cpURLs, pipeReader)
+	}
+	teeToWriters(reader, writers)
+	wg.Wait()
+}
+
+// teeToWriters - copy src to all writers a chunk at a time. A chunk is written to every writer
+// before the next one is read, the slowest writer holds back the others and the source so at
+// most one chunk is buffered. Failed writers are dropped, the others continue.
+func teeToWriters(src io.Reader, writers []*io.PipeWriter) {
+	live := append([]*io.PipeWriter(nil), writers...)
+	buf := make([]byte, fanOutChunkSize)
+	for len(live) > 0 {
+		n, e := src.Read(buf)
+		if n > 0 {
+			var kept []*io.PipeWriter
+			for _, writer := range live {
+				if _, we := writer.Write(buf[:n]); we == nil {
+					kept = append(kept, writer)
+				}
+			}
+			live = kept
+		}
+		if e == io.EOF {
+			for _, writer := range live {
+				writer.Close()
+			}
+			return
+		}
+		if e != nil {
+			for _, writer := range live {
+				writer.CloseWithError(e)
+			}
+			return
+		}
+	}
+}
+
+// copyFanOut - copy to all targets of URLs concurrently, at most workers at a time. Targets
+// on the source's host are copied server side, all others share a single read of the source
+// taking one worker. Every copyURLs is sent to statusCh, with Error set if it failed. Server
+// side copies are verified with isVerify, their metadata and tags follow directives.
+func copyFanOut(URLs []copyURLs, workers int, isVerify bool, directives copyDirectives, statusCh chan<- copyURLs) {
+	var serverSide, streamed []copyURLs
+	for _, cpURLs := range URLs {
+		switch {
+		case cpURLs.Error != nil:
+			statusCh <- cpURLs
+		case isServerSideCopy(cpURLs):
+			serverSide = append(serverSide, cpURLs)
+		default:
+			streamed = append(streamed, cpURLs)
+		}
+	}
+
+	wg := new(sync.WaitGroup)
+	queue := make(chan bool, workers)
+	if len(streamed) > 0 {
+		queue <- true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-queue }()
+			copyStreamed(streamed, statusCh)
+		}()
+	}
+	for _, cpURLs := range serverSide {
+		queue <- true
+		wg.Add(1)
+		go func(cpURLs copyURLs) {
+			defer wg.Done()
+			defer func() { <-queue }()
+			start := time.Now()
+			cpURLs.Error = copyServerSide(cpURLs, isVerify, directives)
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+		}(cpURLs)
+	}
+	wg.Wait()
+}
+
+// doCopyFanOut - copy sourceURL to every target, returns the summary of the transfer. Fan-out
+// is not resumed, a session is only kept in memory for the summary. Server side copies are verified
+// with isVerify, their metadata and tags follow directives.
+func doCopyFanOut(sourceURL string, targetURLs []string, workers int, isSummary, isVerify bool, directives copyDirectives) *transferSummary {
+	session := &sessionV6{Header: &sessionV6Header{
+		When:             time.Now(),
+		CommandType:      "cp",
+		CommandBoolFlags: map[string]bool{"summary": isSummary},
+	}}
+	summary := newTransferSummary(session.Header.When)
+
+	URLs := prepareFanOutURLs(sourceURL, targetURLs)
+	for _, cpURLs := range URLs {
+		session.Header.TotalObjects++
+		session.Header.TotalBytes += cpURLs.SourceContent.Size
+	}
+
+	statusCh := make(chan copyURLs)
+	go func() {
+		defer close(statusCh)
+		copyFanOut(URLs, workers, isVerify, directives, statusCh)
+	}()
+	for cpURLs := range statusCh {
+		source := cpURLs.SourceContent.URL.String()
+		target := cpURLs.TargetContent.URL.String()
+		if cpURLs.Error != nil {
+			summary.Failed(cpURLs.SourceContent.Size, target, cpURLs.Error)
+			errorIf(cpURLs.Error.Trace(source, target), "Failed to copy ‘"+source+"’ to ‘"+target+"’.")
+			continue
+		}
+		summary.Transferred(cpURLs.SourceContent.Size)
+		printMsg(copyMessage{Source: source, Target: target})
+	}
+	printSummary(session, summary)
+	return summary
+}
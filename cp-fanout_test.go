@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// getCountingHandler - counts GETs of object data served by objectStoreHandler.
+type getCountingHandler struct {
+	*objectStoreHandler
+	gets int32
+}
+
+func (h *getCountingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" && r.URL.RawQuery == "" {
+		atomic.AddInt32(&h.gets, 1)
+	}
+	h.objectStoreHandler.ServeHTTP(w, r)
+}
+
+func (s *TestSuite) TestCopyFanOut(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	data := []byte("release data")
+	handler := &getCountingHandler{objectStoreHandler: &objectStoreHandler{objects: map[string]storedObject{
+		"/bucket/release.tar": {data: data, header: make(http.Header)},
+	}}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	// Same host under other credentials is streamed, like any other host.
+	c.Assert(setAlias("fan", hostConfigV7{URL: server.URL, AccessKey: "BKIKJAA5BMMU2RHO6IBB", SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12", API: "S3v4"}), IsNil)
+	c.Assert(setAlias("fanb", hostConfigV7{URL: server.URL, AccessKey: "CKIKJAA5BMMU2RHO6IBB", SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12", API: "S3v4"}), IsNil)
+
+	root, e := ioutil.TempDir("", "mc-fanout-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	targets := []string{"fan/bucket/copy-a", "fanb/bucket/copy-b", root + string(filepath.Separator)}
+	summary := doCopyFanOut("fan/bucket/release.tar", targets, 2, false, true, copyDirectives{})
+	c.Assert(summary.exitCode(), Equals, 0)
+	c.Assert(summary.transferredObjects, Equals, 3)
+
+	// One server side copy, the source is read once for both streamed targets.
+	c.Assert(handler.copies, HasLen, 1)
+	c.Assert(handler.uploads, Equals, 1)
+	c.Assert(atomic.LoadInt32(&handler.gets), Equals, int32(1))
+	c.Assert(handler.objects["/bucket/copy-a"].data, DeepEquals, data)
+	c.Assert(handler.objects["/bucket/copy-b"].data, DeepEquals, data)
+	local, e := ioutil.ReadFile(filepath.Join(root, "release.tar"))
+	c.Assert(e, IsNil)
+	c.Assert(local, DeepEquals, data)
+
+	// A failed target, under a file, does not stop the others.
+	summary = doCopyFanOut("fan/bucket/release.tar", []string{"fan/bucket/copy-c", filepath.Join(root, "release.tar", "x")}, 2, false, false, copyDirectives{})
+	c.Assert(summary.failedObjects, Equals, 1)
+	c.Assert(handler.objects["/bucket/copy-c"].data, DeepEquals, data)
+}
+
+// countingReader - counts bytes read from a reader.
+type countingReader struct {
+	io.Reader
+	read int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, e := r.Reader.Read(p)
+	atomic.AddInt64(&r.read, int64(n))
+	return n, e
+}
+
+func (s *TestSuite) TestTeeBackPressure(c *C) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4*fanOutChunkSize/16)
+	source := &countingReader{Reader: bytes.NewReader(data)}
+
+	fastReader, fastWriter := io.Pipe()
+	slowReader, slowWriter := io.Pipe()
+	release := make(chan struct{})
+	var fast, slow []byte
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		fast, _ = ioutil.ReadAll(fastReader)
+	}()
+	go func() {
+		defer wg.Done()
+		<-release
+		slow, _ = ioutil.ReadAll(slowReader)
+	}()
+	go teeToWriters(source, []*io.PipeWriter{fastWriter, slowWriter})
+
+	// The slow writer holds back the source, a single chunk is read ahead.
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(atomic.LoadInt64(&source.read) <= fanOutChunkSize, Equals, true)
+
+	close(release)
+	wg.Wait()
+	c.Assert(fast, DeepEquals, data)
+	c.Assert(slow, DeepEquals, data)
+
+	// A failed writer is dropped, the others get everything.
+	source = &countingReader{Reader: bytes.NewReader(data)}
+	okReader, okWriter := io.Pipe()
+	failedReader, failedWriter := io.Pipe()
+	failedReader.Close()
+	var ok []byte
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ok, _ = ioutil.ReadAll(okReader)
+	}()
+	teeToWriters(source, []*io.PipeWriter{failedWriter, okWriter})
+	<-done
+	c.Assert(ok, DeepEquals, data)
+}
@@ -0,0 +1,121 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// readFilesFrom - source URLs listed one per line in the file filesFrom, or on standard input
+// for ‘-’. Blank lines and lines starting with ‘#’ are ignored.
+func readFilesFrom(filesFrom string) ([]string, *probe.Error) {
+	var reader io.Reader = os.Stdin
+	if filesFrom != "-" {
+		file, e := os.Open(filesFrom)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		defer file.Close()
+		reader = file
+	}
+	var sourceURLs []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		sourceURLs = append(sourceURLs, line)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return sourceURLs, nil
+}
+
+// isURLSeparator - true for the separators of object and filesystem URLs.
+func isURLSeparator(c byte) bool {
+	return c == '/' || c == os.PathSeparator
+}
+
+// relativeToBase - path of sourceURL below base, both as given on the command line or in the list.
+func relativeToBase(sourceURL, base string) (string, *probe.Error) {
+	relPath := strings.TrimPrefix(sourceURL, base)
+	if relPath == sourceURL {
+		return "", errSourceNotBelowBase(sourceURL, base).Trace(sourceURL, base)
+	}
+	// ‘backup’ is the base of ‘backup/a’, not of ‘backup2/a’.
+	if relPath != "" && !isURLSeparator(base[len(base)-1]) && !isURLSeparator(relPath[0]) {
+		return "", errSourceNotBelowBase(sourceURL, base).Trace(sourceURL, base)
+	}
+	relPath = strings.TrimLeft(relPath, "/"+string(os.PathSeparator))
+	if relPath == "" {
+		return "", errSourceNotBelowBase(sourceURL, base).Trace(sourceURL, base)
+	}
+	return relPath, nil
+}
+
+// prepareCopyURLsListed - copy URLs of a file read by ‘--files-from’, copied into the folder
+// targetURL by its path below base, or by its name without a base.
+func prepareCopyURLsListed(sourceURL, targetURL, base string) copyURLs {
+	var relPath string
+	if base != "" {
+		var err *probe.Error
+		if relPath, err = relativeToBase(sourceURL, base); err != nil {
+			return copyURLs{Error: err}
+		}
+	}
+	cpURLs := prepareCopyURLsTypeB(sourceURL, targetURL)
+	if cpURLs.Error != nil || base == "" {
+		return cpURLs
+	}
+	_, targetURL, _ = mustExpandAlias(targetURL)
+	newTargetURL := urlJoinSourcePath(targetURL, cpURLs.SourceContent.URL, relPath)
+	cpURLs.TargetContent = &client.Content{URL: *client.NewURL(newTargetURL)}
+	return cpURLs
+}
+
+// prepareCopyURLsFromList - prepares copy URLs of the files read by ‘--files-from’, up to
+// workers files are looked up in parallel.
+func prepareCopyURLsFromList(sourceURLs []string, targetURL, base string, workers int) <-chan copyURLs {
+	copyURLsCh := make(chan copyURLs)
+	go func() {
+		defer close(copyURLsCh)
+		sourceQueue := make(chan bool, workers)
+		wg := new(sync.WaitGroup)
+		for _, sourceURL := range sourceURLs {
+			sourceQueue <- true
+			wg.Add(1)
+			go func(sourceURL string) {
+				defer wg.Done()
+				defer func() {
+					<-sourceQueue
+				}()
+				copyURLsCh <- prepareCopyURLsListed(sourceURL, targetURL, base)
+			}(sourceURL)
+		}
+		wg.Wait()
+	}()
+	return copyURLsCh
+}
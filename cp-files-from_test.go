@@ -0,0 +1,103 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestReadFilesFrom(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-files-from-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	list := filepath.Join(root, "list")
+	c.Assert(ioutil.WriteFile(list, []byte("# nightly backup\nbackup/a.tar\n\n  \nbackup/logs/b.log\r\n  # old logs\nbackup/c d.tar\n"), 0600), IsNil)
+	sourceURLs, err := readFilesFrom(list)
+	c.Assert(err, IsNil)
+	c.Assert(sourceURLs, DeepEquals, []string{"backup/a.tar", "backup/logs/b.log", "backup/c d.tar"})
+
+	_, err = readFilesFrom(filepath.Join(root, "missing"))
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *TestSuite) TestRelativeToBase(c *C) {
+	relPath, err := relativeToBase("backup/logs/b.log", "backup/")
+	c.Assert(err, IsNil)
+	c.Assert(relPath, Equals, "logs/b.log")
+	relPath, err = relativeToBase("backup/logs/b.log", "backup")
+	c.Assert(err, IsNil)
+	c.Assert(relPath, Equals, "logs/b.log")
+
+	for _, sourceURL := range []string{"backup2/a.tar", "other/a.tar", "backup/"} {
+		_, err = relativeToBase(sourceURL, "backup")
+		c.Assert(err, Not(IsNil), Commentf("source %s", sourceURL))
+	}
+}
+
+func (s *TestSuite) TestCopyFilesFrom(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-files-from-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	base := filepath.Join(root, "backup") + string(os.PathSeparator)
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	names := []string{"a.tar", filepath.Join("logs", "b.log"), filepath.Join("logs", "2015", "c.log")}
+	list := "# files of the nightly backup\n\n"
+	for _, name := range names {
+		source := filepath.Join(base, name)
+		c.Assert(os.MkdirAll(filepath.Dir(source), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(source, []byte(name), 0600), IsNil)
+		list += source + "\n"
+	}
+	// Missing files are reported, the others are copied with ‘--continue-on-error’.
+	list += filepath.Join(base, "missing.tar") + "\n"
+	listFile := filepath.Join(root, "list")
+	c.Assert(ioutil.WriteFile(listFile, []byte(list), 0600), IsNil)
+
+	sourceURLs, err := readFilesFrom(listFile)
+	c.Assert(err, IsNil)
+	c.Assert(len(sourceURLs), Equals, 4)
+
+	session := newTestCopySession(c, sourceURLs, target, 2, true)
+	session.Header.CommandBoolFlags["files-from"] = true
+	session.Header.CommandStringFlags["base"] = base
+	defer session.Delete()
+	summary := doCopySession(session)
+	msg := summary.Message(session)
+	c.Assert(msg.Transferred, Equals, 3)
+	c.Assert(msg.Failed, Equals, 0)
+	for _, name := range names {
+		data, e := ioutil.ReadFile(filepath.Join(target, name))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, name)
+	}
+
+	// Without a base files are copied by their name.
+	for cpURLs := range prepareCopyURLsFromList(sourceURLs[:2], target, "", 1) {
+		c.Assert(cpURLs.Error, IsNil)
+		c.Assert(cpURLs.TargetContent.URL.Path, Equals, filepath.Join(target, filepath.Base(cpURLs.SourceContent.URL.Path)))
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// defaultFlushInterval - ‘cp --follow’ uploads what was appended this often.
+const defaultFlushInterval = 10 * time.Second
+
+// checkCopySyntaxFollow verifies a single local file is followed into an object.
+func checkCopySyntaxFollow(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Option --follow takes a single SOURCE and TARGET.")
+	}
+	for _, option := range []string{"recursive", "flatten", "fan-out", "archive", "extract", "append", "if-changed", "remove-source", "transaction"} {
+		if ctx.Bool(option) {
+			fatalIf(errInvalidArgument().Trace(), "Option --follow cannot be used with --"+option+".")
+		}
+	}
+	if ctx.String("files-from") != "" {
+		fatalIf(errInvalidArgument().Trace(), "Option --follow cannot be used with --files-from.")
+	}
+	if ctx.Duration("flush-interval") <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --flush-interval must be positive.")
+	}
+	srcURL, tgtURL := ctx.Args().Get(0), ctx.Args().Get(1)
+	_, srcContent, err := url2Stat(srcURL)
+	fatalIf(err.Trace(srcURL), "Unable to stat source ‘"+srcURL+"’.")
+	if srcContent.URL.Type != client.Filesystem || !srcContent.Type.IsRegular() {
+		fatalIf(errInvalidArgument().Trace(srcURL), "Option --follow follows a local file, source ‘"+srcURL+"’ is not one.")
+	}
+	if isTargetURLDir(tgtURL) {
+		fatalIf(errInvalidArgument().Trace(tgtURL), "Option --follow uploads into an object, target ‘"+tgtURL+"’ is a folder.")
+	}
+}
+
+// followedFile - a local file ‘cp --follow’ uploads as it grows. Once the file is truncated or rotated
+// it goes on to TARGET.1, TARGET.2 and so on.
+type followedFile struct {
+	path      string
+	file      *os.File
+	sourceURL string
+	alias     string
+	targetURL string // expanded target.
+	aliased   string // target as given.
+	rotations int
+	uploaded  int64 // size of the file at its last upload, -1 before the first.
+}
+
+// objectURL - object the file goes to, aliased as given or expanded.
+func (f *followedFile) objectURL(targetURL string) string {
+	if f.rotations == 0 {
+		return targetURL
+	}
+	return targetURL + "." + strconv.Itoa(f.rotations)
+}
+
+// flush - upload all of the file if it changed size since the last upload, to a new object if it shrank.
+// Objects are immutable, every upload replaces the object.
+func (f *followedFile) flush() *probe.Error {
+	st, e := f.file.Stat()
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if st.Size() == f.uploaded {
+		return nil
+	}
+	if st.Size() < f.uploaded {
+		f.rotations++
+	}
+	urlStr := f.objectURL(f.targetURL)
+	clnt, err := newClientFromAlias(f.alias, urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	if err = clnt.Put(io.NewSectionReader(f.file, 0, st.Size()), st.Size(), guessURLContentType(urlStr), nil); err != nil {
+		return err.Trace(urlStr)
+	}
+	f.uploaded = st.Size()
+	printMsg(copyMessage{Source: f.sourceURL, Target: f.objectURL(f.aliased)})
+	return nil
+}
+
+// rotate - follow the file now at the path once the one followed so far was renamed or removed, and
+// another took its place. What was written to the previous file is uploaded first.
+func (f *followedFile) rotate() *probe.Error {
+	current, e := os.Stat(f.path)
+	if e != nil {
+		// Nothing took its place yet.
+		return nil
+	}
+	st, e := f.file.Stat()
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if os.SameFile(current, st) {
+		return nil
+	}
+	if err := f.flush(); err != nil {
+		return err.Trace(f.path)
+	}
+	next, e := os.Open(f.path)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	f.file.Close()
+	f.file = next
+	f.rotations++
+	f.uploaded = -1
+	return nil
+}
+
+// doCopyFollow - upload the local file sourceURL to targetURL, and again every interval once it changed,
+// like ‘tail -f’. Once stopCh fires it is uploaded a last time.
+func doCopyFollow(sourceURL, targetURL string, interval time.Duration, stopCh <-chan bool) *probe.Error {
+	_, sourcePath, _ := mustExpandAlias(sourceURL)
+	alias, targetURLFull, _, err := expandAlias(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	file, e := os.Open(sourcePath)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	f := &followedFile{path: sourcePath, file: file, sourceURL: sourceURL, alias: alias,
+		targetURL: targetURLFull, aliased: targetURL, uploaded: -1}
+	defer func() { f.file.Close() }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err = f.rotate(); err != nil {
+			return err.Trace(sourceURL)
+		}
+		if err = f.flush(); err != nil {
+			return err.Trace(sourceURL)
+		}
+		select {
+		case <-stopCh:
+			// The last upload, of what was written since the previous one.
+			if err = f.rotate(); err != nil {
+				return err.Trace(sourceURL)
+			}
+			return f.flush().Trace(sourceURL)
+		case <-ticker.C:
+		}
+	}
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,11 +29,15 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-go"
 	"github.com/minio/minio-xl/pkg/probe"
 	"github.com/minio/pb"
 )
@@ -48,6 +53,251 @@ var (
 			Name:  "recursive, r",
 			Usage: "Copy recursively.",
 		},
+		cli.BoolFlag{
+			Name:  "summary",
+			Usage: "Print a summary of objects transferred, skipped and failed.",
+		},
+		cli.BoolFlag{
+			Name:  "stats",
+			Usage: "Print a histogram of object throughput and the time requests spent in DNS, connect, TLS, first byte and transfer.",
+		},
+		cli.StringFlag{
+			Name:  "summary-file",
+			Usage: "Append a JSON line per object transferred, skipped or failed to this file.",
+		},
+		cli.StringFlag{
+			Name:  "order",
+			Usage: "Transfer objects ‘smallest-first’ or ‘largest-first’, all are listed before the first is transferred. Listing order by default.",
+		},
+		cli.BoolFlag{
+			Name:  "remove-source",
+			Usage: "Remove each source once its copy on the target is verified by size and checksum. Sources of failed copies are kept.",
+		},
+		cli.BoolFlag{
+			Name:  "prune-dirs",
+			Usage: "With --remove-source, remove the local source folders it left empty.",
+		},
+		cli.StringFlag{
+			Name:  "min-free",
+			Usage: "Keep this much free on a local target, such as ‘10GiB’. Transfers which would not leave it free abort before they start, or once free space runs low.",
+		},
+		cli.StringFlag{
+			Name:  "acl",
+			Usage: "Canned ACL of copied objects, such as ‘public-read’ or ‘bucket-owner-full-control’. Sets the permissions of local files where it can.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-acl",
+			Usage: "Give each copy the owner and grants of its source object, both have to be on object storage.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-charset",
+			Value: client.MetadataCharsetRFC2047,
+			Usage: "Send user metadata values outside printable ASCII as ‘rfc2047’ encoded words or as ‘percent’ escapes, decoded again when read. Rejected with ‘none’.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-hardlinks",
+			Usage: "Recreate hard links between local files on a local target, instead of copying the data of each again.",
+		},
+		cli.StringFlag{
+			Name:  "notify",
+			Usage: "POST a JSON summary to this http(s) URL once done, failed or interrupted.",
+		},
+		cli.IntFlag{
+			Name:  "concurrent",
+			Usage: "Upload N parts of a large object in parallel, defaults to 4. Memory held grows with N.",
+		},
+		cli.StringFlag{
+			Name:  "storage-class",
+			Usage: "Storage class of uploaded objects, such as REDUCED_REDUNDANCY, STANDARD_IA or GLACIER.",
+		},
+		cli.StringFlag{
+			Name:  "checksum",
+			Usage: "Send an additional checksum of uploaded objects, CRC32, CRC32C, SHA1 or SHA256, and verify it on download.",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "Sign requests for this region instead of the region of the alias or the one looked up, for this copy only.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-multipart",
+			Usage: "Upload objects of any size with a single PUT, for targets without multipart upload support.",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "Copy N objects in parallel, defaults to one less than the number of CPUs.",
+		},
+		cli.BoolFlag{
+			Name:  "continue-on-error",
+			Usage: "Continue with the remaining objects on any error, failures are listed in the summary.",
+		},
+		cli.IntFlag{
+			Name:  "skip-errors",
+			Usage: "Tolerate up to N failed objects, abort the whole copy on one more. Off by default.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve",
+			Usage: "Preserve file mode, modification time, content type and user metadata.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-content-type-guess",
+			Usage: "Upload without a content type guessed from the name, the server applies its default. Content types of --preserve and --metadata-from-file still apply.",
+		},
+		cli.StringFlag{
+			Name:  "symlinks",
+			Value: fs.SymlinksFollow,
+			Usage: "Local symlinks: follow, skip, error, or store them as empty objects with their target in metadata. Symlinks looping back are never followed.",
+		},
+		cli.BoolFlag{
+			Name:  "sniff",
+			Usage: "Detect the content type of uploads from their first 512 bytes when the name has no known extension.",
+		},
+		cli.StringFlag{
+			Name:  "newer-than-file",
+			Usage: "Copy only objects modified after the modification time of this file, set to the start of the copy once it succeeds. Everything is copied if the file does not exist.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-from-file",
+			Usage: "JSON file mapping target keys or patterns to content type and user metadata, the most specific match applies.",
+		},
+		cli.StringFlag{
+			Name:  "cache-control-map",
+			Usage: "JSON file mapping patterns of target keys, e.g. \"*.html\", to Cache-Control of uploads, the most specific match applies.",
+		},
+		cli.StringFlag{
+			Name:  "attr",
+			Usage: "Set attributes of all uploads, \"KEY=VALUE;...\", Content-Type, Cache-Control or user metadata. Overrides --cache-control-map.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-directive",
+			Usage: "COPY or REPLACE content type and metadata of objects copied between servers, REPLACE sets those of --attr instead. COPY by default.",
+		},
+		cli.StringFlag{
+			Name:  "tagging-directive",
+			Usage: "COPY or REPLACE tags of server side copies of --fan-out, REPLACE drops them. Servers copy tags by default.",
+		},
+		cli.BoolFlag{
+			Name:  "raw",
+			Usage: "Download objects stored with Content-Encoding gzip as stored, instead of decompressing them.",
+		},
+		cli.StringFlag{
+			Name:  "min-speed",
+			Usage: "Abort and retry transfers slower than this, e.g. 64KiB, per second. Off by default.",
+		},
+		cli.StringFlag{
+			Name:  "bw-schedule",
+			Usage: "Cap bandwidth per time of day, e.g. \"09:00-17:00=2MB,17:00-09:00=0\". Zero is unlimited.",
+		},
+		cli.StringFlag{
+			Name:  "offset",
+			Usage: "Copy a range of the source file starting at this offset, e.g. 1GiB.",
+		},
+		cli.StringFlag{
+			Name:  "length",
+			Usage: "Copy a range of the source file of this length, e.g. 500MiB. Defaults to the rest of the file.",
+		},
+		cli.DurationFlag{
+			Name:  "stall-grace",
+			Value: defaultStallGrace,
+			Usage: "Time a transfer may stay below --min-speed before it is retried.",
+		},
+		cli.BoolFlag{
+			Name:  "if-not-present",
+			Usage: "Skip objects already present on the target.",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Overwrite objects present on the target without asking.",
+		},
+		cli.BoolFlag{
+			Name:  "if-changed",
+			Usage: "Download objects again only if they changed from the local files, asking the server with a conditional GET.",
+		},
+		cli.BoolFlag{
+			Name:  "append",
+			Usage: "Continue downloads to local files present, from their end, unless the object changed since.",
+		},
+		cli.BoolFlag{
+			Name:  "force-append",
+			Usage: "Continue downloads with --append even if the object changed.",
+		},
+		cli.BoolFlag{
+			Name:  "skip-identical",
+			Usage: "Skip local files identical to the local target by xxhash, a fast hash to find changed files, never to verify files of others.",
+		},
+		cli.BoolFlag{
+			Name:  "flatten",
+			Usage: "Copy all objects directly into the target folder, without the folders of the source.",
+		},
+		cli.StringFlag{
+			Name:  "on-collision",
+			Value: onCollisionFail,
+			Usage: "Objects of the same name with --flatten: fail, or suffix to copy them as NAME-1, NAME-2 and so on.",
+		},
+		cli.BoolFlag{
+			Name:  "no-abort-incomplete",
+			Usage: "Keep the incomplete upload of a failed multipart upload, to resume it later.",
+		},
+		cli.BoolFlag{
+			Name:  "verify",
+			Usage: "Verify downloads against the MD5 ETag or sha256 metadata of the object, corrupt files are downloaded again.",
+		},
+		cli.BoolFlag{
+			Name:  "store-sha256",
+			Usage: "Store the SHA256 of uploaded objects as sha256 metadata, for --verify to check even multipart objects against.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-etag",
+			Usage: "Verify server side copies of --atomic and --fan-out by size and MD5 ETag, copying a mismatch once more before failing.",
+		},
+		cli.BoolFlag{
+			Name:  "atomic",
+			Usage: "Upload to a temporary object and copy it to the target once complete, readers never see a partial object.",
+		},
+		cli.BoolFlag{
+			Name:  "exclude-empty",
+			Usage: "Skip zero byte files and objects.",
+		},
+		cli.BoolFlag{
+			Name:  "exclude-hidden",
+			Usage: "Skip files whose name starts with a dot, objects are never hidden.",
+		},
+		cli.StringFlag{
+			Name:  "temp-dir",
+			Usage: "Folder of temporary files, such as large members of an extracted archive and downloads to read only folders. Defaults to TMPDIR or the system temp folder.",
+		},
+		cli.BoolFlag{
+			Name:  "transaction",
+			Usage: "Copy all objects or none, on any failure the objects already copied are removed again.",
+		},
+		cli.BoolFlag{
+			Name:  "fan-out",
+			Usage: "Copy the first SOURCE to every TARGET following it, server side where a TARGET is on the same host.",
+		},
+		cli.StringFlag{
+			Name:  "files-from",
+			Usage: "Copy the files listed one per line in this file, or on standard input for ‘-’, into TARGET.",
+		},
+		cli.StringFlag{
+			Name:  "base",
+			Usage: "Copy files of --files-from to their path below this prefix, instead of by their name.",
+		},
+		cli.BoolFlag{
+			Name:  "archive",
+			Usage: "Write all objects below the SOURCE folder to standard output as a tar archive, TARGET is ‘-’.",
+		},
+		cli.BoolFlag{
+			Name:  "extract",
+			Usage: "Extract a tar archive read from standard input into the TARGET folder, SOURCE is ‘-’.",
+		},
+		cli.BoolFlag{
+			Name:  "follow",
+			Usage: "Keep uploading the local file SOURCE to the object TARGET as it grows, until interrupted. Once it is truncated or rotated the file goes to TARGET.1, TARGET.2 and so on.",
+		},
+		cli.DurationFlag{
+			Name:  "flush-interval",
+			Value: defaultFlushInterval,
+			Usage: "With --follow, upload what was appended this often.",
+		},
 	}
 )
 
@@ -62,6 +312,11 @@ var cpCmd = cli.Command{
 
 USAGE:
    mc {{.Name}} [FLAGS] SOURCE [SOURCE...] TARGET
+   mc {{.Name}} --fan-out [FLAGS] SOURCE TARGET [TARGET...]
+   mc {{.Name}} --files-from FILE [FLAGS] TARGET
+   mc {{.Name}} --archive [FLAGS] SOURCE -
+   mc {{.Name}} --extract [FLAGS] - TARGET
+   mc {{.Name}} --follow [FLAGS] SOURCE TARGET
 
 FLAGS:
   {{range .Flags}}{{.}}
@@ -84,6 +339,157 @@ EXAMPLES:
 
    6. Copy a local folder with space separated characters to Amazon S3 cloud storage.
       $ mc {{.Name}} --recursive 'workdir/documents/May 2014/' s3/miniocloud
+
+   7. Copy a folder recursively and post a JSON summary to a webhook when done.
+      $ mc {{.Name}} --recursive --notify https://hooks.example.com/mc backup/ s3/archive
+
+   8. Copy a large file to Amazon S3 cloud storage uploading 8 parts in parallel.
+      $ mc {{.Name}} --concurrent 8 backup/disk.img s3/archive/
+
+   9. Copy a folder to Amazon S3 cloud storage and back, keeping file mode and modification time.
+      $ mc {{.Name}} --recursive --preserve backup/ s3/archive/
+      $ mc {{.Name}} --recursive --preserve s3/archive/ restore/
+
+   10. Copy several files to Amazon S3 cloud storage, 8 at a time, without stopping on failures.
+      $ mc {{.Name}} --workers 8 --continue-on-error --summary *.log s3/logs/
+
+   11. Retry a download once it stays below 100KiB/s for over a minute.
+      $ mc {{.Name}} --min-speed 100KiB --stall-grace 1m s3/archive/backup.tar .
+
+   12. Copy a large file to a gateway without multipart upload support, with a single PUT.
+      $ mc {{.Name}} --disable-multipart backup/disk.img legacy/archive/
+
+   13. Copy a folder of old logs to Amazon S3 cloud storage, storing them as infrequently accessed.
+      $ mc {{.Name}} --recursive --storage-class STANDARD_IA logs/2014/ s3/archive/logs/
+
+   14. Download a folder from Amazon S3 cloud storage, checking every file against its object.
+      $ mc {{.Name}} --recursive --verify s3/archive/photos/ photos/
+
+   15. Copy a folder to Amazon S3 cloud storage, keeping an audit record of every object.
+      $ mc {{.Name}} --recursive --summary-file audit.jsonl backup/ s3/archive/
+
+   16. Copy only the photos not on Amazon S3 cloud storage yet, or replace all of them.
+      $ mc {{.Name}} --recursive --if-not-present photos/ s3/archive/photos/
+      $ mc {{.Name}} --recursive --overwrite photos/ s3/archive/photos/
+
+   17. Copy a folder to Amazon S3 cloud storage with CRC32C checksums, verified by the server.
+      $ mc {{.Name}} --recursive --checksum CRC32C backup/ s3/archive/
+
+   18. Replace a report read by others on Amazon S3 cloud storage, which never see it half written.
+      $ mc {{.Name}} --atomic --overwrite report.csv s3/reports/daily.csv
+
+   Objects present on the target are replaced after asking on a terminal, and kept otherwise,
+   unless --if-not-present, --overwrite, --if-changed or --append is set.
+
+   17. Copy a large video to Amazon S3 cloud storage, keeping the uploaded parts if it fails to resume later.
+      $ mc {{.Name}} --no-abort-incomplete movies/holiday.mp4 s3/videos/
+
+   18. Copy all photos of an album with subfolders into a single local folder, numbering photos of the same name.
+      $ mc {{.Name}} --recursive --flatten --on-collision suffix s3/photos/2015/ album/
+
+   19. Copy a release to two buckets on Amazon S3 cloud storage, server side, and to a Minio server, reading it once.
+      $ mc {{.Name}} --fan-out s3/builds/mc.tar.gz s3/mirror-eu/ s3/mirror-us/ play/releases/
+
+   20. Copy the files found by a script to Amazon S3 cloud storage, keeping their folders below ‘/var/backup/’.
+      $ find /var/backup -name '*.tar' | mc {{.Name}} --files-from - --base /var/backup/ --continue-on-error s3/archive/
+
+   21. Copy a folder to Amazon S3 cloud storage at no more than 2MB/s during office hours, at full speed otherwise.
+      $ mc {{.Name}} --recursive --bw-schedule "09:00-17:00=2MB,17:00-09:00=0" backup/ s3/archive/
+
+   22. Upload a website to Amazon S3 cloud storage, with content type and metadata per file from a JSON file.
+      $ cat site-meta.json
+      {"*.svg": {"Content-Type": "image/svg+xml"}, "downloads/*": {"owner": "releases"}, "downloads/*.sig": {"owner": "security"}}
+      $ mc {{.Name}} --recursive --metadata-from-file site-meta.json site/ s3/mysite/
+
+   23. Refresh a local copy of a dataset from Amazon S3 cloud storage, downloading only the files which changed.
+      $ mc {{.Name}} --recursive --if-changed s3/datasets/weather/ weather/
+
+   24. Finish a download from Amazon S3 cloud storage which another program left half done.
+      $ mc {{.Name}} --append s3/isos/debian-8.2.0-amd64-DVD-1.iso debian-8.2.0-amd64-DVD-1.iso
+
+   25. Back up a folder to an external disk, copying only the files whose contents changed.
+      $ mc {{.Name}} --recursive --skip-identical --overwrite photos/ /media/backup/photos/
+
+   26. Copy a folder to Amazon S3 cloud storage past a few flaky objects, stopping if more than 10 fail.
+      $ mc {{.Name}} --recursive --skip-errors 10 backup/ s3/archive/
+
+   27. Back up a folder of Amazon S3 cloud storage to a compressed tar archive, and restore it to a Minio server.
+      $ mc {{.Name}} --archive s3/documents/2015/ - | gzip > documents-2015.tgz
+      $ gunzip -c documents-2015.tgz | mc {{.Name}} --extract - play/documents/2015/
+
+   28. Copy a release to two buckets on Amazon S3 cloud storage server side, verifying both copies.
+      $ mc {{.Name}} --fan-out --preserve-etag s3/builds/mc.tar.gz s3/mirror-eu/ s3/mirror-us/
+
+   29. Upload a folder to a Minio server leaving content types to the server, except for SVG images.
+      $ mc {{.Name}} --recursive --disable-content-type-guess --metadata-from-file svg-meta.json site/ play/mysite/
+
+   30. Back up a folder keeping its symlinks as symlinks, and restore them with the folder.
+      $ mc {{.Name}} --recursive --symlinks store website/ s3/backup/website/
+      $ mc {{.Name}} --recursive --preserve s3/backup/website/ website-restored/
+
+   31. Upload scanned documents without extensions, detecting their content types from the files.
+      $ mc {{.Name}} --recursive --sniff scans/ s3/documents/scans/
+
+   32. Back up a folder of photos to Amazon S3 cloud storage nightly, copying only what was added or changed since the last backup.
+      $ mc {{.Name}} --recursive --newer-than-file ~/.photos-backup photos/ s3/backup/photos/
+
+   33. Upload to a bucket in Frankfurt through an alias of the global Amazon S3 endpoint, without looking up its region.
+      $ mc {{.Name}} --region eu-central-1 report.pdf s3/reports-eu/
+
+   34. Archive a folder to Amazon S3 cloud storage with the SHA256 of each file, and verify a later restore against it.
+      $ mc {{.Name}} --recursive --store-sha256 archive/ s3/archive/2015/
+      $ mc {{.Name}} --recursive --verify s3/archive/2015/ restored/
+
+   35. Find out whether a slow upload to Amazon S3 cloud storage is spent setting up connections or transferring data.
+      $ mc {{.Name}} --recursive --stats photos/ s3/backup/photos/
+
+   36. Copy a release image from a web server to Amazon S3 cloud storage, without a local copy in between.
+      $ mc {{.Name}} https://releases.example.com/os/release.iso s3/mirror/os/
+
+   37. Publish a website bundle to Amazon S3 cloud storage all at once, nothing of it is left behind if an upload fails.
+      $ mc {{.Name}} --recursive --transaction site/ s3/www/
+
+   38. Back up a home folder to Amazon S3 cloud storage without its dotfiles and empty files.
+      $ mc {{.Name}} --recursive --exclude-hidden --exclude-empty ~/ s3/backup/home/
+
+   39. Extract a tar archive of large videos to Amazon S3 cloud storage, spilling them to a roomy volume instead of /tmp.
+      $ mc {{.Name}} --extract --temp-dir /mnt/scratch - s3/videos/ < videos.tar
+
+   40. Publish a static site with Cache-Control by file type, such as {"*.html": "max-age=60", "*.js": "max-age=31536000"}.
+      $ mc {{.Name}} --recursive --cache-control-map cache.json site/ s3/www/
+
+   41. Upload a hotfix of a static site which must never be cached, whatever the Cache-Control map says.
+      $ mc {{.Name}} --cache-control-map cache.json --attr "Cache-Control=no-store" site/app.js s3/www/
+
+   42. Download logs stored with Content-Encoding gzip as stored, by default they are decompressed into "access.log".
+      $ mc {{.Name}} --raw s3/logs/access.log.gz ~/logs/
+
+   43. Copy objects between buckets server side, replacing their metadata and tags instead of copying them.
+      $ mc {{.Name}} --fan-out --metadata-directive REPLACE --tagging-directive REPLACE --attr "owner=ops" s3/jukebox/ s3/archive/
+
+   44. Upload 500MiB of a huge file starting at 1GiB, such as to re-upload a damaged chunk of it.
+      $ mc {{.Name}} --offset 1GiB --length 500MiB bigfile s3/backups/bigfile.chunk2
+
+   45. Copy a folder with the largest files first, to get them started while there is time left.
+      $ mc {{.Name}} --recursive --order largest-first videos/ s3/archive/
+
+   46. Move a folder of recordings to Amazon S3 cloud storage, removing the local folders it leaves empty.
+      $ mc {{.Name}} --recursive --remove-source --prune-dirs recordings/ s3/archive/recordings/
+
+   47. Download a bucket to a local disk, leaving at least 20GiB free on it.
+      $ mc {{.Name}} --recursive --min-free 20GiB s3/datasets/ /mnt/scratch/datasets/
+
+   48. Upload a folder to a bucket of another account, giving the bucket owner full control of the objects.
+      $ mc {{.Name}} --recursive --acl bucket-owner-full-control reports/ s3/partner-inbox/reports/
+
+   49. Copy a folder of snapshots sharing files by hard links to a backup disk, keeping them shared.
+      $ mc {{.Name}} --recursive --preserve-hardlinks /srv/snapshots/ /media/backup/snapshots/
+
+   50. Ship a log file to Amazon S3 cloud storage as it is written, uploading new lines every minute.
+      $ mc {{.Name}} --follow --flush-interval 1m /var/log/app.log s3/logs/web-1/app.log
+
+   51. Copy a scan keeping its original name as metadata, percent encoded for tools reading it as is.
+      $ mc {{.Name}} --metadata-charset percent --attr "original-name=Übersicht 2015.pdf" scan.pdf s3/archive/scans/
 `,
 }
 
@@ -129,19 +535,21 @@ func (c copyStatMessage) String() string {
 }
 
 // doCopy - Copy a singe file from source to destination
-func doCopy(cpURLs copyURLs, progressReader *barSend, accountingReader *accounter, cpQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
+func doCopy(ctx context.Context, cpURLs copyURLs, session *sessionV6, progressReader *barSend, renderer *progressRenderer, accountingReader *accounter, cpQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
 	defer wg.Done() // Notify that this copy routine is done.
 	defer func() {
 		<-cpQueue
 	}()
+	start := time.Now()
 
 	if cpURLs.Error != nil {
 		cpURLs.Error.Trace()
+		cpURLs.Duration = time.Since(start)
 		statusCh <- cpURLs
 		return
 	}
 
-	if !globalQuiet && !globalJSON {
+	if progressReader != nil {
 		progressReader.SetCaption(cpURLs.SourceContent.URL.String() + ": ")
 	}
 
@@ -151,154 +559,713 @@ func doCopy(cpURLs copyURLs, progressReader *barSend, accountingReader *accounte
 	targetURL := cpURLs.TargetContent.URL
 	length := cpURLs.SourceContent.Size
 
-	reader, err := getSourceFromAlias(sourceAlias, sourceURL.String())
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURL.String())
 	if err != nil {
-		if !globalQuiet && !globalJSON {
+		if progressReader != nil {
 			progressReader.ErrorGet(length)
 		}
 		cpURLs.Error = err.Trace(sourceURL.String())
+		cpURLs.Duration = time.Since(start)
+		statusCh <- cpURLs
+		return
+	}
+	// Objects left by an earlier run are copied at their current size, the totals follow. A range
+	// keeps its length.
+	if session != nil && session.resumed && !isCopyRange(session) {
+		if content, err := sourceClnt.Stat(); err == nil && content.Type.IsRegular() && content.Size != length {
+			if session.progress != nil {
+				session.progress.Resize(length, content.Size)
+			}
+			if progressReader != nil {
+				progressReader.AddTotal(content.Size - length)
+			}
+			cpURLs.SourceContent.Size = content.Size
+			length = content.Size
+		}
+	}
+	targetClnt, err := newClientFromAlias(targetAlias, targetURL.String())
+	if err != nil {
+		if progressReader != nil {
+			progressReader.ErrorPut(length)
+		}
+		cpURLs.Error = err.Trace(targetURL.String())
+		cpURLs.Duration = time.Since(start)
+		statusCh <- cpURLs
+		return
+	}
+	// Copies in flight are cancelled once the whole copy is aborted.
+	sourceClnt, targetClnt = sourceClnt.WithContext(ctx), targetClnt.WithContext(ctx)
+
+	// Objects stored gzip encoded are downloaded decompressed unless ‘--raw’ is set, the
+	// source is stat'ed once for its encoding.
+	if session != nil && !session.Header.CommandBoolFlags["raw"] && isResumableDownload(sourceClnt, targetClnt) {
+		sourceClnt = newStatCacheClient(sourceClnt, newStatCache())
+		if content, err := sourceClnt.Stat(); err == nil && content.Size > 0 && isGzipEncoded(content) {
+			cpURLs.Decompress = true
+			if gunzipURL := gunzipTargetURL(cpURLs.SourceContent, targetURL); gunzipURL != targetURL {
+				if targetClnt, err = newClientFromAlias(targetAlias, gunzipURL.String()); err != nil {
+					if progressReader != nil {
+						progressReader.ErrorPut(length)
+					}
+					cpURLs.Error = err.Trace(gunzipURL.String())
+					cpURLs.Duration = time.Since(start)
+					statusCh <- cpURLs
+					return
+				}
+				targetClnt = targetClnt.WithContext(ctx)
+				targetURL = gunzipURL
+				cpURLs.TargetContent = &client.Content{URL: gunzipURL}
+			}
+		}
+	}
+
+	// Local files are not copied over identical files with ‘--skip-identical’.
+	if globalHashCache != nil && isLocalCopy(sourceClnt, targetClnt) {
+		identical, err := globalHashCache.identical(sourceClnt.GetURL().Path, targetClnt.GetURL().Path)
+		if err != nil {
+			if progressReader != nil {
+				progressReader.ErrorGet(length)
+			}
+			cpURLs.Error = err.Trace(sourceURL.String())
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+		if identical {
+			cpURLs.Skipped = true
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+	}
+
+	// A present target is replaced with ‘--overwrite’, or if the user agrees to. With ‘--if-changed’
+	// a file is replaced once the server reports the object changed, with ‘--append’ it is continued.
+	isPresentChecked := session != nil && !session.Header.CommandBoolFlags["overwrite"] &&
+		!((session.Header.CommandBoolFlags["if-changed"] || session.Header.CommandBoolFlags["append"]) && isResumableDownload(sourceClnt, targetClnt))
+	// A rollback of ‘--transaction’ removes only the targets it created, it needs to know either way.
+	isTransaction := session != nil && session.Header.CommandBoolFlags["transaction"]
+	if isPresentChecked || isTransaction {
+		present, err := isTargetPresent(targetClnt)
+		if err != nil {
+			if progressReader != nil {
+				progressReader.ErrorPut(length)
+			}
+			cpURLs.Error = err.Trace(targetURL.String())
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+		if isPresentChecked && present && (session.Header.CommandBoolFlags["if-not-present"] || !confirmOverwrite(targetURL.String())) {
+			cpURLs.Skipped = true
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+		cpURLs.Replaced = present
+	}
+
+	// With ‘--preserve-hardlinks’ local files sharing the data of one copied before are linked to its copy.
+	if isHardlinkCopy(sourceClnt.GetURL(), targetClnt.GetURL()) {
+		linked, copied := globalHardlinks.link(cpURLs.SourceContent.HardlinkID, targetClnt.GetURL().Path)
+		if linked {
+			if progressReader != nil {
+				progressReader.Progress(length)
+			}
+			if accountingReader != nil && globalQuiet {
+				accountingReader.Add(length)
+			}
+			if session != nil && session.progress != nil {
+				session.progress.Done(sourceURL.String(), length)
+			}
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+		defer func() { copied(cpURLs.Error) }()
+	}
+
+	// With ‘--disable-content-type-guess’ no content type is sent, unless one is given explicitly.
+	var contentType string
+	if session == nil || !session.Header.CommandBoolFlags["disable-content-type-guess"] {
+		contentType = guessURLContentType(targetURL.String())
+		// With ‘--sniff’ unknown extensions are left to the content of the source, detected once it is read.
+		if session != nil && session.Header.CommandBoolFlags["sniff"] && lookupContentType(filepath.Base(targetURL.Path)) == "" {
+			contentType = ""
+		}
+	}
+	// Objects copied between servers keep their content type and metadata, as a server side copy does,
+	// unless ‘--metadata-directive REPLACE’ is set.
+	var metadata map[string]string
+	if (isObjectCopy(sourceClnt, targetClnt) && !isMetadataReplaced()) || (session != nil && session.Header.CommandBoolFlags["preserve"]) {
+		var sourceContentType string
+		sourceContentType, metadata, err = getPreservedMetadata(sourceClnt)
+		if err != nil {
+			if progressReader != nil {
+				progressReader.ErrorGet(length)
+			}
+			cpURLs.Error = err.Trace(sourceURL.String())
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+		if sourceContentType != "" {
+			contentType = sourceContentType
+		}
+	}
+	metadata = symlinkMetadata(cpURLs.SourceContent, metadata)
+	if globalMetadataRules != nil {
+		if rule := globalMetadataRules.match(metadataTargetKey(targetAlias, targetURL.String())); rule != nil {
+			contentType, metadata = rule.apply(contentType, metadata)
+		}
+	}
+	contentType, metadata = applyUploadAttrs(metadataTargetKey(targetAlias, targetURL.String()), contentType, metadata)
+
+	// With ‘--atomic’ objects are uploaded to a temporary object, the target appears only once complete.
+	putClnt := targetClnt
+	isAtomic := isAtomicCopy(session, targetClnt)
+	if isAtomic {
+		if putClnt, err = newAtomicTempClient(targetAlias, targetClnt, length); err != nil {
+			if progressReader != nil {
+				progressReader.ErrorPut(length)
+			}
+			cpURLs.Error = err.Trace(targetURL.String())
+			cpURLs.Duration = time.Since(start)
+			statusCh <- cpURLs
+			return
+		}
+	}
+
+	// Retryable failures, such as stalls and timeouts, are retried before giving up on the object.
+	sourceFailed := false
+	for attempt := 1; ; attempt++ {
+		var read int64
+		read, sourceFailed, err = copyObject(cpURLs, session, sourceClnt, putClnt, contentType, metadata, progressReader, renderer, accountingReader)
+		if err == nil || attempt >= copyAttempts || !isRetryable(err) {
+			break
+		}
+		// Progress of the failed attempt is taken back, it is transferred again.
+		if session != nil && session.progress != nil {
+			read = session.progress.Retry(sourceURL.String())
+		}
+		if progressReader != nil {
+			progressReader.ErrorPut(read)
+		}
+		if accountingReader != nil && globalQuiet {
+			accountingReader.Add(-read)
+		}
+	}
+	if session != nil && session.progress != nil {
+		if err == nil {
+			session.progress.Done(sourceURL.String(), length)
+		} else {
+			session.progress.Failed(sourceURL.String())
+		}
+	}
+	// The local file is up to date or already complete, nothing was downloaded.
+	if _, ok := err.ToGoError().(client.NotModified); ok {
+		cpURLs.Skipped = true
+		cpURLs.Duration = time.Since(start)
+		statusCh <- cpURLs
+		return
+	}
+	if err == nil && isAtomic {
+		err = commitAtomicCopy(putClnt, targetClnt, session.Header.CommandBoolFlags["preserve-etag"])
+	}
+	if err == nil && session != nil {
+		err = applyObjectACL(session, sourceClnt, targetClnt)
+	}
+	// With ‘--remove-source’ the source goes once its copy is verified, one which does not verify fails and keeps it.
+	if err == nil && session != nil && session.Header.CommandBoolFlags["remove-source"] {
+		err = removeVerifiedSource(sourceAlias, sourceClnt, targetAlias, targetClnt, cpURLs.Decompress)
+	}
+	switch {
+	case err == nil:
+	// Nothing of a failed atomic copy is kept, a temporary object is never resumed.
+	case session != nil && session.Header.CommandBoolFlags["atomic"]:
+		err = cleanupAtomicCopy(putClnt, err)
+	// Incomplete uploads are kept with ‘--no-abort-incomplete’, to be resumed later.
+	case !sourceFailed && session != nil && !session.Header.CommandBoolFlags["no-abort-incomplete"]:
+		err = abortIncompleteUpload(targetClnt, err)
+	}
+	if err != nil {
+		if progressReader != nil {
+			if sourceFailed {
+				progressReader.ErrorGet(length)
+			} else {
+				progressReader.ErrorPut(length)
+			}
+		}
+		cpURLs.Error = err
+		cpURLs.Duration = time.Since(start)
 		statusCh <- cpURLs
 		return
 	}
 
-	var newReader io.ReadSeeker
+	cpURLs.Error = nil // just for safety
+	cpURLs.Duration = time.Since(start)
+	statusCh <- cpURLs
+}
+
+// overwriteMutex - one question about overwriting a target at a time, answers are read with
+// overwriteReader. Answers typed ahead are kept for the questions that follow.
+var (
+	overwriteMutex  = new(sync.Mutex)
+	overwriteReader = bufio.NewReader(os.Stdin)
+)
+
+// isTargetPresent - an object or file is present at the URL of targetClnt, not found is not an
+// error. A folder in the way is not present, copying onto it fails instead.
+func isTargetPresent(targetClnt client.Client) (bool, *probe.Error) {
+	content, err := targetClnt.Stat()
+	if err != nil {
+		switch e := err.ToGoError().(type) {
+		case client.PathNotFound, client.ObjectMissing:
+			return false, nil
+		case minio.ErrorResponse:
+			if e.Code == "NoSuchKey" || e.Code == "NoSuchBucket" {
+				return false, nil
+			}
+		}
+		return false, err.Trace(targetClnt.GetURL().String())
+	}
+	return !content.Type.IsDir(), nil
+}
+
+// confirmOverwrite - ask whether to overwrite target, without a terminal to ask on it is kept.
+func confirmOverwrite(target string) bool {
+	if globalJSON || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	overwriteMutex.Lock()
+	defer overwriteMutex.Unlock()
+	if !globalQuiet {
+		console.Eraseline()
+	}
+	fmt.Printf("Overwrite ‘%s’? [y/N]: ", target)
+	answer, _ := overwriteReader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// copyAttempts - attempts to copy an object failing with a retryable error.
+const copyAttempts = 3
+
+// isRetryable - transient errors, such as stalls and timeouts, may succeed on retry.
+func isRetryable(err *probe.Error) bool {
+	retryable, ok := err.ToGoError().(interface {
+		Retryable() bool
+	})
+	return ok && retryable.Retryable()
+}
+
+// copyObject - a single attempt to copy an object, returns the number of bytes read from the source
+// and whether the source could not be read at all.
+func copyObject(cpURLs copyURLs, session *sessionV6, sourceClnt, targetClnt client.Client, contentType string, metadata map[string]string,
+	progressReader *barSend, renderer *progressRenderer, accountingReader *accounter) (read int64, sourceFailed bool, err *probe.Error) {
+	sourceAlias := cpURLs.SourceAlias
+	sourceURL := cpURLs.SourceContent.URL
+	targetAlias := cpURLs.TargetAlias
+	targetURL := cpURLs.TargetContent.URL
+	length := cpURLs.SourceContent.Size
+
+	// Downloads continue where an interrupted session left off, or a failed attempt. Decompressed
+	// downloads start over, offsets of the object are no offsets of the file. Of a range only the
+	// range is read, the upload is as long as the range.
+	var source io.ReadSeeker
+	switch {
+	case isCopyRange(session):
+		source, err = sourceClnt.Get(cpURLs.Offset, length, "")
+	case cpURLs.Decompress:
+		if err = fs.RemovePartial(targetClnt.GetURL().Path); err != nil {
+			return 0, false, err.Trace(targetURL.String())
+		}
+		source, err = sourceClnt.Get(0, 0, "")
+	default:
+		source, err = getResumableSource(session, sourceClnt, targetClnt)
+	}
+	if err != nil {
+		return 0, true, err.Trace(sourceURL.String())
+	}
+	// SHA256 of the data, hashed while it is uploaded.
+	var hashReader *sha256Reader
+	if session != nil && session.Header.CommandBoolFlags["store-sha256"] && targetClnt.GetURL().Type == client.Object {
+		hashReader = newSHA256Reader(source)
+		source = hashReader
+	}
+	// Content type left to the content by ‘--sniff’.
+	if contentType == "" && session != nil && session.Header.CommandBoolFlags["sniff"] {
+		if contentType = sniffContentType(source); contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+	var minSpeed int64
+	grace := defaultStallGrace
+	if session != nil {
+		minSpeed = int64(session.Header.CommandIntFlags["min-speed"])
+		if d, e := time.ParseDuration(session.Header.CommandStringFlags["stall-grace"]); e == nil && d > 0 {
+			grace = d
+		}
+	}
+	reader := newStallReader(newBandwidthReader(source, globalBandwidthLimiter), sourceURL.String(), minSpeed, grace)
+	defer reader.Stop()
+
+	// Progress is shared by all workers of the session.
+	var tracked io.ReadSeeker = reader
+	if session != nil && session.progress != nil {
+		tracked = session.progress.NewProxyReader(sourceURL.String(), reader)
+	}
+	newReader := tracked
 	if globalQuiet || globalJSON {
 		sourcePath := filepath.Join(sourceAlias, sourceURL.Path)
+		// Web sources have no alias, their host is shown instead.
+		if sourceAlias == "" && sourceURL.Type == client.Object {
+			sourcePath = sourceURL.String()
+		}
 		targetPath := filepath.Join(targetAlias, targetURL.Path)
 		printMsg(copyMessage{
 			Source: sourcePath,
 			Target: targetPath,
 		})
-		// No accounting necessary for JSON output.
-		if globalJSON {
-			newReader = reader
-		}
 		// Proxy reader to accounting reader only during quiet mode.
 		if globalQuiet {
-			newReader = accountingReader.NewProxyReader(reader)
+			newReader = accountingReader.NewProxyReader(tracked)
 		}
-	} else {
+	} else if progressReader != nil {
 		// set up progress
-		newReader = progressReader.NewProxyReader(reader)
+		newReader = client.NewProgressReader(tracked, progressReader.Progress)
 	}
-	err = putTargetFromAlias(targetAlias, targetURL.String(), newReader, length)
-	if err != nil {
-		if !globalQuiet && !globalJSON {
-			progressReader.ErrorPut(length)
+	if renderer != nil {
+		// Detailed progress with ETA for this object.
+		objectReader := renderer.NewProxyReader(sourceURL.String(), newReader, length)
+		defer renderer.Done(objectReader)
+		newReader = objectReader
+	}
+	// The decompressed size is unknown up front, the gzip checksum verifies it instead. The
+	// compressed length read must still be the size of the object.
+	if cpURLs.Decompress {
+		err = targetClnt.Put(newGunzipReader(newReader), -1, contentType, metadata)
+		if err == nil && reader.BytesRead() != length {
+			err = errDownloadIncomplete(sourceURL.String(), reader.BytesRead(), length)
 		}
-		cpURLs.Error = err.Trace(targetURL.String())
-		statusCh <- cpURLs
-		return
+	} else {
+		err = targetClnt.Put(newReader, length, contentType, metadata)
 	}
+	if err == nil && hashReader != nil {
+		err = storeSHA256(sourceClnt, targetClnt, hashReader, length)
+	}
+	if err == nil && session != nil && session.Header.CommandBoolFlags["verify"] && !cpURLs.Decompress && isResumableDownload(sourceClnt, targetClnt) {
+		err = verifyDownload(sourceAlias, sourceClnt, targetClnt.GetURL().Path)
+	}
+	if session != nil {
+		finishDownload(session, targetClnt, err == nil)
+	}
+	if err != nil {
+		return reader.BytesRead(), false, err.Trace(targetURL.String())
+	}
+	return reader.BytesRead(), false, nil
+}
 
-	cpURLs.Error = nil // just for safety
-	statusCh <- cpURLs
+// getPreservedMetadata - content type and metadata of the source to be kept on the target with ‘--preserve’.
+// A filesystem source reports its mode and modification time, object storage its user metadata.
+func getPreservedMetadata(sourceClnt client.Client) (contentType string, metadata map[string]string, err *probe.Error) {
+	sourceContent, err := sourceClnt.Stat()
+	if err != nil {
+		return "", nil, err.Trace()
+	}
+	return sourceContent.ContentType, sourceContent.Metadata, nil
+}
+
+// isObjectCopy - reports if an object is copied to an object, the source is stat'ed for its metadata then.
+func isObjectCopy(sourceClnt, targetClnt client.Client) bool {
+	return sourceClnt.GetURL().Type == client.Object && targetClnt.GetURL().Type == client.Object
+}
+
+// symlinkMetadata - metadata with the target of a symlink stored by ‘--symlinks store’ added, metadata itself is not modified.
+func symlinkMetadata(source *client.Content, metadata map[string]string) map[string]string {
+	if source == nil {
+		return metadata
+	}
+	target, ok := source.Metadata[client.MetadataSymlinkTarget]
+	if !ok {
+		return metadata
+	}
+	newMetadata := map[string]string{client.MetadataSymlinkTarget: target}
+	for k, v := range metadata {
+		newMetadata[k] = v
+	}
+	return newMetadata
 }
 
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(cURLs copyURLs, progressReader *barSend) {
-	if !globalQuiet && !globalJSON {
+	if progressReader != nil {
 		progressReader.Progress(cURLs.SourceContent.Size)
 	}
 }
 
+// doCopyDryRun - print objects which would be copied, skipping the ones isCopied reports as done.
+func doCopyDryRun(URLsCh <-chan copyURLs, isCopied func(int, string) bool) {
+	for cpURLs := range URLsCh {
+		if cpURLs.Error != nil {
+			errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
+			continue
+		}
+		if isCopied(cpURLs.Position, cpURLs.SourceContent.URL.String()) {
+			continue
+		}
+		printMsg(dryRunMessage{
+			Operation: "copy",
+			Source:    filepath.Join(cpURLs.SourceAlias, cpURLs.SourceContent.URL.Path),
+			Target:    filepath.Join(cpURLs.TargetAlias, cpURLs.TargetContent.URL.Path),
+			Size:      cpURLs.SourceContent.Size,
+		})
+	}
+}
+
+// prepareSessionCopyURLs - prepares copy URLs of the sources and target of a session.
+func prepareSessionCopyURLs(session *sessionV6) <-chan copyURLs {
+	args := session.Header.CommandArgs
+	workers := copyWorkers(session.Header.CommandIntFlags["workers"])
+	var URLsCh <-chan copyURLs
+	// Files read by ‘--files-from’ are saved as sources of the session.
+	if session.Header.CommandBoolFlags["files-from"] {
+		URLsCh = prepareCopyURLsFromList(args[:len(args)-1], args[len(args)-1], session.Header.CommandStringFlags["base"], workers)
+	} else {
+		URLsCh = prepareCopyURLs(args[:len(args)-1], args[len(args)-1], session.Header.CommandBoolFlags["recursive"],
+			workers, session.Header.CommandBoolFlags["flatten"], session.Header.CommandStringFlags["on-collision"])
+	}
+	return filterSessionCopyURLs(URLsCh, session)
+}
+
+// filterSessionCopyURLs - URLs the filters of the session leave to copy.
+func filterSessionCopyURLs(URLsCh <-chan copyURLs, session *sessionV6) <-chan copyURLs {
+	URLsCh = filterExcludedCopyURLs(filterNewerCopyURLs(URLsCh, getSessionNewerThan(session)), getSessionContentFilter(session))
+	return withCopyRange(URLsCh, session)
+}
+
+// copyURLsFromSession - copy URLs saved in a session, prepared again if the session has none.
+func copyURLsFromSession(session *sessionV6) <-chan copyURLs {
+	if !session.HasData() {
+		return prepareSessionCopyURLs(session)
+	}
+	URLsCh := make(chan copyURLs)
+	go func() {
+		defer close(URLsCh)
+		scanner := bufio.NewScanner(session.NewDataReader())
+		for position := 0; scanner.Scan(); position++ {
+			var cpURLs copyURLs
+			json.Unmarshal([]byte(scanner.Text()), &cpURLs)
+			cpURLs.Position = position
+			URLsCh <- cpURLs
+		}
+	}()
+	return URLsCh
+}
+
 // doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
 func doPrepareCopyURLs(session *sessionV6, trapCh <-chan bool) {
-	// Separate source and target. 'cp' can take only one target,
-	// but any number of sources.
-	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
-	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1] // Last one is target
-
 	var totalBytes int64
 	var totalObjects int
 
-	// Access recursive flag inside the session header.
-	isRecursive := session.Header.CommandBoolFlags["recursive"]
-
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
+	// Large folders on object storage are listed page by page, an interrupted listing continues
+	// from its last checkpoint.
+	checkpointed := isEnumerationCheckpointed(session)
+	startAfter := ""
+	var writer io.Writer
+	if checkpoint := session.Header.Enumeration; checkpointed && checkpoint != nil {
+		writer = session.ResumeDataWriter(checkpoint.DataSize)
+		startAfter, totalBytes, totalObjects = checkpoint.Marker, checkpoint.TotalBytes, checkpoint.TotalObjects
+	} else {
+		writer = session.NewDataWriter()
+	}
+	// Create a session data file to store the processed URLs, in the order of ‘--order’.
+	dataFP := newOrderedDataWriter(writer, session.Header.CommandStringFlags["order"])
 
 	var scanBar scanBarFunc
 	if !globalQuiet && !globalJSON { // set up progress bar
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareCopyURLs(sourceURLs, targetURL, isRecursive)
-	done := false
+	prepare := func(URLsCh <-chan copyURLs) {
+		done := false
+		for done == false {
+			select {
+			case cpURLs, ok := <-URLsCh:
+				if !ok { // Done with URL prepration
+					done = true
+					break
+				}
+				if cpURLs.Error != nil {
+					// Print in new line and adjust to top so that we don't print over the ongoing scan bar
+					if !globalQuiet && !globalJSON {
+						console.Eraseline()
+					}
+					// Listed files have to be present, unless the rest is copied regardless.
+					if session.Header.CommandBoolFlags["files-from"] && !session.Header.CommandBoolFlags["continue-on-error"] {
+						session.Delete()
+						fatalIf(cpURLs.Error.Trace(), "Unable to prepare listed file for copying, use --continue-on-error to copy the others.")
+					}
+					if strings.Contains(cpURLs.Error.ToGoError().Error(), " is a folder.") {
+						errorIf(cpURLs.Error.Trace(), "Folder cannot be copied. Please use ‘...’ suffix.")
+					} else {
+						errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
+					}
+					break
+				}
 
-	for done == false {
-		select {
-		case cpURLs, ok := <-URLsCh:
-			if !ok { // Done with URL prepration
-				done = true
-				break
-			}
-			if cpURLs.Error != nil {
+				jsonData, err := json.Marshal(cpURLs)
+				if err != nil {
+					session.Delete()
+					fatalIf(probe.NewError(err), "Unable to prepare URL for copying. Error in JSON marshaling.")
+				}
+				dataFP.add(string(jsonData), cpURLs.SourceContent.Size)
+				if !globalQuiet && !globalJSON {
+					scanBar(cpURLs.SourceContent.URL.String())
+				}
+
+				// Sources of unknown size, served without a Content-Length, add nothing.
+				if cpURLs.SourceContent.Size > 0 {
+					totalBytes += cpURLs.SourceContent.Size
+				}
+				totalObjects++
+			case <-trapCh:
 				// Print in new line and adjust to top so that we don't print over the ongoing scan bar
 				if !globalQuiet && !globalJSON {
 					console.Eraseline()
 				}
-				if strings.Contains(cpURLs.Error.ToGoError().Error(), " is a folder.") {
-					errorIf(cpURLs.Error.Trace(), "Folder cannot be copied. Please use ‘...’ suffix.")
-				} else {
-					errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
+				// Listing continues from its last checkpoint on resume.
+				if session.Header.Enumeration != nil {
+					session.CloseAndDie(nil)
 				}
-				break
+				session.Delete() // If we are interrupted during the URL scanning, we drop the session.
+				os.Exit(0)
 			}
+		}
+	}
 
-			jsonData, err := json.Marshal(cpURLs)
-			if err != nil {
-				session.Delete()
-				fatalIf(probe.NewError(err), "Unable to prepare URL for copying. Error in JSON marshaling.")
-			}
-			fmt.Fprintln(dataFP, string(jsonData))
-			if !globalQuiet && !globalJSON {
-				scanBar(cpURLs.SourceContent.URL.String())
+	if !checkpointed {
+		prepare(prepareSessionCopyURLs(session))
+	} else {
+		lastCheckpoint := time.Now()
+		for {
+			URLsCh, marker := prepareCopyURLsPage(session, startAfter)
+			prepare(URLsCh)
+			if marker == "" {
+				break
 			}
-
-			totalBytes += cpURLs.SourceContent.Size
-			totalObjects++
-		case <-trapCh:
-			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-			if !globalQuiet && !globalJSON {
-				console.Eraseline()
+			startAfter = marker
+			if time.Since(lastCheckpoint) >= enumerationCheckpointInterval {
+				checkpointEnumeration(session, startAfter, totalBytes, totalObjects)
+				lastCheckpoint = time.Now()
 			}
-			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
-			os.Exit(0)
 		}
+		session.Header.Enumeration = nil
 	}
+	dataFP.flush()
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
-	session.Save()
+	session.SaveOrDie()
+}
+
+// copyWorkers - objects copied in parallel, workers if set, one less than the number of CPUs otherwise.
+// Never more than ‘--max-conns-per-host’, further workers would only wait for a connection.
+func copyWorkers(workers int) int {
+	if workers <= 0 {
+		workers = int(math.Max(float64(runtime.NumCPU())-1, 1))
+	}
+	if globalMaxConnsPerHost > 0 && workers > globalMaxConnsPerHost {
+		return globalMaxConnsPerHost
+	}
+	return workers
 }
 
-func doCopySession(session *sessionV6) {
+// doCopySession - copy all objects of session, returns the summary of the transfer.
+func doCopySession(session *sessionV6) *transferSummary {
 	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	globalPartConcurrency = session.Header.CommandIntFlags["concurrent"]
+	globalDisableMultipart = session.Header.CommandBoolFlags["disable-multipart"]
+	globalStorageClass = session.Header.CommandStringFlags["storage-class"]
+	globalCannedACL = session.Header.CommandStringFlags["acl"]
+	globalMetadataCharset = session.Header.CommandStringFlags["metadata-charset"]
+	globalHardlinks = getSessionHardlinks(session)
+	globalChecksumAlgorithm = session.Header.CommandStringFlags["checksum"]
+	globalRegion = session.Header.CommandStringFlags["region"]
+	globalBandwidthLimiter = getSessionBandwidthLimiter(session)
+	globalMetadataRules = getSessionMetadataRules(session)
+	globalCacheControlMap, globalUploadAttrs = getSessionUploadAttrs(session)
+	globalMetadataDirective = session.Header.CommandStringFlags["metadata-directive"]
+	globalHashCache = getSessionHashCache(session)
+	globalTransferStats = getSessionTransferStats(session)
+	globalSymlinks = session.Header.CommandStringFlags["symlinks"]
+	setTempDir(session.Header.CommandStringFlags["temp-dir"])
 
-	if !session.HasData() {
+	// Buckets signed for a region they are not in fail before any object is copied.
+	for _, arg := range session.Header.CommandArgs {
+		fatalIf(verifyAliasRegion(arg), "Unable to copy to or from ‘"+arg+"’.")
+	}
+
+	session.resumed = session.HasData()
+	if !session.resumed {
 		doPrepareCopyURLs(session, trapCh)
 	}
 
+	// Progress of all workers, saved with the session. A resumed session starts with what was copied before.
+	session.progress = newCopyProgress(session)
+	progress := session.progress.Stat()
+
 	// Enable accounting reader by default.
 	accntReader := newAccounter(session.Header.TotalBytes)
 
+	// Single object transfers render a detailed progress bar with ETA, including
+	// periodic progress messages in JSON mode.
+	var renderer *progressRenderer
+	if session.Header.TotalObjects == 1 && !globalQuiet {
+		renderer = newProgressRenderer()
+		defer renderer.Stop()
+	}
+
 	// Enable progress bar reader only during default mode.
 	var progressReader *barSend
-	if !globalQuiet && !globalJSON { // set up progress bar
-		progressReader = newProgressBar(session.Header.TotalBytes)
+	if !globalQuiet && !globalJSON && renderer == nil { // set up progress bar
+		progressReader = newProgressBar(progress.TotalBytes)
+		if progress.TransferredBytes > 0 {
+			progressReader.Resume(progress.TransferredBytes)
+		}
 	}
 
 	// Prepare URL scanner from session data file.
 	scanner := bufio.NewScanner(session.NewDataReader())
 	// isCopied returns true if an object has been already copied
 	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
+	isCopied := isCopiedAtFactory(session.Header.Copied, session.Header.LastCopied)
 
 	wg := new(sync.WaitGroup)
-	// Limit number of copy routines based on available CPU resources.
-	cpQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+	// Limit number of copy routines, based on available CPU resources unless set.
+	cpQueue := make(chan bool, copyWorkers(session.Header.CommandIntFlags["workers"]))
 	defer close(cpQueue)
 
+	// Summary of objects transferred, skipped and failed, each is also recorded with ‘--summary-file’.
+	// Elapsed time and speed are those of this run, objects copied by earlier runs count as skipped.
+	summary := newTransferSummary(time.Now())
+	records, err := openSummaryFile(session)
+	fatalIf(err.Trace(session.Header.CommandStringFlags["summary-file"]), "Unable to open summary file.")
+	defer records.Close()
+
+	// Failures beyond ‘--skip-errors’ cancel ctx, stopping the copy.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	budget := getSessionErrorBudget(session, cancel)
+	// The first failure with ‘--transaction’ cancels ctx as well, everything copied is rolled back.
+	transaction := getSessionTransaction(session, cancel)
+	// A local target needs room for what is left to copy, running low while copying cancels ctx too.
+	freeSpace, err := newFreeSpaceGuard(session, session.Header.CommandArgs[len(session.Header.CommandArgs)-1], remainingBytes(session.progress), cancel)
+	if err != nil {
+		summary.Abort(err.Trace())
+		return summary
+	}
+	defer freeSpace.stop()
+
 	// Status channel for receiveing copy return status.
 	statusCh := make(chan copyURLs)
 
@@ -310,7 +1277,7 @@ func doCopySession(session *sessionV6) {
 			select {
 			case cpURLs, ok := <-statusCh: // Receive status.
 				if !ok { // We are done here. Top level function has returned.
-					if !globalQuiet && !globalJSON {
+					if progressReader != nil {
 						progressReader.Finish()
 					}
 					if globalQuiet {
@@ -322,18 +1289,65 @@ func doCopySession(session *sessionV6) {
 						}
 						console.Println(console.Colorize("Copy", cpStatMessage.String()))
 					}
+					printSummary(session, summary)
+					printTransferStats(globalTransferStats)
+					notifySummary(session, summary, false)
 					return
 				}
+				if cpURLs.Skipped {
+					doCopyFake(cpURLs, progressReader)
+					summary.Skipped(cpURLs.SourceContent.Size)
+					records.Record(recordSkipped, cpURLs.SourceContent, cpURLs.TargetContent, cpURLs.Duration, nil)
+					// Resume continues after it, the target is not looked at again.
+					session.markCopied(cpURLs.Position, cpURLs.SourceContent.URL.String(), cpURLs.SourceContent.Size)
+					session.Save()
+					continue
+				}
 				if cpURLs.Error == nil {
-					session.Header.LastCopied = cpURLs.SourceContent.URL.String()
+					summary.Transferred(cpURLs.SourceContent.Size)
+					globalTransferStats.Transferred(cpURLs.SourceContent.Size, cpURLs.Duration)
+					records.Record(recordTransferred, cpURLs.SourceContent, cpURLs.TargetContent, cpURLs.Duration, nil)
+					session.markCopied(cpURLs.Position, cpURLs.SourceContent.URL.String(), cpURLs.SourceContent.Size)
 					session.Save()
+					if transaction != nil {
+						transaction.Copied(cpURLs)
+					}
+				} else if budget != nil && budget.exceeded() {
+					// Copies cancelled by the abort are not failures, a resumed session copies them.
+					continue
+				} else if transaction != nil && transaction.Failed() {
+					// Copies cancelled by the failed transaction are not failures of their own.
+					continue
+				} else if freeSpace.failed() != nil {
+					// Neither are those cancelled for lack of free space, a resumed session copies them.
+					continue
 				} else {
+					summary.Failed(cpURLs.SourceContent.Size, cpURLs.SourceContent.URL.String(), cpURLs.Error)
+					records.Record(recordFailed, cpURLs.SourceContent, cpURLs.TargetContent, cpURLs.Duration, cpURLs.Error)
 					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
 					if !globalQuiet && !globalJSON {
 						console.Eraseline()
 					}
 					errorIf(cpURLs.Error.Trace(cpURLs.SourceContent.URL.String()),
 						fmt.Sprintf("Failed to copy ‘%s’.", cpURLs.SourceContent.URL.String()))
+					// Any failure fails a transaction, it is rolled back once copies in flight are done.
+					if transaction != nil {
+						transaction.Fail()
+						continue
+					}
+					// Failures up to ‘--skip-errors’ are tolerated, the first beyond aborts the copy.
+					if budget != nil {
+						if budget.fail() {
+							summary.Tolerated()
+						} else {
+							summary.Abort(errTooManyFailures(int(budget.limit)).Trace())
+						}
+						continue
+					}
+					// all errors are collected into the summary with ‘--continue-on-error’.
+					if session.Header.CommandBoolFlags["continue-on-error"] {
+						continue
+					}
 					// for all non critical errors we can continue for the remaining files
 					switch cpURLs.Error.ToGoError().(type) {
 					// handle this specifically for filesystem related errors.
@@ -347,13 +1361,23 @@ func doCopySession(session *sessionV6) {
 						continue
 					}
 					// for critical errors we should exit. Session can be resumed after the user figures out the problem
-					session.CloseAndDie()
+					printSummary(session, summary)
+					notifySummary(session, summary, false)
+					session.CloseAndDie(cpURLs.Error)
 				}
 			case <-trapCh: // Receive interrupt notification.
+				// An interrupted transaction is rolled back like a failed one, it is never resumed.
+				if transaction != nil {
+					transaction.Fail()
+					summary.Abort(errTransactionInterrupted().Trace())
+					continue
+				}
 				if !globalQuiet && !globalJSON {
 					console.Eraseline()
 				}
-				session.CloseAndDie()
+				printSummary(session, summary)
+				notifySummary(session, summary, true)
+				session.CloseAndDie(nil)
 			}
 		}
 	}()
@@ -365,25 +1389,75 @@ func doCopySession(session *sessionV6) {
 		copyWg := new(sync.WaitGroup)
 		defer close(statusCh)
 
-		for scanner.Scan() {
+		for position := 0; scanner.Scan(); position++ {
 			var cpURLs copyURLs
 			json.Unmarshal([]byte(scanner.Text()), &cpURLs)
-			if isCopied(cpURLs.SourceContent.URL.String()) {
-				doCopyFake(cpURLs, progressReader)
+			cpURLs.Position = position
+			// Progress of objects copied before is already accounted for.
+			if isCopied(cpURLs.Position, cpURLs.SourceContent.URL.String()) {
+				summary.Skipped(cpURLs.SourceContent.Size)
+				records.Record(recordSkipped, cpURLs.SourceContent, cpURLs.TargetContent, 0, nil)
 			} else {
 				// Wait for other copy routines to
 				// complete. We only have limited CPU
 				// and network resources.
 				cpQueue <- true
+				// Nothing more is copied once the copy was aborted.
+				if ctx.Err() != nil {
+					<-cpQueue
+					break
+				}
 				// Account for each copy routines we start.
 				copyWg.Add(1)
 				// Do copying in background concurrently.
-				go doCopy(cpURLs, progressReader, accntReader, cpQueue, copyWg, statusCh)
+				go doCopy(ctx, cpURLs, session, progressReader, renderer, accntReader, cpQueue, copyWg, statusCh)
 			}
 		}
 		copyWg.Wait()
 	}()
 	wg.Wait()
+	if transaction != nil && transaction.Failed() {
+		printMsg(transaction.Rollback())
+	}
+	if err := freeSpace.failed(); err != nil {
+		summary.Abort(err.Trace())
+	}
+	pruneSourceDirs(session, session.Header.CommandArgs[:len(session.Header.CommandArgs)-1])
+	if globalHashCache != nil {
+		errorIf(globalHashCache.save().Trace(), "Unable to save hashes of local files.")
+	}
+	// The next copy with ‘--newer-than-file’ continues from the start of this one.
+	if summary.exitCode() == 0 && summary.abortError() == nil {
+		errorIf(touchNewerThanFile(session).Trace(), "Unable to update the reference file of --newer-than-file.")
+	}
+	return summary
+}
+
+// newCopyProgress - progress of the objects prepared for session, of a resumed session objects
+// copied before, by their position and their size when prepared, count as transferred.
+func newCopyProgress(session *sessionV6) *progressAccumulator {
+	if !session.resumed {
+		return newProgressAccumulator(session.Header.TotalBytes, session.Header.TotalObjects)
+	}
+	isCopied := isCopiedAtFactory(session.Header.Copied, session.Header.LastCopied)
+	var totalBytes, copiedBytes int64
+	var totalObjects, copiedObjects int
+	scanner := bufio.NewScanner(session.NewDataReader())
+	for position := 0; scanner.Scan(); position++ {
+		var cpURLs copyURLs
+		if json.Unmarshal(scanner.Bytes(), &cpURLs) != nil || cpURLs.SourceContent == nil {
+			continue
+		}
+		totalBytes += cpURLs.SourceContent.Size
+		totalObjects++
+		if isCopied(position, cpURLs.SourceContent.URL.String()) {
+			copiedBytes += cpURLs.SourceContent.Size
+			copiedObjects++
+		}
+	}
+	progress := newProgressAccumulator(totalBytes, totalObjects)
+	progress.AddCompleted(copiedBytes, copiedObjects)
+	return progress
 }
 
 // mainCopy is the entry point for cp command.
@@ -396,10 +1470,164 @@ func mainCopy(ctx *cli.Context) {
 
 	// Additional command speific theme customization.
 	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Summary", color.New(color.FgCyan, color.Bold))
+
+	// Temporary files must not fail a copy half way through.
+	setTempDir(ctx.String("temp-dir"))
+	fatalIf(checkTempDir(getTempDir()).Trace(getTempDir()), "Unable to use temp folder ‘"+getTempDir()+"’.")
+
+	// Fan-out copies a single source to many targets, it is not resumed.
+	if ctx.Bool("fan-out") {
+		args := ctx.Args()
+		globalCacheControlMap, globalUploadAttrs = loadUploadAttrs(ctx.String("cache-control-map"), ctx.String("attr"))
+		metadataDirective, _ := parseCopyDirective(ctx.String("metadata-directive"))
+		taggingDirective, _ := parseCopyDirective(ctx.String("tagging-directive"))
+		directives := copyDirectives{metadata: metadataDirective, tagging: taggingDirective}
+		summary := doCopyFanOut(args[0], args[1:], copyWorkers(ctx.Int("workers")), ctx.Bool("summary"), ctx.Bool("preserve-etag"), directives)
+		exitOnFailures(summary)
+		return
+	}
+
+	// Archives are streamed from and to standard input and output, they are not resumed.
+	if ctx.Bool("archive") {
+		sourceURL := ctx.Args().First()
+		fatalIf(doCopyArchive(sourceURL, os.Stdout).Trace(sourceURL), "Unable to archive ‘"+sourceURL+"’.")
+		return
+	}
+	// A followed file is uploaded until interrupted, it is not resumed.
+	if ctx.Bool("follow") {
+		sourceURL, targetURL := ctx.Args().Get(0), ctx.Args().Get(1)
+		trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+		fatalIf(doCopyFollow(sourceURL, targetURL, ctx.Duration("flush-interval"), trapCh).Trace(sourceURL, targetURL), "Unable to follow ‘"+sourceURL+"’.")
+		return
+	}
+	if ctx.Bool("extract") {
+		targetURL := ctx.Args().Get(1)
+		trapSpillFiles()
+		minFree, _ := parseMinFree(ctx.String("min-free"))
+		fatalIf(doCopyExtract(os.Stdin, targetURL, minFree).Trace(targetURL), "Unable to extract archive into ‘"+targetURL+"’.")
+		return
+	}
+
+	// Files listed by ‘--files-from’ are copied as sources of TARGET.
+	args := ctx.Args()
+	if filesFrom := ctx.String("files-from"); filesFrom != "" {
+		sourceURLs, err := readFilesFrom(filesFrom)
+		fatalIf(err.Trace(filesFrom), "Unable to read files to copy from ‘"+filesFrom+"’.")
+		args = append(sourceURLs, args...)
+	}
+
+	if ctx.Bool("exclude-hidden") {
+		warnExcludeHidden(args[:len(args)-1])
+	}
+
+	// Objects modified before the reference file of ‘--newer-than-file’ are left out.
+	newerThanFile := ctx.String("newer-than-file")
+	cutoff, err := newerThanFileCutoff(newerThanFile)
+	fatalIf(err.Trace(newerThanFile), "Unable to read the modification time of ‘"+newerThanFile+"’.")
+
+	if globalDryRun {
+		// Dry run is never resumed, no session is necessary.
+		globalRegion = ctx.String("region")
+		var URLsCh <-chan copyURLs
+		if ctx.String("files-from") != "" {
+			URLsCh = prepareCopyURLsFromList(args[:len(args)-1], args[len(args)-1], ctx.String("base"), copyWorkers(ctx.Int("workers")))
+		} else {
+			URLsCh = prepareCopyURLs(args[:len(args)-1], args[len(args)-1], ctx.Bool("recursive"), copyWorkers(ctx.Int("workers")),
+				ctx.Bool("flatten"), ctx.String("on-collision"))
+		}
+		filter := contentFilter{excludeEmpty: ctx.Bool("exclude-empty"), excludeHidden: ctx.Bool("exclude-hidden")}
+		doCopyDryRun(filterExcludedCopyURLs(filterNewerCopyURLs(URLsCh, cutoff), filter), isCopiedAtFactory(copiedPositions{}, ""))
+		return
+	}
 
 	session := newSessionV6()
 	session.Header.CommandType = "cp"
 	session.Header.CommandBoolFlags["recursive"] = ctx.Bool("recursive")
+	session.Header.CommandBoolFlags["summary"] = ctx.Bool("summary")
+	session.Header.CommandBoolFlags["stats"] = ctx.Bool("stats")
+	session.Header.CommandStringFlags["order"] = ctx.String("order")
+	session.Header.CommandBoolFlags["remove-source"] = ctx.Bool("remove-source")
+	session.Header.CommandBoolFlags["prune-dirs"] = ctx.Bool("prune-dirs")
+	session.Header.CommandStringFlags["min-free"] = ctx.String("min-free")
+	session.Header.CommandStringFlags["acl"] = ctx.String("acl")
+	session.Header.CommandStringFlags["metadata-charset"] = ctx.String("metadata-charset")
+	session.Header.CommandBoolFlags["preserve-acl"] = ctx.Bool("preserve-acl")
+	session.Header.CommandBoolFlags["preserve-hardlinks"] = ctx.Bool("preserve-hardlinks")
+	if summaryFile := ctx.String("summary-file"); summaryFile != "" {
+		summaryFilePath, err := getSummaryFilePath(summaryFile)
+		fatalIf(err.Trace(summaryFile), "Invalid summary file ‘"+summaryFile+"’.")
+		session.Header.CommandStringFlags["summary-file"] = summaryFilePath
+	}
+	session.Header.CommandBoolFlags["preserve"] = ctx.Bool("preserve")
+	session.Header.CommandBoolFlags["disable-content-type-guess"] = ctx.Bool("disable-content-type-guess")
+	session.Header.CommandBoolFlags["sniff"] = ctx.Bool("sniff")
+	session.Header.CommandBoolFlags["raw"] = ctx.Bool("raw")
+	session.Header.CommandBoolFlags["continue-on-error"] = ctx.Bool("continue-on-error")
+	if ctx.IsSet("skip-errors") {
+		session.Header.CommandIntFlags["skip-errors"] = ctx.Int("skip-errors")
+	}
+	session.Header.CommandBoolFlags["disable-multipart"] = ctx.Bool("disable-multipart")
+	session.Header.CommandBoolFlags["verify"] = ctx.Bool("verify")
+	session.Header.CommandBoolFlags["store-sha256"] = ctx.Bool("store-sha256")
+	session.Header.CommandBoolFlags["if-not-present"] = ctx.Bool("if-not-present")
+	session.Header.CommandBoolFlags["overwrite"] = ctx.Bool("overwrite")
+	session.Header.CommandBoolFlags["if-changed"] = ctx.Bool("if-changed")
+	session.Header.CommandBoolFlags["append"] = ctx.Bool("append")
+	session.Header.CommandBoolFlags["force-append"] = ctx.Bool("force-append")
+	session.Header.CommandBoolFlags["skip-identical"] = ctx.Bool("skip-identical")
+	session.Header.CommandBoolFlags["no-abort-incomplete"] = ctx.Bool("no-abort-incomplete")
+	session.Header.CommandBoolFlags["atomic"] = ctx.Bool("atomic")
+	session.Header.CommandBoolFlags["transaction"] = ctx.Bool("transaction")
+	session.Header.CommandBoolFlags["exclude-empty"] = ctx.Bool("exclude-empty")
+	session.Header.CommandBoolFlags["exclude-hidden"] = ctx.Bool("exclude-hidden")
+	session.Header.CommandBoolFlags["preserve-etag"] = ctx.Bool("preserve-etag")
+	session.Header.CommandBoolFlags["flatten"] = ctx.Bool("flatten")
+	session.Header.CommandStringFlags["on-collision"] = ctx.String("on-collision")
+	session.Header.CommandBoolFlags["files-from"] = ctx.String("files-from") != ""
+	session.Header.CommandStringFlags["base"] = ctx.String("base")
+	session.Header.CommandStringFlags["symlinks"] = ctx.String("symlinks")
+	session.Header.CommandStringFlags["temp-dir"] = ctx.String("temp-dir")
+	if err = setSessionNewerThan(session, newerThanFile, cutoff); err != nil {
+		session.Delete()
+		fatalIf(err.Trace(newerThanFile), "Unable to locate reference file ‘"+newerThanFile+"’.")
+	}
+	if workers := ctx.Int("workers"); workers > 0 {
+		session.Header.CommandIntFlags["workers"] = workers
+	}
+	if notifyURL := ctx.String("notify"); notifyURL != "" {
+		session.Header.CommandStringFlags["notify"] = notifyURL
+	}
+	if concurrent := ctx.Int("concurrent"); concurrent > 0 {
+		session.Header.CommandIntFlags["concurrent"] = concurrent
+	}
+	if storageClass := ctx.String("storage-class"); storageClass != "" {
+		session.Header.CommandStringFlags["storage-class"] = storageClass
+	}
+	if checksum := ctx.String("checksum"); checksum != "" {
+		session.Header.CommandStringFlags["checksum"], _ = checkChecksumAlgorithm(checksum)
+	}
+	if region := ctx.String("region"); region != "" {
+		session.Header.CommandStringFlags["region"] = region
+	}
+	if minSpeedStr := ctx.String("min-speed"); minSpeedStr != "" {
+		minSpeed, err := parseMinSpeed(minSpeedStr)
+		fatalIf(err.Trace(minSpeedStr), "Invalid minimum speed ‘"+minSpeedStr+"’.")
+		session.Header.CommandIntFlags["min-speed"] = int(minSpeed)
+		session.Header.CommandStringFlags["stall-grace"] = ctx.Duration("stall-grace").String()
+	}
+	if scheduleStr := ctx.String("bw-schedule"); scheduleStr != "" {
+		session.Header.CommandStringFlags["bw-schedule"] = scheduleStr
+	}
+	session.Header.CommandStringFlags["offset"] = ctx.String("offset")
+	session.Header.CommandStringFlags["length"] = ctx.String("length")
+	if metadataFile := ctx.String("metadata-from-file"); metadataFile != "" {
+		metadataFilePath, e := filepath.Abs(metadataFile)
+		fatalIf(probe.NewError(e), "Unable to locate metadata file ‘"+metadataFile+"’.")
+		session.Header.CommandStringFlags["metadata-from-file"] = metadataFilePath
+	}
+	setSessionUploadAttrs(session, ctx)
+	setSessionMetadataDirective(session, ctx)
 
 	var e error
 	if session.Header.RootPath, e = os.Getwd(); e != nil {
@@ -408,7 +1636,17 @@ func mainCopy(ctx *cli.Context) {
 	}
 
 	// extract URLs.
-	session.Header.CommandArgs = ctx.Args()
-	doCopySession(session)
+	session.Header.CommandArgs = args
+	summary := doCopySession(session)
+	// An aborted copy is kept to be resumed, a rolled back transaction starts over instead.
+	if err := summary.abortError(); err != nil {
+		if session.Header.CommandBoolFlags["transaction"] {
+			session.Delete()
+			fatalIf(err.Trace(), "Aborted copy.")
+		}
+		errorIf(err.Trace(), "Aborted copy.")
+		session.CloseAndDie(err)
+	}
 	session.Delete()
+	exitOnFailures(summary)
 }
@@ -0,0 +1,207 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// metadataRule - content type and user metadata of uploads to keys matching pattern.
+type metadataRule struct {
+	pattern     string
+	contentType string // empty keeps the content type.
+	metadata    map[string]string
+}
+
+// metadataRules - rules of ‘cp --metadata-from-file’, most specific first.
+type metadataRules []metadataRule
+
+// patternSpecificity - characters of pattern matched literally, wildcards and character classes count none.
+func patternSpecificity(pattern string) int {
+	literals := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+		case '[':
+			if j := strings.IndexByte(pattern[i:], ']'); j > 0 {
+				i += j
+			}
+		case '\\':
+			i++
+			literals++
+		default:
+			literals++
+		}
+	}
+	return literals
+}
+
+func (r metadataRules) Len() int      { return len(r) }
+func (r metadataRules) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r metadataRules) Less(i, j int) bool {
+	si, sj := patternSpecificity(r[i].pattern), patternSpecificity(r[j].pattern)
+	if si != sj {
+		return si > sj
+	}
+	if len(r[i].pattern) != len(r[j].pattern) {
+		return len(r[i].pattern) > len(r[j].pattern)
+	}
+	return r[i].pattern < r[j].pattern
+}
+
+// isHeaderToken - true if key is a valid HTTP header name.
+func isHeaderToken(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// newMetadataRule - rule of pattern setting fields, ‘Content-Type’ sets the content type and
+// any other key is user metadata, with or without the ‘X-Amz-Meta-’ prefix.
+func newMetadataRule(pattern string, fields map[string]string) (metadataRule, *probe.Error) {
+	if pattern == "" {
+		return metadataRule{}, errInvalidMetadata(pattern, "pattern cannot be empty").Trace()
+	}
+	if _, e := path.Match(pattern, ""); e != nil {
+		return metadataRule{}, errInvalidMetadata(pattern, "invalid pattern").Trace(pattern)
+	}
+	rule := metadataRule{pattern: pattern, metadata: make(map[string]string)}
+	for field, value := range fields {
+		key := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(field)), "x-amz-meta-")
+		if !isHeaderToken(key) {
+			return metadataRule{}, errInvalidMetadata(field, "not a valid header name").Trace(pattern)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return metadataRule{}, errInvalidMetadata(field, "value cannot span lines").Trace(pattern)
+		}
+		switch {
+		case key == metaContentType:
+			rule.contentType = value
+		case key == metaCacheControl:
+			return metadataRule{}, errInvalidMetadata(field, "set it with ‘--cache-control-map’ or ‘--attr’").Trace(pattern)
+		case key == "expires" || strings.HasPrefix(key, "content-"):
+			return metadataRule{}, errInvalidMetadata(field, "header cannot be set on upload, use ‘mc meta set’").Trace(pattern)
+		default:
+			if _, ok := rule.metadata[key]; ok {
+				return metadataRule{}, errInvalidMetadata(field, "duplicate key").Trace(pattern)
+			}
+			rule.metadata[key] = value
+		}
+	}
+	return rule, nil
+}
+
+// parseMetadataRules - parse a JSON object mapping keys or glob patterns of keys to the
+// metadata of the objects uploaded to them, such as
+//
+//	{"*.png": {"Content-Type": "image/png"}, "docs/*": {"owner": "docs"}}
+func parseMetadataRules(data []byte) (metadataRules, *probe.Error) {
+	var patterns map[string]map[string]string
+	if e := json.Unmarshal(data, &patterns); e != nil {
+		return nil, probe.NewError(e)
+	}
+	var rules metadataRules
+	for pattern, fields := range patterns {
+		rule, err := newMetadataRule(pattern, fields)
+		if err != nil {
+			return nil, err.Trace()
+		}
+		rules = append(rules, rule)
+	}
+	sort.Sort(rules)
+	return rules, nil
+}
+
+// loadMetadataFile - rules of the metadata file of ‘--metadata-from-file’.
+func loadMetadataFile(filename string) (metadataRules, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	rules, err := parseMetadataRules(data)
+	if err != nil {
+		return nil, err.Trace(filename)
+	}
+	return rules, nil
+}
+
+// getSessionMetadataRules - rules of ‘--metadata-from-file’ saved in session, loaded once
+// for all objects of the session. Nil without a metadata file.
+func getSessionMetadataRules(session *sessionV6) metadataRules {
+	filename := session.Header.CommandStringFlags["metadata-from-file"]
+	if filename == "" {
+		return nil
+	}
+	rules, err := loadMetadataFile(filename)
+	fatalIf(err.Trace(filename), "Unable to load metadata file ‘"+filename+"’.")
+	return rules
+}
+
+// match - most specific rule whose pattern matches key, nil if none does.
+func (r metadataRules) match(key string) *metadataRule {
+	for i := range r {
+		if matched, _ := path.Match(r[i].pattern, key); matched {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// apply - contentType and metadata with those of the rule taking precedence.
+func (r metadataRule) apply(contentType string, metadata map[string]string) (string, map[string]string) {
+	applied := make(map[string]string)
+	for key, value := range metadata {
+		applied[key] = value
+	}
+	for key, value := range r.metadata {
+		applied[key] = value
+	}
+	if r.contentType != "" {
+		contentType = r.contentType
+	}
+	return contentType, applied
+}
+
+// metadataTargetKey - key of targetURL metadata patterns are matched against, the object key
+// within the bucket on cloud storage and the path for files.
+func metadataTargetKey(targetAlias, targetURL string) string {
+	url := client.NewURL(targetURL)
+	hostCfg, err := getHostConfig(targetAlias)
+	if err != nil || hostCfg == nil || url.Type != client.Object {
+		return strings.TrimPrefix(filepath.ToSlash(url.Path), "/")
+	}
+	objectPath := strings.TrimPrefix(strings.TrimPrefix(url.Path, client.NewURL(hostCfg.URL).Path), "/")
+	if isURLVirtualHostStyle(url.Host) {
+		return objectPath
+	}
+	_, key := url2BucketAndPrefix(objectPath)
+	return key
+}
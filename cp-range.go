@@ -0,0 +1,106 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// parseCopyRange - offset and length of ‘--offset’ and ‘--length’, sizes such as ‘1GiB’, within a file
+// of size. Without ‘--length’ the range ends with the file, an empty range is invalid.
+func parseCopyRange(offsetStr, lengthStr string, size int64) (offset, length int64, err *probe.Error) {
+	if offsetStr != "" {
+		value, e := humanize.ParseBytes(strings.TrimSpace(offsetStr))
+		if e != nil {
+			return 0, 0, probe.NewError(e)
+		}
+		offset = int64(value)
+	}
+	length = size - offset
+	if lengthStr != "" {
+		value, e := humanize.ParseBytes(strings.TrimSpace(lengthStr))
+		if e != nil {
+			return 0, 0, probe.NewError(e)
+		}
+		length = int64(value)
+	}
+	if offset < 0 || length <= 0 || offset+length > size {
+		return 0, 0, errInvalidCopyRange(offset, length, size).Trace(offsetStr, lengthStr)
+	}
+	return offset, length, nil
+}
+
+// isCopyRange - only a range of the source file is copied, with ‘--offset’ or ‘--length’.
+func isCopyRange(session *sessionV6) bool {
+	return session != nil && (session.Header.CommandStringFlags["offset"] != "" || session.Header.CommandStringFlags["length"] != "")
+}
+
+// checkCopyRangeSyntax verifies a range is copied of a single local file, and lies within it.
+func checkCopyRangeSyntax(ctx *cli.Context) {
+	if !ctx.IsSet("offset") && !ctx.IsSet("length") {
+		return
+	}
+	for _, option := range []string{"recursive", "fan-out", "archive", "extract", "append", "skip-identical"} {
+		if ctx.Bool(option) {
+			fatalIf(errInvalidArgument().Trace(), "Options --offset and --length cannot be used with --"+option+".")
+		}
+	}
+	if ctx.String("files-from") != "" || len(ctx.Args()) != 2 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Options --offset and --length copy a range of a single SOURCE file.")
+	}
+	srcURL := ctx.Args().First()
+	if client.NewURL(srcURL).Type != client.Filesystem {
+		fatalIf(errInvalidArgument().Trace(srcURL), "Options --offset and --length copy a range of a local file, ‘"+srcURL+"’ is not one.")
+	}
+	_, srcContent, err := url2Stat(srcURL)
+	fatalIf(err.Trace(srcURL), "Unable to stat source ‘"+srcURL+"’.")
+	if !srcContent.Type.IsRegular() {
+		fatalIf(errInvalidArgument().Trace(srcURL), "Options --offset and --length copy a range of a file, ‘"+srcURL+"’ is not a file.")
+	}
+	_, _, err = parseCopyRange(ctx.String("offset"), ctx.String("length"), srcContent.Size)
+	fatalIf(err.Trace(srcURL), "Invalid range of ‘"+srcURL+"’.")
+}
+
+// withCopyRange - copy URLs of URLsCh, sources of the range of ‘--offset’ and ‘--length’ if set. A
+// source changed since the range was checked fails if the range is no longer within it.
+func withCopyRange(URLsCh <-chan copyURLs, session *sessionV6) <-chan copyURLs {
+	if !isCopyRange(session) {
+		return URLsCh
+	}
+	offsetStr, lengthStr := session.Header.CommandStringFlags["offset"], session.Header.CommandStringFlags["length"]
+	rangeCh := make(chan copyURLs)
+	go func() {
+		defer close(rangeCh)
+		for cpURLs := range URLsCh {
+			if cpURLs.Error == nil {
+				offset, length, err := parseCopyRange(offsetStr, lengthStr, cpURLs.SourceContent.Size)
+				if err != nil {
+					cpURLs.Error = err.Trace(cpURLs.SourceContent.URL.String())
+				} else {
+					cpURLs.Offset, cpURLs.SourceContent.Size = offset, length
+				}
+			}
+			rangeCh <- cpURLs
+		}
+	}()
+	return rangeCh
+}
@@ -0,0 +1,81 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopySymlinks(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-symlinks-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet; globalSymlinks = "" }()
+
+	source := filepath.Join(root, "source")
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "file.txt"), []byte("hello"), 0600), IsNil)
+	c.Assert(os.Symlink("file.txt", filepath.Join(source, "link.txt")), IsNil)
+
+	copySymlinks := func(policy, target string) *transferSummary {
+		session := newTestCopySession(c, []string{source + string(os.PathSeparator)}, target+string(os.PathSeparator), 1, false)
+		session.Header.CommandBoolFlags["recursive"] = true
+		session.Header.CommandStringFlags["symlinks"] = policy
+		summary := doCopySession(session)
+		session.Delete()
+		return summary
+	}
+
+	// Followed symlinks are copied as the files they point to.
+	target := filepath.Join(root, "follow")
+	c.Assert(copySymlinks("follow", target).exitCode(), Equals, 0)
+	data, e := ioutil.ReadFile(filepath.Join(target, "link.txt"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello")
+	st, e := os.Lstat(filepath.Join(target, "link.txt"))
+	c.Assert(e, IsNil)
+	c.Assert(st.Mode()&os.ModeSymlink, Equals, os.FileMode(0))
+
+	target = filepath.Join(root, "skip")
+	c.Assert(copySymlinks("skip", target).exitCode(), Equals, 0)
+	_, e = os.Lstat(filepath.Join(target, "link.txt"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, e = os.Stat(filepath.Join(target, "file.txt"))
+	c.Assert(e, IsNil)
+
+	// Refused symlinks are reported like broken ones, the other files are copied.
+	target = filepath.Join(root, "error")
+	copySymlinks("error", target)
+	_, e = os.Lstat(filepath.Join(target, "link.txt"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, e = os.Stat(filepath.Join(target, "file.txt"))
+	c.Assert(e, IsNil)
+
+	// Stored symlinks carry their target, a filesystem target restores them as symlinks.
+	target = filepath.Join(root, "store")
+	c.Assert(copySymlinks("store", target).exitCode(), Equals, 0)
+	link, e := os.Readlink(filepath.Join(target, "link.txt"))
+	c.Assert(e, IsNil)
+	c.Assert(link, Equals, "file.txt")
+}
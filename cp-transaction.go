@@ -0,0 +1,141 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// copyTransaction - targets created by a ‘--transaction’ copy, removed again once any object fails.
+// Targets the copy replaced are kept, there is nothing to restore them from. Safe for concurrent use.
+type copyTransaction struct {
+	mutex    sync.Mutex
+	created  []copyURLs
+	replaced []string
+	failed   bool
+	abort    context.CancelFunc
+}
+
+// getSessionTransaction - transaction of a session copied with ‘--transaction’, calling abort on
+// the first failure. Nil if not set.
+func getSessionTransaction(session *sessionV6, abort context.CancelFunc) *copyTransaction {
+	if !session.Header.CommandBoolFlags["transaction"] {
+		return nil
+	}
+	return &copyTransaction{abort: abort}
+}
+
+// Copied - cpURLs was copied, its target is removed by a rollback unless it was replaced.
+func (t *copyTransaction) Copied(cpURLs copyURLs) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if cpURLs.Replaced {
+		t.replaced = append(t.replaced, transactionTargetPath(cpURLs))
+		return
+	}
+	t.created = append(t.created, cpURLs)
+}
+
+// Fail - fail the transaction, copies in flight are cancelled and nothing more is copied.
+func (t *copyTransaction) Fail() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.failed {
+		t.failed = true
+		t.abort()
+	}
+}
+
+// Failed - true once the transaction failed, it is rolled back then.
+func (t *copyTransaction) Failed() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.failed
+}
+
+// Rollback - remove every target the transaction created. Targets which cannot be removed are
+// reported as orphaned, along with those it replaced.
+func (t *copyTransaction) Rollback() transactionRollbackMessage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	msg := transactionRollbackMessage{Replaced: t.replaced}
+	for _, cpURLs := range t.created {
+		targetPath := transactionTargetPath(cpURLs)
+		if err := removeTransactionTarget(cpURLs); err != nil {
+			errorIf(err.Trace(cpURLs.TargetContent.URL.String()), "Unable to roll back ‘"+targetPath+"’.")
+			msg.Orphaned = append(msg.Orphaned, targetPath)
+			continue
+		}
+		msg.Removed++
+	}
+	return msg
+}
+
+// removeTransactionTarget - remove the target of cpURLs.
+func removeTransactionTarget(cpURLs copyURLs) *probe.Error {
+	targetClnt, err := newClientFromAlias(cpURLs.TargetAlias, cpURLs.TargetContent.URL.String())
+	if err != nil {
+		return err.Trace(cpURLs.TargetContent.URL.String())
+	}
+	return targetClnt.Remove(false, "")
+}
+
+// transactionTargetPath - target of cpURLs as the user named it.
+func transactionTargetPath(cpURLs copyURLs) string {
+	return filepath.Join(cpURLs.TargetAlias, cpURLs.TargetContent.URL.Path)
+}
+
+// transactionRollbackMessage - outcome of rolling back a failed ‘--transaction’ copy.
+type transactionRollbackMessage struct {
+	Status   string   `json:"status"`
+	Removed  int      `json:"removed"`
+	Orphaned []string `json:"orphaned,omitempty"`
+	Replaced []string `json:"replaced,omitempty"`
+}
+
+// String colorized rollback message.
+func (t transactionRollbackMessage) String() string {
+	message := fmt.Sprintf("Transaction failed, rolled back by removing %d copied object(s).", t.Removed)
+	if len(t.Orphaned) > 0 {
+		message += fmt.Sprintf("\nUnable to remove %d object(s) of the transaction, they are left behind: %s",
+			len(t.Orphaned), strings.Join(t.Orphaned, ", "))
+	}
+	if len(t.Replaced) > 0 {
+		message += fmt.Sprintf("\nKept %d object(s) the transaction replaced, their previous contents cannot be restored: %s",
+			len(t.Replaced), strings.Join(t.Replaced, ", "))
+	}
+	return console.Colorize("Copy", message)
+}
+
+// JSON jsonified rollback message.
+func (t transactionRollbackMessage) JSON() string {
+	t.Status = "success"
+	if len(t.Orphaned) > 0 {
+		t.Status = "error"
+	}
+	msgBytes, e := json.Marshal(t)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopyTransactionRollback(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-transaction-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	var sources []string
+	for i := 0; i < 6; i++ {
+		source := filepath.Join(root, fmt.Sprintf("file%d", i))
+		c.Assert(ioutil.WriteFile(source, []byte(source), 0600), IsNil)
+		sources = append(sources, source)
+	}
+	// file1 is replaced, the 4th upload fails on a folder in its way.
+	c.Assert(os.MkdirAll(filepath.Join(target, "file3", "in-the-way"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(target, "file1"), []byte("old"), 0600), IsNil)
+
+	session := newTestCopySession(c, sources, target, 1, false)
+	session.Header.CommandBoolFlags["transaction"] = true
+	session.Header.CommandBoolFlags["overwrite"] = true
+	summary := doCopySession(session)
+	session.Delete()
+	msg := summary.Message(session)
+	c.Assert(msg.Transferred >= 3, Equals, true)
+	c.Assert(msg.Failed, Equals, 1)
+
+	// The objects copied before the failure, or in flight at the time, are rolled back except the one replaced.
+	for _, name := range []string{"file0", "file2", "file4", "file5"} {
+		_, e = os.Stat(filepath.Join(target, name))
+		c.Assert(os.IsNotExist(e), Equals, true, Commentf(name))
+	}
+	data, e := ioutil.ReadFile(filepath.Join(target, "file1"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, sources[1])
+}
+
+func (s *TestSuite) TestCopyTransactionOrphaned(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-transaction-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	created := filepath.Join(root, "created")
+	c.Assert(ioutil.WriteFile(created, nil, 0600), IsNil)
+
+	// A target which cannot be removed is reported as left behind, the others are removed.
+	transaction := &copyTransaction{abort: func() {}}
+	transaction.Copied(copyURLs{TargetContent: &client.Content{URL: *client.NewURL(created)}})
+	transaction.Copied(copyURLs{TargetContent: &client.Content{URL: *client.NewURL(filepath.Join(created, "below-a-file"))}})
+	transaction.Copied(copyURLs{TargetContent: &client.Content{URL: *client.NewURL(filepath.Join(root, "replaced"))}, Replaced: true})
+	transaction.Fail()
+	c.Assert(transaction.Failed(), Equals, true)
+
+	msg := transaction.Rollback()
+	c.Assert(msg.Removed, Equals, 1)
+	c.Assert(msg.Orphaned, DeepEquals, []string{filepath.Join(created, "below-a-file")})
+	c.Assert(msg.Replaced, DeepEquals, []string{filepath.Join(root, "replaced")})
+	_, e = os.Stat(created)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
@@ -18,19 +18,66 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-xl/pkg/probe"
 )
 
+// Storage classes of Amazon S3, others may be supported by other servers.
+var knownStorageClasses = map[string]bool{
+	"STANDARD":           true,
+	"REDUCED_REDUNDANCY": true,
+	"STANDARD_IA":        true,
+	"GLACIER":            true,
+}
+
+// Storage classes are upper case words joined by underscores.
+var validStorageClass = regexp.MustCompile("^[A-Z0-9]+(_[A-Z0-9]+)*$")
+
+// checkStorageClass - fails for malformed storage classes, isKnown is false for
+// well formed storage classes not known to Amazon S3.
+func checkStorageClass(storageClass string) (isKnown bool, err *probe.Error) {
+	if !validStorageClass.MatchString(storageClass) {
+		return false, errInvalidStorageClass(storageClass).Trace(storageClass)
+	}
+	return knownStorageClasses[storageClass], nil
+}
+
+// validRegion - region names are lower case words and numbers joined by dashes, such as "eu-west-1".
+var validRegion = regexp.MustCompile("^[a-z][a-z0-9]*(-[a-z0-9]+)*$")
+
+// checkRegion - fails for region names no host would sign requests for.
+func checkRegion(region string) *probe.Error {
+	if len(region) > 64 || !validRegion.MatchString(region) {
+		return errInvalidRegion(region).Trace(region)
+	}
+	return nil
+}
+
+// checkChecksumAlgorithm - algorithm of ‘--checksum’ in upper case, fails for unknown algorithms.
+func checkChecksumAlgorithm(algorithm string) (string, *probe.Error) {
+	algorithm = strings.ToUpper(algorithm)
+	if !minio.IsValidChecksumAlgorithm(algorithm) {
+		return "", errInvalidChecksumAlgorithm(algorithm).Trace(algorithm)
+	}
+	return algorithm, nil
+}
+
 func checkCopySyntax(ctx *cli.Context) {
-	if len(ctx.Args()) < 2 {
+	// Sources are read from a file with ‘--files-from’, only the target is an argument.
+	isFilesFrom := ctx.String("files-from") != ""
+	if !ctx.Args().Present() || (len(ctx.Args()) < 2 && !isFilesFrom) {
 		cli.ShowCommandHelpAndExit(ctx, "cp", 1) // last argument is exit code.
 	}
 
 	// extract URLs.
 	URLs := ctx.Args()
-	if len(URLs) < 2 {
+	if len(URLs) < 2 && !isFilesFrom {
 		fatalIf(errDummy().Trace(ctx.Args()...), fmt.Sprintf("Unable to parse source and target arguments."))
 	}
 
@@ -38,6 +85,123 @@ func checkCopySyntax(ctx *cli.Context) {
 	tgtURL := URLs[len(URLs)-1]
 	isRecursive := ctx.Bool("recursive")
 
+	if notifyURL := ctx.String("notify"); notifyURL != "" {
+		fatalIf(checkNotifyURL(notifyURL).Trace(), "Unable to use notify URL.")
+	}
+	if ctx.Int("concurrent") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --concurrent cannot be negative.")
+	}
+	if ctx.Bool("if-not-present") && ctx.Bool("overwrite") {
+		fatalIf(errInvalidArgument().Trace(), "Options --if-not-present and --overwrite are mutually exclusive.")
+	}
+	if ctx.Bool("if-changed") && ctx.Bool("if-not-present") {
+		fatalIf(errInvalidArgument().Trace(), "Options --if-changed and --if-not-present are mutually exclusive.")
+	}
+	if ctx.Bool("append") && (ctx.Bool("if-changed") || ctx.Bool("if-not-present")) {
+		fatalIf(errInvalidArgument().Trace(), "Option --append cannot be used with --if-changed or --if-not-present.")
+	}
+	if ctx.Bool("force-append") && !ctx.Bool("append") {
+		fatalIf(errInvalidArgument().Trace(), "Option --force-append requires --append.")
+	}
+	if ctx.Int("skip-errors") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --skip-errors cannot be negative.")
+	}
+	if ctx.IsSet("skip-errors") && ctx.Bool("continue-on-error") {
+		fatalIf(errInvalidArgument().Trace(), "Options --skip-errors and --continue-on-error are mutually exclusive.")
+	}
+	if ctx.Bool("transaction") && (ctx.IsSet("skip-errors") || ctx.Bool("continue-on-error")) {
+		fatalIf(errInvalidArgument().Trace(), "Option --transaction rolls back on any failure, it cannot be used with --skip-errors or --continue-on-error.")
+	}
+	if ctx.Bool("transaction") && (ctx.Bool("fan-out") || ctx.Bool("archive") || ctx.Bool("extract")) {
+		fatalIf(errInvalidArgument().Trace(), "Option --transaction cannot be used with --fan-out, --archive or --extract.")
+	}
+	if ctx.Bool("preserve-etag") && !ctx.Bool("atomic") && !ctx.Bool("fan-out") {
+		fatalIf(errInvalidArgument().Trace(), "Option --preserve-etag verifies server side copies, it requires --atomic or --fan-out.")
+	}
+	if ctx.Bool("sniff") && ctx.Bool("disable-content-type-guess") {
+		fatalIf(errInvalidArgument().Trace(), "Options --sniff and --disable-content-type-guess are mutually exclusive.")
+	}
+	if ctx.Int("workers") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --workers cannot be negative.")
+	}
+	if onCollision := ctx.String("on-collision"); onCollision != onCollisionFail && onCollision != onCollisionSuffix {
+		fatalIf(errInvalidArgument().Trace(onCollision), "Option --on-collision is either ‘fail’ or ‘suffix’.")
+	}
+	if symlinks := ctx.String("symlinks"); !fs.IsValidSymlinkPolicy(symlinks) {
+		fatalIf(errInvalidArgument().Trace(symlinks), "Option --symlinks is one of ‘follow’, ‘skip’, ‘error’ or ‘store’.")
+	}
+	// Objects are flattened into a folder, never onto a file.
+	if ctx.Bool("flatten") && !isTargetURLDir(tgtURL) {
+		fatalIf(errInvalidArgument().Trace(tgtURL), "Option --flatten copies into a folder, target ‘"+tgtURL+"’ is not a folder.")
+	}
+	if minSpeed := ctx.String("min-speed"); minSpeed != "" {
+		_, err := parseMinSpeed(minSpeed)
+		fatalIf(err.Trace(minSpeed), "Invalid minimum speed ‘"+minSpeed+"’.")
+	}
+	if metadataFile := ctx.String("metadata-from-file"); metadataFile != "" {
+		_, err := loadMetadataFile(metadataFile)
+		fatalIf(err.Trace(metadataFile), "Invalid metadata file ‘"+metadataFile+"’.")
+	}
+	checkUploadAttrsSyntax(ctx.String("cache-control-map"), ctx.String("attr"))
+	checkCopyDirectivesSyntax(ctx)
+	checkCopyRangeSyntax(ctx)
+	checkTransferOrder(ctx.String("order"))
+	checkRemoveSourceSyntax(ctx)
+	checkMinFreeSyntax(ctx)
+	checkACLSyntax(ctx)
+	checkMetadataCharsetSyntax(ctx)
+	checkPreserveHardlinksSyntax(ctx)
+	if scheduleStr := ctx.String("bw-schedule"); scheduleStr != "" {
+		schedule, err := parseBandwidthSchedule(scheduleStr)
+		fatalIf(err.Trace(scheduleStr), "Invalid bandwidth schedule ‘"+scheduleStr+"’.")
+		// Transfers capped below the minimum speed would be aborted as stalled.
+		minSpeed, _ := parseMinSpeed(ctx.String("min-speed"))
+		if slowest := schedule.slowest(); slowest > 0 && slowest < minSpeed {
+			fatalIf(errInvalidArgument().Trace(scheduleStr), "Bandwidth schedule ‘"+scheduleStr+"’ caps transfers below --min-speed.")
+		}
+	}
+	if ctx.Duration("stall-grace") <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --stall-grace must be positive.")
+	}
+	if storageClass := ctx.String("storage-class"); storageClass != "" {
+		isKnown, err := checkStorageClass(storageClass)
+		fatalIf(err.Trace(storageClass), "Unable to use storage class.")
+		if !isKnown {
+			errorIf(errUnknownStorageClass(storageClass).Trace(), "Passing storage class through unchecked.")
+		}
+	}
+	if checksum := ctx.String("checksum"); checksum != "" {
+		_, err := checkChecksumAlgorithm(checksum)
+		fatalIf(err.Trace(checksum), "Unable to use checksum.")
+	}
+	if region := ctx.String("region"); region != "" {
+		fatalIf(checkRegion(region).Trace(region), "Unable to use region.")
+	}
+
+	if ctx.IsSet("flush-interval") && !ctx.Bool("follow") {
+		fatalIf(errInvalidArgument().Trace(), "Option --flush-interval can only be used with --follow.")
+	}
+	if ctx.Bool("follow") {
+		checkCopySyntaxFollow(ctx)
+		return
+	}
+	if ctx.Bool("archive") || ctx.Bool("extract") {
+		checkCopySyntaxArchive(ctx)
+		return
+	}
+	if isFilesFrom {
+		checkCopySyntaxFilesFrom(ctx)
+		return
+	}
+	if ctx.String("base") != "" {
+		fatalIf(errInvalidArgument().Trace(), "Option --base can only be used with --files-from.")
+	}
+
+	if ctx.Bool("fan-out") {
+		checkCopySyntaxFanOut(ctx)
+		return
+	}
+
 	/****** Generic Invalid Rules *******/
 	// Check if bucket name is passed for URL type arguments.
 	url := client.NewURL(tgtURL)
@@ -69,6 +233,93 @@ func checkCopySyntax(ctx *cli.Context) {
 	}
 }
 
+// checkCopySyntaxFilesFrom verifies the files listed by ‘--files-from’ are copied into a single target folder.
+func checkCopySyntaxFilesFrom(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Option --files-from copies the files listed into TARGET, no SOURCE can be given.")
+	}
+	for _, option := range []string{"recursive", "flatten", "fan-out"} {
+		if ctx.Bool(option) {
+			fatalIf(errInvalidArgument().Trace(), "Option --files-from cannot be used with --"+option+".")
+		}
+	}
+	tgtURL := ctx.Args().First()
+	if !isTargetURLDir(tgtURL) {
+		fatalIf(errInvalidArgument().Trace(tgtURL), "Option --files-from copies into a folder, target ‘"+tgtURL+"’ is not a folder.")
+	}
+}
+
+// checkCopySyntaxArchive verifies a folder is archived to ‘-’, or ‘-’ extracted into a folder.
+func checkCopySyntaxArchive(ctx *cli.Context) {
+	if ctx.Bool("archive") && ctx.Bool("extract") {
+		fatalIf(errInvalidArgument().Trace(), "Options --archive and --extract are mutually exclusive.")
+	}
+	if len(ctx.Args()) != 2 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Options --archive and --extract take a single SOURCE and TARGET.")
+	}
+	for _, option := range []string{"flatten", "fan-out"} {
+		if ctx.Bool(option) {
+			fatalIf(errInvalidArgument().Trace(), "Options --archive and --extract cannot be used with --"+option+".")
+		}
+	}
+	if ctx.String("files-from") != "" {
+		fatalIf(errInvalidArgument().Trace(), "Options --archive and --extract cannot be used with --files-from.")
+	}
+	srcURL, tgtURL := ctx.Args().Get(0), ctx.Args().Get(1)
+	if ctx.Bool("extract") {
+		if srcURL != "-" {
+			fatalIf(errInvalidArgument().Trace(srcURL), "Option --extract reads the archive from standard input, SOURCE must be ‘-’.")
+		}
+		if !isTargetURLDir(tgtURL) {
+			fatalIf(errInvalidArgument().Trace(tgtURL), "Option --extract extracts into a folder, target ‘"+tgtURL+"’ is not a folder.")
+		}
+		return
+	}
+	if tgtURL != "-" {
+		fatalIf(errInvalidArgument().Trace(tgtURL), "Option --archive writes the archive to standard output, TARGET must be ‘-’.")
+	}
+	_, srcContent, err := url2Stat(srcURL)
+	fatalIf(err.Trace(srcURL), "Unable to stat source ‘"+srcURL+"’.")
+	if !srcContent.Type.IsDir() {
+		fatalIf(errInvalidArgument().Trace(srcURL), "Option --archive archives a folder, source ‘"+srcURL+"’ is not a folder.")
+	}
+}
+
+// checkCopySyntaxFanOut verifies the source is a file, copied to targets given after it.
+func checkCopySyntaxFanOut(ctx *cli.Context) {
+	if ctx.Bool("recursive") || ctx.Bool("flatten") {
+		fatalIf(errInvalidArgument().Trace(), "Option --fan-out copies a single file, it cannot be used with --recursive or --flatten.")
+	}
+	if ctx.Bool("if-changed") || ctx.Bool("append") || ctx.Bool("skip-identical") || ctx.IsSet("skip-errors") {
+		fatalIf(errInvalidArgument().Trace(), "Option --fan-out cannot be used with --if-changed, --append, --skip-identical or --skip-errors.")
+	}
+	for _, option := range []string{"summary-file", "notify", "min-speed", "bw-schedule", "metadata-from-file"} {
+		if ctx.String(option) != "" {
+			fatalIf(errInvalidArgument().Trace(), "Option --fan-out cannot be used with --"+option+".")
+		}
+	}
+	// Targets of fan-out are uploaded without metadata, only server side copies may replace theirs.
+	if metadataDirective, _ := parseCopyDirective(ctx.String("metadata-directive")); metadataDirective != client.DirectiveReplace {
+		for _, option := range []string{"cache-control-map", "attr"} {
+			if ctx.String(option) != "" {
+				fatalIf(errInvalidArgument().Trace(), "Option --fan-out uses --"+option+" only with --metadata-directive REPLACE.")
+			}
+		}
+	}
+	srcURL := ctx.Args().First()
+	_, srcContent, err := url2Stat(srcURL)
+	fatalIf(err.Trace(srcURL), "Unable to stat source ‘"+srcURL+"’.")
+	if !srcContent.Type.IsRegular() {
+		fatalIf(errInvalidArgument().Trace(), "Source ‘"+srcURL+"’ is not a file.")
+	}
+	for _, tgtURL := range ctx.Args().Tail() {
+		url := client.NewURL(tgtURL)
+		if url.Host != "" && !isURLVirtualHostStyle(url.Host) && url.Path == string(url.Separator) {
+			fatalIf(errInvalidArgument().Trace(), fmt.Sprintf("Target ‘%s’ does not contain bucket name.", tgtURL))
+		}
+	}
+}
+
 // checkCopySyntaxTypeA verifies if the source and target are valid file arguments.
 func checkCopySyntaxTypeA(srcURLs []string, tgtURL string) {
 	// Check source.
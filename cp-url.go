@@ -17,8 +17,11 @@
 package main
 
 import (
-	"path/filepath"
+	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/minio-xl/pkg/probe"
@@ -29,7 +32,13 @@ type copyURLs struct {
 	SourceContent *client.Content
 	TargetAlias   string
 	TargetContent *client.Content
-	Error         *probe.Error `json:"-"`
+	Error         *probe.Error  `json:"-"`
+	Duration      time.Duration `json:"-"` // time spent copying, not saved in the session.
+	Skipped       bool          `json:"-"` // target was present and kept.
+	Replaced      bool          `json:"-"` // target was present and copied over, only known with ‘--transaction’.
+	Position      int           `json:"-"` // line of the session data file.
+	Decompress    bool          `json:"-"` // source is gzip encoded, the target gets it decompressed.
+	Offset        int64         // offset of the range of the source copied, ‘--offset’.
 }
 
 type copyURLsType uint8
@@ -154,9 +163,8 @@ func prepareCopyURLsTypeB(sourceURL string, targetURL string) copyURLs {
 // makeCopyContentTypeB - CopyURLs content for copying.
 func makeCopyContentTypeB(sourceAlias string, sourceContent *client.Content, targetAlias string, targetURL string) copyURLs {
 	// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
-	targetURLParse := client.NewURL(targetURL)
-	targetURLParse.Path = filepath.Join(targetURLParse.Path, filepath.Base(sourceContent.URL.Path))
-	return makeCopyContentTypeA(sourceAlias, sourceContent, targetAlias, targetURLParse.String())
+	newTargetURL := urlJoinSourcePath(targetURL, sourceContent.URL, downloadName(sourceContent))
+	return makeCopyContentTypeA(sourceAlias, sourceContent, targetAlias, newTargetURL)
 }
 
 // SINGLE SOURCE - Type C: copy(d1..., d2) -> []copy(d1/f, d1/d2/f) -> []A
@@ -177,7 +185,9 @@ func prepareCopyURLsTypeC(sourceURL, targetURL string, isRecursive bool) <-chan
 			return
 		}
 
-		for sourceContent := range sourceClient.List(isRecursive, false) {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		for sourceContent := range sourceClient.List(isRecursive, false, doneCh) {
 			if sourceContent.Err != nil {
 				// Listing failed.
 				copyURLsCh <- copyURLs{Error: sourceContent.Err.Trace(sourceClient.GetURL().String())}
@@ -204,27 +214,94 @@ func makeCopyContentTypeC(sourceAlias string, sourceURL client.URL, sourceConten
 	if pathSeparatorIndex > 1 {
 		newSourceSuffix = strings.TrimPrefix(newSourceURL.Path, sourceURL.Path[:pathSeparatorIndex])
 	}
-	newTargetURL := urlJoinPath(targetURL, newSourceSuffix)
+	newTargetURL := urlJoinSourcePath(targetURL, newSourceURL, newSourceSuffix)
 	return makeCopyContentTypeA(sourceAlias, sourceContent, targetAlias, newTargetURL)
 }
 
 // MULTI-SOURCE - Type D: copy([](f|d...), d) -> []B
-// prepareCopyURLsTypeE - prepares target and source URLs for copying.
-func prepareCopyURLsTypeD(sourceURLs []string, targetURL string, isRecursive bool) <-chan copyURLs {
+// prepareCopyURLsTypeD - prepares target and source URLs for copying, up to workers sources are scanned in parallel.
+func prepareCopyURLsTypeD(sourceURLs []string, targetURL string, isRecursive bool, workers int) <-chan copyURLs {
 	copyURLsCh := make(chan copyURLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan copyURLs) {
 		defer close(copyURLsCh)
+		sourceQueue := make(chan bool, workers)
+		wg := new(sync.WaitGroup)
 		for _, sourceURL := range sourceURLs {
-			for cpURLs := range prepareCopyURLsTypeC(sourceURL, targetURL, isRecursive) {
-				copyURLsCh <- cpURLs
-			}
+			sourceQueue <- true
+			wg.Add(1)
+			go func(sourceURL string) {
+				defer wg.Done()
+				defer func() {
+					<-sourceQueue
+				}()
+				for cpURLs := range prepareCopyURLsTypeC(sourceURL, targetURL, isRecursive) {
+					copyURLsCh <- cpURLs
+				}
+			}(sourceURL)
 		}
+		wg.Wait()
 	}(sourceURLs, targetURL, copyURLsCh)
 	return copyURLsCh
 }
 
-// prepareCopyURLs - prepares target and source URLs for copying.
-func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool) <-chan copyURLs {
+// Handling of objects with the same name copied into one folder by ‘cp --flatten’.
+const (
+	onCollisionFail   = "fail"   // object is not copied.
+	onCollisionSuffix = "suffix" // object is copied with a numbered suffix, such as ‘photo-1.jpg’.
+)
+
+// flattenCopyURLs - copy URLs of URLsCh with every target directly in the folder targetURL, named
+// after its source. Names are taken in the order of URLsCh, objects with a name already taken fail
+// or get a suffix depending on onCollision.
+func flattenCopyURLs(URLsCh <-chan copyURLs, targetURL, onCollision string) <-chan copyURLs {
+	_, targetURL, _ = mustExpandAlias(targetURL)
+	flatURLsCh := make(chan copyURLs)
+	go func() {
+		defer close(flatURLsCh)
+		taken := make(map[string]bool)
+		for cpURLs := range URLsCh {
+			if cpURLs.Error != nil {
+				flatURLsCh <- cpURLs
+				continue
+			}
+			sourceURL := cpURLs.SourceContent.URL
+			name := downloadName(cpURLs.SourceContent)
+			if taken[name] {
+				if onCollision != onCollisionSuffix {
+					target := urlJoinSourcePath(targetURL, sourceURL, name)
+					flatURLsCh <- copyURLs{Error: errFlattenCollision(sourceURL.String(), target).Trace(sourceURL.String())}
+					continue
+				}
+				name = suffixedName(name, taken)
+			}
+			taken[name] = true
+			cpURLs.TargetContent = &client.Content{URL: *client.NewURL(urlJoinSourcePath(targetURL, sourceURL, name))}
+			flatURLsCh <- cpURLs
+		}
+	}()
+	return flatURLsCh
+}
+
+// suffixedName - name with the lowest numbered suffix not taken, inserted before the extension.
+func suffixedName(name string, taken map[string]bool) string {
+	ext := path.Ext(name)
+	if ext == name {
+		// Dot files have no extension.
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		suffixed := base + "-" + strconv.Itoa(i) + ext
+		if !taken[suffixed] {
+			return suffixed
+		}
+	}
+}
+
+// prepareCopyURLs - prepares target and source URLs for copying. Whether the target is a
+// folder is decided once for all sources. With isFlatten all objects are copied directly into
+// the target folder, name collisions are handled as onCollision says.
+func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool, workers int, isFlatten bool, onCollision string) <-chan copyURLs {
 	copyURLsCh := make(chan copyURLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan copyURLs) {
 		defer close(copyURLsCh)
@@ -236,11 +313,23 @@ func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool) <-
 		case copyURLsTypeB:
 			copyURLsCh <- prepareCopyURLsTypeB(sourceURLs[0], targetURL)
 		case copyURLsTypeC:
-			for cURLs := range prepareCopyURLsTypeC(sourceURLs[0], targetURL, isRecursive) {
+			cURLsCh := prepareCopyURLsTypeC(sourceURLs[0], targetURL, isRecursive)
+			if isFlatten {
+				cURLsCh = flattenCopyURLs(cURLsCh, targetURL, onCollision)
+			}
+			for cURLs := range cURLsCh {
 				copyURLsCh <- cURLs
 			}
 		case copyURLsTypeD:
-			for cURLs := range prepareCopyURLsTypeD(sourceURLs, targetURL, isRecursive) {
+			if isFlatten {
+				// Sources are scanned one by one, names are taken in the same order every time.
+				workers = 1
+			}
+			cURLsCh := prepareCopyURLsTypeD(sourceURLs, targetURL, isRecursive, workers)
+			if isFlatten {
+				cURLsCh = flattenCopyURLs(cURLsCh, targetURL, onCollision)
+			}
+			for cURLs := range cURLsCh {
 				copyURLsCh <- cURLs
 			}
 		default:
@@ -0,0 +1,88 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/mock"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopyFromWeb(c *C) {
+	defer useTempMcConfig(c)()
+	data := bytes.Repeat([]byte("iso image "), 700*1024)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/release.iso", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-iso9660-image")
+		http.ServeContent(w, r, "", time.Date(2015, 10, 14, 0, 0, 0, 0, time.UTC), bytes.NewReader(data))
+	})
+	// Streamed without a Content-Length.
+	mux.HandleFunc("/live.log", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data[:100])
+		w.(http.Flusher).Flush()
+		w.Write(data[100:])
+	})
+	mux.Handle("/latest.iso", http.RedirectHandler("/release.iso", http.StatusFound))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := mock.NewStore(map[string][]byte{"images": nil})
+	for _, name := range []string{"release.iso", "live.log", "latest.iso"} {
+		sourceClnt, err := newClientFromAlias("", server.URL+"/"+name)
+		c.Assert(err, IsNil)
+		source, err := sourceClnt.Stat()
+		c.Assert(err, IsNil)
+		targetClnt, err := store.New("https://mock.example/images/" + name)
+		c.Assert(err, IsNil)
+		cpURLs := copyURLs{SourceContent: source, TargetContent: &client.Content{URL: targetClnt.GetURL()}}
+		_, _, err = copyObject(cpURLs, newTestSession(), sourceClnt, targetClnt, source.ContentType, nil, nil, nil, nil)
+		c.Assert(err, IsNil)
+		copied, ok := store.Object("images/" + name)
+		c.Assert(ok, Equals, true)
+		c.Assert(bytes.Equal(copied, data), Equals, true, Commentf("%s", name))
+	}
+	targetClnt, err := store.New("https://mock.example/images/release.iso")
+	c.Assert(err, IsNil)
+	content, err := targetClnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.ContentType, Equals, "application/x-iso9660-image")
+
+	// A session names the target after the source, missing sources are left out.
+	root, e := ioutil.TempDir(os.TempDir(), "cp-web-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+	session := newTestCopySession(c, []string{server.URL + "/release.iso", server.URL + "/missing.iso"}, root+string(filepath.Separator), 1, true)
+	summary := doCopySession(session)
+	session.Delete()
+	c.Assert(summary.exitCode(), Equals, 0)
+	copied, e := ioutil.ReadFile(filepath.Join(root, "release.iso"))
+	c.Assert(e, IsNil)
+	c.Assert(bytes.Equal(copied, data), Equals, true)
+	_, e = os.Stat(filepath.Join(root, "missing.iso"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
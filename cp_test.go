@@ -0,0 +1,525 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestCopySession - cp session of sourceURLs to targetURL as mainCopy creates it.
+func newTestCopySession(c *C, sourceURLs []string, targetURL string, workers int, continueOnError bool) *sessionV6 {
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	session.Header.CommandType = "cp"
+	session.Header.CommandBoolFlags["continue-on-error"] = continueOnError
+	session.Header.CommandIntFlags["workers"] = workers
+	session.Header.CommandArgs = append(append([]string{}, sourceURLs...), targetURL)
+	return session
+}
+
+func (s *TestSuite) TestCopyMultipleSources(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-multi-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	var sourceURLs []string
+	var totalBytes int64
+	for i := 0; i < 8; i++ {
+		source := filepath.Join(root, fmt.Sprintf("dir%d", i), fmt.Sprintf("file%d", i))
+		data := []byte(fmt.Sprintf("contents of file %d", i))
+		c.Assert(os.MkdirAll(filepath.Dir(source), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(source, data, 0600), IsNil)
+		sourceURLs = append(sourceURLs, source)
+		totalBytes += int64(len(data))
+	}
+
+	// Every source is prepared exactly once, even when scanned in parallel.
+	var prepared []string
+	for cpURLs := range prepareCopyURLs(sourceURLs, target, false, 3, false, "") {
+		c.Assert(cpURLs.Error, IsNil)
+		prepared = append(prepared, cpURLs.SourceContent.URL.Path)
+	}
+	sort.Strings(prepared)
+	c.Assert(prepared, DeepEquals, sourceURLs)
+
+	session := newTestCopySession(c, sourceURLs, target, 3, false)
+	defer session.Delete()
+	summary := doCopySession(session)
+	msg := summary.Message(session)
+	c.Assert(msg.TotalObjects, Equals, 8)
+	c.Assert(msg.Transferred, Equals, 8)
+	c.Assert(msg.TransferredBytes, Equals, totalBytes)
+	c.Assert(msg.Failed, Equals, 0)
+	c.Assert(summary.exitCode(), Equals, 0)
+	for i, source := range sourceURLs {
+		data, e := ioutil.ReadFile(filepath.Join(target, fmt.Sprintf("file%d", i)))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, fmt.Sprintf("contents of file %d", i))
+		_, e = os.Stat(source)
+		c.Assert(e, IsNil)
+	}
+}
+
+func (s *TestSuite) TestCopyContinueOnError(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-continue-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	var sourceURLs []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		source := filepath.Join(root, name)
+		c.Assert(ioutil.WriteFile(source, []byte(name), 0600), IsNil)
+		sourceURLs = append(sourceURLs, source)
+	}
+	// Copying "b" fails, a folder is in the way on the target.
+	c.Assert(os.MkdirAll(filepath.Join(target, "b"), 0700), IsNil)
+
+	session := newTestCopySession(c, sourceURLs, target, 2, true)
+	defer session.Delete()
+	summary := doCopySession(session)
+	msg := summary.Message(session)
+	c.Assert(msg.Transferred, Equals, 3)
+	c.Assert(msg.Failed, Equals, 1)
+	c.Assert(len(msg.Errors), Equals, 1)
+	c.Assert(msg.Errors[0].URL, Equals, sourceURLs[1])
+	c.Assert(summary.exitCode(), Equals, exitPartialFailure)
+	for _, name := range []string{"a", "c", "d"} {
+		data, e := ioutil.ReadFile(filepath.Join(target, name))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, name)
+	}
+}
+
+func (s *TestSuite) TestCopySummaryFile(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-summary-file-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	var sourceURLs []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		source := filepath.Join(root, name)
+		c.Assert(ioutil.WriteFile(source, []byte(name), 0600), IsNil)
+		sourceURLs = append(sourceURLs, source)
+	}
+	// Copying "b" fails, a folder is in the way on the target.
+	c.Assert(os.MkdirAll(filepath.Join(target, "b"), 0700), IsNil)
+
+	// Records of an earlier run of the session are kept.
+	summaryFile := filepath.Join(root, "summary.jsonl")
+	c.Assert(ioutil.WriteFile(summaryFile, []byte(`{"url":"earlier","status":"transferred"}`+"\n"), 0600), IsNil)
+
+	// One worker prepares the objects in order, "a" was copied before the session was resumed.
+	session := newTestCopySession(c, sourceURLs, target, 1, true)
+	defer session.Delete()
+	session.Header.CommandStringFlags["summary-file"] = summaryFile
+	doPrepareCopyURLs(session, make(chan bool))
+	session.Header.LastCopied = sourceURLs[0]
+	doCopySession(session)
+
+	file, e := os.Open(summaryFile)
+	c.Assert(e, IsNil)
+	defer file.Close()
+	statuses := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record transferRecord
+		c.Assert(json.Unmarshal(scanner.Bytes(), &record), IsNil, Commentf("%s", scanner.Text()))
+		_, ok := statuses[record.URL]
+		c.Assert(ok, Equals, false, Commentf("%s recorded twice", record.URL))
+		statuses[record.URL] = record.Status
+		if record.URL == "earlier" {
+			continue
+		}
+		c.Assert(record.Size, Equals, int64(1))
+		c.Assert(record.Target, Equals, filepath.Join(target, filepath.Base(record.URL)))
+		c.Assert(record.Time.IsZero(), Equals, false)
+		c.Assert(record.Error != "", Equals, record.Status == recordFailed)
+	}
+	c.Assert(statuses, DeepEquals, map[string]string{
+		"earlier":     recordTransferred,
+		sourceURLs[0]: recordSkipped,
+		sourceURLs[1]: recordFailed,
+		sourceURLs[2]: recordTransferred,
+		sourceURLs[3]: recordTransferred,
+	})
+}
+
+func (s *TestSuite) TestCopyIfNotPresent(c *C) {
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	for _, mode := range []string{"", "if-not-present", "overwrite"} {
+		root, e := ioutil.TempDir(os.TempDir(), "cp-if-not-present-")
+		c.Assert(e, IsNil)
+		defer os.RemoveAll(root)
+
+		target := filepath.Join(root, "target") + string(os.PathSeparator)
+		var sourceURLs []string
+		for _, name := range []string{"a", "b", "c"} {
+			source := filepath.Join(root, name)
+			c.Assert(ioutil.WriteFile(source, []byte(name), 0600), IsNil)
+			sourceURLs = append(sourceURLs, source)
+		}
+		c.Assert(os.MkdirAll(target, 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(target, "b"), []byte("existing"), 0600), IsNil)
+
+		// Tests run without a terminal, present targets are kept unless overwrite is set.
+		session := newTestCopySession(c, sourceURLs, target, 1, false)
+		defer session.Delete()
+		if mode != "" {
+			session.Header.CommandBoolFlags[mode] = true
+		}
+		summary := doCopySession(session)
+		msg := summary.Message(session)
+		expected := "existing"
+		if mode == "overwrite" {
+			expected = "b"
+			c.Assert(msg.Transferred, Equals, 3, Commentf("mode %q", mode))
+			c.Assert(msg.Skipped, Equals, 0, Commentf("mode %q", mode))
+		} else {
+			c.Assert(msg.Transferred, Equals, 2, Commentf("mode %q", mode))
+			c.Assert(msg.Skipped, Equals, 1, Commentf("mode %q", mode))
+		}
+		c.Assert(msg.Failed, Equals, 0)
+		data, e := ioutil.ReadFile(filepath.Join(target, "b"))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, expected, Commentf("mode %q", mode))
+	}
+}
+
+func (s *TestSuite) TestCopyIfNotPresentResume(c *C) {
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	for _, mode := range []string{"", "if-not-present"} {
+		root, e := ioutil.TempDir(os.TempDir(), "cp-if-not-present-resume-")
+		c.Assert(e, IsNil)
+		defer os.RemoveAll(root)
+
+		target := filepath.Join(root, "target") + string(os.PathSeparator)
+		var sourceURLs []string
+		for _, name := range []string{"a", "b", "c"} {
+			source := filepath.Join(root, name)
+			c.Assert(ioutil.WriteFile(source, []byte(name), 0600), IsNil)
+			sourceURLs = append(sourceURLs, source)
+		}
+		c.Assert(os.MkdirAll(target, 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(target, "b"), []byte("existing"), 0600), IsNil)
+
+		// The present target is skipped and marked completed like the copied ones.
+		session := newTestCopySession(c, sourceURLs, target, 1, true)
+		defer session.Delete()
+		if mode != "" {
+			session.Header.CommandBoolFlags[mode] = true
+		}
+		summary := doCopySession(session)
+		c.Assert(summary.Message(session).Skipped, Equals, 1, Commentf("mode %q", mode))
+		c.Assert(session.Header.Copied, DeepEquals, copiedPositions{Next: 3}, Commentf("mode %q", mode))
+		c.Assert(session.Close(), IsNil)
+
+		// A folder now in the way of ‘b’ would fail a copy, were the target stat'ed again. Sessions
+		// without positions resume after the last copied, which was the skipped one.
+		c.Assert(os.Remove(filepath.Join(target, "b")), IsNil)
+		c.Assert(os.Mkdir(filepath.Join(target, "b"), 0700), IsNil)
+		c.Assert(os.Remove(filepath.Join(target, "c")), IsNil)
+		resumed, err := loadSessionV6(session.SessionID)
+		c.Assert(err, IsNil)
+		resumed.Header.Copied = copiedPositions{}
+		resumed.Header.LastCopied = sourceURLs[1]
+		summary = doCopySession(resumed)
+		msg := summary.Message(resumed)
+		c.Assert(msg.Failed, Equals, 0, Commentf("mode %q", mode))
+		c.Assert(msg.Transferred, Equals, 1, Commentf("mode %q", mode))
+		data, e := ioutil.ReadFile(filepath.Join(target, "c"))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, "c")
+		c.Assert(resumed.Close(), IsNil)
+	}
+}
+
+func (s *TestSuite) TestIsTargetPresent(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-target-present-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file"), []byte("file"), 0600), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(root, "dir"), 0700), IsNil)
+
+	for name, present := range map[string]bool{"missing": false, "file": true, "dir": false} {
+		targetClnt, err := newClient(filepath.Join(root, name))
+		c.Assert(err, IsNil)
+		isPresent, err := isTargetPresent(targetClnt)
+		c.Assert(err, IsNil)
+		c.Assert(isPresent, Equals, present, Commentf("%s", name))
+	}
+}
+
+// flattenTestTargets - targets of a recursive copy of source to target relative to target, by
+// source relative to source. Errors are counted.
+func flattenTestTargets(c *C, source, target string, isFlatten bool, onCollision string) (map[string]string, int) {
+	targets := make(map[string]string)
+	errs := 0
+	for cpURLs := range prepareCopyURLs([]string{source}, target, true, 1, isFlatten, onCollision) {
+		if cpURLs.Error != nil {
+			errs++
+			continue
+		}
+		sourcePath, e := filepath.Rel(source, cpURLs.SourceContent.URL.Path)
+		c.Assert(e, IsNil)
+		targetPath, e := filepath.Rel(target, cpURLs.TargetContent.URL.Path)
+		c.Assert(e, IsNil)
+		targets[filepath.ToSlash(sourcePath)] = filepath.ToSlash(targetPath)
+	}
+	return targets, errs
+}
+
+func (s *TestSuite) TestCopyFlatten(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-flatten-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	source := filepath.Join(root, "source")
+	target := filepath.Join(root, "target")
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	for _, name := range []string{"a/x.txt", "b/x.txt", "b/c/x.txt", "y.txt", "b/.rc", "a/.rc"} {
+		c.Assert(os.MkdirAll(filepath.Join(source, filepath.Dir(name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+
+	// Relative paths are preserved by default.
+	targets, errs := flattenTestTargets(c, source, target, false, onCollisionFail)
+	c.Assert(errs, Equals, 0)
+	for sourcePath, targetPath := range targets {
+		c.Assert(targetPath, Equals, "source/"+sourcePath)
+	}
+
+	// Only the first object of every name is copied.
+	targets, errs = flattenTestTargets(c, source, target, true, onCollisionFail)
+	c.Assert(errs, Equals, 3)
+	c.Assert(targets, HasLen, 3)
+	c.Assert(targets["y.txt"], Equals, "y.txt")
+
+	// Objects of the same name are numbered in listing order, the same for every run.
+	targets, errs = flattenTestTargets(c, source, target, true, onCollisionSuffix)
+	c.Assert(errs, Equals, 0)
+	c.Assert(targets, HasLen, 6)
+	var names []string
+	for _, targetPath := range targets {
+		c.Assert(strings.Contains(targetPath, "/"), Equals, false)
+		names = append(names, targetPath)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{".rc", ".rc-1", "x-1.txt", "x-2.txt", "x.txt", "y.txt"})
+	c.Assert(targets["a/x.txt"], Equals, "x.txt")
+	c.Assert(targets["a/.rc"], Equals, ".rc")
+	again, _ := flattenTestTargets(c, source, target, true, onCollisionSuffix)
+	c.Assert(again, DeepEquals, targets)
+}
+
+func (s *TestSuite) TestSuffixedName(c *C) {
+	taken := map[string]bool{"photo.jpg": true, "photo-1.jpg": true, "archive.tar.gz": true}
+	c.Assert(suffixedName("photo.jpg", taken), Equals, "photo-2.jpg")
+	c.Assert(suffixedName("archive.tar.gz", taken), Equals, "archive.tar-1.gz")
+	c.Assert(suffixedName("README", taken), Equals, "README-1")
+}
+
+func (s *TestSuite) TestCheckChecksumAlgorithm(c *C) {
+	for input, algorithm := range map[string]string{"crc32": "CRC32", "CRC32C": "CRC32C", "Sha1": "SHA1", "sha256": "SHA256"} {
+		checked, err := checkChecksumAlgorithm(input)
+		c.Assert(err, IsNil)
+		c.Assert(checked, Equals, algorithm)
+	}
+	for _, input := range []string{"MD5", "crc64", "SHA-256"} {
+		_, err := checkChecksumAlgorithm(input)
+		c.Assert(err, Not(IsNil))
+	}
+}
+
+func (s *TestSuite) TestCheckStorageClass(c *C) {
+	for storageClass, isKnown := range map[string]bool{"STANDARD_IA": true, "GLACIER": true, "COLD_LINE": false, "TIER2": false} {
+		known, err := checkStorageClass(storageClass)
+		c.Assert(err, IsNil)
+		c.Assert(known, Equals, isKnown)
+	}
+	for _, storageClass := range []string{"glacier", "STANDARD IA", "_STANDARD", "STANDARD__IA", ""} {
+		_, err := checkStorageClass(storageClass)
+		c.Assert(err, Not(IsNil))
+	}
+}
+
+func (s *TestSuite) TestCopyResumeByPosition(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-resume-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	source := filepath.Join(root, "source") + string(os.PathSeparator)
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+
+	session := newTestCopySession(c, []string{source}, target, 2, true)
+	session.Header.CommandBoolFlags["recursive"] = true
+	summaryFile := filepath.Join(root, "summary.jsonl")
+	session.Header.CommandStringFlags["summary-file"] = summaryFile
+	doPrepareCopyURLs(session, make(chan bool))
+	var prepared []string
+	for cpURLs := range copyURLsFromSession(session) {
+		prepared = append(prepared, cpURLs.SourceContent.URL.String())
+	}
+	c.Assert(len(prepared), Equals, 4)
+
+	// Workers of the interrupted run finished the third object before the first one, the first was copied last.
+	session.markCopied(2, prepared[2], 1)
+	session.markCopied(0, prepared[0], 1)
+	c.Assert(session.Save(), IsNil)
+	c.Assert(session.Close(), IsNil)
+
+	// A file listed first now does not change what is resumed, the prepared list is.
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "0"), []byte("0"), 0600), IsNil)
+
+	session, err := loadSessionV6(session.SessionID)
+	c.Assert(err, IsNil)
+	defer session.Delete()
+	c.Assert(session.Header.LastCopied, Equals, prepared[0])
+	c.Assert(session.HasData(), Equals, true)
+	summary := doCopySession(session)
+	c.Assert(summary.exitCode(), Equals, 0)
+
+	file, e := os.Open(summaryFile)
+	c.Assert(e, IsNil)
+	defer file.Close()
+	statuses := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record transferRecord
+		c.Assert(json.Unmarshal(scanner.Bytes(), &record), IsNil)
+		_, ok := statuses[record.URL]
+		c.Assert(ok, Equals, false, Commentf("%s recorded twice", record.URL))
+		statuses[record.URL] = record.Status
+	}
+	c.Assert(statuses, DeepEquals, map[string]string{
+		prepared[0]: recordSkipped,
+		prepared[1]: recordTransferred,
+		prepared[2]: recordSkipped,
+		prepared[3]: recordTransferred,
+	})
+	c.Assert(session.Header.Copied, DeepEquals, copiedPositions{Next: 4})
+}
+
+func (s *TestSuite) TestCopyWorkers(c *C) {
+	defer func(saved int) { globalMaxConnsPerHost = saved }(globalMaxConnsPerHost)
+	globalMaxConnsPerHost = 0
+	c.Assert(copyWorkers(16), Equals, 16)
+	c.Assert(copyWorkers(0) >= 1, Equals, true)
+
+	// Workers beyond the connections to a host would only wait for one.
+	globalMaxConnsPerHost = 4
+	c.Assert(copyWorkers(16), Equals, 4)
+	c.Assert(copyWorkers(2), Equals, 2)
+	c.Assert(copyWorkers(0) <= 4, Equals, true)
+}
+
+func (s *TestSuite) TestCopyResumeProgress(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-resume-progress-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	var sourceURLs []string
+	for i, size := range []int{100, 200, 300, 400} {
+		source := filepath.Join(root, fmt.Sprintf("file%d", i))
+		c.Assert(ioutil.WriteFile(source, []byte(strings.Repeat("a", size)), 0600), IsNil)
+		sourceURLs = append(sourceURLs, source)
+	}
+
+	// An earlier run prepared all objects and copied the first two of them.
+	session := newTestCopySession(c, sourceURLs, target, 1, false)
+	defer session.Delete()
+	doPrepareCopyURLs(session, make(chan bool))
+	var prepared []copyURLs
+	scanner := bufio.NewScanner(session.NewDataReader())
+	for scanner.Scan() {
+		var cpURLs copyURLs
+		c.Assert(json.Unmarshal(scanner.Bytes(), &cpURLs), IsNil)
+		prepared = append(prepared, cpURLs)
+	}
+	c.Assert(prepared, HasLen, 4)
+	var copiedBytes int64
+	for position, cpURLs := range prepared[:2] {
+		session.markCopied(position, cpURLs.SourceContent.URL.String(), cpURLs.SourceContent.Size)
+		copiedBytes += cpURLs.SourceContent.Size
+	}
+	session.resumed = session.HasData()
+	c.Assert(session.resumed, Equals, true)
+
+	stat := newCopyProgress(session).Stat()
+	c.Assert(stat.TotalBytes, Equals, int64(1000))
+	c.Assert(stat.TotalObjects, Equals, 4)
+	c.Assert(stat.TransferredBytes, Equals, copiedBytes)
+	c.Assert(stat.TransferredObjects, Equals, 2)
+	c.Assert(stat.Percent(), Equals, float64(copiedBytes)*100/1000)
+
+	// An object left to copy grew since it was prepared, it is copied whole and the totals follow.
+	grown := prepared[2].SourceContent
+	c.Assert(ioutil.WriteFile(grown.URL.Path, []byte(strings.Repeat("b", int(grown.Size)+50)), 0600), IsNil)
+	summary := doCopySession(session)
+	c.Assert(summary.exitCode(), Equals, 0)
+	stat = session.progress.Stat()
+	c.Assert(stat.TotalBytes, Equals, int64(1050))
+	c.Assert(stat.TransferredBytes, Equals, int64(1050))
+	c.Assert(stat.TransferredObjects, Equals, 4)
+	data, e := ioutil.ReadFile(filepath.Join(target, filepath.Base(grown.URL.Path)))
+	c.Assert(e, IsNil)
+	c.Assert(len(data), Equals, int(grown.Size)+50)
+}
@@ -0,0 +1,238 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// differContent - text objects differing in contents, with ‘diff --content’.
+const differContent string = "content"
+
+// diffContextLines - unchanged lines shown around each change of a unified diff.
+const diffContextLines = 3
+
+// textContentTypes - content types of text not under ‘text/’.
+var textContentTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"application/xml":        true,
+	"application/x-ndjson":   true,
+	"application/x-sh":       true,
+	"application/x-yaml":     true,
+	"application/toml":       true,
+}
+
+// isTextContentType - contents of this type are lines of text, which are compared by ‘diff --content’.
+func isTextContentType(contentType string) bool {
+	contentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return strings.HasPrefix(contentType, "text/") || textContentTypes[contentType] ||
+		strings.HasSuffix(contentType, "+json") || strings.HasSuffix(contentType, "+xml")
+}
+
+// diffContent - options of ‘diff --content’.
+type diffContent struct {
+	maxSize      int64 // Objects larger than this are compared by size only.
+	normalizeEOL bool  // Compare lines ending in CRLF equal to lines ending in LF.
+}
+
+// readDiffContent - contents of urlStr, nil if it is larger than maxSize.
+func readDiffContent(alias, urlStr string, maxSize int64) ([]byte, *probe.Error) {
+	reader, err := getSourceFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, err.Trace(alias, urlStr)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	data, e := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// compare - unified diff of the text objects firstURL and secondURL, empty if their contents are
+// equal. ok is false if either is no text or too large, these are compared by size only.
+func (d diffContent) compare(firstAlias, firstURL, secondAlias, secondURL string) (unified string, ok bool, err *probe.Error) {
+	if !isTextContentType(guessURLContentType(firstURL)) || !isTextContentType(guessURLContentType(secondURL)) {
+		return "", false, nil
+	}
+	first, err := readDiffContent(firstAlias, firstURL, d.maxSize)
+	if err != nil || first == nil {
+		return "", false, err
+	}
+	second, err := readDiffContent(secondAlias, secondURL, d.maxSize)
+	if err != nil || second == nil {
+		return "", false, err
+	}
+	if d.normalizeEOL {
+		first = bytes.Replace(first, []byte("\r\n"), []byte("\n"), -1)
+		second = bytes.Replace(second, []byte("\r\n"), []byte("\n"), -1)
+	}
+	if bytes.Equal(first, second) {
+		return "", true, nil
+	}
+	return unifiedDiff(firstURL, secondURL, splitLines(string(first)), splitLines(string(second)), diffContextLines), true, nil
+}
+
+// splitLines - lines of text, without their line endings.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffEdit - a line of a unified diff, kept (' '), removed ('-') or added ('+').
+type diffEdit struct {
+	op   byte
+	line string
+}
+
+// diffLines - shortest edit script turning a into b, by the algorithm of Eugene W. Myers.
+func diffLines(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v after round d for the diagonals -d to d, to follow the path back.
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				break search
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	var edits []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1] // diagonals -(d-1) to d-1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && previous[k-1+d-1] < previous[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := previous[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, diffEdit{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, diffEdit{'+', b[y]})
+		} else {
+			x--
+			edits = append(edits, diffEdit{'-', a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, diffEdit{' ', a[x]})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// unifiedDiff - differences of the lines a of firstName and b of secondName in unified format,
+// with context unchanged lines around every change.
+func unifiedDiff(firstName, secondName string, a, b []string, context int) string {
+	edits := diffLines(a, b)
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", firstName, secondName)
+	// Line numbers in a and b of every edit, from zero.
+	aLine, bLine := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for i, edit := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if edit.op != '+' {
+			aLine[i+1]++
+		}
+		if edit.op != '-' {
+			bLine[i+1]++
+		}
+	}
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// A hunk runs from context lines before this change to context lines after the last change
+		// closer than twice context lines to the one before.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits) && j-end <= 2*context; j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			}
+		}
+		i = end
+		if end += context; end > len(edits) {
+			end = len(edits)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]), hunkRange(bLine[start], bLine[end]))
+		for _, edit := range edits[start:end] {
+			out.WriteByte(edit.op)
+			out.WriteString(edit.line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// hunkRange - lines from start up to end of a hunk header, numbered from one. An empty range
+// names the line before it.
+func hunkRange(start, end int) string {
+	if start == end {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
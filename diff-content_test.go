@@ -0,0 +1,73 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestDiffContent(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "diff-content-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	first := filepath.Join(root, "first.yaml")
+	second := filepath.Join(root, "second.yaml")
+	c.Assert(ioutil.WriteFile(first, []byte("a: 1\nb: 2\nc: 3\nd: 4\ne: 5\nf: 6\ng: 7\nh: 8\ni: 9\nj: 10\n"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(second, []byte("a: 1\nb: 20\nc: 3\nd: 4\ne: 5\nf: 6\ng: 7\nh: 8\ni: 9\nj: 10\nk: 11\n"), 0600), IsNil)
+
+	content := diffContent{maxSize: 1024}
+	unified, ok, err := content.compare("", first, "", second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(unified, Equals, "--- "+first+"\n+++ "+second+"\n"+
+		"@@ -1,5 +1,5 @@\n a: 1\n-b: 2\n+b: 20\n c: 3\n d: 4\n e: 5\n"+
+		"@@ -8,3 +8,4 @@\n h: 8\n i: 9\n j: 10\n+k: 11\n")
+
+	// Equal contents, also with other line endings once normalized.
+	c.Assert(ioutil.WriteFile(second, []byte("a: 1\r\nb: 2\r\nc: 3\r\nd: 4\r\ne: 5\r\nf: 6\r\ng: 7\r\nh: 8\r\ni: 9\r\nj: 10\r\n"), 0600), IsNil)
+	unified, ok, err = content.compare("", first, "", second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(unified, Not(Equals), "")
+	content.normalizeEOL = true
+	unified, ok, err = content.compare("", first, "", second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(unified, Equals, "")
+
+	// Objects too large, or not text, are compared by size only.
+	content.maxSize = 16
+	_, ok, err = content.compare("", first, "", second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+	_, ok, err = content.compare("", filepath.Join(root, "first.bin"), "", filepath.Join(root, "second.bin"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TestSuite) TestUnifiedDiff(c *C) {
+	c.Assert(unifiedDiff("a", "b", nil, []string{"x"}, 3), Equals, "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n")
+	c.Assert(unifiedDiff("a", "b", []string{"x", "y"}, []string{"y", "z"}, 3), Equals, "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-x\n y\n+z\n")
+	c.Assert(isTextContentType("application/json; charset=utf-8"), Equals, true)
+	c.Assert(isTextContentType("image/svg+xml"), Equals, true)
+	c.Assert(isTextContentType("application/octet-stream"), Equals, false)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
@@ -35,6 +36,19 @@ var (
 			Name:  "help, h",
 			Usage: "Help of diff.",
 		},
+		cli.BoolFlag{
+			Name:  "content",
+			Usage: "Compare contents of text objects, printing a unified diff of those differing.",
+		},
+		cli.StringFlag{
+			Name:  "content-max-size",
+			Value: "1MiB",
+			Usage: "Text objects larger than this are compared by size only with --content.",
+		},
+		cli.BoolFlag{
+			Name:  "normalize-eol",
+			Usage: "Compare lines ending in CRLF equal to lines ending in LF with --content.",
+		},
 	}
 )
 
@@ -42,7 +56,7 @@ var (
 var diffCmd = cli.Command{
 	Name:        "diff",
 	Usage:       "Compute differences between two folders.",
-	Description: "Diff only lists missing objects or objects with size differences. It *DOES NOT* compare contents. i.e. Objects of same name and size, but differ in contents are not noticed.",
+	Description: "Diff only lists missing objects or objects with size differences. It *DOES NOT* compare contents unless --content is set, i.e. Objects of same name and size, but differ in contents are not noticed. With --content text objects, by their content type, are downloaded and compared line by line, binary and larger objects still by size.",
 	Action:      mainDiff,
 	Flags:       append(diffFlags, globalFlags...),
 	CustomHelpTemplate: `NAME:
@@ -63,6 +77,9 @@ EXAMPLES:
 
    2. Compare two different folders on a local filesystem.
       $ mc {{.Name}} ~/Photos /Media/Backup/Photos
+
+   3. Find configuration drift between the staging and production folders on Amazon S3 cloud storage.
+      $ mc {{.Name}} --content --normalize-eol s3/configs/staging/ s3/configs/production/
 `,
 }
 
@@ -72,6 +89,7 @@ type diffMessage struct {
 	FirstURL  string       `json:"first"`
 	SecondURL string       `json:"second"`
 	Diff      string       `json:"diff"`
+	Unified   string       `json:"unified,omitempty"`
 	Error     *probe.Error `json:"error,omitempty"`
 }
 
@@ -88,6 +106,10 @@ func (d diffMessage) String() string {
 	case "size":
 		msg = console.Colorize("DiffMessage",
 			"‘"+d.FirstURL+"’"+" and "+"‘"+d.SecondURL+"’") + console.Colorize("DiffSize", " - differ in size.")
+	case differContent:
+		msg = console.Colorize("DiffMessage",
+			"‘"+d.FirstURL+"’"+" and "+"‘"+d.SecondURL+"’") + console.Colorize("DiffContent", " - differ in content.") +
+			"\n" + strings.TrimSuffix(d.Unified, "\n")
 	default:
 		fatalIf(errDummy().Trace(d.FirstURL, d.SecondURL),
 			"Unhandled difference between ‘"+d.FirstURL+"’ and ‘"+d.SecondURL+"’.")
@@ -114,6 +136,9 @@ func checkDiffSyntax(ctx *cli.Context) {
 			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
 		}
 	}
+	if _, err := parseDiffMaxSize(ctx.String("content-max-size")); err != nil {
+		fatalIf(err.Trace(ctx.String("content-max-size")), "Invalid size ‘"+ctx.String("content-max-size")+"’.")
+	}
 	URLs := ctx.Args()
 	firstURL := URLs[0]
 	secondURL := URLs[1]
@@ -141,8 +166,17 @@ func checkDiffSyntax(ctx *cli.Context) {
 	}
 }
 
-// doDiffMain runs the diff.
-func doDiffMain(firstURL, secondURL string) {
+// parseDiffMaxSize - parse a human readable size such as ‘1MiB’.
+func parseDiffMaxSize(sizeStr string) (int64, *probe.Error) {
+	size, e := humanize.ParseBytes(strings.TrimSpace(sizeStr))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	return int64(size), nil
+}
+
+// doDiffMain runs the diff, comparing contents of text objects unless content is nil.
+func doDiffMain(firstURL, secondURL string, content *diffContent) {
 	// Source and targets are always directories
 	sourceSeparator := string(client.NewURL(firstURL).Separator)
 	if !strings.HasSuffix(firstURL, sourceSeparator) {
@@ -169,7 +203,9 @@ func doDiffMain(firstURL, secondURL string) {
 	}
 	isRecursive := true
 	isIncomplete := false
-	for sourceContent := range firstClient.List(isRecursive, isIncomplete) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for sourceContent := range firstClient.List(isRecursive, isIncomplete, doneCh) {
 		if sourceContent.Err != nil {
 			switch sourceContent.Err.ToGoError().(type) {
 			// Handle this specifically for filesystem related errors.
@@ -184,19 +220,35 @@ func doDiffMain(firstURL, secondURL string) {
 			continue
 		}
 		suffix := strings.TrimPrefix(sourceContent.URL.String(), firstURL)
-		differ, err := difference(suffix, sourceContent.Type, sourceContent.Size)
+		differ, err := difference(sourceContent.URL, suffix, sourceContent.Type, sourceContent.Size)
 		if err != nil {
 			errorIf(sourceContent.Err.Trace(secondURL, suffix),
-				fmt.Sprintf("Failed on '%s'", urlJoinPath(secondURL, suffix)))
+				fmt.Sprintf("Failed on '%s'", urlJoinSourcePath(secondURL, sourceContent.URL, suffix)))
 			continue
 		}
+		targetURL := urlJoinSourcePath(secondURL, sourceContent.URL, suffix)
+		unified := ""
+		if content != nil && (differ == differNone || differ == differSize) {
+			diff, ok, err := content.compare(firstAlias, sourceContent.URL.String(), secondAlias, targetURL)
+			if err != nil {
+				errorIf(err.Trace(sourceContent.URL.String(), targetURL), fmt.Sprintf("Failed on '%s'", targetURL))
+				continue
+			}
+			// Text objects are compared by contents, others by size only.
+			if ok {
+				if differ, unified = differNone, diff; unified != "" {
+					differ = differContent
+				}
+			}
+		}
 		if differ == differNone {
 			continue
 		}
 		printMsg(diffMessage{
 			FirstURL:  sourceContent.URL.String(),
-			SecondURL: urlJoinPath(secondURL, suffix),
+			SecondURL: targetURL,
 			Diff:      differ,
+			Unified:   unified,
 		})
 	}
 }
@@ -214,10 +266,16 @@ func mainDiff(ctx *cli.Context) {
 	console.SetColor("DiffOnlyInFirst", color.New(color.FgRed, color.Bold))
 	console.SetColor("DiffType", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffSize", color.New(color.FgMagenta, color.Bold))
+	console.SetColor("DiffContent", color.New(color.FgBlue, color.Bold))
 
 	URLs := ctx.Args()
 	firstURL := URLs[0]
 	secondURL := URLs[1]
 
-	doDiffMain(firstURL, secondURL)
+	var content *diffContent
+	if ctx.Bool("content") {
+		maxSize, _ := parseDiffMaxSize(ctx.String("content-max-size"))
+		content = &diffContent{maxSize: maxSize, normalizeEOL: ctx.Bool("normalize-eol")}
+	}
+	doDiffMain(firstURL, secondURL, content)
 }
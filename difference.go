@@ -24,15 +24,17 @@ import (
 )
 
 // objectDifference function finds the difference between object on source and target
-// it takes suffix string, type and size on the source
+// it takes the source url, suffix string relative to it, type and size on the source
 // objectDifferenceFactory returns objectDifference function
-type objectDifference func(string, os.FileMode, int64) (string, *probe.Error)
+type objectDifference func(client.URL, string, os.FileMode, int64) (string, *probe.Error)
 
 const (
-	differSize      string = "size"          // differs in size
-	differOnlyFirst string = "only-in-first" // only on source
-	differType      string = "type"          // differs in type, ex file/directory
-	differNone      string = ""              // does not differ
+	differSize       string = "size"           // differs in size
+	differETag       string = "etag"           // same size, differs in ETag
+	differOnlyFirst  string = "only-in-first"  // only on source
+	differOnlySecond string = "only-in-second" // only on target
+	differType       string = "type"           // differs in type, ex file/directory
+	differNone       string = ""               // does not differ
 )
 
 // objectDifferenceFactory returns objectDifference function to check for difference
@@ -45,18 +47,19 @@ func objectDifferenceFactory(targetAlias, targetURL string) (objectDifference, *
 	}
 	isIncomplete := false
 	isRecursive := true
-	ch := clnt.List(isRecursive, isIncomplete)
+	// Target listing is consumed by the returned function for its lifetime, it is never stopped early.
+	ch := clnt.List(isRecursive, isIncomplete, nil)
 	current := targetURL
 	reachedEOF := false
 	ok := false
 	var content *client.Content
 
-	difference := func(suffix string, srcType os.FileMode, srcSize int64) (string, *probe.Error) {
+	difference := func(srcURL client.URL, suffix string, srcType os.FileMode, srcSize int64) (string, *probe.Error) {
 		if reachedEOF {
 			// would mean the suffix is not on target
 			return differOnlyFirst, nil
 		}
-		expected := urlJoinPath(targetURL, suffix)
+		expected := urlJoinSourcePath(targetURL, srcURL, suffix)
 		for {
 			if expected < current {
 				return differOnlyFirst, nil // not available in the target
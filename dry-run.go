@@ -0,0 +1,248 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// dryRunMessage container for operations skipped by ‘--dry-run’.
+type dryRunMessage struct {
+	Status    string `json:"status"`
+	Operation string `json:"operation"`
+	Source    string `json:"source,omitempty"`
+	Target    string `json:"target"`
+	Size      int64  `json:"size,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+// String colorized dry run message.
+func (d dryRunMessage) String() string {
+	message := console.Colorize("DryRun", "[dry-run] ") + d.Operation + " "
+	if d.Source != "" {
+		message += "‘" + d.Source + "’ -> "
+	}
+	message += "‘" + d.Target + "’"
+	if d.Value != "" {
+		message += " " + d.Value
+	}
+	if d.Size > 0 {
+		message += fmt.Sprintf(" (%s)", humanize.IBytes(uint64(d.Size)))
+	}
+	return message
+}
+
+// JSON jsonified dry run message.
+func (d dryRunMessage) JSON() string {
+	d.Status = "dry-run"
+	dryRunMessageBytes, e := json.Marshal(d)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(dryRunMessageBytes)
+}
+
+// dryRunClient wraps a client, mutating operations print what they would
+// do and succeed without touching the target. Reads pass through.
+type dryRunClient struct {
+	client.Client
+	alias string
+}
+
+// newDryRunClient - wrap clnt for ‘--dry-run’.
+func newDryRunClient(alias string, clnt client.Client) client.Client {
+	return dryRunClient{Client: clnt, alias: alias}
+}
+
+// aliasedPath - user facing path of url.
+func (d dryRunClient) aliasedPath(url client.URL) string {
+	if d.alias == "" {
+		return url.String()
+	}
+	return filepath.Join(d.alias, url.Path)
+}
+
+func (d dryRunClient) print(operation, value string, size int64) {
+	printMsg(dryRunMessage{
+		Operation: operation,
+		Target:    d.aliasedPath(d.GetURL()),
+		Size:      size,
+		Value:     value,
+	})
+}
+
+func (d dryRunClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	d.print("put", "", size)
+	return nil
+}
+
+func (d dryRunClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	d.print("put", "", 0)
+	return nil
+}
+
+func (d dryRunClient) Remove(incomplete bool, versionID string) *probe.Error {
+	switch {
+	case incomplete:
+		d.print("remove incomplete upload", "", 0)
+	case versionID != "":
+		d.print("remove version", versionID, 0)
+	default:
+		d.print("remove", "", 0)
+	}
+	return nil
+}
+
+func (d dryRunClient) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			if content.VersionID != "" {
+				printMsg(dryRunMessage{Operation: "remove version", Target: d.aliasedPath(content.URL), Value: content.VersionID, Size: content.Size})
+			} else {
+				printMsg(dryRunMessage{Operation: "remove", Target: d.aliasedPath(content.URL)})
+			}
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+func (d dryRunClient) RemoveUploads(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			printMsg(dryRunMessage{Operation: "remove incomplete upload", Target: d.aliasedPath(content.URL), Value: content.UploadID, Size: content.Size})
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+func (d dryRunClient) MakeBucket() *probe.Error {
+	d.print("make bucket", "", 0)
+	return nil
+}
+
+func (d dryRunClient) RemoveBucket() *probe.Error {
+	d.print("remove bucket", "", 0)
+	return nil
+}
+
+func (d dryRunClient) SetBucketAccess(access string) *probe.Error {
+	d.print("set access", access, 0)
+	return nil
+}
+
+func (d dryRunClient) SetBucketVersioning(enable bool) *probe.Error {
+	if enable {
+		d.print("set versioning", "Enabled", 0)
+	} else {
+		d.print("set versioning", "Suspended", 0)
+	}
+	return nil
+}
+
+func (d dryRunClient) SetBucketAccelerate(enable bool) *probe.Error {
+	if enable {
+		d.print("set accelerate", "Enabled", 0)
+	} else {
+		d.print("set accelerate", "Suspended", 0)
+	}
+	return nil
+}
+
+func (d dryRunClient) SetBucketReplication(config client.ReplicationConfig) *probe.Error {
+	if len(config.Rules) == 0 {
+		d.print("remove replication", "", 0)
+	} else {
+		d.print("set replication", fmt.Sprintf("%d rule(s)", len(config.Rules)), 0)
+	}
+	return nil
+}
+
+func (d dryRunClient) SetBucketPolicy(policy string) *probe.Error {
+	if policy == "" {
+		d.print("remove policy", "", 0)
+	} else {
+		d.print("set policy", "", int64(len(policy)))
+	}
+	return nil
+}
+
+func (d dryRunClient) SetTags(tags map[string]string) *probe.Error {
+	d.print("set tags", fmt.Sprintf("%d tag(s)", len(tags)), 0)
+	return nil
+}
+
+func (d dryRunClient) SetObjectACL(acl client.ACL) *probe.Error {
+	if acl.Canned != "" {
+		d.print("set acl", acl.Canned, 0)
+	} else {
+		d.print("set acl", fmt.Sprintf("%d grant(s)", len(acl.Grants)), 0)
+	}
+	return nil
+}
+
+func (d dryRunClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	d.print("set retention", mode+" until "+retainUntil.UTC().Format(time.RFC3339), 0)
+	return nil
+}
+
+func (d dryRunClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
+	d.print("set metadata", fmt.Sprintf("%d key(s)", len(metadata)), 0)
+	return nil
+}
+
+func (d dryRunClient) RestoreVersion(versionID string) *probe.Error {
+	d.print("restore version", versionID, 0)
+	return nil
+}
+
+func (d dryRunClient) RestoreArchived(days int, tier string) *probe.Error {
+	d.print("restore", fmt.Sprintf("%s tier for %d day(s)", tier, days), 0)
+	return nil
+}
+
+func (d dryRunClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	d.print("copy from", source.String(), 0)
+	return nil
+}
+
+func (d dryRunClient) WithContext(ctx context.Context) client.Client {
+	return dryRunClient{Client: d.Client.WithContext(ctx), alias: d.alias}
+}
+
+func (d dryRunClient) SetLegalHold(enabled bool) *probe.Error {
+	if enabled {
+		d.print("set legal hold", "ON", 0)
+	} else {
+		d.print("set legal hold", "OFF", 0)
+	}
+	return nil
+}
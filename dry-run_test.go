@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	. "gopkg.in/check.v1"
+)
+
+// captureDryRun - run fn with JSON output, returning dry run messages printed.
+func captureDryRun(c *C, fn func()) []dryRunMessage {
+	var buffer bytes.Buffer
+	savedOutput, savedJSON := color.Output, globalJSON
+	color.Output, globalJSON = &buffer, true
+	defer func() { color.Output, globalJSON = savedOutput, savedJSON }()
+	fn()
+
+	var msgs []dryRunMessage
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var msg dryRunMessage
+		c.Assert(json.Unmarshal([]byte(line), &msg), IsNil)
+		c.Assert(msg.Status, Equals, "dry-run")
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func (s *TestSuite) TestDryRunClientNoMutations(c *C) {
+	var mutations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			atomic.AddInt32(&mutations, 1)
+		}
+	}))
+	defer server.Close()
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	s3Clnt, err := s3.New(conf)
+	c.Assert(err, IsNil)
+	clnt := newDryRunClient("play", s3Clnt)
+
+	msgs := captureDryRun(c, func() {
+		c.Assert(clnt.Put(bytes.NewReader([]byte("hello")), 5, "", nil), IsNil)
+		c.Assert(clnt.PutStream(bytes.NewReader([]byte("hello")), 5*1024*1024, ""), IsNil)
+		c.Assert(clnt.Remove(false, ""), IsNil)
+		c.Assert(clnt.MakeBucket(), IsNil)
+		c.Assert(clnt.SetBucketAccess("public-read"), IsNil)
+		c.Assert(clnt.SetBucketVersioning(true), IsNil)
+		c.Assert(clnt.SetTags(map[string]string{"a": "b"}), IsNil)
+
+		contentCh := make(chan *client.Content, 1)
+		contentCh <- &client.Content{URL: *client.NewURL(server.URL + "/bucket/other")}
+		close(contentCh)
+		for content := range clnt.RemoveBatch(contentCh) {
+			c.Assert(content.Err, IsNil)
+		}
+	})
+	c.Assert(atomic.LoadInt32(&mutations), Equals, int32(0))
+
+	var operations []string
+	for _, msg := range msgs {
+		operations = append(operations, msg.Operation+" "+msg.Target)
+	}
+	c.Assert(operations, DeepEquals, []string{
+		"put play/bucket/object",
+		"put play/bucket/object",
+		"remove play/bucket/object",
+		"make bucket play/bucket/object",
+		"set access play/bucket/object",
+		"set versioning play/bucket/object",
+		"set tags play/bucket/object",
+		"remove play/bucket/other",
+	})
+}
+
+func (s *TestSuite) TestCopyDryRun(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-dry-run-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	source := filepath.Join(root, "source")
+	target := filepath.Join(root, "target")
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	for _, name := range []string{"a", "b", "c"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+
+	savedDryRun := globalDryRun
+	globalDryRun = true
+	defer func() { globalDryRun = savedDryRun }()
+
+	// A resumed session which already copied "a" only plans the rest.
+	isCopied := isCopiedAtFactory(copiedPositions{}, filepath.Join(source, "a"))
+	msgs := captureDryRun(c, func() {
+		doCopyDryRun(prepareCopyURLs([]string{source}, target, true, 1, false, ""), isCopied)
+	})
+	var planned []string
+	for _, msg := range msgs {
+		c.Assert(msg.Operation, Equals, "copy")
+		c.Assert(msg.Size, Equals, int64(1))
+		planned = append(planned, filepath.Base(msg.Source)+"->"+msg.Target)
+	}
+	c.Assert(planned, DeepEquals, []string{
+		"b->" + filepath.Join(target, "source", "b"),
+		"c->" + filepath.Join(target, "source", "c"),
+	})
+
+	entries, e := ioutil.ReadDir(target)
+	c.Assert(e, IsNil)
+	c.Assert(len(entries), Equals, 0)
+}
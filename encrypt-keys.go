@@ -0,0 +1,266 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// encryptKeySize - SSE-C keys are 256 bit AES keys.
+const encryptKeySize = 32
+
+// encryptKeysV1 - keystore of SSE-C keys by ‘ALIAS/BUCKET/PREFIX’ pattern, keys are base64 encoded.
+type encryptKeysV1 struct {
+	Version string            `json:"version"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// encryptKey - SSE-C key of objects under prefix of alias.
+type encryptKey struct {
+	pattern string
+	alias   string
+	prefix  string
+	key     []byte
+}
+
+// encryptKeyring - keys of ‘--encrypt-key-file’ and of the keystore.
+type encryptKeyring struct {
+	fileKeys  []encryptKey
+	storeKeys []encryptKey
+}
+
+// matches - true if objectPath of alias is under the pattern of k. A pattern of only a bucket
+// matches that whole bucket, not other buckets whose names start with it.
+func (k encryptKey) matches(alias, objectPath string) bool {
+	if k.alias != alias || !strings.HasPrefix(objectPath, k.prefix) {
+		return false
+	}
+	if k.prefix == "" || strings.Contains(k.prefix, "/") {
+		return true
+	}
+	rest := objectPath[len(k.prefix):]
+	return rest == "" || rest[0] == '/'
+}
+
+// lookup - key of the longest pattern matching objectPath of alias, keys of
+// ‘--encrypt-key-file’ win over the keystore on equal patterns.
+func (k encryptKeyring) lookup(alias, objectPath string) []byte {
+	var found *encryptKey
+	for _, keys := range [][]encryptKey{k.fileKeys, k.storeKeys} {
+		for i := range keys {
+			if !keys[i].matches(alias, objectPath) {
+				continue
+			}
+			if found == nil || len(keys[i].prefix) > len(found.prefix) {
+				found = &keys[i]
+			}
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	return found.key
+}
+
+var (
+	encryptKeysMutex sync.Mutex
+	// Keys loaded by the first lookup, reset whenever the key file or keystore changes.
+	globalEncryptKeyring *encryptKeyring
+)
+
+// setEncryptKeyFile - set the key file of ‘--encrypt-key-file’.
+func setEncryptKeyFile(filename string) {
+	encryptKeysMutex.Lock()
+	defer encryptKeysMutex.Unlock()
+	globalEncryptKeyFile = filename
+	globalEncryptKeyring = nil
+}
+
+// getEncryptionKey - SSE-C key of objectPath, relative to the host of alias. Nil if no key is configured.
+func getEncryptionKey(alias, objectPath string) ([]byte, *probe.Error) {
+	encryptKeysMutex.Lock()
+	defer encryptKeysMutex.Unlock()
+	if globalEncryptKeyring == nil {
+		keyring := &encryptKeyring{}
+		var err *probe.Error
+		if globalEncryptKeyFile != "" {
+			if keyring.fileKeys, err = loadEncryptKeyFile(globalEncryptKeyFile); err != nil {
+				return nil, err.Trace(globalEncryptKeyFile)
+			}
+		}
+		if keyring.storeKeys, err = loadEncryptKeystore(getEncryptKeysFile()); err != nil {
+			return nil, err.Trace()
+		}
+		globalEncryptKeyring = keyring
+	}
+	return globalEncryptKeyring.lookup(alias, strings.TrimPrefix(objectPath, "/")), nil
+}
+
+// getEncryptKeysFile - keystore file in the config folder.
+func getEncryptKeysFile() string {
+	return filepath.Join(mustGetMcConfigDir(), "keys.json")
+}
+
+// newEncryptKey - parse pattern ‘ALIAS[/BUCKET[/PREFIX]]’ and its base64 encoded key.
+func newEncryptKey(pattern, value string) (encryptKey, *probe.Error) {
+	alias, prefix := pattern, ""
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		alias, prefix = pattern[:i], pattern[i+1:]
+	}
+	if alias == "" {
+		return encryptKey{}, errInvalidEncryptKey(pattern, "pattern must start with an alias").Trace(pattern)
+	}
+	key, e := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if e != nil {
+		return encryptKey{}, errInvalidEncryptKey(pattern, "key is not base64 encoded").Trace(pattern)
+	}
+	if len(key) != encryptKeySize {
+		return encryptKey{}, errInvalidEncryptKey(pattern, "key must be 32 bytes").Trace(pattern)
+	}
+	return encryptKey{pattern: pattern, alias: alias, prefix: prefix, key: key}, nil
+}
+
+// encodeEncryptKey - key as saved in the keystore.
+func encodeEncryptKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// checkKeyFilePerms - key files must not be accessible by group or others.
+func checkKeyFilePerms(filename string) *probe.Error {
+	st, e := os.Stat(filename)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	// Windows has no permission bits, access is controlled by ACLs.
+	if runtime.GOOS != "windows" && st.Mode().Perm()&0077 != 0 {
+		return errInsecureKeyFile(filename).Trace(filename)
+	}
+	return nil
+}
+
+// loadEncryptKeyFile - read keys of ‘--encrypt-key-file’, one ‘PATTERN=BASE64KEY’ per line.
+// Empty lines and lines starting with ‘#’ are skipped.
+func loadEncryptKeyFile(filename string) ([]encryptKey, *probe.Error) {
+	if err := checkKeyFilePerms(filename); err != nil {
+		return nil, err.Trace(filename)
+	}
+	file, e := os.Open(filename)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer file.Close()
+
+	var keys []encryptKey
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, errInvalidEncryptKey(line, "expected PATTERN=KEY").Trace(filename)
+		}
+		key, err := newEncryptKey(strings.TrimSpace(line[:i]), line[i+1:])
+		if err != nil {
+			return nil, err.Trace(filename)
+		}
+		keys = append(keys, key)
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return keys, nil
+}
+
+// readEncryptKeystore - keystore entries by pattern, empty if the keystore does not exist yet.
+func readEncryptKeystore(filename string) (map[string]string, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return make(map[string]string), nil
+		}
+		return nil, probe.NewError(e)
+	}
+	if err := checkKeyFilePerms(filename); err != nil {
+		return nil, err.Trace(filename)
+	}
+	keystore := encryptKeysV1{}
+	if e = json.Unmarshal(data, &keystore); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if keystore.Version != "1" {
+		return nil, errInvalidEncryptKey(filename, "unsupported keystore version ‘"+keystore.Version+"’").Trace(filename)
+	}
+	if keystore.Keys == nil {
+		keystore.Keys = make(map[string]string)
+	}
+	return keystore.Keys, nil
+}
+
+// loadEncryptKeystore - read and validate all keys of the keystore.
+func loadEncryptKeystore(filename string) ([]encryptKey, *probe.Error) {
+	entries, err := readEncryptKeystore(filename)
+	if err != nil {
+		return nil, err.Trace(filename)
+	}
+	var keys []encryptKey
+	for pattern, value := range entries {
+		key, err := newEncryptKey(pattern, value)
+		if err != nil {
+			return nil, err.Trace(filename)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// updateEncryptKeystore - apply update to the keystore entries while holding the keystore lock.
+func updateEncryptKeystore(filename string, update func(keys map[string]string) *probe.Error) *probe.Error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err.Trace(filename)
+	}
+	defer unlock()
+
+	keys, err := readEncryptKeystore(filename)
+	if err != nil {
+		return err.Trace(filename)
+	}
+	if err = update(keys); err != nil {
+		return err.Trace(filename)
+	}
+	// Saved files are only accessible by the owner.
+	if err = saveFileAtomic(filename, encryptKeysV1{Version: "1", Keys: keys}); err != nil {
+		return err.Trace(filename)
+	}
+
+	encryptKeysMutex.Lock()
+	globalEncryptKeyring = nil
+	encryptKeysMutex.Unlock()
+	return nil
+}
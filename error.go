@@ -19,11 +19,78 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
 
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-go"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
+// Exit codes, stable so that scripts can tell failures apart.
+const (
+	exitFailure        = 1 // any failure not classified below.
+	exitAccessDenied   = 2 // invalid credentials or insufficient permissions.
+	exitNotFound       = 3 // bucket, object or path does not exist.
+	exitNetworkFailure = 4 // connection failures and timeouts.
+	exitPartialFailure = 5 // some objects failed, the rest were transferred.
+)
+
+// errorCodes - code reported in JSON error messages for each exit code.
+var errorCodes = map[int]string{
+	exitFailure:        "Failure",
+	exitAccessDenied:   "AccessDenied",
+	exitNotFound:       "NotFound",
+	exitNetworkFailure: "NetworkFailure",
+	exitPartialFailure: "PartialFailure",
+}
+
+// exitCode - exit code classifying the underlying cause of err.
+func exitCode(err *probe.Error) int {
+	e := err.ToGoError()
+	switch e.(type) {
+	case client.PathInsufficientPermission:
+		return exitAccessDenied
+	case client.PathNotFound, client.ObjectMissing:
+		return exitNotFound
+	case client.Timeout, client.ObjectTimeout:
+		return exitNetworkFailure
+	}
+	if errResponse := minio.ToErrorResponse(e); errResponse != nil {
+		switch errResponse.Code {
+		case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "AllAccessDisabled":
+			return exitAccessDenied
+		case "NoSuchKey", "NoSuchBucket", "NoSuchUpload", "NoSuchVersion":
+			return exitNotFound
+		case "RequestTimeout":
+			return exitNetworkFailure
+		}
+		return exitFailure
+	}
+	switch {
+	case os.IsPermission(e):
+		return exitAccessDenied
+	case os.IsNotExist(e):
+		return exitNotFound
+	}
+	if _, ok := e.(net.Error); ok || isTLSError(e) {
+		return exitNetworkFailure
+	}
+	return exitFailure
+}
+
+// isTLSError - TLS handshake and certificate verification failures.
+func isTLSError(e error) bool {
+	if urlErr, ok := e.(*url.Error); ok {
+		e = urlErr.Err
+	}
+	msg := e.Error()
+	return strings.HasPrefix(msg, "tls: ") || strings.HasPrefix(msg, "x509: ")
+}
+
 // causeMessage container for golang error messages
 type causeMessage struct {
 	Message string `json:"message"`
@@ -35,45 +102,65 @@ type errorMessage struct {
 	Message   string             `json:"message"`
 	Cause     causeMessage       `json:"cause"`
 	Type      string             `json:"type"`
+	Code      string             `json:"code"`
+	ExitCode  int                `json:"exitCode,omitempty"`
 	CallTrace []probe.TracePoint `json:"trace,omitempty"`
 	SysInfo   map[string]string  `json:"sysinfo"`
 }
 
-// fatalIf wrapper function which takes error and selectively prints stack frames if available on debug
+// printErrorJSON - print err as a JSON error message of errType classified by code.
+func printErrorJSON(err *probe.Error, msg, errType string, code int) {
+	errorMsg := errorMessage{
+		Message: msg,
+		Type:    errType,
+		Cause: causeMessage{
+			Message: err.ToGoError().Error(),
+			Error:   err.ToGoError(),
+		},
+		Code:    errorCodes[code],
+		SysInfo: err.SysInfo,
+	}
+	// Fatal errors are final, scripts get the exit code and the trace to report them.
+	if errType == "fatal" {
+		errorMsg.ExitCode = code
+	}
+	if globalDebug || errType == "fatal" {
+		errorMsg.CallTrace = err.CallTrace
+	}
+	json, e := json.Marshal(struct {
+		Status string       `json:"status"`
+		Error  errorMessage `json:"error"`
+	}{
+		Status: "error",
+		Error:  errorMsg,
+	})
+	if e != nil {
+		console.Fatalln(probe.NewError(e))
+	}
+	console.Println(string(json))
+}
+
+// fatalIf wrapper function which takes error and selectively prints stack frames if available on debug,
+// exits with the exit code classifying err.
 func fatalIf(err *probe.Error, msg string) {
 	if err == nil {
 		return
 	}
+	fatalWithCode(err, msg, exitCode(err))
+}
+
+// fatalWithCode same as fatalIf, exits with code.
+func fatalWithCode(err *probe.Error, msg string, code int) {
+	// Messages held back by the output format are printed ahead of the error.
+	flushOutput()
 	if globalJSON {
-		errorMsg := errorMessage{
-			Message: msg,
-			Type:    "fatal",
-			Cause: causeMessage{
-				Message: err.ToGoError().Error(),
-				Error:   err.ToGoError(),
-			},
-			SysInfo: err.SysInfo,
-		}
-		if globalDebug {
-			errorMsg.CallTrace = err.CallTrace
-		}
-		json, err := json.Marshal(struct {
-			Status string       `json:"status"`
-			Error  errorMessage `json:"error"`
-		}{
-			Status: "error",
-			Error:  errorMsg,
-		})
-		if err != nil {
-			console.Fatalln(probe.NewError(err))
-		}
-		console.Println(string(json))
-		console.Fatalln()
+		printErrorJSON(err, msg, "fatal", code)
+		console.FatalCodeln(code)
 	}
 	if !globalDebug {
-		console.Fatalln(fmt.Sprintf("%s %s", msg, err.ToGoError()))
+		console.FatalCodeln(code, fmt.Sprintf("%s %s", msg, err.ToGoError()))
 	}
-	console.Fatalln(fmt.Sprintf("%s %s", msg, err))
+	console.FatalCodeln(code, fmt.Sprintf("%s %s", msg, err))
 }
 
 // errorIf synonymous with fatalIf but doesn't exit on error != nil
@@ -82,29 +169,7 @@ func errorIf(err *probe.Error, msg string) {
 		return
 	}
 	if globalJSON {
-		errorMsg := errorMessage{
-			Message: msg,
-			Type:    "error",
-			Cause: causeMessage{
-				Message: err.ToGoError().Error(),
-				Error:   err.ToGoError(),
-			},
-			SysInfo: err.SysInfo,
-		}
-		if globalDebug {
-			errorMsg.CallTrace = err.CallTrace
-		}
-		json, err := json.Marshal(struct {
-			Status string       `json:"status"`
-			Error  errorMessage `json:"error"`
-		}{
-			Status: "error",
-			Error:  errorMsg,
-		})
-		if err != nil {
-			console.Fatalln(probe.NewError(err))
-		}
-		console.Println(string(json))
+		printErrorJSON(err, msg, "error", exitCode(err))
 		return
 	}
 	if !globalDebug {
@@ -0,0 +1,115 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// simulatedErrors - an error of each class along with the exit code it maps to.
+var simulatedErrors = map[string]struct {
+	err  error
+	code int
+}{
+	"generic":          {errors.New("something went wrong"), exitFailure},
+	"s3-unknown":       {minio.ErrorResponse{Code: "InternalError"}, exitFailure},
+	"access-denied":    {minio.ErrorResponse{Code: "AccessDenied"}, exitAccessDenied},
+	"invalid-key":      {minio.ErrorResponse{Code: "InvalidAccessKeyId"}, exitAccessDenied},
+	"bad-signature":    {minio.ErrorResponse{Code: "SignatureDoesNotMatch"}, exitAccessDenied},
+	"permission":       {client.PathInsufficientPermission{Path: "s3/bucket"}, exitAccessDenied},
+	"fs-permission":    {&os.PathError{Op: "open", Path: "/root", Err: os.ErrPermission}, exitAccessDenied},
+	"no-such-key":      {minio.ErrorResponse{Code: "NoSuchKey"}, exitNotFound},
+	"no-such-bucket":   {minio.ErrorResponse{Code: "NoSuchBucket"}, exitNotFound},
+	"path-not-found":   {client.PathNotFound{Path: "/tmp/missing"}, exitNotFound},
+	"object-missing":   {client.ObjectMissing{}, exitNotFound},
+	"fs-not-exist":     {&os.PathError{Op: "open", Path: "/tmp/missing", Err: os.ErrNotExist}, exitNotFound},
+	"timeout":          {client.Timeout{Op: "Get", URL: "https://s3.amazonaws.com"}, exitNetworkFailure},
+	"request-timeout":  {minio.ErrorResponse{Code: "RequestTimeout"}, exitNetworkFailure},
+	"connection-reset": {&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, exitNetworkFailure},
+	"untrusted-cert":   {errors.New("x509: certificate signed by unknown authority"), exitNetworkFailure},
+}
+
+func (s *TestSuite) TestExitCode(c *C) {
+	for name, simulated := range simulatedErrors {
+		c.Assert(exitCode(probe.NewError(simulated.err)), Equals, simulated.code, Commentf("%s", name))
+		// Tracing keeps the cause.
+		c.Assert(exitCode(probe.NewError(simulated.err).Trace("s3/bucket")), Equals, simulated.code, Commentf("%s", name))
+	}
+
+	summary := newTransferSummary(time.Now())
+	summary.Transferred(10)
+	c.Assert(summary.exitCode(), Equals, 0)
+	summary.Failed(10, "s3/bucket/object", probe.NewError(errors.New("failed")))
+	c.Assert(summary.exitCode(), Equals, exitPartialFailure)
+}
+
+// TestFatalIfExitCode runs itself for every simulated error, the child process exits through fatalIf.
+func (s *TestSuite) TestFatalIfExitCode(c *C) {
+	if name := os.Getenv("MC_TEST_FATAL_ERROR"); name != "" {
+		globalJSON = os.Getenv("MC_TEST_FATAL_JSON") != ""
+		fatalIf(probe.NewError(simulatedErrors[name].err), "Simulated failure.")
+		return
+	}
+	for name, simulated := range simulatedErrors {
+		for _, jsonMode := range []bool{false, true} {
+			cmd := exec.Command(os.Args[0], "-test.run=^Test$", "-check.f=TestFatalIfExitCode$")
+			cmd.Env = append(os.Environ(), "MC_TEST_FATAL_ERROR="+name)
+			if jsonMode {
+				cmd.Env = append(cmd.Env, "MC_TEST_FATAL_JSON=1")
+			}
+			output, e := cmd.Output()
+			exitErr, ok := e.(*exec.ExitError)
+			c.Assert(ok, Equals, true, Commentf("%s: %v", name, e))
+			c.Assert(exitErr.Sys().(interface {
+				ExitStatus() int
+			}).ExitStatus(), Equals, simulated.code, Commentf("%s", name))
+			if !jsonMode {
+				continue
+			}
+			var message struct {
+				Status string `json:"status"`
+				Error  struct {
+					Cause struct {
+						Message string `json:"message"`
+					} `json:"cause"`
+					Code      string             `json:"code"`
+					ExitCode  int                `json:"exitCode"`
+					CallTrace []probe.TracePoint `json:"trace"`
+				} `json:"error"`
+			}
+			// The error is the last line printed.
+			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+			c.Assert(json.Unmarshal([]byte(lines[len(lines)-1]), &message), IsNil, Commentf("%s: %s", name, output))
+			c.Assert(message.Status, Equals, "error")
+			c.Assert(message.Error.Code, Equals, errorCodes[simulated.code])
+			c.Assert(message.Error.ExitCode, Equals, simulated.code)
+			c.Assert(message.Error.Cause.Message, Equals, simulated.err.Error())
+			c.Assert(len(message.Error.CallTrace) > 0, Equals, true)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+)
+
+// contentFilter - sources left out of a copy or mirror by ‘--exclude-empty’ and ‘--exclude-hidden’,
+// on top of any ‘--exclude’ patterns.
+type contentFilter struct {
+	excludeEmpty  bool
+	excludeHidden bool
+}
+
+// getSessionContentFilter - content filter saved in session.
+func getSessionContentFilter(session *sessionV6) contentFilter {
+	return contentFilter{
+		excludeEmpty:  session.Header.CommandBoolFlags["exclude-empty"],
+		excludeHidden: session.Header.CommandBoolFlags["exclude-hidden"],
+	}
+}
+
+// isEmptyExcluded - true if content is a zero byte source left out with ‘--exclude-empty’.
+func (f contentFilter) isEmptyExcluded(content *client.Content) bool {
+	return f.excludeEmpty && content.Size == 0
+}
+
+// isHiddenExcluded - true if content is a dotfile left out with ‘--exclude-hidden’. Objects are never
+// hidden, only files are.
+func (f contentFilter) isHiddenExcluded(content *client.Content) bool {
+	if !f.excludeHidden || content.URL.Type == client.Object {
+		return false
+	}
+	name := content.URL.Path[strings.LastIndex(content.URL.Path, string(content.URL.Separator))+1:]
+	return strings.HasPrefix(name, ".")
+}
+
+// isExcluded - true if content is left out for either reason.
+func (f contentFilter) isExcluded(content *client.Content) bool {
+	return f.isEmptyExcluded(content) || f.isHiddenExcluded(content)
+}
+
+// filterExcludedCopyURLs - copy URLs of sources not left out by filter, errors are passed on.
+func filterExcludedCopyURLs(URLsCh <-chan copyURLs, filter contentFilter) <-chan copyURLs {
+	if filter == (contentFilter{}) {
+		return URLsCh
+	}
+	includedCh := make(chan copyURLs)
+	go func() {
+		defer close(includedCh)
+		for cpURLs := range URLsCh {
+			if cpURLs.Error != nil || !filter.isExcluded(cpURLs.SourceContent) {
+				includedCh <- cpURLs
+			}
+		}
+	}()
+	return includedCh
+}
+
+// warnExcludeHidden - warn ‘--exclude-hidden’ has no effect on sources which are objects.
+func warnExcludeHidden(sourceURLs []string) {
+	for _, sourceURL := range sourceURLs {
+		_, urlStr, _ := mustExpandAlias(sourceURL)
+		if client.NewURL(urlStr).Type != client.Object {
+			continue
+		}
+		err := errHiddenObjects(sourceURL)
+		if globalJSON {
+			printErrorJSON(err, "Option --exclude-hidden has no effect on it.", "warning", exitFailure)
+			continue
+		}
+		console.Errorln(err.ToGoError().Error() + " Option --exclude-hidden has no effect on it.")
+	}
+}
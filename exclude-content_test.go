@@ -0,0 +1,114 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestContentFilterSource - a folder with an empty file and dotfiles besides regular files.
+func newTestContentFilterSource(c *C, root string) string {
+	source := filepath.Join(root, "source")
+	c.Assert(os.MkdirAll(filepath.Join(source, "dir"), 0700), IsNil)
+	for name, data := range map[string]string{
+		"a":          "a",
+		"empty":      "",
+		".hidden":    "hidden",
+		"dir/b":      "b",
+		"dir/.empty": "",
+	} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(data), 0600), IsNil)
+	}
+	return source
+}
+
+// listTestFiles - regular files below root, relative to it.
+func listTestFiles(c *C, root string) []string {
+	var names []string
+	c.Assert(filepath.Walk(root, func(path string, info os.FileInfo, e error) error {
+		if e == nil && info.Mode().IsRegular() {
+			name, _ := filepath.Rel(root, path)
+			names = append(names, filepath.ToSlash(name))
+		}
+		return e
+	}), IsNil)
+	sort.Strings(names)
+	return names
+}
+
+func (s *TestSuite) TestCopyExcludeContent(c *C) {
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	testCases := []struct {
+		filter contentFilter
+		copied []string
+	}{
+		{contentFilter{}, []string{".hidden", "a", "dir/.empty", "dir/b", "empty"}},
+		{contentFilter{excludeEmpty: true}, []string{".hidden", "a", "dir/b"}},
+		{contentFilter{excludeHidden: true}, []string{"a", "dir/b", "empty"}},
+		{contentFilter{excludeEmpty: true, excludeHidden: true}, []string{"a", "dir/b"}},
+	}
+	for i, testCase := range testCases {
+		root, e := ioutil.TempDir(os.TempDir(), "cp-exclude-content-")
+		c.Assert(e, IsNil)
+		source := newTestContentFilterSource(c, root)
+		target := filepath.Join(root, "target")
+
+		session := newTestCopySession(c, []string{source}, target, 1, false)
+		session.Header.CommandBoolFlags["recursive"] = true
+		session.Header.CommandBoolFlags["exclude-empty"] = testCase.filter.excludeEmpty
+		session.Header.CommandBoolFlags["exclude-hidden"] = testCase.filter.excludeHidden
+		summary := doCopySession(session)
+		session.Delete()
+		c.Assert(summary.Message(session).Failed, Equals, 0, Commentf("case %d", i))
+		c.Assert(listTestFiles(c, filepath.Join(target, "source")), DeepEquals, testCase.copied, Commentf("case %d", i))
+		os.RemoveAll(root)
+	}
+}
+
+func (s *TestSuite) TestMirrorExcludeContent(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mirror-exclude-content-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := newTestContentFilterSource(c, root)
+	target := filepath.Join(root, "target")
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	// Targets of excluded sources are left alone even with --remove.
+	c.Assert(ioutil.WriteFile(filepath.Join(target, "empty"), []byte("stale"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(target, ".profile"), []byte("kept"), 0600), IsNil)
+
+	var copied, removed []string
+	filter := contentFilter{excludeEmpty: true, excludeHidden: true}
+	for mURLs := range prepareMirrorURLs(source, target, false, false, true, false, nil, filter) {
+		c.Assert(mURLs.Error, IsNil)
+		if mURLs.SourceContent == nil {
+			removed = append(removed, filepath.Base(mURLs.TargetContent.URL.Path))
+			continue
+		}
+		copied = append(copied, filepath.Base(mURLs.SourceContent.URL.Path))
+	}
+	sort.Strings(copied)
+	c.Assert(copied, DeepEquals, []string{"a", "b"})
+	c.Assert(removed, IsNil)
+}
@@ -0,0 +1,156 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	existsFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of exists.",
+		},
+		cli.BoolFlag{
+			Name:  "verbose, v",
+			Usage: "Print whether the target exists, and why it could not be told.",
+		},
+	}
+)
+
+// check if a file, folder, bucket or object exists.
+var existsCmd = cli.Command{
+	Name:   "exists",
+	Usage:  "Check if a file, folder, bucket or object exists.",
+	Action: mainExists,
+	Flags:  append(existsFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXIT STATUS:
+   0 if TARGET exists, 3 if it does not. 2 if access was denied, 4 on network failures and 1
+   on any other failure, whether TARGET exists could not be told.
+
+EXAMPLES:
+   1. Upload a file only if the object does not exist yet.
+      $ mc {{.Name}} s3/backups/2015-12-29.tar || mc cp 2015-12-29.tar s3/backups/
+
+   2. Check if a bucket exists, printing the result.
+      $ mc {{.Name}} --verbose play/mybucket
+
+   3. Check if a local folder exists.
+      $ mc {{.Name}} /var/backups
+`,
+}
+
+// existsMessage container for the result of an existence check.
+type existsMessage struct {
+	Status string `json:"status"`
+	URL    string `json:"url"`
+	Exists bool   `json:"exists"`
+	Type   string `json:"type,omitempty"`
+}
+
+// String colorized exists message.
+func (e existsMessage) String() string {
+	if !e.Exists {
+		return console.Colorize("ExistsMissing", "‘"+e.URL+"’ does not exist.")
+	}
+	return console.Colorize("Exists", "‘"+e.URL+"’ exists, "+e.Type+".")
+}
+
+// JSON jsonified exists message.
+func (e existsMessage) JSON() string {
+	existsJSONBytes, err := json.Marshal(e)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(existsJSONBytes)
+}
+
+// Validate command line arguments.
+func checkExistsSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "exists", 1) // last argument is exit code
+	}
+}
+
+// checkExists - exit code telling if urlStr exists: zero if it does, exitNotFound if it does not.
+// Any other code is that of the error returned, which kept from telling.
+func checkExists(urlStr string) (existsMessage, int, *probe.Error) {
+	message := existsMessage{Status: "success", URL: urlStr}
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return message, exitCode(err), err.Trace(urlStr)
+	}
+	content, err := clnt.Stat()
+	if err != nil {
+		if code := exitCode(err); code != exitNotFound {
+			return message, code, err.Trace(urlStr)
+		}
+		return message, exitNotFound, nil
+	}
+	message.Exists = true
+	message.Type = "file"
+	if content.Type.IsDir() {
+		message.Type = "folder"
+	}
+	return message, 0, nil
+}
+
+// mainExists is entry point for exists command.
+func mainExists(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'exists' cli arguments.
+	checkExistsSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Exists", color.New(color.FgGreen, color.Bold))
+	console.SetColor("ExistsMissing", color.New(color.FgYellow, color.Bold))
+
+	verbose := ctx.Bool("verbose")
+	urlStr := ctx.Args().First()
+	message, code, err := checkExists(urlStr)
+	if err != nil {
+		if verbose {
+			fatalIf(err, "Unable to check if ‘"+urlStr+"’ exists.")
+		}
+		flushOutput()
+		os.Exit(code)
+	}
+	if verbose {
+		printMsg(message)
+	}
+	if code != 0 {
+		flushOutput()
+		os.Exit(code)
+	}
+}
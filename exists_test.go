@@ -0,0 +1,94 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+// existsHandler is an http.Handler serving bucket ‘bucket’ with object ‘present’,
+// ‘denied’ is forbidden and every other object is missing.
+type existsHandler struct{}
+
+func (h existsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "HEAD" && (r.URL.Path == "/bucket" || r.URL.Path == "/bucket/present"):
+		w.Header().Set("Content-Length", "0")
+		w.Header().Set("ETag", "\"d41d8cd98f00b204e9800998ecf8427e\"")
+		w.Header().Set("Last-Modified", "Thu, 21 May 2015 18:24:21 GMT")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "HEAD" && r.URL.Path == "/bucket/denied":
+		w.WriteHeader(http.StatusForbidden)
+	case r.Method == "HEAD":
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == "GET" && r.URL.Path == "/bucket":
+		// Missing objects are looked up as folders.
+		w.Write([]byte("<ListBucketResult xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"><IsTruncated>false</IsTruncated><Name>bucket</Name></ListBucketResult>"))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (s *TestSuite) TestExistsObject(c *C) {
+	defer useMetadataServer(c, existsHandler{})()
+
+	message, code, err := checkExists("meta/bucket/present")
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, 0)
+	c.Assert(message.Exists, Equals, true)
+	c.Assert(message.Type, Equals, "file")
+
+	message, code, err = checkExists("meta/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, 0)
+	c.Assert(message.Type, Equals, "folder")
+
+	message, code, err = checkExists("meta/bucket/missing")
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, exitNotFound)
+	c.Assert(message.Exists, Equals, false)
+
+	_, code, err = checkExists("meta/bucket/denied")
+	c.Assert(err, NotNil)
+	c.Assert(code, Equals, exitAccessDenied)
+}
+
+func (s *TestSuite) TestExistsFilesystem(c *C) {
+	root, e := ioutil.TempDir("", "mc-exists-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "present"), []byte("present"), 0600), IsNil)
+
+	message, code, err := checkExists(filepath.Join(root, "present"))
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, 0)
+	c.Assert(message.Type, Equals, "file")
+
+	message, code, err = checkExists(root)
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, 0)
+	c.Assert(message.Type, Equals, "folder")
+
+	_, code, err = checkExists(filepath.Join(root, "missing"))
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, exitNotFound)
+}
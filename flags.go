@@ -43,10 +43,76 @@ var globalFlags = []cli.Flag{
 		Name:  "json",
 		Usage: "Enable json formatted output.",
 	},
+	cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format of messages, ‘text’, ‘json’, ‘csv’ or ‘table’. Defaults to text.",
+	},
+	cli.StringFlag{
+		Name:  "filter",
+		Usage: "JMESPath expression applied to each JSON message, such as ‘size > `1024`’ or ‘{key: key, size: size}’.",
+	},
 	cli.BoolFlag{
 		Name:  "debug",
 		Usage: "Enable debugging output.",
 	},
+	cli.StringFlag{
+		Name:  "debug-format",
+		Usage: "Format of HTTP traces of ‘--debug’, ‘verbose’ or ‘compact’. Defaults to verbose.",
+	},
+	cli.StringFlag{
+		Name:  "audit",
+		Usage: "Append what every request is signed for to a file, a line of JSON each, without signatures or secrets.",
+	},
+	cli.BoolFlag{
+		Name:  "dry-run, fake",
+		Usage: "Print what would be changed, without changing anything.",
+	},
+	cli.BoolFlag{
+		Name:  "no-stat-cache",
+		Usage: "Disable caching of object and folder lookups within a command.",
+	},
+	cli.BoolFlag{
+		Name:  "no-dns-cache",
+		Usage: "Resolve hosts on every connection, instead of reusing their addresses for 30s.",
+	},
+	cli.StringFlag{
+		Name:  "encrypt-key-file",
+		Usage: "File of SSE-C keys, one ‘ALIAS/BUCKET/PREFIX=BASE64KEY’ per line.",
+	},
+	cli.StringFlag{
+		Name:  "encrypt-mode",
+		Usage: "Encryption by keys of ‘--encrypt-key-file’ and the keystore, ‘sse-c’ by the server or ‘client’ before upload. Defaults to sse-c.",
+	},
+	cli.DurationFlag{
+		Name:  "conn-timeout",
+		Usage: "Timeout for connecting to a host, including TLS handshake. Defaults to 30s.",
+	},
+	cli.DurationFlag{
+		Name:  "read-timeout",
+		Usage: "Timeout waiting for a host to respond to a request. Defaults to 2m.",
+	},
+	cli.DurationFlag{
+		Name:  "idle-timeout",
+		Usage: "Timeout for a stalled transfer, reset whenever data moves. Defaults to 5m.",
+	},
+	cli.DurationFlag{
+		Name:  "keepalive-timeout",
+		Usage: "Time an idle connection is kept for reuse. Defaults to 90s.",
+	},
+	cli.IntFlag{
+		Name:  "max-conns-per-host",
+		Usage: "Limit connections and requests in flight to a single host, shared by all aliases of the host, also caps --workers. Unlimited by default.",
+	},
+	cli.StringFlag{
+		Name:   "user-agent-suffix",
+		Usage:  "Append to the User-Agent of every request, to tell jobs or hosts apart in access logs.",
+		EnvVar: userAgentSuffixEnv,
+	},
+	cli.BoolFlag{
+		Name:   "no-correlation-id",
+		Usage:  "Do not send the x-amz-request-correlation header tying the requests of a command together, for hosts which reject it.",
+		EnvVar: noCorrelationIDEnv,
+	},
 }
 
 // registerCmd registers a cli command
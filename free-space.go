@@ -0,0 +1,180 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// diskFree - bytes available on the filesystem of a folder, replaced by tests.
+var diskFree = fs.DiskFree
+
+// freeSpaceInterval - free space of a local target is checked this often during a transfer.
+var freeSpaceInterval = 5 * time.Second
+
+// parseMinFree - bytes ‘--min-free’ keeps free on a local target, none if empty.
+func parseMinFree(minFree string) (uint64, *probe.Error) {
+	if minFree == "" {
+		return 0, nil
+	}
+	reserve, e := humanize.ParseBytes(strings.TrimSpace(minFree))
+	if e != nil {
+		return 0, probe.NewError(e).Trace(minFree)
+	}
+	return reserve, nil
+}
+
+// checkMinFreeSyntax - validate the size of ‘--min-free’.
+func checkMinFreeSyntax(ctx *cli.Context) {
+	minFree := ctx.String("min-free")
+	_, err := parseMinFree(minFree)
+	fatalIf(err.Trace(minFree), "Invalid size ‘"+minFree+"’ of --min-free.")
+}
+
+// localTargetDir - folder of targetURL on a local filesystem, the closest existing one above a target
+// not created yet. Empty for targets on object storage.
+func localTargetDir(targetURL string) string {
+	_, urlStr, _ := mustExpandAlias(targetURL)
+	url := client.NewURL(urlStr)
+	if url.Type != client.Filesystem {
+		return ""
+	}
+	dir := url.Path
+	for {
+		if st, e := os.Stat(dir); e == nil && st.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// checkFreeSpace - need bytes have to fit into the free space of dir with reserve left over. Platforms
+// which do not report free space are not checked.
+func checkFreeSpace(dir string, need int64, reserve uint64) *probe.Error {
+	free, err := diskFree(dir)
+	if err != nil {
+		if _, ok := err.ToGoError().(client.APINotImplemented); ok {
+			return nil
+		}
+		return err.Trace(dir)
+	}
+	if need < 0 {
+		need = 0
+	}
+	if free < reserve || free-reserve < uint64(need) {
+		return errInsufficientFreeSpace(dir, need, free, reserve).Trace(dir)
+	}
+	return nil
+}
+
+// freeSpaceGuard checks the free space of a local target while a session transfers to it. The session
+// is aborted once what is left to transfer no longer fits, before the disk is full.
+type freeSpaceGuard struct {
+	dir       string
+	reserve   uint64
+	remaining func() int64
+	abort     context.CancelFunc
+	stopCh    chan struct{}
+
+	mutex sync.Mutex
+	err   *probe.Error
+}
+
+// newFreeSpaceGuard - guard of the local target of session, nil for targets on object storage. What is
+// left to transfer, as reported by remaining, has to fit right away or the session is not started.
+func newFreeSpaceGuard(session *sessionV6, target string, remaining func() int64, abort context.CancelFunc) (*freeSpaceGuard, *probe.Error) {
+	dir := localTargetDir(target)
+	if dir == "" {
+		return nil, nil
+	}
+	reserve, err := parseMinFree(session.Header.CommandStringFlags["min-free"])
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if err = checkFreeSpace(dir, remaining(), reserve); err != nil {
+		return nil, err.Trace(target)
+	}
+	g := &freeSpaceGuard{
+		dir:       dir,
+		reserve:   reserve,
+		remaining: remaining,
+		abort:     abort,
+		stopCh:    make(chan struct{}),
+	}
+	go g.watch()
+	return g, nil
+}
+
+// watch - check free space every freeSpaceInterval until stopped or aborted.
+func (g *freeSpaceGuard) watch() {
+	ticker := time.NewTicker(freeSpaceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stopCh:
+			return
+		case <-ticker.C:
+			if err := checkFreeSpace(g.dir, g.remaining(), g.reserve); err != nil {
+				g.mutex.Lock()
+				g.err = err.Trace()
+				g.mutex.Unlock()
+				g.abort()
+				return
+			}
+		}
+	}
+}
+
+// stop - end checking, a nil guard has nothing to stop.
+func (g *freeSpaceGuard) stop() {
+	if g != nil {
+		close(g.stopCh)
+	}
+}
+
+// failed - error the session was aborted on for lack of free space, nil otherwise.
+func (g *freeSpaceGuard) failed() *probe.Error {
+	if g == nil {
+		return nil
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.err
+}
+
+// remainingBytes - bytes progress has left to transfer.
+func remainingBytes(progress *progressAccumulator) func() int64 {
+	return func() int64 {
+		stat := progress.Stat()
+		return stat.TotalBytes - stat.TransferredBytes
+	}
+}
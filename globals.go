@@ -18,6 +18,9 @@
 package main
 
 import (
+	"time"
+
+	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
 )
@@ -25,6 +28,9 @@ import (
 // mc configuration related constants.
 const (
 	globalMCConfigVersion = "7"
+	// Session and share db formats written by this version.
+	globalSessionVersion = "6"
+	globalShareDBVersion = "2"
 
 	globalMCConfigDir        = ".mc/"
 	globalMCConfigWindowsDir = "mc\\"
@@ -33,6 +39,8 @@ const (
 	// session config and shared urls related constants
 	globalSessionDir        = "session"
 	globalSharedURLsDataDir = "share"
+	globalContentCacheDir   = "cache"
+	globalSyncDir           = "sync"
 )
 
 var (
@@ -40,21 +48,105 @@ var (
 	globalJSON    = false // Json flag set via command line
 	globalDebug   = false // Debug flag set via command line
 	globalNoColor = false // Debug flag set via command line
+	globalDryRun  = false // Dry run flag set via command line
+	// Format of ‘--debug’ HTTP traces set via command line, only changed through setDebugFormat.
+	globalDebugFormat = debugFormatVerbose
+	// Stat cache flag set via command line, the cache itself is replaced on every setGlobals.
+	globalNoStatCache = false
+	globalStatCache   *statCache
+	// Resolve endpoint hosts on every dial, set via command line.
+	globalNoDNSCache = false
+	// Timeouts set via command line, zero leaves it to the alias config or built-in defaults.
+	globalConnTimeout time.Duration
+	globalReadTimeout time.Duration
+	globalIdleTimeout time.Duration
+	// Connection pooling set via command line, zero leaves it to built-in defaults.
+	globalKeepAliveTimeout time.Duration
+	globalMaxConnsPerHost  int
+	// Parts of a single object uploaded in parallel, set from the cp and mirror session, zero is the default.
+	globalPartConcurrency int
+	// Upload with a single PUT to every host, set by ‘cp --disable-multipart’.
+	globalDisableMultipart bool
+	// Storage class of uploaded objects, set by ‘cp --storage-class’.
+	globalStorageClass string
+	// Canned ACL of uploaded and copied objects, set by ‘cp --acl’ and ‘mirror --acl’.
+	globalCannedACL string
+	// Encoding of user metadata values outside printable ASCII, set by ‘cp --metadata-charset’ and
+	// ‘mirror --metadata-charset’, RFC 2047 if empty.
+	globalMetadataCharset string
+	// Additional checksum of uploaded objects, set by ‘cp --checksum’.
+	globalChecksumAlgorithm string
+	// Signing region of every host in place of that of its alias, set by ‘cp --region’.
+	globalRegion string
+	// Paces the transfers of cp and mirror, set from ‘--bw-schedule’ of the session, nil is unlimited.
+	globalBandwidthLimiter *bandwidthLimiter
+	// Bounds of a single object and of the whole mirror, set from ‘--object-timeout’ and ‘--total-timeout’
+	// of the session, zero is unbounded.
+	globalObjectTimeout time.Duration
+	globalTotalTimeout  time.Duration
+	// Content type and metadata of uploads, loaded from ‘cp --metadata-from-file’ of the session.
+	globalMetadataRules metadataRules
+	// Cache-Control and attributes of uploads, from ‘--cache-control-map’ and ‘--attr’ of the cp and mirror session.
+	globalCacheControlMap cacheControlMap
+	globalUploadAttrs     *uploadAttrs
+	// Metadata directive of object copies, from ‘--metadata-directive’ of the cp and mirror session.
+	globalMetadataDirective string
+	// Hashes of local files, loaded for ‘cp --skip-identical’ of the session, nil without it.
+	globalHashCache *hashCache
+	// Targets of local files copied so far by their hardlink id, for ‘--preserve-hardlinks’ of the cp and mirror session, nil without it.
+	globalHardlinks *hardlinkTracker
+	// Throughput and request phases collected for ‘--stats’ of the cp and mirror session, nil without it.
+	globalTransferStats *transferStats
+	// Treatment of symlinks by filesystem clients, set from ‘--symlinks’ of the cp and mirror session, empty follows them.
+	globalSymlinks string
+	// Style of timestamps in listings, set by ‘ls --time-style’.
+	globalTimeStyle = timeStyleDefault
+	// SSE-C key file set via command line, only changed through setEncryptKeyFile.
+	globalEncryptKeyFile string
+	// Encryption by SSE-C keys, set via command line, only changed through setEncryptMode.
+	globalEncryptMode = encryptModeSSEC
+	// Signature audit file set via command line and its log, only changed through setAuditFile.
+	globalAuditFile string
+	globalAudit     *auditLog
+	// Suffix of the User-Agent set via command line or environment, only changed through setUserAgentSuffix.
+	globalUserAgentSuffix string
+	// Correlation id sent with every request of this command, only changed through setCorrelationID.
+	globalCorrelationID   string
+	globalNoCorrelationID bool
 	// WHEN YOU ADD NEXT GLOBAL FLAG, MAKE SURE TO ALSO UPDATE SESSION CODE AND CODE BELOW.
 )
 
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
-func setGlobals(quiet, debug, json, noColor bool) {
+func setGlobals(quiet, debug, json, noColor, dryRun, noStatCache, noDNSCache bool, connTimeout, readTimeout, idleTimeout, keepAliveTimeout time.Duration, maxConnsPerHost int) {
 	globalQuiet = quiet
 	globalDebug = debug
 	globalJSON = json
 	globalNoColor = noColor
+	globalDryRun = dryRun
+	globalNoStatCache = noStatCache
+	globalNoDNSCache = noDNSCache
+	globalConnTimeout = connTimeout
+	globalReadTimeout = readTimeout
+	globalIdleTimeout = idleTimeout
+	globalKeepAliveTimeout = keepAliveTimeout
+	globalMaxConnsPerHost = maxConnsPerHost
 
 	// Enable debug messages if requested.
 	if globalDebug == true {
 		console.DebugPrint = true
 	}
 
+	// Label operations skipped during a dry run.
+	if globalDryRun == true {
+		console.SetColor("DryRun", color.New(color.FgYellow, color.Bold))
+	}
+
+	// Start every command, and every resumed session, with an empty stat cache.
+	globalStatCache = nil
+	if globalNoStatCache == false {
+		globalStatCache = newStatCache()
+	}
+
 	// Disable colorified messages if requested.
 	if globalNoColor == true {
 		console.SetColorOff()
@@ -67,5 +159,86 @@ func setGlobalsFromContext(ctx *cli.Context) {
 	debug := ctx.Bool("debug") || ctx.GlobalBool("debug")
 	json := ctx.Bool("json") || ctx.GlobalBool("json")
 	noColor := ctx.Bool("no-color") || ctx.GlobalBool("no-color")
-	setGlobals(quiet, debug, json, noColor)
+	dryRun := ctx.Bool("dry-run") || ctx.GlobalBool("dry-run")
+	noStatCache := ctx.Bool("no-stat-cache") || ctx.GlobalBool("no-stat-cache")
+	noDNSCache := ctx.Bool("no-dns-cache") || ctx.GlobalBool("no-dns-cache")
+	connTimeout := durationFromContext(ctx, "conn-timeout")
+	readTimeout := durationFromContext(ctx, "read-timeout")
+	idleTimeout := durationFromContext(ctx, "idle-timeout")
+	keepAliveTimeout := durationFromContext(ctx, "keepalive-timeout")
+	maxConnsPerHost := ctx.Int("max-conns-per-host")
+	if maxConnsPerHost == 0 {
+		maxConnsPerHost = ctx.GlobalInt("max-conns-per-host")
+	}
+	if maxConnsPerHost < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --max-conns-per-host cannot be negative.")
+	}
+	setGlobals(quiet, debug, json, noColor, dryRun, noStatCache, noDNSCache, connTimeout, readTimeout, idleTimeout, keepAliveTimeout, maxConnsPerHost)
+
+	encryptKeyFile := ctx.String("encrypt-key-file")
+	if encryptKeyFile == "" {
+		encryptKeyFile = ctx.GlobalString("encrypt-key-file")
+	}
+	setEncryptKeyFile(encryptKeyFile)
+
+	encryptMode := ctx.String("encrypt-mode")
+	if encryptMode == "" {
+		encryptMode = ctx.GlobalString("encrypt-mode")
+	}
+	setEncryptMode(encryptMode)
+
+	auditFile := ctx.String("audit")
+	if auditFile == "" {
+		auditFile = ctx.GlobalString("audit")
+	}
+	setAuditFile(auditFile)
+
+	debugFormat := ctx.String("debug-format")
+	if debugFormat == "" {
+		debugFormat = ctx.GlobalString("debug-format")
+	}
+	setDebugFormat(debugFormat)
+
+	setOutput(outputFromContext(ctx))
+
+	filter := ctx.String("filter")
+	if filter == "" {
+		filter = ctx.GlobalString("filter")
+	}
+	setFilter(filter)
+
+	userAgentSuffix := ctx.String("user-agent-suffix")
+	if userAgentSuffix == "" {
+		userAgentSuffix = ctx.GlobalString("user-agent-suffix")
+	}
+	setUserAgentSuffix(userAgentSuffix)
+	setCorrelationID(ctx.Bool("no-correlation-id") || ctx.GlobalBool("no-correlation-id"))
+}
+
+// Formats of ‘--debug’ HTTP traces.
+const (
+	// All headers of requests and responses, with the canonical request and string to sign.
+	debugFormatVerbose = "verbose"
+	// One line per request with its status.
+	debugFormatCompact = "compact"
+)
+
+// setDebugFormat - set the format of ‘--debug’ HTTP traces, verbose if empty.
+func setDebugFormat(format string) {
+	switch format {
+	case "":
+		format = debugFormatVerbose
+	case debugFormatVerbose, debugFormatCompact:
+	default:
+		fatalIf(errInvalidArgument().Trace(format), "Option --debug-format must be ‘verbose’ or ‘compact’.")
+	}
+	globalDebugFormat = format
+}
+
+// durationFromContext prefers the command level flag over the global one.
+func durationFromContext(ctx *cli.Context, name string) time.Duration {
+	if d := ctx.Duration(name); d > 0 {
+		return d
+	}
+	return ctx.GlobalDuration(name)
 }
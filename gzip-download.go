@@ -0,0 +1,75 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// isGzipEncoded - content is stored with Content-Encoding gzip, whoever uploaded it.
+func isGzipEncoded(content *client.Content) bool {
+	encoding := strings.ToLower(strings.TrimSpace(content.ContentEncoding))
+	return encoding == "gzip" || encoding == "x-gzip"
+}
+
+// gunzipTargetURL - target of a decompressed download of source. A name taken from the
+// source loses the ‘.gz’ suffix of its encoding, a name given for the target is kept.
+func gunzipTargetURL(source *client.Content, targetURL client.URL) client.URL {
+	name := urlBase(targetURL)
+	if name != downloadName(source) || !strings.HasSuffix(name, ".gz") || name == ".gz" {
+		return targetURL
+	}
+	targetURL.Path = strings.TrimSuffix(targetURL.Path, ".gz")
+	return targetURL
+}
+
+// gunzipReader - decompressed data of a gzip stream, its checksum and length are
+// verified at the end. It seeks only to its start before anything is read.
+type gunzipReader struct {
+	source io.Reader
+	reader *gzip.Reader
+}
+
+func newGunzipReader(source io.Reader) *gunzipReader {
+	return &gunzipReader{source: source}
+}
+
+func (r *gunzipReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		reader, e := gzip.NewReader(r.source)
+		if e != nil {
+			if e == io.EOF {
+				e = io.ErrUnexpectedEOF
+			}
+			return 0, e
+		}
+		r.reader = reader
+	}
+	return r.reader.Read(p)
+}
+
+func (r *gunzipReader) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == 0 && r.reader == nil {
+		return 0, nil
+	}
+	return 0, errors.New("decompressed downloads can not be seeked")
+}
@@ -0,0 +1,122 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+// gzipHandler serves an object stored with Content-Encoding gzip. Content-Length is
+// set as S3 does, ServeContent leaves it out of encoded responses.
+type gzipHandler struct {
+	rangeHandler
+}
+
+func (h *gzipHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Set("Content-Length", strconv.Itoa(len(h.data)))
+	h.rangeHandler.ServeHTTP(w, r)
+}
+
+func (s *TestSuite) TestCopyGzipEncoded(c *C) {
+	defer useTempMcConfig(c)()
+	data := bytes.Repeat([]byte("date,amount\n2015-10-21,42\n"), 1024)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(data)
+	c.Assert(writer.Close(), IsNil)
+
+	server := httptest.NewServer(&gzipHandler{rangeHandler{data: compressed.Bytes(), etag: "etag"}})
+	defer server.Close()
+	c.Assert(setAlias("gz", hostConfigV7{URL: server.URL, API: "S3v4"}), IsNil)
+
+	root, e := ioutil.TempDir(os.TempDir(), "cp-gzip-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	copyGzip := func(target string, raw bool) {
+		session := newTestCopySession(c, []string{"gz/bucket/report.csv.gz"}, target, 1, false)
+		defer session.Delete()
+		session.Header.CommandBoolFlags["raw"] = raw
+		c.Assert(doCopySession(session).exitCode(), Equals, 0)
+	}
+
+	// Decompressed into a folder, the name loses the suffix of its encoding.
+	decompressed := filepath.Join(root, "decompressed") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(decompressed, 0700), IsNil)
+	copyGzip(decompressed, false)
+	got, e := ioutil.ReadFile(filepath.Join(decompressed, "report.csv"))
+	c.Assert(e, IsNil)
+	c.Assert(got, DeepEquals, data)
+	_, e = os.Stat(filepath.Join(decompressed, "report.csv.gz"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+
+	// A name given for the target is kept.
+	named := filepath.Join(root, "named.csv.gz")
+	copyGzip(named, false)
+	got, e = ioutil.ReadFile(named)
+	c.Assert(e, IsNil)
+	c.Assert(got, DeepEquals, data)
+
+	// With ‘--raw’ the object is downloaded as stored.
+	raw := filepath.Join(root, "raw") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(raw, 0700), IsNil)
+	copyGzip(raw, true)
+	got, e = ioutil.ReadFile(filepath.Join(raw, "report.csv.gz"))
+	c.Assert(e, IsNil)
+	c.Assert(got, DeepEquals, compressed.Bytes())
+}
+
+func (s *TestSuite) TestGunzipReader(c *C) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("report"))
+	c.Assert(writer.Close(), IsNil)
+
+	reader := newGunzipReader(bytes.NewReader(compressed.Bytes()))
+	_, e := reader.Seek(0, 0)
+	c.Assert(e, IsNil)
+	got, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(got), Equals, "report")
+	_, e = reader.Seek(0, 0)
+	c.Assert(e, Not(IsNil))
+
+	// Truncated streams fail their checksum.
+	_, e = ioutil.ReadAll(newGunzipReader(bytes.NewReader(compressed.Bytes()[:compressed.Len()-4])))
+	c.Assert(e, Not(IsNil))
+	_, e = ioutil.ReadAll(newGunzipReader(bytes.NewReader(nil)))
+	c.Assert(e, Not(IsNil))
+
+	c.Assert(isGzipEncoded(&client.Content{ContentEncoding: "GZIP"}), Equals, true)
+	c.Assert(isGzipEncoded(&client.Content{ContentEncoding: "br"}), Equals, false)
+	c.Assert(isGzipEncoded(&client.Content{}), Equals, false)
+}
@@ -0,0 +1,137 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// checkPreserveHardlinksSyntax - hard links are told apart and created only on platforms supporting them.
+func checkPreserveHardlinksSyntax(ctx *cli.Context) {
+	if ctx.Bool("preserve-hardlinks") && !fs.HardlinksSupported {
+		warnHardlinks(errHardlinksUnsupported(), "Files are copied as they are.")
+	}
+}
+
+// warnHardlinks - warn hard links are not preserved, for the reason of err.
+func warnHardlinks(err *probe.Error, msg string) {
+	if globalJSON {
+		printErrorJSON(err, msg, "warning", exitFailure)
+		return
+	}
+	console.Errorln(err.ToGoError().Error() + " " + msg)
+}
+
+// hardlinkTracker - targets of local files copied with ‘--preserve-hardlinks’ by their hardlink id,
+// later files of an id are linked to the target of the first instead of copied.
+type hardlinkTracker struct {
+	mutex    sync.Mutex
+	targets  map[string]*hardlinkTarget
+	warnOnce sync.Once
+}
+
+// hardlinkTarget - target of the first file of a hardlink id, done is closed once it is copied.
+type hardlinkTarget struct {
+	path string
+	done chan struct{}
+	err  *probe.Error
+}
+
+// copied - report the copy of the first file is done, files linked to it wait for it.
+func (h *hardlinkTarget) copied(err *probe.Error) {
+	h.err = err
+	close(h.done)
+}
+
+// isFailed - the copy of the first file is done and failed.
+func (h *hardlinkTarget) isFailed() bool {
+	select {
+	case <-h.done:
+		return h.err != nil
+	default:
+		return false
+	}
+}
+
+func newHardlinkTracker() *hardlinkTracker {
+	return &hardlinkTracker{targets: make(map[string]*hardlinkTarget)}
+}
+
+// link - link targetPath to the target of the first file of id, once that is copied. The first file
+// of an id is copied instead, it reports the outcome with copied. Files of an id whose first failed
+// take its place, those which can not be linked are copied after a warning. Nil-safe.
+func (t *hardlinkTracker) link(id, targetPath string) (linked bool, copied func(*probe.Error)) {
+	if t == nil || id == "" {
+		return false, func(*probe.Error) {}
+	}
+	for {
+		t.mutex.Lock()
+		first, ok := t.targets[id]
+		if !ok || first.isFailed() {
+			first = &hardlinkTarget{path: targetPath, done: make(chan struct{})}
+			t.targets[id] = first
+			t.mutex.Unlock()
+			return false, first.copied
+		}
+		t.mutex.Unlock()
+		<-first.done
+		if first.err == nil {
+			if err := linkFile(first.path, targetPath); err != nil {
+				t.warnOnce.Do(func() { warnHardlinks(err.Trace(targetPath), "Files are copied instead.") })
+				return false, func(*probe.Error) {}
+			}
+			return true, func(*probe.Error) {}
+		}
+	}
+}
+
+// linkFile - make target a hard link of existing, replacing what is there.
+func linkFile(existing, target string) *probe.Error {
+	if e := os.MkdirAll(filepath.Dir(target), 0777); e != nil {
+		return probe.NewError(e)
+	}
+	tmpPath := target + ".link-" + newRandomID(8)
+	if e := os.Link(existing, tmpPath); e != nil {
+		return probe.NewError(e)
+	}
+	if e := os.Rename(tmpPath, target); e != nil {
+		os.Remove(tmpPath)
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// getSessionHardlinks - tracker of hardlinks for ‘--preserve-hardlinks’ of session, nil without it.
+func getSessionHardlinks(session *sessionV6) *hardlinkTracker {
+	if !session.Header.CommandBoolFlags["preserve-hardlinks"] || !fs.HardlinksSupported {
+		return nil
+	}
+	return newHardlinkTracker()
+}
+
+// isHardlinkCopy - source and target of a copy with ‘--preserve-hardlinks’ are local files.
+func isHardlinkCopy(source, target client.URL) bool {
+	return globalHardlinks != nil && source.Type == client.Filesystem && target.Type == client.Filesystem
+}
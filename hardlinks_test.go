@@ -0,0 +1,90 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/client/fs"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestPreserveHardlinks(c *C) {
+	if !fs.HardlinksSupported {
+		c.Skip("hard links are not told apart on this platform")
+	}
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-hardlinks-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	c.Assert(os.MkdirAll(filepath.Join(source, "nested"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "first"), []byte("shared"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "other"), []byte("other"), 0600), IsNil)
+	for _, link := range []string{"second", filepath.Join("nested", "third")} {
+		c.Assert(os.Link(filepath.Join(source, "first"), filepath.Join(source, link)), IsNil)
+	}
+	source += string(filepath.Separator)
+
+	// Files of a target sharing the data of first, and whether other does.
+	assertLinks := func(target string, linked bool) {
+		first, e := os.Stat(filepath.Join(target, "first"))
+		c.Assert(e, IsNil)
+		for _, name := range []string{"second", filepath.Join("nested", "third")} {
+			st, e := os.Stat(filepath.Join(target, name))
+			c.Assert(e, IsNil)
+			c.Assert(os.SameFile(first, st), Equals, linked, Commentf("%s", name))
+			data, e := ioutil.ReadFile(filepath.Join(target, name))
+			c.Assert(e, IsNil)
+			c.Assert(string(data), Equals, "shared")
+		}
+		other, e := os.Stat(filepath.Join(target, "other"))
+		c.Assert(e, IsNil)
+		c.Assert(os.SameFile(first, other), Equals, false)
+	}
+
+	// Copied in parallel, the links wait for the copy of the first file of their inode.
+	target := filepath.Join(root, "cp")
+	session := newTestCopySession(c, []string{source}, target, 4, true)
+	session.Header.CommandBoolFlags["recursive"] = true
+	session.Header.CommandBoolFlags["preserve-hardlinks"] = true
+	c.Assert(doCopySession(session).exitCode(), Equals, 0)
+	session.Delete()
+	assertLinks(target, true)
+
+	target = filepath.Join(root, "mirror")
+	session = newSessionV6()
+	session.Header.CommandType = "mirror"
+	session.Header.CommandArgs = []string{source, target}
+	session.Header.CommandBoolFlags["preserve-hardlinks"] = true
+	c.Assert(doMirrorSession(session).exitCode(), Equals, 0)
+	session.Delete()
+	assertLinks(target, true)
+
+	// Without ‘--preserve-hardlinks’ every file is copied.
+	target = filepath.Join(root, "copies")
+	session = newTestCopySession(c, []string{source}, target, 4, true)
+	session.Header.CommandBoolFlags["recursive"] = true
+	c.Assert(doCopySession(session).exitCode(), Equals, 0)
+	session.Delete()
+	assertLinks(target, false)
+}
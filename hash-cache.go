@@ -0,0 +1,161 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/xxhash"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// hashCacheEntry - xxhash of a file when it had Size and ModTime, in nanoseconds.
+type hashCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Hash    string `json:"xxhash"`
+}
+
+// hashCache - xxhash of local files by absolute path for ‘cp --skip-identical’, kept in the config
+// folder so that files unchanged since an earlier run are not hashed again. An entry is used only
+// while the size and modification time of its file are unchanged. Safe for concurrent use.
+//
+// xxhash detects changed files, it is not a cryptographic hash. A file changed deliberately to keep
+// its hash is not detected, never use it to verify files of someone not trusted.
+type hashCache struct {
+	mutex    sync.Mutex
+	filename string
+	entries  map[string]hashCacheEntry
+	dirty    bool
+}
+
+// isLocalCopy - source and target are both local files, which ‘--skip-identical’ compares.
+func isLocalCopy(sourceClnt, targetClnt client.Client) bool {
+	return sourceClnt.GetURL().Type == client.Filesystem && targetClnt.GetURL().Type == client.Filesystem
+}
+
+// getHashCacheFile - file of the hash cache in the config folder.
+func getHashCacheFile() string {
+	return filepath.Join(mustGetMcConfigDir(), "xxhash-cache.json")
+}
+
+// loadHashCache - hash cache of filename, empty if there is none yet or it is unreadable.
+func loadHashCache(filename string) *hashCache {
+	hc := &hashCache{filename: filename, entries: make(map[string]hashCacheEntry)}
+	if data, e := ioutil.ReadFile(filename); e == nil {
+		json.Unmarshal(data, &hc.entries)
+	}
+	return hc
+}
+
+// getSessionHashCache - hash cache of ‘--skip-identical’ of the session, nil without it.
+func getSessionHashCache(session *sessionV6) *hashCache {
+	if !session.Header.CommandBoolFlags["skip-identical"] {
+		return nil
+	}
+	return loadHashCache(getHashCacheFile())
+}
+
+// sum - xxhash of the file at path, taken from the cache unless the file changed.
+func (hc *hashCache) sum(path string) (string, *probe.Error) {
+	path, e := filepath.Abs(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	st, e := os.Stat(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	hc.mutex.Lock()
+	entry, ok := hc.entries[path]
+	hc.mutex.Unlock()
+	if ok && entry.Size == st.Size() && entry.ModTime == st.ModTime().UnixNano() {
+		return entry.Hash, nil
+	}
+
+	file, e := os.Open(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	defer file.Close()
+	hasher := xxhash.New()
+	if _, e = io.Copy(hasher, file); e != nil {
+		return "", probe.NewError(e)
+	}
+	entry = hashCacheEntry{Size: st.Size(), ModTime: st.ModTime().UnixNano(), Hash: hex.EncodeToString(hasher.Sum(nil))}
+	hc.mutex.Lock()
+	hc.entries[path] = entry
+	hc.dirty = true
+	hc.mutex.Unlock()
+	return entry.Hash, nil
+}
+
+// identical - the file at target has the contents of the file at source, false if there is no
+// file at target. Files of different sizes are never hashed.
+func (hc *hashCache) identical(source, target string) (bool, *probe.Error) {
+	sourceSt, e := os.Stat(source)
+	if e != nil {
+		return false, probe.NewError(e)
+	}
+	targetSt, e := os.Stat(target)
+	if e != nil || !targetSt.Mode().IsRegular() || targetSt.Size() != sourceSt.Size() {
+		return false, nil
+	}
+	sourceSum, err := hc.sum(source)
+	if err != nil {
+		return false, err.Trace(source)
+	}
+	targetSum, err := hc.sum(target)
+	if err != nil {
+		return false, err.Trace(target)
+	}
+	return sourceSum == targetSum, nil
+}
+
+// save - save the hashes taken, along with those other invocations saved meanwhile.
+func (hc *hashCache) save() *probe.Error {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	if !hc.dirty {
+		return nil
+	}
+	unlock, err := lockFile(hc.filename)
+	if err != nil {
+		return err.Trace(hc.filename)
+	}
+	defer unlock()
+	saved := loadHashCache(hc.filename)
+	for path, entry := range hc.entries {
+		saved.entries[path] = entry
+	}
+	data, e := json.Marshal(saved.entries)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if err = writeFileAtomic(hc.filename, data); err != nil {
+		return err.Trace(hc.filename)
+	}
+	hc.dirty = false
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopySkipIdentical(c *C) {
+	defer useTempMcConfig(c)()
+	root, e := ioutil.TempDir(os.TempDir(), "cp-skip-identical-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	sources := []string{filepath.Join(root, "a"), filepath.Join(root, "b")}
+	c.Assert(ioutil.WriteFile(sources[0], []byte("contents of a"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(sources[1], []byte("contents of b"), 0600), IsNil)
+
+	copyIdentical := func() summaryMessage {
+		session := newTestCopySession(c, sources, target, 2, false)
+		defer session.Delete()
+		session.Header.CommandBoolFlags["skip-identical"] = true
+		session.Header.CommandBoolFlags["overwrite"] = true
+		summary := doCopySession(session)
+		c.Assert(summary.exitCode(), Equals, 0)
+		return summary.Message(session)
+	}
+
+	// Missing targets are copied, identical ones skipped.
+	msg := copyIdentical()
+	c.Assert(msg.Transferred, Equals, 2)
+	msg = copyIdentical()
+	c.Assert(msg.Transferred, Equals, 0)
+	c.Assert(msg.Skipped, Equals, 2)
+
+	// A file changed to the same size is copied again.
+	c.Assert(ioutil.WriteFile(sources[0], []byte("contents of A"), 0600), IsNil)
+	later := time.Now().Add(time.Minute)
+	c.Assert(os.Chtimes(sources[0], later, later), IsNil)
+	msg = copyIdentical()
+	c.Assert(msg.Transferred, Equals, 1)
+	c.Assert(msg.Skipped, Equals, 1)
+	data, e := ioutil.ReadFile(filepath.Join(target, "a"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "contents of A")
+
+	// Hashes of files of unchanged size and modification time are taken from the cache.
+	hc := loadHashCache(getHashCacheFile())
+	c.Assert(len(hc.entries), Equals, 4)
+	targetB := filepath.Join(target, "b")
+	st, e := os.Stat(targetB)
+	c.Assert(e, IsNil)
+	c.Assert(ioutil.WriteFile(targetB, []byte("contents of B"), 0600), IsNil)
+	c.Assert(os.Chtimes(targetB, st.ModTime(), st.ModTime()), IsNil)
+	msg = copyIdentical()
+	c.Assert(msg.Skipped, Equals, 2)
+	data, e = ioutil.ReadFile(targetB)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "contents of B")
+}
@@ -0,0 +1,213 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// incompleteMessage container for the incomplete uploads of an object.
+type incompleteMessage struct {
+	Status    string    `json:"status"`
+	Key       string    `json:"key"`
+	Initiated time.Time `json:"initiated"` // oldest upload of the object.
+	Uploads   int       `json:"uploads"`
+	Parts     int       `json:"parts"`
+	Size      int64     `json:"size"`
+}
+
+// String colorized incomplete uploads message.
+func (i incompleteMessage) String() string {
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", formatTime(i.Initiated.Local())))
+	message = message + console.Colorize("Size", fmt.Sprintf("%6s ", humanize.IBytes(uint64(i.Size))))
+	message = message + console.Colorize("File", i.Key)
+	return message + fmt.Sprintf(" (%d upload(s), %d part(s))", i.Uploads, i.Parts)
+}
+
+// JSON jsonified incomplete uploads message.
+func (i incompleteMessage) JSON() string {
+	i.Status = "success"
+	incompleteMessageBytes, e := json.Marshal(i)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(incompleteMessageBytes)
+}
+
+// incompleteTotalMessage container for the totals of all incomplete uploads listed.
+type incompleteTotalMessage struct {
+	Status  string `json:"status"`
+	Objects int    `json:"objects"`
+	Uploads int    `json:"uploads"`
+	Parts   int    `json:"parts"`
+	Size    int64  `json:"size"`
+}
+
+// String colorized incomplete uploads total message.
+func (i incompleteTotalMessage) String() string {
+	return console.Colorize("Size", fmt.Sprintf("Total: %s in %d part(s) of %d upload(s) to %d object(s).",
+		humanize.IBytes(uint64(i.Size)), i.Parts, i.Uploads, i.Objects))
+}
+
+// JSON jsonified incomplete uploads total message.
+func (i incompleteTotalMessage) JSON() string {
+	i.Status = "success"
+	totalMessageBytes, e := json.Marshal(i)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(totalMessageBytes)
+}
+
+// isOlderThan - true if the upload was initiated longer than olderThan ago, always true if olderThan is zero.
+func isOlderThan(content *client.Content, olderThan time.Duration) bool {
+	return olderThan <= 0 || time.Since(content.Time) > olderThan
+}
+
+// staleUploads - list incomplete uploads of clnt initiated longer than olderThan ago, one entry per
+// upload with Size set to the bytes of its parts, until doneCh is closed. Parts of each upload are
+// listed with a client from newClient. Listing errors are forwarded.
+func staleUploads(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error),
+	olderThan time.Duration, doneCh <-chan struct{}) <-chan *client.Content {
+	staleCh := make(chan *client.Content)
+	go func() {
+		defer close(staleCh)
+		for content := range clnt.List(true, true, doneCh) {
+			if content.Err == nil && (content.Type.IsDir() || !isOlderThan(content, olderThan)) {
+				continue
+			}
+			if content.Err == nil && content.UploadID != "" {
+				partsClnt, err := newClient(content.URL.String())
+				if err == nil {
+					var size int64
+					if _, size, err = listUploadParts(partsClnt, content.UploadID, doneCh); err == nil {
+						content.Size = size
+					}
+				}
+				if err != nil {
+					// Size of the upload as listed is kept.
+					errorIf(err.Trace(content.URL.String()), "Unable to list parts of ‘"+content.URL.String()+"’.")
+				}
+			}
+			select {
+			case staleCh <- content:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	return staleCh
+}
+
+// listUploadParts - count the parts uploaded so far to an incomplete upload and the bytes they occupy.
+func listUploadParts(clnt client.Client, uploadID string, doneCh <-chan struct{}) (parts int, size int64, err *probe.Error) {
+	for part := range clnt.ListParts(uploadID, doneCh) {
+		if part.Err != nil {
+			return 0, 0, part.Err.Trace(uploadID)
+		}
+		parts++
+		size += part.Size
+	}
+	return parts, size, nil
+}
+
+// aggregateIncomplete - list incomplete uploads of clnt aggregated per object, skipping
+// uploads initiated within olderThan. Parts of each upload are listed with a client
+// from newClient, objects are listed until doneCh is closed.
+func aggregateIncomplete(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error),
+	isRecursive bool, olderThan time.Duration, doneCh <-chan struct{}) <-chan incompleteMessage {
+	prefixPath := listPrefix(clnt)
+	msgCh := make(chan incompleteMessage)
+	go func() {
+		defer close(msgCh)
+		var current *incompleteMessage
+		send := func() bool {
+			select {
+			case msgCh <- *current:
+				return true
+			case <-doneCh:
+				return false
+			}
+		}
+		for content := range clnt.List(isRecursive, true, doneCh) {
+			if content.Err != nil {
+				errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list incomplete uploads.")
+				continue
+			}
+			if content.Type.IsDir() || !isOlderThan(content, olderThan) {
+				continue
+			}
+			key := strings.TrimPrefix(content.URL.Path, prefixPath)
+			if current != nil && current.Key != key {
+				if !send() {
+					return
+				}
+				current = nil
+			}
+			if current == nil {
+				current = &incompleteMessage{Key: key, Initiated: content.Time}
+			}
+			current.Uploads++
+			if content.Time.Before(current.Initiated) {
+				current.Initiated = content.Time
+			}
+
+			partsClnt, err := newClient(content.URL.String())
+			if err == nil {
+				var parts int
+				var size int64
+				if parts, size, err = listUploadParts(partsClnt, content.UploadID, doneCh); err == nil {
+					current.Parts += parts
+					current.Size += size
+					continue
+				}
+			}
+			// Size of the upload is known from the listing, only its parts remain uncounted.
+			errorIf(err.Trace(content.URL.String()), "Unable to list parts of ‘"+key+"’.")
+			current.Size += content.Size
+		}
+		if current != nil {
+			send()
+		}
+	}()
+	return msgCh
+}
+
+// doListIncomplete - print incomplete uploads aggregated per object, followed by their totals.
+func doListIncomplete(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error),
+	isRecursive bool, olderThan time.Duration, limit int) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	total := incompleteTotalMessage{}
+	for msg := range aggregateIncomplete(clnt, newClient, isRecursive, olderThan, doneCh) {
+		printMsg(msg)
+		total.Objects++
+		total.Uploads += msg.Uploads
+		total.Parts += msg.Parts
+		total.Size += msg.Size
+		if limit > 0 && total.Objects >= limit {
+			break
+		}
+	}
+	printMsg(total)
+}
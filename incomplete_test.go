@@ -0,0 +1,165 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// incompleteUpload - an incomplete upload served by incompleteHandler, with the sizes of its parts.
+type incompleteUpload struct {
+	key       string
+	uploadID  string
+	initiated time.Time
+	parts     []int
+}
+
+// incompleteHandler is an http.Handler that serves a synthetic listing of incomplete uploads in /bucket.
+type incompleteHandler struct {
+	uploads []incompleteUpload
+	aborted map[string]bool // key?uploadId of every upload aborted.
+}
+
+func (h incompleteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	_, isUploads := query["uploads"]
+	var response bytes.Buffer
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/bucket" && isUploads:
+		response.WriteString("<ListMultipartUploadsResult><Bucket>bucket</Bucket>")
+		for _, upload := range h.uploads {
+			response.WriteString("<Upload><Key>" + upload.key + "</Key><UploadId>" + upload.uploadID +
+				"</UploadId><Initiated>" + upload.initiated.UTC().Format(time.RFC3339) + "</Initiated></Upload>")
+		}
+		response.WriteString("<IsTruncated>false</IsTruncated></ListMultipartUploadsResult>")
+	case r.Method == "GET" && query.Get("uploadId") != "":
+		response.WriteString("<ListPartsResult><Bucket>bucket</Bucket>")
+		for _, upload := range h.uploads {
+			if r.URL.Path != "/bucket/"+upload.key || query.Get("uploadId") != upload.uploadID {
+				continue
+			}
+			for i, size := range upload.parts {
+				response.WriteString("<Part><PartNumber>" + strconv.Itoa(i+1) + "</PartNumber><Size>" + strconv.Itoa(size) + "</Size></Part>")
+			}
+		}
+		response.WriteString("<IsTruncated>false</IsTruncated></ListPartsResult>")
+	case r.Method == "DELETE" && query.Get("uploadId") != "" && h.aborted != nil:
+		h.aborted[strings.TrimPrefix(r.URL.Path, "/bucket/")+"?"+query.Get("uploadId")] = true
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Write(response.Bytes())
+}
+
+// newIncompleteTestClient - s3 client for urlStr, without an alias.
+func newIncompleteTestClient(urlStr string) (client.Client, *probe.Error) {
+	conf := new(client.Config)
+	conf.HostURL = urlStr
+	return s3.New(conf)
+}
+
+func (s *TestSuite) TestAggregateIncomplete(c *C) {
+	now := time.Now()
+	handler := incompleteHandler{
+		uploads: []incompleteUpload{
+			{key: "backup/a.tar", uploadID: "1", initiated: now.Add(-72 * time.Hour), parts: []int{5, 5}},
+			{key: "backup/a.tar", uploadID: "2", initiated: now.Add(-48 * time.Hour), parts: []int{7}},
+			{key: "backup/b.tar", uploadID: "3", initiated: now.Add(-time.Hour), parts: []int{3}},
+			{key: "c.bin", uploadID: "4", initiated: now.Add(-96 * time.Hour), parts: []int{1, 2, 3}},
+		},
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	clnt, err := newIncompleteTestClient(server.URL + "/bucket/")
+	c.Assert(err, IsNil)
+
+	var msgs []incompleteMessage
+	for msg := range aggregateIncomplete(clnt, newIncompleteTestClient, true, 0, nil) {
+		msgs = append(msgs, msg)
+	}
+	c.Assert(len(msgs), Equals, 3)
+	c.Assert(msgs[0].Key, Equals, "backup/a.tar")
+	c.Assert(msgs[0].Uploads, Equals, 2)
+	c.Assert(msgs[0].Parts, Equals, 3)
+	c.Assert(msgs[0].Size, Equals, int64(17))
+	c.Assert(msgs[0].Initiated.Unix(), Equals, now.Add(-72*time.Hour).Unix())
+	c.Assert(msgs[1].Key, Equals, "backup/b.tar")
+	c.Assert(msgs[1].Parts, Equals, 1)
+	c.Assert(msgs[2].Key, Equals, "c.bin")
+	c.Assert(msgs[2].Parts, Equals, 3)
+	c.Assert(msgs[2].Size, Equals, int64(6))
+
+	// Recent uploads are left out.
+	msgs = nil
+	for msg := range aggregateIncomplete(clnt, newIncompleteTestClient, true, 24*time.Hour, nil) {
+		msgs = append(msgs, msg)
+	}
+	c.Assert(len(msgs), Equals, 2)
+	c.Assert(msgs[0].Key, Equals, "backup/a.tar")
+	c.Assert(msgs[1].Key, Equals, "c.bin")
+}
+
+func (s *TestSuite) TestRmStaleUploads(c *C) {
+	now := time.Now()
+	handler := incompleteHandler{
+		uploads: []incompleteUpload{
+			{key: "backup/a.tar", uploadID: "1", initiated: now.Add(-72 * time.Hour), parts: []int{5, 5}},
+			{key: "backup/a.tar", uploadID: "2", initiated: now.Add(-time.Hour), parts: []int{7}},
+			{key: "backup/b.tar", uploadID: "3", initiated: now.Add(-2 * time.Hour), parts: []int{3}},
+			{key: "c.bin", uploadID: "4", initiated: now.Add(-96 * time.Hour), parts: []int{1, 2, 3}},
+		},
+		aborted: make(map[string]bool),
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	clnt, err := newIncompleteTestClient(server.URL + "/bucket/")
+	c.Assert(err, IsNil)
+
+	isJSON, isDryRun := globalJSON, globalDryRun
+	defer func() { globalJSON, globalDryRun = isJSON, isDryRun }()
+	globalJSON = true
+
+	// Dry run previews the bytes reclaimed, nothing is aborted.
+	globalDryRun = true
+	total := rmStaleUploads(newDryRunClient("", clnt), newIncompleteTestClient, "", 24*time.Hour)
+	c.Assert(total.Uploads, Equals, 2)
+	c.Assert(total.Size, Equals, int64(16))
+	c.Assert(total.DryRun, Equals, true)
+	c.Assert(len(handler.aborted), Equals, 0)
+
+	// Only old uploads are aborted, recent uploads to the same object are left in progress.
+	globalDryRun = false
+	total = rmStaleUploads(clnt, newIncompleteTestClient, "", 24*time.Hour)
+	c.Assert(total.Uploads, Equals, 2)
+	c.Assert(total.Size, Equals, int64(16))
+	c.Assert(handler.aborted, DeepEquals, map[string]bool{"backup/a.tar?1": true, "c.bin?4": true})
+}
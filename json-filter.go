@@ -0,0 +1,83 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// JMESPath expression ‘--filter’ applies to every JSON message printed, nil if not set.
+var globalFilter *jmespath.JMESPath
+
+// setFilter - compile the ‘--filter’ expression, only JSON output and the formats
+// rendered from it can be filtered.
+func setFilter(expr string) {
+	globalFilter = nil
+	if expr == "" {
+		return
+	}
+	if !globalJSON && globalOutputRenderer == nil {
+		fatalIf(errInvalidArgument().Trace(expr), "Option --filter applies to JSON output, use it along with --json.")
+	}
+	if _, isText := globalOutputRenderer.(textRenderer); isText {
+		fatalIf(errInvalidArgument().Trace(expr), "Option --filter applies to JSON output, use it along with --json.")
+	}
+	filter, err := parseFilter(expr)
+	fatalIf(err.Trace(expr), "Invalid filter expression ‘"+expr+"’.")
+	globalFilter = filter
+}
+
+// parseFilter - compile a JMESPath expression, syntax errors point at where it failed.
+func parseFilter(expr string) (*jmespath.JMESPath, *probe.Error) {
+	filter, e := jmespath.Compile(expr)
+	if e != nil {
+		if syntaxErr, ok := e.(jmespath.SyntaxError); ok {
+			return nil, probe.NewError(errors.New(syntaxErr.Error() + "\n" + syntaxErr.HighlightLocation()))
+		}
+		return nil, probe.NewError(e)
+	}
+	return filter, nil
+}
+
+// filterJSON - applies filter to the JSON message jsonStr, printed as is if the expression is
+// true and not at all if it is false or null. Any other result is printed in its place, so
+// that an expression can select messages as well as pick fields of them.
+func filterJSON(filter *jmespath.JMESPath, jsonStr string) (string, bool, *probe.Error) {
+	var data interface{}
+	if e := json.Unmarshal([]byte(jsonStr), &data); e != nil {
+		return "", false, probe.NewError(e)
+	}
+	result, e := filter.Search(data)
+	if e != nil {
+		return "", false, probe.NewError(e)
+	}
+	switch result {
+	case nil, false:
+		return "", false, nil
+	case true:
+		return jsonStr, true, nil
+	}
+	resultBytes, e := json.Marshal(result)
+	if e != nil {
+		return "", false, probe.NewError(e)
+	}
+	return string(resultBytes), true, nil
+}
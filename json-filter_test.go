@@ -0,0 +1,72 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestFilterJSON(c *C) {
+	modTime := time.Date(2015, 12, 29, 0, 0, 0, 0, time.UTC)
+	listing := []string{
+		contentMessage{Status: "success", Filetype: "file", Time: modTime, Size: 512, Key: "a.txt", StorageClass: "STANDARD"}.JSON(),
+		contentMessage{Status: "success", Filetype: "file", Time: modTime, Size: 4096, Key: "b.tar", StorageClass: "GLACIER"}.JSON(),
+		contentMessage{Status: "success", Filetype: "folder", Time: modTime, Key: "c/"}.JSON(),
+	}
+	filterListing := func(expr string) []string {
+		filter, err := parseFilter(expr)
+		c.Assert(err, IsNil)
+		var results []string
+		for _, record := range listing {
+			result, ok, err := filterJSON(filter, record)
+			c.Assert(err, IsNil)
+			if ok {
+				results = append(results, result)
+			}
+		}
+		return results
+	}
+
+	// True and false select records.
+	c.Assert(filterListing("size > `1024`"), DeepEquals, []string{listing[1]})
+	c.Assert(filterListing("type == 'folder'"), DeepEquals, []string{listing[2]})
+	// Null drops records, anything else is printed in their place.
+	c.Assert(filterListing("storageClass"), DeepEquals, []string{`"STANDARD"`, `"GLACIER"`})
+	c.Assert(filterListing("{key: key, size: size}"), DeepEquals, []string{
+		`{"key":"a.txt","size":512}`, `{"key":"b.tar","size":4096}`, `{"key":"c/","size":0}`,
+	})
+	c.Assert(filterListing("starts_with(key, 'b') && key"), DeepEquals, []string{`"b.tar"`})
+
+	// Aggregate messages are filtered as a whole.
+	filter, err := parseFilter("buckets[?region == 'eu-west-1'].name")
+	c.Assert(err, IsNil)
+	result, ok, err := filterJSON(filter, `{"status":"success","buckets":[{"name":"a","region":"us-east-1"},{"name":"b","region":"eu-west-1"}]}`)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(result, Equals, `["b"]`)
+}
+
+func (s *TestSuite) TestFilterInvalid(c *C) {
+	_, err := parseFilter("size >")
+	c.Assert(err, NotNil)
+	filter, err := parseFilter("abs(key)")
+	c.Assert(err, IsNil)
+	_, _, err = filterJSON(filter, `{"key":"a.txt"}`)
+	c.Assert(err, NotNil)
+}
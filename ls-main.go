@@ -17,12 +17,16 @@
 package main
 
 import (
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
 )
 
 // ls specific flags.
@@ -38,7 +42,60 @@ var (
 		},
 		cli.BoolFlag{
 			Name:  "incomplete, I",
-			Usage: "Remove incomplete uploads.",
+			Usage: "List incomplete uploads.",
+		},
+		cli.BoolFlag{
+			Name:  "summarize",
+			Usage: "Summarize incomplete uploads per object, with the parts and bytes they occupy.",
+		},
+		cli.StringFlag{
+			Name:  "older-than",
+			Usage: "List only incomplete uploads initiated longer ago than this duration, such as 168h.",
+		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Stop listing each target after N entries, 0 lists all entries.",
+		},
+		cli.StringFlag{
+			Name:  "start-after",
+			Usage: "List only objects whose key comes after this key in lexical order, such as a continuation token of an earlier listing.",
+		},
+		cli.IntFlag{
+			Name:  "max-keys",
+			Usage: "List only one page of up to N objects, 1000 at most. JSON output ends with the continuation token of the next page.",
+		},
+		cli.BoolFlag{
+			Name:  "versions",
+			Usage: "List all versions of objects.",
+		},
+		cli.BoolFlag{
+			Name:  "include-delete-markers",
+			Usage: "List delete markers along with --versions.",
+		},
+		cli.BoolFlag{
+			Name:  "metadata",
+			Usage: "Show storage class, ETag and owner of objects, the user and group of local files.",
+		},
+		cli.BoolFlag{
+			Name:  "full",
+			Usage: "Show content type and expiry along with --metadata, stat'ing every object.",
+		},
+		cli.BoolFlag{
+			Name:  "with-region",
+			Usage: "Show the region of every bucket when listing the buckets of a host.",
+		},
+		cli.BoolFlag{
+			Name:  "full-path",
+			Usage: "Show listed paths in full, with alias or host, instead of relative to the listed folder.",
+		},
+		cli.StringFlag{
+			Name:  "content-type",
+			Usage: "List only objects whose content type matches a pattern, such as ‘image/*’. Objects on object storage are stat'ed for it.",
+		},
+		cli.StringFlag{
+			Name:  "time-style",
+			Value: timeStyleDefault,
+			Usage: "Style of timestamps: default, iso, full, relative or unix. JSON output is always RFC3339.",
 		},
 	}
 )
@@ -76,6 +133,41 @@ EXAMPLES:
 
    6. List incomplete (previously failed) uploads of objects on Amazon S3. 
       $ mc {{.Name}} --incomplete s3/mybucket
+
+   7. List only the first 10 objects of mybucket on Amazon S3, objects are listed in lexical order of their keys.
+      $ mc {{.Name}} --recursive --limit 10 s3/mybucket
+
+   8. List all versions of objects in a versioned bucket on Amazon S3, newest version first.
+      $ mc {{.Name}} --versions s3/mybucket/backups/
+
+   9. Summarize incomplete uploads older than a week per object, showing the storage they occupy.
+      $ mc {{.Name}} --recursive --incomplete --summarize --older-than 168h s3/mybucket
+
+   10. List objects of mybucket on Amazon S3 with their storage class, ETag, owner and content type.
+      $ mc {{.Name}} --metadata --full s3/mybucket/photos/
+
+   11. List objects of mybucket on Amazon S3 with the time they were last modified relative to now.
+      $ mc {{.Name}} --time-style relative s3/mybucket/
+
+   12. List buckets on Amazon S3 cloud storage with the region each bucket is located in.
+      $ mc {{.Name}} --with-region s3
+
+   13. List all versions of objects including delete markers, removing the latest delete marker restores the object.
+      $ mc {{.Name}} --versions --include-delete-markers s3/mybucket/backups/
+
+   14. List objects recursively with their full path, to be passed on to other commands.
+      $ mc {{.Name}} --recursive --full-path s3/mybucket/photos/
+
+   15. List a large bucket on Amazon S3 a page of 500 objects at a time, resuming after the last page listed.
+      $ mc {{.Name}} --json --recursive --max-keys 500 s3/mybucket/
+      $ mc {{.Name}} --json --recursive --max-keys 500 --start-after photos/2015/12/IMG_0452.jpg s3/mybucket/
+
+   16. List all images in mybucket on Amazon S3 cloud storage by their content type.
+      $ mc {{.Name}} --recursive --content-type "image/*" s3/mybucket/
+
+   17. List a bucket of millions of objects, printed as they are listed a page at a time. Output as a
+       table instead holds every row in memory until the listing completes, to align its columns.
+      $ mc {{.Name}} --recursive s3/mybucket/ > objects.txt
 `,
 }
 
@@ -90,9 +182,64 @@ func checkListSyntax(ctx *cli.Context) {
 			fatalIf(errInvalidArgument().Trace(args...), "Unable to validate empty argument.")
 		}
 	}
+	if ctx.Int("limit") < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("limit")), "Limit cannot be negative.")
+	}
+	if ctx.Int("max-keys") < 0 || ctx.Int("max-keys") > maxListKeys {
+		fatalIf(errInvalidArgument().Trace(ctx.String("max-keys")), "Max keys should be between 1 and 1000.")
+	}
+	if ctx.String("start-after") != "" || ctx.Int("max-keys") > 0 {
+		if ctx.Bool("incomplete") || ctx.Bool("versions") || ctx.Bool("with-region") {
+			fatalIf(errInvalidArgument().Trace(), "Options --start-after and --max-keys cannot be used with --incomplete, --versions or --with-region.")
+		}
+	}
+	if !isValidTimeStyle(ctx.String("time-style")) {
+		fatalIf(errInvalidArgument().Trace(ctx.String("time-style")), "Time style ‘"+ctx.String("time-style")+"’ is not one of default, iso, full, relative or unix.")
+	}
 	// extract URLs.
 	URLs := ctx.Args()
 	isIncomplete := ctx.Bool("incomplete")
+	if !isIncomplete && (ctx.Bool("summarize") || ctx.String("older-than") != "") {
+		fatalIf(errInvalidArgument().Trace(), "Options --summarize and --older-than can only be used with --incomplete.")
+	}
+	parseOlderThan(ctx.String("older-than"))
+	if ctx.Bool("full-path") && ctx.Bool("summarize") {
+		fatalIf(errInvalidArgument().Trace(), "Option --full-path cannot be used with --summarize.")
+	}
+	if ctx.Bool("full") && !ctx.Bool("metadata") {
+		fatalIf(errInvalidArgument().Trace(), "Option --full can only be used with --metadata.")
+	}
+	if ctx.Bool("metadata") && isIncomplete {
+		fatalIf(errInvalidArgument().Trace(), "Option --metadata cannot be used with --incomplete.")
+	}
+	if ctx.Bool("with-region") {
+		if isIncomplete || ctx.Bool("versions") || ctx.Bool("recursive") {
+			fatalIf(errInvalidArgument().Trace(), "Option --with-region cannot be used with --incomplete, --versions or --recursive.")
+		}
+		for _, url := range URLs {
+			if !isServiceRoot(url) {
+				fatalIf(errInvalidArgument().Trace(url), "Option --with-region lists the buckets of a host, ‘"+url+"’ is not a host.")
+			}
+		}
+	}
+	if contentType := ctx.String("content-type"); contentType != "" {
+		if _, e := path.Match(contentType, ""); e != nil {
+			fatalIf(probe.NewError(e).Trace(contentType), "Unable to parse content type pattern ‘"+contentType+"’.")
+		}
+		if isIncomplete || ctx.Bool("versions") || ctx.Bool("with-region") {
+			fatalIf(errInvalidArgument().Trace(), "Option --content-type cannot be used with --incomplete, --versions or --with-region.")
+		}
+	}
+	if ctx.Bool("include-delete-markers") && !ctx.Bool("versions") {
+		fatalIf(errInvalidArgument().Trace(), "Option --include-delete-markers can only be used with --versions.")
+	}
+	if ctx.Bool("versions") {
+		if isIncomplete {
+			fatalIf(errInvalidArgument().Trace(), "Option --versions cannot be used with --incomplete.")
+		}
+		// Objects with a delete marker as latest version cannot be stat'ed.
+		return
+	}
 
 	for _, url := range URLs {
 		_, _, err := url2Stat(url)
@@ -109,6 +256,9 @@ func mainList(ctx *cli.Context) {
 	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
 	console.SetColor("Size", color.New(color.FgYellow))
 	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("Version", color.New(color.FgMagenta))
+	console.SetColor("Metadata", color.New(color.FgBlue))
+	console.SetColor("Region", color.New(color.FgMagenta))
 
 	// Set global flags from context.
 	setGlobalsFromContext(ctx)
@@ -119,6 +269,19 @@ func mainList(ctx *cli.Context) {
 	// Set command flags from context.
 	isRecursive := ctx.Bool("recursive")
 	isIncomplete := ctx.Bool("incomplete")
+	limit := ctx.Int("limit")
+	isVersions := ctx.Bool("versions")
+	isDeleteMarkers := ctx.Bool("include-delete-markers")
+	isSummarize := ctx.Bool("summarize")
+	olderThan := parseOlderThan(ctx.String("older-than"))
+	isMetadata := ctx.Bool("metadata")
+	isFull := ctx.Bool("full")
+	globalTimeStyle = ctx.String("time-style")
+	isWithRegion := ctx.Bool("with-region")
+	isFullPath := ctx.Bool("full-path")
+	startAfter := ctx.String("start-after")
+	maxKeys := ctx.Int("max-keys")
+	contentTypePattern := ctx.String("content-type")
 
 	args := ctx.Args()
 	// mimic operating system tool behavior.
@@ -127,14 +290,80 @@ func mainList(ctx *cli.Context) {
 	}
 
 	for _, targetURL := range args {
+		// ‘alias/bucket’ lists the contents of the bucket as ‘alias/bucket/’ does.
+		if isBucketRoot(targetURL) && !strings.HasSuffix(targetURL, "/") {
+			targetURL = targetURL + "/"
+		}
 		var clnt client.Client
 		clnt, err := newClient(targetURL)
 		fatalIf(err.Trace(targetURL), "Unable to initialize target ‘"+targetURL+"’.")
 
-		err = doList(clnt, isRecursive, isIncomplete)
+		if isSummarize {
+			alias, _, _ := mustExpandAlias(targetURL)
+			newAliasClient := func(urlStr string) (client.Client, *probe.Error) {
+				return newClientFromAlias(alias, urlStr)
+			}
+			doListIncomplete(clnt, newAliasClient, isRecursive, olderThan, limit)
+			continue
+		}
+
+		var newStatClient func(urlStr string) (client.Client, *probe.Error)
+		// Objects are stat'ed for their content type, files have theirs guessed from their name.
+		if isFull || (contentTypePattern != "" && clnt.GetURL().Type == client.Object) {
+			alias, _, _ := mustExpandAlias(targetURL)
+			newStatClient = uncachedStats(func(urlStr string) (client.Client, *probe.Error) {
+				return newClientFromAlias(alias, urlStr)
+			})
+		}
+		var newRegionClient func(urlStr string) (client.Client, *probe.Error)
+		if isWithRegion {
+			alias, _, _ := mustExpandAlias(targetURL)
+			newRegionClient = func(urlStr string) (client.Client, *probe.Error) {
+				return newClientFromAlias(alias, urlStr)
+			}
+		}
+		pathPrefix := ""
+		if isFullPath {
+			pathPrefix = fullPathPrefix(targetURL, clnt)
+		}
+		err = doList(clnt, pathPrefix, isRecursive, isIncomplete, isVersions, isDeleteMarkers, olderThan, limit, startAfter, maxKeys, contentTypePattern, isMetadata, newStatClient, newRegionClient)
 		if err != nil {
 			errorIf(err.Trace(clnt.GetURL().String()), "Unable to list target ‘"+clnt.GetURL().String()+"’.")
 			continue
 		}
 	}
 }
+
+// uncachedStats - clients of newClient stat'ing past the stat cache. Listed objects are stat'ed
+// once each, caching them would only churn through the cache.
+func uncachedStats(newClient func(urlStr string) (client.Client, *probe.Error)) func(urlStr string) (client.Client, *probe.Error) {
+	return func(urlStr string) (client.Client, *probe.Error) {
+		clnt, err := newClient(urlStr)
+		if cached, ok := clnt.(statCacheClient); ok {
+			return cached.Client, err
+		}
+		return clnt, err
+	}
+}
+
+// parseOlderThan - parse ‘--older-than’ duration such as ‘168h’ or ‘90d’, zero if not set.
+func parseOlderThan(olderThanArg string) time.Duration {
+	if olderThanArg == "" {
+		return 0
+	}
+	var olderThan time.Duration
+	var e error
+	// Days are not known to time.ParseDuration.
+	if days := strings.TrimSuffix(olderThanArg, "d"); days != olderThanArg {
+		var n int
+		n, e = strconv.Atoi(days)
+		olderThan = time.Duration(n) * 24 * time.Hour
+	} else {
+		olderThan, e = time.ParseDuration(olderThanArg)
+	}
+	fatalIf(probe.NewError(e), "Unable to parse older-than=‘"+olderThanArg+"’.")
+	if olderThan < 0 {
+		fatalIf(errInvalidArgument().Trace(olderThanArg), "Option --older-than cannot be negative.")
+	}
+	return olderThan
+}
@@ -19,6 +19,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -41,11 +43,31 @@ type contentMessage struct {
 	Time     time.Time `json:"lastModified"`
 	Size     int64     `json:"size"`
 	Key      string    `json:"key"`
+
+	// Set only when listing versions.
+	VersionID      string `json:"versionId,omitempty"`
+	IsLatest       bool   `json:"isLatest,omitempty"`
+	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+
+	// Set only when listing with metadata, if known.
+	ETag         string `json:"etag,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+	// Set only when listing with metadata, if the listing names the owner.
+	OwnerID          string `json:"ownerId,omitempty"`
+	OwnerDisplayName string `json:"ownerDisplayName,omitempty"`
+	// Set only when listing with full metadata, if the object is set to expire.
+	Expires      *time.Time `json:"expires,omitempty"`
+	ExpiryDate   *time.Time `json:"expiryDate,omitempty"`
+	ExpiryRuleID string     `json:"expiryRuleId,omitempty"`
+
+	// Set only when listing buckets with their region.
+	Region string `json:"region,omitempty"`
 }
 
 // String colorized string message.
 func (c contentMessage) String() string {
-	message := console.Colorize("Time", fmt.Sprintf("[%s] ", c.Time.Format(printDate)))
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", formatTime(c.Time)))
 	message = message + console.Colorize("Size", fmt.Sprintf("%6s ", humanize.IBytes(uint64(c.Size))))
 	message = func() string {
 		if c.Filetype == "folder" {
@@ -53,9 +75,53 @@ func (c contentMessage) String() string {
 		}
 		return message + console.Colorize("File", fmt.Sprintf("%s", c.Key))
 	}()
+	if c.VersionID != "" {
+		message = message + console.Colorize("Version", fmt.Sprintf(" %s", c.VersionID))
+		if c.IsLatest {
+			message = message + console.Colorize("Version", " (latest)")
+		}
+		if c.IsDeleteMarker {
+			message = message + console.Colorize("Version", " (delete marker)")
+		}
+	}
+	if c.Region != "" {
+		message = message + console.Colorize("Region", " "+c.Region)
+	}
+	for _, field := range []string{c.StorageClass, c.ETag, c.ContentType} {
+		if field != "" {
+			message = message + console.Colorize("Metadata", " "+field)
+		}
+	}
+	if c.OwnerDisplayName != "" {
+		message = message + console.Colorize("Metadata", " owner "+c.OwnerDisplayName)
+	} else if c.OwnerID != "" {
+		message = message + console.Colorize("Metadata", " owner "+c.OwnerID)
+	}
+	if c.Expires != nil {
+		message = message + console.Colorize("Metadata", " expires "+formatTime(*c.Expires))
+	}
+	if c.ExpiryDate != nil {
+		message = message + console.Colorize("Metadata", " expiry "+formatTime(*c.ExpiryDate))
+		if c.ExpiryRuleID != "" {
+			message = message + console.Colorize("Metadata", " ("+c.ExpiryRuleID+")")
+		}
+	}
 	return message
 }
 
+// setExpiry - expiry of st, if any, on the message of a listed object.
+func (c *contentMessage) setExpiry(st *client.Content) {
+	if !st.Expires.IsZero() {
+		expires := st.Expires.Local()
+		c.Expires = &expires
+	}
+	if !st.ExpiryDate.IsZero() {
+		expiryDate := st.ExpiryDate.Local()
+		c.ExpiryDate = &expiryDate
+		c.ExpiryRuleID = st.ExpiryRuleID
+	}
+}
+
 // JSON jsonified content message.
 func (c contentMessage) JSON() string {
 	c.Status = "success"
@@ -79,6 +145,10 @@ func parseContent(c *client.Content) contentMessage {
 	}()
 
 	content.Size = c.Size
+	content.VersionID = c.VersionID
+	content.IsLatest = c.IsLatest
+	content.IsDeleteMarker = c.IsDeleteMarker
+	content.Region = c.Region
 	// Convert OS Type to match console file printing style.
 	content.Key = func() string {
 		switch {
@@ -103,14 +173,72 @@ func parseContent(c *client.Content) contentMessage {
 	return content
 }
 
-// doList - list all entities inside a folder.
-func doList(clnt client.Client, isRecursive, isIncomplete bool) *probe.Error {
+// listPrefix - folder of the listed URL, trimmed from listed paths.
+func listPrefix(clnt client.Client) string {
 	prefixPath := clnt.GetURL().Path
 	separator := string(clnt.GetURL().Separator)
 	if !strings.HasSuffix(prefixPath, separator) {
 		prefixPath = prefixPath[:strings.LastIndex(prefixPath, separator)+1]
 	}
-	for content := range clnt.List(isRecursive, isIncomplete) {
+	return prefixPath
+}
+
+// fullPathPrefix - folder listed by clnt as targetURL names it, with alias or host, listed paths
+// are prefixed with it to name them in full. Filesystem folders are made absolute.
+func fullPathPrefix(targetURL string, clnt client.Client) string {
+	clntURL := clnt.GetURL()
+	separator := string(clntURL.Separator)
+	prefixPath := listPrefix(clnt)
+	if clntURL.Type == client.Filesystem {
+		absPath, e := filepath.Abs(prefixPath)
+		if e != nil {
+			return prefixPath
+		}
+		return strings.TrimSuffix(absPath, separator) + separator
+	}
+	// The file listed, if any, follows the folder in targetURL.
+	prefix := strings.TrimSuffix(targetURL, strings.TrimPrefix(clntURL.Path, prefixPath))
+	if !strings.HasSuffix(prefix, separator) {
+		prefix = prefix + separator
+	}
+	return prefix
+}
+
+// doList - list all entities inside a folder, stops after limit entries if limit is positive.
+// Listed paths are relative to the folder, prefixed with pathPrefix. Objects are listed after the
+// key startAfter, only a page of maxKeys if maxKeys is positive.
+// All versions of objects are listed if isVersions is set, delete markers only along with
+// isDeleteMarkers. Incomplete uploads initiated within olderThan are skipped. Only objects whose
+// content type matches contentTypePattern are listed if it is set, objects are stat'ed for it with
+// newStatClient, or on a filesystem without, their content type guessed from their name.
+//
+// ETag and storage class are shown if isMetadata is set, content type and expiry too if
+// newStatClient is set, it returns the client each object is stat'ed with. Regions of buckets are shown if
+// newRegionClient is set, it returns the client of each bucket.
+func doList(clnt client.Client, pathPrefix string, isRecursive, isIncomplete, isVersions, isDeleteMarkers bool, olderThan time.Duration, limit int,
+	startAfter string, maxKeys int, contentTypePattern string, isMetadata bool, newStatClient, newRegionClient func(urlStr string) (client.Client, *probe.Error)) *probe.Error {
+	prefixPath := listPrefix(clnt)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	listed := 0
+	isPaged := startAfter != "" || maxKeys > 0
+	var marker string
+	contentCh := func() <-chan *client.Content {
+		if isVersions {
+			return clnt.ListVersions(isRecursive, doneCh)
+		}
+		if isPaged {
+			return listPages(clnt, isRecursive, startAfter, maxKeys, &marker, doneCh)
+		}
+		return clnt.List(isRecursive, isIncomplete, doneCh)
+	}()
+	if newRegionClient != nil {
+		contentCh = withRegions(contentCh, newRegionClient, doneCh)
+	}
+	if contentTypePattern != "" {
+		contentCh = withContentTypes(contentCh, newStatClient, doneCh)
+	}
+	for content := range contentCh {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -130,12 +258,222 @@ func doList(clnt client.Client, isRecursive, isIncomplete bool) *probe.Error {
 			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
 			continue
 		}
+		if isIncomplete && !content.Type.IsDir() && !isOlderThan(content, olderThan) {
+			continue
+		}
+		if content.IsDeleteMarker && !isDeleteMarkers {
+			continue
+		}
+		if contentTypePattern != "" && (content.Type.IsDir() || !matchContentType(contentTypePattern, content.ContentType)) {
+			continue
+		}
+		var st *client.Content
+		if newStatClient != nil && !content.Type.IsDir() && !content.IsDeleteMarker {
+			if contentTypePattern != "" {
+				// Stat'ed already for its content type.
+				st = content
+			} else {
+				st = statListed(newStatClient, content)
+			}
+		}
 		contentURL := content.URL.Path
 		contentURL = strings.TrimPrefix(contentURL, prefixPath)
-		content.URL.Path = contentURL
+		content.URL.Path = pathPrefix + contentURL
 		parsedContent := parseContent(content)
+		if isMetadata {
+			parsedContent.ETag = content.ETag
+			parsedContent.StorageClass = content.StorageClass
+			parsedContent.OwnerID = content.OwnerID
+			parsedContent.OwnerDisplayName = content.OwnerDisplayName
+			if st != nil {
+				parsedContent.ContentType = st.ContentType
+				parsedContent.setExpiry(st)
+			}
+		}
 		// print colorized or jsonized content info.
 		printMsg(parsedContent)
+		listed++
+		if limit > 0 && listed >= limit {
+			// Closing doneCh upon return stops listing further.
+			return nil
+		}
+	}
+	// The marker is set once all pages are listed.
+	if isPaged && marker != "" {
+		printMsg(listMarkerMessage{ContinuationToken: marker})
 	}
 	return nil
 }
+
+// maxListKeys - most objects listed in a page.
+const maxListKeys = 1000
+
+// listMarkerMessage - continuation token of a listing in pages.
+type listMarkerMessage struct {
+	Status            string `json:"status"`
+	ContinuationToken string `json:"continuationToken"`
+}
+
+// String - continuation token to resume listing with.
+func (l listMarkerMessage) String() string {
+	return console.Colorize("Metadata", "Continue listing with --start-after "+l.ContinuationToken)
+}
+
+// JSON - jsonified continuation token.
+func (l listMarkerMessage) JSON() string {
+	l.Status = "success"
+	jsonMessageBytes, e := json.Marshal(l)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// listPages - contents of clnt listed page by page after the key startAfter, only the first page of
+// maxKeys if maxKeys is positive. Once the channel is closed marker holds the key to continue listing
+// after, empty if all objects are listed. Listing stops at the first error.
+func listPages(clnt client.Client, isRecursive bool, startAfter string, maxKeys int, marker *string, doneCh <-chan struct{}) <-chan *client.Content {
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer close(contentCh)
+		pageKeys := maxKeys
+		if pageKeys <= 0 {
+			pageKeys = maxListKeys
+		}
+		for {
+			contents, nextMarker, err := clnt.ListPage(isRecursive, startAfter, pageKeys)
+			if err != nil {
+				contents = []*client.Content{{Err: err}}
+				nextMarker = ""
+			}
+			for _, content := range contents {
+				select {
+				case contentCh <- content:
+				case <-doneCh:
+					return
+				}
+			}
+			if nextMarker == "" || maxKeys > 0 {
+				*marker = nextMarker
+				return
+			}
+			startAfter = nextMarker
+		}
+	}()
+	return contentCh
+}
+
+// statListed - stat a listed object, its content type guessed from its name if not stored.
+// Nil if the stat failed.
+func statListed(newStatClient func(urlStr string) (client.Client, *probe.Error), content *client.Content) *client.Content {
+	urlStr := content.URL.String()
+	clnt, err := newStatClient(urlStr)
+	var st *client.Content
+	if err == nil {
+		st, err = clnt.Stat()
+	}
+	if err != nil {
+		errorIf(err.Trace(urlStr), "Unable to stat ‘"+urlStr+"’.")
+		return nil
+	}
+	if st.ContentType == "" {
+		// Stat'ed contents may be cached, guess on a copy.
+		guessed := *st
+		guessed.ContentType = guessURLContentType(urlStr)
+		return &guessed
+	}
+	return st
+}
+
+// regionWorkers - regions of buckets looked up in parallel.
+const regionWorkers = 8
+
+// withRegions - contents of contentCh in the same order, buckets with their region. Up to
+// regionWorkers regions are looked up at a time, a failed lookup shows region "unknown".
+func withRegions(contentCh <-chan *client.Content, newRegionClient func(urlStr string) (client.Client, *probe.Error), doneCh <-chan struct{}) <-chan *client.Content {
+	return updateInParallel(contentCh, regionWorkers, func(content *client.Content) {
+		if content.Err == nil && content.Type.IsDir() {
+			content.Region = bucketRegion(newRegionClient, content.URL.String())
+		}
+	}, doneCh)
+}
+
+// contentTypeWorkers - objects stat'ed in parallel for their content type.
+const contentTypeWorkers = 8
+
+// withContentTypes - contents of contentCh in the same order, objects with their content type and
+// expiry as stat'ed with newStatClient, with no newStatClient their content type guessed from their
+// name. Up to contentTypeWorkers objects are stat'ed at a time, a failed stat leaves it empty.
+func withContentTypes(contentCh <-chan *client.Content, newStatClient func(urlStr string) (client.Client, *probe.Error), doneCh <-chan struct{}) <-chan *client.Content {
+	return updateInParallel(contentCh, contentTypeWorkers, func(content *client.Content) {
+		if content.Err != nil || content.Type.IsDir() {
+			return
+		}
+		if newStatClient == nil {
+			content.ContentType = guessURLContentType(content.URL.String())
+			return
+		}
+		if st := statListed(newStatClient, content); st != nil {
+			content.ContentType = st.ContentType
+			content.Expires, content.ExpiryDate, content.ExpiryRuleID = st.Expires, st.ExpiryDate, st.ExpiryRuleID
+		}
+	}, doneCh)
+}
+
+// matchContentType - contentType, without parameters such as charset, matches the shell pattern,
+// such as ‘image/*’. Case is ignored.
+func matchContentType(pattern, contentType string) bool {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	matched, e := path.Match(strings.ToLower(pattern), strings.ToLower(strings.TrimSpace(contentType)))
+	return e == nil && matched
+}
+
+// updateInParallel - contents of contentCh in the same order, each passed to update by one of up
+// to workers at a time.
+func updateInParallel(contentCh <-chan *client.Content, workers int, update func(content *client.Content), doneCh <-chan struct{}) <-chan *client.Content {
+	lookupCh := make(chan chan *client.Content, workers)
+	workerCh := make(chan struct{}, workers)
+	go func() {
+		defer close(lookupCh)
+		for content := range contentCh {
+			resultCh := make(chan *client.Content, 1)
+			select {
+			case lookupCh <- resultCh:
+			case <-doneCh:
+				return
+			}
+			workerCh <- struct{}{}
+			go func(content *client.Content) {
+				update(content)
+				<-workerCh
+				resultCh <- content
+			}(content)
+		}
+	}()
+	updatedCh := make(chan *client.Content)
+	go func() {
+		defer close(updatedCh)
+		for resultCh := range lookupCh {
+			select {
+			case updatedCh <- <-resultCh:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	return updatedCh
+}
+
+// bucketRegion - region of the bucket at urlStr, "unknown" if it cannot be looked up.
+func bucketRegion(newRegionClient func(urlStr string) (client.Client, *probe.Error), urlStr string) string {
+	clnt, err := newRegionClient(urlStr)
+	if err != nil {
+		return "unknown"
+	}
+	region, err := clnt.GetBucketRegion()
+	if err != nil {
+		return "unknown"
+	}
+	return region
+}
@@ -37,6 +37,10 @@ var (
 			Name:  "help, h",
 			Usage: "Show help.",
 		},
+		cli.BoolFlag{
+			Name:  "version",
+			Usage: "Print version.",
+		},
 	}
 )
 
@@ -136,6 +140,9 @@ func registerBefore(ctx *cli.Context) error {
 	// Checkconfig if it can be read.
 	checkConfig()
 
+	// Merge user content-type overrides.
+	errorIf(loadContentTypes().Trace(), "Unable to load ‘"+globalMimeTypesFile+"’, using default content types.")
+
 	return nil
 }
 
@@ -161,20 +168,34 @@ func findClosestCommands(command string) []string {
 
 func registerApp() *cli.App {
 	// Register all the commands (refer flags.go)
-	registerCmd(lsCmd)      // List contents of a bucket.
-	registerCmd(mbCmd)      // Make a bucket.
-	registerCmd(catCmd)     // Display contents of a file.
-	registerCmd(pipeCmd)    // Write contents of stdin to a file.
-	registerCmd(shareCmd)   // Share documents via URL.
-	registerCmd(cpCmd)      // Copy objects and files from multiple sources to single destination.
-	registerCmd(mirrorCmd)  // Mirror objects and files from single source to multiple destinations.
-	registerCmd(diffCmd)    // Computer differences between two files or folders.
-	registerCmd(rmCmd)      // Remove a file or bucket
-	registerCmd(accessCmd)  // Set access permissions.
-	registerCmd(sessionCmd) // Manage sessions for copy and mirror.
-	registerCmd(configCmd)  // Configure minio client.
-	registerCmd(updateCmd)  // Check for new software updates.
-	registerCmd(versionCmd) // Print version.
+	registerCmd(lsCmd)         // List contents of a bucket.
+	registerCmd(treeCmd)       // List contents of a bucket in a tree.
+	registerCmd(mbCmd)         // Make a bucket.
+	registerCmd(rbCmd)         // Remove a bucket.
+	registerCmd(catCmd)        // Display contents of a file.
+	registerCmd(existsCmd)     // Check if a file or object exists.
+	registerCmd(pipeCmd)       // Write contents of stdin to a file.
+	registerCmd(shareCmd)      // Share documents via URL.
+	registerCmd(cpCmd)         // Copy objects and files from multiple sources to single destination.
+	registerCmd(mirrorCmd)     // Mirror objects and files from single source to multiple destinations.
+	registerCmd(syncCmd)       // Reconcile two folders in both directions.
+	registerCmd(diffCmd)       // Computer differences between two files or folders.
+	registerCmd(rmCmd)         // Remove a file or bucket
+	registerCmd(accessCmd)     // Set access permissions.
+	registerCmd(tagCmd)        // Manage object and bucket tags.
+	registerCmd(metaCmd)       // Manage object metadata.
+	registerCmd(retentionCmd)  // Manage object retention and legal hold.
+	registerCmd(versioningCmd) // Manage bucket versioning.
+	registerCmd(replicateCmd)  // Manage bucket replication.
+	registerCmd(undoCmd)       // Undo changes of versioned objects.
+	registerCmd(restoreCmd)    // Restore archived objects.
+	registerCmd(policyCmd)     // Manage bucket policies.
+	registerCmd(sessionCmd)    // Manage sessions for copy and mirror.
+	registerCmd(configCmd)     // Configure minio client.
+	registerCmd(aliasCmd)      // Manage host aliases.
+	registerCmd(pingCmd)       // Check if a host is reachable.
+	registerCmd(updateCmd)     // Check for new software updates.
+	registerCmd(versionCmd)    // Print version.
 
 	app := cli.NewApp()
 	app.Usage = "Minio Client for cloud storage and filesystems."
@@ -194,6 +215,16 @@ func main() {
 	contentdb.Init() // Load contentdb into memory.
 	app := registerApp()
 	app.Before = registerBefore
+	app.After = func(ctx *cli.Context) error {
+		flushOutput()
+		return nil
+	}
+
+	// mc --version prints the same message as mc version, --json included.
+	cli.VersionPrinter = func(ctx *cli.Context) {
+		setGlobalsFromContext(ctx)
+		printMsg(newVersionMessage())
+	}
 
 	app.ExtraInfo = func() map[string]string {
 		if _, e := ts.GetSize(); e != nil {
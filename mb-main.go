@@ -116,6 +116,9 @@ func mainMakeBucket(ctx *cli.Context) {
 			errorIf(err.Trace(targetURL), "Unable to make bucket ‘"+targetURL+"’.")
 			continue
 		}
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
 
 		// Successfully created a bucket.
 		printMsg(makeBucketMessage{Status: "success", Bucket: targetURL})
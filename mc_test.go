@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 
 	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/contentdb"
 	. "gopkg.in/check.v1"
 )
 
@@ -40,6 +41,7 @@ var server *httptest.Server
 var app *cli.App
 
 func (s *TestSuite) SetUpSuite(c *C) {
+	contentdb.Init()
 }
 
 func (s *TestSuite) TearDownSuite(c *C) {
@@ -118,6 +120,38 @@ func (s *TestSuite) TestHumanizedTime(c *C) {
 	c.Assert(hTime.Days, Not(Equals), int64(0))
 }
 
+func (s *TestSuite) TestFormatTimeStyle(c *C) {
+	zone := time.FixedZone("CET", 3600)
+	modTime := time.Date(2015, 10, 21, 16, 29, 3, 120000000, zone)
+	now := modTime.Add(3*24*time.Hour + 2*time.Hour)
+	testCases := []struct {
+		style    string
+		t        time.Time
+		expected string
+	}{
+		{timeStyleDefault, modTime, "2015-10-21 16:29:03 CET"},
+		{timeStyleISO, modTime, "2015-10-21T16:29:03+01:00"},
+		{timeStyleFull, modTime, "2015-10-21 16:29:03.120000000 +0100"},
+		{timeStyleRelative, modTime, "3 days ago"},
+		{timeStyleRelative, now.Add(-time.Hour), "1 hour ago"},
+		{timeStyleRelative, now.Add(-30 * time.Second), "30 seconds ago"},
+		{timeStyleRelative, now, "just now"},
+		{timeStyleRelative, now.Add(2 * time.Minute), "in 2 minutes"},
+		{timeStyleUnix, modTime, "1445441343"},
+		// Unknown times are left out, except in the default style which is kept as it was.
+		{timeStyleDefault, time.Time{}, "0001-01-01 00:00:00 UTC"},
+		{timeStyleISO, time.Time{}, "-"},
+		{timeStyleFull, time.Time{}, "-"},
+		{timeStyleRelative, time.Time{}, "-"},
+		{timeStyleUnix, time.Time{}, "-"},
+	}
+	for i, testCase := range testCases {
+		c.Assert(isValidTimeStyle(testCase.style), Equals, true)
+		c.Assert(formatTimeStyle(testCase.t, testCase.style, now), Equals, testCase.expected, Commentf("Test %d", i+1))
+	}
+	c.Assert(isValidTimeStyle("long-iso"), Equals, false)
+}
+
 func (s *TestSuite) TestCommonPrefix(c *C) {
 	c.Assert(commonPrefix("/usr", "/usr/local"), Equals, "/usr")
 	c.Assert(commonPrefix("/uabbf", "/ursfad/ccc"), Equals, "/u")
@@ -0,0 +1,327 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Metadata keys set as standard headers, anything else is user metadata.
+const (
+	metaContentType  = "content-type"
+	metaCacheControl = "cache-control"
+)
+
+var (
+	metaFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of meta.",
+		},
+	}
+)
+
+// Manage object metadata.
+var metaCmd = cli.Command{
+	Name:   "meta",
+	Usage:  "Manage metadata of objects.",
+	Action: mainMeta,
+	Flags:  append(metaFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] set TARGET KEY=VALUE [KEY=VALUE...]
+   mc {{.Name}} [FLAGS] list TARGET [TARGET...]
+
+   Keys ‘Content-Type’ and ‘Cache-Control’ set the respective header, any other key is user metadata.
+   Metadata not given is kept, an empty VALUE removes the key. The object is copied onto itself
+   server side, it is downloaded and uploaded again only if the server does not support that.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Correct the content type of an object on Amazon S3 cloud storage.
+      $ mc {{.Name}} set s3/mysite/index.html Content-Type=text/html
+
+   2. Let browsers cache an image for a day and record who owns it.
+      $ mc {{.Name}} set s3/mysite/logo.png Cache-Control=max-age=86400 owner=web
+
+   3. Remove user metadata ‘owner’ of an object.
+      $ mc {{.Name}} set s3/mysite/logo.png owner=
+
+   4. List metadata of an object as JSON.
+      $ mc --json {{.Name}} list s3/mysite/logo.png
+`,
+}
+
+// metaMessage is container for meta command success messages.
+type metaMessage struct {
+	Operation    string            `json:"operation"`
+	Status       string            `json:"status"`
+	Target       string            `json:"target"`
+	ContentType  string            `json:"contentType,omitempty"`
+	CacheControl string            `json:"cacheControl,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	// Set by list if the object is set to expire.
+	Expires      *time.Time `json:"expires,omitempty"`
+	ExpiryDate   *time.Time `json:"expiryDate,omitempty"`
+	ExpiryRuleID string     `json:"expiryRuleId,omitempty"`
+	// Set if the server could not copy the object onto itself.
+	Reuploaded bool `json:"reuploaded,omitempty"`
+}
+
+// String colorized meta message.
+func (m metaMessage) String() string {
+	switch m.Operation {
+	case "set":
+		message := console.Colorize("Meta", "Metadata updated successfully for ‘"+m.Target+"’.")
+		if m.Reuploaded {
+			message = console.Colorize("MetaWarning", "Server cannot replace metadata in place, ‘"+m.Target+"’ was uploaded again.") + "\n" + message
+		}
+		return message
+	case "list":
+		message := console.Colorize("Meta", "Metadata for ‘"+m.Target+"’:")
+		message += "\n" + console.Colorize("MetaKey", "   Content-Type") + "=" + console.Colorize("MetaValue", m.ContentType)
+		if m.CacheControl != "" {
+			message += "\n" + console.Colorize("MetaKey", "   Cache-Control") + "=" + console.Colorize("MetaValue", m.CacheControl)
+		}
+		keys := make([]string, 0, len(m.Metadata))
+		for key := range m.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			message += "\n" + console.Colorize("MetaKey", "   "+key) + "=" + console.Colorize("MetaValue", m.Metadata[key])
+		}
+		if m.Expires != nil {
+			message += "\n" + console.Colorize("MetaKey", "   Expires") + "=" + console.Colorize("MetaValue", m.Expires.UTC().Format(http.TimeFormat))
+		}
+		if m.ExpiryDate != nil {
+			expiry := m.ExpiryDate.UTC().Format(http.TimeFormat)
+			if m.ExpiryRuleID != "" {
+				expiry += " (rule ‘" + m.ExpiryRuleID + "’)"
+			}
+			message += "\n" + console.Colorize("MetaKey", "   Lifecycle-Expiry") + "=" + console.Colorize("MetaValue", expiry)
+		}
+		return message
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified meta message.
+func (m metaMessage) JSON() string {
+	metaJSONBytes, e := json.Marshal(m)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(metaJSONBytes)
+}
+
+// parseMetadata - parse KEY=VALUE arguments, keys are case insensitive.
+func parseMetadata(args []string) (map[string]string, *probe.Error) {
+	changes := make(map[string]string)
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalidMetadata(arg, "metadata should be of the form KEY=VALUE").Trace(arg)
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		if key == "" {
+			return nil, errInvalidMetadata(arg, "key cannot be empty").Trace(arg)
+		}
+		if _, ok := changes[key]; ok {
+			return nil, errInvalidMetadata(arg, "duplicate key").Trace(arg)
+		}
+		changes[key] = kv[1]
+	}
+	return changes, nil
+}
+
+// applyMetadata - metadata of content with changes applied, an empty value removes a key.
+func applyMetadata(content *client.Content, changes map[string]string) metaMessage {
+	edited := metaMessage{
+		ContentType:  content.ContentType,
+		CacheControl: content.CacheControl,
+		Metadata:     make(map[string]string),
+	}
+	for key, value := range content.Metadata {
+		edited.Metadata[key] = value
+	}
+	for key, value := range changes {
+		switch key {
+		case metaContentType:
+			edited.ContentType = value
+		case metaCacheControl:
+			edited.CacheControl = value
+		default:
+			if value == "" {
+				delete(edited.Metadata, key)
+				continue
+			}
+			edited.Metadata[key] = value
+		}
+	}
+	return edited
+}
+
+// checkMetaSyntax check for incoming syntax.
+func checkMetaSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "meta", 1) // last argument is exit code.
+	}
+	switch ctx.Args().First() {
+	case "set":
+		if len(ctx.Args().Tail()) < 2 {
+			cli.ShowCommandHelpAndExit(ctx, "meta", 1) // last argument is exit code.
+		}
+		_, err := parseMetadata(ctx.Args().Tail().Tail())
+		fatalIf(err.Trace(ctx.Args()...), "Unable to parse metadata.")
+	case "list":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "meta", 1) // last argument is exit code.
+	}
+	for _, arg := range ctx.Args().Tail() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+// statObject - stat the URL of clnt, which must be an object or a file.
+func statObject(clnt client.Client) (*client.Content, *probe.Error) {
+	content, err := clnt.Stat()
+	if err != nil {
+		return nil, err.Trace(clnt.GetURL().String())
+	}
+	if !content.Type.IsRegular() {
+		return nil, errInvalidTarget(clnt.GetURL().String()).Trace()
+	}
+	return content, nil
+}
+
+// doSetMetadata do set metadata, changes are applied to the current metadata. Servers
+// unable to copy an object onto itself get it uploaded again.
+func doSetMetadata(targetURL string, changes map[string]string) (metaMessage, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	content, err := statObject(clnt)
+	if err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	edited := applyMetadata(content, changes)
+	edited.Operation, edited.Status, edited.Target = "set", "success", targetURL
+
+	err = clnt.SetMetadata(edited.ContentType, edited.CacheControl, edited.Metadata)
+	if err == nil {
+		return edited, nil
+	}
+	if _, ok := err.ToGoError().(client.APINotImplemented); !ok || clnt.GetURL().Type != client.Object {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	// Fall back to uploading the object again, only content type and user metadata survive that.
+	if edited.CacheControl != "" {
+		return metaMessage{}, errCacheControlReupload(targetURL).Trace(targetURL)
+	}
+	reader, err := clnt.Get(0, content.Size, "")
+	if err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	if err = clnt.Put(reader, content.Size, edited.ContentType, edited.Metadata); err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	edited.Reuploaded = true
+	return edited, nil
+}
+
+// doListMetadata do list metadata.
+func doListMetadata(targetURL string) (metaMessage, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	content, err := statObject(clnt)
+	if err != nil {
+		return metaMessage{}, err.Trace(targetURL)
+	}
+	listed := metaMessage{
+		Operation:    "list",
+		Status:       "success",
+		Target:       targetURL,
+		ContentType:  content.ContentType,
+		CacheControl: content.CacheControl,
+		Metadata:     content.Metadata,
+		ExpiryRuleID: content.ExpiryRuleID,
+	}
+	if !content.Expires.IsZero() {
+		listed.Expires = &content.Expires
+	}
+	if !content.ExpiryDate.IsZero() {
+		listed.ExpiryDate = &content.ExpiryDate
+	}
+	return listed, nil
+}
+
+// mainMeta - main handler for mc meta command.
+func mainMeta(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'meta' cli arguments.
+	checkMetaSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Meta", color.New(color.FgGreen, color.Bold))
+	console.SetColor("MetaWarning", color.New(color.FgYellow, color.Bold))
+	console.SetColor("MetaKey", color.New(color.FgCyan, color.Bold))
+	console.SetColor("MetaValue", color.New(color.FgWhite))
+
+	switch ctx.Args().First() {
+	case "set":
+		targetURL := ctx.Args().Tail().First()
+		changes, err := parseMetadata(ctx.Args().Tail().Tail())
+		fatalIf(err.Trace(ctx.Args()...), "Unable to parse metadata.")
+		msg, err := doSetMetadata(targetURL, changes)
+		fatalIf(err.Trace(targetURL), "Unable to set metadata for ‘"+targetURL+"’.")
+		if globalDryRun { // Already printed by the dry run client.
+			return
+		}
+		printMsg(msg)
+	case "list":
+		for _, targetURL := range ctx.Args().Tail() {
+			msg, err := doListMetadata(targetURL)
+			if err != nil {
+				errorIf(err.Trace(targetURL), "Unable to list metadata for ‘"+targetURL+"’.")
+				continue
+			}
+			printMsg(msg)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// mirror event types.
+const (
+	mirrorEventCopying = "copying"
+	mirrorEventCopied  = "copied"
+	mirrorEventRemoved = "removed"
+	mirrorEventSkipped = "skipped"
+	mirrorEventFailed  = "failed"
+	mirrorEventSummary = "summary"
+)
+
+// mirrorEventWriter - where ‘mirror --json’ writes its events.
+var mirrorEventWriter io.Writer = os.Stdout
+
+// mirrorEvent - a JSON line of ‘mirror --json’. Every object gets a single terminal event,
+// copied, removed, skipped or failed. The summary event ends the stream.
+type mirrorEvent struct {
+	Status string `json:"status"`
+	Event  string `json:"event"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+	Size   int64  `json:"size"`
+	Error  string `json:"error,omitempty"`
+	// Aggregate counts, set only for the summary event.
+	Summary *summaryMessage `json:"summary,omitempty"`
+}
+
+// mirrorEventStream writes events of all mirror routines as JSON lines, one at a time.
+// Every event is flushed as soon as it is written.
+type mirrorEventStream struct {
+	mutex   *sync.Mutex
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// newMirrorEventStream - event stream writing to w.
+func newMirrorEventStream(w io.Writer) *mirrorEventStream {
+	writer := bufio.NewWriter(w)
+	return &mirrorEventStream{
+		mutex:   new(sync.Mutex),
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// emit - write event, a nil stream writes nothing.
+func (s *mirrorEventStream) emit(event mirrorEvent) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if event.Status == "" {
+		event.Status = "success"
+	}
+	e := s.encoder.Encode(event)
+	if e == nil {
+		e = s.writer.Flush()
+	}
+	fatalIf(probe.NewError(e), "Unable to write mirror event.")
+}
+
+// object - write an event of the object of sURLs, failed events carry err.
+func (s *mirrorEventStream) object(event string, sURLs mirrorURLs, err *probe.Error) {
+	if s == nil {
+		return
+	}
+	ev := mirrorEvent{Event: event, Size: sURLs.size()}
+	if sURLs.SourceContent != nil {
+		ev.Source = filepath.Join(sURLs.SourceAlias, sURLs.SourceContent.URL.Path)
+	}
+	if sURLs.TargetContent != nil {
+		ev.Target = filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path)
+	}
+	if err != nil {
+		ev.Status = "error"
+		ev.Error = err.ToGoError().Error()
+	}
+	s.emit(ev)
+}
+
+// summary - write the summary event ending the stream.
+func (s *mirrorEventStream) summary(session *sessionV6, summary *transferSummary) {
+	if s == nil {
+		return
+	}
+	msg := summary.Message(session)
+	msg.Status = "success"
+	s.emit(mirrorEvent{Event: mirrorEventSummary, Summary: &msg})
+}
@@ -18,24 +18,29 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"os"
 	"path/filepath"
-	"runtime"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 	"github.com/minio/pb"
 )
 
+// mirrorExcludeSeparator separates exclude patterns saved in a session, newlines are never part of a pattern.
+const mirrorExcludeSeparator = "\n"
+
 // mirror specific flags.
 var (
 	mirrorFlags = []cli.Flag{
@@ -47,6 +52,141 @@ var (
 			Name:  "force",
 			Usage: "Force overwrite of an existing target(s).",
 		},
+		cli.BoolFlag{
+			Name:  "summary",
+			Usage: "Print a summary of objects transferred, skipped and failed.",
+		},
+		cli.BoolFlag{
+			Name:  "stats",
+			Usage: "Print a histogram of object throughput and the time requests spent in DNS, connect, TLS, first byte and transfer.",
+		},
+		cli.StringFlag{
+			Name:  "summary-file",
+			Usage: "Append a JSON line per object transferred, skipped or failed to this file.",
+		},
+		cli.StringFlag{
+			Name:  "order",
+			Usage: "Transfer objects ‘smallest-first’ or ‘largest-first’, all are listed before the first is transferred. Listing order by default.",
+		},
+		cli.BoolFlag{
+			Name:  "remove-source",
+			Usage: "Remove each source once its copy on the target is verified by size and checksum. Sources of failed copies are kept.",
+		},
+		cli.BoolFlag{
+			Name:  "prune-dirs",
+			Usage: "With --remove-source, remove the local source folders it left empty.",
+		},
+		cli.StringFlag{
+			Name:  "min-free",
+			Usage: "Keep this much free on a local target, such as ‘10GiB’. Transfers which would not leave it free abort before they start, or once free space runs low.",
+		},
+		cli.StringFlag{
+			Name:  "acl",
+			Usage: "Canned ACL of copied objects, such as ‘public-read’ or ‘bucket-owner-full-control’. Sets the permissions of local files where it can.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-acl",
+			Usage: "Give each copy the owner and grants of its source object, both have to be on object storage.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-charset",
+			Value: client.MetadataCharsetRFC2047,
+			Usage: "Send user metadata values outside printable ASCII as ‘rfc2047’ encoded words or as ‘percent’ escapes, decoded again when read. Rejected with ‘none’.",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-hardlinks",
+			Usage: "Recreate hard links between local files on a local target, instead of copying the data of each again.",
+		},
+		cli.StringFlag{
+			Name:  "manifest",
+			Usage: "Append a JSON line per object copied, with its size, ETag and version, to this file.",
+		},
+		cli.StringFlag{
+			Name:  "verify-manifest",
+			Usage: "Verify the targets recorded in this manifest are unchanged, instead of mirroring.",
+		},
+		cli.BoolFlag{
+			Name:  "plan-only",
+			Usage: "Print what would be copied and removed, with --against, without mirroring.",
+		},
+		cli.StringFlag{
+			Name:  "against",
+			Usage: "Plan against the target as recorded in this manifest, the target is not listed.",
+		},
+		cli.StringFlag{
+			Name:  "notify",
+			Usage: "POST a JSON summary to this http(s) URL once done, failed or interrupted.",
+		},
+		cli.IntFlag{
+			Name:  "concurrent",
+			Usage: "Upload N parts of a large object in parallel, defaults to 4. Memory held grows with N.",
+		},
+		cli.StringFlag{
+			Name:  "bw-schedule",
+			Usage: "Cap bandwidth per time of day, e.g. \"09:00-17:00=2MB,17:00-09:00=0\". Zero is unlimited.",
+		},
+		cli.DurationFlag{
+			Name:  "object-timeout",
+			Usage: "Fail and retry the transfer of an object taking longer than this, e.g. 30s. Off by default.",
+		},
+		cli.DurationFlag{
+			Name:  "total-timeout",
+			Usage: "Stop mirroring after this long, e.g. 6h, the session can be resumed. Off by default.",
+		},
+		cli.IntFlag{
+			Name:  "skip-errors",
+			Usage: "Tolerate up to N failed objects, abort the whole mirror on one more. Off by default.",
+		},
+		cli.BoolFlag{
+			Name:  "if-not-present",
+			Usage: "Copy only objects missing on the target, objects which differ are kept as they are.",
+		},
+		cli.BoolFlag{
+			Name:  "remove",
+			Usage: "Remove objects on target which are not available on source.",
+		},
+		cli.BoolFlag{
+			Name:  "compare-etag",
+			Usage: "Objects of the same size differ if their ETags differ too. ETags of objects uploaded in parts differ from those uploaded at once.",
+		},
+		cli.BoolFlag{
+			Name:  "no-abort-incomplete",
+			Usage: "Keep the incomplete upload of a failed multipart upload, to resume it later.",
+		},
+		cli.StringFlag{
+			Name:  "symlinks",
+			Value: fs.SymlinksFollow,
+			Usage: "Local symlinks: follow, skip, error, or store them as empty objects with their target in metadata. Symlinks looping back are never followed.",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Value: &cli.StringSlice{},
+			Usage: "Exclude objects matching the glob pattern, may be repeated.",
+		},
+		cli.BoolFlag{
+			Name:  "exclude-empty",
+			Usage: "Do not mirror zero byte objects, their targets are left alone.",
+		},
+		cli.BoolFlag{
+			Name:  "exclude-hidden",
+			Usage: "Do not mirror hidden files and folders starting with a dot, their targets are left alone.",
+		},
+		cli.StringFlag{
+			Name:  "cache-control-map",
+			Usage: "JSON file mapping patterns of target keys, e.g. \"*.html\", to Cache-Control of uploads, the most specific match applies.",
+		},
+		cli.StringFlag{
+			Name:  "attr",
+			Usage: "Set attributes of all uploads, \"KEY=VALUE;...\", Content-Type, Cache-Control or user metadata. Overrides --cache-control-map.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-directive",
+			Usage: "COPY or REPLACE content type and metadata of objects mirrored between servers, REPLACE sets those of --attr instead. COPY by default.",
+		},
+		cli.StringFlag{
+			Name:  "newer-than-file",
+			Usage: "Mirror only objects modified after the modification time of this file, set to the start of the mirror once it succeeds. Everything is mirrored if the file does not exist.",
+		},
 	}
 )
 
@@ -61,6 +201,7 @@ var mirrorCmd = cli.Command{
 
 USAGE:
    mc {{.Name}} [FLAGS] SOURCE TARGET
+   mc {{.Name}} --verify-manifest FILE
 
 FLAGS:
   {{range .Flags}}{{.}}
@@ -74,6 +215,85 @@ EXAMPLES:
 
    3. Mirror a bucket from aliased Amazon S3 cloud storage to a folder on Windows.
       $ mc {{.Name}} s3\documents\2014\ C:\backup\2014
+
+   4. Mirror a local folder to Amazon S3 cloud storage, removing objects which are no longer available locally.
+      $ mc {{.Name}} --remove backup/ s3/archive
+
+   5. Preview mirroring of a local folder excluding temporary files.
+      $ mc {{.Name}} --dry-run --exclude "*.tmp" --exclude ".git/*" backup/ s3/archive
+
+   6. Mirror a local folder to Amazon S3 cloud storage and post a JSON summary to a webhook when done.
+      $ mc {{.Name}} --notify https://hooks.example.com/mc backup/ s3/archive
+
+   7. Mirror a folder of large files to Amazon S3 cloud storage uploading 8 parts of each in parallel.
+      $ mc {{.Name}} --concurrent 8 images/ s3/archive
+
+   8. Mirror a local folder to Amazon S3 cloud storage, keeping an audit record of every object.
+      $ mc {{.Name}} --summary-file audit.jsonl backup/ s3/archive
+
+   9. Mirror only the photos missing on Amazon S3 cloud storage, leaving changed ones alone.
+      $ mc {{.Name}} --if-not-present photos/ s3/archive/photos
+
+   10. Mirror a folder of large videos to Amazon S3 cloud storage, keeping the uploaded parts of failed uploads.
+      $ mc {{.Name}} --no-abort-incomplete movies/ s3/videos
+
+   11. Mirror a bucket to Amazon S3 cloud storage recording a manifest, later verify the copies are unchanged.
+      $ mc {{.Name}} --manifest photos.jsonl play/photos s3/backup-photos
+      $ mc {{.Name}} --verify-manifest photos.jsonl
+
+   11. Mirror a local folder to Amazon S3 cloud storage, following the failed objects as they happen.
+      $ mc --json {{.Name}} backup/ s3/archive | jq -c 'select(.event == "failed")'
+
+   12. Mirror a local folder to Amazon S3 cloud storage at no more than 1MB/s until midnight, at 10MB/s overnight.
+      $ mc {{.Name}} --bw-schedule "08:00-00:00=1MB,00:00-08:00=10MB" backup/ s3/archive
+
+   13. Mirror many small files to Amazon S3 cloud storage within the night, retrying any file taking over 30 seconds.
+      $ mc {{.Name}} --object-timeout 30s --total-timeout 8h logs/ s3/archive/logs
+
+   14. Mirror a local folder to Amazon S3 cloud storage past a few flaky files, stopping if more than 10 fail.
+      $ mc {{.Name}} --skip-errors 10 backup/ s3/archive
+
+   15. Mirror a local folder to Amazon S3 cloud storage leaving its symlinks out.
+      $ mc {{.Name}} --symlinks skip backup/ s3/archive
+
+   16. Mirror a growing folder of logs to Amazon S3 cloud storage hourly, looking only at logs written since the last run.
+      $ mc {{.Name}} --newer-than-file ~/.logs-mirrored logs/ s3/archive/logs
+
+   17. Review offline what mirroring a local folder would copy and remove, against the manifest of the last mirror.
+      $ mc --json {{.Name}} --plan-only --against photos.jsonl --remove photos/ s3/backup-photos
+
+   18. Find out whether a slow mirror to Amazon S3 cloud storage is spent setting up connections or transferring data.
+      $ mc {{.Name}} --stats photos/ s3/backup-photos
+
+   19. Mirror a project folder to Amazon S3 cloud storage without its dotfiles and empty placeholder files.
+      $ mc {{.Name}} --exclude-hidden --exclude-empty projects/ s3/backup-projects
+
+   20. Mirror a static site to Amazon S3 cloud storage with Cache-Control by file type, such as {"*.html": "max-age=60"}.
+      $ mc {{.Name}} --cache-control-map cache.json site/ s3/www
+
+   21. Mirror a bucket to another with fresh Cache-Control, instead of the metadata of the source objects.
+      $ mc {{.Name}} --metadata-directive REPLACE --attr "Cache-Control=max-age=60" s3/www s3/www-staging
+
+   22. Mirror a folder of mixed file sizes with the many small files first, large ones start only once all are listed.
+      $ mc {{.Name}} --order smallest-first photos/ s3/backup-photos
+
+   23. Move a bucket to another server, each object is removed from the source once its copy is verified.
+      $ mc {{.Name}} --remove-source play/photos s3/photos
+
+   24. Mirror a bucket to an external disk, aborting before the disk has less than 5GiB free.
+      $ mc {{.Name}} --min-free 5GiB s3/photos /media/backup/photos
+
+   25. Mirror a bucket to another region, keeping the grants each object has.
+      $ mc {{.Name}} --preserve-acl s3/photos s3-eu/photos
+
+   26. Mirror a folder to another disk, files hard linked to each other stay linked on it.
+      $ mc {{.Name}} --preserve-hardlinks /srv/builds /mnt/archive/builds
+
+   27. Mirror a bucket to a server unable to store encoded metadata, objects with metadata outside ASCII fail.
+      $ mc {{.Name}} --metadata-charset none s3/documents legacy/documents
+
+   28. Mirror a bucket to another server, overwriting objects changed without a change in size.
+      $ mc {{.Name}} --force --compare-etag s3/photos play/photos
 `,
 }
 
@@ -82,10 +302,14 @@ type mirrorMessage struct {
 	Status string `json:"status"`
 	Source string `json:"source"`
 	Target string `json:"target"`
+	Remove bool   `json:"remove,omitempty"`
 }
 
 // String colorized mirror message
 func (m mirrorMessage) String() string {
+	if m.Remove {
+		return console.Colorize("Mirror", fmt.Sprintf("Removed ‘%s’", m.Target))
+	}
 	return console.Colorize("Mirror", fmt.Sprintf("‘%s’ -> ‘%s’", m.Source, m.Target))
 }
 
@@ -119,18 +343,29 @@ func (c mirrorStatMessage) String() string {
 }
 
 // doMirror - Mirror an object to multiple destination. mirrorURLs status contains a copy of sURLs and error if any.
-func doMirror(sURLs mirrorURLs, progressReader *barSend, accountingReader *accounter, mirrorQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- mirrorURLs) {
+// With ‘--json’ the progress of the object is written to events, with ‘--manifest’ the copy is recorded to manifest.
+// It fails once ctx of the whole mirror is done.
+func doMirror(ctx context.Context, sURLs mirrorURLs, session *sessionV6, progressReader *barSend, accountingReader *accounter, events *mirrorEventStream,
+	manifest *mirrorManifest, mirrorQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- mirrorURLs) {
 	defer wg.Done() // Notify that this copy routine is done.
 	defer func() {
 		<-mirrorQueueCh
 	}()
+	start := time.Now()
 
 	if sURLs.Error != nil { // Errorneous sURLs passed.
 		sURLs.Error = sURLs.Error.Trace()
+		sURLs.Duration = time.Since(start)
+		events.object(mirrorEventFailed, sURLs, sURLs.Error)
 		statusCh <- sURLs
 		return
 	}
 
+	if sURLs.isRemove() {
+		doMirrorRemove(sURLs, events, statusCh)
+		return
+	}
+
 	sourceAlias := sURLs.SourceAlias
 	sourceURL := sURLs.SourceContent.URL
 	targetAlias := sURLs.TargetAlias
@@ -141,71 +376,305 @@ func doMirror(sURLs mirrorURLs, progressReader *barSend, accountingReader *accou
 		progressReader.SetCaption(sourceURL.String() + ": ")
 	}
 
-	reader, err := getSourceFromAlias(sourceAlias, sourceURL.String())
-	if err != nil {
-		if !globalQuiet && !globalJSON {
-			progressReader.ErrorGet(length)
+	// Copying is reported once the source could be read, a retry does not report it again.
+	isReported := false
+	reportCopying := func() {
+		if isReported || !(globalQuiet || globalJSON) {
+			return
+		}
+		isReported = true
+		if events != nil {
+			events.object(mirrorEventCopying, sURLs, nil)
+		} else {
+			printMsg(mirrorMessage{
+				Source: filepath.Join(sourceAlias, sourceURL.Path),
+				Target: filepath.Join(targetAlias, targetURL.Path),
+			})
 		}
-		sURLs.Error = err.Trace(sourceURL.String())
-		statusCh <- sURLs
-		return
 	}
 
-	var newReader io.ReadSeeker
-	if globalQuiet || globalJSON {
-		sourcePath := filepath.Join(sourceAlias, sourceURL.Path)
-		targetPath := filepath.Join(targetAlias, targetURL.Path)
-		printMsg(mirrorMessage{
-			Source: sourcePath,
-			Target: targetPath,
-		})
-		if globalJSON {
-			newReader = reader
+	// Objects timing out are retried before giving up on them, every attempt gets the full ‘--object-timeout’.
+	var source *client.Content
+	var err *probe.Error
+	sourceFailed := false
+	// With ‘--preserve-hardlinks’ local files sharing the data of one copied before are linked to its copy.
+	linked := false
+	if isHardlinkCopy(sourceURL, targetURL) {
+		var copied func(*probe.Error)
+		linked, copied = globalHardlinks.link(sURLs.SourceContent.HardlinkID, targetURL.Path)
+		defer func() { copied(sURLs.Error) }()
+	}
+	if linked {
+		source = sURLs.SourceContent
+		reportCopying()
+		if !globalQuiet && !globalJSON {
+			progressReader.Progress(length)
 		}
 		if globalQuiet {
-			newReader = accountingReader.NewProxyReader(reader)
+			accountingReader.Add(length)
+		}
+	}
+	for attempt := 1; !linked; attempt++ {
+		var read int64
+		source, read, sourceFailed, err = mirrorObject(ctx, sURLs, manifest != nil, reportCopying, progressReader, accountingReader, session.progress)
+		if err == nil || attempt >= copyAttempts || !isRetryable(err) {
+			break
+		}
+		// Progress of the failed attempt is taken back, it is transferred again.
+		if session.progress != nil {
+			read = session.progress.Retry(sourceURL.String())
+		}
+		if !globalQuiet && !globalJSON {
+			progressReader.ErrorPut(read)
+		}
+		if globalQuiet {
+			accountingReader.Add(-read)
+		}
+	}
+	if session.progress != nil {
+		if err == nil {
+			session.progress.Done(sourceURL.String(), length)
+		} else {
+			session.progress.Failed(sourceURL.String())
 		}
-	} else {
-		// set up progress
-		newReader = progressReader.NewProxyReader(reader)
 	}
-	err = putTargetFromAlias(targetAlias, targetURL.String(), newReader, length)
 	if err != nil {
 		if !globalQuiet && !globalJSON {
-			progressReader.ErrorPut(length)
+			if sourceFailed {
+				progressReader.ErrorGet(length)
+			} else {
+				progressReader.ErrorPut(length)
+			}
 		}
+		// Incomplete uploads are kept with ‘--no-abort-incomplete’, to be resumed later.
+		if !sourceFailed && !session.Header.CommandBoolFlags["no-abort-incomplete"] {
+			if targetClnt, e := newClientFromAlias(targetAlias, targetURL.String()); e == nil {
+				err = abortIncompleteUpload(targetClnt, err)
+			}
+		}
+		sURLs.Error = err.Trace()
+		sURLs.Duration = time.Since(start)
+		events.object(mirrorEventFailed, sURLs, sURLs.Error)
+		statusCh <- sURLs
+		return
+	}
+
+	if err = applyMirroredACL(session, sURLs); err != nil {
 		sURLs.Error = err.Trace(targetURL.String())
+		sURLs.Duration = time.Since(start)
+		events.object(mirrorEventFailed, sURLs, sURLs.Error)
 		statusCh <- sURLs
 		return
 	}
 
+	// Recorded before the object is marked completed, a resumed session copies and records it again otherwise.
+	if manifest != nil {
+		if err = recordManifestEntry(manifest, sURLs, source); err != nil {
+			sURLs.Error = err.Trace(targetURL.String())
+			sURLs.Duration = time.Since(start)
+			events.object(mirrorEventFailed, sURLs, sURLs.Error)
+			statusCh <- sURLs
+			return
+		}
+	}
+
+	// With ‘--remove-source’ the source goes once its copy is verified, one which does not verify fails and keeps it.
+	if session.Header.CommandBoolFlags["remove-source"] {
+		if err = removeMirroredSource(sURLs); err != nil {
+			sURLs.Error = err.Trace(sourceURL.String())
+			sURLs.Duration = time.Since(start)
+			events.object(mirrorEventFailed, sURLs, sURLs.Error)
+			statusCh <- sURLs
+			return
+		}
+	}
+
 	sURLs.Error = nil // just for safety
+	sURLs.Duration = time.Since(start)
+	events.object(mirrorEventCopied, sURLs, nil)
+	statusCh <- sURLs
+}
+
+// mirrorObject - a single attempt to mirror the object of sURLs, failing once ‘--object-timeout’ passed or ctx is done.
+// The source is stat'ed first if isStat is set, reportCopying is called once it could be read. Returns the stat'ed source, the number of bytes read from the source
+// and whether the source could not be read at all.
+func mirrorObject(ctx context.Context, sURLs mirrorURLs, isStat bool, reportCopying func(), progressReader *barSend,
+	accountingReader *accounter, progress *progressAccumulator) (source *client.Content, read int64, sourceFailed bool, err *probe.Error) {
+	sourceURL := sURLs.SourceContent.URL.String()
+	targetURL := sURLs.TargetContent.URL.String()
+	length := sURLs.SourceContent.Size
+
+	objectCtx, cancel := newObjectContext(ctx, globalObjectTimeout)
+	defer cancel()
+	defer func() {
+		err = transferTimeoutError(ctx, objectCtx, sourceURL, globalObjectTimeout, globalTotalTimeout, err)
+	}()
+
+	// The manifest records the source as it is copied, which may have changed since it was listed.
+	if isStat {
+		if source, err = statManifestSource(objectCtx, sURLs); err != nil {
+			return nil, 0, true, err.Trace()
+		}
+		length = source.Size
+	}
+
+	sourceClnt, err := newClientFromAlias(sURLs.SourceAlias, sourceURL)
+	if err != nil {
+		return source, 0, true, err.Trace(sourceURL)
+	}
+	reader, err := sourceClnt.WithContext(objectCtx).Get(0, 0, "")
+	if err != nil {
+		return source, 0, true, err.Trace(sourceURL)
+	}
+	reportCopying()
+
+	// Counts the bytes read, stall detection is off.
+	counter := newStallReader(newBandwidthReader(reader, globalBandwidthLimiter), sourceURL, 0, defaultStallGrace)
+	defer counter.Stop()
+
+	var newReader io.ReadSeeker = counter
+	// Progress is shared by all workers of the session.
+	if progress != nil {
+		newReader = progress.NewProxyReader(sourceURL, counter)
+	}
+	switch {
+	case globalQuiet:
+		newReader = accountingReader.NewProxyReader(newReader)
+	case !globalJSON:
+		newReader = client.NewProgressReader(newReader, progressReader.Progress)
+	}
+	targetClnt, err := newClientFromAlias(sURLs.TargetAlias, targetURL)
+	if err == nil {
+		contentType := guessURLContentType(targetURL)
+		var metadata map[string]string
+		// Objects mirrored between servers keep their content type and metadata, unless
+		// ‘--metadata-directive REPLACE’ is set.
+		if isObjectCopy(sourceClnt, targetClnt) && !isMetadataReplaced() {
+			sourceContent := source
+			if sourceContent == nil {
+				if sourceContent, err = sourceClnt.WithContext(objectCtx).Stat(); err != nil {
+					return source, counter.BytesRead(), true, err.Trace(sourceURL)
+				}
+			}
+			if sourceContent.ContentType != "" {
+				contentType = sourceContent.ContentType
+			}
+			metadata = sourceContent.Metadata
+		}
+		contentType, metadata = applyUploadAttrs(metadataTargetKey(sURLs.TargetAlias, targetURL), contentType, metadata)
+		err = targetClnt.WithContext(objectCtx).Put(newReader, length, contentType, symlinkMetadata(sURLs.SourceContent, metadata))
+	}
+	if err != nil {
+		return source, counter.BytesRead(), false, err.Trace(targetURL)
+	}
+	return source, counter.BytesRead(), false, nil
+}
+
+// doMirrorRemove - Remove a target object which is not available on source.
+func doMirrorRemove(sURLs mirrorURLs, events *mirrorEventStream, statusCh chan<- mirrorURLs) {
+	start := time.Now()
+	targetAlias := sURLs.TargetAlias
+	targetURL := sURLs.TargetContent.URL
+	clnt, err := newClientFromAlias(targetAlias, targetURL.String())
+	if err == nil {
+		err = clnt.Remove(false, "")
+	}
+	if err != nil {
+		sURLs.Error = err.Trace(targetURL.String())
+		sURLs.Duration = time.Since(start)
+		events.object(mirrorEventFailed, sURLs, sURLs.Error)
+		statusCh <- sURLs
+		return
+	}
+	sURLs.Duration = time.Since(start)
+	if events != nil {
+		events.object(mirrorEventRemoved, sURLs, nil)
+		statusCh <- sURLs
+		return
+	}
+	if !globalQuiet && !globalJSON {
+		console.Eraseline()
+	}
+	printMsg(mirrorMessage{
+		Target: filepath.Join(targetAlias, targetURL.Path),
+		Remove: true,
+	})
 	statusCh <- sURLs
 }
 
 // doMirrorFake - Perform a fake mirror to update the progress bar appropriately.
 func doMirrorFake(sURLs mirrorURLs, progressReader *barSend) {
 	if !globalDebug && !globalJSON {
-		progressReader.Progress(sURLs.SourceContent.Size)
+		progressReader.Progress(sURLs.size())
 	}
 }
 
+// doMirrorDryRun - print objects which would be mirrored or removed, skipping the ones
+// isCopied reports as done.
+func doMirrorDryRun(URLsCh <-chan mirrorURLs, isCopied func(string) bool) {
+	for sURLs := range URLsCh {
+		if sURLs.Error != nil {
+			errorIf(sURLs.Error.Trace(), "Unable to prepare URLs for mirroring.")
+			continue
+		}
+		if sURLs.isEmpty() || isCopied(sURLs.sessionURL()) {
+			continue
+		}
+		if sURLs.isRemove() {
+			printMsg(dryRunMessage{
+				Operation: "remove",
+				Target:    filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path),
+			})
+			continue
+		}
+		printMsg(dryRunMessage{
+			Operation: "copy",
+			Source:    filepath.Join(sURLs.SourceAlias, sURLs.SourceContent.URL.Path),
+			Target:    filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path),
+			Size:      sURLs.SourceContent.Size,
+		})
+	}
+}
+
+// mirrorURLsFromSession - mirror URLs saved in a session, prepared again if the session has none.
+func mirrorURLsFromSession(session *sessionV6) <-chan mirrorURLs {
+	if !session.HasData() {
+		return prepareMirrorURLs(session.Header.CommandArgs[0], session.Header.CommandArgs[1],
+			session.Header.CommandBoolFlags["force"], session.Header.CommandBoolFlags["if-not-present"],
+			session.Header.CommandBoolFlags["remove"], session.Header.CommandBoolFlags["compare-etag"],
+			getMirrorExcludes(session), getSessionContentFilter(session))
+	}
+	URLsCh := make(chan mirrorURLs)
+	go func() {
+		defer close(URLsCh)
+		scanner := bufio.NewScanner(session.NewDataReader())
+		for scanner.Scan() {
+			var sURLs mirrorURLs
+			json.Unmarshal([]byte(scanner.Text()), &sURLs)
+			URLsCh <- sURLs
+		}
+	}()
+	return URLsCh
+}
+
 // doPrepareMirrorURLs scans the source URL and prepares a list of objects for mirroring.
-func doPrepareMirrorURLs(session *sessionV6, isForce bool, trapCh <-chan bool) {
+func doPrepareMirrorURLs(session *sessionV6, isForce, isIfNotPresent, isRemove bool, excludes []string, trapCh <-chan bool) {
 	sourceURL := session.Header.CommandArgs[0] // first one is source.
 	targetURL := session.Header.CommandArgs[1]
 	var totalBytes int64
 	var totalObjects int
 
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
+	// Create a session data file to store the processed URLs, in the order of ‘--order’.
+	dataFP := newOrderedDataWriter(session.NewDataWriter(), session.Header.CommandStringFlags["order"])
 
 	var scanBar scanBarFunc
 	if !globalQuiet && !globalJSON { // set up progress bar
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareMirrorURLs(sourceURL, targetURL, isForce)
+	isCompareETag := session.Header.CommandBoolFlags["compare-etag"]
+	URLsCh := filterNewerMirrorURLs(prepareMirrorURLs(sourceURL, targetURL, isForce, isIfNotPresent, isRemove, isCompareETag, excludes, getSessionContentFilter(session)),
+		getSessionNewerThan(session))
 	done := false
 	for done == false {
 		select {
@@ -230,44 +699,87 @@ func doPrepareMirrorURLs(session *sessionV6, isForce bool, trapCh <-chan bool) {
 				session.Delete()
 				fatalIf(probe.NewError(err), "Unable to marshal URLs into JSON.")
 			}
-			fmt.Fprintln(dataFP, string(jsonData))
+			dataFP.add(string(jsonData), sURLs.size())
 			if !globalQuiet && !globalJSON {
-				scanBar(sURLs.SourceContent.URL.String())
+				scanBar(sURLs.sessionURL())
 			}
 
-			totalBytes += sURLs.SourceContent.Size
+			totalBytes += sURLs.size()
 			totalObjects++
 		case <-trapCh:
 			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
 			if !globalQuiet && !globalJSON {
 				console.Eraseline()
 			}
+			removeSpillFiles()
 			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
 			os.Exit(0)
 		}
 	}
+	dataFP.flush()
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
-	session.Save()
+	session.SaveOrDie()
 }
 
 // Session'fied mirror command.
-func doMirrorSession(session *sessionV6) {
+// doMirrorSession - mirror all objects of session, returns the summary of the transfer.
+func doMirrorSession(session *sessionV6) *transferSummary {
 	isForce := session.Header.CommandBoolFlags["force"]
+	isIfNotPresent := session.Header.CommandBoolFlags["if-not-present"]
+	isRemove := session.Header.CommandBoolFlags["remove"]
+	excludes := getMirrorExcludes(session)
 	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	globalPartConcurrency = session.Header.CommandIntFlags["concurrent"]
+	globalCannedACL = session.Header.CommandStringFlags["acl"]
+	globalMetadataCharset = session.Header.CommandStringFlags["metadata-charset"]
+	globalHardlinks = getSessionHardlinks(session)
+	globalBandwidthLimiter = getSessionBandwidthLimiter(session)
+	globalObjectTimeout, globalTotalTimeout = getSessionTimeouts(session)
+	globalTransferStats = getSessionTransferStats(session)
+	globalSymlinks = session.Header.CommandStringFlags["symlinks"]
+	globalCacheControlMap, globalUploadAttrs = getSessionUploadAttrs(session)
+	globalMetadataDirective = session.Header.CommandStringFlags["metadata-directive"]
+
+	// Buckets signed for a region they are not in fail before any object is mirrored.
+	for _, arg := range session.Header.CommandArgs {
+		fatalIf(verifyAliasRegion(arg), "Unable to mirror ‘"+arg+"’.")
+	}
 
 	if !session.HasData() {
-		doPrepareMirrorURLs(session, isForce, trapCh)
+		doPrepareMirrorURLs(session, isForce, isIfNotPresent, isRemove, excludes, trapCh)
 	}
 
+	// Progress of all workers, saved with the session.
+	session.progress = newProgressAccumulator(session.Header.TotalBytes, session.Header.TotalObjects)
+
 	// Enable accounting reader by default.
 	accntReader := newAccounter(session.Header.TotalBytes)
 
-	// Set up progress bar.
+	// Objects in flight fail once ‘--total-timeout’ passed, stopping the mirror.
+	ctx, cancel := newTotalContext(globalTotalTimeout)
+	defer cancel()
+	// Failures beyond ‘--skip-errors’ cancel ctx as well.
+	budget := getSessionErrorBudget(session, cancel)
+	// Elapsed time and speed are those of this run, objects mirrored by earlier runs count as skipped.
+	summary := newTransferSummary(time.Now())
+	// A local target needs room for what is left to mirror, running low while mirroring cancels ctx too.
+	freeSpace, err := newFreeSpaceGuard(session, session.Header.CommandArgs[1], remainingBytes(session.progress), cancel)
+	if err != nil {
+		summary.Abort(err.Trace())
+		return summary
+	}
+	defer freeSpace.stop()
+
+	// Set up progress bar, JSON output is a stream of events instead.
 	var progressReader *barSend
+	var events *mirrorEventStream
 	if !globalQuiet && !globalJSON {
 		progressReader = newProgressBar(session.Header.TotalBytes)
 	}
+	if globalJSON {
+		events = newMirrorEventStream(mirrorEventWriter)
+	}
 
 	// Prepare URL scanner from session data file.
 	scanner := bufio.NewScanner(session.NewDataReader())
@@ -277,8 +789,29 @@ func doMirrorSession(session *sessionV6) {
 
 	wg := new(sync.WaitGroup)
 	// Limit numner of mirror routines based on available CPU resources.
-	mirrorQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+	mirrorQueue := make(chan bool, copyWorkers(0))
 	defer close(mirrorQueue)
+
+	// Summary of objects transferred, skipped and failed, each is also recorded with ‘--summary-file’.
+	records, err := openSummaryFile(session)
+	fatalIf(err.Trace(session.Header.CommandStringFlags["summary-file"]), "Unable to open summary file.")
+	defer records.Close()
+
+	// Objects copied are recorded with ‘--manifest’, a resumed session appends to it.
+	manifest, err := openMirrorManifest(session)
+	fatalIf(err.Trace(session.Header.CommandStringFlags["manifest"]), "Unable to open manifest.")
+	defer manifest.Close()
+
+	// finish - report the summary, as the last event with ‘--json’.
+	finish := func(interrupted bool) {
+		if events != nil {
+			events.summary(session, summary)
+		} else {
+			printSummary(session, summary)
+		}
+		notifySummary(session, summary, interrupted)
+	}
+
 	// Status channel for receiveing mirror return status.
 	statusCh := make(chan mirrorURLs)
 
@@ -292,7 +825,7 @@ func doMirrorSession(session *sessionV6) {
 				if !ok { // We are done here. Top level function has returned.
 					if !globalQuiet && !globalJSON {
 						progressReader.Finish()
-					} else {
+					} else if events == nil {
 						accntStat := accntReader.Stat()
 						mrStatMessage := mirrorStatMessage{
 							Total:       accntStat.Total,
@@ -301,17 +834,44 @@ func doMirrorSession(session *sessionV6) {
 						}
 						console.Println(console.Colorize("Mirror", mrStatMessage.String()))
 					}
+					printTransferStats(globalTransferStats)
+					finish(false)
 					return
 				}
 				if sURLs.Error == nil {
-					session.Header.LastCopied = sURLs.SourceContent.URL.String()
+					if sURLs.isRemove() {
+						session.markCompleted(sURLs.sessionURL(), 0)
+						records.Record(recordRemoved, nil, sURLs.TargetContent, sURLs.Duration, nil)
+					} else {
+						summary.Transferred(sURLs.size())
+						globalTransferStats.Transferred(sURLs.size(), sURLs.Duration)
+						records.Record(recordTransferred, sURLs.SourceContent, sURLs.TargetContent, sURLs.Duration, nil)
+						session.markCompleted(sURLs.sessionURL(), sURLs.size())
+					}
 					session.Save()
+				} else if (budget != nil && budget.exceeded()) || freeSpace.failed() != nil {
+					// Objects cancelled by the abort are not failures, a resumed session mirrors them.
+					continue
 				} else {
+					summary.Failed(sURLs.size(), sURLs.sessionURL(), sURLs.Error)
+					records.Record(recordFailed, sURLs.SourceContent, sURLs.TargetContent, sURLs.Duration, sURLs.Error)
 					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
 					if !globalQuiet && !globalJSON {
 						console.Eraseline()
 					}
-					errorIf(sURLs.Error.Trace(), fmt.Sprintf("Failed to mirror ‘%s’.", sURLs.SourceContent.URL.String()))
+					// Failed events carry the error with ‘--json’.
+					if events == nil {
+						errorIf(sURLs.Error.Trace(), fmt.Sprintf("Failed to mirror ‘%s’.", sURLs.sessionURL()))
+					}
+					// Failures up to ‘--skip-errors’ are tolerated, the first beyond aborts the mirror.
+					if budget != nil {
+						if budget.fail() {
+							summary.Tolerated()
+						} else {
+							summary.Abort(errTooManyFailures(int(budget.limit)).Trace())
+						}
+						continue
+					}
 					// for all non critical errors we can continue for the remaining files
 					switch sURLs.Error.ToGoError().(type) {
 					// handle this specifically for filesystem related errors.
@@ -323,16 +883,21 @@ func doMirrorSession(session *sessionV6) {
 						continue
 					case client.PathInsufficientPermission:
 						continue
+					// an object which kept timing out does not hold up the others.
+					case client.ObjectTimeout:
+						continue
 					}
 					// for critical errors we should exit. Session can be resumed after the user figures out the problem
-					session.CloseAndDie()
+					finish(false)
+					session.CloseAndDie(sURLs.Error)
 				}
 			case <-trapCh: // Receive interrupt notification.
 				// Print in new line and adjust to top so that we don't print over the ongoing progress bar
 				if !globalQuiet && !globalJSON {
 					console.Eraseline()
 				}
-				session.CloseAndDie()
+				finish(true)
+				session.CloseAndDie(nil)
 			}
 		}
 	}()
@@ -347,23 +912,40 @@ func doMirrorSession(session *sessionV6) {
 		for scanner.Scan() {
 			var sURLs mirrorURLs
 			json.Unmarshal([]byte(scanner.Text()), &sURLs)
-			if isCopied(sURLs.SourceContent.URL.String()) {
+			if isCopied(sURLs.sessionURL()) {
 				doMirrorFake(sURLs, progressReader)
+				events.object(mirrorEventSkipped, sURLs, nil)
+				summary.Skipped(sURLs.size())
+				records.Record(recordSkipped, sURLs.SourceContent, sURLs.TargetContent, 0, nil)
 			} else {
 				// Wait for other mirror routines to
 				// complete. We only have limited CPU
 				// and network resources.
 				mirrorQueue <- true
+				// Nothing more is mirrored once the mirror was aborted.
+				if (budget != nil && budget.exceeded()) || freeSpace.failed() != nil {
+					<-mirrorQueue
+					break
+				}
 				// Account for each mirror routines we start.
 				mirrorWg.Add(1)
 				// Do mirroring in background concurrently.
-				go doMirror(sURLs, progressReader, accntReader, mirrorQueue, mirrorWg, statusCh)
+				go doMirror(ctx, sURLs, session, progressReader, accntReader, events, manifest, mirrorQueue, mirrorWg, statusCh)
 			}
 		}
 		mirrorWg.Wait()
 	}()
 
 	wg.Wait()
+	if err := freeSpace.failed(); err != nil {
+		summary.Abort(err.Trace())
+	}
+	pruneSourceDirs(session, session.Header.CommandArgs[:1])
+	// The next mirror with ‘--newer-than-file’ continues from the start of this one.
+	if summary.exitCode() == 0 && summary.abortError() == nil {
+		errorIf(touchNewerThanFile(session).Trace(), "Unable to update the reference file of --newer-than-file.")
+	}
+	return summary
 }
 
 // Main entry point for mirror command.
@@ -376,6 +958,46 @@ func mainMirror(ctx *cli.Context) {
 
 	// Additional command speific theme customization.
 	console.SetColor("Mirror", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Summary", color.New(color.FgCyan, color.Bold))
+	console.SetColor("ManifestDrift", color.New(color.FgRed, color.Bold))
+
+	// Verify the targets of an earlier mirror against its manifest, nothing is copied.
+	if manifestFile := ctx.String("verify-manifest"); manifestFile != "" {
+		mismatches, err := doVerifyManifest(manifestFile)
+		fatalIf(err.Trace(manifestFile), "Unable to verify manifest ‘"+manifestFile+"’.")
+		// Each mismatch was already reported, signal there were some.
+		if mismatches > 0 {
+			flushOutput()
+			os.Exit(exitPartialFailure)
+		}
+		return
+	}
+
+	// Objects modified before the reference file of ‘--newer-than-file’ are left out.
+	newerThanFile := ctx.String("newer-than-file")
+	cutoff, err := newerThanFileCutoff(newerThanFile)
+	fatalIf(err.Trace(newerThanFile), "Unable to read the modification time of ‘"+newerThanFile+"’.")
+
+	if ctx.Bool("exclude-hidden") {
+		warnExcludeHidden(ctx.Args()[:1])
+	}
+	filter := contentFilter{excludeEmpty: ctx.Bool("exclude-empty"), excludeHidden: ctx.Bool("exclude-hidden")}
+
+	// Plan against the target as recorded in a manifest, it is not listed.
+	if ctx.Bool("plan-only") {
+		trapSpillFiles()
+		URLsCh := prepareMirrorPlanURLs(ctx.Args()[0], ctx.Args()[1], ctx.String("against"), ctx.Bool("force"), ctx.Bool("if-not-present"), ctx.Bool("remove"), ctx.Bool("compare-etag"), ctx.StringSlice("exclude"), filter)
+		doMirrorDryRun(filterNewerMirrorURLs(URLsCh, cutoff), isCopiedFactory(""))
+		return
+	}
+
+	if globalDryRun {
+		// Dry run is never resumed, no session is necessary.
+		trapSpillFiles()
+		URLsCh := prepareMirrorURLs(ctx.Args()[0], ctx.Args()[1], ctx.Bool("force"), ctx.Bool("if-not-present"), ctx.Bool("remove"), ctx.Bool("compare-etag"), ctx.StringSlice("exclude"), filter)
+		doMirrorDryRun(filterNewerMirrorURLs(URLsCh, cutoff), isCopiedFactory(""))
+		return
+	}
 
 	var e error
 	session := newSessionV6()
@@ -389,9 +1011,82 @@ func mainMirror(ctx *cli.Context) {
 	// Set command flags from context.
 	isForce := ctx.Bool("force")
 	session.Header.CommandBoolFlags["force"] = isForce
+	session.Header.CommandBoolFlags["if-not-present"] = ctx.Bool("if-not-present")
+	session.Header.CommandBoolFlags["no-abort-incomplete"] = ctx.Bool("no-abort-incomplete")
+	session.Header.CommandBoolFlags["summary"] = ctx.Bool("summary")
+	session.Header.CommandBoolFlags["stats"] = ctx.Bool("stats")
+	session.Header.CommandStringFlags["order"] = ctx.String("order")
+	session.Header.CommandBoolFlags["remove-source"] = ctx.Bool("remove-source")
+	session.Header.CommandBoolFlags["prune-dirs"] = ctx.Bool("prune-dirs")
+	session.Header.CommandStringFlags["min-free"] = ctx.String("min-free")
+	session.Header.CommandStringFlags["acl"] = ctx.String("acl")
+	session.Header.CommandStringFlags["metadata-charset"] = ctx.String("metadata-charset")
+	session.Header.CommandBoolFlags["preserve-acl"] = ctx.Bool("preserve-acl")
+	session.Header.CommandBoolFlags["preserve-hardlinks"] = ctx.Bool("preserve-hardlinks")
+	if summaryFile := ctx.String("summary-file"); summaryFile != "" {
+		summaryFilePath, err := getSummaryFilePath(summaryFile)
+		fatalIf(err.Trace(summaryFile), "Invalid summary file ‘"+summaryFile+"’.")
+		session.Header.CommandStringFlags["summary-file"] = summaryFilePath
+	}
+	if manifestFile := ctx.String("manifest"); manifestFile != "" {
+		manifestFilePath, err := getSummaryFilePath(manifestFile)
+		fatalIf(err.Trace(manifestFile), "Invalid manifest ‘"+manifestFile+"’.")
+		session.Header.CommandStringFlags["manifest"] = manifestFilePath
+	}
+	if notifyURL := ctx.String("notify"); notifyURL != "" {
+		session.Header.CommandStringFlags["notify"] = notifyURL
+	}
+	if concurrent := ctx.Int("concurrent"); concurrent > 0 {
+		session.Header.CommandIntFlags["concurrent"] = concurrent
+	}
+	if scheduleStr := ctx.String("bw-schedule"); scheduleStr != "" {
+		session.Header.CommandStringFlags["bw-schedule"] = scheduleStr
+	}
+	if objectTimeout := ctx.Duration("object-timeout"); objectTimeout > 0 {
+		session.Header.CommandStringFlags["object-timeout"] = objectTimeout.String()
+	}
+	if totalTimeout := ctx.Duration("total-timeout"); totalTimeout > 0 {
+		session.Header.CommandStringFlags["total-timeout"] = totalTimeout.String()
+	}
+	if ctx.IsSet("skip-errors") {
+		session.Header.CommandIntFlags["skip-errors"] = ctx.Int("skip-errors")
+	}
+	session.Header.CommandBoolFlags["remove"] = ctx.Bool("remove")
+	session.Header.CommandBoolFlags["compare-etag"] = ctx.Bool("compare-etag")
+	session.Header.CommandStringFlags["symlinks"] = ctx.String("symlinks")
+	setSessionUploadAttrs(session, ctx)
+	setSessionMetadataDirective(session, ctx)
+	setMirrorExcludes(session, ctx.StringSlice("exclude"))
+	session.Header.CommandBoolFlags["exclude-empty"] = filter.excludeEmpty
+	session.Header.CommandBoolFlags["exclude-hidden"] = filter.excludeHidden
+	if err = setSessionNewerThan(session, newerThanFile, cutoff); err != nil {
+		session.Delete()
+		fatalIf(err.Trace(newerThanFile), "Unable to locate reference file ‘"+newerThanFile+"’.")
+	}
 
 	// extract URLs.
 	session.Header.CommandArgs = ctx.Args()
-	doMirrorSession(session)
+	summary := doMirrorSession(session)
+	// An aborted mirror is kept to be resumed.
+	if err := summary.abortError(); err != nil {
+		errorIf(err.Trace(), "Aborted mirror.")
+		session.CloseAndDie(err)
+	}
 	session.Delete()
+	exitOnFailures(summary)
+}
+
+// setMirrorExcludes - save exclude patterns to session.
+func setMirrorExcludes(session *sessionV6, excludes []string) {
+	if len(excludes) > 0 {
+		session.Header.CommandStringFlags["exclude"] = strings.Join(excludes, mirrorExcludeSeparator)
+	}
+}
+
+// getMirrorExcludes - load exclude patterns from session.
+func getMirrorExcludes(session *sessionV6) []string {
+	if excludes := session.Header.CommandStringFlags["exclude"]; excludes != "" {
+		return strings.Split(excludes, mirrorExcludeSeparator)
+	}
+	return nil
 }
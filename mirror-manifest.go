@@ -0,0 +1,282 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// manifestEntry - an object copied by mirror, one line of the ‘--manifest’. Source fields are
+// those of the source at copy time, target fields those of the copy right after it was made.
+type manifestEntry struct {
+	Time            time.Time `json:"time"`
+	SourceAlias     string    `json:"sourceAlias,omitempty"`
+	Source          string    `json:"source"`
+	Size            int64     `json:"size"`
+	ETag            string    `json:"etag,omitempty"`
+	VersionID       string    `json:"versionId,omitempty"`
+	TargetAlias     string    `json:"targetAlias,omitempty"`
+	Target          string    `json:"target"`
+	TargetETag      string    `json:"targetEtag,omitempty"`
+	TargetVersionID string    `json:"targetVersionId,omitempty"`
+}
+
+// mirrorManifest appends a JSON line per copied object to a file, before the object is marked
+// completed in the session. A resumed session appends to the same file. Safe for concurrent use.
+type mirrorManifest struct {
+	mutex *sync.Mutex
+	file  *os.File
+}
+
+// openMirrorManifest - open the ‘--manifest’ of session for appending, nil if none is set.
+func openMirrorManifest(session *sessionV6) (*mirrorManifest, *probe.Error) {
+	path := session.Header.CommandStringFlags["manifest"]
+	if path == "" {
+		return nil, nil
+	}
+	file, e := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return &mirrorManifest{mutex: new(sync.Mutex), file: file}, nil
+}
+
+// Record - append entry. Unlike the ‘--summary-file’ a failed write fails the object, the
+// manifest must not miss anything that was copied.
+func (m *mirrorManifest) Record(entry manifestEntry) *probe.Error {
+	entryBytes, e := json.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	// A single write of a whole line, lines of parallel copies never interleave.
+	if _, e = m.file.Write(append(entryBytes, '\n')); e != nil {
+		return probe.NewError(e).Trace(m.file.Name())
+	}
+	return nil
+}
+
+// Close - close the file, a nil mirrorManifest is ignored.
+func (m *mirrorManifest) Close() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.file.Close()
+}
+
+// statManifestSource - stat the source of sURLs right before it is copied, what was listed may
+// have changed since. The stat fails once ctx is done.
+func statManifestSource(ctx context.Context, sURLs mirrorURLs) (*client.Content, *probe.Error) {
+	sourceURL := sURLs.SourceContent.URL.String()
+	clnt, err := newClientFromAlias(sURLs.SourceAlias, sourceURL)
+	if err != nil {
+		return nil, err.Trace(sourceURL)
+	}
+	st, err := clnt.WithContext(ctx).Stat()
+	if err != nil {
+		return nil, err.Trace(sourceURL)
+	}
+	return st, nil
+}
+
+// recordManifestEntry - record the copy of source, as stat'ed before the copy, to the target of sURLs.
+func recordManifestEntry(manifest *mirrorManifest, sURLs mirrorURLs, source *client.Content) *probe.Error {
+	targetURL := sURLs.TargetContent.URL.String()
+	clnt, err := newClientFromAlias(sURLs.TargetAlias, targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	target, err := clnt.Stat()
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	return manifest.Record(manifestEntry{
+		Time:            time.Now().UTC(),
+		SourceAlias:     sURLs.SourceAlias,
+		Source:          sURLs.SourceContent.URL.String(),
+		Size:            source.Size,
+		ETag:            source.ETag,
+		VersionID:       source.VersionID,
+		TargetAlias:     sURLs.TargetAlias,
+		Target:          targetURL,
+		TargetETag:      target.ETag,
+		TargetVersionID: target.VersionID,
+	})
+}
+
+// Outcomes of verifying an object of a manifest.
+const (
+	manifestMatched = "matched"
+	manifestDrifted = "drifted"
+	manifestMissing = "missing"
+)
+
+// manifestVerifyMessage container for the outcome of verifying an object of a manifest.
+type manifestVerifyMessage struct {
+	Status string `json:"status"`
+	Target string `json:"target"`
+	Result string `json:"result"`
+	// Set only if drifted, what differs from the manifest.
+	Reason string `json:"reason,omitempty"`
+}
+
+// String colorized manifest verify message.
+func (m manifestVerifyMessage) String() string {
+	switch m.Result {
+	case manifestDrifted:
+		return console.Colorize("ManifestDrift", fmt.Sprintf("Drifted ‘%s’, %s.", m.Target, m.Reason))
+	case manifestMissing:
+		return console.Colorize("ManifestDrift", fmt.Sprintf("Missing ‘%s’.", m.Target))
+	}
+	return console.Colorize("Mirror", fmt.Sprintf("Matched ‘%s’.", m.Target))
+}
+
+// JSON jsonified manifest verify message.
+func (m manifestVerifyMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.Marshal(m)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// verifyManifestEntry - check the target of entry still is the copy the manifest recorded.
+// ETags and versions are compared only if both the manifest and the target have one.
+func verifyManifestEntry(entry manifestEntry) (manifestVerifyMessage, *probe.Error) {
+	msg := manifestVerifyMessage{Target: entry.Target, Result: manifestMatched}
+	clnt, err := newClientFromAlias(entry.TargetAlias, entry.Target)
+	if err != nil {
+		return msg, err.Trace(entry.Target)
+	}
+	st, err := clnt.Stat()
+	if err != nil {
+		switch err.ToGoError().(type) {
+		case client.PathNotFound, client.ObjectMissing:
+			msg.Result = manifestMissing
+			return msg, nil
+		}
+		return msg, err.Trace(entry.Target)
+	}
+	switch {
+	case st.Size != entry.Size:
+		msg.Result, msg.Reason = manifestDrifted, fmt.Sprintf("size %d, expected %d", st.Size, entry.Size)
+	case entry.TargetETag != "" && st.ETag != "" && st.ETag != entry.TargetETag:
+		msg.Result, msg.Reason = manifestDrifted, fmt.Sprintf("ETag ‘%s’, expected ‘%s’", st.ETag, entry.TargetETag)
+	case entry.TargetVersionID != "" && st.VersionID != "" && st.VersionID != entry.TargetVersionID:
+		msg.Result, msg.Reason = manifestDrifted, fmt.Sprintf("version ‘%s’, expected ‘%s’", st.VersionID, entry.TargetVersionID)
+	}
+	return msg, nil
+}
+
+// doVerifyManifest - verify every object of the manifest filename, returns the number of objects
+// which drifted, are missing or could not be verified.
+func doVerifyManifest(filename string) (int, *probe.Error) {
+	file, e := os.Open(filename)
+	if e != nil {
+		return 0, probe.NewError(e).Trace(filename)
+	}
+	defer file.Close()
+
+	mismatches := 0
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry manifestEntry
+		if e = json.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			return mismatches, probe.NewError(e).Trace(filename, strconv.Itoa(line))
+		}
+		msg, err := verifyManifestEntry(entry)
+		if err != nil {
+			errorIf(err.Trace(filename, strconv.Itoa(line)), "Unable to verify ‘"+entry.Target+"’.")
+			mismatches++
+			continue
+		}
+		if msg.Result != manifestMatched {
+			mismatches++
+		}
+		printMsg(msg)
+	}
+	if e = scanner.Err(); e != nil {
+		return mismatches, probe.NewError(e).Trace(filename)
+	}
+	return mismatches, nil
+}
+
+// listManifestTargets - the targets under targetURL recorded in the manifest filename, as a listing
+// in lexical order until doneCh is closed. A target copied more than once is listed as last recorded.
+func listManifestTargets(filename, targetURL string, doneCh <-chan struct{}) (<-chan *client.Content, *probe.Error) {
+	file, e := os.Open(filename)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	defer file.Close()
+
+	entries := make(map[string]manifestEntry)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry manifestEntry
+		if e = json.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			return nil, probe.NewError(e).Trace(filename, strconv.Itoa(line))
+		}
+		if strings.HasPrefix(entry.Target, targetURL) {
+			entries[entry.Target] = entry
+		}
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	targets := make([]string, 0, len(entries))
+	for target := range entries {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer close(contentCh)
+		for _, target := range targets {
+			entry := entries[target]
+			content := &client.Content{
+				URL:       *client.NewURL(entry.Target),
+				Time:      entry.Time,
+				Size:      entry.Size,
+				ETag:      entry.TargetETag,
+				VersionID: entry.TargetVersionID,
+			}
+			select {
+			case contentCh <- content:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	return contentCh, nil
+}
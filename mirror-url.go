@@ -17,11 +17,18 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -30,19 +37,41 @@ type mirrorURLs struct {
 	SourceContent *client.Content
 	TargetAlias   string
 	TargetContent *client.Content
-	Error         *probe.Error `json:"-"`
+	Error         *probe.Error  `json:"-"`
+	Duration      time.Duration `json:"-"` // time spent mirroring, not saved in the session.
 }
 
 func (m mirrorURLs) isEmpty() bool {
 	if m.SourceContent == nil && m.TargetContent == nil && m.Error == nil {
 		return true
 	}
-	if m.SourceContent.Size == 0 && m.TargetContent == nil && m.Error == nil {
+	if m.SourceContent != nil && m.SourceContent.Size == 0 && m.TargetContent == nil && m.Error == nil {
 		return true
 	}
 	return false
 }
 
+// isRemove - true if target is to be removed, since it is not available in source.
+func (m mirrorURLs) isRemove() bool {
+	return m.SourceContent == nil && m.TargetContent != nil
+}
+
+// sessionURL - URL which identifies this entry in a session, target URL for removals.
+func (m mirrorURLs) sessionURL() string {
+	if m.isRemove() {
+		return m.TargetContent.URL.String()
+	}
+	return m.SourceContent.URL.String()
+}
+
+// size - number of bytes transferred for this entry.
+func (m mirrorURLs) size() int64 {
+	if m.SourceContent == nil {
+		return 0
+	}
+	return m.SourceContent.Size
+}
+
 //
 //   * MIRROR ARGS - VALID CASES
 //   =========================
@@ -50,6 +79,13 @@ func (m mirrorURLs) isEmpty() bool {
 
 // checkMirrorSyntax(URLs []string)
 func checkMirrorSyntax(ctx *cli.Context) {
+	// Verifying a manifest takes no arguments, the targets are recorded in it.
+	if ctx.String("verify-manifest") != "" {
+		if len(ctx.Args()) != 0 || ctx.String("manifest") != "" {
+			cli.ShowCommandHelpAndExit(ctx, "mirror", 1) // last argument is exit code.
+		}
+		return
+	}
 	if len(ctx.Args()) != 2 {
 		cli.ShowCommandHelpAndExit(ctx, "mirror", 1) // last argument is exit code.
 	}
@@ -84,6 +120,52 @@ func checkMirrorSyntax(ctx *cli.Context) {
 			}
 		}
 	}
+	if notifyURL := ctx.String("notify"); notifyURL != "" {
+		fatalIf(checkNotifyURL(notifyURL).Trace(), "Unable to use notify URL.")
+	}
+	if ctx.Int("concurrent") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --concurrent cannot be negative.")
+	}
+	if scheduleStr := ctx.String("bw-schedule"); scheduleStr != "" {
+		_, err := parseBandwidthSchedule(scheduleStr)
+		fatalIf(err.Trace(scheduleStr), "Invalid bandwidth schedule ‘"+scheduleStr+"’.")
+	}
+	checkUploadAttrsSyntax(ctx.String("cache-control-map"), ctx.String("attr"))
+	checkCopyDirectivesSyntax(ctx)
+	checkTransferOrder(ctx.String("order"))
+	checkRemoveSourceSyntax(ctx)
+	checkMinFreeSyntax(ctx)
+	checkACLSyntax(ctx)
+	checkMetadataCharsetSyntax(ctx)
+	checkPreserveHardlinksSyntax(ctx)
+	if ctx.Int("skip-errors") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Option --skip-errors cannot be negative.")
+	}
+	if ctx.Duration("object-timeout") < 0 || ctx.Duration("total-timeout") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Options --object-timeout and --total-timeout cannot be negative.")
+	}
+	if symlinks := ctx.String("symlinks"); !fs.IsValidSymlinkPolicy(symlinks) {
+		fatalIf(errInvalidArgument().Trace(symlinks), "Option --symlinks is one of ‘follow’, ‘skip’, ‘error’ or ‘store’.")
+	}
+	if ctx.Bool("if-not-present") && ctx.Bool("force") {
+		fatalIf(errInvalidArgument().Trace(), "Options --if-not-present and --force are mutually exclusive.")
+	}
+	for _, pattern := range ctx.StringSlice("exclude") {
+		if _, e := path.Match(pattern, ""); e != nil {
+			fatalIf(probe.NewError(e).Trace(pattern), "Invalid exclude pattern ‘"+pattern+"’.")
+		}
+	}
+	if ctx.Bool("plan-only") != (ctx.String("against") != "") {
+		fatalIf(errInvalidArgument().Trace(), "Options --plan-only and --against are used together.")
+	}
+	if ctx.Bool("plan-only") {
+		if ctx.String("manifest") != "" {
+			fatalIf(errInvalidArgument().Trace(), "Option --plan-only copies nothing to record in a --manifest.")
+		}
+		// The target is planned against its manifest, offline.
+		return
+	}
+
 	_, _, err = url2Stat(tgtURL)
 	// we die on any error other than client.PathNotFound - destination directory need not exist.
 	if _, ok := err.ToGoError().(client.PathNotFound); !ok {
@@ -91,7 +173,234 @@ func checkMirrorSyntax(ctx *cli.Context) {
 	}
 }
 
-func deltaSourceTargets(sourceURL string, targetURL string, isForce bool, mirrorURLsCh chan<- mirrorURLs) {
+// mirrorDiff is the difference between source and target entries of the same key.
+type mirrorDiff struct {
+	Key    string          // key relative to source and target roots, '/' separated.
+	Source *client.Content // nil if only in target.
+	Target *client.Content // nil if only in source.
+	Differ string
+	Error  *probe.Error
+}
+
+// mirrorKey - key of content relative to rootURL, separators are normalized for comparison.
+func mirrorKey(rootURL string, content *client.Content) string {
+	suffix := strings.TrimPrefix(content.URL.String(), rootURL)
+	return strings.Replace(suffix, string(content.URL.Separator), "/", -1)
+}
+
+// isMirrorExcluded - true if key or its base name matches any of the exclude glob patterns.
+func isMirrorExcluded(key string, excludes []string) bool {
+	for _, pattern := range excludes {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(key)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorLister - iterates over a lexically sorted listing, skipping folders and excluded keys.
+type mirrorLister struct {
+	rootURL  string
+	ch       <-chan *client.Content
+	excludes []string
+	// Zero byte entries with ‘--exclude-empty’ are listed, but never copied, their targets are left alone.
+	excludeEmpty bool
+	content      *client.Content // current entry, nil once listing is done.
+	key          string
+	unsorted     bool // listing is not in lexical order, no difference can be computed.
+}
+
+// next - advance to the next entry, listing errors are returned and listing continues.
+func (l *mirrorLister) next() *probe.Error {
+	for content := range l.ch {
+		if content.Err != nil {
+			return content.Err.Trace(l.rootURL)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		key := mirrorKey(l.rootURL, content)
+		if isMirrorExcluded(key, l.excludes) {
+			continue
+		}
+		if l.content != nil && key < l.key {
+			// Merge join relies on lexical order, bail out instead of producing a wrong difference.
+			l.content = nil
+			l.unsorted = true
+			return errListingNotSorted(l.rootURL, key).Trace(l.key)
+		}
+		l.content = content
+		l.key = key
+		return nil
+	}
+	l.content = nil
+	return nil
+}
+
+// isEmptyExcluded - true if the current entry is zero bytes and excluded with ‘--exclude-empty’.
+func (l *mirrorLister) isEmptyExcluded() bool {
+	return l.excludeEmpty && l.content.Size == 0
+}
+
+// isETagDifferent - true if source and target both have an ETag and they differ. Objects uploaded
+// in parts have ETags of their own, those differ even if the data does not.
+func isETagDifferent(source, target *client.Content) bool {
+	sourceETag := strings.Trim(source.ETag, "\"")
+	targetETag := strings.Trim(target.ETag, "\"")
+	return sourceETag != "" && targetETag != "" && sourceETag != targetETag
+}
+
+// mirrorRemovals - keys only in target, spilled to a file until the source is fully listed.
+type mirrorRemovals struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// add - spill removal of diff, the spill file is created with the first one.
+func (r *mirrorRemovals) add(diff mirrorDiff) *probe.Error {
+	if r.file == nil {
+		file, err := newSpillFile()
+		if err != nil {
+			return err.Trace()
+		}
+		r.file = file
+		r.writer = bufio.NewWriter(file)
+		r.encoder = json.NewEncoder(r.writer)
+	}
+	if e := r.encoder.Encode(diff); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// send - send all spilled removals in the order they were added.
+func (r *mirrorRemovals) send(diffCh chan<- mirrorDiff) *probe.Error {
+	if r.file == nil {
+		return nil
+	}
+	if e := r.writer.Flush(); e != nil {
+		return probe.NewError(e)
+	}
+	if _, e := r.file.Seek(0, 0); e != nil {
+		return probe.NewError(e)
+	}
+	decoder := json.NewDecoder(bufio.NewReader(r.file))
+	for {
+		var diff mirrorDiff
+		e := decoder.Decode(&diff)
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		diffCh <- diff
+	}
+}
+
+// remove - remove the spill file and all removals in it.
+func (r *mirrorRemovals) remove() {
+	if r.file != nil {
+		removeSpillFile(r.file)
+		r.file = nil
+	}
+}
+
+// mirrorJoin - merge joins lexically sorted source and target listings, only the current entry
+// of each listing is held in memory. Keys available only in target are sent last if isRemove is set.
+// Entries of the same size differ if isCompareETag is set and their ETags differ.
+func mirrorJoin(source, target *mirrorLister, isRemove, isCompareETag bool, diffCh chan<- mirrorDiff) {
+	defer close(diffCh)
+
+	sourceFailed := false
+	// advance - advance a listing, any listing error is sent.
+	advance := func(l *mirrorLister) {
+		for {
+			err := l.next()
+			if err == nil {
+				return
+			}
+			if l == source {
+				// Unlisted source keys would appear to be only in target.
+				sourceFailed = true
+			}
+			if _, ok := err.ToGoError().(client.PathNotFound); ok && l == target {
+				// Target need not exist.
+				continue
+			}
+			diffCh <- mirrorDiff{Error: err}
+			if l.content == nil {
+				return
+			}
+		}
+	}
+	// Removals are held back in a spill file until the source is fully listed in order,
+	// an unsorted or failed source listing must never remove objects.
+	removals := new(mirrorRemovals)
+	defer removals.remove()
+	advance(source)
+	advance(target)
+	for source.content != nil || target.content != nil {
+		if source.unsorted || target.unsorted {
+			return
+		}
+		switch {
+		case target.content == nil || (source.content != nil && source.key < target.key):
+			if !source.isEmptyExcluded() {
+				diffCh <- mirrorDiff{Key: source.key, Source: source.content, Differ: differOnlyFirst}
+			}
+			advance(source)
+		case source.content == nil || target.key < source.key:
+			if isRemove {
+				if err := removals.add(mirrorDiff{Key: target.key, Target: target.content, Differ: differOnlySecond}); err != nil {
+					// Some removals are missing, none are sent.
+					diffCh <- mirrorDiff{Error: err.Trace(target.key)}
+					removals.remove()
+					isRemove = false
+				}
+			}
+			advance(target)
+		default:
+			switch {
+			case source.isEmptyExcluded():
+			case !target.content.Type.IsRegular():
+				// Source is never a folder.
+				diffCh <- mirrorDiff{Key: source.key, Source: source.content, Target: target.content, Differ: differType}
+			case source.content.Size != target.content.Size:
+				diffCh <- mirrorDiff{Key: source.key, Source: source.content, Target: target.content, Differ: differSize}
+			case isCompareETag && isETagDifferent(source.content, target.content):
+				diffCh <- mirrorDiff{Key: source.key, Source: source.content, Target: target.content, Differ: differETag}
+			}
+			advance(source)
+			advance(target)
+		}
+	}
+	if sourceFailed {
+		return
+	}
+	if err := removals.send(diffCh); err != nil {
+		diffCh <- mirrorDiff{Error: err.Trace()}
+	}
+}
+
+// mirrorTargetLister - lists the target at targetURL of alias targetAlias recursively in lexical
+// order, until doneCh is closed.
+type mirrorTargetLister func(targetAlias, targetURL string, doneCh <-chan struct{}) (<-chan *client.Content, *probe.Error)
+
+// listMirrorTarget - list the live target.
+func listMirrorTarget(targetAlias, targetURL string, doneCh <-chan struct{}) (<-chan *client.Content, *probe.Error) {
+	targetClient, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return nil, err.Trace(targetAlias, targetURL)
+	}
+	return targetClient.List(true, false, doneCh), nil
+}
+
+func deltaSourceTargets(sourceURL string, targetURL string, isForce, isIfNotPresent, isRemove, isCompareETag bool, excludes []string, filter contentFilter, listTarget mirrorTargetLister, mirrorURLsCh chan<- mirrorURLs) {
 	// source and targets are always directories
 	sourceSeparator := string(client.NewURL(sourceURL).Separator)
 	if !strings.HasSuffix(sourceURL, sourceSeparator) {
@@ -108,61 +417,80 @@ func deltaSourceTargets(sourceURL string, targetURL string, isForce bool, mirror
 
 	defer close(mirrorURLsCh)
 
-	objectDifferenceTarget, err := objectDifferenceFactory(targetAlias, targetURL)
+	sourceClient, err := newClientFromAlias(sourceAlias, sourceURL)
 	if err != nil {
-		mirrorURLsCh <- mirrorURLs{Error: err.Trace(targetAlias, targetURL)}
+		mirrorURLsCh <- mirrorURLs{Error: err.Trace(sourceAlias, sourceURL)}
 		return
 	}
-
-	sourceClient, err := newClientFromAlias(sourceAlias, sourceURL)
+	// Source and target are listed only once, both listings are in lexical order.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	targetCh, err := listTarget(targetAlias, targetURL, doneCh)
 	if err != nil {
-		mirrorURLsCh <- mirrorURLs{Error: err.Trace(sourceAlias, sourceURL)}
+		mirrorURLsCh <- mirrorURLs{Error: err.Trace(targetAlias, targetURL)}
 		return
 	}
+	// Dotfiles of ‘--exclude-hidden’ are excluded on both sides like patterns, the target keeps its own.
+	if filter.excludeHidden && sourceClient.GetURL().Type != client.Object {
+		excludes = append(excludes[:len(excludes):len(excludes)], ".*")
+	}
+	source := &mirrorLister{rootURL: sourceURL, ch: sourceClient.List(true, false, doneCh), excludes: excludes, excludeEmpty: filter.excludeEmpty}
+	target := &mirrorLister{rootURL: targetURL, ch: targetCh, excludes: excludes}
 
-	for sourceContent := range sourceClient.List(true, false) {
-		if sourceContent.Err != nil {
-			mirrorURLsCh <- mirrorURLs{
-				Error: sourceContent.Err.Trace(sourceClient.GetURL().String()),
-			}
+	diffCh := make(chan mirrorDiff)
+	go mirrorJoin(source, target, isRemove, isCompareETag, diffCh)
+	for diff := range diffCh {
+		if diff.Error != nil {
+			mirrorURLsCh <- mirrorURLs{Error: diff.Error.Trace(sourceURL, targetURL)}
 			continue
 		}
-		if sourceContent.Type.IsDir() {
-			continue
-		}
-		suffix := strings.TrimPrefix(sourceContent.URL.String(), sourceURL)
-		differ, err := objectDifferenceTarget(suffix, sourceContent.Type, sourceContent.Size)
-		if err != nil {
-			mirrorURLsCh <- mirrorURLs{Error: err.Trace(sourceContent.URL.String())}
+		switch diff.Differ {
+		case differType:
+			mirrorURLsCh <- mirrorURLs{Error: errInvalidTarget(diff.Key)}
 			continue
-		}
-		if differ == differNone {
-			// no difference, continue
-			continue
-		}
-		if differ == differType {
-			mirrorURLsCh <- mirrorURLs{Error: errInvalidTarget(suffix)}
-			continue
-		}
-		if differ == differSize && !isForce {
-			// size differs and force not set
-			mirrorURLsCh <- mirrorURLs{Error: errOverWriteNotAllowed(sourceContent.URL.String())}
+		case differSize, differETag:
+			if isIfNotPresent {
+				// present on target, kept as it is.
+				continue
+			}
+			if !isForce {
+				// size or ETag differs and force not set
+				mirrorURLsCh <- mirrorURLs{Error: errOverWriteNotAllowed(diff.Source.URL.String())}
+				continue
+			}
+		case differOnlySecond:
+			// available only in target, remove it.
+			mirrorURLsCh <- mirrorURLs{
+				TargetAlias:   targetAlias,
+				TargetContent: diff.Target,
+			}
 			continue
 		}
-		// either available only in source or size differs and force is set
-		targetPath := urlJoinPath(targetURL, suffix)
+		// either available only in source or size or ETag differs and force is set
+		targetPath := urlJoinSourcePath(targetURL, diff.Source.URL, strings.TrimPrefix(diff.Source.URL.String(), sourceURL))
 		targetContent := &client.Content{URL: *client.NewURL(targetPath)}
 		mirrorURLsCh <- mirrorURLs{
 			SourceAlias:   sourceAlias,
-			SourceContent: sourceContent,
+			SourceContent: diff.Source,
 			TargetAlias:   targetAlias,
 			TargetContent: targetContent,
 		}
 	}
 }
 
-func prepareMirrorURLs(sourceURL string, targetURL string, isForce bool) <-chan mirrorURLs {
+func prepareMirrorURLs(sourceURL string, targetURL string, isForce, isIfNotPresent, isRemove, isCompareETag bool, excludes []string, filter contentFilter) <-chan mirrorURLs {
 	mirrorURLsCh := make(chan mirrorURLs)
-	go deltaSourceTargets(sourceURL, targetURL, isForce, mirrorURLsCh)
+	go deltaSourceTargets(sourceURL, targetURL, isForce, isIfNotPresent, isRemove, isCompareETag, excludes, filter, listMirrorTarget, mirrorURLsCh)
+	return mirrorURLsCh
+}
+
+// prepareMirrorPlanURLs - mirror URLs as prepareMirrorURLs, with the target as recorded in the
+// ‘--manifest’ manifestFile instead of as listed.
+func prepareMirrorPlanURLs(sourceURL string, targetURL string, manifestFile string, isForce, isIfNotPresent, isRemove, isCompareETag bool, excludes []string, filter contentFilter) <-chan mirrorURLs {
+	mirrorURLsCh := make(chan mirrorURLs)
+	listTarget := func(targetAlias, targetURL string, doneCh <-chan struct{}) (<-chan *client.Content, *probe.Error) {
+		return listManifestTargets(manifestFile, targetURL, doneCh)
+	}
+	go deltaSourceTargets(sourceURL, targetURL, isForce, isIfNotPresent, isRemove, isCompareETag, excludes, filter, listTarget, mirrorURLsCh)
 	return mirrorURLsCh
 }
@@ -0,0 +1,529 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+// newTestMirrorLister - lister over objects of given sizes in given order under rootURL.
+func newTestMirrorLister(rootURL string, keys []string, sizes map[string]int64, excludes []string) *mirrorLister {
+	ch := make(chan *client.Content, len(keys))
+	for _, key := range keys {
+		ch <- &client.Content{
+			URL:  *client.NewURL(rootURL + key),
+			Size: sizes[key],
+			Type: os.FileMode(0664),
+		}
+	}
+	close(ch)
+	return &mirrorLister{rootURL: rootURL, ch: ch, excludes: excludes}
+}
+
+// collectMirrorJoin - run merge join and collect differences by key.
+func collectMirrorJoin(source, target *mirrorLister, isRemove bool) (map[string]string, int) {
+	diffCh := make(chan mirrorDiff)
+	go mirrorJoin(source, target, isRemove, false, diffCh)
+	diffs := make(map[string]string)
+	errs := 0
+	for diff := range diffCh {
+		if diff.Error != nil {
+			errs++
+			continue
+		}
+		diffs[diff.Key] = diff.Differ
+	}
+	return diffs, errs
+}
+
+func (s *TestSuite) TestMirrorJoinOverlapping(c *C) {
+	sizes := map[string]int64{"a.txt": 1, "a/b": 2, "c": 3, "d/e": 4}
+	source := newTestMirrorLister("https://s3.amazonaws.com/src/", []string{"a.txt", "a/b", "c"}, sizes, nil)
+	targetSizes := map[string]int64{"a.txt": 1, "a/b": 5, "d/e": 4}
+	target := newTestMirrorLister("https://s3.amazonaws.com/dst/", []string{"a.txt", "a/b", "d/e"}, targetSizes, nil)
+
+	diffs, errs := collectMirrorJoin(source, target, true)
+	c.Assert(errs, Equals, 0)
+	c.Assert(diffs, DeepEquals, map[string]string{
+		"a/b": differSize,
+		"c":   differOnlyFirst,
+		"d/e": differOnlySecond,
+	})
+}
+
+func (s *TestSuite) TestMirrorJoinDisjoint(c *C) {
+	sizes := map[string]int64{"a": 1, "b": 1, "c": 1, "d": 1}
+	source := newTestMirrorLister("/tmp/src/", []string{"a", "c"}, sizes, nil)
+	target := newTestMirrorLister("/tmp/dst/", []string{"b", "d"}, sizes, nil)
+
+	// Without remove, target only objects are left alone.
+	diffs, errs := collectMirrorJoin(source, target, false)
+	c.Assert(errs, Equals, 0)
+	c.Assert(diffs, DeepEquals, map[string]string{"a": differOnlyFirst, "c": differOnlyFirst})
+
+	source = newTestMirrorLister("/tmp/src/", []string{"a", "c"}, sizes, nil)
+	target = newTestMirrorLister("/tmp/dst/", []string{"b", "d"}, sizes, nil)
+	diffs, errs = collectMirrorJoin(source, target, true)
+	c.Assert(errs, Equals, 0)
+	c.Assert(diffs, DeepEquals, map[string]string{"a": differOnlyFirst, "b": differOnlySecond, "c": differOnlyFirst, "d": differOnlySecond})
+
+	// Empty target.
+	source = newTestMirrorLister("/tmp/src/", []string{"a", "c"}, sizes, nil)
+	target = newTestMirrorLister("/tmp/dst/", nil, sizes, nil)
+	diffs, errs = collectMirrorJoin(source, target, true)
+	c.Assert(errs, Equals, 0)
+	c.Assert(diffs, DeepEquals, map[string]string{"a": differOnlyFirst, "c": differOnlyFirst})
+}
+
+func (s *TestSuite) TestMirrorJoinExclude(c *C) {
+	sizes := map[string]int64{"a.tmp": 1, "b/c.tmp": 1, "b/d": 1, "e": 1}
+	excludes := []string{"*.tmp"}
+	source := newTestMirrorLister("/tmp/src/", []string{"a.tmp", "b/c.tmp", "b/d"}, sizes, excludes)
+	target := newTestMirrorLister("/tmp/dst/", []string{"b/c.tmp", "e"}, sizes, excludes)
+
+	diffs, errs := collectMirrorJoin(source, target, true)
+	c.Assert(errs, Equals, 0)
+	// Excluded objects are neither copied nor removed.
+	c.Assert(diffs, DeepEquals, map[string]string{"b/d": differOnlyFirst, "e": differOnlySecond})
+}
+
+func (s *TestSuite) TestMirrorJoinUnsorted(c *C) {
+	sizes := map[string]int64{"a": 1, "b": 1, "c": 1}
+	source := newTestMirrorLister("/tmp/src/", []string{"c", "a"}, sizes, nil)
+	target := newTestMirrorLister("/tmp/dst/", []string{"a", "b"}, sizes, nil)
+
+	// Out of order listing stops the join, nothing is removed.
+	diffs, errs := collectMirrorJoin(source, target, true)
+	c.Assert(errs, Equals, 1)
+	for _, differ := range diffs {
+		c.Assert(differ, Not(Equals), differOnlySecond)
+	}
+}
+
+func (s *TestSuite) TestMirrorJoinETag(c *C) {
+	// newLister - lister over objects of one byte with the given ETags, none if empty.
+	newLister := func(rootURL string, keys []string, etags map[string]string) *mirrorLister {
+		ch := make(chan *client.Content, len(keys))
+		for _, key := range keys {
+			ch <- &client.Content{URL: *client.NewURL(rootURL + key), Size: 1, Type: os.FileMode(0664), ETag: etags[key]}
+		}
+		close(ch)
+		return &mirrorLister{rootURL: rootURL, ch: ch}
+	}
+	keys := []string{"changed", "quoted", "same", "unknown"}
+	sourceETags := map[string]string{"changed": "1", "quoted": "\"2\"", "same": "3", "unknown": "4"}
+	targetETags := map[string]string{"changed": "5", "quoted": "2", "same": "3"}
+
+	for _, isCompareETag := range []bool{false, true} {
+		diffCh := make(chan mirrorDiff)
+		go mirrorJoin(newLister("s3/src/", keys, sourceETags), newLister("s3/dst/", keys, targetETags), false, isCompareETag, diffCh)
+		diffs := make(map[string]string)
+		for diff := range diffCh {
+			c.Assert(diff.Error, IsNil)
+			diffs[diff.Key] = diff.Differ
+		}
+		if !isCompareETag {
+			// Same size is no difference.
+			c.Assert(diffs, DeepEquals, map[string]string{})
+			continue
+		}
+		// Only ETags known on both sides are compared.
+		c.Assert(diffs, DeepEquals, map[string]string{"changed": differETag})
+	}
+}
+
+func (s *TestSuite) TestMirrorJoinRemovalsSpilled(c *C) {
+	spillDir, e := ioutil.TempDir("", "mc-mirror-spill-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(spillDir)
+	setTempDir(spillDir)
+	defer setTempDir("")
+
+	var targetKeys []string
+	sizes := make(map[string]int64)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("old/%04d", i)
+		targetKeys = append(targetKeys, key)
+		sizes[key] = int64(i)
+	}
+	source := newTestMirrorLister("/tmp/src/", []string{"new", "z"}, sizes, nil)
+	target := newTestMirrorLister("/tmp/dst/", targetKeys, sizes, nil)
+
+	diffCh := make(chan mirrorDiff)
+	go mirrorJoin(source, target, true, false, diffCh)
+	var removed []string
+	for diff := range diffCh {
+		c.Assert(diff.Error, IsNil)
+		if diff.Differ != differOnlySecond {
+			// Removals come only once the source is fully listed.
+			c.Assert(removed, IsNil)
+			continue
+		}
+		if removed == nil {
+			// Until then they wait in a spill file instead of in memory.
+			names, _ := filepath.Glob(filepath.Join(spillDir, "mc-spill-*"))
+			c.Assert(names, HasLen, 1)
+		}
+		c.Assert(diff.Target.URL.String(), Equals, "/tmp/dst/"+diff.Key)
+		c.Assert(diff.Target.Size, Equals, sizes[diff.Key])
+		removed = append(removed, diff.Key)
+	}
+	c.Assert(removed, DeepEquals, targetKeys)
+	// The spill file is removed once done.
+	names, e := filepath.Glob(filepath.Join(spillDir, "mc-spill-*"))
+	c.Assert(e, IsNil)
+	c.Assert(names, HasLen, 0)
+}
+
+func (s *TestSuite) TestPrepareMirrorURLsIfNotPresent(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mirror-if-not-present-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	source := filepath.Join(root, "source")
+	target := filepath.Join(root, "target")
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "a"), []byte("a"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(source, "b"), []byte("b"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(target, "b"), []byte("existing"), 0600), IsNil)
+
+	for _, isIfNotPresent := range []bool{false, true} {
+		var copied []string
+		errs := 0
+		for mURLs := range prepareMirrorURLs(source, target, false, isIfNotPresent, false, false, nil, contentFilter{}) {
+			if mURLs.Error != nil {
+				errs++
+				continue
+			}
+			copied = append(copied, filepath.Base(mURLs.SourceContent.URL.Path))
+		}
+		c.Assert(copied, DeepEquals, []string{"a"})
+		// Without --if-not-present the differing "b" is reported, with it "b" is kept silently.
+		if isIfNotPresent {
+			c.Assert(errs, Equals, 0)
+		} else {
+			c.Assert(errs, Equals, 1)
+		}
+	}
+}
+
+// decodeMirrorEvents - every line of stream as an event, the stream must be valid NDJSON.
+func decodeMirrorEvents(c *C, stream []byte) []mirrorEvent {
+	var events []mirrorEvent
+	scanner := bufio.NewScanner(bytes.NewReader(stream))
+	for scanner.Scan() {
+		var event mirrorEvent
+		c.Assert(json.Unmarshal(scanner.Bytes(), &event), IsNil, Commentf("line %q", scanner.Text()))
+		events = append(events, event)
+	}
+	return events
+}
+
+func (s *TestSuite) TestMirrorJSONEvents(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	savedJSON, savedWriter := globalJSON, mirrorEventWriter
+	defer func() { globalJSON, mirrorEventWriter = savedJSON, savedWriter }()
+	stream := new(bytes.Buffer)
+	globalJSON, mirrorEventWriter = true, stream
+
+	root, e := ioutil.TempDir("", "mc-mirror-events-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source") + string(filepath.Separator)
+	target := filepath.Join(root, "target") + string(filepath.Separator)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	for i := 0; i < 12; i++ {
+		name := filepath.Join(source, fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%d", i))
+		c.Assert(os.MkdirAll(filepath.Dir(name), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(name, []byte(fmt.Sprintf("contents of file %d", i)), 0600), IsNil)
+	}
+
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	defer session.Delete()
+	session.Header.CommandType = "mirror"
+	session.Header.CommandArgs = []string{source, target}
+	summary := doMirrorSession(session)
+	c.Assert(summary.exitCode(), Equals, 0)
+
+	// Every object gets a copying and a single terminal event, the summary ends the stream.
+	events := decodeMirrorEvents(c, stream.Bytes())
+	c.Assert(len(events) > 0, Equals, true)
+	last := events[len(events)-1]
+	c.Assert(last.Event, Equals, mirrorEventSummary)
+	c.Assert(last.Summary, NotNil)
+	c.Assert(last.Summary.Transferred, Equals, 12)
+	c.Assert(last.Summary.Failed, Equals, 0)
+	copying := make(map[string]int)
+	terminal := make(map[string]int)
+	for _, event := range events[:len(events)-1] {
+		c.Assert(event.Status, Equals, "success")
+		switch event.Event {
+		case mirrorEventCopying:
+			copying[event.Source]++
+		case mirrorEventCopied:
+			terminal[event.Source]++
+			c.Assert(event.Size > 0, Equals, true)
+		default:
+			c.Fatalf("unexpected event %q", event.Event)
+		}
+	}
+	c.Assert(terminal, HasLen, 12)
+	for source, count := range terminal {
+		c.Assert(count, Equals, 1)
+		c.Assert(copying[source], Equals, 1)
+	}
+}
+
+func (s *TestSuite) TestMirrorJSONFailedEvent(c *C) {
+	savedJSON := globalJSON
+	defer func() { globalJSON = savedJSON }()
+	globalJSON = true
+	stream := new(bytes.Buffer)
+	events := newMirrorEventStream(stream)
+	root, e := ioutil.TempDir("", "mc-mirror-events-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	sURLs := mirrorURLs{
+		SourceContent: &client.Content{URL: *client.NewURL(filepath.Join(root, "missing")), Size: 4},
+		TargetContent: &client.Content{URL: *client.NewURL(filepath.Join(root, "target"))},
+	}
+	queueCh := make(chan bool, 1)
+	queueCh <- true
+	statusCh := make(chan mirrorURLs, 1)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	doMirror(context.Background(), sURLs, newTestSession(), nil, newAccounter(4), events, nil, queueCh, wg, statusCh)
+	c.Assert((<-statusCh).Error, NotNil)
+
+	decoded := decodeMirrorEvents(c, stream.Bytes())
+	c.Assert(decoded, HasLen, 1)
+	c.Assert(decoded[0].Event, Equals, mirrorEventFailed)
+	c.Assert(decoded[0].Status, Equals, "error")
+	c.Assert(decoded[0].Error, Not(Equals), "")
+	c.Assert(decoded[0].Size, Equals, int64(4))
+
+	// A nil stream, the human output, writes nothing.
+	var none *mirrorEventStream
+	none.object(mirrorEventCopied, sURLs, nil)
+}
+
+// readManifest - every entry of the manifest filename.
+func readManifest(c *C, filename string) []manifestEntry {
+	data, e := ioutil.ReadFile(filename)
+	c.Assert(e, IsNil)
+	var entries []manifestEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry manifestEntry
+		c.Assert(json.Unmarshal(scanner.Bytes(), &entry), IsNil, Commentf("line %q", scanner.Text()))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (s *TestSuite) TestMirrorManifest(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	savedJSON, savedWriter := globalJSON, mirrorEventWriter
+	defer func() { globalJSON, mirrorEventWriter = savedJSON, savedWriter }()
+	globalJSON, mirrorEventWriter = true, new(bytes.Buffer)
+
+	root, e := ioutil.TempDir("", "mc-mirror-manifest-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source") + string(filepath.Separator)
+	target := filepath.Join(root, "target") + string(filepath.Separator)
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	c.Assert(os.MkdirAll(target, 0700), IsNil)
+	for i := 0; i < 4; i++ {
+		name := filepath.Join(source, fmt.Sprintf("file%d", i))
+		c.Assert(ioutil.WriteFile(name, []byte(fmt.Sprintf("contents of file %d", i)), 0600), IsNil)
+	}
+	manifestFile := filepath.Join(root, "manifest.jsonl")
+
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	defer session.Delete()
+	session.Header.CommandType = "mirror"
+	session.Header.CommandArgs = []string{source, target}
+	session.Header.CommandStringFlags["manifest"] = manifestFile
+	summary := doMirrorSession(session)
+	c.Assert(summary.exitCode(), Equals, 0)
+
+	// Every object copied is recorded once, with its size at copy time.
+	entries := readManifest(c, manifestFile)
+	c.Assert(entries, HasLen, 4)
+	for _, entry := range entries {
+		st, e := os.Stat(entry.Source)
+		c.Assert(e, IsNil)
+		c.Assert(entry.Size, Equals, st.Size())
+		c.Assert(entry.Target, Equals, filepath.Join(target, filepath.Base(entry.Source)))
+		msg, err := verifyManifestEntry(entry)
+		c.Assert(err, IsNil)
+		c.Assert(msg.Result, Equals, manifestMatched)
+	}
+	mismatches, err := doVerifyManifest(manifestFile)
+	c.Assert(err, IsNil)
+	c.Assert(mismatches, Equals, 0)
+
+	// A target rewritten with other contents drifts, a removed one is missing.
+	c.Assert(ioutil.WriteFile(filepath.Join(target, "file1"), []byte("changed"), 0600), IsNil)
+	c.Assert(os.Remove(filepath.Join(target, "file2")), IsNil)
+	results := make(map[string]string)
+	for _, entry := range entries {
+		msg, err := verifyManifestEntry(entry)
+		c.Assert(err, IsNil)
+		results[filepath.Base(entry.Target)] = msg.Result
+	}
+	c.Assert(results, DeepEquals, map[string]string{
+		"file0": manifestMatched,
+		"file1": manifestDrifted,
+		"file2": manifestMissing,
+		"file3": manifestMatched,
+	})
+	mismatches, err = doVerifyManifest(manifestFile)
+	c.Assert(err, IsNil)
+	c.Assert(mismatches, Equals, 2)
+}
+
+func (s *TestSuite) TestMirrorManifestChangedSource(c *C) {
+	handler := &objectStoreHandler{objects: make(map[string]storedObject)}
+	restore := useMetadataServer(c, handler)
+	defer restore()
+	savedJSON := globalJSON
+	defer func() { globalJSON = savedJSON }()
+	globalJSON = true
+
+	root, e := ioutil.TempDir("", "mc-mirror-manifest-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	c.Assert(ioutil.WriteFile(source, []byte("grown since listed"), 0600), IsNil)
+	_, targetURL, _, err := expandAlias("meta/bucket/object")
+	c.Assert(err, IsNil)
+
+	manifestFile := filepath.Join(root, "manifest.jsonl")
+	session := newTestSession()
+	session.Header.CommandStringFlags = map[string]string{"manifest": manifestFile}
+	manifest, err := openMirrorManifest(session)
+	c.Assert(err, IsNil)
+
+	// The source was listed before it grew, the manifest records what was actually copied.
+	sURLs := mirrorURLs{
+		SourceContent: &client.Content{URL: *client.NewURL(source), Size: 4},
+		TargetAlias:   "meta",
+		TargetContent: &client.Content{URL: *client.NewURL(targetURL)},
+	}
+	queueCh := make(chan bool, 1)
+	queueCh <- true
+	statusCh := make(chan mirrorURLs, 1)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	doMirror(context.Background(), sURLs, session, nil, newAccounter(4), nil, manifest, queueCh, wg, statusCh)
+	c.Assert((<-statusCh).Error, IsNil)
+	manifest.Close()
+	c.Assert(string(handler.objects["/bucket/object"].data), Equals, "grown since listed")
+
+	entries := readManifest(c, manifestFile)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Size, Equals, int64(len("grown since listed")))
+	c.Assert(entries[0].TargetAlias, Equals, "meta")
+	c.Assert(entries[0].TargetETag, Equals, "etag")
+	msg, err := verifyManifestEntry(entries[0])
+	c.Assert(err, IsNil)
+	c.Assert(msg.Result, Equals, manifestMatched)
+
+	// A target which no longer has the recorded ETag drifted, even at the same size.
+	entries[0].TargetETag = "other"
+	msg, err = verifyManifestEntry(entries[0])
+	c.Assert(err, IsNil)
+	c.Assert(msg.Result, Equals, manifestDrifted)
+	c.Assert(msg.Reason, Equals, "ETag ‘etag’, expected ‘other’")
+}
+
+func (s *TestSuite) TestMirrorPlanAgainstManifest(c *C) {
+	root, e := ioutil.TempDir("", "mc-mirror-plan-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	for name, data := range map[string]string{"a": "a", "b": "b", "c": "cc"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(data), 0600), IsNil)
+	}
+	// The target is never listed, it need not even be reachable.
+	target := filepath.Join(root, "target")
+	manifestFile := filepath.Join(root, "manifest.jsonl")
+	var manifest bytes.Buffer
+	for _, entry := range []manifestEntry{
+		{Source: filepath.Join(source, "d"), Target: filepath.Join(target, "d"), Size: 3},
+		{Source: filepath.Join(source, "c"), Target: filepath.Join(target, "c"), Size: 2},
+		{Source: filepath.Join(source, "b"), Target: filepath.Join(target, "b"), Size: 1},
+		// Copied again since, the last record counts.
+		{Source: filepath.Join(source, "c"), Target: filepath.Join(target, "c"), Size: 5},
+		// Another target is left alone.
+		{Source: filepath.Join(source, "a"), Target: filepath.Join(root, "other", "a"), Size: 1},
+	} {
+		entryBytes, e := json.Marshal(entry)
+		c.Assert(e, IsNil)
+		manifest.Write(append(entryBytes, '\n'))
+	}
+	c.Assert(ioutil.WriteFile(manifestFile, manifest.Bytes(), 0600), IsNil)
+
+	plan := func(isForce, isRemove bool) []dryRunMessage {
+		var buffer bytes.Buffer
+		savedOutput, savedJSON := color.Output, globalJSON
+		color.Output, globalJSON = &buffer, true
+		defer func() { color.Output, globalJSON = savedOutput, savedJSON }()
+		doMirrorDryRun(prepareMirrorPlanURLs(source, target, manifestFile, isForce, false, isRemove, false, nil, contentFilter{}), isCopiedFactory(""))
+
+		var messages []dryRunMessage
+		for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			var message dryRunMessage
+			c.Assert(json.Unmarshal([]byte(line), &message), IsNil)
+			message.Status = ""
+			messages = append(messages, message)
+		}
+		return messages
+	}
+	c.Assert(plan(true, true), DeepEquals, []dryRunMessage{
+		{Operation: "copy", Source: filepath.Join(source, "a"), Target: filepath.Join(target, "a"), Size: 1},
+		{Operation: "copy", Source: filepath.Join(source, "c"), Target: filepath.Join(target, "c"), Size: 2},
+		{Operation: "remove", Target: filepath.Join(target, "d")},
+	})
+	// Without --remove nothing is removed.
+	c.Assert(plan(true, false), DeepEquals, []dryRunMessage{
+		{Operation: "copy", Source: filepath.Join(source, "a"), Target: filepath.Join(target, "a"), Size: 1},
+		{Operation: "copy", Source: filepath.Join(source, "c"), Target: filepath.Join(target, "c"), Size: 2},
+	})
+}
@@ -0,0 +1,128 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// newerThanFileCutoff - modification time of the ‘--newer-than-file’ reference file, only objects
+// modified after it are copied. Zero if there is no reference file yet, everything is copied.
+func newerThanFileCutoff(referenceFile string) (time.Time, *probe.Error) {
+	if referenceFile == "" {
+		return time.Time{}, nil
+	}
+	st, e := os.Stat(referenceFile)
+	if os.IsNotExist(e) {
+		return time.Time{}, nil
+	}
+	if e != nil {
+		return time.Time{}, probe.NewError(e)
+	}
+	if !st.Mode().IsRegular() {
+		return time.Time{}, errInvalidArgument().Trace(referenceFile)
+	}
+	return st.ModTime(), nil
+}
+
+// setSessionNewerThan - save the reference file and its cutoff to session, a resumed session
+// copies the objects of the original cutoff.
+func setSessionNewerThan(session *sessionV6, referenceFile string, cutoff time.Time) *probe.Error {
+	if referenceFile == "" {
+		return nil
+	}
+	referenceFilePath, e := filepath.Abs(referenceFile)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	session.Header.CommandStringFlags["newer-than-file"] = referenceFilePath
+	if !cutoff.IsZero() {
+		session.Header.CommandStringFlags["newer-than"] = cutoff.UTC().Format(time.RFC3339Nano)
+	}
+	return nil
+}
+
+// getSessionNewerThan - cutoff saved in session, zero if everything is copied.
+func getSessionNewerThan(session *sessionV6) time.Time {
+	cutoff, e := time.Parse(time.RFC3339Nano, session.Header.CommandStringFlags["newer-than"])
+	if e != nil {
+		return time.Time{}
+	}
+	return cutoff
+}
+
+// isNewerThan - content was modified after cutoff, always true for a zero cutoff.
+func isNewerThan(content *client.Content, cutoff time.Time) bool {
+	return cutoff.IsZero() || content == nil || content.Time.After(cutoff)
+}
+
+// filterNewerCopyURLs - copy URLs of sources modified after cutoff, errors are passed on.
+func filterNewerCopyURLs(URLsCh <-chan copyURLs, cutoff time.Time) <-chan copyURLs {
+	if cutoff.IsZero() {
+		return URLsCh
+	}
+	newerCh := make(chan copyURLs)
+	go func() {
+		defer close(newerCh)
+		for cpURLs := range URLsCh {
+			if cpURLs.Error != nil || isNewerThan(cpURLs.SourceContent, cutoff) {
+				newerCh <- cpURLs
+			}
+		}
+	}()
+	return newerCh
+}
+
+// filterNewerMirrorURLs - mirror URLs of sources modified after cutoff, removals and errors are passed on.
+func filterNewerMirrorURLs(URLsCh <-chan mirrorURLs, cutoff time.Time) <-chan mirrorURLs {
+	if cutoff.IsZero() {
+		return URLsCh
+	}
+	newerCh := make(chan mirrorURLs)
+	go func() {
+		defer close(newerCh)
+		for sURLs := range URLsCh {
+			if sURLs.Error != nil || isNewerThan(sURLs.SourceContent, cutoff) {
+				newerCh <- sURLs
+			}
+		}
+	}()
+	return newerCh
+}
+
+// touchNewerThanFile - set the modification time of the reference file of session to the time the
+// session started, creating the file on the first run. The next run copies what was modified since.
+func touchNewerThanFile(session *sessionV6) *probe.Error {
+	referenceFile := session.Header.CommandStringFlags["newer-than-file"]
+	if referenceFile == "" {
+		return nil
+	}
+	file, e := os.OpenFile(referenceFile, os.O_WRONLY|os.O_CREATE, 0644)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	file.Close()
+	if e = os.Chtimes(referenceFile, session.Header.When, session.Header.When); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCopyNewerThanFile(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-newer-than-file-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	source := filepath.Join(root, "source")
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	hourAgo := time.Now().Add(-time.Hour)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+		c.Assert(os.Chtimes(filepath.Join(source, name), hourAgo, hourAgo), IsNil)
+	}
+	reference := filepath.Join(root, "last-backup")
+	target := filepath.Join(root, "target")
+
+	// copyNewer - copy source with ‘--newer-than-file’ into an empty target, returns the names copied.
+	copyNewer := func() []string {
+		c.Assert(os.RemoveAll(target), IsNil)
+		cutoff, err := newerThanFileCutoff(reference)
+		c.Assert(err, IsNil)
+		session := newTestCopySession(c, []string{source + string(os.PathSeparator)}, target+string(os.PathSeparator), 1, false)
+		defer session.Delete()
+		session.Header.CommandBoolFlags["recursive"] = true
+		c.Assert(setSessionNewerThan(session, reference, cutoff), IsNil)
+		c.Assert(doCopySession(session).exitCode(), Equals, 0)
+		// The reference file is moved to the start of the copy.
+		st, e := os.Stat(reference)
+		c.Assert(e, IsNil)
+		c.Assert(st.ModTime().Equal(session.Header.When), Equals, true)
+
+		var names []string
+		files, _ := ioutil.ReadDir(target)
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		return names
+	}
+
+	// Without a reference file everything is copied, and the file is created.
+	c.Assert(copyNewer(), DeepEquals, []string{"a.txt", "b.txt"})
+	// Nothing changed since.
+	c.Assert(copyNewer(), IsNil)
+	// Only what changed after the last copy started is copied.
+	minuteAhead := time.Now().Add(time.Minute)
+	c.Assert(os.Chtimes(filepath.Join(source, "b.txt"), minuteAhead, minuteAhead), IsNil)
+	c.Assert(copyNewer(), DeepEquals, []string{"b.txt"})
+
+	// A folder is no reference file.
+	_, err := newerThanFileCutoff(root)
+	c.Assert(err, Not(IsNil))
+}
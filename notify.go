@@ -0,0 +1,96 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// notifyTimeout - maximum time spent posting a summary to the ‘--notify’ URL.
+var notifyTimeout = 10 * time.Second
+
+// notifyMessage container for the summary posted to the ‘--notify’ URL once a session ends.
+type notifyMessage struct {
+	summaryMessage
+	SessionID string `json:"sessionId"`
+}
+
+// checkNotifyURL - only http(s) URLs without credentials are accepted.
+func checkNotifyURL(urlStr string) *probe.Error {
+	u, e := url.Parse(urlStr)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errInvalidNotifyURL(urlStr, "only http and https are supported").Trace(u.Scheme)
+	}
+	if u.Host == "" {
+		return errInvalidNotifyURL(urlStr, "host is missing").Trace()
+	}
+	if u.User != nil {
+		// Do not echo the credentials back.
+		return errInvalidNotifyURL(u.Scheme+"://"+u.Host, "credentials are not allowed").Trace()
+	}
+	return nil
+}
+
+// postNotify - POST msg as JSON to urlStr, giving up after notifyTimeout.
+func postNotify(urlStr string, msg notifyMessage) *probe.Error {
+	body, e := json.Marshal(msg)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	httpClient := &http.Client{Timeout: notifyTimeout}
+	resp, e := httpClient.Post(urlStr, "application/json", bytes.NewReader(body))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return probe.NewError(errors.New("Notify URL responded with ‘" + resp.Status + "’."))
+	}
+	return nil
+}
+
+// notifySummary - post the summary of a session to its ‘--notify’ URL, if set. Failing
+// to notify only warns, the outcome of the command is not changed.
+func notifySummary(session *sessionV6, summary *transferSummary, isInterrupted bool) {
+	notifyURL := session.Header.CommandStringFlags["notify"]
+	if notifyURL == "" {
+		return
+	}
+	msg := notifyMessage{
+		summaryMessage: summary.Message(session),
+		SessionID:      session.SessionID,
+	}
+	switch {
+	case isInterrupted:
+		msg.Status = "interrupted"
+	case msg.Failed > 0:
+		msg.Status = "failure"
+	default:
+		msg.Status = "success"
+	}
+	errorIf(postNotify(notifyURL, msg).Trace(), "Unable to notify the summary of session ‘"+session.SessionID+"’.")
+}
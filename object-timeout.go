@@ -0,0 +1,65 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// getSessionTimeouts - ‘--object-timeout’ and ‘--total-timeout’ saved in session, zero if not set.
+func getSessionTimeouts(session *sessionV6) (objectTimeout, totalTimeout time.Duration) {
+	objectTimeout, _ = time.ParseDuration(session.Header.CommandStringFlags["object-timeout"])
+	totalTimeout, _ = time.ParseDuration(session.Header.CommandStringFlags["total-timeout"])
+	return objectTimeout, totalTimeout
+}
+
+// newTotalContext - context of the whole transfer, done once totalTimeout passed. Never done if
+// totalTimeout is zero, until cancelled.
+func newTotalContext(totalTimeout time.Duration) (context.Context, context.CancelFunc) {
+	if totalTimeout > 0 {
+		return context.WithTimeout(context.Background(), totalTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// newObjectContext - context of a single attempt to transfer an object, done once objectTimeout
+// passed or ctx of the whole transfer is done. Every attempt starts a new objectTimeout.
+func newObjectContext(ctx context.Context, objectTimeout time.Duration) (context.Context, context.CancelFunc) {
+	if objectTimeout > 0 {
+		return context.WithTimeout(ctx, objectTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// transferTimeoutError - err of the attempt of objectCtx to transfer the object at url, reported as
+// the timeout which failed it. Only a timed out object may be retried, the whole transfer stops
+// once totalCtx is done.
+func transferTimeoutError(totalCtx, objectCtx context.Context, url string, objectTimeout, totalTimeout time.Duration, err *probe.Error) *probe.Error {
+	switch {
+	case err == nil:
+		return nil
+	case totalCtx.Err() == context.DeadlineExceeded:
+		return errTotalTimeout(totalTimeout).Trace(url)
+	case objectCtx.Err() == context.DeadlineExceeded:
+		return probe.NewError(client.ObjectTimeout{URL: url, Timeout: objectTimeout})
+	}
+	return err
+}
@@ -0,0 +1,278 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Output formats of ‘--output’.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTable = "table"
+)
+
+// outputRenderer - renders messages of every command in one output format.
+type outputRenderer interface {
+	// Render - lines to print for msg, empty if nothing is printed for it yet.
+	Render(msg message) string
+	// Flush - lines held back until all messages are rendered, such as the rows of a table.
+	Flush() string
+}
+
+// outputRenderers - every output format of ‘--output’, a format added here applies to all commands.
+var outputRenderers = map[string]func() outputRenderer{
+	outputText:  func() outputRenderer { return textRenderer{} },
+	outputJSON:  func() outputRenderer { return jsonRenderer{} },
+	outputCSV:   func() outputRenderer { return &csvRenderer{} },
+	outputTable: func() outputRenderer { return &tableRenderer{} },
+}
+
+// Format and renderer of ‘--output’, empty and nil for text or JSON output as ‘--json’ says.
+var (
+	globalOutputFormat   string
+	globalOutputRenderer outputRenderer
+)
+
+// getOutputRenderer - renderer of messages printed by printMsg.
+func getOutputRenderer() outputRenderer {
+	if globalOutputRenderer != nil {
+		return globalOutputRenderer
+	}
+	if globalJSON {
+		return jsonRenderer{}
+	}
+	return textRenderer{}
+}
+
+// setOutput - set the output format of ‘--output’, text or JSON as ‘--json’ says if empty.
+func setOutput(format string) {
+	globalOutputFormat, globalOutputRenderer = "", nil
+	if format == "" {
+		return
+	}
+	newRenderer, ok := outputRenderers[format]
+	if !ok {
+		fatalIf(errInvalidArgument().Trace(format), "Unknown output format ‘"+format+"’, use one of "+strings.Join(outputFormats(), ", ")+".")
+	}
+	if globalJSON && format != outputJSON {
+		fatalIf(errInvalidArgument().Trace(format), "Option --json cannot be used with --output "+format+".")
+	}
+	switch format {
+	case outputJSON:
+		// Commands leave out progress bars and prompts for JSON output.
+		globalJSON = true
+	case outputText:
+	default:
+		// Progress bars would break up the rows of messages.
+		globalQuiet = true
+	}
+	globalOutputFormat, globalOutputRenderer = format, newRenderer()
+}
+
+// outputFromContext prefers the command level ‘--output’ over the global one.
+func outputFromContext(ctx *cli.Context) string {
+	if format := ctx.String("output"); format != "" {
+		return format
+	}
+	return ctx.GlobalString("output")
+}
+
+// outputFormats - sorted names of all output formats.
+func outputFormats() []string {
+	var formats []string
+	for format := range outputRenderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// flushOutput - print what the renderer held back, before mc exits.
+func flushOutput() {
+	if globalOutputRenderer == nil {
+		return
+	}
+	if lines := globalOutputRenderer.Flush(); lines != "" {
+		fmt.Println(lines)
+	}
+}
+
+// textRenderer - colorized messages for the console.
+type textRenderer struct{}
+
+func (textRenderer) Render(msg message) string { return msg.String() }
+func (textRenderer) Flush() string             { return "" }
+
+// jsonRenderer - a JSON object per message, ‘--filter’ selects messages or fields of them.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(msg message) string {
+	if globalFilter == nil {
+		return msg.JSON()
+	}
+	filtered, ok, err := filterJSON(globalFilter, msg.JSON())
+	fatalIf(err.Trace(), "Unable to filter JSON output.")
+	if !ok {
+		return ""
+	}
+	return filtered
+}
+
+func (jsonRenderer) Flush() string { return "" }
+
+// messageFields - top level fields of the JSON of msg, in the order of the message. Nested
+// objects and arrays are kept as JSON. A message filtered to a single value is one field ‘value’.
+func messageFields(msg message) (keys, values []string, ok bool) {
+	jsonStr := jsonRenderer{}.Render(msg)
+	if jsonStr == "" {
+		return nil, nil, false
+	}
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	token, e := decoder.Token()
+	fatalIf(probe.NewError(e), "Unable to decode JSON message.")
+	if delim, isDelim := token.(json.Delim); !isDelim || delim != '{' {
+		return []string{"value"}, []string{jsonFieldValue(json.RawMessage(jsonStr))}, true
+	}
+	for decoder.More() {
+		keyToken, e := decoder.Token()
+		fatalIf(probe.NewError(e), "Unable to decode JSON message.")
+		var value json.RawMessage
+		fatalIf(probe.NewError(decoder.Decode(&value)), "Unable to decode JSON message.")
+		keys = append(keys, keyToken.(string))
+		values = append(values, jsonFieldValue(value))
+	}
+	return keys, values, true
+}
+
+// jsonFieldValue - strings without quotes, null as empty, anything else as JSON.
+func jsonFieldValue(value json.RawMessage) string {
+	var str string
+	if json.Unmarshal(value, &str) == nil {
+		return str
+	}
+	if string(value) == "null" {
+		return ""
+	}
+	return string(value)
+}
+
+// csvRenderer - a CSV record per message, preceded by a header whenever the fields change.
+type csvRenderer struct {
+	header []string
+}
+
+func (r *csvRenderer) Render(msg message) string {
+	keys, values, ok := messageFields(msg)
+	if !ok {
+		return ""
+	}
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if strings.Join(keys, ",") != strings.Join(r.header, ",") {
+		writer.Write(keys)
+		r.header = keys
+	}
+	writer.Write(values)
+	writer.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (r *csvRenderer) Flush() string { return "" }
+
+// tableRenderer - messages as rows of aligned columns, printed once all are rendered. Each
+// type of message has a table of its own, with a column for every field any of them has.
+type tableRenderer struct {
+	tables []*messageTable
+}
+
+// messageTable - rows of messages of one type.
+type messageTable struct {
+	msgType string
+	columns []string
+	rows    []map[string]string
+}
+
+func (r *tableRenderer) Render(msg message) string {
+	keys, values, ok := messageFields(msg)
+	if !ok {
+		return ""
+	}
+	var table *messageTable
+	msgType := fmt.Sprintf("%T", msg)
+	for _, t := range r.tables {
+		if t.msgType == msgType {
+			table = t
+		}
+	}
+	if table == nil {
+		table = &messageTable{msgType: msgType}
+		r.tables = append(r.tables, table)
+	}
+	row := make(map[string]string)
+	for i, key := range keys {
+		if !containsString(table.columns, key) {
+			table.columns = append(table.columns, key)
+		}
+		// Tabs and line breaks within a value would break up its row.
+		row[key] = strings.Join(strings.Fields(values[i]), " ")
+	}
+	table.rows = append(table.rows, row)
+	return ""
+}
+
+func (r *tableRenderer) Flush() string {
+	var buf bytes.Buffer
+	for i, table := range r.tables {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writer := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(table.columns, "\t")))
+		for _, row := range table.rows {
+			values := make([]string, len(table.columns))
+			for j, column := range table.columns {
+				values[j] = row[column]
+			}
+			fmt.Fprintln(writer, strings.Join(values, "\t"))
+		}
+		writer.Flush()
+	}
+	r.tables = nil
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// containsString - true if s is one of strs.
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestOutputRenderers(c *C) {
+	when := time.Date(2015, time.November, 2, 10, 30, 0, 0, time.UTC)
+	msgs := []message{
+		shareMesssage{ObjectURL: "s3/backup/2006-Mar-1/backup.tar.gz", ShareURL: "https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz?X-Amz-Signature=abc", TimeLeft: time.Hour},
+		shareMesssage{ObjectURL: "s3/backup/2007-Mar-2/", ShareURL: "curl https://s3.amazonaws.com/backup -F key=2007-Mar-2/<NAME>", TimeLeft: time.Hour,
+			ContentType: "image/", MaxSize: 1024, KeyPrefix: "2007-Mar-2/"},
+		sessionMessage{SessionID: "HiMnJqzL", Time: when, CommandType: "cp", CommandArgs: []string{"backup/", "s3/archive/"}},
+		contentMessage{Filetype: "file", Time: when, Size: 2048, Key: "backup.tar.gz"},
+		rmMessage{Status: "success", URL: "s3/backup/old.tar.gz"},
+	}
+	for _, msg := range msgs {
+		c.Assert(textRenderer{}.Render(msg), Equals, msg.String())
+		c.Assert(jsonRenderer{}.Render(msg), Equals, msg.JSON())
+
+		// CSV and table output carry every field of the JSON message, in its order.
+		var fields map[string]interface{}
+		c.Assert(json.Unmarshal([]byte(msg.JSON()), &fields), IsNil)
+		records, e := csv.NewReader(strings.NewReader((&csvRenderer{}).Render(msg))).ReadAll()
+		c.Assert(e, IsNil)
+		c.Assert(len(records), Equals, 2)
+		c.Assert(len(records[0]), Equals, len(fields))
+		c.Assert(records[0][0], Equals, "status")
+		for i, key := range records[0] {
+			_, ok := fields[key]
+			c.Assert(ok, Equals, true, Commentf("%T field %s", msg, key))
+			if str, isString := fields[key].(string); isString {
+				c.Assert(records[1][i], Equals, str)
+			}
+		}
+
+		table := &tableRenderer{}
+		c.Assert(table.Render(msg), Equals, "")
+		lines := strings.Split(table.Flush(), "\n")
+		c.Assert(len(lines), Equals, 2)
+		c.Assert(strings.Fields(lines[0]), DeepEquals, strings.Fields(strings.ToUpper(strings.Join(records[0], " "))))
+		c.Assert(table.Flush(), Equals, "")
+	}
+}
+
+func (s *TestSuite) TestTableRenderer(c *C) {
+	table := &tableRenderer{}
+	table.Render(rmMessage{Status: "success", URL: "s3/backup/a"})
+	table.Render(clearSessionMessage{Status: "success", SessionID: "HiMnJqzL"})
+	table.Render(rmMessage{Status: "success", URL: "s3/backup/b", VersionID: "3HL4kqtJ"})
+	// Columns are aligned, messages of each type are in a table of their own.
+	c.Assert(table.Flush(), Equals, strings.Join([]string{
+		"STATUS   URL          VERSIONID",
+		"success  s3/backup/a  ",
+		"success  s3/backup/b  3HL4kqtJ",
+		"",
+		"STATUS   SESSIONID",
+		"success  HiMnJqzL",
+	}, "\n"))
+
+	csvOutput := &csvRenderer{}
+	c.Assert(csvOutput.Render(rmMessage{Status: "success", URL: "s3/backup/a"}), Equals, "status,url\nsuccess,s3/backup/a")
+	c.Assert(csvOutput.Render(rmMessage{Status: "success", URL: "s3/backup/b,c"}), Equals, "success,\"s3/backup/b,c\"")
+	c.Assert(csvOutput.Render(clearSessionMessage{Status: "success", SessionID: "HiMnJqzL"}), Equals, "status,sessionId\nsuccess,HiMnJqzL")
+}
@@ -0,0 +1,264 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	pingFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of ping.",
+		},
+		cli.IntFlag{
+			Name:  "count",
+			Value: 4,
+			Usage: "Number of probes to send.",
+		},
+		cli.DurationFlag{
+			Name:  "interval",
+			Value: time.Second,
+			Usage: "Wait between probes.",
+		},
+	}
+)
+
+// check if an alias is reachable with its credentials.
+var pingCmd = cli.Command{
+	Name:   "ping",
+	Usage:  "Check if a host is reachable and accepts its credentials.",
+	Action: mainPing,
+	Flags:  append(pingFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] ALIAS
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Check if the host of alias ‘s3’ is reachable.
+      $ mc {{.Name}} s3
+
+   2. Send ten probes, half a second apart.
+      $ mc {{.Name}} --count 10 --interval 500ms play
+
+   3. Check a host from a script, one JSON message per probe.
+      $ mc --json {{.Name}} --count 1 myminio
+`,
+}
+
+// pingMessage container for the result of a single probe.
+type pingMessage struct {
+	Status    string  `json:"status"`
+	Alias     string  `json:"alias"`
+	Sequence  int     `json:"sequence"`
+	Latency   float64 `json:"latencyMs,omitempty"`
+	Region    string  `json:"region,omitempty"`
+	Signature string  `json:"signature,omitempty"`
+	ErrorType string  `json:"errorType,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// String colorized ping message.
+func (p pingMessage) String() string {
+	if p.Status != "success" {
+		return console.Colorize("PingError", fmt.Sprintf("%s: seq=%d %s error: %s", p.Alias, p.Sequence, p.ErrorType, p.Error))
+	}
+	return console.Colorize("Ping", fmt.Sprintf("%s: seq=%d time=%.1f ms region=%s signature=%s",
+		p.Alias, p.Sequence, p.Latency, p.Region, p.Signature))
+}
+
+// JSON jsonified ping message.
+func (p pingMessage) JSON() string {
+	pingJSONBytes, e := json.Marshal(p)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(pingJSONBytes)
+}
+
+// pingSummaryMessage container for latencies over all probes, in milliseconds.
+type pingSummaryMessage struct {
+	Status   string  `json:"status"`
+	Alias    string  `json:"alias"`
+	Sent     int     `json:"sent"`
+	Received int     `json:"received"`
+	Min      float64 `json:"minMs"`
+	Avg      float64 `json:"avgMs"`
+	Max      float64 `json:"maxMs"`
+}
+
+// String colorized ping summary message.
+func (p pingSummaryMessage) String() string {
+	message := console.Colorize("Ping", "--- "+p.Alias+" ping statistics ---") + "\n"
+	message += fmt.Sprintf("%d probes sent, %d succeeded, %d%% failed", p.Sent, p.Received, (p.Sent-p.Received)*100/p.Sent)
+	if p.Received > 0 {
+		message += fmt.Sprintf("\nmin/avg/max = %.1f/%.1f/%.1f ms", p.Min, p.Avg, p.Max)
+	}
+	return message
+}
+
+// JSON jsonified ping summary message.
+func (p pingSummaryMessage) JSON() string {
+	p.Status = "summary"
+	pingJSONBytes, e := json.Marshal(p)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(pingJSONBytes)
+}
+
+// Validate command line arguments.
+func checkPingSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "ping", 1) // last argument is exit code
+	}
+	if ctx.Int("count") < 1 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "At least one probe must be sent.")
+	}
+	if ctx.Duration("interval") < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Interval must not be negative.")
+	}
+}
+
+// pingErrorType - class of a failed probe. TLS and connection failures are
+// told apart from rejected credentials.
+func pingErrorType(err *probe.Error) string {
+	switch exitCode(err) {
+	case exitAccessDenied:
+		return "auth"
+	case exitNetworkFailure:
+		if isTLSError(err.ToGoError()) {
+			return "tls"
+		}
+		return "network"
+	}
+	return "error"
+}
+
+// pingHost - a single probe, listing buckets is the lightest request every host answers.
+func pingHost(clnt client.Client) (time.Duration, *probe.Error) {
+	start := time.Now()
+	_, err := clnt.Stat()
+	return time.Since(start), err
+}
+
+// pingAlias - probe the host of alias count times, printing every probe. The
+// error of the last failed probe, if any, is returned along with the summary.
+func pingAlias(alias string, hostCfg hostConfigV7, count int, interval time.Duration) (pingSummaryMessage, *probe.Error) {
+	summary := pingSummaryMessage{Alias: alias}
+	clnt, err := newS3ClientFromHost(hostCfg, hostCfg.URL, nil)
+	if err != nil {
+		return summary, err.Trace(alias)
+	}
+	// Region and signature version are settled once the client is set up.
+	var region, signature string
+	if negotiated, ok := clnt.(interface {
+		Region() string
+		SignatureVersion() string
+	}); ok {
+		region, signature = negotiated.Region(), negotiated.SignatureVersion()
+	}
+	anonymous := hostCfg.AccessKey == "" && hostCfg.SecretKey == ""
+
+	var lastErr *probe.Error
+	var total time.Duration
+	for seq := 1; seq <= count; seq++ {
+		if seq > 1 {
+			time.Sleep(interval)
+		}
+		summary.Sent++
+		latency, err := pingHost(clnt)
+		if err != nil {
+			errorType := pingErrorType(err)
+			// Anonymous hosts usually do not allow listing buckets, server replying is good enough.
+			if !anonymous || errorType == "network" || errorType == "tls" {
+				lastErr = err.Trace(alias)
+				printMsg(pingMessage{
+					Status:    "error",
+					Alias:     alias,
+					Sequence:  seq,
+					ErrorType: errorType,
+					Error:     err.ToGoError().Error(),
+				})
+				continue
+			}
+		}
+		ms := float64(latency) / float64(time.Millisecond)
+		if summary.Received == 0 || ms < summary.Min {
+			summary.Min = ms
+		}
+		if ms > summary.Max {
+			summary.Max = ms
+		}
+		summary.Received++
+		total += latency
+		summary.Avg = float64(total) / float64(time.Millisecond) / float64(summary.Received)
+		printMsg(pingMessage{
+			Status:    "success",
+			Alias:     alias,
+			Sequence:  seq,
+			Latency:   ms,
+			Region:    region,
+			Signature: signature,
+		})
+	}
+	return summary, lastErr
+}
+
+// mainPing is entry point for ping command.
+func mainPing(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'ping' cli arguments.
+	checkPingSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Ping", color.New(color.FgGreen, color.Bold))
+	console.SetColor("PingError", color.New(color.FgRed, color.Bold))
+
+	alias := strings.TrimSuffix(ctx.Args().First(), "/")
+	hostCfg, err := getHostConfig(alias)
+	fatalIf(err.Trace(alias), "Unable to find alias ‘"+alias+"’.")
+
+	summary, err := pingAlias(alias, *hostCfg, ctx.Int("count"), ctx.Duration("interval"))
+	if summary.Sent == 0 {
+		fatalIf(err.Trace(alias), "Unable to initialize host ‘"+alias+"’.")
+	}
+	printMsg(summary)
+	switch {
+	case summary.Received == 0:
+		fatalIf(err.Trace(alias), "Unable to reach host ‘"+alias+"’.")
+	case summary.Received < summary.Sent:
+		flushOutput()
+		os.Exit(exitPartialFailure)
+	}
+}
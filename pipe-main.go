@@ -17,19 +17,34 @@
 package main
 
 import (
+	"io"
 	"os"
+	"strings"
 	"syscall"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
+// Part size limits of a multipart upload.
+const (
+	pipeDefaultPartSize = 64 * humanize.MiByte
+	pipeMinPartSize     = 5 * humanize.MiByte
+	pipeMaxPartSize     = 5 * humanize.GiByte
+)
+
 var (
 	pipeFlags = []cli.Flag{
 		cli.BoolFlag{
 			Name:  "help, h",
 			Usage: "Help of pipe.",
 		},
+		cli.StringFlag{
+			Name:  "part-size",
+			Value: humanize.IBytes(pipeDefaultPartSize),
+			Usage: "Size of each uploaded part, held in memory while uploading. Largest object is 10000 parts.",
+		},
 	}
 )
 
@@ -60,10 +75,25 @@ EXAMPLES:
 
    4. Stream MySQL database dump to Amazon S3 directly.
       $ mysqldump -u root -p ******* accountsdb | mc {{.Name}} s3/ferenginar/backups/accountsdb-oct-9-2015.sql
+
+   5. Stream a large backup in 512MiB parts, allowing objects up to 5TB.
+      $ tar cz /var/lib/data | mc {{.Name}} --part-size 512MiB s3/ferenginar/backups/data.tar.gz
 `,
 }
 
-func pipe(targetURL string) *probe.Error {
+// parsePipePartSize parses and validates the part size for streaming uploads.
+func parsePipePartSize(partSizeStr string) (int64, *probe.Error) {
+	partSize, e := humanize.ParseBytes(strings.TrimSpace(partSizeStr))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	if partSize < pipeMinPartSize || partSize > pipeMaxPartSize {
+		return 0, errInvalidArgument().Trace(partSizeStr)
+	}
+	return int64(partSize), nil
+}
+
+func pipe(targetURL string, partSize int64) *probe.Error {
 	if targetURL == "" {
 		// When no target is specified, pipe cat's stdin to stdout.
 		return catOut(os.Stdin).Trace()
@@ -72,7 +102,16 @@ func pipe(targetURL string) *probe.Error {
 	// Stream from stdin to multiple objects until EOF.
 	// Ignore size, since os.Stat() would not return proper size all the time
 	// for local filesystem for example /proc files.
-	err := putTarget(targetURL, os.Stdin, -1)
+	var reader io.ReadSeeker = os.Stdin
+	if !globalQuiet {
+		// Size is unknown, progress is rendered as a spinner.
+		renderer := newProgressRenderer()
+		defer renderer.Stop()
+		stdinReader := renderer.NewProxyReader(targetURL, os.Stdin, -1)
+		defer renderer.Done(stdinReader)
+		reader = stdinReader
+	}
+	err := putTargetStream(targetURL, reader, partSize)
 	// TODO: See if this check is necessary.
 	switch e := err.ToGoError().(type) {
 	case *os.PathError:
@@ -89,6 +128,9 @@ func checkPipeSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) > 1 {
 		cli.ShowCommandHelpAndExit(ctx, "pipe", 1) // last argument is exit code.
 	}
+	if _, err := parsePipePartSize(ctx.String("part-size")); err != nil {
+		fatalIf(err.Trace(), "Invalid part size ‘"+ctx.String("part-size")+"’, must be between 5MiB and 5GiB.")
+	}
 }
 
 // mainPipe is the main entry point for pipe command.
@@ -99,13 +141,14 @@ func mainPipe(ctx *cli.Context) {
 	// validate pipe input arguments.
 	checkPipeSyntax(ctx)
 
+	partSize, _ := parsePipePartSize(ctx.String("part-size"))
 	if len(ctx.Args()) == 0 {
-		err := pipe("")
+		err := pipe("", partSize)
 		fatalIf(err.Trace("stdout"), "Unable to write to one or more targets.")
 	} else {
 		// extract URLs.
 		URLs := ctx.Args()
-		err := pipe(URLs[0])
+		err := pipe(URLs[0], partSize)
 		fatalIf(err.Trace(URLs[0]), "Unable to write to one or more targets.")
 	}
 }
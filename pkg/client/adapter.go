@@ -0,0 +1,282 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Error - a *probe.Error as a standard error for code outside of mc. Unwrap returns
+// the original cause, errors.Is and errors.As see the typed errors of this package
+// and of the S3 API, such as minio.ErrorResponse with its error code.
+type Error struct {
+	Err *probe.Error
+}
+
+func (e *Error) Error() string {
+	return e.Err.ToGoError().Error()
+}
+
+// Unwrap - the original cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err.ToGoError()
+}
+
+// ToError - err as a standard error, nil if err is nil.
+func ToError(err *probe.Error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Err: err}
+}
+
+// GoClient adapts a Client to standard error handling, each method returns the error of
+// the Client method it calls wrapped by ToError. Contents listed keep their *probe.Error,
+// use ToError(content.Err) to convert it.
+type GoClient struct {
+	clnt Client
+}
+
+// NewGoClient - adapt clnt to standard error handling.
+func NewGoClient(clnt Client) *GoClient {
+	return &GoClient{clnt: clnt}
+}
+
+// Client - the adapted client.
+func (g *GoClient) Client() Client {
+	return g.clnt
+}
+
+// Stat - see Client.
+func (g *GoClient) Stat() (*Content, error) {
+	content, err := g.clnt.Stat()
+	return content, ToError(err)
+}
+
+// List - see Client.
+func (g *GoClient) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *Content {
+	return g.clnt.List(recursive, incomplete, doneCh)
+}
+
+// ListVersions - see Client.
+func (g *GoClient) ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *Content {
+	return g.clnt.ListVersions(recursive, doneCh)
+}
+
+// ListParts - see Client.
+func (g *GoClient) ListParts(uploadID string, doneCh <-chan struct{}) <-chan *Content {
+	return g.clnt.ListParts(uploadID, doneCh)
+}
+
+// ListPage - see Client.
+func (g *GoClient) ListPage(recursive bool, startAfter string, maxKeys int) ([]*Content, string, error) {
+	contents, marker, err := g.clnt.ListPage(recursive, startAfter, maxKeys)
+	return contents, marker, ToError(err)
+}
+
+// MakeBucket - see Client.
+func (g *GoClient) MakeBucket() error {
+	return ToError(g.clnt.MakeBucket())
+}
+
+// RemoveBucket - see Client.
+func (g *GoClient) RemoveBucket() error {
+	return ToError(g.clnt.RemoveBucket())
+}
+
+// GetBucketAccess - see Client.
+func (g *GoClient) GetBucketAccess() (string, error) {
+	access, err := g.clnt.GetBucketAccess()
+	return access, ToError(err)
+}
+
+// SetBucketAccess - see Client.
+func (g *GoClient) SetBucketAccess(access string) error {
+	return ToError(g.clnt.SetBucketAccess(access))
+}
+
+// GetBucketVersioning - see Client.
+func (g *GoClient) GetBucketVersioning() (string, error) {
+	status, err := g.clnt.GetBucketVersioning()
+	return status, ToError(err)
+}
+
+// SetBucketVersioning - see Client.
+func (g *GoClient) SetBucketVersioning(enable bool) error {
+	return ToError(g.clnt.SetBucketVersioning(enable))
+}
+
+// GetBucketAccelerate - see Client.
+func (g *GoClient) GetBucketAccelerate() (string, error) {
+	status, err := g.clnt.GetBucketAccelerate()
+	return status, ToError(err)
+}
+
+// SetBucketAccelerate - see Client.
+func (g *GoClient) SetBucketAccelerate(enable bool) error {
+	return ToError(g.clnt.SetBucketAccelerate(enable))
+}
+
+// GetBucketReplication - see Client.
+func (g *GoClient) GetBucketReplication() (ReplicationConfig, error) {
+	config, err := g.clnt.GetBucketReplication()
+	return config, ToError(err)
+}
+
+// SetBucketReplication - see Client.
+func (g *GoClient) SetBucketReplication(config ReplicationConfig) error {
+	return ToError(g.clnt.SetBucketReplication(config))
+}
+
+// GetBucketPolicy - see Client.
+func (g *GoClient) GetBucketPolicy() (string, error) {
+	policy, err := g.clnt.GetBucketPolicy()
+	return policy, ToError(err)
+}
+
+// SetBucketPolicy - see Client.
+func (g *GoClient) SetBucketPolicy(policy string) error {
+	return ToError(g.clnt.SetBucketPolicy(policy))
+}
+
+// GetAnonymousAccess - see Client.
+func (g *GoClient) GetAnonymousAccess() (string, error) {
+	access, err := g.clnt.GetAnonymousAccess()
+	return access, ToError(err)
+}
+
+// GetBucketRegion - see Client.
+func (g *GoClient) GetBucketRegion() (string, error) {
+	region, err := g.clnt.GetBucketRegion()
+	return region, ToError(err)
+}
+
+// Get - see Client.
+func (g *GoClient) Get(offset, length int64, versionID string) (io.ReadSeeker, error) {
+	body, err := g.clnt.Get(offset, length, versionID)
+	return body, ToError(err)
+}
+
+// GetIfChanged - see Client.
+func (g *GoClient) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, error) {
+	body, err := g.clnt.GetIfChanged(etag, modTime)
+	return body, ToError(err)
+}
+
+// Put - see Client.
+func (g *GoClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) error {
+	return ToError(g.clnt.Put(data, size, contentType, metadata))
+}
+
+// PutStream - see Client.
+func (g *GoClient) PutStream(data io.Reader, partSize int64, contentType string) error {
+	return ToError(g.clnt.PutStream(data, partSize, contentType))
+}
+
+// ShareDownload - see Client.
+func (g *GoClient) ShareDownload(expires time.Duration) (string, error) {
+	url, err := g.clnt.ShareDownload(expires)
+	return url, ToError(err)
+}
+
+// ShareUpload - see Client.
+func (g *GoClient) ShareUpload(options ShareUploadOptions) (map[string]string, error) {
+	formData, err := g.clnt.ShareUpload(options)
+	return formData, ToError(err)
+}
+
+// Remove - see Client.
+func (g *GoClient) Remove(incomplete bool, versionID string) error {
+	return ToError(g.clnt.Remove(incomplete, versionID))
+}
+
+// RemoveBatch - see Client.
+func (g *GoClient) RemoveBatch(contentCh <-chan *Content) <-chan *Content {
+	return g.clnt.RemoveBatch(contentCh)
+}
+
+// RemoveUploads - see Client.
+func (g *GoClient) RemoveUploads(contentCh <-chan *Content) <-chan *Content {
+	return g.clnt.RemoveUploads(contentCh)
+}
+
+// GetTags - see Client.
+func (g *GoClient) GetTags() (map[string]string, error) {
+	tags, err := g.clnt.GetTags()
+	return tags, ToError(err)
+}
+
+// SetTags - see Client.
+func (g *GoClient) SetTags(tags map[string]string) error {
+	return ToError(g.clnt.SetTags(tags))
+}
+
+// GetRetention - see Client.
+func (g *GoClient) GetRetention() (string, time.Time, error) {
+	mode, retainUntil, err := g.clnt.GetRetention()
+	return mode, retainUntil, ToError(err)
+}
+
+// SetRetention - see Client.
+func (g *GoClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) error {
+	return ToError(g.clnt.SetRetention(mode, retainUntil, bypassGovernance))
+}
+
+// GetLegalHold - see Client.
+func (g *GoClient) GetLegalHold() (bool, error) {
+	enabled, err := g.clnt.GetLegalHold()
+	return enabled, ToError(err)
+}
+
+// SetLegalHold - see Client.
+func (g *GoClient) SetLegalHold(enabled bool) error {
+	return ToError(g.clnt.SetLegalHold(enabled))
+}
+
+// SetMetadata - see Client.
+func (g *GoClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) error {
+	return ToError(g.clnt.SetMetadata(contentType, cacheControl, metadata))
+}
+
+// RestoreVersion - see Client.
+func (g *GoClient) RestoreVersion(versionID string) error {
+	return ToError(g.clnt.RestoreVersion(versionID))
+}
+
+// RestoreArchived - see Client.
+func (g *GoClient) RestoreArchived(days int, tier string) error {
+	return ToError(g.clnt.RestoreArchived(days, tier))
+}
+
+// Copy - see Client.
+func (g *GoClient) Copy(source URL, options CopyOptions) error {
+	return ToError(g.clnt.Copy(source, options))
+}
+
+// GetURL - see Client.
+func (g *GoClient) GetURL() URL {
+	return g.clnt.GetURL()
+}
+
+// WithContext - see Client.
+func (g *GoClient) WithContext(ctx context.Context) *GoClient {
+	return &GoClient{clnt: g.clnt.WithContext(ctx)}
+}
@@ -17,10 +17,13 @@
 package client
 
 import (
+	"context"
 	"io"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/minio/mc/pkg/httptracer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -28,26 +31,98 @@ import (
 type Client interface {
 	// Common operations
 	Stat() (content *Content, err *probe.Error)
-	List(recursive, incomplete bool) <-chan *Content
+	// List stops listing and closes the channel once doneCh is closed, a nil doneCh never stops listing.
+	List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *Content
+	// ListVersions lists all versions and delete markers of objects, newest version of each object first.
+	ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *Content
+	// ListParts lists the parts uploaded so far to the incomplete upload uploadID of this object.
+	ListParts(uploadID string, doneCh <-chan struct{}) <-chan *Content
+	// ListPage lists one page of at most maxKeys objects and folders under the URL, starting after the
+	// key startAfter. The marker returned lists the next page as startAfter, it is empty on the last page.
+	ListPage(recursive bool, startAfter string, maxKeys int) (contents []*Content, marker string, err *probe.Error)
 
 	// Bucket operations
 	MakeBucket() *probe.Error
+	// RemoveBucket removes the bucket, or the folder on a filesystem. It fails with BucketNotEmpty
+	// while objects remain.
+	RemoveBucket() *probe.Error
 	GetBucketAccess() (access string, error *probe.Error)
 	SetBucketAccess(access string) *probe.Error
+	GetBucketVersioning() (status string, error *probe.Error)
+	SetBucketVersioning(enable bool) *probe.Error
+	// GetBucketAccelerate returns the Transfer Acceleration state of the bucket, "Enabled", "Suspended"
+	// or empty if never enabled.
+	GetBucketAccelerate() (status string, error *probe.Error)
+	SetBucketAccelerate(enable bool) *probe.Error
+	// GetBucketReplication returns the replication rules of the bucket, none if it is not replicated.
+	GetBucketReplication() (config ReplicationConfig, err *probe.Error)
+	// SetBucketReplication replaces the replication rules of the bucket, a config without rules removes them.
+	SetBucketReplication(config ReplicationConfig) *probe.Error
+	// GetBucketPolicy returns the JSON policy document of the bucket, empty if none is set.
+	GetBucketPolicy() (policy string, error *probe.Error)
+	// SetBucketPolicy replaces the JSON policy document of the bucket, an empty policy removes it.
+	SetBucketPolicy(policy string) *probe.Error
+	// GetAnonymousAccess returns the access everyone has to all objects under the URL, one of the
+	// Anonymous constants. Object storage grants it by bucket policy, a filesystem by permissions.
+	GetAnonymousAccess() (access string, err *probe.Error)
+	// GetBucketRegion returns the region the bucket is located in.
+	GetBucketRegion() (region string, err *probe.Error)
 
-	// I/O operations
-	Get(offset, length int64) (body io.ReadSeeker, err *probe.Error)
-	Put(data io.ReadSeeker, size int64, contentType string) *probe.Error
+	// I/O operations, an empty versionID is the latest version
+	Get(offset, length int64, versionID string) (body io.ReadSeeker, err *probe.Error)
+	// GetIfChanged gets the object only if it changed from a copy with etag, or without an etag,
+	// if it was modified since modTime. An unchanged object fails with NotModified.
+	GetIfChanged(etag string, modTime time.Time) (body io.ReadSeeker, err *probe.Error)
+	// Put stores metadata along with data, a nil metadata stores none.
+	Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error
+	// PutStream holds at most partSize bytes of data in memory.
+	PutStream(data io.Reader, partSize int64, contentType string) *probe.Error
 
 	// I/O operations with expiration
 	ShareDownload(expires time.Duration) (string, *probe.Error)
-	ShareUpload(bool, time.Duration, string) (map[string]string, *probe.Error)
+	ShareUpload(options ShareUploadOptions) (map[string]string, *probe.Error)
 
-	// Delete operations
-	Remove(incomplete bool) *probe.Error
+	// Delete operations, a non empty versionID permanently removes that version
+	Remove(incomplete bool, versionID string) *probe.Error
+	// RemoveBatch removes every object read from contentCh, each is sent back with Err set if it failed.
+	// Contents with a VersionID have that version removed permanently instead.
+	RemoveBatch(contentCh <-chan *Content) <-chan *Content
+	// RemoveUploads aborts every incomplete upload read from contentCh by its UploadID, leaving other
+	// uploads to the same object alone. Each is sent back with Err set if it failed.
+	RemoveUploads(contentCh <-chan *Content) <-chan *Content
+
+	// Tagging operations
+	GetTags() (map[string]string, *probe.Error)
+	SetTags(tags map[string]string) *probe.Error
+
+	// ACL operations, SetObjectACL sets the canned ACL of acl if it has one, else its grants.
+	GetObjectACL() (ACL, *probe.Error)
+	SetObjectACL(acl ACL) *probe.Error
+
+	// Object lock operations, an empty mode means the object has no retention.
+	GetRetention() (mode string, retainUntil time.Time, err *probe.Error)
+	// SetRetention shortens or removes a GOVERNANCE retention only if bypassGovernance is set.
+	SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error
+	GetLegalHold() (enabled bool, err *probe.Error)
+	SetLegalHold(enabled bool) *probe.Error
+
+	// SetMetadata replaces content type, cache control and user metadata of an object in place.
+	SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error
+	// RestoreVersion makes versionID the latest version of an object again, keeping all versions.
+	RestoreVersion(versionID string) *probe.Error
+	// RestoreArchived initiates the restore of an object of an archive storage class, its copy is
+	// kept for days. Stat tells once the restore completed.
+	RestoreArchived(days int, tier string) *probe.Error
+	// Copy copies the object at source, on the same host, to this URL server side. Metadata
+	// and tags are copied or replaced as options say.
+	Copy(source URL, options CopyOptions) *probe.Error
 
 	// GetURL returns back internal url
 	GetURL() URL
+
+	// WithContext returns a client whose operations are cancelled once ctx is done, listings
+	// stop and close their channel as if doneCh was closed.
+	WithContext(ctx context.Context) Client
 }
 
 // Content container for content metadata
@@ -57,6 +132,246 @@ type Content struct {
 	Size int64
 	Type os.FileMode
 	Err  *probe.Error
+
+	// Set by Stat on object storage, changes whenever the object does.
+	ETag         string
+	ContentType  string
+	CacheControl string
+	// Set by Stat on object storage, the file name suggested for downloads.
+	ContentDisposition string
+	// Set by Stat on object storage, gzip for objects stored compressed.
+	ContentEncoding string
+	// Set by Stat and List on object storage, ETag is also set by List.
+	StorageClass string
+
+	// Set by Stat, user metadata on object storage, file attributes on a filesystem.
+	Metadata map[string]string
+	// Set by Stat and List on a filesystem, device and inode of files with more than one hard link.
+	HardlinkID string
+	// Set by List on object storage if the listing names the owner, by Stat and List on a filesystem
+	// as uid:gid along with the names of user and group.
+	OwnerID          string
+	OwnerDisplayName string
+
+	// Set by Stat on object storage, zero if unknown. Expires is the Expires header of the
+	// object, ExpiryDate the date a lifecycle rule expires it, that of ExpiryRuleID.
+	Expires      time.Time
+	ExpiryDate   time.Time
+	ExpiryRuleID string
+
+	// Set by Stat on object storage if a restore of the archived object was requested. The restored
+	// copy is available until RestoreExpiryDate once the restore is no longer ongoing.
+	RestoreRequested  bool
+	RestoreOngoing    bool
+	RestoreExpiryDate time.Time
+
+	// Set only for contents listed by ListVersions, VersionID also by Stat of objects of versioned buckets.
+	VersionID      string
+	IsLatest       bool
+	IsDeleteMarker bool
+
+	// Set only for incomplete uploads listed by List.
+	UploadID string
+
+	// Set only for buckets whose region is looked up along with the listing.
+	Region string
+}
+
+// MaxShareUploadSize - largest object a POST upload accepts, 5GiB.
+const MaxShareUploadSize = 5 * 1024 * 1024 * 1024
+
+// ReplicationRule - objects under Prefix are replicated to the bucket Destination, an ARN such as
+// "arn:aws:s3:::backup". Of all rules matching an object the one of the highest Priority applies.
+type ReplicationRule struct {
+	ID           string `json:"id"`
+	Priority     int    `json:"priority"`
+	Prefix       string `json:"prefix"`
+	Destination  string `json:"destination"`
+	StorageClass string `json:"storageClass,omitempty"`
+	Enabled      bool   `json:"enabled"`
+	// DeleteMarkers replicates delete markers as well.
+	DeleteMarkers bool `json:"deleteMarkers"`
+}
+
+// ReplicationConfig - replication rules of a bucket, Role is the ARN of the role objects are replicated as.
+type ReplicationConfig struct {
+	Role  string            `json:"role,omitempty"`
+	Rules []ReplicationRule `json:"rules"`
+}
+
+// Grant - Permission granted on an object, one of "FULL_CONTROL", "READ", "WRITE", "READ_ACP" or
+// "WRITE_ACP". The grantee is a "CanonicalUser" of ID, an "AmazonCustomerByEmail" of Email or a
+// "Group" of URI, as Type tells.
+type Grant struct {
+	Type        string `json:"type"`
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Permission  string `json:"permission"`
+}
+
+// ACL - owner and grants of an object. Canned is a canned ACL such as "public-read", set in place
+// of the grants.
+type ACL struct {
+	OwnerID          string  `json:"ownerId,omitempty"`
+	OwnerDisplayName string  `json:"ownerDisplayName,omitempty"`
+	Grants           []Grant `json:"grants,omitempty"`
+	Canned           string  `json:"canned,omitempty"`
+}
+
+// Directives of server side copies, what a copy keeps of its source.
+const (
+	DirectiveCopy    = "COPY"
+	DirectiveReplace = "REPLACE"
+)
+
+// CopyOptions - what a server side copy keeps of its source, the zero value keeps its
+// content type, metadata and tags.
+type CopyOptions struct {
+	// MetadataDirective is DirectiveCopy or DirectiveReplace, a replaced copy gets ContentType
+	// and Metadata, cache control among it.
+	MetadataDirective string
+	ContentType       string
+	Metadata          map[string]string
+	// TaggingDirective is DirectiveCopy or DirectiveReplace, a replaced copy gets Tags.
+	TaggingDirective string
+	Tags             map[string]string
+}
+
+// ShareUploadOptions - conditions of the POST policy ShareUpload signs.
+type ShareUploadOptions struct {
+	Expires time.Duration
+	// Recursive accepts any key starting with the object name of the URL, else only that key.
+	Recursive bool
+	// ContentType uploads must be sent with, one ending in "/" such as "image/" accepts any subtype.
+	ContentType string
+	// Size range of uploads in bytes, MaxSize of zero accepts up to MaxShareUploadSize.
+	MinSize int64
+	MaxSize int64
+}
+
+// Validate - rejects options no upload could meet.
+func (o ShareUploadOptions) Validate() *probe.Error {
+	if o.Expires < time.Second {
+		return probe.NewError(InvalidUploadCondition{Reason: "Expiry cannot be lesser than 1 second."})
+	}
+	if o.MinSize < 0 || o.MaxSize < 0 {
+		return probe.NewError(InvalidUploadCondition{Reason: "Size range cannot be negative."})
+	}
+	if o.MaxSize > MaxShareUploadSize {
+		return probe.NewError(InvalidUploadCondition{Reason: "Size range cannot exceed 5GiB, the most a POST upload accepts."})
+	}
+	if o.MaxSize > 0 && o.MinSize > o.MaxSize {
+		return probe.NewError(InvalidUploadCondition{Reason: "Minimum size is larger than the maximum size."})
+	}
+	if o.ContentType != "" {
+		mediaType := strings.SplitN(o.ContentType, "/", 2)
+		if len(mediaType) != 2 || mediaType[0] == "" || strings.ContainsAny(o.ContentType, " *") {
+			return probe.NewError(InvalidUploadCondition{Reason: "Content type ‘" + o.ContentType + "’ is not of the form TYPE/SUBTYPE or TYPE/."})
+		}
+	}
+	return nil
+}
+
+// Effective anonymous access reported by GetAnonymousAccess.
+const (
+	AnonymousNone      = "none"
+	AnonymousReadOnly  = "readonly"
+	AnonymousWriteOnly = "writeonly"
+	AnonymousReadWrite = "readwrite"
+)
+
+// AnonymousAccess - anonymous access allowing reads and or writes.
+func AnonymousAccess(read, write bool) string {
+	switch {
+	case read && write:
+		return AnonymousReadWrite
+	case read:
+		return AnonymousReadOnly
+	case write:
+		return AnonymousWriteOnly
+	}
+	return AnonymousNone
+}
+
+// Metadata keys under which file attributes are preserved, object storage
+// keeps them as "x-amz-meta-mode", "x-amz-meta-mtime" and "x-amz-meta-symlink-target".
+const (
+	MetadataMode          = "mode"           // permission bits, octal.
+	MetadataMtime         = "mtime"          // modification time, RFC3339 with nanoseconds.
+	MetadataSymlinkTarget = "symlink-target" // target of a symlink stored as an empty object.
+)
+
+// MetadataCacheControl - metadata key Put stores as the Cache-Control header of an object, not as
+// user metadata. Stat reports it as CacheControl.
+const MetadataCacheControl = "cache-control"
+
+// ForwardContents - forwards listed contents to contentCh until doneCh is closed, closes contentCh upon return.
+// Contents listed after doneCh is closed are drained, so that the listing routine is never blocked.
+func ForwardContents(listCh <-chan *Content, contentCh chan<- *Content, doneCh <-chan struct{}) {
+	defer close(contentCh)
+	for content := range listCh {
+		select {
+		case contentCh <- content:
+		case <-doneCh:
+			for range listCh {
+			}
+			return
+		}
+	}
+}
+
+// ContextDone - doneCh merged with the done channel of ctx, a nil ctx is never done. stop must
+// be called once the listing using it returned.
+func ContextDone(ctx context.Context, doneCh <-chan struct{}) (mergedCh <-chan struct{}, stop func()) {
+	if ctx == nil || ctx.Done() == nil {
+		return doneCh, func() {}
+	}
+	if doneCh == nil {
+		return ctx.Done(), func() {}
+	}
+	ch := make(chan struct{})
+	stopCh := make(chan struct{})
+	go func() {
+		select {
+		case <-doneCh:
+			close(ch)
+		case <-ctx.Done():
+			close(ch)
+		case <-stopCh:
+		}
+	}()
+	return ch, func() { close(stopCh) }
+}
+
+// contextReader - fails reads with the error of ctx once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	io.ReadSeeker
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if e := r.ctx.Err(); e != nil {
+		return 0, e
+	}
+	return r.ReadSeeker.Read(p)
+}
+
+// Close - closes the underlying reader if it is a closer.
+func (r contextReader) Close() error {
+	if closer, ok := r.ReadSeeker.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// NewContextReader - reader failing with the error of ctx once ctx is done, r itself if ctx is nil.
+func NewContextReader(ctx context.Context, r io.ReadSeeker) io.ReadSeeker {
+	if ctx == nil {
+		return r
+	}
+	return contextReader{ctx, r}
 }
 
 // Config - see http://docs.amazonwebservices.com/AmazonS3/latest/dev/index.html?RESTAuthentication.html
@@ -69,4 +384,49 @@ type Config struct {
 	AppVersion  string
 	AppComments []string
 	Debug       bool
+	ConnTimeout time.Duration // dial and TLS handshake timeout.
+	ReadTimeout time.Duration // wait for response headers.
+	IdleTimeout time.Duration // max stall of a transfer, reset on progress.
+	Region      string        // signing region, looked up from the host if empty.
+	BasePath    string        // path the host serves S3 under, such as "/s3".
+	// Appended to the User-Agent, to tell requests of a job or host apart in access logs.
+	UserAgentSuffix string
+	// Sent as the x-amz-request-correlation header of every request, none if empty.
+	CorrelationID string
+	// Idle connections are kept for reuse this long, the client default if zero.
+	KeepAliveTimeout time.Duration
+	// Connections to a single host, unlimited if zero.
+	MaxConnsPerHost int
+	// Resolve the host on every dial, instead of reusing its addresses for a short while.
+	DisableDNSCache bool
+	// Called once the server reports the local clock is off by offset, request times are corrected from then on.
+	ClockSkewed func(endpoint string, offset time.Duration)
+	// Parts of a single object uploaded in parallel, the client default if zero.
+	PartConcurrency int
+	// SSE-C key of objects read and written, nil for unencrypted objects.
+	EncryptionKey []byte
+	// Upload objects of any size with a single PUT, limited to 5GB.
+	DisableMultipart bool
+	// Storage class of uploaded objects, the server default if empty.
+	StorageClass string
+	// Canned ACL of uploaded and copied objects, such as "public-read", the server default if empty.
+	CannedACL string
+	// Encoding of user metadata values outside printable ASCII, such as MetadataCharsetPercent.
+	// RFC 2047 if empty.
+	MetadataCharset string
+	// Transfer objects through <bucket>.s3-accelerate.amazonaws.com, Amazon S3 Transfer Acceleration.
+	// Requests are signed for Region all the same. Buckets with dots in their name are not accelerated.
+	Accelerate bool
+	// Additional checksum of uploaded objects, verified on download, such as "CRC32C". None if empty.
+	ChecksumAlgorithm string
+	// One line per request in Debug traces, instead of all headers and how requests were signed.
+	DebugCompact bool
+	// Audit records what every request was signed for as a line of JSON, without signatures
+	// or secrets. Each line is a single Write, concurrent requests write concurrently.
+	Audit io.Writer
+	// Phases records the time every request spent in each phase, requests are not timed if nil.
+	Phases httptracer.PhaseRecorder
+	// Progress is called with the bytes transferred by Get, GetIfChanged and Put as they are read,
+	// see ProgressFunc for when it is called. Transfers are not reported if nil.
+	Progress ProgressFunc
 }
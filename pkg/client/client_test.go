@@ -17,8 +17,11 @@
 package client
 
 import (
+	"errors"
+	"io"
 	"testing"
 
+	"github.com/minio/minio-xl/pkg/probe"
 	. "gopkg.in/check.v1"
 )
 
@@ -67,3 +70,67 @@ func (s *MySuite) TestPathParse(c *C) {
 	c.Assert(u.Path, Equals, "/path/test")
 	c.Assert(u.SchemeSeparator, Equals, "")
 }
+
+func (s *MySuite) TestJoinURLsWindowsSource(c *C) {
+	// Local paths as seen on windows, with native and mixed separators.
+	for _, sourcePath := range []string{`dir\Sub\Ünïcode file.TXT`, `dir/Sub\Ünïcode file.TXT`, `\dir\Sub\Ünïcode file.TXT`} {
+		source := &URL{Type: Filesystem, Path: sourcePath, Separator: '\\'}
+		u := JoinURLs(NewURL("https://s3.example.com/bucket/prefix"), source)
+		c.Assert(u.String(), Equals, "https://s3.example.com/bucket/prefix/dir/Sub/Ünïcode file.TXT")
+	}
+	// Object keys keep a literal backslash, s3 to s3 never translates.
+	source := &URL{Type: Object, Path: `dir\odd/Key%20`, Separator: '/'}
+	u := JoinURLs(NewURL("https://s3.example.com/bucket/"), source)
+	c.Assert(u.String(), Equals, `https://s3.example.com/bucket/dir\odd/Key%20`)
+}
+
+func (s *MySuite) TestJoinURLsWindowsTarget(c *C) {
+	source := &URL{Type: Object, Path: "dir/Sub/file.TXT", Separator: '/'}
+	target := &URL{Type: Filesystem, Path: `C:\backup\`, Separator: '\\'}
+	c.Assert(JoinURLs(target, source).String(), Equals, `C:\backup\dir\Sub\file.TXT`)
+
+	source = &URL{Type: Filesystem, Path: `dir\file`, Separator: '\\'}
+	target = &URL{Type: Filesystem, Path: `C:\backup`, Separator: '\\'}
+	c.Assert(JoinURLs(target, source).String(), Equals, `C:\backup\dir\file`)
+}
+
+func (s *MySuite) TestToError(c *C) {
+	// A nil *probe.Error is a nil error, not a non nil interface holding nil.
+	c.Assert(ToError(nil) == nil, Equals, true)
+
+	err := ToError(probe.NewError(ObjectMissing{}).Trace("bucket"))
+	c.Assert(err.Error(), Equals, ObjectMissing{}.Error())
+	var missing ObjectMissing
+	c.Assert(errors.As(err, &missing), Equals, true)
+	var wrapped *Error
+	c.Assert(errors.As(err, &wrapped), Equals, true)
+	c.Assert(wrapped.Err.ToGoError(), Equals, ObjectMissing{})
+
+	c.Assert(errors.Is(ToError(probe.NewError(io.EOF)), io.EOF), Equals, true)
+}
+
+func (s *MySuite) TestEncodeMetadata(c *C) {
+	metadata := map[string]string{"name": "naïve 100%", "plain": "50% off"}
+	testCases := []struct {
+		charset string
+		encoded map[string]string
+	}{
+		{MetadataCharsetRFC2047, map[string]string{"name": "=?UTF-8?b?bmHDr3ZlIDEwMCU=?=", "plain": "50% off"}},
+		{MetadataCharsetPercent, map[string]string{"name": "na%C3%AFve 100%25", "plain": "50%25 off"}},
+	}
+	for _, testCase := range testCases {
+		encoded, err := EncodeMetadata(metadata, testCase.charset)
+		c.Assert(err, IsNil)
+		c.Assert(encoded, DeepEquals, testCase.encoded)
+		c.Assert(DecodeMetadata(encoded, testCase.charset), DeepEquals, metadata)
+	}
+
+	// Values sent as they are must be printable ASCII, names always.
+	_, err := EncodeMetadata(metadata, MetadataCharsetNone)
+	c.Assert(err, NotNil)
+	_, err = EncodeMetadata(map[string]string{"naïve": "name"}, MetadataCharsetRFC2047)
+	c.Assert(err, NotNil)
+	encoded, err := EncodeMetadata(map[string]string{"plain": "50% off"}, MetadataCharsetNone)
+	c.Assert(err, IsNil)
+	c.Assert(DecodeMetadata(encoded, MetadataCharsetNone)["plain"], Equals, "50% off")
+}
@@ -16,7 +16,10 @@
 
 package client
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 /// Collection of standard errors
 
@@ -51,6 +54,13 @@ func (e BucketExists) Error() string {
 	return "Bucket #" + e.Bucket + " exists."
 }
 
+// BucketNotEmpty - bucket still holds objects and cannot be removed
+type BucketNotEmpty GenericBucketError
+
+func (e BucketNotEmpty) Error() string {
+	return "Bucket #" + e.Bucket + " is not empty."
+}
+
 // InvalidBucketName - bucket name invalid (http://goo.gl/wJlzDz)
 type InvalidBucketName GenericBucketError
 
@@ -134,6 +144,13 @@ func (e TooManyLevelsSymlink) Error() string {
 	return "Requested file ‘" + e.Path + "’ has too many levels of symlinks"
 }
 
+// SymlinkNotAllowed - file is a symlink, refused by the symlink policy.
+type SymlinkNotAllowed GenericFileError
+
+func (e SymlinkNotAllowed) Error() string {
+	return "Requested file ‘" + e.Path + "’ is a symlink, symlinks are not allowed"
+}
+
 // EmptyPath (EINVAL) - invalid argument.
 type EmptyPath struct{}
 
@@ -147,3 +164,179 @@ type ObjectMissing struct{}
 func (e ObjectMissing) Error() string {
 	return "Object key is missing, object key cannot be empty"
 }
+
+// Timeout - network operation timed out, it is safe to retry.
+type Timeout struct {
+	Op  string
+	URL string
+}
+
+func (e Timeout) Error() string {
+	return "Timed out during ‘" + e.Op + "’ on ‘" + e.URL + "’."
+}
+
+// Retryable - timeouts are transient, operation may be retried.
+func (e Timeout) Retryable() bool {
+	return true
+}
+
+// ObjectTimeout - transfer of a single object took longer than allowed, it is safe to retry.
+type ObjectTimeout struct {
+	URL     string
+	Timeout time.Duration
+}
+
+func (e ObjectTimeout) Error() string {
+	return "Transfer of ‘" + e.URL + "’ did not complete within " + e.Timeout.String() + "."
+}
+
+// Retryable - a single object timing out is transient, transfer may be retried.
+func (e ObjectTimeout) Retryable() bool {
+	return true
+}
+
+// Stalled - transfer stayed below the minimum speed for too long, it is safe to retry.
+type Stalled struct {
+	URL      string
+	MinSpeed int64         // bytes per second.
+	Grace    time.Duration // longest time below MinSpeed.
+}
+
+func (e Stalled) Error() string {
+	return "Transfer of ‘" + e.URL + "’ stalled, below " + strconv.FormatInt(e.MinSpeed, 10) + " bytes/s for longer than " + e.Grace.String() + "."
+}
+
+// Retryable - stalls are transient, transfer may be retried.
+func (e Stalled) Retryable() bool {
+	return true
+}
+
+// Truncated - object ended before all of it was read, e.g. the connection dropped mid-body, it is safe to retry.
+type Truncated struct {
+	URL    string
+	Reason string
+}
+
+func (e Truncated) Error() string {
+	return "Download of ‘" + e.URL + "’ was cut short, " + e.Reason + "."
+}
+
+// Retryable - truncated downloads resume where they were cut short, download may be retried.
+func (e Truncated) Retryable() bool {
+	return true
+}
+
+// HTTPStatus - web server answered the request for URL with an error status.
+type HTTPStatus struct {
+	URL        string
+	Status     string
+	StatusCode int
+}
+
+func (e HTTPStatus) Error() string {
+	return "Request for ‘" + e.URL + "’ failed with ‘" + e.Status + "’."
+}
+
+// Retryable - server errors are transient, request may be retried.
+func (e HTTPStatus) Retryable() bool {
+	return e.StatusCode >= 500
+}
+
+// NotModified - object did not change from the copy a conditional get was made for.
+type NotModified struct {
+	URL string
+}
+
+func (e NotModified) Error() string {
+	return "Object ‘" + e.URL + "’ was not modified."
+}
+
+// RestoreInProgress - a restore of the archived object is already in progress.
+type RestoreInProgress struct {
+	URL string
+}
+
+func (e RestoreInProgress) Error() string {
+	return "Restore of ‘" + e.URL + "’ is already in progress."
+}
+
+// NotArchived - object is not of an archive storage class, it needs no restore.
+type NotArchived struct {
+	URL          string
+	StorageClass string
+}
+
+func (e NotArchived) Error() string {
+	if e.StorageClass == "" {
+		return "Object ‘" + e.URL + "’ is not archived, it needs no restore."
+	}
+	return "Object ‘" + e.URL + "’ of storage class ‘" + e.StorageClass + "’ is not archived, it needs no restore."
+}
+
+// ChecksumMismatch - downloaded or copied data differs from the object, it is safe to retry.
+type ChecksumMismatch struct {
+	URL       string
+	Algorithm string // md5 or sha256, size or etag for server side copies.
+	Expected  string
+	Actual    string
+}
+
+func (e ChecksumMismatch) Error() string {
+	return "Verification of ‘" + e.URL + "’ failed, " + e.Algorithm + " is ‘" + e.Actual + "’ instead of ‘" + e.Expected + "’."
+}
+
+// Retryable - corrupt downloads are removed, download may be retried.
+func (e ChecksumMismatch) Retryable() bool {
+	return true
+}
+
+// SinglePutTooLarge - object exceeds the size of a single PUT while multipart uploads are disabled.
+type SinglePutTooLarge struct {
+	Object string
+}
+
+func (e SinglePutTooLarge) Error() string {
+	return "Object ‘" + e.Object + "’ is larger than 5GB, the most a single PUT uploads. Remove ‘--disable-multipart’ or the ‘disableMultipart’ setting of the alias to upload it in parts."
+}
+
+// InvalidUploadCondition - conditions of a shared upload no upload could meet.
+type InvalidUploadCondition struct {
+	Reason string
+}
+
+func (e InvalidUploadCondition) Error() string {
+	return e.Reason
+}
+
+// IncompleteUpload - multipart upload failed, parts uploaded before the failure may remain on the server.
+type IncompleteUpload struct {
+	URL     string
+	Err     error
+	Aborted bool // incomplete upload was removed after the failure.
+}
+
+func (e IncompleteUpload) Error() string {
+	if e.Aborted {
+		return e.Err.Error() + " Incomplete upload of ‘" + e.URL + "’ was removed."
+	}
+	return e.Err.Error() + " Incomplete upload of ‘" + e.URL + "’ may be left behind, remove it with ‘mc rm --incomplete " + e.URL + "’."
+}
+
+// Retryable - failed upload may be retried if its cause is transient, remaining parts are resumed.
+func (e IncompleteUpload) Retryable() bool {
+	retryable, ok := e.Err.(interface {
+		Retryable() bool
+	})
+	return ok && retryable.Retryable()
+}
+
+// RegionMismatch - requests of the bucket are signed for a region the bucket is not in.
+type RegionMismatch struct {
+	Bucket        string
+	Region        string
+	SigningRegion string
+}
+
+func (e RegionMismatch) Error() string {
+	return "Bucket ‘" + e.Bucket + "’ is in region ‘" + e.Region + "’, requests are signed for region ‘" + e.SigningRegion + "’."
+}
@@ -0,0 +1,41 @@
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this fs except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// DiskFree - bytes available to this user on the volume of path.
+func DiskFree(path string) (uint64, *probe.Error) {
+	pathPtr, e := syscall.UTF16PtrFromString(normalizePath(path))
+	if e != nil {
+		return 0, probe.NewError(e).Trace(path)
+	}
+	var free uint64
+	if ret, _, e := getDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&free)), 0, 0); ret == 0 {
+		return 0, probe.NewError(e).Trace(path)
+	}
+	return free, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this fs except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// tempDir - folder of part files of targets in read only folders, none if empty.
+var tempDir string
+
+// SetTempDir - write part files of targets in read only folders to dir, their existing files are
+// then overwritten in place once complete. Empty disables it, such targets fail.
+func SetTempDir(dir string) {
+	tempDir = dir
+}
+
+// tempPartPath - part file of path in the temp folder, named by its full path to keep targets
+// of the same name apart.
+func tempPartPath(path string) string {
+	if absPath, e := filepath.Abs(path); e == nil {
+		path = absPath
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(tempDir, fmt.Sprintf("%x-%s%s", sum[:8], filepath.Base(path), partSuffix))
+}
+
+// existingPartPath - part file of path left by an interrupted Put, empty if there is none.
+func existingPartPath(path string) string {
+	if _, e := os.Stat(path + partSuffix); e == nil {
+		return path + partSuffix
+	}
+	if tempDir != "" {
+		if _, e := os.Stat(tempPartPath(path)); e == nil {
+			return tempPartPath(path)
+		}
+	}
+	return ""
+}
+
+// openPartFile - open the part file of path to append to, next to it unless its folder is read only.
+func openPartFile(path string) (*os.File, error) {
+	partPath := existingPartPath(path)
+	if partPath == "" {
+		partPath = path + partSuffix
+	}
+	partFile, e := os.OpenFile(partPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if e != nil && partPath == path+partSuffix && tempDir != "" && isReadOnly(e) {
+		return os.OpenFile(tempPartPath(path), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	}
+	return partFile, e
+}
+
+// commitPartFile - replace path by its complete part file. A part file in the temp folder is
+// copied over the file at path, its folder is read only.
+func commitPartFile(partPath, path string) error {
+	if partPath == path+partSuffix {
+		return os.Rename(partPath, path)
+	}
+	if e := copyFile(partPath, path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC); e != nil {
+		return e
+	}
+	return os.Remove(partPath)
+}
+
+// copyFile - copy the file src to dst, opened with flag.
+func copyFile(src, dst string, flag int) error {
+	srcFile, e := os.Open(src)
+	if e != nil {
+		return e
+	}
+	defer srcFile.Close()
+	dstFile, e := os.OpenFile(dst, flag, 0600)
+	if e != nil {
+		return e
+	}
+	if _, e = io.Copy(dstFile, srcFile); e != nil {
+		dstFile.Close()
+		return e
+	}
+	return dstFile.Close()
+}
+
+// PartialSize - bytes written to path by an interrupted Put, Put continues from there.
+func PartialSize(path string) int64 {
+	partPath := existingPartPath(path)
+	if partPath == "" {
+		return 0
+	}
+	st, e := os.Stat(partPath)
+	if e != nil {
+		return 0
+	}
+	return st.Size()
+}
+
+// MakePartial - make the file at path what an interrupted Put wrote, Put appends to it. In a
+// read only folder the file is copied to the temp folder instead.
+func MakePartial(path string) *probe.Error {
+	e := os.Rename(path, path+partSuffix)
+	if e != nil && tempDir != "" && isReadOnly(e) {
+		e = copyFile(path, tempPartPath(path), os.O_CREATE|os.O_EXCL|os.O_WRONLY)
+	}
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// RemovePartial - discard what an interrupted Put wrote to path, the next Put starts over.
+func RemovePartial(path string) *probe.Error {
+	if e := os.Remove(path + partSuffix); e != nil && !os.IsNotExist(e) {
+		return probe.NewError(e)
+	}
+	if tempDir != "" {
+		if e := os.Remove(tempPartPath(path)); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Symlink policies, how symlinks found on the filesystem are treated.
+const (
+	SymlinksFollow = "follow" // copy what the symlink points to, symlinks looping back are reported.
+	SymlinksSkip   = "skip"   // leave symlinks out.
+	SymlinksError  = "error"  // report symlinks as errors.
+	SymlinksStore  = "store"  // copy symlinks as empty objects carrying their target in metadata.
+)
+
+// IsValidSymlinkPolicy - true if policy is one of the symlink policies.
+func IsValidSymlinkPolicy(policy string) bool {
+	switch policy {
+	case SymlinksFollow, SymlinksSkip, SymlinksError, SymlinksStore:
+		return true
+	}
+	return false
+}
+
+// NewWithSymlinks - instantiate a new fs client treating symlinks according to policy, empty policy follows them.
+func NewWithSymlinks(path, policy string) (client.Client, *probe.Error) {
+	clnt, err := New(path)
+	if err != nil {
+		return nil, err.Trace(path)
+	}
+	if policy != "" && !IsValidSymlinkPolicy(policy) {
+		return nil, probe.NewError(fmt.Errorf("Unknown symlink policy ‘%s’", policy))
+	}
+	clnt.(*fsClient).symlinks = policy
+	return clnt, nil
+}
+
+// isFollowSymlinks - true if symlinks are followed.
+func (f *fsClient) isFollowSymlinks() bool {
+	return f.symlinks == "" || f.symlinks == SymlinksFollow
+}
+
+// symlinkContent - content listed for the symlink fp with Lstat info fi, unless it is to be followed.
+// Returns nil content if the symlink is skipped.
+func (f *fsClient) symlinkContent(fp string, fi os.FileInfo) (content *client.Content, follow bool) {
+	switch f.symlinks {
+	case SymlinksSkip:
+		return nil, false
+	case SymlinksError:
+		return &client.Content{
+			Err: probe.NewError(client.SymlinkNotAllowed{Path: fp}),
+		}, false
+	case SymlinksStore:
+		target, e := os.Readlink(fp)
+		if e != nil {
+			err := f.toClientError(e, fp)
+			return &client.Content{Err: err.Trace(fp)}, false
+		}
+		// Stored as an empty regular file, its permission bits are kept.
+		return &client.Content{
+			URL:      *client.NewURL(fp),
+			Time:     fi.ModTime(),
+			Size:     0,
+			Type:     fi.Mode().Perm(),
+			Metadata: map[string]string{client.MetadataSymlinkTarget: target},
+		}, false
+	}
+	return nil, true
+}
+
+// isSymlinkLoop - true if the folder symlink fp points to itself or to any folder it is listed in,
+// following it would list the same folders over and over.
+func isSymlinkLoop(fp string) bool {
+	target, e := filepath.EvalSymlinks(fp)
+	if e != nil {
+		return false
+	}
+	for dir := filepath.Dir(fp); ; dir = filepath.Dir(dir) {
+		if realDir, e := filepath.EvalSymlinks(dir); e == nil {
+			if realDir == target || strings.HasPrefix(realDir, strings.TrimSuffix(target, string(os.PathSeparator))+string(os.PathSeparator)) {
+				return true
+			}
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// putSymlink - create path as a symlink to target, replacing any file or symlink already there.
+func putSymlink(path, target string) *probe.Error {
+	if st, e := os.Lstat(path); e == nil {
+		if st.IsDir() {
+			return probe.NewError(client.PathIsDir{Path: path})
+		}
+		if e = os.Remove(path); e != nil {
+			return probe.NewError(e)
+		}
+	}
+	if e := os.Symlink(target, path); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// statSymlink - content of the path if it is a symlink which is not followed, nil content for all other paths.
+func (f *fsClient) statSymlink() (*client.Content, *probe.Error) {
+	if f.isFollowSymlinks() {
+		return nil, nil
+	}
+	fpath := f.PathURL.Path
+	fi, e := os.Lstat(fpath)
+	if e != nil || fi.Mode()&os.ModeSymlink != os.ModeSymlink {
+		return nil, nil
+	}
+	content, _ := f.symlinkContent(fpath, fi)
+	if content == nil {
+		return nil, probe.NewError(client.SymlinkNotAllowed{Path: fpath})
+	}
+	if content.Err != nil {
+		return nil, content.Err.Trace(fpath)
+	}
+	content.URL = *f.PathURL
+	return content, nil
+}
@@ -17,10 +17,15 @@
 package fs
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +38,10 @@ import (
 // filesystem client
 type fsClient struct {
 	PathURL *client.URL
+	// ctx fails reads and stops listings once done, nil if never.
+	ctx context.Context
+	// symlinks is the symlink policy, empty follows symlinks.
+	symlinks string
 }
 
 const (
@@ -54,10 +63,15 @@ func (f *fsClient) GetURL() client.URL {
 	return *f.PathURL
 }
 
+// WithContext - client whose reads and listings fail once ctx is done.
+func (f *fsClient) WithContext(ctx context.Context) client.Client {
+	return &fsClient{PathURL: f.PathURL, ctx: ctx, symlinks: f.symlinks}
+}
+
 /// Object operations.
 
-// Put - create a new file.
-func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string) *probe.Error {
+// Put - create a new file, file attributes preserved in metadata are restored.
+func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
 	// ContentType is not handled on purpose.
 	// For filesystem this is a redundant information.
 
@@ -83,8 +97,6 @@ func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string) *prob
 		}
 	}
 
-	// Write to a temporary file "object.part.mc" before commiting.
-	objectPartPath := objectPath + partSuffix
 	if objectDir != "" {
 		// Create any missing top level directories.
 		if e := os.MkdirAll(objectDir, 0700); e != nil {
@@ -93,13 +105,21 @@ func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string) *prob
 		}
 	}
 
+	// A stored symlink is restored as a symlink, its object is empty.
+	if target, ok := metadata[client.MetadataSymlinkTarget]; ok {
+		return putSymlink(objectPath, target).Trace(objectPath, target)
+	}
+
+	// Write to a temporary file "object.part.mc" before commiting.
 	// If exists, open in append mode. If not create it the part file.
-	partFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	partFile, e := openPartFile(objectPath)
 	if e != nil {
 		err := f.toClientError(e, f.PathURL.Path)
 		return err.Trace(f.PathURL.Path)
 	}
 
+	objectPartPath := partFile.Name()
+
 	// Get stat to get the current size.
 	partSt, e := partFile.Stat()
 	if e != nil {
@@ -108,6 +128,7 @@ func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string) *prob
 
 	// Seek to current position for incoming reader.
 	data.Seek(partSt.Size(), 0)
+	data = client.NewContextReader(f.ctx, data)
 
 	// Write to the part file.
 	if size < 0 { // Read till EOF.
@@ -123,13 +144,97 @@ func (f *fsClient) Put(data io.ReadSeeker, size int64, contentType string) *prob
 	partFile.Close()
 
 	// Safely completed put. Now commit by renaming to actual filename.
-	if e = os.Rename(objectPartPath, objectPath); e != nil {
+	if e = commitPartFile(objectPartPath, objectPath); e != nil {
 		err := f.toClientError(e, objectPath)
 		return err.Trace(objectPartPath, objectPath)
 	}
+	if err := setAttributes(objectPath, metadata); err != nil {
+		return err.Trace(objectPath)
+	}
+	return nil
+}
+
+// getAttributes - file attributes in the form Put restores them from metadata.
+func getAttributes(st os.FileInfo) map[string]string {
+	return map[string]string{
+		client.MetadataMode:  fmt.Sprintf("%04o", st.Mode().Perm()),
+		client.MetadataMtime: st.ModTime().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// setAttributes - restore file attributes from metadata, values which do not parse are ignored.
+func setAttributes(path string, metadata map[string]string) *probe.Error {
+	if value, ok := metadata[client.MetadataMode]; ok {
+		if mode, e := strconv.ParseUint(value, 8, 32); e == nil {
+			if e = os.Chmod(path, os.FileMode(mode)&os.ModePerm); e != nil {
+				return probe.NewError(e)
+			}
+		}
+	}
+	if value, ok := metadata[client.MetadataMtime]; ok {
+		if mtime, e := time.Parse(time.RFC3339Nano, value); e == nil {
+			if e = os.Chtimes(path, mtime, mtime); e != nil {
+				return probe.NewError(e)
+			}
+		}
+	}
 	return nil
 }
 
+// streamReadSeeker - stream which cannot seek, Put only seeks to resume a partial file.
+type streamReadSeeker struct {
+	io.Reader
+}
+
+func (s streamReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New("Seek is not supported on a stream.")
+}
+
+// PutStream - write a stream to file, partSize is ignored since nothing is buffered.
+func (f *fsClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	// A stream cannot be resumed, discard any leftover partial file.
+	if err := RemovePartial(f.PathURL.Path); err != nil {
+		return err.Trace(f.PathURL.Path)
+	}
+	return f.Put(streamReadSeeker{data}, -1, contentType, nil)
+}
+
+// RemoveBatch - remove files one at a time.
+func (f *fsClient) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			clnt, err := New(content.URL.String())
+			if err == nil {
+				err = clnt.Remove(false, content.VersionID)
+			}
+			if err != nil {
+				content.Err = err.Trace(content.URL.String())
+			}
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+// RemoveUploads - remove partial files listed as incomplete uploads.
+func (f *fsClient) RemoveUploads(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			if strings.HasSuffix(content.URL.Path, partSuffix) {
+				if e := os.Remove(content.URL.Path); e != nil && !os.IsNotExist(e) {
+					content.Err = f.toClientError(e, content.URL.Path).Trace(content.URL.String())
+				}
+			}
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
 // ShareDownload - share download not implemented for filesystem.
 func (f *fsClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
 	return "", probe.NewError(client.APINotImplemented{
@@ -139,7 +244,7 @@ func (f *fsClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
 }
 
 // ShareUpload - share upload not implemented for filesystem.
-func (f *fsClient) ShareUpload(startsWith bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+func (f *fsClient) ShareUpload(options client.ShareUploadOptions) (map[string]string, *probe.Error) {
 	return nil, probe.NewError(client.APINotImplemented{
 		API:     "ShareUpload",
 		APIType: "filesystem",
@@ -148,11 +253,23 @@ func (f *fsClient) ShareUpload(startsWith bool, expires time.Duration, contentTy
 
 // Get download an full or part object from bucket.
 // returns a reader, length and nil for no errors.
-func (f *fsClient) Get(offset, length int64) (io.ReadSeeker, *probe.Error) {
+func (f *fsClient) Get(offset, length int64, versionID string) (io.ReadSeeker, *probe.Error) {
+	if versionID != "" {
+		return nil, probe.NewError(client.APINotImplemented{API: "Get version", APIType: "filesystem"})
+	}
 	if offset < 0 || length < 0 {
 		return nil, probe.NewError(client.InvalidRange{Offset: offset})
 	}
 
+	content, err := f.statSymlink()
+	if err != nil {
+		return nil, err.Trace(f.PathURL.Path)
+	}
+	// A stored symlink reads as an empty object.
+	if content != nil {
+		return client.NewContextReader(f.ctx, bytes.NewReader(nil)), nil
+	}
+
 	tmppath := f.PathURL.Path
 	// Golang strips trailing / if you clean(..) or
 	// EvalSymlinks(..). Adding '.' prevents it from doing so.
@@ -172,35 +289,70 @@ func (f *fsClient) Get(offset, length int64) (io.ReadSeeker, *probe.Error) {
 		return nil, err.Trace(f.PathURL.Path)
 	}
 	if offset == 0 && length == 0 {
-		return body, nil
+		return client.NewContextReader(f.ctx, body), nil
+	}
+	return client.NewContextReader(f.ctx, io.NewSectionReader(body, offset, length)), nil
+}
+
+// GetIfChanged - get the file only if it was modified since modTime, files have no ETag.
+func (f *fsClient) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, *probe.Error) {
+	st, e := os.Stat(f.PathURL.Path)
+	if e != nil {
+		err := f.toClientError(e, f.PathURL.Path)
+		return nil, err.Trace(f.PathURL.Path)
+	}
+	if !modTime.IsZero() && !st.ModTime().After(modTime) {
+		return nil, probe.NewError(client.NotModified{URL: f.PathURL.String()})
 	}
-	return io.NewSectionReader(body, offset, length), nil
+	return f.Get(0, 0, "")
 }
 
 // Remove - remove the path.
-func (f *fsClient) Remove(incomplete bool) *probe.Error {
+func (f *fsClient) Remove(incomplete bool, versionID string) *probe.Error {
 	if incomplete {
 		return nil
 	}
+	if versionID != "" {
+		return probe.NewError(client.APINotImplemented{API: "Remove version", APIType: "filesystem"})
+	}
 	e := os.Remove(f.PathURL.Path)
 	err := f.toClientError(e, f.PathURL.Path)
 	return err.Trace(f.PathURL.Path)
 }
 
-// List - list files and folders.
-func (f *fsClient) List(recursive, incomplete bool) <-chan *client.Content {
-	contentCh := make(chan *client.Content)
+// errListingDone is returned by walk functions to stop walking once listing is done.
+var errListingDone = errors.New("listing done")
+
+// isListingDone - true if doneCh is closed.
+func isListingDone(doneCh <-chan struct{}) bool {
+	select {
+	case <-doneCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// List - list files and folders. Closing doneCh stops listing.
+func (f *fsClient) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *client.Content {
+	doneCh, stop := client.ContextDone(f.ctx, doneCh)
+	listCh := make(chan *client.Content)
 	switch recursive {
 	case true:
-		go f.listRecursiveInRoutine(contentCh, incomplete)
+		go f.listRecursiveInRoutine(listCh, incomplete, doneCh)
 	default:
-		go f.listInRoutine(contentCh, incomplete)
+		go f.listInRoutine(listCh, incomplete, doneCh)
 	}
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer stop()
+		client.ForwardContents(listCh, contentCh, doneCh)
+	}()
 	return contentCh
 }
 
 // listPrefixes - list all files for any given prefix.
-func (f *fsClient) listPrefixes(prefix string, contentCh chan<- *client.Content, incomplete bool) {
+func (f *fsClient) listPrefixes(prefix string, contentCh chan<- *client.Content, incomplete bool, doneCh <-chan struct{}) {
 	dirName := filepath.Dir(prefix)
 	files, e := ioutil.ReadDir(dirName)
 	if e != nil {
@@ -212,8 +364,17 @@ func (f *fsClient) listPrefixes(prefix string, contentCh chan<- *client.Content,
 	}
 	pathURL := *f.PathURL
 	for _, fi := range files {
+		if isListingDone(doneCh) {
+			return
+		}
 		file := filepath.Join(dirName, fi.Name())
 		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			if content, follow := f.symlinkContent(file, fi); !follow {
+				if content != nil && !incomplete && strings.HasPrefix(file, prefix) {
+					contentCh <- content
+				}
+				continue
+			}
 			st, e := os.Stat(file)
 			if e != nil {
 				if os.IsPermission(e) {
@@ -285,11 +446,14 @@ func (f *fsClient) listPrefixes(prefix string, contentCh chan<- *client.Content,
 					}
 				}
 				contentCh <- &client.Content{
-					URL:  *client.NewURL(file),
-					Time: st.ModTime(),
-					Size: st.Size(),
-					Type: st.Mode(),
-					Err:  nil,
+					URL:              *client.NewURL(file),
+					Time:             st.ModTime(),
+					Size:             st.Size(),
+					Type:             st.Mode(),
+					HardlinkID:       hardlinkID(st),
+					OwnerID:          ownerID(st),
+					OwnerDisplayName: ownerName(st),
+					Err:              nil,
 				}
 				continue
 			}
@@ -305,18 +469,21 @@ func (f *fsClient) listPrefixes(prefix string, contentCh chan<- *client.Content,
 				}
 			}
 			contentCh <- &client.Content{
-				URL:  *client.NewURL(file),
-				Time: fi.ModTime(),
-				Size: fi.Size(),
-				Type: fi.Mode(),
-				Err:  nil,
+				URL:              *client.NewURL(file),
+				Time:             fi.ModTime(),
+				Size:             fi.Size(),
+				Type:             fi.Mode(),
+				HardlinkID:       hardlinkID(fi),
+				OwnerID:          ownerID(fi),
+				OwnerDisplayName: ownerName(fi),
+				Err:              nil,
 			}
 		}
 	}
 	return
 }
 
-func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bool) {
+func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bool, doneCh <-chan struct{}) {
 	// close the channel when the function returns.
 	defer close(contentCh)
 
@@ -329,7 +496,7 @@ func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bo
 		if _, ok := err.ToGoError().(client.PathNotFound); ok {
 			// If file does not exist treat it like a prefix and list all prefixes if any.
 			prefix := fpath
-			f.listPrefixes(prefix, contentCh, incomplete)
+			f.listPrefixes(prefix, contentCh, incomplete, doneCh)
 			return
 		}
 		// For all other errors we return genuine error back to the caller.
@@ -340,7 +507,7 @@ func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bo
 	// Now if the file exists and doesn't end with a separator ('/') do not traverse it.
 	// If the directory doesn't end with a separator, do not traverse it.
 	if !strings.HasSuffix(fpath, string(pathURL.Separator)) && fst.Mode().IsDir() && fpath != "." {
-		f.listPrefixes(fpath, contentCh, incomplete)
+		f.listPrefixes(fpath, contentCh, incomplete, doneCh)
 		return
 	}
 
@@ -353,8 +520,17 @@ func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bo
 			return
 		}
 		for _, file := range files {
+			if isListingDone(doneCh) {
+				return
+			}
 			fi := file
 			if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+				if content, follow := f.symlinkContent(filepath.Join(fpath, fi.Name()), fi); !follow {
+					if content != nil && !incomplete {
+						contentCh <- content
+					}
+					continue
+				}
 				fi, e = os.Stat(filepath.Join(fpath, fi.Name()))
 				if os.IsPermission(e) {
 					// On windows there are folder symlinks
@@ -422,11 +598,14 @@ func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bo
 				pathURL := *f.PathURL
 				pathURL.Path = filepath.Join(pathURL.Path, fi.Name())
 				contentCh <- &client.Content{
-					URL:  pathURL,
-					Time: fi.ModTime(),
-					Size: fi.Size(),
-					Type: fi.Mode(),
-					Err:  nil,
+					URL:              pathURL,
+					Time:             fi.ModTime(),
+					Size:             fi.Size(),
+					Type:             fi.Mode(),
+					HardlinkID:       hardlinkID(fi),
+					OwnerID:          ownerID(fi),
+					OwnerDisplayName: ownerName(fi),
+					Err:              nil,
 				}
 			}
 		}
@@ -450,13 +629,17 @@ func (f *fsClient) listInRoutine(contentCh chan<- *client.Content, incomplete bo
 	}
 }
 
-func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomplete bool) {
+func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomplete bool, doneCh <-chan struct{}) {
 	// close channels upon return.
 	defer close(contentCh)
 	var dirName string
 	var filePrefix string
 	pathURL := *f.PathURL
-	visitFS := func(fp string, fi os.FileInfo, e error) error {
+	var visitFS WalkFunc
+	visitFS = func(fp string, fi os.FileInfo, e error) error {
+		if isListingDone(doneCh) {
+			return errListingDone
+		}
 		// If file path ends with os.PathSeparator and equals to root path, skip it.
 		if strings.HasSuffix(fp, string(pathURL.Separator)) {
 			if fp == dirName {
@@ -535,6 +718,12 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomp
 			return e
 		}
 		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			if content, follow := f.symlinkContent(fp, fi); !follow {
+				if content != nil && !incomplete {
+					contentCh <- content
+				}
+				return nil
+			}
 			fi, e = os.Stat(fp)
 			if e != nil {
 				if os.IsPermission(e) {
@@ -587,6 +776,16 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomp
 				}
 				return e
 			}
+			// Folders are followed as well, unless they lead back to a folder being listed.
+			if fi.IsDir() {
+				if isSymlinkLoop(fp) {
+					contentCh <- &client.Content{
+						Err: probe.NewError(client.TooManyLevelsSymlink{Path: fp}),
+					}
+					return nil
+				}
+				return walk(fp, fi, visitFS)
+			}
 		}
 		if fi.Mode().IsRegular() || fi.Mode().IsDir() {
 			if incomplete {
@@ -599,11 +798,14 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomp
 				}
 			}
 			contentCh <- &client.Content{
-				URL:  *client.NewURL(fp),
-				Time: fi.ModTime(),
-				Size: fi.Size(),
-				Type: fi.Mode(),
-				Err:  nil,
+				URL:              *client.NewURL(fp),
+				Time:             fi.ModTime(),
+				Size:             fi.Size(),
+				Type:             fi.Mode(),
+				HardlinkID:       hardlinkID(fi),
+				OwnerID:          ownerID(fi),
+				OwnerDisplayName: ownerName(fi),
+				Err:              nil,
 			}
 		}
 		return nil
@@ -626,7 +828,7 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *client.Content, incomp
 	}
 	// Walks invokes our custom function.
 	e := Walk(dirName, visitFS)
-	if e != nil {
+	if e != nil && e != errListingDone {
 		contentCh <- &client.Content{
 			Err: probe.NewError(e),
 		}
@@ -642,6 +844,16 @@ func (f *fsClient) MakeBucket() *probe.Error {
 	return nil
 }
 
+// RemoveBucket - remove the folder, which must be empty.
+func (f *fsClient) RemoveBucket() *probe.Error {
+	e := os.Remove(f.PathURL.Path)
+	if isDirNotEmpty(e) {
+		return probe.NewError(client.BucketNotEmpty{Bucket: f.PathURL.Path})
+	}
+	err := f.toClientError(e, f.PathURL.Path)
+	return err.Trace(f.PathURL.Path)
+}
+
 // GetBucketACL - get bucket access.
 func (f *fsClient) GetBucketAccess() (acl string, err *probe.Error) {
 	return "", probe.NewError(client.APINotImplemented{API: "GetBucketAccess", APIType: "filesystem"})
@@ -652,8 +864,179 @@ func (f *fsClient) SetBucketAccess(acl string) *probe.Error {
 	return probe.NewError(client.APINotImplemented{API: "SetBucketAccess", APIType: "filesystem"})
 }
 
+// GetBucketVersioning - get bucket versioning.
+func (f *fsClient) GetBucketVersioning() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: "filesystem"})
+}
+
+// GetBucketRegion - get bucket region.
+func (f *fsClient) GetBucketRegion() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketRegion", APIType: "filesystem"})
+}
+
+// SetBucketVersioning - set bucket versioning.
+func (f *fsClient) SetBucketVersioning(enable bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: "filesystem"})
+}
+
+// GetBucketAccelerate - get bucket acceleration.
+func (f *fsClient) GetBucketAccelerate() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketAccelerate", APIType: "filesystem"})
+}
+
+// SetBucketAccelerate - set bucket acceleration.
+func (f *fsClient) SetBucketAccelerate(enable bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketAccelerate", APIType: "filesystem"})
+}
+
+// GetBucketReplication - get bucket replication.
+func (f *fsClient) GetBucketReplication() (client.ReplicationConfig, *probe.Error) {
+	return client.ReplicationConfig{}, probe.NewError(client.APINotImplemented{API: "GetBucketReplication", APIType: "filesystem"})
+}
+
+// SetBucketReplication - set bucket replication.
+func (f *fsClient) SetBucketReplication(config client.ReplicationConfig) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketReplication", APIType: "filesystem"})
+}
+
+// GetBucketPolicy - get bucket policy.
+func (f *fsClient) GetBucketPolicy() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: "filesystem"})
+}
+
+// GetAnonymousAccess - access of others to the path. A folder is readable if others may list
+// and enter it, writable if they may create files in it.
+func (f *fsClient) GetAnonymousAccess() (string, *probe.Error) {
+	st, err := f.fsStat()
+	if err != nil {
+		return "", err.Trace(f.PathURL.String())
+	}
+	perm := st.Mode().Perm()
+	if st.IsDir() {
+		return client.AnonymousAccess(perm&0005 == 0005, perm&0003 == 0003), nil
+	}
+	return client.AnonymousAccess(perm&0004 != 0, perm&0002 != 0), nil
+}
+
+// SetBucketPolicy - set bucket policy.
+func (f *fsClient) SetBucketPolicy(policy string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: "filesystem"})
+}
+
+// ListVersions - list versions.
+func (f *fsClient) ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *client.Content {
+	contentCh := make(chan *client.Content, 1)
+	contentCh <- &client.Content{
+		Err: probe.NewError(client.APINotImplemented{API: "ListVersions", APIType: "filesystem"}),
+	}
+	close(contentCh)
+	return contentCh
+}
+
+// ListPage - filesystem folders are not listed in pages.
+func (f *fsClient) ListPage(recursive bool, startAfter string, maxKeys int) ([]*client.Content, string, *probe.Error) {
+	return nil, "", probe.NewError(client.APINotImplemented{API: "ListPage", APIType: "filesystem"})
+}
+
+// ListParts - list parts of an incomplete upload.
+func (f *fsClient) ListParts(uploadID string, doneCh <-chan struct{}) <-chan *client.Content {
+	contentCh := make(chan *client.Content, 1)
+	contentCh <- &client.Content{
+		Err: probe.NewError(client.APINotImplemented{API: "ListParts", APIType: "filesystem"}),
+	}
+	close(contentCh)
+	return contentCh
+}
+
+// GetTags - get tags.
+func (f *fsClient) GetTags() (map[string]string, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "GetTags", APIType: "filesystem"})
+}
+
+// SetTags - set tags.
+func (f *fsClient) SetTags(tags map[string]string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetTags", APIType: "filesystem"})
+}
+
+// cannedACLModes - permissions of the canned ACLs files can express, directories are searchable
+// by whoever may read them.
+var cannedACLModes = map[string]os.FileMode{
+	"private":            0600,
+	"public-read":        0644,
+	"authenticated-read": 0644,
+	"public-read-write":  0666,
+}
+
+// GetObjectACL - get ACL.
+func (f *fsClient) GetObjectACL() (client.ACL, *probe.Error) {
+	return client.ACL{}, probe.NewError(client.APINotImplemented{API: "GetObjectACL", APIType: "filesystem"})
+}
+
+// SetObjectACL - set permissions of the file or directory from a canned ACL, grants and canned
+// ACLs about bucket owners cannot be expressed.
+func (f *fsClient) SetObjectACL(acl client.ACL) *probe.Error {
+	mode, ok := cannedACLModes[acl.Canned]
+	if !ok {
+		return probe.NewError(client.APINotImplemented{API: "SetObjectACL", APIType: "filesystem"})
+	}
+	st, err := f.fsStat()
+	if err != nil {
+		return err.Trace(f.PathURL.Path)
+	}
+	if st.IsDir() {
+		mode |= (mode & 0444) >> 2
+	}
+	if e := os.Chmod(f.PathURL.Path, mode); e != nil {
+		return probe.NewError(e).Trace(f.PathURL.Path)
+	}
+	return nil
+}
+
+// GetRetention - get retention.
+func (f *fsClient) GetRetention() (string, time.Time, *probe.Error) {
+	return "", time.Time{}, probe.NewError(client.APINotImplemented{API: "GetRetention", APIType: "filesystem"})
+}
+
+// SetRetention - set retention.
+func (f *fsClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetRetention", APIType: "filesystem"})
+}
+
+// GetLegalHold - get legal hold.
+func (f *fsClient) GetLegalHold() (bool, *probe.Error) {
+	return false, probe.NewError(client.APINotImplemented{API: "GetLegalHold", APIType: "filesystem"})
+}
+
+// SetLegalHold - set legal hold.
+func (f *fsClient) SetLegalHold(enabled bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetLegalHold", APIType: "filesystem"})
+}
+
+// SetMetadata - set metadata.
+func (f *fsClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetMetadata", APIType: "filesystem"})
+}
+
+// RestoreArchived - restore archived object.
+func (f *fsClient) RestoreArchived(days int, tier string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RestoreArchived", APIType: "filesystem"})
+}
+
+// RestoreVersion - restore version.
+func (f *fsClient) RestoreVersion(versionID string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RestoreVersion", APIType: "filesystem"})
+}
+
+// Copy - copy server side.
+func (f *fsClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "Copy", APIType: "filesystem"})
+}
+
 // Stat - get metadata from path.
 func (f *fsClient) Stat() (content *client.Content, err *probe.Error) {
+	if content, err = f.statSymlink(); err != nil || content != nil {
+		return content, err.Trace(f.PathURL.String())
+	}
 	st, err := f.fsStat()
 	if err != nil {
 		return nil, err.Trace(f.PathURL.String())
@@ -663,5 +1046,8 @@ func (f *fsClient) Stat() (content *client.Content, err *probe.Error) {
 	content.Size = st.Size()
 	content.Time = st.ModTime()
 	content.Type = st.Mode()
+	content.Metadata = getAttributes(st)
+	content.HardlinkID = hardlinkID(st)
+	content.OwnerID, content.OwnerDisplayName = ownerID(st), ownerName(st)
 	return content, nil
 }
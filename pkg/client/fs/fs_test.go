@@ -18,11 +18,16 @@ package fs_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/user"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/client/fs"
@@ -46,21 +51,21 @@ func (s *MySuite) TestList(c *C) {
 
 	data := "hello"
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
 	objectPath = filepath.Join(root, "object2")
 	fsc, err = fs.New(objectPath)
 	c.Assert(err, IsNil)
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
 	fsc, err = fs.New(root)
 	c.Assert(err, IsNil)
 
 	var contents []*client.Content
-	for content := range fsc.List(false, false) {
+	for content := range fsc.List(false, false, nil) {
 		if content.Err != nil {
 			err = content.Err
 			break
@@ -75,14 +80,14 @@ func (s *MySuite) TestList(c *C) {
 	fsc, err = fs.New(objectPath)
 	c.Assert(err, IsNil)
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
 	fsc, err = fs.New(root)
 	c.Assert(err, IsNil)
 
 	contents = nil
-	for content := range fsc.List(false, false) {
+	for content := range fsc.List(false, false, nil) {
 		if content.Err != nil {
 			err = content.Err
 			break
@@ -97,7 +102,7 @@ func (s *MySuite) TestList(c *C) {
 	c.Assert(err, IsNil)
 
 	contents = nil
-	for content := range fsc.List(true, false) {
+	for content := range fsc.List(true, false, nil) {
 		if content.Err != nil {
 			err = content.Err
 			break
@@ -124,6 +129,47 @@ func (s *MySuite) TestList(c *C) {
 	c.Assert(regularFiles, Equals, 3)
 }
 
+func (s *MySuite) TestOwner(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("Files are not told apart by owner on windows.")
+	}
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file"), []byte("owned"), 0600), IsNil)
+
+	// Files belong to the user and group they are created by.
+	ownerID := strconv.Itoa(os.Getuid()) + ":" + strconv.Itoa(os.Getgid())
+	ownerName := ""
+	u, ue := user.LookupId(strconv.Itoa(os.Getuid()))
+	g, ge := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	switch {
+	case ue == nil && ge == nil:
+		ownerName = u.Username + ":" + g.Name
+	case ue == nil:
+		ownerName = u.Username + ":" + strconv.Itoa(os.Getgid())
+	case ge == nil:
+		ownerName = strconv.Itoa(os.Getuid()) + ":" + g.Name
+	}
+
+	fsc, err := fs.New(filepath.Join(root, "file"))
+	c.Assert(err, IsNil)
+	content, err := fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.OwnerID, Equals, ownerID)
+	c.Assert(content.OwnerDisplayName, Equals, ownerName)
+
+	fsc, err = fs.New(root)
+	c.Assert(err, IsNil)
+	for _, isRecursive := range []bool{false, true} {
+		for content := range fsc.List(isRecursive, false, nil) {
+			c.Assert(content.Err, IsNil)
+			c.Assert(content.OwnerID, Equals, ownerID)
+			c.Assert(content.OwnerDisplayName, Equals, ownerName)
+		}
+	}
+}
+
 func (s *MySuite) TestPutBucket(c *C) {
 	root, e := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(e, IsNil)
@@ -169,6 +215,31 @@ func (s *MySuite) TestBucketACLFails(c *C) {
 	c.Assert(err, Not(IsNil))
 }
 
+func (s *MySuite) TestVersioningFails(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	bucketPath := filepath.Join(root, "bucket")
+	fsc, err := fs.New(bucketPath)
+	c.Assert(err, IsNil)
+	err = fsc.MakeBucket()
+	c.Assert(err, IsNil)
+
+	err = fsc.SetBucketVersioning(true)
+	c.Assert(err, Not(IsNil))
+
+	_, err = fsc.GetBucketVersioning()
+	c.Assert(err, Not(IsNil))
+
+	for content := range fsc.ListVersions(true, nil) {
+		c.Assert(content.Err, Not(IsNil))
+	}
+
+	err = fsc.Remove(false, "1")
+	c.Assert(err, Not(IsNil))
+}
+
 func (s *MySuite) TestPut(c *C) {
 	root, e := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(e, IsNil)
@@ -179,10 +250,68 @@ func (s *MySuite) TestPut(c *C) {
 	c.Assert(err, IsNil)
 
 	data := "hello"
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 }
 
+func (s *MySuite) TestPutReadOnlyFolder(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	tempDir := filepath.Join(root, "temp")
+	folder := filepath.Join(root, "folder")
+	c.Assert(os.MkdirAll(tempDir, 0700), IsNil)
+	c.Assert(os.MkdirAll(folder, 0700), IsNil)
+	fs.SetTempDir(tempDir)
+	defer fs.SetTempDir("")
+
+	// Part files of targets in writable folders stay next to them.
+	objectPath := filepath.Join(folder, "object")
+	c.Assert(ioutil.WriteFile(objectPath, []byte("old"), 0600), IsNil)
+	fsc, err := fs.New(objectPath)
+	c.Assert(err, IsNil)
+	c.Assert(fsc.Put(bytes.NewReader([]byte("new")), 3, "", nil), IsNil)
+	names, e := ioutil.ReadDir(tempDir)
+	c.Assert(e, IsNil)
+	c.Assert(len(names), Equals, 0)
+
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		c.Skip("Folders are never read only for root, nor on windows.")
+	}
+	// In a read only folder the part file is written to the temp folder, and copied over the existing file.
+	c.Assert(os.Chmod(folder, 0500), IsNil)
+	defer os.Chmod(folder, 0700)
+	data := "written through the temp folder"
+	c.Assert(fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "", nil), IsNil)
+	written, e := ioutil.ReadFile(objectPath)
+	c.Assert(e, IsNil)
+	c.Assert(string(written), Equals, data)
+	names, e = ioutil.ReadDir(tempDir)
+	c.Assert(e, IsNil)
+	c.Assert(len(names), Equals, 0)
+}
+
+func (s *MySuite) TestPutStream(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	// Leftover of an interrupted stream must not end up in the object.
+	e = ioutil.WriteFile(objectPath+".part.mc", []byte("stale"), 0600)
+	c.Assert(e, IsNil)
+
+	fsc, err := fs.New(objectPath)
+	c.Assert(err, IsNil)
+	data := []byte("hello, stream")
+	err = fsc.PutStream(ioutil.NopCloser(bytes.NewReader(data)), 5*1024*1024, "application/octet-stream")
+	c.Assert(err, IsNil)
+
+	written, e := ioutil.ReadFile(objectPath)
+	c.Assert(e, IsNil)
+	c.Assert(written, DeepEquals, data)
+}
+
 func (s *MySuite) TestGet(c *C) {
 	root, e := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(e, IsNil)
@@ -194,10 +323,10 @@ func (s *MySuite) TestGet(c *C) {
 
 	data := "hello"
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
-	reader, err := fsc.Get(0, 0)
+	reader, err := fsc.Get(0, 0, "")
 	c.Assert(err, IsNil)
 	var results bytes.Buffer
 	_, e = io.Copy(&results, reader)
@@ -217,10 +346,10 @@ func (s *MySuite) TestGetRange(c *C) {
 
 	data := "hello world"
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
-	reader, err := fsc.Get(0, 5)
+	reader, err := fsc.Get(0, 5, "")
 	c.Assert(err, IsNil)
 	var results bytes.Buffer
 	_, e = io.Copy(&results, reader)
@@ -228,6 +357,30 @@ func (s *MySuite) TestGetRange(c *C) {
 	c.Assert([]byte("hello"), DeepEquals, results.Bytes())
 }
 
+func (s *MySuite) TestGetContextCancel(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, err := fs.New(objectPath)
+	c.Assert(err, IsNil)
+
+	data := "hello world"
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, err := fsc.WithContext(ctx).Get(0, 0, "")
+	c.Assert(err, IsNil)
+	buf := make([]byte, 5)
+	_, e = io.ReadFull(reader, buf)
+	c.Assert(e, IsNil)
+	cancel()
+	_, e = reader.Read(buf)
+	c.Assert(e, Equals, context.Canceled)
+}
+
 func (s *MySuite) TestStatObject(c *C) {
 	root, e := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(e, IsNil)
@@ -240,10 +393,173 @@ func (s *MySuite) TestStatObject(c *C) {
 	data := "hello"
 	dataLen := len(data)
 
-	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream")
+	err = fsc.Put(bytes.NewReader([]byte(data)), int64(len(data)), "application/octet-stream", nil)
 	c.Assert(err, IsNil)
 
 	content, err := fsc.Stat()
 	c.Assert(err, IsNil)
 	c.Assert(content.Size, Equals, int64(dataLen))
 }
+
+func (s *MySuite) TestPutAttributes(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, err := fs.New(objectPath)
+	c.Assert(err, IsNil)
+
+	mtime := time.Date(2015, 10, 21, 7, 28, 0, 123456789, time.UTC)
+	metadata := map[string]string{
+		client.MetadataMode:  "0640",
+		client.MetadataMtime: mtime.Format(time.RFC3339Nano),
+	}
+	err = fsc.Put(bytes.NewReader([]byte("hello")), 5, "", metadata)
+	c.Assert(err, IsNil)
+
+	st, e := os.Stat(objectPath)
+	c.Assert(e, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0640))
+	// Filesystems differ in timestamp resolution, seconds are kept by all.
+	c.Assert(st.ModTime().Unix(), Equals, mtime.Unix())
+
+	// Stat reports attributes the same way.
+	content, err := fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Metadata[client.MetadataMode], Equals, "0640")
+
+	// Values which do not parse are ignored.
+	err = fsc.Put(bytes.NewReader([]byte("hello")), 5, "", map[string]string{client.MetadataMode: "rw-r-----", client.MetadataMtime: "yesterday"})
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestGetAnonymousAccess(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	c.Assert(ioutil.WriteFile(objectPath, []byte("hello"), 0600), IsNil)
+	testCases := []struct {
+		path   string
+		perm   os.FileMode
+		access string
+	}{
+		{root, 0700, client.AnonymousNone},
+		{root, 0755, client.AnonymousReadOnly},
+		{root, 0733, client.AnonymousWriteOnly},
+		{root, 0777, client.AnonymousReadWrite},
+		// Others may see the names in a folder, not enter it.
+		{root, 0754, client.AnonymousNone},
+		{objectPath, 0600, client.AnonymousNone},
+		{objectPath, 0644, client.AnonymousReadOnly},
+		{objectPath, 0666, client.AnonymousReadWrite},
+	}
+	for i, testCase := range testCases {
+		c.Assert(os.Chmod(testCase.path, testCase.perm), IsNil)
+		fsc, err := fs.New(testCase.path)
+		c.Assert(err, IsNil)
+		access, err := fsc.GetAnonymousAccess()
+		c.Assert(err, IsNil)
+		c.Assert(access, Equals, testCase.access, Commentf("Test %d", i+1))
+	}
+	c.Assert(os.Chmod(root, 0700), IsNil)
+}
+
+// listSymlinks - recursive listing of root under policy, contents by their path relative to root and the errors.
+func listSymlinks(c *C, root, policy string) (map[string]*client.Content, []error) {
+	fsc, err := fs.NewWithSymlinks(root+string(os.PathSeparator), policy)
+	c.Assert(err, IsNil)
+	contents := make(map[string]*client.Content)
+	var errs []error
+	for content := range fsc.List(true, false, nil) {
+		if content.Err != nil {
+			errs = append(errs, content.Err.ToGoError())
+			continue
+		}
+		name, e := filepath.Rel(root, content.URL.Path)
+		c.Assert(e, IsNil)
+		contents[name] = content
+	}
+	return contents, errs
+}
+
+func (s *MySuite) TestSymlinks(c *C) {
+	tmp, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(tmp)
+
+	root := filepath.Join(tmp, "dir")
+	c.Assert(os.MkdirAll(root, 0700), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(tmp, "other"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file.txt"), []byte("hello"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(tmp, "other", "a.txt"), []byte("a"), 0600), IsNil)
+	c.Assert(os.Symlink("file.txt", filepath.Join(root, "link.txt")), IsNil)
+	c.Assert(os.Symlink(filepath.Join(tmp, "other"), filepath.Join(root, "sub")), IsNil)
+	// Points back to the folder being listed.
+	c.Assert(os.Symlink(root, filepath.Join(root, "loop")), IsNil)
+
+	// Follow is the default, looping symlinks are reported instead of followed.
+	contents, errs := listSymlinks(c, root, "")
+	c.Assert(len(contents), Equals, 4)
+	c.Assert(contents["link.txt"].Size, Equals, int64(5))
+	c.Assert(contents["sub"].Type.IsDir(), Equals, true)
+	c.Assert(contents[filepath.Join("sub", "a.txt")], NotNil)
+	c.Assert(errs, DeepEquals, []error{client.TooManyLevelsSymlink{Path: filepath.Join(root, "loop")}})
+
+	contents, errs = listSymlinks(c, root, fs.SymlinksSkip)
+	c.Assert(len(contents), Equals, 1)
+	c.Assert(contents["file.txt"], NotNil)
+	c.Assert(errs, IsNil)
+
+	contents, errs = listSymlinks(c, root, fs.SymlinksError)
+	c.Assert(len(contents), Equals, 1)
+	c.Assert(len(errs), Equals, 3)
+	for _, e := range errs {
+		_, ok := e.(client.SymlinkNotAllowed)
+		c.Assert(ok, Equals, true)
+	}
+
+	// Stored symlinks are empty files carrying their target, folder symlinks included.
+	contents, errs = listSymlinks(c, root, fs.SymlinksStore)
+	c.Assert(errs, IsNil)
+	c.Assert(len(contents), Equals, 4)
+	for name, target := range map[string]string{"link.txt": "file.txt", "sub": filepath.Join(tmp, "other"), "loop": root} {
+		c.Assert(contents[name].Type.IsRegular(), Equals, true)
+		c.Assert(contents[name].Size, Equals, int64(0))
+		c.Assert(contents[name].Metadata[client.MetadataSymlinkTarget], Equals, target)
+	}
+
+	linkPath := filepath.Join(root, "link.txt")
+	fsc, err := fs.NewWithSymlinks(linkPath, fs.SymlinksStore)
+	c.Assert(err, IsNil)
+	content, err := fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Metadata[client.MetadataSymlinkTarget], Equals, "file.txt")
+	reader, err := fsc.Get(0, 0, "")
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(len(data), Equals, 0)
+
+	fsc, err = fs.NewWithSymlinks(linkPath, fs.SymlinksError)
+	c.Assert(err, IsNil)
+	_, err = fsc.Stat()
+	c.Assert(err, NotNil)
+	_, ok := err.ToGoError().(client.SymlinkNotAllowed)
+	c.Assert(ok, Equals, true)
+
+	// The stored symlink is restored as a symlink.
+	restoredPath := filepath.Join(tmp, "restored", "link.txt")
+	fsc, err = fs.New(restoredPath)
+	c.Assert(err, IsNil)
+	err = fsc.Put(bytes.NewReader(nil), 0, "", content.Metadata)
+	c.Assert(err, IsNil)
+	target, e := os.Readlink(restoredPath)
+	c.Assert(e, IsNil)
+	c.Assert(target, Equals, "file.txt")
+
+	_, err = fs.NewWithSymlinks(root, "unknown")
+	c.Assert(err, NotNil)
+}
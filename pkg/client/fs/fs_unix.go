@@ -18,6 +18,30 @@
 
 package fs
 
+import (
+	"os"
+	"syscall"
+)
+
 func normalizePath(path string) string {
 	return path
 }
+
+// isReadOnly - true if e reports writing to a folder without permission or on a read only filesystem.
+func isReadOnly(e error) bool {
+	switch err := e.(type) {
+	case *os.PathError:
+		e = err.Err
+	case *os.LinkError:
+		e = err.Err
+	}
+	return os.IsPermission(e) || e == syscall.EROFS
+}
+
+// isDirNotEmpty - true if e reports removing a folder which is not empty.
+func isDirNotEmpty(e error) bool {
+	if pathErr, ok := e.(*os.PathError); ok {
+		return pathErr.Err == syscall.ENOTEMPTY || pathErr.Err == syscall.EEXIST
+	}
+	return false
+}
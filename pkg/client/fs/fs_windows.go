@@ -19,10 +19,14 @@
 package fs
 
 import (
+	"os"
 	"path/filepath"
 	"syscall"
 )
 
+// errorDirNotEmpty - ERROR_DIR_NOT_EMPTY, removing a folder which is not empty.
+const errorDirNotEmpty syscall.Errno = 145
+
 func normalizePath(path string) string {
 	if filepath.VolumeName(path) == "" && filepath.HasPrefix(path, "\\") {
 		var err error
@@ -33,3 +37,16 @@ func normalizePath(path string) string {
 	}
 	return path
 }
+
+// isReadOnly - true if e reports writing to a folder without permission.
+func isReadOnly(e error) bool {
+	return os.IsPermission(e)
+}
+
+// isDirNotEmpty - true if e reports removing a folder which is not empty.
+func isDirNotEmpty(e error) bool {
+	if pathErr, ok := e.(*os.PathError); ok {
+		return pathErr.Err == errorDirNotEmpty
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+// +build !darwin,!dragonfly,!freebsd,!linux
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this fs except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import "os"
+
+// ownerID - files are not told apart by owner on this platform.
+func ownerID(st os.FileInfo) string {
+	return ""
+}
+
+// ownerName - files are not told apart by owner on this platform.
+func ownerName(st os.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,84 @@
+// +build darwin dragonfly freebsd linux
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this fs except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// ownerNames - names of users and groups looked up so far, by "u" or "g" and their id, empty if unknown.
+var ownerNames = struct {
+	sync.Mutex
+	names map[string]string
+}{names: make(map[string]string)}
+
+// lookupOwnerName - name of the user or group of id, empty if it has none.
+func lookupOwnerName(kind, id string) string {
+	ownerNames.Lock()
+	defer ownerNames.Unlock()
+	if name, ok := ownerNames.names[kind+id]; ok {
+		return name
+	}
+	var name string
+	if kind == "u" {
+		if u, e := user.LookupId(id); e == nil {
+			name = u.Username
+		}
+	} else {
+		if g, e := user.LookupGroupId(id); e == nil {
+			name = g.Name
+		}
+	}
+	ownerNames.names[kind+id] = name
+	return name
+}
+
+// ownerID - uid and gid of a file, as "uid:gid".
+func ownerID(st os.FileInfo) string {
+	sys, ok := st.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(uint64(sys.Uid), 10) + ":" + strconv.FormatUint(uint64(sys.Gid), 10)
+}
+
+// ownerName - names of the user and group owning a file as "user:group", ids stand in for names
+// not found. Empty if neither is found.
+func ownerName(st os.FileInfo) string {
+	sys, ok := st.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	uid, gid := strconv.FormatUint(uint64(sys.Uid), 10), strconv.FormatUint(uint64(sys.Gid), 10)
+	userName, groupName := lookupOwnerName("u", uid), lookupOwnerName("g", gid)
+	if userName == "" && groupName == "" {
+		return ""
+	}
+	if userName == "" {
+		userName = uid
+	}
+	if groupName == "" {
+		groupName = gid
+	}
+	return userName + ":" + groupName
+}
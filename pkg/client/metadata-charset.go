@@ -0,0 +1,126 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Encodings of user metadata values outside printable ASCII, S3 sends metadata as US-ASCII headers.
+const (
+	// MetadataCharsetRFC2047 - values are sent as RFC 2047 encoded words of UTF-8, =?UTF-8?b?...?=.
+	MetadataCharsetRFC2047 = "rfc2047"
+	// MetadataCharsetPercent - bytes outside printable ASCII and ‘%’ are sent as %XX.
+	MetadataCharsetPercent = "percent"
+	// MetadataCharsetNone - values are sent as they are, those outside printable ASCII are rejected.
+	MetadataCharsetNone = "none"
+)
+
+// IsValidMetadataCharset - charset is one of the encodings of metadata values, empty is RFC 2047.
+func IsValidMetadataCharset(charset string) bool {
+	switch charset {
+	case "", MetadataCharsetRFC2047, MetadataCharsetPercent, MetadataCharsetNone:
+		return true
+	}
+	return false
+}
+
+// InvalidMetadata - user metadata cannot be sent as a header.
+type InvalidMetadata struct {
+	Key    string
+	Reason string
+}
+
+func (e InvalidMetadata) Error() string {
+	return "Metadata ‘" + e.Key + "’ " + e.Reason
+}
+
+// isPrintableASCII - s holds only printable ASCII characters, the only ones headers carry as they are.
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// percentEncode - bytes of s outside printable ASCII, and ‘%’ itself, as %XX.
+func percentEncode(s string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' || s[i] == '%' {
+			fmt.Fprintf(&encoded, "%%%02X", s[i])
+			continue
+		}
+		encoded.WriteByte(s[i])
+	}
+	return encoded.String()
+}
+
+// EncodeMetadata - metadata with values encoded by charset, keys must be printable ASCII. Values of
+// printable ASCII are kept as they are, other than the ‘%’ of percent encoding.
+func EncodeMetadata(metadata map[string]string, charset string) (map[string]string, *probe.Error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	encoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if !isPrintableASCII(key) {
+			return nil, probe.NewError(InvalidMetadata{Key: key, Reason: "has a name outside printable ASCII."})
+		}
+		switch charset {
+		case MetadataCharsetNone:
+			if !isPrintableASCII(value) {
+				return nil, probe.NewError(InvalidMetadata{Key: key, Reason: "has a value outside printable ASCII, it needs a metadata charset to be sent."})
+			}
+		case MetadataCharsetPercent:
+			value = percentEncode(value)
+		default:
+			value = mime.BEncoding.Encode("UTF-8", value)
+		}
+		encoded[key] = value
+	}
+	return encoded, nil
+}
+
+// DecodeMetadata - metadata with values decoded by charset, values not encoded are kept as they are.
+func DecodeMetadata(metadata map[string]string, charset string) map[string]string {
+	if metadata == nil || charset == MetadataCharsetNone {
+		return metadata
+	}
+	decoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		var e error
+		var decodedValue string
+		if charset == MetadataCharsetPercent {
+			decodedValue, e = url.PathUnescape(value)
+		} else {
+			decodedValue, e = new(mime.WordDecoder).DecodeHeader(value)
+		}
+		if e == nil {
+			value = decodedValue
+		}
+		decoded[key] = value
+	}
+	return decoded
+}
@@ -0,0 +1,849 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mock implements an in-memory client.Client of object storage, for tests of code
+// using pkg/client without a server to talk to.
+package mock
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Store - buckets and objects shared by all clients created from it, safe for concurrent use.
+type Store struct {
+	mutex    sync.Mutex
+	buckets  map[string]*bucket
+	uploadID int
+}
+
+type bucket struct {
+	created time.Time
+	acl     string
+	objects map[string]*object
+	uploads map[string]*upload // by upload ID.
+}
+
+type object struct {
+	data         []byte
+	etag         string
+	modTime      time.Time
+	contentType  string
+	cacheControl string
+	metadata     map[string]string
+}
+
+type upload struct {
+	key       string
+	size      int64
+	initiated time.Time
+}
+
+// NewStore - store holding objects keyed by "bucket/object", buckets are created along with
+// their objects. A key without an object such as "bucket/" creates an empty bucket.
+func NewStore(objects map[string][]byte) *Store {
+	s := &Store{buckets: make(map[string]*bucket)}
+	for key, data := range objects {
+		bucketName, objectName := splitKey(key)
+		b := s.makeBucket(bucketName)
+		if objectName != "" {
+			b.objects[objectName] = newObject(data, "application/octet-stream", "", nil)
+		}
+	}
+	return s
+}
+
+// New - client of urlStr on the store, such as "https://mock.example/bucket/object".
+func (s *Store) New(urlStr string) (client.Client, *probe.Error) {
+	u := client.NewURL(urlStr)
+	if u.Type != client.Object {
+		return nil, probe.NewError(errors.New("‘" + urlStr + "’ is not an object storage URL, such as https://mock.example/bucket/object."))
+	}
+	return &mockClient{store: s, hostURL: u}, nil
+}
+
+// Object - data of the object at key "bucket/object", false if there is none.
+func (s *Store) Object(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	bucketName, objectName := splitKey(key)
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte{}, o.data...), true
+}
+
+// AddIncompleteUpload - start an upload to key "bucket/object" which never completes, size bytes
+// were uploaded so far. Its bucket must exist. Returns the upload ID.
+func (s *Store) AddIncompleteUpload(key string, size int64) (uploadID string, err *probe.Error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	bucketName, objectName := splitKey(key)
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return "", probe.NewError(noSuchBucket(bucketName))
+	}
+	if objectName == "" {
+		return "", probe.NewError(client.ObjectMissing{})
+	}
+	s.uploadID++
+	uploadID = "mock-upload-" + strconv.Itoa(s.uploadID)
+	b.uploads[uploadID] = &upload{key: objectName, size: size, initiated: time.Now().UTC()}
+	return uploadID, nil
+}
+
+// makeBucket - bucket of name, created if not present. Store must be locked.
+func (s *Store) makeBucket(name string) *bucket {
+	b, ok := s.buckets[name]
+	if !ok {
+		b = &bucket{
+			created: time.Now().UTC(),
+			acl:     "private",
+			objects: make(map[string]*object),
+			uploads: make(map[string]*upload),
+		}
+		s.buckets[name] = b
+	}
+	return b
+}
+
+func newObject(data []byte, contentType, cacheControl string, metadata map[string]string) *object {
+	sum := md5.Sum(data)
+	return &object{
+		data:         data,
+		etag:         hex.EncodeToString(sum[:]),
+		modTime:      time.Now().UTC(),
+		contentType:  contentType,
+		cacheControl: cacheControl,
+		metadata:     copyMetadata(metadata),
+	}
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
+
+// splitKey - bucket and object of a "bucket/object" key.
+func splitKey(key string) (bucketName, objectName string) {
+	key = strings.TrimPrefix(key, "/")
+	if i := strings.Index(key, "/"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+// Errors of the S3 API, as the s3 client returns them.
+func noSuchBucket(bucketName string) error {
+	return minio.ErrorResponse{Code: "NoSuchBucket", Message: "The specified bucket does not exist.", Resource: "/" + bucketName}
+}
+
+func noSuchKey(bucketName, objectName string) error {
+	return minio.ErrorResponse{Code: "NoSuchKey", Message: "The specified key does not exist.", Resource: "/" + bucketName + "/" + objectName}
+}
+
+// mockClient - client of a URL on a Store.
+type mockClient struct {
+	store   *Store
+	hostURL *client.URL
+	// ctx fails reads and stops listings once done, nil if never.
+	ctx context.Context
+}
+
+// GetURL - get url.
+func (c *mockClient) GetURL() client.URL {
+	return *c.hostURL
+}
+
+// WithContext - client whose reads and listings fail once ctx is done.
+func (c *mockClient) WithContext(ctx context.Context) client.Client {
+	return &mockClient{store: c.store, hostURL: c.hostURL, ctx: ctx}
+}
+
+func (c *mockClient) url2BucketAndObject() (bucketName, objectName string) {
+	return splitKey(c.hostURL.Path)
+}
+
+// objectURL - URL of objectName in bucketName, on the host of this client.
+func (c *mockClient) objectURL(bucketName, objectName string) client.URL {
+	u := *c.hostURL
+	u.Path = "/" + bucketName
+	if objectName != "" {
+		u.Path += "/" + objectName
+	}
+	return u
+}
+
+// lookup - object of this URL, the store must be locked.
+func (c *mockClient) lookup() (*object, error) {
+	bucketName, objectName := c.url2BucketAndObject()
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return nil, noSuchKey(bucketName, objectName)
+	}
+	return o, nil
+}
+
+/// Object operations.
+
+// Stat - metadata of an object, bucket or folder.
+func (c *mockClient) Stat() (*client.Content, *probe.Error) {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, objectName := c.url2BucketAndObject()
+	if bucketName == "" {
+		return &client.Content{URL: *c.hostURL, Type: os.ModeDir}, nil
+	}
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return nil, probe.NewError(noSuchBucket(bucketName))
+	}
+	if objectName == "" {
+		return &client.Content{URL: *c.hostURL, Time: b.created, Type: os.ModeDir}, nil
+	}
+	if o, ok := b.objects[objectName]; ok {
+		return &client.Content{
+			URL:          *c.hostURL,
+			Time:         o.modTime,
+			Size:         int64(len(o.data)),
+			Type:         os.FileMode(0664),
+			ETag:         o.etag,
+			ContentType:  o.contentType,
+			CacheControl: o.cacheControl,
+			StorageClass: "STANDARD",
+			Metadata:     copyMetadata(o.metadata),
+		}, nil
+	}
+	// A name any object is stored under is a folder.
+	prefix := strings.TrimSuffix(objectName, "/") + "/"
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			return &client.Content{URL: *c.hostURL, Type: os.ModeDir}, nil
+		}
+	}
+	return nil, probe.NewError(client.PathNotFound{Path: c.hostURL.Path})
+}
+
+// Get - get length bytes of the object from offset, the rest of it if length is 0.
+func (c *mockClient) Get(offset, length int64, versionID string) (io.ReadSeeker, *probe.Error) {
+	if versionID != "" {
+		return nil, probe.NewError(client.APINotImplemented{API: "Get version", APIType: "mock"})
+	}
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	o, e := c.lookup()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	size := int64(len(o.data))
+	if offset < 0 || length < 0 || offset > size {
+		return nil, probe.NewError(client.InvalidRange{Offset: offset})
+	}
+	end := size
+	if length > 0 && offset+length < size {
+		end = offset + length
+	}
+	return client.NewContextReader(c.ctx, bytes.NewReader(o.data[offset:end])), nil
+}
+
+// GetIfChanged - get the object only if it changed from a copy with etag, or without an etag
+// if it was modified since modTime.
+func (c *mockClient) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, *probe.Error) {
+	c.store.mutex.Lock()
+	o, e := c.lookup()
+	c.store.mutex.Unlock()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	notModified := !modTime.IsZero() && !o.modTime.After(modTime)
+	if etag != "" {
+		notModified = strings.Trim(etag, "\"") == o.etag
+	}
+	if notModified {
+		return nil, probe.NewError(client.NotModified{URL: c.hostURL.String()})
+	}
+	return c.Get(0, 0, "")
+}
+
+// Put - store size bytes of data as the object, all of it if size is negative, replacing it if present.
+func (c *mockClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	// Data of unknown size is read until EOF.
+	var reader io.Reader = data
+	if size >= 0 {
+		reader = io.LimitReader(data, size)
+	}
+	buf, e := ioutil.ReadAll(reader)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if int64(len(buf)) < size {
+		return probe.NewError(io.ErrUnexpectedEOF)
+	}
+	return c.put(buf, contentType, metadata)
+}
+
+// PutStream - store all of data as the object, replacing it if present.
+func (c *mockClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	buf, e := ioutil.ReadAll(data)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return c.put(buf, contentType, nil)
+}
+
+func (c *mockClient) put(data []byte, contentType string, metadata map[string]string) *probe.Error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, objectName := c.url2BucketAndObject()
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return probe.NewError(noSuchBucket(bucketName))
+	}
+	if objectName == "" {
+		return probe.NewError(client.ObjectMissing{})
+	}
+	metadata, cacheControl := splitCacheControl(metadata)
+	b.objects[objectName] = newObject(data, contentType, cacheControl, metadata)
+	return nil
+}
+
+// splitCacheControl - user metadata without the cache control it carries, and that cache control.
+func splitCacheControl(metadata map[string]string) (map[string]string, string) {
+	cacheControl := metadata[client.MetadataCacheControl]
+	if cacheControl == "" {
+		return metadata, ""
+	}
+	userMetadata := make(map[string]string)
+	for k, v := range metadata {
+		if k != client.MetadataCacheControl {
+			userMetadata[k] = v
+		}
+	}
+	return userMetadata, cacheControl
+}
+
+// SetMetadata - replace content type, cache control and user metadata of the object.
+func (c *mockClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	o, e := c.lookup()
+	if e != nil {
+		return probe.NewError(e)
+	}
+	o.contentType = contentType
+	o.cacheControl = cacheControl
+	o.metadata = copyMetadata(metadata)
+	o.modTime = time.Now().UTC()
+	return nil
+}
+
+// Copy - copy the object at source, a URL of the same store, to this URL. Tags are not kept by the store.
+func (c *mockClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	sourceClnt := &mockClient{store: c.store, hostURL: &source}
+	o, e := sourceClnt.lookup()
+	if e != nil {
+		if minio.ToErrorResponse(e).Code == "NoSuchKey" {
+			return probe.NewError(client.PathNotFound{Path: source.String()})
+		}
+		return probe.NewError(e)
+	}
+	bucketName, objectName := c.url2BucketAndObject()
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return probe.NewError(noSuchBucket(bucketName))
+	}
+	if objectName == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: bucketName, Object: objectName})
+	}
+	if options.MetadataDirective == client.DirectiveReplace {
+		metadata, cacheControl := splitCacheControl(options.Metadata)
+		b.objects[objectName] = newObject(o.data, options.ContentType, cacheControl, metadata)
+		return nil
+	}
+	b.objects[objectName] = newObject(o.data, o.contentType, o.cacheControl, o.metadata)
+	return nil
+}
+
+/// Delete operations.
+
+// Remove - remove the object, the bucket if the URL has no object, or the incomplete uploads
+// of the object. Like object storage, removing an object which is not present succeeds.
+func (c *mockClient) Remove(incomplete bool, versionID string) *probe.Error {
+	if versionID != "" {
+		return probe.NewError(client.APINotImplemented{API: "Remove version", APIType: "mock"})
+	}
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, objectName := c.url2BucketAndObject()
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return probe.NewError(noSuchBucket(bucketName))
+	}
+	switch {
+	case incomplete:
+		for uploadID, u := range b.uploads {
+			if u.key == objectName {
+				delete(b.uploads, uploadID)
+			}
+		}
+	case objectName == "":
+		if len(b.objects) > 0 || len(b.uploads) > 0 {
+			return probe.NewError(minio.ErrorResponse{Code: "BucketNotEmpty", Message: "The bucket you tried to delete is not empty.", Resource: "/" + bucketName})
+		}
+		delete(c.store.buckets, bucketName)
+	default:
+		delete(b.objects, objectName)
+	}
+	return nil
+}
+
+// RemoveBatch - remove every object read from contentCh.
+func (c *mockClient) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			clnt := &mockClient{store: c.store, hostURL: &content.URL}
+			content.Err = clnt.Remove(false, content.VersionID)
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+// RemoveUploads - abort every incomplete upload read from contentCh by its UploadID.
+func (c *mockClient) RemoveUploads(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			content.Err = c.removeUpload(content.URL, content.UploadID)
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+func (c *mockClient) removeUpload(u client.URL, uploadID string) *probe.Error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, objectName := splitKey(u.Path)
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return probe.NewError(noSuchBucket(bucketName))
+	}
+	if upload, ok := b.uploads[uploadID]; !ok || upload.key != objectName {
+		return probe.NewError(minio.ErrorResponse{Code: "NoSuchUpload", Message: "The specified upload does not exist.", Resource: u.Path})
+	}
+	delete(b.uploads, uploadID)
+	return nil
+}
+
+/// Bucket operations.
+
+// MakeBucket - make a new bucket.
+func (c *mockClient) MakeBucket() *probe.Error {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName != "" {
+		return probe.NewError(client.BucketNameTopLevel{})
+	}
+	if bucketName == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	if len(bucketName) < 3 || len(bucketName) > 63 {
+		return probe.NewError(client.InvalidBucketName{Bucket: bucketName})
+	}
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	if _, ok := c.store.buckets[bucketName]; ok {
+		return probe.NewError(minio.ErrorResponse{Code: "BucketAlreadyOwnedByYou", Message: "Your previous request to create the named bucket succeeded and you already own it.", Resource: "/" + bucketName})
+	}
+	c.store.makeBucket(bucketName)
+	return nil
+}
+
+// RemoveBucket - remove the bucket, which must hold neither objects nor incomplete uploads.
+func (c *mockClient) RemoveBucket() *probe.Error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	b, err := c.bucket()
+	if err != nil {
+		return err.Trace()
+	}
+	bucketName, _ := c.url2BucketAndObject()
+	if len(b.objects) > 0 || len(b.uploads) > 0 {
+		return probe.NewError(client.BucketNotEmpty{Bucket: bucketName})
+	}
+	delete(c.store.buckets, bucketName)
+	return nil
+}
+
+// bucket - bucket of a bucket URL, the store must be locked.
+func (c *mockClient) bucket() (*bucket, *probe.Error) {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName != "" {
+		return nil, probe.NewError(client.InvalidBucketName{Bucket: bucketName + "/" + objectName})
+	}
+	if bucketName == "" {
+		return nil, probe.NewError(client.BucketNameEmpty{})
+	}
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return nil, probe.NewError(noSuchBucket(bucketName))
+	}
+	return b, nil
+}
+
+// GetBucketAccess - get acl of a bucket.
+func (c *mockClient) GetBucketAccess() (string, *probe.Error) {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	b, err := c.bucket()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return b.acl, nil
+}
+
+// SetBucketAccess - set acl of a bucket, one of private, public-read, public-read-write or authenticated-read.
+func (c *mockClient) SetBucketAccess(acl string) *probe.Error {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read":
+	default:
+		return probe.NewError(minio.ErrorResponse{Code: "InvalidArgument", Message: "Invalid bucket acl ‘" + acl + "’."})
+	}
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	b, err := c.bucket()
+	if err != nil {
+		return err.Trace()
+	}
+	b.acl = acl
+	return nil
+}
+
+// GetAnonymousAccess - anonymous access to objects under the URL, granted by the acl of their bucket.
+func (c *mockClient) GetAnonymousAccess() (string, *probe.Error) {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, _ := c.url2BucketAndObject()
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return "", probe.NewError(noSuchBucket(bucketName))
+	}
+	return client.AnonymousAccess(strings.HasPrefix(b.acl, "public-read"), b.acl == "public-read-write"), nil
+}
+
+/// Listing.
+
+// List - list buckets, objects or incomplete uploads of objects under the URL, sorted by name.
+func (c *mockClient) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *client.Content {
+	doneCh, stop := client.ContextDone(c.ctx, doneCh)
+	contents := c.list(recursive, incomplete)
+	listCh := make(chan *client.Content)
+	go func() {
+		defer close(listCh)
+		for _, content := range contents {
+			listCh <- content
+		}
+	}()
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer stop()
+		client.ForwardContents(listCh, contentCh, doneCh)
+	}()
+	return contentCh
+}
+
+// list - contents List sends, taken all at once from the store.
+func (c *mockClient) list(recursive, incomplete bool) []*client.Content {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+	bucketName, objectName := c.url2BucketAndObject()
+	if bucketName == "" {
+		var names []string
+		for name := range c.store.buckets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var contents []*client.Content
+		for _, name := range names {
+			b := c.store.buckets[name]
+			if !incomplete {
+				contents = append(contents, &client.Content{URL: c.objectURL(name, ""), Time: b.created, Type: os.ModeDir})
+				if !recursive {
+					continue
+				}
+			}
+			contents = append(contents, c.listBucket(name, "", recursive, incomplete)...)
+		}
+		return contents
+	}
+	b, ok := c.store.buckets[bucketName]
+	if !ok {
+		return []*client.Content{{Err: probe.NewError(noSuchBucket(bucketName))}}
+	}
+	if !recursive && !incomplete {
+		// The bucket itself, or the object itself, unless its contents are asked for.
+		if objectName == "" && !strings.HasSuffix(c.hostURL.Path, "/") {
+			return []*client.Content{{URL: *c.hostURL, Time: b.created, Type: os.ModeDir}}
+		}
+		if o, ok := b.objects[objectName]; ok && objectName != "" {
+			return []*client.Content{c.objectContent(bucketName, objectName, o)}
+		}
+	}
+	return c.listBucket(bucketName, objectName, recursive, incomplete)
+}
+
+// listBucket - objects or incomplete uploads of bucketName whose name starts with prefix. Unless
+// recursive, names with a '/' after prefix are listed once as the folder up to the '/'.
+func (c *mockClient) listBucket(bucketName, prefix string, recursive, incomplete bool) []*client.Content {
+	b := c.store.buckets[bucketName]
+	var names []string
+	if incomplete {
+		for _, u := range b.uploads {
+			names = append(names, u.key)
+		}
+	} else {
+		for name := range b.objects {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var contents []*client.Content
+	listed := make(map[string]bool)
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || listed[name] {
+			continue
+		}
+		if i := strings.Index(name[len(prefix):], "/"); i >= 0 && !recursive {
+			folder := name[:len(prefix)+i+1]
+			if !listed[folder] {
+				listed[folder] = true
+				contents = append(contents, &client.Content{URL: c.objectURL(bucketName, folder), Time: time.Now(), Type: os.ModeDir})
+			}
+			continue
+		}
+		listed[name] = true
+		if !incomplete {
+			contents = append(contents, c.objectContent(bucketName, name, b.objects[name]))
+			continue
+		}
+		contents = append(contents, uploadContents(c.objectURL(bucketName, name), name, b.uploads)...)
+	}
+	return contents
+}
+
+func (c *mockClient) objectContent(bucketName, objectName string, o *object) *client.Content {
+	return &client.Content{
+		URL:          c.objectURL(bucketName, objectName),
+		Time:         o.modTime,
+		Size:         int64(len(o.data)),
+		Type:         os.FileMode(0664),
+		ETag:         o.etag,
+		StorageClass: "STANDARD",
+	}
+}
+
+// uploadContents - incomplete uploads to objectName, oldest first.
+func uploadContents(u client.URL, objectName string, uploads map[string]*upload) []*client.Content {
+	var contents []*client.Content
+	for uploadID, upload := range uploads {
+		if upload.key != objectName {
+			continue
+		}
+		contents = append(contents, &client.Content{
+			URL:      u,
+			Time:     upload.initiated,
+			Size:     upload.size,
+			Type:     os.ModeTemporary,
+			UploadID: uploadID,
+		})
+	}
+	sort.Slice(contents, func(i, j int) bool {
+		if contents[i].Time.Equal(contents[j].Time) {
+			return contents[i].UploadID < contents[j].UploadID
+		}
+		return contents[i].Time.Before(contents[j].Time)
+	})
+	return contents
+}
+
+// notImplemented - listing of a single error, for listings the store does not model.
+func notImplemented(api string) <-chan *client.Content {
+	contentCh := make(chan *client.Content, 1)
+	contentCh <- &client.Content{Err: probe.NewError(client.APINotImplemented{API: api, APIType: "mock"})}
+	close(contentCh)
+	return contentCh
+}
+
+// ListVersions - versioning is not modelled.
+func (c *mockClient) ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *client.Content {
+	return notImplemented("ListVersions")
+}
+
+// ListPage - listing in pages is not modelled.
+func (c *mockClient) ListPage(recursive bool, startAfter string, maxKeys int) ([]*client.Content, string, *probe.Error) {
+	return nil, "", probe.NewError(client.APINotImplemented{API: "ListPage", APIType: "mock"})
+}
+
+// ListParts - parts of incomplete uploads are not modelled.
+func (c *mockClient) ListParts(uploadID string, doneCh <-chan struct{}) <-chan *client.Content {
+	return notImplemented("ListParts")
+}
+
+/// Operations the store does not model.
+
+// GetBucketVersioning - not implemented.
+func (c *mockClient) GetBucketVersioning() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: "mock"})
+}
+
+// SetBucketVersioning - not implemented.
+func (c *mockClient) SetBucketVersioning(enable bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: "mock"})
+}
+
+// GetBucketAccelerate - not implemented.
+func (c *mockClient) GetBucketAccelerate() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketAccelerate", APIType: "mock"})
+}
+
+// SetBucketAccelerate - not implemented.
+func (c *mockClient) SetBucketAccelerate(enable bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketAccelerate", APIType: "mock"})
+}
+
+// GetBucketReplication - not implemented.
+func (c *mockClient) GetBucketReplication() (client.ReplicationConfig, *probe.Error) {
+	return client.ReplicationConfig{}, probe.NewError(client.APINotImplemented{API: "GetBucketReplication", APIType: "mock"})
+}
+
+// SetBucketReplication - not implemented.
+func (c *mockClient) SetBucketReplication(config client.ReplicationConfig) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketReplication", APIType: "mock"})
+}
+
+// GetBucketPolicy - not implemented.
+func (c *mockClient) GetBucketPolicy() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: "mock"})
+}
+
+// SetBucketPolicy - not implemented.
+func (c *mockClient) SetBucketPolicy(policy string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: "mock"})
+}
+
+// GetBucketRegion - not implemented.
+func (c *mockClient) GetBucketRegion() (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketRegion", APIType: "mock"})
+}
+
+// ShareDownload - not implemented.
+func (c *mockClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "ShareDownload", APIType: "mock"})
+}
+
+// ShareUpload - not implemented.
+func (c *mockClient) ShareUpload(options client.ShareUploadOptions) (map[string]string, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "ShareUpload", APIType: "mock"})
+}
+
+// GetTags - not implemented.
+func (c *mockClient) GetTags() (map[string]string, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "GetTags", APIType: "mock"})
+}
+
+// SetTags - not implemented.
+func (c *mockClient) SetTags(tags map[string]string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetTags", APIType: "mock"})
+}
+
+// GetObjectACL - not implemented.
+func (c *mockClient) GetObjectACL() (client.ACL, *probe.Error) {
+	return client.ACL{}, probe.NewError(client.APINotImplemented{API: "GetObjectACL", APIType: "mock"})
+}
+
+// SetObjectACL - not implemented.
+func (c *mockClient) SetObjectACL(acl client.ACL) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetObjectACL", APIType: "mock"})
+}
+
+// GetRetention - not implemented.
+func (c *mockClient) GetRetention() (string, time.Time, *probe.Error) {
+	return "", time.Time{}, probe.NewError(client.APINotImplemented{API: "GetRetention", APIType: "mock"})
+}
+
+// SetRetention - not implemented.
+func (c *mockClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetRetention", APIType: "mock"})
+}
+
+// GetLegalHold - not implemented.
+func (c *mockClient) GetLegalHold() (bool, *probe.Error) {
+	return false, probe.NewError(client.APINotImplemented{API: "GetLegalHold", APIType: "mock"})
+}
+
+// SetLegalHold - not implemented.
+func (c *mockClient) SetLegalHold(enabled bool) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetLegalHold", APIType: "mock"})
+}
+
+// RestoreArchived - not implemented.
+func (c *mockClient) RestoreArchived(days int, tier string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RestoreArchived", APIType: "mock"})
+}
+
+// RestoreVersion - not implemented.
+func (c *mockClient) RestoreVersion(versionID string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RestoreVersion", APIType: "mock"})
+}
@@ -0,0 +1,271 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/mock"
+	"github.com/minio/minio-go"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func newClient(c *C, store *mock.Store, path string) client.Client {
+	clnt, err := store.New("https://mock.example" + path)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+// listPaths - paths and types listed under path.
+func listPaths(c *C, store *mock.Store, path string, recursive, incomplete bool) []string {
+	var paths []string
+	for content := range newClient(c, store, path).List(recursive, incomplete, nil) {
+		c.Assert(content.Err, IsNil)
+		path := content.URL.Path
+		switch {
+		case content.Type.IsDir():
+			path += " dir"
+		case content.Type&os.ModeTemporary != 0:
+			path += " upload " + content.UploadID
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func errorCode(err error) string {
+	errResponse := minio.ToErrorResponse(err)
+	if errResponse == nil {
+		return ""
+	}
+	return errResponse.Code
+}
+
+func (s *MySuite) TestNew(c *C) {
+	store := mock.NewStore(nil)
+	_, err := store.New("bucket/object")
+	c.Assert(err, NotNil)
+	clnt, err := store.New("https://mock.example/bucket/object")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.GetURL().String(), Equals, "https://mock.example/bucket/object")
+}
+
+func (s *MySuite) TestStatGetPut(c *C) {
+	store := mock.NewStore(map[string][]byte{
+		"bucket/dir/object": []byte("hello world"),
+		"empty/":            nil,
+	})
+
+	content, err := newClient(c, store, "/bucket/dir/object").Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(11))
+	c.Assert(content.ETag, Equals, "5eb63bbbe01eeed093cb22bb8f5acdc3")
+	c.Assert(content.Type.IsRegular(), Equals, true)
+	for _, path := range []string{"/", "/bucket", "/bucket/dir", "/bucket/dir/", "/empty"} {
+		content, err = newClient(c, store, path).Stat()
+		c.Assert(err, IsNil, Commentf("%s", path))
+		c.Assert(content.Type.IsDir(), Equals, true, Commentf("%s", path))
+	}
+
+	// Missing objects and buckets fail as they do on object storage.
+	_, err = newClient(c, store, "/bucket/missing").Stat()
+	c.Assert(err, NotNil)
+	_, ok := err.ToGoError().(client.PathNotFound)
+	c.Assert(ok, Equals, true)
+	_, err = newClient(c, store, "/missing/object").Stat()
+	c.Assert(errorCode(err.ToGoError()), Equals, "NoSuchBucket")
+	_, err = newClient(c, store, "/bucket/missing").Get(0, 0, "")
+	c.Assert(errorCode(err.ToGoError()), Equals, "NoSuchKey")
+
+	reader, err := newClient(c, store, "/bucket/dir/object").Get(6, 3, "")
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "wor")
+	_, err = newClient(c, store, "/bucket/dir/object").Get(12, 0, "")
+	c.Assert(err, NotNil)
+
+	clnt := newClient(c, store, "/empty/new")
+	c.Assert(clnt.Put(bytes.NewReader([]byte("new data")), 8, "text/plain", map[string]string{"owner": "me"}), IsNil)
+	content, err = clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.ContentType, Equals, "text/plain")
+	c.Assert(content.Metadata, DeepEquals, map[string]string{"owner": "me"})
+	data, ok = store.Object("empty/new")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "new data")
+	// Short data fails the upload.
+	c.Assert(newClient(c, store, "/empty/short").Put(bytes.NewReader([]byte("abc")), 4, "", nil), NotNil)
+	c.Assert(newClient(c, store, "/missing/new").PutStream(bytes.NewReader([]byte("abc")), 0, ""), NotNil)
+
+	// An unchanged object is not downloaded again.
+	_, err = clnt.GetIfChanged(content.ETag, time.Time{})
+	_, ok = err.ToGoError().(client.NotModified)
+	c.Assert(ok, Equals, true)
+	reader, err = clnt.GetIfChanged("other", time.Time{})
+	c.Assert(err, IsNil)
+	data, _ = ioutil.ReadAll(reader)
+	c.Assert(string(data), Equals, "new data")
+
+	c.Assert(newClient(c, store, "/bucket/copy").Copy(clnt.GetURL(), client.CopyOptions{}), IsNil)
+	data, ok = store.Object("bucket/copy")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "new data")
+}
+
+func (s *MySuite) TestList(c *C) {
+	store := mock.NewStore(map[string][]byte{
+		"bucket/a":       []byte("a"),
+		"bucket/dir/b":   []byte("b"),
+		"bucket/dir/c/d": []byte("d"),
+		"bucket/dir0":    []byte("e"),
+		"other/f":        []byte("f"),
+	})
+	c.Assert(listPaths(c, store, "/", false, false), DeepEquals, []string{"/bucket dir", "/other dir"})
+	c.Assert(listPaths(c, store, "/", true, false), DeepEquals,
+		[]string{"/bucket dir", "/bucket/a", "/bucket/dir/b", "/bucket/dir/c/d", "/bucket/dir0", "/other dir", "/other/f"})
+	c.Assert(listPaths(c, store, "/bucket", false, false), DeepEquals, []string{"/bucket dir"})
+	c.Assert(listPaths(c, store, "/bucket/", false, false), DeepEquals,
+		[]string{"/bucket/a", "/bucket/dir/ dir", "/bucket/dir0"})
+	c.Assert(listPaths(c, store, "/bucket/dir/", false, false), DeepEquals,
+		[]string{"/bucket/dir/b", "/bucket/dir/c/ dir"})
+	c.Assert(listPaths(c, store, "/bucket/dir", true, false), DeepEquals,
+		[]string{"/bucket/dir/b", "/bucket/dir/c/d", "/bucket/dir0"})
+	c.Assert(listPaths(c, store, "/bucket/a", false, false), DeepEquals, []string{"/bucket/a"})
+
+	// A missing bucket is listed as a single error.
+	var contents []*client.Content
+	for content := range newClient(c, store, "/missing/").List(true, false, nil) {
+		contents = append(contents, content)
+	}
+	c.Assert(len(contents), Equals, 1)
+	c.Assert(errorCode(contents[0].Err.ToGoError()), Equals, "NoSuchBucket")
+
+	// Listing stops once doneCh is closed.
+	doneCh := make(chan struct{})
+	listCh := newClient(c, store, "/").List(true, false, doneCh)
+	<-listCh
+	close(doneCh)
+	for range listCh {
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	listed := 0
+	for range newClient(c, store, "/").WithContext(ctx).List(true, false, nil) {
+		listed++
+	}
+	c.Assert(listed < 7, Equals, true)
+}
+
+func (s *MySuite) TestIncompleteUploads(c *C) {
+	store := mock.NewStore(map[string][]byte{"bucket/object": []byte("data")})
+	first, err := store.AddIncompleteUpload("bucket/dir/upload", 100)
+	c.Assert(err, IsNil)
+	second, err := store.AddIncompleteUpload("bucket/dir/upload", 200)
+	c.Assert(err, IsNil)
+	other, err := store.AddIncompleteUpload("bucket/top", 5)
+	c.Assert(err, IsNil)
+	_, err = store.AddIncompleteUpload("missing/upload", 5)
+	c.Assert(err, NotNil)
+
+	// Uploads are listed apart from objects, each upload of an object on its own.
+	c.Assert(listPaths(c, store, "/bucket/", true, true), DeepEquals, []string{
+		"/bucket/dir/upload upload " + first, "/bucket/dir/upload upload " + second, "/bucket/top upload " + other,
+	})
+	c.Assert(listPaths(c, store, "/bucket/", false, true), DeepEquals, []string{"/bucket/dir/ dir", "/bucket/top upload " + other})
+	c.Assert(listPaths(c, store, "/", true, true), DeepEquals, []string{
+		"/bucket/dir/upload upload " + first, "/bucket/dir/upload upload " + second, "/bucket/top upload " + other,
+	})
+
+	// A bucket with uploads is not empty.
+	c.Assert(newClient(c, store, "/bucket/object").Remove(false, ""), IsNil)
+	err = newClient(c, store, "/bucket").Remove(false, "")
+	c.Assert(errorCode(err.ToGoError()), Equals, "BucketNotEmpty")
+
+	contentCh := make(chan *client.Content, 2)
+	contentCh <- &client.Content{URL: newClient(c, store, "/bucket/dir/upload").GetURL(), UploadID: first}
+	contentCh <- &client.Content{URL: newClient(c, store, "/bucket/top").GetURL(), UploadID: first}
+	close(contentCh)
+	var results []*client.Content
+	for content := range newClient(c, store, "/bucket").RemoveUploads(contentCh) {
+		results = append(results, content)
+	}
+	c.Assert(results[0].Err, IsNil)
+	c.Assert(errorCode(results[1].Err.ToGoError()), Equals, "NoSuchUpload")
+
+	c.Assert(newClient(c, store, "/bucket/dir/upload").Remove(true, ""), IsNil)
+	c.Assert(newClient(c, store, "/bucket/top").Remove(true, ""), IsNil)
+	c.Assert(listPaths(c, store, "/bucket/", true, true), IsNil)
+	c.Assert(newClient(c, store, "/bucket").Remove(false, ""), IsNil)
+	c.Assert(listPaths(c, store, "/", false, false), IsNil)
+}
+
+func (s *MySuite) TestBuckets(c *C) {
+	store := mock.NewStore(nil)
+	clnt := newClient(c, store, "/bucket")
+	c.Assert(clnt.MakeBucket(), IsNil)
+	err := clnt.MakeBucket()
+	c.Assert(errorCode(err.ToGoError()), Equals, "BucketAlreadyOwnedByYou")
+	c.Assert(newClient(c, store, "/bucket/object").MakeBucket(), NotNil)
+
+	access, err := clnt.GetBucketAccess()
+	c.Assert(err, IsNil)
+	c.Assert(access, Equals, "private")
+	c.Assert(clnt.SetBucketAccess("public-read"), IsNil)
+	c.Assert(clnt.SetBucketAccess("everyone"), NotNil)
+	access, err = clnt.GetBucketAccess()
+	c.Assert(err, IsNil)
+	c.Assert(access, Equals, "public-read")
+	access, err = newClient(c, store, "/bucket/object").GetAnonymousAccess()
+	c.Assert(err, IsNil)
+	c.Assert(access, Equals, client.AnonymousReadOnly)
+	_, err = newClient(c, store, "/missing").GetBucketAccess()
+	c.Assert(errorCode(err.ToGoError()), Equals, "NoSuchBucket")
+
+	// Removing objects not present succeeds, as it does on object storage.
+	c.Assert(newClient(c, store, "/bucket/object").Put(bytes.NewReader([]byte("x")), 1, "", nil), IsNil)
+	contentCh := make(chan *client.Content, 2)
+	contentCh <- &client.Content{URL: newClient(c, store, "/bucket/object").GetURL()}
+	contentCh <- &client.Content{URL: newClient(c, store, "/bucket/missing").GetURL()}
+	close(contentCh)
+	for content := range clnt.RemoveBatch(contentCh) {
+		c.Assert(content.Err, IsNil)
+	}
+	_, ok := store.Object("bucket/object")
+	c.Assert(ok, Equals, false)
+
+	// Operations the store does not model fail as not implemented.
+	_, err = clnt.GetBucketPolicy()
+	_, ok = err.ToGoError().(client.APINotImplemented)
+	c.Assert(ok, Equals, true)
+	for content := range clnt.ListVersions(true, nil) {
+		_, ok = content.Err.ToGoError().(client.APINotImplemented)
+		c.Assert(ok, Equals, true)
+	}
+}
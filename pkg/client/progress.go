@@ -0,0 +1,92 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import "io"
+
+// ProgressFunc - called with the number of bytes transferred since its previous call, n is negative
+// when a transfer goes back to send bytes again. The deltas of a transfer which completes add up to
+// the size of the object.
+//
+// It is called on the I/O path of every transfer, by the goroutine reading the data and without
+// locks held, so transfers in parallel call it concurrently. It must be safe for concurrent use and
+// return quickly, anything slow belongs on a goroutine of its own.
+type ProgressFunc func(n int64)
+
+// NewProgressReader - r reporting the bytes read and the offsets moved by Seek to progress, r itself
+// if progress is nil. The reader reads at offsets too if r is an io.ReaderAt, each of those reads is
+// reported as well.
+func NewProgressReader(r io.ReadSeeker, progress ProgressFunc) io.ReadSeeker {
+	if progress == nil {
+		return r
+	}
+	reader := &progressReader{ReadSeeker: r, progress: progress}
+	if readerAt, ok := r.(io.ReaderAt); ok {
+		return &progressReaderAt{progressReader: reader, readerAt: readerAt}
+	}
+	return reader
+}
+
+// progressReader - reports transfers of a ReadSeeker to progress, offset is where it reads next.
+type progressReader struct {
+	io.ReadSeeker
+	progress ProgressFunc
+	offset   int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, e := r.ReadSeeker.Read(p)
+	if n > 0 {
+		r.offset += int64(n)
+		r.progress(int64(n))
+	}
+	return n, e
+}
+
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	n, e := r.ReadSeeker.Seek(offset, whence)
+	if e != nil {
+		return n, e
+	}
+	if n != r.offset {
+		r.progress(n - r.offset)
+		r.offset = n
+	}
+	return n, nil
+}
+
+// Close - closes the underlying reader if it is a closer.
+func (r *progressReader) Close() error {
+	if closer, ok := r.ReadSeeker.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// progressReaderAt - progressReader of an io.ReaderAt, parts read in parallel report concurrently.
+type progressReaderAt struct {
+	*progressReader
+	readerAt io.ReaderAt
+}
+
+func (r *progressReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, e := r.readerAt.ReadAt(p, off)
+	if n > 0 {
+		r.progress(int64(n))
+	}
+	return n, e
+}
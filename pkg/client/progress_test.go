@@ -0,0 +1,61 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestProgressReader(c *C) {
+	var deltas []int64
+	progress := func(n int64) { deltas = append(deltas, n) }
+
+	// A retry going back reports the bytes it sends again as negative.
+	reader := NewProgressReader(strings.NewReader("0123456789"), progress)
+	_, ok := reader.(io.ReaderAt)
+	c.Assert(ok, Equals, true)
+	_, e := io.CopyN(ioutil.Discard, reader, 6)
+	c.Assert(e, IsNil)
+	_, e = reader.Seek(2, 0)
+	c.Assert(e, IsNil)
+	_, e = io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	var sum int64
+	for _, n := range deltas {
+		sum += n
+	}
+	c.Assert(sum, Equals, int64(10))
+	c.Assert(deltas[1], Equals, int64(-4))
+
+	// Resuming at an offset reports the bytes skipped.
+	deltas = nil
+	reader = NewProgressReader(bytes.NewReader(make([]byte, 100)), progress)
+	_, e = reader.Seek(60, 0)
+	c.Assert(e, IsNil)
+	_, e = io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	c.Assert(deltas, DeepEquals, []int64{60, 40})
+
+	// Without a callback the reader is not wrapped.
+	plain := strings.NewReader("x")
+	c.Assert(NewProgressReader(plain, nil), Equals, io.ReadSeeker(plain))
+}
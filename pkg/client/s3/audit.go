@@ -0,0 +1,63 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go"
+)
+
+// auditRecord - a line of the signature audit, what a request was signed for. Neither the
+// signature nor the credentials are part of it.
+type auditRecord struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	Host          string    `json:"host"`
+	Path          string    `json:"path"`
+	Presigned     bool      `json:"presigned,omitempty"`
+	Algorithm     string    `json:"algorithm"`
+	Scope         string    `json:"scope,omitempty"`
+	SignedHeaders []string  `json:"signedHeaders"`
+	Payload       string    `json:"payload,omitempty"`
+	Correlation   string    `json:"correlation,omitempty"`
+}
+
+// newAuditor - records signatures to w as one line of JSON each, written with a single Write.
+// Records carry correlationID, the requests of one command share it.
+func newAuditor(w io.Writer, correlationID string) func(audit minio.SignatureAudit) {
+	return func(audit minio.SignatureAudit) {
+		line, e := json.Marshal(auditRecord{
+			Time:          audit.Time.UTC(),
+			Method:        audit.Method,
+			Host:          audit.Host,
+			Path:          audit.Path,
+			Presigned:     audit.Presigned,
+			Algorithm:     audit.Algorithm,
+			Scope:         audit.Scope,
+			SignedHeaders: audit.SignedHeaders,
+			Payload:       audit.Payload,
+			Correlation:   correlationID,
+		})
+		if e != nil {
+			return
+		}
+		w.Write(append(line, '\n'))
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultDNSCacheTTL is how long addresses of an endpoint host are reused before it is resolved again.
+const DefaultDNSCacheTTL = 30 * time.Second
+
+// dnsEntry - addresses of a host, next is the one the following dial starts with.
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+	next    int
+}
+
+// dnsCache - resolves endpoint hosts once per ttl, dials rotate across their addresses.
+// Hosts reached through a proxy are not cached, the proxy resolves them.
+type dnsCache struct {
+	mutex   sync.Mutex
+	entries map[string]*dnsEntry
+	ttl     time.Duration
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	proxy   func(*http.Request) (*url.URL, error)
+	now     func() time.Time
+}
+
+// newDNSCache - cache of lookup results, kept for ttl.
+func newDNSCache(lookup func(ctx context.Context, host string) ([]string, error), ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		entries: make(map[string]*dnsEntry),
+		ttl:     ttl,
+		lookup:  lookup,
+		proxy:   http.ProxyFromEnvironment,
+		now:     time.Now,
+	}
+}
+
+// endpointDNS is shared by all transports, so that parallel transfers resolve a host once.
+var endpointDNS = newDNSCache(net.DefaultResolver.LookupHost, DefaultDNSCacheTTL)
+
+// isProxied - true if requests to addr go through a proxy, which is then the address dialed.
+// Hosts matching NO_PROXY are dialed directly.
+func (d *dnsCache) isProxied(addr string) bool {
+	for _, scheme := range []string{"http", "https"} {
+		proxyURL, e := d.proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+		if e != nil || proxyURL != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve - addresses of host, starting with the next one in turn. Cached reports
+// whether they were taken from the cache rather than looked up.
+func (d *dnsCache) resolve(ctx context.Context, host string) (addrs []string, cached bool, e error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	entry, ok := d.entries[host]
+	if !ok || !d.now().Before(entry.expires) {
+		// Lookups of a host are not deduplicated, parallel dials may resolve it more than once.
+		found, e := d.lookup(ctx, host)
+		if e != nil {
+			delete(d.entries, host)
+			return nil, false, e
+		}
+		entry = &dnsEntry{addrs: found, expires: d.now().Add(d.ttl)}
+		d.entries[host] = entry
+	} else {
+		cached = true
+	}
+	addrs = make([]string, 0, len(entry.addrs))
+	addrs = append(addrs, entry.addrs[entry.next:]...)
+	addrs = append(addrs, entry.addrs[:entry.next]...)
+	entry.next = (entry.next + 1) % len(entry.addrs)
+	return addrs, cached, nil
+}
+
+// forget - drop the addresses of host, the next dial resolves it again.
+func (d *dnsCache) forget(host string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.entries, host)
+}
+
+// dialer - wrap dial to connect to the cached addresses of the host of addr. Once none of
+// the cached addresses can be reached, the host is resolved again and its fresh addresses
+// are tried, before giving up.
+func (d *dnsCache) dialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, e := net.SplitHostPort(addr)
+		if e != nil || net.ParseIP(host) != nil || d.isProxied(addr) {
+			return dial(ctx, network, addr)
+		}
+		for {
+			addrs, cached, e := d.resolve(ctx, host)
+			if e != nil {
+				return nil, e
+			}
+			var conn net.Conn
+			for _, ip := range addrs {
+				if conn, e = dial(ctx, network, net.JoinHostPort(ip, port)); e == nil {
+					return conn, nil
+				}
+			}
+			d.forget(host)
+			if !cached {
+				return nil, e
+			}
+		}
+	}
+}
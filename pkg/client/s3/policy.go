@@ -0,0 +1,212 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// policyStrings - policy field holding a string or a list of strings.
+type policyStrings []string
+
+func (p *policyStrings) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*p = policyStrings{s}
+		return nil
+	}
+	var list []string
+	if e := json.Unmarshal(data, &list); e != nil {
+		return e
+	}
+	*p = list
+	return nil
+}
+
+// policyStatement - the parts of a statement which decide anonymous access.
+type policyStatement struct {
+	Effect       string
+	Principal    json.RawMessage
+	NotPrincipal json.RawMessage
+	Action       policyStrings
+	NotAction    policyStrings
+	Resource     policyStrings
+	NotResource  policyStrings
+	Condition    json.RawMessage
+}
+
+// isAnonymous - statement applies to everyone.
+func (s policyStatement) isAnonymous() bool {
+	if len(s.NotPrincipal) > 0 {
+		return false
+	}
+	var principal policyStrings
+	if json.Unmarshal(s.Principal, &principal) == nil {
+		return principal.contains("*")
+	}
+	aws := struct {
+		AWS policyStrings
+	}{}
+	if json.Unmarshal(s.Principal, &aws) == nil {
+		return aws.AWS.contains("*")
+	}
+	return false
+}
+
+// hasAction - statement applies to action.
+func (s policyStatement) hasAction(action string) bool {
+	if len(s.NotAction) > 0 {
+		return !s.NotAction.matchAny(action)
+	}
+	return s.Action.matchAny(action)
+}
+
+// allows - statement allows action on every object under resource. Statements with
+// conditions or a NotResource may not apply and never allow, so the result is sound.
+func (s policyStatement) allows(action, resource string) bool {
+	if s.Effect != "Allow" || len(s.Condition) > 0 || len(s.NotResource) > 0 || !s.isAnonymous() || !s.hasAction(action) {
+		return false
+	}
+	for _, pattern := range s.Resource {
+		if coversPrefix(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// denies - statement may deny action on some object under resource, conditions are assumed to hold.
+func (s policyStatement) denies(action, resource string) bool {
+	if s.Effect != "Deny" || !s.isAnonymous() || !s.hasAction(action) {
+		return false
+	}
+	if len(s.NotResource) > 0 {
+		return true
+	}
+	for _, pattern := range s.Resource {
+		if matchesPrefix(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p policyStrings) contains(s string) bool {
+	for _, v := range p {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny - action matches one of the patterns, actions are case insensitive.
+func (p policyStrings) matchAny(action string) bool {
+	for _, pattern := range p {
+		if wildcardMatch(strings.ToLower(pattern), strings.ToLower(action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch - s matches pattern, ‘*’ matches any run of characters and ‘?’ any single one.
+func wildcardMatch(pattern, s string) bool {
+	if pattern == "" {
+		return s == ""
+	}
+	switch pattern[0] {
+	case '*':
+		return wildcardMatch(pattern[1:], s) || (s != "" && wildcardMatch(pattern, s[1:]))
+	case '?':
+		return s != "" && wildcardMatch(pattern[1:], s[1:])
+	}
+	return s != "" && pattern[0] == s[0] && wildcardMatch(pattern[1:], s[1:])
+}
+
+// coversPrefix - pattern matches every string starting with prefix.
+func coversPrefix(pattern, prefix string) bool {
+	if prefix == "" {
+		return pattern != "" && strings.Trim(pattern, "*") == ""
+	}
+	if pattern == "" {
+		return false
+	}
+	switch pattern[0] {
+	case '*':
+		return coversPrefix(pattern[1:], prefix) || coversPrefix(pattern, prefix[1:])
+	case '?':
+		return coversPrefix(pattern[1:], prefix[1:])
+	}
+	return pattern[0] == prefix[0] && coversPrefix(pattern[1:], prefix[1:])
+}
+
+// matchesPrefix - pattern matches some string starting with prefix.
+func matchesPrefix(pattern, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if pattern == "" {
+		return false
+	}
+	switch pattern[0] {
+	case '*':
+		return matchesPrefix(pattern[1:], prefix) || matchesPrefix(pattern, prefix[1:])
+	case '?':
+		return matchesPrefix(pattern[1:], prefix[1:])
+	}
+	return pattern[0] == prefix[0] && matchesPrefix(pattern[1:], prefix[1:])
+}
+
+// policyAccess - effective anonymous access granted by policy to every object of bucket under
+// prefix. Access is granted only if an anonymous statement allows it for all such objects and
+// none denies it for any of them.
+func policyAccess(policy, bucket, prefix string) (string, error) {
+	if policy == "" {
+		return client.AnonymousNone, nil
+	}
+	document := struct {
+		Statement json.RawMessage
+	}{}
+	if e := json.Unmarshal([]byte(policy), &document); e != nil {
+		return "", e
+	}
+	var statements []policyStatement
+	if e := json.Unmarshal(document.Statement, &statements); e != nil {
+		// A single statement need not be a list.
+		var statement policyStatement
+		if e := json.Unmarshal(document.Statement, &statement); e != nil {
+			return "", e
+		}
+		statements = []policyStatement{statement}
+	}
+	resource := "arn:aws:s3:::" + bucket + "/" + prefix
+	isGranted := func(action string) bool {
+		allowed := false
+		for _, statement := range statements {
+			if statement.denies(action, resource) {
+				return false
+			}
+			allowed = allowed || statement.allows(action, resource)
+		}
+		return allowed
+	}
+	return client.AnonymousAccess(isGranted("s3:GetObject"), isGranted("s3:PutObject")), nil
+}
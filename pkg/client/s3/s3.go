@@ -17,13 +17,17 @@
 package s3
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"hash/fnv"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -40,6 +44,19 @@ type s3Client struct {
 	api          minio.CloudStorageAPI
 	hostURL      *client.URL
 	virtualStyle bool
+	// basePath is stripped from URL paths before splitting them into
+	// bucket and object, and prepended to paths of listed objects.
+	basePath string
+	// disableMultipart is set when uploads are limited to a single PUT.
+	disableMultipart bool
+	// metadataCharset encodes user metadata values outside printable ASCII, RFC 2047 if empty.
+	metadataCharset string
+	// transport is shared with all clients of equal transport settings.
+	transport *http.Transport
+	// ctx cancels requests and listings once done, nil if never.
+	ctx context.Context
+	// progress is reported the bytes transferred, nil if not.
+	progress client.ProgressFunc
 }
 
 // newFactory encloses New function with client cache.
@@ -50,22 +67,38 @@ func newFactory() func(config *client.Config) (client.Client, *probe.Error) {
 	// Return New function.
 	return func(config *client.Config) (client.Client, *probe.Error) {
 		u := client.NewURL(config.HostURL)
-		transport := http.DefaultTransport
+		basePath := cleanBasePath(config.BasePath)
+		settings := newTransportSettings(config)
+		sharedTransport := transports.get(settings)
+		var transport http.RoundTripper = sharedTransport
+		if config.Phases != nil {
+			transport = httptracer.PhaseTransport{Recorder: config.Phases, Transport: transport}
+		}
+		transport = expectContinueTransport{transport}
+		// Requests to the host, of any client, stay within its budget, lowered while it answers SlowDown.
+		transport = budgetTransport{RoundTripper: transport, budget: hostBudgets.get(u.Host, settings.maxConnsPerHost), debug: config.Debug}
+		var trace *Trace
 		if config.Debug == true {
-			if config.Signature == "S3v4" {
-				transport = httptracer.GetNewTraceTransport(NewTraceV4(), http.DefaultTransport)
-			}
 			if config.Signature == "S3v2" {
-				transport = httptracer.GetNewTraceTransport(NewTraceV2(), http.DefaultTransport)
+				trace = NewTraceV2(config.DebugCompact)
+			} else {
+				trace = NewTraceV4(config.DebugCompact)
 			}
+			transport = httptracer.GetNewTraceTransport(trace, transport)
 		}
 
 		// New S3 configuration.
 		s3Conf := minio.Config{
-			AccessKeyID:     config.AccessKey,
-			SecretAccessKey: config.SecretKey,
-			Transport:       transport,
-			Endpoint:        u.Scheme + u.SchemeSeparator + u.Host,
+			AccessKeyID:      config.AccessKey,
+			SecretAccessKey:  config.SecretKey,
+			Transport:        transport,
+			Endpoint:         u.Scheme + u.SchemeSeparator + u.Host + basePath,
+			Region:           config.Region,
+			PartConcurrency:  config.PartConcurrency,
+			DisableMultipart: config.DisableMultipart,
+			StorageClass:     config.StorageClass,
+			CannedACL:        config.CannedACL,
+			Accelerate:       config.Accelerate,
 			Signature: func() minio.SignatureType {
 				if config.Signature == "S3v2" {
 					return minio.SignatureV2
@@ -74,11 +107,31 @@ func newFactory() func(config *client.Config) (client.Client, *probe.Error) {
 			}(),
 		}
 
+		if trace != nil {
+			s3Conf.TraceSignature = trace.TraceSignature
+		}
+		s3Conf.ChecksumAlgorithm = config.ChecksumAlgorithm
+		s3Conf.ClockSkewed = config.ClockSkewed
+		if config.Audit != nil {
+			s3Conf.AuditSignature = newAuditor(config.Audit, config.CorrelationID)
+		}
 		s3Conf.SetUserAgent(config.AppName, config.AppVersion, config.AppComments...)
+		s3Conf.AppendUserAgent(config.UserAgentSuffix)
+		if config.CorrelationID != "" {
+			s3Conf.Header = http.Header{CorrelationHeader: {config.CorrelationID}}
+		}
 
 		// Generate a hash out of s3Conf.
 		confHash := fnv.New32a()
-		confHash.Write([]byte(s3Conf.Endpoint + s3Conf.AccessKeyID + s3Conf.SecretAccessKey))
+		confHash.Write([]byte(s3Conf.Endpoint + s3Conf.AccessKeyID + s3Conf.SecretAccessKey + config.Signature + config.Region))
+		confHash.Write([]byte(settings.connTimeout.String() + settings.readTimeout.String() + settings.idleTimeout.String()))
+		confHash.Write([]byte(settings.keepAliveTimeout.String() + strconv.Itoa(settings.maxConnsPerHost)))
+		confHash.Write([]byte(strconv.FormatBool(config.DisableMultipart) + config.StorageClass + config.ChecksumAlgorithm))
+		confHash.Write([]byte(strconv.FormatBool(config.Accelerate) + config.CannedACL))
+		confHash.Write([]byte(config.UserAgentSuffix + config.CorrelationID))
+		// Clients auditing to different writers, or recording phases elsewhere, never share an API.
+		confHash.Write([]byte(fmt.Sprintf("%p", config.Audit)))
+		confHash.Write([]byte(fmt.Sprintf("%p", config.Phases)))
 		confSum := confHash.Sum32()
 
 		// Lookup previous cache by hash.
@@ -96,17 +149,29 @@ func newFactory() func(config *client.Config) (client.Client, *probe.Error) {
 			// Cache the new minio client with hash of config as key.
 			clientCache[confSum] = api
 		}
+		if config.EncryptionKey != nil {
+			api = api.WithCustomerKey(config.EncryptionKey)
+		}
 
 		s3Clnt := &s3Client{
 			mu:           new(sync.Mutex),
 			api:          api,
 			hostURL:      u,
 			virtualStyle: isVirtualHostStyle(u.Host),
+			basePath:     basePath,
+
+			disableMultipart: config.DisableMultipart,
+			metadataCharset:  config.MetadataCharset,
+			transport:        sharedTransport,
+			progress:         config.Progress,
 		}
 		return s3Clnt, nil
 	}
 }
 
+// CorrelationHeader - header carrying Config.CorrelationID, ties the requests of a command together in server logs.
+const CorrelationHeader = "x-amz-request-correlation"
+
 // New returns an initialized s3Client structure. If debug is enabled,
 // it also enables an internal trace transport.
 var New = newFactory()
@@ -116,101 +181,913 @@ func (c *s3Client) GetURL() client.URL {
 	return *c.hostURL
 }
 
-// Get - get object.
-func (c *s3Client) Get(offset, length int64) (io.ReadSeeker, *probe.Error) {
+// WithContext - client whose requests are cancelled once ctx is done.
+func (c *s3Client) WithContext(ctx context.Context) client.Client {
+	clnt := *c
+	clnt.api = c.api.WithContext(ctx)
+	clnt.ctx = ctx
+	return &clnt
+}
+
+// Get - get object.
+func (c *s3Client) Get(offset, length int64, versionID string) (io.ReadSeeker, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	var reader io.ReadSeeker
+	var e error
+	if versionID != "" {
+		reader, e = c.api.GetObjectVersion(bucket, object, versionID)
+	} else {
+		reader, e = c.api.GetPartialObject(bucket, object, offset, length)
+	}
+	if e != nil {
+		if isTimeout(e) {
+			return nil, probe.NewError(client.Timeout{Op: "Get", URL: c.hostURL.String()})
+		}
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return nil, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return nil, probe.NewError(e)
+	}
+	return client.NewProgressReader(timeoutReadSeeker{ReadSeeker: reader, url: c.hostURL.String()}, c.progress), nil
+}
+
+// GetIfChanged - get object with a conditional request, only if it changed from a copy with etag
+// or since modTime.
+func (c *s3Client) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	reader, e := c.api.GetObjectIfChanged(bucket, object, etag, modTime)
+	if e != nil {
+		if isTimeout(e) {
+			return nil, probe.NewError(client.Timeout{Op: "Get", URL: c.hostURL.String()})
+		}
+		if errResponse := minio.ToErrorResponse(e); errResponse != nil {
+			switch errResponse.Code {
+			case "NotModified":
+				return nil, probe.NewError(client.NotModified{URL: c.hostURL.String()})
+			case "AccessDenied":
+				return nil, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return nil, probe.NewError(e)
+	}
+	return client.NewProgressReader(timeoutReadSeeker{ReadSeeker: reader, url: c.hostURL.String()}, c.progress), nil
+}
+
+// Remove - remove object or bucket, or a specific version of an object.
+func (c *s3Client) Remove(incomplete bool, versionID string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if incomplete {
+		errCh := c.api.RemoveIncompleteUpload(bucket, object)
+		return probe.NewError(<-errCh)
+	}
+	var e error
+	if versionID != "" {
+		if object == "" {
+			return probe.NewError(client.ObjectMissing{})
+		}
+		e = c.api.RemoveObjectVersion(bucket, object, versionID)
+	} else if object == "" {
+		e = c.api.RemoveBucket(bucket)
+	} else {
+		e = c.api.RemoveObject(bucket, object)
+	}
+	return probe.NewError(e)
+}
+
+// RemoveBatch - remove objects with multi-object delete, up to 1000 keys per request. Contents
+// with a VersionID have that version removed permanently.
+func (c *s3Client) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go c.removeBatchInRoutine(contentCh, resultCh)
+	return resultCh
+}
+
+// RemoveUploads - abort incomplete uploads one by one, there is no multi-upload abort.
+func (c *s3Client) RemoveUploads(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			contentClnt := *c
+			contentClnt.hostURL = &content.URL
+			bucket, object := contentClnt.url2BucketAndObject()
+			if e := c.api.AbortMultipartUpload(bucket, object, content.UploadID); e != nil {
+				content.Err = probe.NewError(e).Trace(content.URL.String(), content.UploadID)
+			}
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+func (c *s3Client) removeBatchInRoutine(contentCh <-chan *client.Content, resultCh chan<- *client.Content) {
+	defer close(resultCh)
+
+	// Contents waiting for their result, by object name and version.
+	pendingMutex := &sync.Mutex{}
+	pending := make(map[minio.ObjectVersionKey][]*client.Content)
+
+	var bucket string
+	var objectsCh chan minio.ObjectVersionKey
+	var drainDone chan struct{}
+	// finish waits for all results of the current bucket.
+	finish := func() {
+		if objectsCh == nil {
+			return
+		}
+		close(objectsCh)
+		<-drainDone
+	}
+	for content := range contentCh {
+		contentClnt := *c
+		contentClnt.hostURL = &content.URL
+		contentBucket, object := contentClnt.url2BucketAndObject()
+		if object == "" {
+			content.Err = probe.NewError(client.ObjectMissing{}).Trace(content.URL.String())
+			resultCh <- content
+			continue
+		}
+		// Multi-object delete works on a single bucket, start over on a new bucket.
+		if objectsCh == nil || contentBucket != bucket {
+			finish()
+			bucket = contentBucket
+			objectsCh = make(chan minio.ObjectVersionKey)
+			drainDone = make(chan struct{})
+			go func(results <-chan minio.RemoveObjectResult, drainDone chan<- struct{}) {
+				defer close(drainDone)
+				for result := range results {
+					key := minio.ObjectVersionKey{ObjectName: result.ObjectName, VersionID: result.VersionID}
+					pendingMutex.Lock()
+					contents := pending[key]
+					content := contents[0]
+					if len(contents) == 1 {
+						delete(pending, key)
+					} else {
+						pending[key] = contents[1:]
+					}
+					pendingMutex.Unlock()
+					if result.Err != nil {
+						content.Err = probe.NewError(result.Err).Trace(content.URL.String())
+					}
+					resultCh <- content
+				}
+			}(c.api.RemoveObjectVersions(bucket, objectsCh), drainDone)
+		}
+		key := minio.ObjectVersionKey{ObjectName: object, VersionID: content.VersionID}
+		pendingMutex.Lock()
+		pending[key] = append(pending[key], content)
+		pendingMutex.Unlock()
+		objectsCh <- key
+	}
+	finish()
+}
+
+// ShareDownload - get a usable presigned object url to share.
+func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	presignedURL, e := c.api.PresignedGetObject(bucket, object, expires)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return presignedURL, nil
+}
+
+// timeNow - current time, replaced in tests for reproducible signatures.
+var timeNow = time.Now
+
+// ShareUpload - get data for presigned post http form upload, upload size is restricted if maxSize is positive.
+func (c *s3Client) ShareUpload(options client.ShareUploadOptions) (map[string]string, *probe.Error) {
+	if err := options.Validate(); err != nil {
+		return nil, err.Trace(c.hostURL.String())
+	}
+	bucket, object := c.url2BucketAndObject()
+	p := minio.NewPostPolicy()
+	now := timeNow().UTC()
+	if e := p.SetExpires(now.Add(options.Expires)); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e := p.SetDate(now); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if options.MinSize > 0 || options.MaxSize > 0 {
+		maxSize := options.MaxSize
+		if maxSize == 0 {
+			maxSize = client.MaxShareUploadSize
+		}
+		if e := p.SetContentLength(int(options.MinSize), int(maxSize)); e != nil {
+			return nil, probe.NewError(e)
+		}
+	}
+	switch {
+	case strings.HasSuffix(options.ContentType, "/"):
+		if e := p.SetContentTypeStartsWith(options.ContentType); e != nil {
+			return nil, probe.NewError(e)
+		}
+	case options.ContentType != "":
+		if e := p.SetContentType(options.ContentType); e != nil {
+			return nil, probe.NewError(e)
+		}
+	}
+	if e := p.SetBucket(bucket); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if options.Recursive {
+		if e := p.SetKeyStartsWith(object); e != nil {
+			return nil, probe.NewError(e)
+		}
+	} else {
+		if e := p.SetKey(object); e != nil {
+			return nil, probe.NewError(e)
+		}
+	}
+	m, e := c.api.PresignedPostPolicy(p)
+	return m, probe.NewError(e)
+}
+
+// Put - put object.
+func (c *s3Client) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
+	// for a multipart upload and there is no need to cross verify,
+	// invidual parts are properly verified fully in transit and also upon completion
+	// of the multipart request.
+	// An empty content type is not sent, the server applies its default.
+	bucket, object := c.url2BucketAndObject()
+	metadata, err := client.EncodeMetadata(metadata, c.metadataCharset)
+	if err != nil {
+		return err.Trace(c.hostURL.String())
+	}
+	data = client.NewProgressReader(data, c.progress)
+	e := c.api.PutObjectWithMetadata(bucket, object, data, size, contentType, metadata)
+	isMultipart := !c.disableMultipart && (size < 0 || size >= multipartThreshold)
+	return c.putError(e, object, isMultipart)
+}
+
+// PutStream - put a stream of unknown length, uploading parts of partSize as they fill.
+func (c *s3Client) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	// Failed streams are aborted right away, nothing is left to resume.
+	e := c.api.PutObjectStream(bucket, object, data, partSize, contentType)
+	return c.putError(e, object, false)
+}
+
+// multipartThreshold - objects of this size and larger are uploaded in parts, failed multipart
+// uploads are kept on the server to be resumed.
+const multipartThreshold = 5 * 1024 * 1024
+
+// putError - translate errors of upload operations, failures of a multipart upload report the
+// incomplete upload.
+func (c *s3Client) putError(e error, object string, isMultipart bool) *probe.Error {
+	if e == nil {
+		return nil
+	}
+	incomplete := func(err error) *probe.Error {
+		if !isMultipart {
+			return probe.NewError(err)
+		}
+		return probe.NewError(client.IncompleteUpload{URL: c.hostURL.String(), Err: err})
+	}
+	if isTimeout(e) {
+		return incomplete(client.Timeout{Op: "Put", URL: c.hostURL.String()})
+	}
+	errResponse := minio.ToErrorResponse(e)
+	if errResponse != nil {
+		if errResponse.Code == "AccessDenied" {
+			return probe.NewError(client.PathInsufficientPermission{
+				Path: c.hostURL.String(),
+			})
+		}
+		if errResponse.Code == "MethodNotAllowed" {
+			return probe.NewError(client.ObjectAlreadyExists{
+				Object: object,
+			})
+		}
+		if errResponse.Code == "InvalidArgument" {
+			return probe.NewError(client.ObjectMissing{})
+		}
+		if errResponse.Code == "EntityTooLarge" && c.disableMultipart {
+			return probe.NewError(client.SinglePutTooLarge{Object: object})
+		}
+	}
+	return incomplete(e)
+}
+
+// MakeBucket - make a new bucket.
+func (c *s3Client) MakeBucket() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return probe.NewError(client.BucketNameTopLevel{})
+	}
+	if len(bucket) < 3 || len(bucket) > 63 {
+		return probe.NewError(errors.New("Bucket name should be more than 3 characters and less than 64 characters"))
+	}
+	match, _ := regexp.MatchString("^[a-zA-Z][a-zA-Z0-9\\-]+[a-zA-Z0-9]$", bucket)
+	if !match {
+		return probe.NewError(errors.New("Bucket name can contain alphabet, '-' and numbers, but first character should be an alphabet"))
+	}
+
+	e := c.api.MakeBucket(bucket, minio.BucketACL("private"))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// RemoveBucket - remove the bucket, the server refuses while it holds objects.
+func (c *s3Client) RemoveBucket() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return probe.NewError(client.BucketNameTopLevel{})
+	}
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	e := c.api.RemoveBucket(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			switch errResponse.Code {
+			case "BucketNotEmpty":
+				return probe.NewError(client.BucketNotEmpty{Bucket: bucket})
+			case "NoSuchBucket":
+				return probe.NewError(client.PathNotFound{Path: c.hostURL.String()})
+			case "AccessDenied":
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetBucketAccess get acl on a bucket.
+func (c *s3Client) GetBucketAccess() (acl string, err *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return "", probe.NewError(client.InvalidBucketName{Bucket: filepath.Join(bucket, object)})
+	}
+	if bucket == "" {
+		return "", probe.NewError(client.BucketNameEmpty{})
+	}
+	bucketACL, e := c.api.GetBucketACL(bucket)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return bucketACL.String(), nil
+}
+
+// SetBucketAccess set acl on a bucket
+func (c *s3Client) SetBucketAccess(acl string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return probe.NewError(client.InvalidBucketName{Bucket: filepath.Join(bucket, object)})
+	}
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	e := c.api.SetBucketACL(bucket, minio.BucketACL(acl))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetBucketVersioning - get versioning state of a bucket, one of "Enabled", "Suspended" or empty if never enabled.
+func (c *s3Client) GetBucketVersioning() (string, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(client.BucketNameEmpty{})
+	}
+	status, e := c.api.GetBucketVersioning(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return "", probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: "s3"})
+			}
+		}
+		return "", probe.NewError(e)
+	}
+	return status, nil
+}
+
+// regionCache - regions of buckets looked up so far, by host and bucket.
+var regionCache = struct {
+	sync.Mutex
+	regions map[string]string
+}{regions: make(map[string]string)}
+
+// GetBucketRegion - region of the bucket, looked up once per host and bucket.
+func (c *s3Client) GetBucketRegion() (string, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(client.BucketNameEmpty{})
+	}
+	key := c.hostURL.Host + "/" + bucket
+	regionCache.Lock()
+	region, ok := regionCache.regions[key]
+	regionCache.Unlock()
+	if ok {
+		return region, nil
+	}
+	region, e := c.api.GetBucketLocation(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return "", probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return "", probe.NewError(client.APINotImplemented{API: "GetBucketRegion", APIType: "s3"})
+			}
+		}
+		return "", probe.NewError(e)
+	}
+	regionCache.Lock()
+	regionCache.regions[key] = region
+	regionCache.Unlock()
+	return region, nil
+}
+
+// regionErrorCodes - error codes of requests signed for a region the bucket is not in.
+var regionErrorCodes = map[string]bool{
+	"AuthorizationHeaderMalformed": true,
+	"SignatureDoesNotMatch":        true,
+	"PermanentRedirect":            true,
+	"400 Bad Request":              true, // HEAD responses carry no error code.
+	"301 Moved Permanently":        true,
+}
+
+// VerifyRegion - preflight with a cheap signed request that the bucket is in the
+// region requests are signed for. On a signature or region error the region of the
+// bucket is looked up and RegionMismatch returned if it differs. Other errors are
+// left to the operation that follows.
+func (c *s3Client) VerifyRegion() *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil
+	}
+	e := c.api.BucketExists(bucket)
+	if e == nil {
+		return nil
+	}
+	errResponse := minio.ToErrorResponse(e)
+	if errResponse == nil || (!regionErrorCodes[errResponse.Code] && errResponse.AmzBucketRegion == "") {
+		return nil
+	}
+	region := errResponse.AmzBucketRegion
+	if region != "" {
+		regionCache.Lock()
+		regionCache.regions[c.hostURL.Host+"/"+bucket] = region
+		regionCache.Unlock()
+	} else {
+		var err *probe.Error
+		if region, err = c.GetBucketRegion(); err != nil {
+			return nil
+		}
+	}
+	if region == c.Region() {
+		return nil
+	}
+	return probe.NewError(client.RegionMismatch{Bucket: bucket, Region: region, SigningRegion: c.Region()})
+}
+
+// SetBucketVersioning - enable or suspend versioning of a bucket.
+func (c *s3Client) SetBucketVersioning(enable bool) *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	status := minio.VersioningSuspended
+	if enable {
+		status = minio.VersioningEnabled
+	}
+	if e := c.api.SetBucketVersioning(bucket, status); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: "s3"})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetBucketAccelerate - get Transfer Acceleration state of a bucket, one of "Enabled", "Suspended" or empty if never enabled.
+func (c *s3Client) GetBucketAccelerate() (string, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(client.BucketNameEmpty{})
+	}
+	status, e := c.api.GetBucketAccelerate(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return "", probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return "", probe.NewError(client.APINotImplemented{API: "GetBucketAccelerate", APIType: "s3"})
+			}
+		}
+		return "", probe.NewError(e)
+	}
+	return status, nil
+}
+
+// SetBucketAccelerate - enable or suspend Transfer Acceleration of a bucket.
+func (c *s3Client) SetBucketAccelerate(enable bool) *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	status := minio.AccelerateSuspended
+	if enable {
+		status = minio.AccelerateEnabled
+	}
+	if e := c.api.SetBucketAccelerate(bucket, status); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return probe.NewError(client.APINotImplemented{API: "SetBucketAccelerate", APIType: "s3"})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// replicationStatus - replication status of enabled.
+func replicationStatus(enabled bool) string {
+	if enabled {
+		return minio.ReplicationEnabled
+	}
+	return minio.ReplicationDisabled
+}
+
+// GetBucketReplication - get replication rules of a bucket, none if the bucket is not replicated.
+func (c *s3Client) GetBucketReplication() (client.ReplicationConfig, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return client.ReplicationConfig{}, probe.NewError(client.BucketNameEmpty{})
+	}
+	config, e := c.api.GetBucketReplication(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return client.ReplicationConfig{}, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return client.ReplicationConfig{}, probe.NewError(client.APINotImplemented{API: "GetBucketReplication", APIType: "s3"})
+			}
+		}
+		return client.ReplicationConfig{}, probe.NewError(e)
+	}
+	replication := client.ReplicationConfig{Role: config.Role}
+	for _, rule := range config.Rules {
+		replication.Rules = append(replication.Rules, client.ReplicationRule{
+			ID:            rule.ID,
+			Priority:      rule.Priority,
+			Prefix:        rule.Filter.Prefix,
+			Destination:   rule.Destination.Bucket,
+			StorageClass:  rule.Destination.StorageClass,
+			Enabled:       rule.Status == minio.ReplicationEnabled,
+			DeleteMarkers: rule.DeleteMarkerReplication.Status == minio.ReplicationEnabled,
+		})
+	}
+	return replication, nil
+}
+
+// SetBucketReplication - replace replication rules of a bucket, a config without rules removes them.
+func (c *s3Client) SetBucketReplication(replication client.ReplicationConfig) *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	config := minio.ReplicationConfig{Role: replication.Role}
+	for _, rule := range replication.Rules {
+		config.Rules = append(config.Rules, minio.ReplicationRule{
+			ID:                      rule.ID,
+			Priority:                rule.Priority,
+			Status:                  replicationStatus(rule.Enabled),
+			Filter:                  minio.ReplicationFilter{Prefix: rule.Prefix},
+			Destination:             minio.ReplicationDestination{Bucket: rule.Destination, StorageClass: rule.StorageClass},
+			DeleteMarkerReplication: minio.ReplicationDeleteMarkerRule{Status: replicationStatus(rule.DeleteMarkers)},
+		})
+	}
+	if e := c.api.SetBucketReplication(bucket, config); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return probe.NewError(client.APINotImplemented{API: "SetBucketReplication", APIType: "s3"})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetBucketPolicy - get JSON policy document of a bucket, empty if the bucket has no policy.
+func (c *s3Client) GetBucketPolicy() (string, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(client.BucketNameEmpty{})
+	}
+	policy, e := c.api.GetBucketPolicy(bucket)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return "", probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: "s3"})
+			}
+		}
+		return "", probe.NewError(e)
+	}
+	return policy, nil
+}
+
+// GetAnonymousAccess - effective anonymous access to objects under the URL, granted by the bucket policy.
+func (c *s3Client) GetAnonymousAccess() (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	policy, err := c.GetBucketPolicy()
+	if err != nil {
+		return "", err.Trace()
+	}
+	access, e := policyAccess(policy, bucket, object)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return access, nil
+}
+
+// SetBucketPolicy - replace JSON policy document of a bucket, an empty policy removes it.
+func (c *s3Client) SetBucketPolicy(policy string) *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	if e := c.api.SetBucketPolicy(bucket, policy); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+			if errResponse.Code == "NotImplemented" {
+				return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: "s3"})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetTags - get tags on an object, or on the bucket if URL resolves to a bucket root.
+func (c *s3Client) GetTags() (map[string]string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil, probe.NewError(client.BucketNameEmpty{})
+	}
+	tags, e := c.api.GetObjectTagging(bucket, object)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "NoSuchTagSet" {
+				return map[string]string{}, nil
+			}
+			if errResponse.Code == "AccessDenied" {
+				return nil, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return nil, probe.NewError(e)
+	}
+	return tags, nil
+}
+
+// GetObjectACL - get owner and grants of an object.
+func (c *s3Client) GetObjectACL() (client.ACL, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return client.ACL{}, probe.NewError(client.BucketNameEmpty{})
+	}
+	if object == "" {
+		return client.ACL{}, probe.NewError(client.ObjectMissing{})
+	}
+	objectACL, e := c.api.GetObjectACL(bucket, object)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil && errResponse.Code == "AccessDenied" {
+			return client.ACL{}, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+		}
+		return client.ACL{}, probe.NewError(e)
+	}
+	acl := client.ACL{OwnerID: objectACL.OwnerID, OwnerDisplayName: objectACL.OwnerDisplayName}
+	for _, g := range objectACL.Grants {
+		acl.Grants = append(acl.Grants, client.Grant{
+			Type:        g.GranteeType,
+			ID:          g.ID,
+			DisplayName: g.DisplayName,
+			Email:       g.EmailAddress,
+			URI:         g.URI,
+			Permission:  g.Permission,
+		})
+	}
+	return acl, nil
+}
+
+// SetObjectACL - replace grants of an object, by those of a canned ACL if acl has one.
+func (c *s3Client) SetObjectACL(acl client.ACL) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	if object == "" {
+		return probe.NewError(client.ObjectMissing{})
+	}
+	var e error
+	if acl.Canned != "" {
+		e = c.api.SetObjectCannedACL(bucket, object, acl.Canned)
+	} else {
+		objectACL := minio.ObjectACL{OwnerID: acl.OwnerID, OwnerDisplayName: acl.OwnerDisplayName}
+		for _, g := range acl.Grants {
+			objectACL.Grants = append(objectACL.Grants, minio.ObjectGrant{
+				GranteeType:  g.Type,
+				ID:           g.ID,
+				DisplayName:  g.DisplayName,
+				EmailAddress: g.Email,
+				URI:          g.URI,
+				Permission:   g.Permission,
+			})
+		}
+		e = c.api.SetObjectACL(bucket, object, objectACL)
+	}
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil && errResponse.Code == "AccessDenied" {
+			return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// SetTags - replace tags on an object, or on the bucket if URL resolves to a bucket root.
+// An empty tag set removes all the tags.
+func (c *s3Client) SetTags(tags map[string]string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
+	}
+	e := c.api.SetObjectTagging(bucket, object, tags)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetRetention - get retention mode and date of an object, mode is empty if no retention is set.
+func (c *s3Client) GetRetention() (string, time.Time, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	objectRetention, e := c.api.GetObjectRetention(bucket, object)
+	if e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "NoSuchObjectLockConfiguration" {
+				return "", time.Time{}, nil
+			}
+			if errResponse.Code == "AccessDenied" {
+				return "", time.Time{}, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return "", time.Time{}, probe.NewError(e)
+	}
+	return objectRetention.Mode, objectRetention.RetainUntilDate, nil
+}
+
+// SetRetention - set retention mode and date of an object.
+func (c *s3Client) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	objectRetention := minio.ObjectRetention{Mode: mode, RetainUntilDate: retainUntil}
+	if e := c.api.SetObjectRetention(bucket, object, objectRetention, bypassGovernance); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetLegalHold - get legal hold of an object.
+func (c *s3Client) GetLegalHold() (bool, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
-	reader, e := c.api.GetPartialObject(bucket, object, offset, length)
+	status, e := c.api.GetObjectLegalHold(bucket, object)
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse != nil {
+			if errResponse.Code == "NoSuchObjectLockConfiguration" {
+				return false, nil
+			}
 			if errResponse.Code == "AccessDenied" {
-				return nil, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+				return false, probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
 			}
 		}
-		return nil, probe.NewError(e)
+		return false, probe.NewError(e)
 	}
-	return reader, nil
+	return status == minio.LegalHoldOn, nil
 }
 
-// Remove - remove object or bucket.
-func (c *s3Client) Remove(incomplete bool) *probe.Error {
+// SetLegalHold - place or release a legal hold on an object.
+func (c *s3Client) SetLegalHold(enabled bool) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
-	if incomplete {
-		errCh := c.api.RemoveIncompleteUpload(bucket, object)
-		return probe.NewError(<-errCh)
+	status := minio.LegalHoldOff
+	if enabled {
+		status = minio.LegalHoldOn
 	}
-	var e error
-	if object == "" {
-		e = c.api.RemoveBucket(bucket)
-	} else {
-		e = c.api.RemoveObject(bucket, object)
-	}
-	return probe.NewError(e)
-}
-
-// ShareDownload - get a usable presigned object url to share.
-func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
-	bucket, object := c.url2BucketAndObject()
-	presignedURL, e := c.api.PresignedGetObject(bucket, object, expires)
-	if e != nil {
-		return "", probe.NewError(e)
+	if e := c.api.SetObjectLegalHold(bucket, object, status); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			if errResponse.Code == "AccessDenied" {
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			}
+		}
+		return probe.NewError(e)
 	}
-	return presignedURL, nil
+	return nil
 }
 
-// ShareUpload - get data for presigned post http form upload.
-func (c *s3Client) ShareUpload(isRecursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+// SetMetadata - replace content type, cache control and user metadata of an object by copying
+// it onto itself, the data is not transferred. Servers unable to copy return APINotImplemented.
+func (c *s3Client) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
-	p := minio.NewPostPolicy()
-	if e := p.SetExpires(time.Now().UTC().Add(expires)); e != nil {
-		return nil, probe.NewError(e)
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
 	}
-	if strings.TrimSpace(contentType) != "" || contentType != "" {
-		// No need to verify for error here, since we have stripped out spaces.
-		p.SetContentType(contentType)
+	if object == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: bucket, Object: object})
 	}
-	if e := p.SetBucket(bucket); e != nil {
-		return nil, probe.NewError(e)
+	metadata, err := client.EncodeMetadata(metadata, c.metadataCharset)
+	if err != nil {
+		return err.Trace(c.hostURL.String())
 	}
-	if isRecursive {
-		if e := p.SetKeyStartsWith(object); e != nil {
-			return nil, probe.NewError(e)
-		}
-	} else {
-		if e := p.SetKey(object); e != nil {
-			return nil, probe.NewError(e)
+	if e := c.api.ReplaceObjectMetadata(bucket, object, contentType, cacheControl, metadata); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			switch errResponse.Code {
+			case "AccessDenied":
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			case "NotImplemented":
+				return probe.NewError(client.APINotImplemented{API: "SetMetadata", APIType: "s3"})
+			}
 		}
+		return probe.NewError(e)
 	}
-	m, e := c.api.PresignedPostPolicy(p)
-	return m, probe.NewError(e)
+	return nil
 }
 
-// Put - put object.
-func (c *s3Client) Put(data io.ReadSeeker, size int64, contentType string) *probe.Error {
-	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
-	// for a multipart upload and there is no need to cross verify,
-	// invidual parts are properly verified fully in transit and also upon completion
-	// of the multipart request.
+// RestoreVersion - copy versionID of an object over its latest version, server side.
+func (c *s3Client) RestoreVersion(versionID string) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
 	}
-	e := c.api.PutObject(bucket, object, data, size, contentType)
-	if e != nil {
+	if object == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: bucket, Object: object})
+	}
+	if e := c.api.RestoreObjectVersion(bucket, object, versionID); e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse != nil {
-			if errResponse.Code == "AccessDenied" {
-				return probe.NewError(client.PathInsufficientPermission{
-					Path: c.hostURL.String(),
-				})
-			}
-			if errResponse.Code == "MethodNotAllowed" {
-				return probe.NewError(client.ObjectAlreadyExists{
-					Object: object,
-				})
-			}
-			if errResponse.Code == "InvalidArgument" {
-				return probe.NewError(client.ObjectMissing{})
+			switch errResponse.Code {
+			case "AccessDenied":
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			case "NotImplemented":
+				return probe.NewError(client.APINotImplemented{API: "RestoreVersion", APIType: "s3"})
 			}
 		}
 		return probe.NewError(e)
@@ -218,54 +1095,76 @@ func (c *s3Client) Put(data io.ReadSeeker, size int64, contentType string) *prob
 	return nil
 }
 
-// MakeBucket - make a new bucket.
-func (c *s3Client) MakeBucket() *probe.Error {
+// RestoreArchived - initiate the restore of an archived object, restores already in progress fail
+// with RestoreInProgress, objects not archived with NotArchived.
+func (c *s3Client) RestoreArchived(days int, tier string) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
-	if object != "" {
-		return probe.NewError(client.BucketNameTopLevel{})
-	}
-	if len(bucket) < 3 || len(bucket) > 63 {
-		return probe.NewError(errors.New("Bucket name should be more than 3 characters and less than 64 characters"))
+	if bucket == "" {
+		return probe.NewError(client.BucketNameEmpty{})
 	}
-	match, _ := regexp.MatchString("^[a-zA-Z][a-zA-Z0-9\\-]+[a-zA-Z0-9]$", bucket)
-	if !match {
-		return probe.NewError(errors.New("Bucket name can contain alphabet, '-' and numbers, but first character should be an alphabet"))
+	if object == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: bucket, Object: object})
 	}
-
-	e := c.api.MakeBucket(bucket, minio.BucketACL("private"))
-	if e != nil {
+	if e := c.api.RestoreObject(bucket, object, days, tier); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			switch errResponse.Code {
+			case "RestoreAlreadyInProgress":
+				return probe.NewError(client.RestoreInProgress{URL: c.hostURL.String()})
+			case "InvalidObjectState":
+				return probe.NewError(client.NotArchived{URL: c.hostURL.String()})
+			case "AccessDenied":
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			case "NotImplemented":
+				return probe.NewError(client.APINotImplemented{API: "RestoreArchived", APIType: "s3"})
+			}
+		}
 		return probe.NewError(e)
 	}
 	return nil
 }
 
-// GetBucketAccess get acl on a bucket.
-func (c *s3Client) GetBucketAccess() (acl string, err *probe.Error) {
+// Copy - copy the object at source, on the same host, to this URL server side.
+func (c *s3Client) Copy(source client.URL, options client.CopyOptions) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
-	if object != "" {
-		return "", probe.NewError(client.InvalidBucketName{Bucket: filepath.Join(bucket, object)})
-	}
 	if bucket == "" {
-		return "", probe.NewError(client.BucketNameEmpty{})
-	}
-	bucketACL, e := c.api.GetBucketACL(bucket)
-	if e != nil {
-		return "", probe.NewError(e)
+		return probe.NewError(client.BucketNameEmpty{})
 	}
-	return bucketACL.String(), nil
-}
-
-// SetBucketAccess set acl on a bucket
-func (c *s3Client) SetBucketAccess(acl string) *probe.Error {
-	bucket, object := c.url2BucketAndObject()
-	if object != "" {
-		return probe.NewError(client.InvalidBucketName{Bucket: filepath.Join(bucket, object)})
+	if object == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: bucket, Object: object})
 	}
-	if bucket == "" {
+	sourceClnt := *c
+	sourceClnt.hostURL = &source
+	sourceBucket, sourceObject := sourceClnt.url2BucketAndObject()
+	if sourceBucket == "" {
 		return probe.NewError(client.BucketNameEmpty{})
 	}
-	e := c.api.SetBucketACL(bucket, minio.BucketACL(acl))
-	if e != nil {
+	if sourceObject == "" {
+		return probe.NewError(client.InvalidObjectName{Bucket: sourceBucket, Object: sourceObject})
+	}
+	userMetadata, err := client.EncodeMetadata(options.Metadata, c.metadataCharset)
+	if err != nil {
+		return err.Trace(c.hostURL.String())
+	}
+	directives := minio.CopyDirectives{
+		Metadata:     options.MetadataDirective,
+		ContentType:  options.ContentType,
+		UserMetadata: userMetadata,
+		Tagging:      options.TaggingDirective,
+		Tags:         options.Tags,
+	}
+	if e := c.api.CopyObjectWithDirectives(bucket, object, sourceBucket, sourceObject, directives); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		if errResponse != nil {
+			switch errResponse.Code {
+			case "AccessDenied":
+				return probe.NewError(client.PathInsufficientPermission{Path: c.hostURL.String()})
+			case "NoSuchKey":
+				return probe.NewError(client.PathNotFound{Path: source.String()})
+			case "NotImplemented":
+				return probe.NewError(client.APINotImplemented{API: "Copy", APIType: "s3"})
+			}
+		}
 		return probe.NewError(e)
 	}
 	return nil
@@ -292,6 +1191,9 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 		metadata, e := c.api.StatObject(bucket, object)
 		if e != nil {
 			c.mu.Unlock()
+			if isTimeout(e) {
+				return nil, probe.NewError(client.Timeout{Op: "Stat", URL: c.hostURL.String()})
+			}
 			errResponse := minio.ToErrorResponse(e)
 			if errResponse != nil {
 				if errResponse.Code == "NoSuchKey" {
@@ -300,7 +1202,10 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 					prefixName := object
 					// Trim any trailing separators and add it.
 					prefixName = strings.TrimSuffix(prefixName, string(c.hostURL.Separator)) + string(c.hostURL.Separator)
-					for objectStat := range c.api.ListObjects(bucket, prefixName, false) {
+					// Only the first entry is needed, stop listing upon return.
+					doneCh := make(chan struct{})
+					defer close(doneCh)
+					for objectStat := range c.api.ListObjects(bucket, prefixName, false, doneCh) {
 						if objectStat.Err != nil {
 							return nil, probe.NewError(objectStat.Err)
 						}
@@ -317,6 +1222,20 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 		objectMetadata.URL = *c.hostURL
 		objectMetadata.Time = metadata.LastModified
 		objectMetadata.Size = metadata.Size
+		objectMetadata.ETag = metadata.ETag
+		objectMetadata.StorageClass = metadata.StorageClass
+		objectMetadata.ContentType = metadata.ContentType
+		objectMetadata.CacheControl = metadata.CacheControl
+		objectMetadata.ContentDisposition = metadata.ContentDisposition
+		objectMetadata.ContentEncoding = metadata.ContentEncoding
+		objectMetadata.Metadata = client.DecodeMetadata(metadata.Metadata, c.metadataCharset)
+		objectMetadata.Expires = metadata.Expires
+		objectMetadata.ExpiryDate = metadata.ExpiryDate
+		objectMetadata.ExpiryRuleID = metadata.ExpiryRuleID
+		objectMetadata.RestoreRequested = metadata.RestoreRequested
+		objectMetadata.RestoreOngoing = metadata.RestoreOngoing
+		objectMetadata.RestoreExpiryDate = metadata.RestoreExpiryDate
+		objectMetadata.VersionID = metadata.VersionID
 		objectMetadata.Type = os.FileMode(0664)
 		c.mu.Unlock()
 		return objectMetadata, nil
@@ -333,17 +1252,131 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 	return bucketMetadata, nil
 }
 
+// Region - region requests are signed for.
+func (c *s3Client) Region() string {
+	return c.api.Region()
+}
+
+// SignatureVersion - signature version requests are signed with, named as in the config.
+func (c *s3Client) SignatureVersion() string {
+	if c.api.SignatureType() == minio.SignatureV2 {
+		return "S3v2"
+	}
+	return "S3v4"
+}
+
+// NegotiateSignature - signature version, named as in the config, the host of
+// config accepts. Signature version '4' is tried first, version '2' only if
+// the host rejects version '4'. Anonymous requests are not signed at all.
+func NegotiateSignature(config *client.Config) (string, *probe.Error) {
+	if config.AccessKey == "" && config.SecretKey == "" {
+		return "S3v4", nil
+	}
+	var e error
+	for _, signature := range []string{"S3v4", "S3v2"} {
+		conf := *config
+		conf.Signature = signature
+		clnt, err := New(&conf)
+		if err != nil {
+			return "", err.Trace(signature)
+		}
+		if e = clnt.(*s3Client).probeSignature(); !isSignatureRejected(e) {
+			return signature, nil
+		}
+	}
+	return "", probe.NewError(e)
+}
+
+// probeSignature - sends a cheap signed request, the location of the bucket
+// or the list of buckets, and returns the error the server replied with.
+func (c *s3Client) probeSignature() error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket != "" {
+		_, e := c.api.GetBucketLocation(bucket)
+		return e
+	}
+	var e error
+	for bucketStat := range c.api.ListBuckets() {
+		if bucketStat.Err != nil && e == nil {
+			e = bucketStat.Err
+		}
+	}
+	return e
+}
+
+// isSignatureRejected - reports if the server refused the signature version
+// of a request, as opposed to its credentials or the operation.
+func isSignatureRejected(e error) bool {
+	errResponse := minio.ToErrorResponse(e)
+	if errResponse == nil {
+		return false
+	}
+	switch errResponse.Code {
+	case "SignatureVersionNotSupported":
+		return true
+	case "InvalidArgument", "InvalidRequest", "AccessDenied", "NotImplemented", "AuthorizationHeaderMalformed":
+		message := strings.ToLower(errResponse.Message)
+		for _, hint := range []string{"authorization mechanism", "authorization type", "signature version", "aws4-hmac-sha256"} {
+			if strings.Contains(message, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Figure out if the URL is of 'virtual host' style.
-// Currently only supported hosts with virtual style are Amazon S3 and Google Cloud Storage.
+// Currently only supported hosts with virtual style are Amazon S3 and Google Cloud Storage,
+// a host with a port never is.
 func isVirtualHostStyle(hostURL string) bool {
+	if _, _, e := net.SplitHostPort(hostURL); e == nil {
+		return false
+	}
 	matchS3, _ := filepath.Match("*.s3*.amazonaws.com", hostURL)
 	matchGoogle, _ := filepath.Match("*.storage.googleapis.com", hostURL)
 	return matchS3 || matchGoogle
 }
 
+// cleanBasePath - base path with a leading and without a trailing separator, empty for root.
+func cleanBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// urlJoin - joins elems onto base with '/'. Unlike path.Join keys are not
+// cleaned, a listed key is preserved exactly.
+func urlJoin(base string, elems ...string) string {
+	joined := strings.TrimSuffix(base, "/")
+	for _, elem := range elems {
+		if elem != "" {
+			joined += "/" + elem
+		}
+	}
+	if joined == "" {
+		return "/"
+	}
+	return joined
+}
+
+// objectPath - URL path of a listed object, below the base path. Folders
+// are returned without their trailing separator.
+func (c *s3Client) objectPath(bucket, object string) string {
+	object = strings.TrimSuffix(object, "/")
+	if c.virtualStyle {
+		return urlJoin(c.basePath, object)
+	}
+	return urlJoin(c.basePath, bucket, object)
+}
+
 // url2BucketAndObject gives bucketName and objectName from URL path.
 func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 	path := c.hostURL.Path
+	if c.basePath != "" && (path == c.basePath || strings.HasPrefix(path, c.basePath+string(c.hostURL.Separator))) {
+		path = strings.TrimPrefix(path, c.basePath)
+	}
 	// Convert any virtual host styled requests.
 	//
 	// For the time being this check is introduced for S3,
@@ -357,7 +1390,7 @@ func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 		}
 		if hostIndex > 0 {
 			bucket = c.hostURL.Host[:hostIndex-1]
-			path = string(c.hostURL.Separator) + bucket + c.hostURL.Path
+			path = string(c.hostURL.Separator) + bucket + path
 		}
 	}
 	splits := strings.SplitN(path, string(c.hostURL.Separator), 3)
@@ -377,29 +1410,35 @@ func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 
 /// Bucket API operations.
 
-// List - list at delimited path, if not recursive.
-func (c *s3Client) List(recursive, incomplete bool) <-chan *client.Content {
+// List - list at delimited path, if not recursive. Closing doneCh stops listing further pages.
+func (c *s3Client) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *client.Content {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	contentCh := make(chan *client.Content)
+	doneCh, stop := client.ContextDone(c.ctx, doneCh)
+	listCh := make(chan *client.Content)
 	if incomplete {
 		if recursive {
-			go c.listIncompleteRecursiveInRoutine(contentCh)
+			go c.listIncompleteRecursiveInRoutine(listCh, doneCh)
 		} else {
-			go c.listIncompleteInRoutine(contentCh)
+			go c.listIncompleteInRoutine(listCh, doneCh)
 		}
 	} else {
 		if recursive {
-			go c.listRecursiveInRoutine(contentCh)
+			go c.listRecursiveInRoutine(listCh, doneCh)
 		} else {
-			go c.listInRoutine(contentCh)
+			go c.listInRoutine(listCh, doneCh)
 		}
 	}
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer stop()
+		client.ForwardContents(listCh, contentCh, doneCh)
+	}()
 	return contentCh
 }
 
-func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
+func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content, doneCh <-chan struct{}) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -412,7 +1451,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
 				}
 				return
 			}
-			for object := range c.api.ListIncompleteUploads(bucket.Name, o, false) {
+			for object := range c.api.ListIncompleteUploads(bucket.Name, o, false, doneCh) {
 				if object.Err != nil {
 					contentCh <- &client.Content{
 						Err: probe.NewError(object.Err),
@@ -422,10 +1461,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
 				content := new(client.Content)
 				url := *c.hostURL
 				// Join bucket with - incoming object key.
-				url.Path = filepath.Join(string(url.Separator), bucket.Name, object.Key)
-				if c.virtualStyle {
-					url.Path = filepath.Join(string(url.Separator), object.Key)
-				}
+				url.Path = c.objectPath(bucket.Name, object.Key)
 				switch {
 				case strings.HasSuffix(object.Key, string(c.hostURL.Separator)):
 					// We need to keep the trailing Separator, do not use filepath.Join().
@@ -437,12 +1473,13 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
 					content.Size = object.Size
 					content.Time = object.Initiated
 					content.Type = os.ModeTemporary
+					content.UploadID = object.UploadID
 				}
 				contentCh <- content
 			}
 		}
 	default:
-		for object := range c.api.ListIncompleteUploads(b, o, false) {
+		for object := range c.api.ListIncompleteUploads(b, o, false, doneCh) {
 			if object.Err != nil {
 				contentCh <- &client.Content{
 					Err: probe.NewError(object.Err),
@@ -452,10 +1489,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
 			content := new(client.Content)
 			url := *c.hostURL
 			// Join bucket with - incoming object key.
-			url.Path = filepath.Join(string(url.Separator), b, object.Key)
-			if c.virtualStyle {
-				url.Path = filepath.Join(string(url.Separator), object.Key)
-			}
+			url.Path = c.objectPath(b, object.Key)
 			switch {
 			case strings.HasSuffix(object.Key, string(c.hostURL.Separator)):
 				// We need to keep the trailing Separator, do not use filepath.Join().
@@ -467,13 +1501,14 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan *client.Content) {
 				content.Size = object.Size
 				content.Time = object.Initiated
 				content.Type = os.ModeTemporary
+				content.UploadID = object.UploadID
 			}
 			contentCh <- content
 		}
 	}
 }
 
-func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan *client.Content) {
+func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan *client.Content, doneCh <-chan struct{}) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -486,7 +1521,7 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan *client.Conte
 				}
 				return
 			}
-			for object := range c.api.ListIncompleteUploads(bucket.Name, o, true) {
+			for object := range c.api.ListIncompleteUploads(bucket.Name, o, true, doneCh) {
 				if object.Err != nil {
 					contentCh <- &client.Content{
 						Err: probe.NewError(object.Err),
@@ -495,16 +1530,17 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan *client.Conte
 				}
 				content := new(client.Content)
 				url := *c.hostURL
-				url.Path = filepath.Join(url.Path, bucket.Name, object.Key)
+				url.Path = urlJoin(url.Path, bucket.Name, object.Key)
 				content.URL = url
 				content.Size = object.Size
 				content.Time = object.Initiated
 				content.Type = os.ModeTemporary
+				content.UploadID = object.UploadID
 				contentCh <- content
 			}
 		}
 	default:
-		for object := range c.api.ListIncompleteUploads(b, o, true) {
+		for object := range c.api.ListIncompleteUploads(b, o, true, doneCh) {
 			if object.Err != nil {
 				contentCh <- &client.Content{
 					Err: probe.NewError(object.Err),
@@ -513,21 +1549,19 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan *client.Conte
 			}
 			url := *c.hostURL
 			// Join bucket and incoming object key.
-			url.Path = filepath.Join(string(url.Separator), b, object.Key)
-			if c.virtualStyle {
-				url.Path = filepath.Join(string(url.Separator), object.Key)
-			}
+			url.Path = c.objectPath(b, object.Key)
 			content := new(client.Content)
 			content.URL = url
 			content.Size = object.Size
 			content.Time = object.Initiated
 			content.Type = os.ModeTemporary
+			content.UploadID = object.UploadID
 			contentCh <- content
 		}
 	}
 }
 
-func (c *s3Client) listInRoutine(contentCh chan *client.Content) {
+func (c *s3Client) listInRoutine(contentCh chan *client.Content, doneCh <-chan struct{}) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -541,7 +1575,7 @@ func (c *s3Client) listInRoutine(contentCh chan *client.Content) {
 				return
 			}
 			url := *c.hostURL
-			url.Path = filepath.Join(url.Path, bucket.Name)
+			url.Path = urlJoin(url.Path, bucket.Name)
 			content := new(client.Content)
 			content.URL = url
 			content.Size = 0
@@ -549,17 +1583,9 @@ func (c *s3Client) listInRoutine(contentCh chan *client.Content) {
 			content.Type = os.ModeDir
 			contentCh <- content
 		}
-	case b != "" && !strings.HasSuffix(c.hostURL.Path, string(c.hostURL.Separator)) && o == "":
-		e := c.api.BucketExists(b)
-		if e != nil {
-			contentCh <- &client.Content{
-				Err: probe.NewError(e),
-			}
-		}
-		content := new(client.Content)
-		content.URL = *c.hostURL
-		content.Type = os.ModeDir
-		contentCh <- content
+	case o == "":
+		// ‘alias/bucket’ lists the contents of the bucket, as ‘alias/bucket/’ does.
+		c.listDelimited(contentCh, b, "", doneCh)
 	default:
 		metadata, e := c.api.StatObject(b, o)
 		switch e.(type) {
@@ -569,41 +1595,48 @@ func (c *s3Client) listInRoutine(contentCh chan *client.Content) {
 			content.Time = metadata.LastModified
 			content.Size = metadata.Size
 			content.Type = os.FileMode(0664)
+			content.ETag = metadata.ETag
+			content.StorageClass = metadata.StorageClass
 			contentCh <- content
 		default:
-			for object := range c.api.ListObjects(b, o, false) {
-				if object.Err != nil {
-					contentCh <- &client.Content{
-						Err: probe.NewError(object.Err),
-					}
-					return
-				}
-				content := new(client.Content)
-				url := *c.hostURL
-				// Join bucket and incoming object key.
-				url.Path = filepath.Join(string(url.Separator), b, object.Key)
-				if c.virtualStyle {
-					url.Path = filepath.Join(string(url.Separator), object.Key)
-				}
-				switch {
-				case strings.HasSuffix(object.Key, string(c.hostURL.Separator)):
-					// We need to keep the trailing Separator, do not use filepath.Join().
-					content.URL = url
-					content.Time = time.Now()
-					content.Type = os.ModeDir
-				default:
-					content.URL = url
-					content.Size = object.Size
-					content.Time = object.LastModified
-					content.Type = os.FileMode(0664)
-				}
-				contentCh <- content
+			// Keys starting with o, keys below the next separator as common prefixes.
+			c.listDelimited(contentCh, b, o, doneCh)
+		}
+	}
+}
+
+// listDelimited - send the objects of bucket starting with prefix, and the common prefixes
+// up to the next separator after prefix as folders. Stops at the first error.
+func (c *s3Client) listDelimited(contentCh chan *client.Content, bucket, prefix string, doneCh <-chan struct{}) {
+	for object := range c.api.ListObjects(bucket, prefix, false, doneCh) {
+		if object.Err != nil {
+			contentCh <- &client.Content{
+				Err: probe.NewError(object.Err),
 			}
+			return
+		}
+		content := new(client.Content)
+		url := *c.hostURL
+		// Join bucket and incoming object key.
+		url.Path = c.objectPath(bucket, object.Key)
+		switch {
+		case strings.HasSuffix(object.Key, string(c.hostURL.Separator)):
+			// We need to keep the trailing Separator, do not use filepath.Join().
+			content.URL = url
+			content.Time = time.Now()
+			content.Type = os.ModeDir
+		default:
+			content.URL = url
+			content.Size = object.Size
+			content.Time = object.LastModified
+			content.Type = os.FileMode(0664)
+			setListedMetadata(content, object)
 		}
+		contentCh <- content
 	}
 }
 
-func (c *s3Client) listRecursiveInRoutine(contentCh chan *client.Content) {
+func (c *s3Client) listRecursiveInRoutine(contentCh chan *client.Content, doneCh <-chan struct{}) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -617,13 +1650,13 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *client.Content) {
 				return
 			}
 			bucketURL := *c.hostURL
-			bucketURL.Path = filepath.Join(bucketURL.Path, bucket.Name)
+			bucketURL.Path = urlJoin(bucketURL.Path, bucket.Name)
 			contentCh <- &client.Content{
 				URL:  bucketURL,
 				Type: os.ModeDir,
 				Time: bucket.CreationDate,
 			}
-			for object := range c.api.ListObjects(bucket.Name, o, true) {
+			for object := range c.api.ListObjects(bucket.Name, o, true, doneCh) {
 				if object.Err != nil {
 					contentCh <- &client.Content{
 						Err: probe.NewError(object.Err),
@@ -632,16 +1665,17 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *client.Content) {
 				}
 				content := new(client.Content)
 				objectURL := *c.hostURL
-				objectURL.Path = filepath.Join(objectURL.Path, bucket.Name, object.Key)
+				objectURL.Path = urlJoin(objectURL.Path, bucket.Name, object.Key)
 				content.URL = objectURL
 				content.Size = object.Size
 				content.Time = object.LastModified
 				content.Type = os.FileMode(0664)
+				setListedMetadata(content, object)
 				contentCh <- content
 			}
 		}
 	default:
-		for object := range c.api.ListObjects(b, o, true) {
+		for object := range c.api.ListObjects(b, o, true, doneCh) {
 			if object.Err != nil {
 				contentCh <- &client.Content{
 					Err: probe.NewError(object.Err),
@@ -651,16 +1685,144 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *client.Content) {
 			content := new(client.Content)
 			url := *c.hostURL
 			// Join bucket and incoming object key.
-			url.Path = filepath.Join(string(url.Separator), b, object.Key)
-			// If virtualStyle replace the url.Path back.
-			if c.virtualStyle {
-				url.Path = filepath.Join(string(url.Separator), object.Key)
-			}
+			url.Path = c.objectPath(b, object.Key)
 			content.URL = url
 			content.Size = object.Size
 			content.Time = object.LastModified
 			content.Type = os.FileMode(0664)
+			setListedMetadata(content, object)
+			contentCh <- content
+		}
+	}
+}
+
+// setListedMetadata - ETag, storage class and owner of a listed object, listings quote the ETag unlike Stat.
+func setListedMetadata(content *client.Content, object minio.ObjectStat) {
+	content.ETag = strings.Trim(object.ETag, "\"")
+	content.StorageClass = object.StorageClass
+	content.OwnerID = object.Owner.ID
+	content.OwnerDisplayName = object.Owner.DisplayName
+}
+
+// ListVersions - list all versions and delete markers of objects in a bucket.
+func (c *s3Client) ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *client.Content {
+	doneCh, stop := client.ContextDone(c.ctx, doneCh)
+	listCh := make(chan *client.Content)
+	go c.listVersionsInRoutine(recursive, listCh, doneCh)
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer stop()
+		client.ForwardContents(listCh, contentCh, doneCh)
+	}()
+	return contentCh
+}
+
+func (c *s3Client) listVersionsInRoutine(recursive bool, contentCh chan *client.Content, doneCh <-chan struct{}) {
+	defer close(contentCh)
+	// get bucket and object from URL.
+	b, o := c.url2BucketAndObject()
+	if b == "" {
+		contentCh <- &client.Content{
+			Err: probe.NewError(client.BucketNameEmpty{}),
+		}
+		return
+	}
+	for object := range c.api.ListObjectVersions(b, o, recursive, doneCh) {
+		if object.Err != nil {
+			contentCh <- &client.Content{
+				Err: probe.NewError(object.Err),
+			}
+			return
+		}
+		content := new(client.Content)
+		url := *c.hostURL
+		// Join bucket and incoming object key.
+		url.Path = c.objectPath(b, object.Key)
+		content.URL = url
+		if strings.HasSuffix(object.Key, string(c.hostURL.Separator)) {
+			content.Time = time.Now()
+			content.Type = os.ModeDir
 			contentCh <- content
+			continue
+		}
+		content.Size = object.Size
+		content.Time = object.LastModified
+		content.Type = os.FileMode(0664)
+		setListedMetadata(content, object)
+		content.VersionID = object.VersionID
+		content.IsLatest = object.IsLatest
+		content.IsDeleteMarker = object.IsDeleteMarker
+		contentCh <- content
+	}
+}
+
+// ListPage - list one page of objects in a bucket after the key startAfter.
+func (c *s3Client) ListPage(recursive bool, startAfter string, maxKeys int) ([]*client.Content, string, *probe.Error) {
+	b, o := c.url2BucketAndObject()
+	if b == "" {
+		return nil, "", probe.NewError(client.BucketNameEmpty{})
+	}
+	objects, marker, e := c.api.ListObjectsPage(b, o, startAfter, recursive, maxKeys)
+	if e != nil {
+		return nil, "", probe.NewError(e)
+	}
+	contents := make([]*client.Content, 0, len(objects))
+	for _, object := range objects {
+		content := new(client.Content)
+		content.URL = *c.hostURL
+		// Join bucket and incoming object key.
+		content.URL.Path = c.objectPath(b, object.Key)
+		if strings.HasSuffix(object.Key, string(c.hostURL.Separator)) {
+			content.Time = time.Now()
+			content.Type = os.ModeDir
+		} else {
+			content.Size = object.Size
+			content.Time = object.LastModified
+			content.Type = os.FileMode(0664)
+			setListedMetadata(content, object)
+		}
+		contents = append(contents, content)
+	}
+	return contents, marker, nil
+}
+
+// ListParts - list parts uploaded so far to an incomplete upload of this object.
+func (c *s3Client) ListParts(uploadID string, doneCh <-chan struct{}) <-chan *client.Content {
+	doneCh, stop := client.ContextDone(c.ctx, doneCh)
+	listCh := make(chan *client.Content)
+	go c.listPartsInRoutine(uploadID, listCh, doneCh)
+	contentCh := make(chan *client.Content)
+	go func() {
+		defer stop()
+		client.ForwardContents(listCh, contentCh, doneCh)
+	}()
+	return contentCh
+}
+
+func (c *s3Client) listPartsInRoutine(uploadID string, contentCh chan *client.Content, doneCh <-chan struct{}) {
+	defer close(contentCh)
+	// get bucket and object from URL.
+	b, o := c.url2BucketAndObject()
+	if b == "" {
+		contentCh <- &client.Content{
+			Err: probe.NewError(client.BucketNameEmpty{}),
+		}
+		return
+	}
+	for part := range c.api.ListObjectParts(b, o, uploadID, doneCh) {
+		if part.Err != nil {
+			contentCh <- &client.Content{
+				Err: probe.NewError(part.Err),
+			}
+			return
 		}
+		content := new(client.Content)
+		content.URL = *c.hostURL
+		content.Size = part.Size
+		content.Time = part.LastModified
+		content.Type = os.ModeTemporary
+		content.ETag = part.ETag
+		content.UploadID = uploadID
+		contentCh <- content
 	}
 }
@@ -0,0 +1,218 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// slowDownRetries - times a request answered with SlowDown is sent again.
+	slowDownRetries = 4
+	// throttleRampSuccesses - requests to succeed before a lowered limit is raised by one.
+	throttleRampSuccesses = 10
+	// slowDownMaxDelay - longest wait before a request answered with SlowDown is sent again.
+	slowDownMaxDelay = 5 * time.Second
+)
+
+// slowDownBaseDelay - wait before the first retry of a request answered with SlowDown, doubled
+// with every retry. Shortened by tests.
+var slowDownBaseDelay = 100 * time.Millisecond
+
+// hostBudget - requests in flight to a host, at most limit at a time. The limit is halved whenever
+// the host answers SlowDown, and raised by one after every throttleRampSuccesses requests succeeding
+// since, until it is back where it was. This is the adaptive retry recommended by AWS, it keeps
+// many workers from being throttled into failure.
+type hostBudget struct {
+	host      string
+	mutex     *sync.Mutex
+	changed   chan struct{} // closed whenever a waiting request may be let through.
+	inFlight  int
+	max       int // configured limit, zero is unlimited.
+	limit     int // current limit, zero is unlimited.
+	ceiling   int // limit a lowered limit is raised back to.
+	successes int
+}
+
+// newHostBudget - budget of max requests in flight to host, zero is unlimited.
+func newHostBudget(host string, max int) *hostBudget {
+	return &hostBudget{host: host, mutex: &sync.Mutex{}, changed: make(chan struct{}), max: max, limit: max, ceiling: max}
+}
+
+// limitTo - limit an unlimited budget to max requests in flight.
+func (b *hostBudget) limitTo(max int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.max != 0 {
+		return
+	}
+	b.max, b.ceiling = max, max
+	if b.limit == 0 || b.limit > max {
+		b.limit = max
+	}
+}
+
+// acquire - wait until a request may be sent, or ctx is done.
+func (b *hostBudget) acquire(ctx context.Context) error {
+	for {
+		b.mutex.Lock()
+		if b.limit == 0 || b.inFlight < b.limit {
+			b.inFlight++
+			b.mutex.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release - a request is done.
+func (b *hostBudget) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.inFlight--
+	b.notify()
+}
+
+// notify - let waiting requests check the budget again, called with the mutex held.
+func (b *hostBudget) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// throttled - the host answered SlowDown, halve the limit. Returns the new limit.
+func (b *hostBudget) throttled() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.limit == 0 {
+		// Unlimited so far, raised back to as many as were in flight.
+		b.limit, b.ceiling = b.inFlight, b.inFlight
+	}
+	if b.limit /= 2; b.limit < 1 {
+		b.limit = 1
+	}
+	b.successes = 0
+	return b.limit
+}
+
+// succeeded - the host answered without SlowDown, raise a lowered limit once enough did. Returns
+// the new limit and true if it was raised.
+func (b *hostBudget) succeeded() (int, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.limit == b.max {
+		return b.limit, false
+	}
+	if b.successes++; b.successes < throttleRampSuccesses {
+		return b.limit, false
+	}
+	b.successes = 0
+	if b.limit++; b.limit >= b.ceiling {
+		b.limit = b.max
+	}
+	b.notify()
+	return b.limit, true
+}
+
+// isSlowDown - resp is a SlowDown error, its body can still be read after.
+func isSlowDown(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	// Errors of HEAD requests have no body to tell.
+	if resp.Request != nil && resp.Request.Method == "HEAD" || resp.Body == nil || resp.Body == http.NoBody {
+		return true
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return bytes.Contains(data, []byte("<Code>SlowDown</Code>"))
+}
+
+// slowDownDelay - wait before retry attempt of a request answered with SlowDown, exponential
+// with jitter so that throttled workers do not retry all at once.
+func slowDownDelay(attempt int) time.Duration {
+	delay := slowDownBaseDelay << uint(attempt)
+	if delay > slowDownMaxDelay || delay <= 0 {
+		delay = slowDownMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// traceThrottle - show a change of the budget in --debug output.
+func (t budgetTransport) traceThrottle(format string, args ...interface{}) {
+	if t.debug {
+		printTrace(fmt.Sprintf(format, args...) + "\n")
+	}
+}
+
+// retrySlowDown - lower the budget after resp answered req with SlowDown, and, unless attempt was
+// the last or the body of req cannot be sent again, wait and return the request to send again,
+// its budget released. Otherwise nil is returned and resp is left to the caller.
+func (t budgetTransport) retrySlowDown(req *http.Request, resp *http.Response, attempt int, release func()) (*http.Request, error) {
+	limit := t.budget.throttled()
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	if attempt >= slowDownRetries || hasBody && req.GetBody == nil {
+		t.traceThrottle("SlowDown from %s, requests in flight lowered to %d.", t.budget.host, limit)
+		return nil, nil
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	release()
+	delay := slowDownDelay(attempt)
+	t.traceThrottle("SlowDown from %s, requests in flight lowered to %d, retrying in %s.", t.budget.host, limit, delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	retry := req.WithContext(req.Context())
+	if hasBody {
+		body, e := req.GetBody()
+		if e != nil {
+			return nil, e
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// traceRaised - show a raised budget in --debug output.
+func (t budgetTransport) traceRaised(limit int) {
+	if limit == 0 {
+		t.traceThrottle("Requests in flight to %s no longer limited.", t.budget.host)
+		return
+	}
+	t.traceThrottle("Requests in flight to %s raised to %d.", t.budget.host, limit)
+}
@@ -0,0 +1,150 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+)
+
+// Default timeouts used when neither the alias nor the command line sets one.
+const (
+	DefaultConnTimeout = 30 * time.Second
+	DefaultReadTimeout = 2 * time.Minute
+	DefaultIdleTimeout = 5 * time.Minute
+)
+
+// withTimeoutDefaults fills in unset timeouts.
+func withTimeoutDefaults(config *client.Config) (conn, read, idle time.Duration) {
+	conn, read, idle = config.ConnTimeout, config.ReadTimeout, config.IdleTimeout
+	if conn <= 0 {
+		conn = DefaultConnTimeout
+	}
+	if read <= 0 {
+		read = DefaultReadTimeout
+	}
+	if idle <= 0 {
+		idle = DefaultIdleTimeout
+	}
+	return conn, read, idle
+}
+
+// newTransport returns a http transport which bounds connection setup and
+// the wait for response headers. Established connections fail once no data
+// moves in either direction for idleTimeout, slow but alive transfers are
+// never cut short. HTTP/2 is used with hosts offering it over TLS. Endpoint hosts
+// are resolved through endpointDNS unless the DNS cache is disabled. Objects are
+// read as stored, gzip encoded objects are not decompressed on the way.
+func newTransport(settings transportSettings) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   settings.connTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	maxIdleConnsPerHost := DefaultMaxIdleConnsPerHost
+	if settings.maxConnsPerHost > 0 {
+		maxIdleConnsPerHost = settings.maxConnsPerHost
+	}
+	dial := dialer.DialContext
+	if !settings.disableDNSCache {
+		dial = endpointDNS.dialer(dial)
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, e := dial(ctx, network, addr)
+			if e != nil {
+				return nil, e
+			}
+			return newIdleTimeoutConn(conn, settings.idleTimeout), nil
+		},
+		TLSHandshakeTimeout:   settings.connTimeout,
+		ResponseHeaderTimeout: settings.readTimeout,
+		ExpectContinueTimeout: expectContinueTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxConnsPerHost:       settings.maxConnsPerHost,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       settings.keepAliveTimeout,
+		DisableCompression:    true,
+	}
+}
+
+// idleTimeoutConn pushes the deadline of the connection forward on every
+// successful read or write. Progress in one direction also extends the
+// other, a long upload keeps the pending response read alive.
+type idleTimeoutConn struct {
+	net.Conn
+	idleTimeout time.Duration
+}
+
+func newIdleTimeoutConn(conn net.Conn, idleTimeout time.Duration) net.Conn {
+	c := &idleTimeoutConn{Conn: conn, idleTimeout: idleTimeout}
+	c.extend()
+	return c
+}
+
+func (c *idleTimeoutConn) extend() {
+	c.Conn.SetDeadline(time.Now().Add(c.idleTimeout))
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	n, e := c.Conn.Read(p)
+	if n > 0 {
+		c.extend()
+	}
+	return n, e
+}
+
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	c.extend()
+	n, e := c.Conn.Write(p)
+	if n > 0 {
+		c.extend()
+	}
+	return n, e
+}
+
+// isTimeout reports whether the error is a network timeout.
+func isTimeout(e error) bool {
+	if netErr, ok := e.(net.Error); ok {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// timeoutReadSeeker translates network timeouts while reading an object
+// into client.Timeout, and bodies cut short into client.Truncated.
+type timeoutReadSeeker struct {
+	io.ReadSeeker
+	url string
+}
+
+func (r timeoutReadSeeker) Read(p []byte) (int, error) {
+	n, e := r.ReadSeeker.Read(p)
+	if e != nil && isTimeout(e) {
+		return n, client.Timeout{Op: "Get", URL: r.url}
+	}
+	if errResponse := minio.ToErrorResponse(e); errResponse != nil && errResponse.Code == "UnexpectedShortRead" {
+		return n, client.Truncated{URL: r.url, Reason: errResponse.Message}
+	}
+	return n, e
+}
@@ -0,0 +1,172 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// printTrace writes a trace, to stderr unless replaced by tests.
+var printTrace = console.Debug
+
+// Trace - traces requests and responses, along with how each request was signed. Compact
+// traces are a single line per request, verbose traces dump all headers.
+type Trace struct {
+	compact bool
+	// redactAuthorization redacts the credential and signature of the Authorization header.
+	redactAuthorization func(auth string) string
+
+	// Signatures of requests on their way out, until they are traced.
+	mutex      sync.Mutex
+	signatures map[*http.Request]signatureTrace
+}
+
+// signatureTrace - how a request was signed.
+type signatureTrace struct {
+	canonicalRequest string
+	stringToSign     string
+}
+
+// newTrace - initialize Trace structure.
+func newTrace(compact bool, redactAuthorization func(string) string) *Trace {
+	return &Trace{
+		compact:             compact,
+		redactAuthorization: redactAuthorization,
+		signatures:          make(map[*http.Request]signatureTrace),
+	}
+}
+
+// TraceSignature - remember how req was signed until it is traced.
+func (t *Trace) TraceSignature(req *http.Request, canonicalRequest, stringToSign string) {
+	if t.compact {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.signatures[req] = signatureTrace{canonicalRequest, stringToSign}
+}
+
+// takeSignature - how req was signed, forgotten once taken.
+func (t *Trace) takeSignature(req *http.Request) (signatureTrace, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	signature, ok := t.signatures[req]
+	delete(t.signatures, req)
+	return signature, ok
+}
+
+// Request - Trace HTTP Request
+func (t *Trace) Request(req *http.Request) (err error) {
+	signature, signed := t.takeSignature(req)
+	if t.compact {
+		return nil
+	}
+
+	// Set a temporary redacted auth
+	origAuth := req.Header.Get("Authorization")
+	if origAuth != "" {
+		req.Header.Set("Authorization", t.redactAuthorization(origAuth))
+	}
+	restoreKeys := redactCustomerKeys(req.Header)
+	reqTrace, err := httputil.DumpRequestOut(req, false) // Only display header
+	// Undo
+	if origAuth != "" {
+		req.Header.Set("Authorization", origAuth)
+	}
+	restoreKeys()
+	if err != nil {
+		return err
+	}
+
+	trace := redactQuery(string(reqTrace))
+	if signed {
+		if signature.canonicalRequest != "" {
+			trace += "Canonical request:\n" + redactCanonicalKeys(signature.canonicalRequest) + "\n\n"
+		}
+		trace += "String to sign:\n" + redactCanonicalKeys(signature.stringToSign) + "\n\n"
+	}
+	printTrace(trace)
+	return nil
+}
+
+// Response - Trace HTTP Response
+func (t *Trace) Response(res *http.Response, elapsed time.Duration) (err error) {
+	if t.compact {
+		printTrace(fmt.Sprintf("%s %s %s %s\n", res.Request.Method, redactQuery(res.Request.URL.RequestURI()), res.Status, elapsed))
+		return nil
+	}
+	restoreKeys := redactCustomerKeys(res.Header)
+	resTrace, err := httputil.DumpResponse(res, false) // Only display header
+	restoreKeys()
+	if err != nil {
+		return err
+	}
+	printTrace(string(resTrace) + "Response Time: " + elapsed.String() + "\n\n")
+	return nil
+}
+
+// customerKeyHeaders - headers carrying SSE-C keys, never traced.
+var customerKeyHeaders = []string{
+	"X-Amz-Server-Side-Encryption-Customer-Key",
+	"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key",
+}
+
+// redactCustomerKeys temporarily redacts SSE-C keys, call the returned function to undo.
+func redactCustomerKeys(header http.Header) func() {
+	origKeys := make(map[string]string)
+	for _, name := range customerKeyHeaders {
+		if key := header.Get(name); key != "" {
+			origKeys[name] = key
+			header.Set(name, "**REDACTED**")
+		}
+	}
+	return func() {
+		for name, key := range origKeys {
+			header.Set(name, key)
+		}
+	}
+}
+
+// redactCanonicalKeys redacts SSE-C keys among the canonical headers of a canonical request or
+// of a S3v2 string to sign.
+func redactCanonicalKeys(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		for _, name := range customerKeyHeaders {
+			if strings.HasPrefix(line, strings.ToLower(name)+":") {
+				lines[i] = strings.ToLower(name) + ":**REDACTED**"
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// regQueryCredentials matches the credential and signature of presigned URLs, for S3v4 and S3v2.
+var regQueryCredentials = regexp.MustCompile("(X-Amz-Credential|X-Amz-Signature|AWSAccessKeyId|Signature)=[^&\\s]+")
+
+// redactQuery redacts the credential and signature of presigned URLs in s.
+func redactQuery(s string) string {
+	return regQueryCredentials.ReplaceAllString(s, "$1=**REDACTED**")
+}
@@ -16,51 +16,13 @@
 
 package s3
 
-import (
-	"net/http"
-	"net/http/httputil"
-	"strings"
-
-	"github.com/minio/mc/pkg/console"
-	"github.com/minio/mc/pkg/httptracer"
-)
-
-// TraceV2 - tracing structure
-type TraceV2 struct{}
-
-// NewTraceV2 - initialize Trace structure
-func NewTraceV2() httptracer.HTTPTracer {
-	return TraceV2{}
-}
-
-// Request - Trace HTTP Request
-func (t TraceV2) Request(req *http.Request) (err error) {
-	origAuth := req.Header.Get("Authorization")
-
-	if strings.TrimSpace(origAuth) != "" {
-		// Authorization (S3 v2 signature) Format:
-		// Authorization: AWS AKIAJVA5BMMU2RHO6IO1:Y10YHUZ0DTUterAUI6w3XKX7Iqk=
-
-		// Set a temporary redacted auth
-		req.Header.Set("Authorization", "AWS **REDACTED**:**REDACTED**")
-
-		var reqTrace []byte
-		reqTrace, err = httputil.DumpRequestOut(req, false) // Only display header
-		if err == nil {
-			console.Debug(string(reqTrace))
-		}
-
-		// Undo
-		req.Header.Set("Authorization", origAuth)
-	}
-	return err
+// NewTraceV2 - initialize Trace structure for S3v2 signed requests
+func NewTraceV2(compact bool) *Trace {
+	return newTrace(compact, redactAuthorizationV2)
 }
 
-// Response - Trace HTTP Response
-func (t TraceV2) Response(res *http.Response) (err error) {
-	resTrace, err := httputil.DumpResponse(res, false) // Only display header
-	if err == nil {
-		console.Debug(string(resTrace))
-	}
-	return err
+// redactAuthorizationV2 - Authorization (S3 v2 signature) Format:
+// Authorization: AWS AKIAJVA5BMMU2RHO6IO1:Y10YHUZ0DTUterAUI6w3XKX7Iqk=
+func redactAuthorizationV2(auth string) string {
+	return "AWS **REDACTED**:**REDACTED**"
 }
@@ -0,0 +1,249 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// Connection pooling defaults used when neither the alias nor the command line sets one.
+const (
+	// DefaultKeepAliveTimeout is how long an idle connection is kept for reuse.
+	DefaultKeepAliveTimeout = 90 * time.Second
+	// DefaultMaxIdleConnsPerHost bounds idle connections kept per host when
+	// connections per host are not limited.
+	DefaultMaxIdleConnsPerHost = 64
+)
+
+// expectContinueTimeout - how long uploads sent with ‘Expect: 100-continue’ wait for the server
+// to accept their headers, servers not answering at all are sent the body once it passed.
+const expectContinueTimeout = time.Second
+
+// transportSettings - everything a transport is built from, clients with equal
+// settings share one transport.
+type transportSettings struct {
+	connTimeout      time.Duration
+	readTimeout      time.Duration
+	idleTimeout      time.Duration
+	keepAliveTimeout time.Duration
+	maxConnsPerHost  int // zero is unlimited.
+	disableDNSCache  bool
+}
+
+// newTransportSettings - transport settings of config, unset values are defaulted.
+func newTransportSettings(config *client.Config) transportSettings {
+	conn, read, idle := withTimeoutDefaults(config)
+	settings := transportSettings{
+		connTimeout:      conn,
+		readTimeout:      read,
+		idleTimeout:      idle,
+		keepAliveTimeout: config.KeepAliveTimeout,
+		maxConnsPerHost:  config.MaxConnsPerHost,
+		disableDNSCache:  config.DisableDNSCache,
+	}
+	if settings.keepAliveTimeout <= 0 {
+		settings.keepAliveTimeout = DefaultKeepAliveTimeout
+	}
+	if settings.maxConnsPerHost < 0 {
+		settings.maxConnsPerHost = 0
+	}
+	return settings
+}
+
+// transportPool - transports created so far, one per distinct settings.
+type transportPool struct {
+	mutex      *sync.Mutex
+	transports map[transportSettings]*http.Transport
+}
+
+// transports is shared by all clients of one invocation, so that parallel
+// transfers reuse pooled connections instead of dialing anew.
+var transports = transportPool{
+	mutex:      &sync.Mutex{},
+	transports: make(map[transportSettings]*http.Transport),
+}
+
+// get - transport for settings, created on first use.
+func (p transportPool) get(settings transportSettings) *http.Transport {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	transport, ok := p.transports[settings]
+	if !ok {
+		transport = newTransport(settings)
+		p.transports[settings] = transport
+	}
+	return transport
+}
+
+// hostBudgetPool - budgets of requests in flight, one per endpoint host.
+type hostBudgetPool struct {
+	mutex   *sync.Mutex
+	budgets map[string]*hostBudget
+}
+
+// hostBudgets is shared by all clients of one invocation, whatever their alias or
+// settings, so that a host is never sent more requests at a time than its budget.
+var hostBudgets = hostBudgetPool{
+	mutex:   &sync.Mutex{},
+	budgets: make(map[string]*hostBudget),
+}
+
+// get - budget of limit requests in flight to host, zero is unlimited, created on
+// first use. The host keeps the limit it was first given, unless that was unlimited.
+func (p hostBudgetPool) get(host string, limit int) *hostBudget {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	host = strings.ToLower(host)
+	budget, ok := p.budgets[host]
+	if !ok {
+		budget = newHostBudget(host, limit)
+		p.budgets[host] = budget
+	} else if limit > 0 {
+		budget.limitTo(limit)
+	}
+	return budget
+}
+
+// budgetTransport - every request takes from the budget of its host, from before it
+// is sent until its response body is read to the end or closed. Requests answered
+// with SlowDown lower the budget, and are sent again if they can be.
+type budgetTransport struct {
+	http.RoundTripper
+	budget *hostBudget
+	debug  bool
+}
+
+func (t budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if e := t.budget.acquire(req.Context()); e != nil {
+			return nil, e
+		}
+		once := new(sync.Once)
+		release := func() { once.Do(t.budget.release) }
+		resp, e := t.RoundTripper.RoundTrip(req)
+		if e != nil {
+			release()
+			return resp, e
+		}
+		if !isSlowDown(resp) {
+			if resp.StatusCode < http.StatusInternalServerError {
+				if limit, raised := t.budget.succeeded(); raised {
+					t.traceRaised(limit)
+				}
+			}
+		} else if retry, e := t.retrySlowDown(req, resp, attempt, release); e != nil {
+			return nil, e
+		} else if retry != nil {
+			req = retry
+			continue
+		}
+		if resp.Body == nil || resp.Body == http.NoBody {
+			release()
+			return resp, nil
+		}
+		resp.Body = budgetBody{ReadCloser: resp.Body, release: release}
+		return resp, nil
+	}
+}
+
+// budgetBody - response body giving back the budget taken by its request once done.
+type budgetBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b budgetBody) Read(p []byte) (int, error) {
+	n, e := b.ReadCloser.Read(p)
+	if e != nil {
+		b.release()
+	}
+	return n, e
+}
+
+func (b budgetBody) Close() error {
+	b.release()
+	return b.ReadCloser.Close()
+}
+
+// expectContinueTransport - a request with ‘Expect: 100-continue’ answered with 417 Expectation
+// Failed, by a server or proxy not supporting it, is sent again without as long as nothing of its
+// body was sent.
+type expectContinueTransport struct {
+	http.RoundTripper
+}
+
+func (t expectContinueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Expect") == "" || req.Body == nil || req.Body == http.NoBody {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	body := &expectContinueBody{ReadCloser: req.Body}
+	expecting := req.WithContext(req.Context())
+	expecting.Body = body
+	resp, e := t.RoundTripper.RoundTrip(expecting)
+	if e != nil || resp.StatusCode != http.StatusExpectationFailed || body.isRead() {
+		body.closeUnread()
+		return resp, e
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	retry := req.WithContext(req.Context())
+	retry.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		retry.Header[k] = v
+	}
+	retry.Header.Del("Expect")
+	return t.RoundTripper.RoundTrip(retry)
+}
+
+// expectContinueBody - request body kept open until it is known whether it is sent again, its
+// request may be answered before any of it was sent.
+type expectContinueBody struct {
+	io.ReadCloser
+	read int32 // set once read from.
+}
+
+func (b *expectContinueBody) Read(p []byte) (int, error) {
+	atomic.StoreInt32(&b.read, 1)
+	return b.ReadCloser.Read(p)
+}
+
+// Close - close the body once read from, an unread body may be sent again.
+func (b *expectContinueBody) Close() error {
+	if b.isRead() {
+		return b.ReadCloser.Close()
+	}
+	return nil
+}
+
+func (b *expectContinueBody) isRead() bool {
+	return atomic.LoadInt32(&b.read) == 1
+}
+
+// closeUnread - close the body not sent again, which was never read from.
+func (b *expectContinueBody) closeUnread() {
+	if !b.isRead() {
+		b.ReadCloser.Close()
+	}
+}
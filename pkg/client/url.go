@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 )
 
@@ -117,31 +116,29 @@ func NewURL(urlStr string) *URL {
 	}
 }
 
-// JoinURLs join two input urls and returns a url
+// slashPath - path of u with '/' separators. Filesystem paths are translated
+// from the native separator, object keys are returned as is since a '\\' is a
+// valid character in a key.
+func (u URL) slashPath() string {
+	if u.Type == Filesystem && u.Separator != '/' {
+		return strings.Replace(u.Path, string(u.Separator), "/", -1)
+	}
+	return u.Path
+}
+
+// JoinURLs join two input urls and returns a url. Separators of url2 are
+// translated to those of url1, keys joined onto an object url never contain
+// a filesystem separator.
 func JoinURLs(url1, url2 *URL) *URL {
-	var url1Path, url2Path string
-	url1Path = url1.Path
-	url2Path = url2.Path
-	if runtime.GOOS == "windows" {
-		url1Path = strings.Replace(url1.Path, "\\", "/", -1)
-		url2Path = strings.Replace(url2.Path, "\\", "/", -1)
+	url1Path := url1.slashPath()
+	url2Path := url2.slashPath()
+	if strings.HasSuffix(url1Path, "/") {
+		url1.Path = url1Path + strings.TrimPrefix(url2Path, "/")
+	} else {
+		url1.Path = url1Path + "/" + strings.TrimPrefix(url2Path, "/")
 	}
-	if url1.Type == Object {
-		if strings.HasSuffix(url1Path, "/") {
-			url1.Path = url1Path + strings.TrimPrefix(url2Path, "/")
-		} else {
-			url1.Path = url1Path + "/" + strings.TrimPrefix(url2Path, "/")
-		}
-	}
-	if url1.Type == Filesystem {
-		if strings.HasSuffix(url1Path, "/") {
-			url1.Path = url1Path + strings.TrimPrefix(url2Path, "/")
-		} else {
-			url1.Path = url1Path + "/" + strings.TrimPrefix(url2Path, "/")
-		}
-		if runtime.GOOS == "windows" {
-			url1.Path = strings.Replace(url1.Path, "/", "\\", -1)
-		}
+	if url1.Type == Filesystem && url1.Separator != '/' {
+		url1.Path = strings.Replace(url1.Path, "/", string(url1.Separator), -1)
 	}
 	return url1
 }
@@ -161,18 +158,10 @@ func (u URL) String() string {
 		if h := u.Host; h != "" {
 			buf.WriteString(h)
 		}
-		switch runtime.GOOS {
-		case "windows":
-			if u.Path != "" && u.Path[0] != '\\' && u.Host != "" && u.Path[0] != '/' {
-				buf.WriteByte('/')
-			}
-			buf.WriteString(strings.Replace(u.Path, "\\", "/", -1))
-		default:
-			if u.Path != "" && u.Path[0] != '/' && u.Host != "" {
-				buf.WriteByte('/')
-			}
-			buf.WriteString(u.Path)
+		if u.Path != "" && u.Path[0] != '/' && u.Host != "" {
+			buf.WriteByte('/')
 		}
+		buf.WriteString(u.Path)
 	}
 	return buf.String()
 }
@@ -0,0 +1,485 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package web implements a read only client.Client of resources served over plain HTTP(S),
+// such as downloads from a web server, to copy them without a local file in between.
+package web
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// httpClient follows redirects, up to ten of them.
+var httpClient = &http.Client{}
+
+// webClient - client of the resource at urlStr.
+type webClient struct {
+	urlStr  string
+	hostURL *client.URL
+	// ctx cancels requests once done, nil if never.
+	ctx context.Context
+}
+
+// IsWebURL - reports if urlStr is a plain http:// or https:// URL.
+func IsWebURL(urlStr string) bool {
+	return strings.HasPrefix(urlStr, "http://") || strings.HasPrefix(urlStr, "https://")
+}
+
+// New - client of the resource at urlStr, an http:// or https:// URL.
+func New(urlStr string) (client.Client, *probe.Error) {
+	if !IsWebURL(urlStr) {
+		return nil, probe.NewError(client.InvalidObjectName{Object: urlStr})
+	}
+	return &webClient{urlStr: urlStr, hostURL: client.NewURL(urlStr)}, nil
+}
+
+// GetURL - get url.
+func (c *webClient) GetURL() client.URL {
+	return *c.hostURL
+}
+
+// WithContext - client whose requests are cancelled once ctx is done.
+func (c *webClient) WithContext(ctx context.Context) client.Client {
+	return &webClient{urlStr: c.urlStr, hostURL: c.hostURL, ctx: ctx}
+}
+
+// do - send a request of method with header, error statuses other than 304 Not Modified and
+// 416 Range Not Satisfiable fail with client.HTTPStatus.
+func (c *webClient) do(method string, header http.Header) (*http.Response, *probe.Error) {
+	req, e := http.NewRequest(method, c.urlStr, nil)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if c.ctx != nil {
+		req = req.WithContext(c.ctx)
+	}
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	resp, e := httpClient.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if resp.StatusCode < 400 || resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return resp, nil
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return nil, probe.NewError(client.PathNotFound{Path: c.urlStr})
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, probe.NewError(client.PathInsufficientPermission{Path: c.urlStr})
+	}
+	return nil, probe.NewError(client.HTTPStatus{URL: c.urlStr, Status: resp.Status, StatusCode: resp.StatusCode})
+}
+
+// Stat - size, modification time, ETag and content type of the resource, looked up with HEAD.
+// Servers refusing HEAD are asked with GET, of which only the headers are read. Size is -1
+// if the server sends no Content-Length.
+func (c *webClient) Stat() (*client.Content, *probe.Error) {
+	resp, err := c.do("HEAD", nil)
+	if err != nil {
+		status, ok := err.ToGoError().(client.HTTPStatus)
+		if !ok || (status.StatusCode != http.StatusMethodNotAllowed && status.StatusCode != http.StatusNotImplemented) {
+			return nil, err.Trace(c.urlStr)
+		}
+		if resp, err = c.do("GET", nil); err != nil {
+			return nil, err.Trace(c.urlStr)
+		}
+	}
+	resp.Body.Close()
+	content := &client.Content{
+		URL:         *c.hostURL,
+		Size:        resp.ContentLength,
+		Type:        os.FileMode(0664),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), "\""),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if modTime, e := http.ParseTime(resp.Header.Get("Last-Modified")); e == nil {
+		content.Time = modTime
+	}
+	return content, nil
+}
+
+// Get - reader of length bytes of the resource from offset, up to its end if length is zero.
+// The first request is sent right away, so that missing resources fail here.
+func (c *webClient) Get(offset, length int64, versionID string) (io.ReadSeeker, *probe.Error) {
+	if versionID != "" {
+		return nil, probe.NewError(client.APINotImplemented{API: "Get version", APIType: "http"})
+	}
+	if offset < 0 || length < 0 {
+		return nil, probe.NewError(client.InvalidRange{Offset: offset})
+	}
+	o := &object{clnt: c, offset: offset, end: -1}
+	if length > 0 {
+		o.end = offset + length
+	}
+	if err := o.open(); err != nil {
+		return nil, err.Trace(c.urlStr)
+	}
+	return o, nil
+}
+
+// GetIfChanged - get the resource only if it changed from a copy with etag, or without an etag
+// if it was modified since modTime.
+func (c *webClient) GetIfChanged(etag string, modTime time.Time) (io.ReadSeeker, *probe.Error) {
+	header := make(http.Header)
+	if etag != "" {
+		header.Set("If-None-Match", "\""+strings.Trim(etag, "\"")+"\"")
+	} else if !modTime.IsZero() {
+		header.Set("If-Modified-Since", modTime.UTC().Format(http.TimeFormat))
+	}
+	resp, err := c.do("GET", header)
+	if err != nil {
+		return nil, err.Trace(c.urlStr)
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, probe.NewError(client.NotModified{URL: c.urlStr})
+	}
+	return &object{clnt: c, end: -1, body: resp.Body}, nil
+}
+
+// object - reader of a resource, seeking sends a new request for the rest with a Range header.
+type object struct {
+	clnt   *webClient
+	offset int64
+	end    int64 // offset reading stops at, -1 reads to the end.
+	size   int64 // total size reported by the server, -1 if unknown.
+	body   io.ReadCloser
+}
+
+// open - request the resource from offset, skipping to it if the server ignores the range.
+func (o *object) open() *probe.Error {
+	header := make(http.Header)
+	if o.offset > 0 || o.end >= 0 {
+		rangeHeader := "bytes=" + strconv.FormatInt(o.offset, 10) + "-"
+		if o.end >= 0 {
+			rangeHeader += strconv.FormatInt(o.end-1, 10)
+		}
+		header.Set("Range", rangeHeader)
+	}
+	resp, err := o.clnt.do("GET", header)
+	if err != nil {
+		return err.Trace()
+	}
+	o.size = -1
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing is left from offset on.
+		resp.Body.Close()
+		o.body = ioutil.NopCloser(strings.NewReader(""))
+		return nil
+	case http.StatusPartialContent:
+		if i := strings.LastIndex(resp.Header.Get("Content-Range"), "/"); i >= 0 {
+			if size, e := strconv.ParseInt(resp.Header.Get("Content-Range")[i+1:], 10, 64); e == nil {
+				o.size = size
+			}
+		}
+	default:
+		o.size = resp.ContentLength
+		if _, e := io.CopyN(ioutil.Discard, resp.Body, o.offset); e != nil {
+			resp.Body.Close()
+			return probe.NewError(client.InvalidRange{Offset: o.offset})
+		}
+	}
+	o.body = resp.Body
+	return nil
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.end >= 0 {
+		if o.offset >= o.end {
+			return 0, io.EOF
+		}
+		if int64(len(p)) > o.end-o.offset {
+			p = p[:o.end-o.offset]
+		}
+	}
+	if o.body == nil {
+		if err := o.open(); err != nil {
+			return 0, err.ToGoError()
+		}
+	}
+	n, e := o.body.Read(p)
+	o.offset += int64(n)
+	return n, e
+}
+
+// Seek - move to offset, the following read requests the resource from there. Seeking from the
+// end needs the size of the resource.
+func (o *object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		if o.size < 0 {
+			return o.offset, client.InvalidRange{Offset: offset}
+		}
+		offset += o.size
+	}
+	if offset < 0 {
+		return o.offset, client.InvalidRange{Offset: offset}
+	}
+	if offset != o.offset {
+		o.Close()
+		o.offset = offset
+	}
+	return o.offset, nil
+}
+
+// Close - close the pending response, if any.
+func (o *object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	e := o.body.Close()
+	o.body = nil
+	return e
+}
+
+// List - the resource itself, web servers offer no listing.
+func (c *webClient) List(recursive, incomplete bool, doneCh <-chan struct{}) <-chan *client.Content {
+	contentCh := make(chan *client.Content, 1)
+	defer close(contentCh)
+	if incomplete {
+		contentCh <- &client.Content{Err: probe.NewError(client.APINotImplemented{API: "List incomplete", APIType: "http"})}
+		return contentCh
+	}
+	content, err := c.Stat()
+	if err != nil {
+		content = &client.Content{Err: err.Trace(c.urlStr)}
+	}
+	contentCh <- content
+	return contentCh
+}
+
+// notImplemented - error of api, which a web server does not offer.
+func notImplemented(api string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: api, APIType: "http"})
+}
+
+// notImplementedList - listing of a single error, for listings a web server does not offer.
+func notImplementedList(api string) <-chan *client.Content {
+	contentCh := make(chan *client.Content, 1)
+	contentCh <- &client.Content{Err: notImplemented(api)}
+	close(contentCh)
+	return contentCh
+}
+
+// ListVersions - not offered by web servers.
+func (c *webClient) ListVersions(recursive bool, doneCh <-chan struct{}) <-chan *client.Content {
+	return notImplementedList("ListVersions")
+}
+
+// ListParts - not offered by web servers.
+func (c *webClient) ListParts(uploadID string, doneCh <-chan struct{}) <-chan *client.Content {
+	return notImplementedList("ListParts")
+}
+
+// ListPage - not offered by web servers.
+func (c *webClient) ListPage(recursive bool, startAfter string, maxKeys int) ([]*client.Content, string, *probe.Error) {
+	return nil, "", notImplemented("ListPage")
+}
+
+// RemoveBatch - resources are read only, each is sent back failed.
+func (c *webClient) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	return failAll(contentCh, "RemoveBatch")
+}
+
+// RemoveUploads - resources are read only, each is sent back failed.
+func (c *webClient) RemoveUploads(contentCh <-chan *client.Content) <-chan *client.Content {
+	return failAll(contentCh, "RemoveUploads")
+}
+
+// failAll - send every content of contentCh back with the error of api.
+func failAll(contentCh <-chan *client.Content, api string) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			content.Err = notImplemented(api)
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+// Put - resources are read only.
+func (c *webClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	return notImplemented("Put")
+}
+
+// PutStream - resources are read only.
+func (c *webClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	return notImplemented("PutStream")
+}
+
+// Remove - resources are read only.
+func (c *webClient) Remove(incomplete bool, versionID string) *probe.Error {
+	return notImplemented("Remove")
+}
+
+// Copy - resources are read only.
+func (c *webClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	return notImplemented("Copy")
+}
+
+// SetMetadata - resources are read only.
+func (c *webClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
+	return notImplemented("SetMetadata")
+}
+
+// RestoreVersion - not offered by web servers.
+func (c *webClient) RestoreVersion(versionID string) *probe.Error {
+	return notImplemented("RestoreVersion")
+}
+
+// RestoreArchived - not offered by web servers.
+func (c *webClient) RestoreArchived(days int, tier string) *probe.Error {
+	return notImplemented("RestoreArchived")
+}
+
+// MakeBucket - not offered by web servers.
+func (c *webClient) MakeBucket() *probe.Error {
+	return notImplemented("MakeBucket")
+}
+
+// RemoveBucket - not offered by web servers.
+func (c *webClient) RemoveBucket() *probe.Error {
+	return notImplemented("RemoveBucket")
+}
+
+// GetBucketAccess - not offered by web servers.
+func (c *webClient) GetBucketAccess() (string, *probe.Error) {
+	return "", notImplemented("GetBucketAccess")
+}
+
+// SetBucketAccess - not offered by web servers.
+func (c *webClient) SetBucketAccess(access string) *probe.Error {
+	return notImplemented("SetBucketAccess")
+}
+
+// GetBucketVersioning - not offered by web servers.
+func (c *webClient) GetBucketVersioning() (string, *probe.Error) {
+	return "", notImplemented("GetBucketVersioning")
+}
+
+// SetBucketVersioning - not offered by web servers.
+func (c *webClient) SetBucketVersioning(enable bool) *probe.Error {
+	return notImplemented("SetBucketVersioning")
+}
+
+// GetBucketAccelerate - not offered by web servers.
+func (c *webClient) GetBucketAccelerate() (string, *probe.Error) {
+	return "", notImplemented("GetBucketAccelerate")
+}
+
+// SetBucketAccelerate - not offered by web servers.
+func (c *webClient) SetBucketAccelerate(enable bool) *probe.Error {
+	return notImplemented("SetBucketAccelerate")
+}
+
+// GetBucketReplication - not offered by web servers.
+func (c *webClient) GetBucketReplication() (client.ReplicationConfig, *probe.Error) {
+	return client.ReplicationConfig{}, notImplemented("GetBucketReplication")
+}
+
+// SetBucketReplication - not offered by web servers.
+func (c *webClient) SetBucketReplication(config client.ReplicationConfig) *probe.Error {
+	return notImplemented("SetBucketReplication")
+}
+
+// GetBucketPolicy - not offered by web servers.
+func (c *webClient) GetBucketPolicy() (string, *probe.Error) {
+	return "", notImplemented("GetBucketPolicy")
+}
+
+// SetBucketPolicy - not offered by web servers.
+func (c *webClient) SetBucketPolicy(policy string) *probe.Error {
+	return notImplemented("SetBucketPolicy")
+}
+
+// GetAnonymousAccess - not offered by web servers.
+func (c *webClient) GetAnonymousAccess() (string, *probe.Error) {
+	return "", notImplemented("GetAnonymousAccess")
+}
+
+// GetBucketRegion - not offered by web servers.
+func (c *webClient) GetBucketRegion() (string, *probe.Error) {
+	return "", notImplemented("GetBucketRegion")
+}
+
+// ShareDownload - the URL itself, it needs no signature and never expires.
+func (c *webClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
+	return c.urlStr, nil
+}
+
+// ShareUpload - resources are read only.
+func (c *webClient) ShareUpload(options client.ShareUploadOptions) (map[string]string, *probe.Error) {
+	return nil, notImplemented("ShareUpload")
+}
+
+// GetTags - not offered by web servers.
+func (c *webClient) GetTags() (map[string]string, *probe.Error) {
+	return nil, notImplemented("GetTags")
+}
+
+// SetTags - not offered by web servers.
+func (c *webClient) SetTags(tags map[string]string) *probe.Error {
+	return notImplemented("SetTags")
+}
+
+// GetObjectACL - not offered by web servers.
+func (c *webClient) GetObjectACL() (client.ACL, *probe.Error) {
+	return client.ACL{}, notImplemented("GetObjectACL")
+}
+
+// SetObjectACL - not offered by web servers.
+func (c *webClient) SetObjectACL(acl client.ACL) *probe.Error {
+	return notImplemented("SetObjectACL")
+}
+
+// GetRetention - not offered by web servers.
+func (c *webClient) GetRetention() (string, time.Time, *probe.Error) {
+	return "", time.Time{}, notImplemented("GetRetention")
+}
+
+// SetRetention - not offered by web servers.
+func (c *webClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	return notImplemented("SetRetention")
+}
+
+// GetLegalHold - not offered by web servers.
+func (c *webClient) GetLegalHold() (bool, *probe.Error) {
+	return false, notImplemented("GetLegalHold")
+}
+
+// SetLegalHold - not offered by web servers.
+func (c *webClient) SetLegalHold(enabled bool) *probe.Error {
+	return notImplemented("SetLegalHold")
+}
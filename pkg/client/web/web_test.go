@@ -0,0 +1,187 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+var (
+	webData    = bytes.Repeat([]byte("0123456789"), 10000)
+	webModTime = time.Date(2015, 10, 14, 9, 30, 0, 0, time.UTC)
+)
+
+// newWebServer - server of webData at /file, with ranges, and of the same data at /stream
+// without a Content-Length. /old redirects to /file, /nohead refuses HEAD requests.
+func newWebServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-iso9660-image")
+		w.Header().Set("ETag", "\"v1\"")
+		http.ServeContent(w, r, "", webModTime, bytes.NewReader(webData))
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		for i := 0; i < len(webData); i += 4096 {
+			end := i + 4096
+			if end > len(webData) {
+				end = len(webData)
+			}
+			w.Write(webData[i:end])
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.Handle("/old", http.RedirectHandler("/file", http.StatusMovedPermanently))
+	mux.HandleFunc("/nohead", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(webData)))
+		w.Write(webData)
+	})
+	mux.HandleFunc("/secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	return httptest.NewServer(mux)
+}
+
+func (s *MySuite) TestStat(c *C) {
+	server := newWebServer()
+	defer server.Close()
+
+	clnt, err := New(server.URL + "/file")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.GetURL().Type == client.Object, Equals, true)
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(webData)))
+	c.Assert(content.ContentType, Equals, "application/x-iso9660-image")
+	c.Assert(content.ETag, Equals, "v1")
+	c.Assert(content.Time.Equal(webModTime), Equals, true)
+
+	// Redirects are followed.
+	clnt, err = New(server.URL + "/old")
+	c.Assert(err, IsNil)
+	content, err = clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(webData)))
+
+	// Without a Content-Length the size is unknown.
+	clnt, err = New(server.URL + "/stream")
+	c.Assert(err, IsNil)
+	content, err = clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(-1))
+
+	// Servers refusing HEAD are asked with GET.
+	clnt, err = New(server.URL + "/nohead")
+	c.Assert(err, IsNil)
+	content, err = clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(webData)))
+
+	_, err = New("/tmp/file")
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestStatErrors(c *C) {
+	server := newWebServer()
+	defer server.Close()
+
+	for path, expected := range map[string]interface{}{
+		"/missing": client.PathNotFound{},
+		"/secret":  client.PathInsufficientPermission{},
+		"/broken":  client.HTTPStatus{},
+	} {
+		clnt, err := New(server.URL + path)
+		c.Assert(err, IsNil)
+		_, err = clnt.Stat()
+		c.Assert(err, NotNil)
+		c.Assert(err.ToGoError(), FitsTypeOf, expected)
+		_, err = clnt.Get(0, 0, "")
+		c.Assert(err, NotNil)
+		c.Assert(err.ToGoError(), FitsTypeOf, expected)
+	}
+	clnt, err := New(server.URL + "/broken")
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	status := err.ToGoError().(client.HTTPStatus)
+	c.Assert(status.StatusCode, Equals, http.StatusServiceUnavailable)
+	c.Assert(status.Retryable(), Equals, true)
+}
+
+func (s *MySuite) TestGet(c *C) {
+	server := newWebServer()
+	defer server.Close()
+
+	for _, path := range []string{"/file", "/old", "/stream", "/nohead"} {
+		clnt, err := New(server.URL + path)
+		c.Assert(err, IsNil)
+		reader, err := clnt.Get(0, 0, "")
+		c.Assert(err, IsNil)
+		data, e := ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		c.Assert(bytes.Equal(data, webData), Equals, true, Commentf("%s", path))
+
+		// Seeking requests the rest again, servers ignoring the range are skipped ahead.
+		_, e = reader.Seek(1000, io.SeekStart)
+		c.Assert(e, IsNil)
+		data, e = ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		c.Assert(bytes.Equal(data, webData[1000:]), Equals, true, Commentf("%s", path))
+
+		reader, err = clnt.Get(5, 20, "")
+		c.Assert(err, IsNil)
+		data, e = ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, string(webData[5:25]), Commentf("%s", path))
+	}
+
+	clnt, err := New(server.URL + "/file")
+	c.Assert(err, IsNil)
+	_, err = clnt.GetIfChanged("v1", time.Time{})
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError(), FitsTypeOf, client.NotModified{})
+	reader, err := clnt.GetIfChanged("v0", time.Time{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(len(data), Equals, len(webData))
+
+	err = clnt.Put(bytes.NewReader(nil), 0, "", nil)
+	c.Assert(err.ToGoError(), FitsTypeOf, client.APINotImplemented{})
+}
@@ -84,6 +84,13 @@ var (
 		return
 	}
 
+	// FatalCodeln print a error message with a new line and exit with code.
+	FatalCodeln = func(code int, data ...interface{}) {
+		consolePrintln("Fatal", Theme["Fatal"], data...)
+		os.Exit(code)
+		return
+	}
+
 	// Error prints a error message.
 	Error = func(data ...interface{}) {
 		consolePrint("Error", Theme["Error"], data...)
@@ -20,14 +20,12 @@ import (
 	"errors"
 	"net/http"
 	"time"
-
-	"github.com/minio/mc/pkg/console"
 )
 
 // HTTPTracer provides callback hook mechanism for HTTP transport.
 type HTTPTracer interface {
 	Request(req *http.Request) error
-	Response(res *http.Response) error
+	Response(res *http.Response, elapsed time.Duration) error
 }
 
 // RoundTripTrace interposes HTTP transport requests and respsonses using HTTPTracer hooks
@@ -46,6 +44,10 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 
 	res, err = t.Transport.RoundTrip(req)
 	if err != nil {
+		// Requests which failed are traced as well, there is no response.
+		if t.Trace != nil {
+			t.Trace.Request(req)
+		}
 		return res, err
 	}
 
@@ -55,11 +57,10 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 			return nil, err
 		}
 
-		err = t.Trace.Response(res)
+		err = t.Trace.Response(res, time.Since(timeStamp))
 		if err != nil {
 			return nil, err
 		}
-		console.Debugln("Response Time: ", time.Since(timeStamp).String()+"\n")
 	}
 	return res, err
 }
@@ -17,7 +17,16 @@
 package httptracer
 
 import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
@@ -31,3 +40,58 @@ var _ = Suite(&MySuite{})
 func (s *MySuite) TestHTTPTracer(c *C) {
 	//
 }
+
+// phaseLog - PhaseRecorder keeping all phases recorded.
+type phaseLog struct {
+	mutex  sync.Mutex
+	phases []Phases
+}
+
+func (l *phaseLog) RecordPhases(p Phases) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.phases = append(l.phases, p)
+}
+
+func (s *MySuite) TestPhaseTransport(c *C) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		time.Sleep(20 * time.Millisecond)
+		w.Write(bytes.Repeat([]byte("x"), 1<<20))
+	}))
+	defer server.Close()
+
+	log := new(phaseLog)
+	transport := PhaseTransport{
+		Recorder:  log,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	// Looked up by name, the host is resolved.
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	req, e := http.NewRequest("PUT", url, bytes.NewReader(bytes.Repeat([]byte("y"), 1<<20)))
+	c.Assert(e, IsNil)
+	res, e := transport.RoundTrip(req)
+	c.Assert(e, IsNil)
+	// Nothing is recorded until the response is read.
+	c.Assert(log.phases, HasLen, 0)
+	n, e := io.Copy(ioutil.Discard, res.Body)
+	c.Assert(e, IsNil)
+	c.Assert(n, Equals, int64(1<<20))
+	res.Body.Close()
+
+	c.Assert(log.phases, HasLen, 1)
+	phases := log.phases[0]
+	c.Assert(phases.DNS > 0, Equals, true)
+	c.Assert(phases.Connect > 0, Equals, true)
+	c.Assert(phases.TLS > 0, Equals, true)
+	c.Assert(phases.FirstByte >= 20*time.Millisecond, Equals, true)
+	c.Assert(phases.Transfer > 0, Equals, true)
+
+	// A failed request is recorded right away.
+	server.Close()
+	req, e = http.NewRequest("GET", url, nil)
+	c.Assert(e, IsNil)
+	_, e = transport.RoundTrip(req)
+	c.Assert(e, NotNil)
+	c.Assert(log.phases, HasLen, 2)
+}
@@ -0,0 +1,149 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Phases - time a request spent resolving the host, connecting, in the TLS handshake, waiting
+// for the first byte of the response after the request was written, and transferring data.
+// Transfer counts writing the request body and reading the response body.
+type Phases struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Transfer  time.Duration
+}
+
+// PhaseRecorder is handed the phases of every request once its response body is read or
+// closed, or once it failed. Requests are recorded concurrently.
+type PhaseRecorder interface {
+	RecordPhases(p Phases)
+}
+
+// PhaseTransport times the phases of requests sent through Transport with httptrace.
+type PhaseTransport struct {
+	Recorder  PhaseRecorder
+	Transport http.RoundTripper
+}
+
+// RoundTrip sends req, recording its phases with Recorder.
+func (t PhaseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timer := new(phaseTimer)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timer.clientTrace()))
+	res, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		t.Recorder.RecordPhases(timer.phases(time.Time{}))
+		return res, err
+	}
+	res.Body = &phaseBody{ReadCloser: res.Body, timer: timer, recorder: t.Recorder}
+	return res, nil
+}
+
+// phaseTimer - moments of a single request, set from httptrace hooks. Dials may run in
+// parallel to the request, or be abandoned for another connection, hence the mutex.
+type phaseTimer struct {
+	mutex        sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+// set - sets the moment at p to now, the first moment for a start and the last for a done.
+func (t *phaseTimer) set(p *time.Time, first bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if first && !p.IsZero() {
+		return
+	}
+	*p = time.Now()
+}
+
+func (t *phaseTimer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(&t.dnsStart, true) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone, false) },
+		ConnectStart:         func(string, string) { t.set(&t.connectStart, true) },
+		ConnectDone:          func(string, string, error) { t.set(&t.connectDone, false) },
+		TLSHandshakeStart:    func() { t.set(&t.tlsStart, true) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.set(&t.tlsDone, false) },
+		GotConn:              func(httptrace.GotConnInfo) { t.set(&t.gotConn, false) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte, false) },
+	}
+}
+
+// phases - phases of the request, its response body read until end, zero if never read.
+func (t *phaseTimer) phases(end time.Time) Phases {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return Phases{
+		DNS:       between(t.dnsStart, t.dnsDone),
+		Connect:   between(t.connectStart, t.connectDone),
+		TLS:       between(t.tlsStart, t.tlsDone),
+		FirstByte: between(t.wroteRequest, t.firstByte),
+		Transfer:  between(t.gotConn, t.wroteRequest) + between(t.firstByte, end),
+	}
+}
+
+// between - time from start to end, zero unless both happened in order.
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// phaseBody records the phases of its request once read until EOF or closed.
+type phaseBody struct {
+	io.ReadCloser
+	timer    *phaseTimer
+	recorder PhaseRecorder
+	once     sync.Once
+}
+
+func (b *phaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.record()
+	}
+	return n, err
+}
+
+func (b *phaseBody) Close() error {
+	b.record()
+	return b.ReadCloser.Close()
+}
+
+func (b *phaseBody) record() {
+	b.once.Do(func() {
+		b.recorder.RecordPhases(b.timer.phases(time.Now()))
+	})
+}
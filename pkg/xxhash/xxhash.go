@@ -0,0 +1,154 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package xxhash implements XXH64, a fast non-cryptographic hash. It detects changed
+// data, it does not protect against data changed deliberately to keep its hash.
+package xxhash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+// Size - size of the XXH64 checksum in bytes.
+const Size = 8
+
+// digest - XXH64 of data written so far, stripes of 32 bytes are processed as they fill up.
+type digest struct {
+	v1, v2, v3, v4 uint64
+	total          uint64
+	mem            [32]byte
+	n              int // bytes of mem in use.
+}
+
+// New - XXH64 with seed 0, its Sum is the checksum in big endian.
+func New() hash.Hash64 {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+// Sum64 - XXH64 of b with seed 0.
+func Sum64(b []byte) uint64 {
+	d := new(digest)
+	d.Reset()
+	d.Write(b)
+	return d.Sum64()
+}
+
+func (d *digest) Reset() {
+	// Constants overflow, variables wrap around as the algorithm expects.
+	p1, p2 := prime1, prime2
+	d.v1 = p1 + p2
+	d.v2 = p2
+	d.v3 = 0
+	d.v4 = -p1
+	d.total = 0
+	d.n = 0
+}
+
+func (d *digest) Size() int      { return Size }
+func (d *digest) BlockSize() int { return 32 }
+
+func (d *digest) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+	if d.n+len(b) < 32 {
+		d.n += copy(d.mem[d.n:], b)
+		return n, nil
+	}
+	if d.n > 0 {
+		c := copy(d.mem[d.n:], b)
+		d.stripe(d.mem[:])
+		b = b[c:]
+		d.n = 0
+	}
+	for ; len(b) >= 32; b = b[32:] {
+		d.stripe(b)
+	}
+	d.n = copy(d.mem[:], b)
+	return n, nil
+}
+
+// stripe - mix 32 bytes of b into the accumulators.
+func (d *digest) stripe(b []byte) {
+	d.v1 = round(d.v1, binary.LittleEndian.Uint64(b[0:8]))
+	d.v2 = round(d.v2, binary.LittleEndian.Uint64(b[8:16]))
+	d.v3 = round(d.v3, binary.LittleEndian.Uint64(b[16:24]))
+	d.v4 = round(d.v4, binary.LittleEndian.Uint64(b[24:32]))
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	var sum [Size]byte
+	binary.BigEndian.PutUint64(sum[:], d.Sum64())
+	return append(b, sum[:]...)
+}
+
+func (d *digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= 32 {
+		h = bits.RotateLeft64(d.v1, 1) + bits.RotateLeft64(d.v2, 7) + bits.RotateLeft64(d.v3, 12) + bits.RotateLeft64(d.v4, 18)
+		h = mergeRound(h, d.v1)
+		h = mergeRound(h, d.v2)
+		h = mergeRound(h, d.v3)
+		h = mergeRound(h, d.v4)
+	} else {
+		h = d.v3 + prime5
+	}
+	h += d.total
+
+	b := d.mem[:d.n]
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(b))
+		h = bits.RotateLeft64(h, 27)*prime1 + prime4
+	}
+	if len(b) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(b)) * prime1
+		h = bits.RotateLeft64(h, 23)*prime2 + prime3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * prime5
+		h = bits.RotateLeft64(h, 11) * prime1
+	}
+
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+	return h
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, val uint64) uint64 {
+	acc ^= round(0, val)
+	return acc*prime1 + prime4
+}
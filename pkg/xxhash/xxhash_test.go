@@ -0,0 +1,65 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xxhash_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/minio/mc/pkg/xxhash"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) TestSum64(c *C) {
+	vectors := []struct {
+		data string
+		sum  uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+		{"The quick brown fox jumps over the lazy dog", 0x0b242d361fda71bc},
+	}
+	for _, v := range vectors {
+		c.Assert(xxhash.Sum64([]byte(v.data)), Equals, v.sum, Commentf("%q", v.data))
+	}
+}
+
+func (s *MySuite) TestStreaming(c *C) {
+	// Data written in pieces of any size hashes like data written at once.
+	data := bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 100)
+	for _, size := range []int{1, 3, 7, 31, 32, 33, 100, len(data)} {
+		h := xxhash.New()
+		for b := data; len(b) > 0; {
+			n := size
+			if n > len(b) {
+				n = len(b)
+			}
+			h.Write(b[:n])
+			b = b[n:]
+		}
+		c.Assert(h.Sum64(), Equals, xxhash.Sum64(data), Commentf("pieces of %d", size))
+		c.Assert(fmt.Sprintf("%x", h.Sum(nil)), Equals, fmt.Sprintf("%016x", xxhash.Sum64(data)))
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	policyFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of policy.",
+		},
+	}
+)
+
+// Manage bucket policy.
+var policyCmd = cli.Command{
+	Name:   "policy",
+	Usage:  "Manage anonymous access through bucket policies.",
+	Action: mainPolicy,
+	Flags:  append(policyFlags, globalFlags...),
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] set PERMISSION TARGET
+   mc {{.Name}} [FLAGS] set-json TARGET [FILE]
+   mc {{.Name}} [FLAGS] get TARGET
+   mc {{.Name}} [FLAGS] check TARGET
+
+PERMISSION:
+   Allowed permissions are: [none, download, upload, public]. Permissions apply to
+   objects under the prefix of TARGET and replace any existing bucket policy.
+
+CHECK:
+   Reports the anonymous access the policy grants to every object under the prefix
+   of TARGET: [none, readonly, writeonly, readwrite]. Folders report the access of others.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Allow anonymous downloads of objects under a prefix on Amazon S3 cloud storage.
+      $ mc {{.Name}} set download s3/shared/public-downloads
+
+   2. Allow anonymous uploads to a bucket.
+      $ mc {{.Name}} set upload s3/incoming
+
+   3. Remove the bucket policy.
+      $ mc {{.Name}} set none s3/shared
+
+   4. Set a hand written policy document from a file.
+      $ mc {{.Name}} set-json s3/shared policy.json
+
+   5. Copy the policy of one bucket to another.
+      $ mc --json {{.Name}} get s3/shared | jq .policy | mc {{.Name}} set-json s3/archive
+
+   6. Display the policy of a bucket.
+      $ mc {{.Name}} get s3/shared
+
+   7. Confirm that objects under a prefix can be downloaded by anyone.
+      $ mc {{.Name}} check s3/shared/public-downloads
+`,
+}
+
+// policyMessage is container for policy command on bucket success messages.
+type policyMessage struct {
+	Operation string          `json:"operation"`
+	Status    string          `json:"status"`
+	Bucket    string          `json:"bucket"`
+	Perms     string          `json:"permission,omitempty"`
+	Policy    json.RawMessage `json:"policy,omitempty"`
+	Access    string          `json:"access,omitempty"`
+}
+
+// String colorized policy message.
+func (p policyMessage) String() string {
+	switch p.Operation {
+	case "set":
+		return console.Colorize("Policy", "Policy ‘"+p.Perms+"’ set for ‘"+p.Bucket+"’.")
+	case "set-json":
+		return console.Colorize("Policy", "Policy set for ‘"+p.Bucket+"’.")
+	case "check":
+		return console.Colorize("Policy", "Anonymous access to ‘"+p.Bucket+"’ is ‘"+p.Access+"’.")
+	}
+	if len(p.Policy) == 0 {
+		return console.Colorize("Policy", "No policy set for ‘"+p.Bucket+"’.")
+	}
+	return prettyPolicy(string(p.Policy))
+}
+
+// JSON jsonified policy message.
+func (p policyMessage) JSON() string {
+	policyJSONBytes, e := json.Marshal(p)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(policyJSONBytes)
+}
+
+// checkPolicySyntax check for incoming syntax.
+func checkPolicySyntax(ctx *cli.Context) {
+	args := ctx.Args()
+	switch args.First() {
+	case "set":
+		if len(args) != 3 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code.
+		}
+		if !isValidCannedPolicy(args.Get(1)) {
+			fatalIf(errDummy().Trace(),
+				"Unrecognized permission ‘"+args.Get(1)+"’. Allowed values are [none, download, upload, public].")
+		}
+	case "set-json":
+		if len(args) != 2 && len(args) != 3 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code.
+		}
+	case "get", "check":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code.
+		}
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code.
+	}
+}
+
+// url2BucketAndPrefix - split path of a target into bucket and object prefix.
+func url2BucketAndPrefix(path string) (bucket, prefix string) {
+	splits := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		prefix = splits[1]
+	}
+	return bucket, prefix
+}
+
+// doSetCannedPolicy set a canned policy scoped to the prefix of target.
+func doSetCannedPolicy(targetURL, perm string) *probe.Error {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	bucket, prefix := url2BucketAndPrefix(clnt.GetURL().Path)
+	policy, err := cannedPolicy(perm, bucket, prefix)
+	if err != nil {
+		return err.Trace(targetURL, perm)
+	}
+	return clnt.SetBucketPolicy(policy).Trace(targetURL, perm)
+}
+
+// doSetPolicy validate and set a policy document.
+func doSetPolicy(targetURL string, policy []byte) *probe.Error {
+	document, err := validatePolicy(policy)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	return clnt.SetBucketPolicy(document).Trace(targetURL)
+}
+
+// doGetPolicy get policy document, empty if none is set.
+func doGetPolicy(targetURL string) (string, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	policy, err := clnt.GetBucketPolicy()
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	return policy, nil
+}
+
+// doCheckAccess get effective anonymous access to objects under target.
+func doCheckAccess(targetURL string) (string, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	access, err := clnt.GetAnonymousAccess()
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	return access, nil
+}
+
+// readPolicy - read policy document from file, or from stdin if file is empty or ‘-’.
+func readPolicy(file string) ([]byte, *probe.Error) {
+	var policy []byte
+	var e error
+	if file == "" || file == "-" {
+		policy, e = ioutil.ReadAll(os.Stdin)
+	} else {
+		policy, e = ioutil.ReadFile(file)
+	}
+	if e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+	return policy, nil
+}
+
+func mainPolicy(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'policy' cli arguments.
+	checkPolicySyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Policy", color.New(color.FgGreen, color.Bold))
+
+	args := ctx.Args()
+	operation := args.First()
+	msg := policyMessage{Status: "success", Operation: operation}
+	switch operation {
+	case "set":
+		msg.Perms, msg.Bucket = args.Get(1), args.Get(2)
+		err := doSetCannedPolicy(msg.Bucket, msg.Perms)
+		fatalIf(err.Trace(msg.Bucket, msg.Perms), "Unable to set policy ‘"+msg.Perms+"’ for ‘"+msg.Bucket+"’.")
+		if globalDryRun { // Already printed by the dry run client.
+			return
+		}
+	case "set-json":
+		msg.Bucket = args.Get(1)
+		policy, err := readPolicy(args.Get(2))
+		fatalIf(err.Trace(args.Get(2)), "Unable to read policy document.")
+		err = doSetPolicy(msg.Bucket, policy)
+		fatalIf(err.Trace(msg.Bucket), "Unable to set policy for ‘"+msg.Bucket+"’.")
+		if globalDryRun { // Already printed by the dry run client.
+			return
+		}
+	case "get":
+		msg.Bucket = args.Get(1)
+		policy, err := doGetPolicy(msg.Bucket)
+		fatalIf(err.Trace(msg.Bucket), "Unable to get policy for ‘"+msg.Bucket+"’.")
+		if policy != "" {
+			msg.Policy = json.RawMessage(policy)
+		}
+	case "check":
+		msg.Bucket = args.Get(1)
+		access, err := doCheckAccess(msg.Bucket)
+		fatalIf(err.Trace(msg.Bucket), "Unable to check anonymous access to ‘"+msg.Bucket+"’.")
+		msg.Access = access
+	}
+	printMsg(msg)
+}
@@ -0,0 +1,192 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Canned policies, granting anonymous access to a bucket or a prefix.
+const (
+	policyNone     = "none"
+	policyDownload = "download"
+	policyUpload   = "upload"
+	policyPublic   = "public"
+)
+
+// policyVersion - version of the policy language.
+const policyVersion = "2012-10-17"
+
+// isValidCannedPolicy - validate canned policy name.
+func isValidCannedPolicy(perm string) bool {
+	switch perm {
+	case policyNone, policyDownload, policyUpload, policyPublic:
+		return true
+	}
+	return false
+}
+
+// policyStatement - single statement of a generated policy.
+type policyStatement struct {
+	Effect    string                         `json:"Effect"`
+	Principal map[string][]string            `json:"Principal"`
+	Action    []string                       `json:"Action"`
+	Resource  []string                       `json:"Resource"`
+	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+// bucketPolicy - generated policy document.
+type bucketPolicy struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+// newAnonymousStatement - statement allowing actions on resource to everyone.
+func newAnonymousStatement(actions []string, resource string) policyStatement {
+	return policyStatement{
+		Effect:    "Allow",
+		Principal: map[string][]string{"AWS": {"*"}},
+		Action:    actions,
+		Resource:  []string{resource},
+	}
+}
+
+// cannedPolicy - translate a canned policy into a JSON policy document scoped to
+// objects under prefix, an empty prefix covers the whole bucket. ‘none’ translates
+// into an empty document.
+func cannedPolicy(perm, bucket, prefix string) (string, *probe.Error) {
+	if !isValidCannedPolicy(perm) {
+		return "", errInvalidArgument().Trace(perm)
+	}
+	if perm == policyNone {
+		return "", nil
+	}
+	bucketResource := "arn:aws:s3:::" + bucket
+	objectResource := bucketResource + "/" + prefix + "*"
+
+	bucketActions := []string{"s3:GetBucketLocation"}
+	var objectActions []string
+	if perm == policyUpload || perm == policyPublic {
+		bucketActions = append(bucketActions, "s3:ListBucketMultipartUploads")
+		objectActions = append(objectActions, "s3:AbortMultipartUpload", "s3:DeleteObject",
+			"s3:ListMultipartUploadParts", "s3:PutObject")
+	}
+	statements := []policyStatement{newAnonymousStatement(bucketActions, bucketResource)}
+	if perm == policyDownload || perm == policyPublic {
+		// Listing is limited to the prefix through a condition on the bucket.
+		listStatement := newAnonymousStatement([]string{"s3:ListBucket"}, bucketResource)
+		if prefix != "" {
+			listStatement.Condition = map[string]map[string][]string{
+				"StringLike": {"s3:prefix": {prefix + "*"}},
+			}
+		}
+		statements = append(statements, listStatement)
+		objectActions = append([]string{"s3:GetObject"}, objectActions...)
+	}
+	statements = append(statements, newAnonymousStatement(objectActions, objectResource))
+
+	policyBytes, e := json.Marshal(bucketPolicy{Version: policyVersion, Statement: statements})
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return string(policyBytes), nil
+}
+
+// validatePolicy - structural validation of a user supplied policy document,
+// returns it compacted. Fields are only checked for presence and type, the
+// server remains the authority on what a policy may grant.
+func validatePolicy(policy []byte) (string, *probe.Error) {
+	var document map[string]interface{}
+	if e := json.Unmarshal(policy, &document); e != nil {
+		return "", errInvalidPolicy("must be a JSON object").Trace(e.Error())
+	}
+	if version, ok := document["Version"]; ok {
+		if _, ok := version.(string); !ok {
+			return "", errInvalidPolicy("‘Version’ must be a string")
+		}
+	}
+	var statements []interface{}
+	switch statement := document["Statement"].(type) {
+	case []interface{}:
+		statements = statement
+	case map[string]interface{}:
+		statements = []interface{}{statement}
+	}
+	if len(statements) == 0 {
+		return "", errInvalidPolicy("‘Statement’ must hold at least one statement")
+	}
+	for _, s := range statements {
+		statement, ok := s.(map[string]interface{})
+		if !ok {
+			return "", errInvalidPolicy("every statement must be a JSON object")
+		}
+		if effect := statement["Effect"]; effect != "Allow" && effect != "Deny" {
+			return "", errInvalidPolicy("‘Effect’ must be one of ‘Allow’ or ‘Deny’")
+		}
+		if !hasPolicyField(statement, "Principal") {
+			return "", errInvalidPolicy("statement is missing ‘Principal’")
+		}
+		for _, field := range []string{"Action", "Resource"} {
+			if !hasPolicyField(statement, field) {
+				return "", errInvalidPolicy("statement is missing ‘" + field + "’")
+			}
+			if !isPolicyStrings(statement[field]) && !isPolicyStrings(statement["Not"+field]) {
+				return "", errInvalidPolicy("‘" + field + "’ must be a string or a list of strings")
+			}
+		}
+	}
+	var compacted bytes.Buffer
+	if e := json.Compact(&compacted, policy); e != nil {
+		return "", probe.NewError(e)
+	}
+	return compacted.String(), nil
+}
+
+// hasPolicyField - statement holds field or its negated form.
+func hasPolicyField(statement map[string]interface{}, field string) bool {
+	_, ok := statement[field]
+	_, notOk := statement["Not"+field]
+	return ok || notOk
+}
+
+// isPolicyStrings - value is a string or a non empty list of strings.
+func isPolicyStrings(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return true
+	case []interface{}:
+		for _, s := range v {
+			if _, ok := s.(string); !ok {
+				return false
+			}
+		}
+		return len(v) > 0
+	}
+	return false
+}
+
+// prettyPolicy - indent a policy document for display, returned as is if it is not JSON.
+func prettyPolicy(policy string) string {
+	var indented bytes.Buffer
+	if e := json.Indent(&indented, []byte(policy), "", "  "); e != nil {
+		return policy
+	}
+	return indented.String()
+}
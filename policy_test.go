@@ -0,0 +1,130 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCannedPolicy(c *C) {
+	policy, err := cannedPolicy(policyNone, "shared", "")
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, "")
+
+	_, err = cannedPolicy("readonly", "shared", "")
+	c.Assert(err, Not(IsNil))
+
+	policy, err = cannedPolicy(policyDownload, "shared", "downloads/")
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetBucketLocation"],"Resource":["arn:aws:s3:::shared"]},`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::shared"],"Condition":{"StringLike":{"s3:prefix":["downloads/*"]}}},`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::shared/downloads/*"]}]}`)
+
+	policy, err = cannedPolicy(policyUpload, "incoming", "")
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetBucketLocation","s3:ListBucketMultipartUploads"],"Resource":["arn:aws:s3:::incoming"]},`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:AbortMultipartUpload","s3:DeleteObject","s3:ListMultipartUploadParts","s3:PutObject"],"Resource":["arn:aws:s3:::incoming/*"]}]}`)
+
+	policy, err = cannedPolicy(policyPublic, "shared", "")
+	c.Assert(err, IsNil)
+	var document bucketPolicy
+	c.Assert(json.Unmarshal([]byte(policy), &document), IsNil)
+	c.Assert(document.Statement, HasLen, 3)
+	c.Assert(document.Statement[1].Condition, IsNil)
+	c.Assert(document.Statement[2].Action, DeepEquals, []string{"s3:GetObject", "s3:AbortMultipartUpload",
+		"s3:DeleteObject", "s3:ListMultipartUploadParts", "s3:PutObject"})
+
+	// Generated policies pass our own validation.
+	for _, perm := range []string{policyDownload, policyUpload, policyPublic} {
+		policy, err = cannedPolicy(perm, "shared", "prefix")
+		c.Assert(err, IsNil)
+		_, err = validatePolicy([]byte(policy))
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *TestSuite) TestPolicyRoundTrip(c *C) {
+	handWritten := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "DenyInsecure",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": "s3:*",
+      "Resource": [
+        "arn:aws:s3:::shared",
+        "arn:aws:s3:::shared/*"
+      ],
+      "Condition": {
+        "Bool": {
+          "aws:SecureTransport": "false"
+        }
+      }
+    },
+    {
+      "Effect": "Allow",
+      "NotPrincipal": {
+        "AWS": "arn:aws:iam::111122223333:root"
+      },
+      "NotAction": [
+        "s3:DeleteObject"
+      ],
+      "Resource": "arn:aws:s3:::shared/*"
+    }
+  ]
+}`
+	document, err := validatePolicy([]byte(handWritten))
+	c.Assert(err, IsNil)
+	c.Assert(document, Not(Equals), handWritten)
+	c.Assert(prettyPolicy(document), Equals, handWritten)
+
+	// Get embeds the document as is.
+	msg := policyMessage{Status: "success", Operation: "get", Bucket: "s3/shared", Policy: json.RawMessage(document)}
+	var decoded policyMessage
+	c.Assert(json.Unmarshal([]byte(msg.JSON()), &decoded), IsNil)
+	c.Assert(string(decoded.Policy), Equals, document)
+	c.Assert(msg.String(), Equals, handWritten)
+}
+
+func (s *TestSuite) TestValidatePolicy(c *C) {
+	invalid := []string{
+		`not json`,
+		`["Statement"]`,
+		`{"Version": 2012}`,
+		`{"Version": "2012-10-17"}`,
+		`{"Statement": []}`,
+		`{"Statement": ["Allow"]}`,
+		`{"Statement": [{"Effect": "Maybe", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::b/*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::b/*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Principal": "*", "Resource": "arn:aws:s3:::b/*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": [1], "Resource": "arn:aws:s3:::b/*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": []}]}`,
+	}
+	for _, policy := range invalid {
+		_, err := validatePolicy([]byte(policy))
+		c.Assert(err, Not(IsNil), Commentf("%s", policy))
+	}
+
+	// A single statement need not be wrapped in a list.
+	_, err := validatePolicy([]byte(`{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::b/*"}}`))
+	c.Assert(err, IsNil)
+}
@@ -24,11 +24,10 @@ type message interface {
 	String() string
 }
 
-// printMsg prints message string or JSON structure depending on the type of output console.
+// printMsg prints message in the output format of ‘--output’, string or JSON structure
+// depending on the type of output console if not set.
 func printMsg(msg message) {
-	if !globalJSON {
-		console.Println(msg.String())
-	} else {
-		console.Println(msg.JSON())
+	if lines := getOutputRenderer().Render(msg); lines != "" {
+		console.Println(lines)
 	}
 }
@@ -0,0 +1,178 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"sync"
+)
+
+// progressAccumulator - progress of a transfer shared by all its workers, the progress
+// bar and session saves read the same totals. Bytes are kept per object in flight, a
+// retried object takes back what its failed attempt reported instead of counting twice.
+type progressAccumulator struct {
+	mutex              sync.Mutex
+	totalBytes         int64
+	totalObjects       int
+	transferredBytes   int64
+	transferredObjects int
+	// Bytes reported by objects in flight, keyed by URL.
+	inFlight map[string]int64
+}
+
+// accumulatorStat - consistent snapshot of a progressAccumulator.
+type accumulatorStat struct {
+	TotalBytes         int64
+	TotalObjects       int
+	TransferredBytes   int64
+	TransferredObjects int
+}
+
+// newProgressAccumulator - progress of a transfer of totalObjects objects, totalBytes in size.
+func newProgressAccumulator(totalBytes int64, totalObjects int) *progressAccumulator {
+	return &progressAccumulator{
+		totalBytes:   totalBytes,
+		totalObjects: totalObjects,
+		inFlight:     make(map[string]int64),
+	}
+}
+
+// AddTotal adds an object of size to the transfer.
+func (p *progressAccumulator) AddTotal(size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.totalObjects++
+	p.totalBytes += size
+}
+
+// AddCompleted counts objects transferred by an earlier run of a resumed transfer.
+func (p *progressAccumulator) AddCompleted(bytes int64, objects int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.transferredBytes += bytes
+	p.transferredObjects += objects
+}
+
+// Resize changes the size of an object from size from to size to, it changed since the
+// transfer was prepared.
+func (p *progressAccumulator) Resize(from, to int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.totalBytes += to - from
+}
+
+// Add reports n more bytes transferred of object url.
+func (p *progressAccumulator) Add(url string, n int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.inFlight[url] += n
+	p.transferredBytes += n
+}
+
+// Set reports n bytes transferred of object url so far, used by resumed transfers seeking ahead.
+func (p *progressAccumulator) Set(url string, n int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.transferredBytes += n - p.inFlight[url]
+	p.inFlight[url] = n
+}
+
+// Retry takes back the bytes reported by the failed attempt of object url, returns them.
+func (p *progressAccumulator) Retry(url string) int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n := p.inFlight[url]
+	delete(p.inFlight, url)
+	p.transferredBytes -= n
+	return n
+}
+
+// Failed gives up on object url, its bytes are taken back like a retry.
+func (p *progressAccumulator) Failed(url string) int64 {
+	return p.Retry(url)
+}
+
+// Done marks object url of size transferred, whatever its attempts reported.
+func (p *progressAccumulator) Done(url string, size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.transferredBytes += size - p.inFlight[url]
+	p.transferredObjects++
+	delete(p.inFlight, url)
+}
+
+// Stat returns a consistent snapshot of the progress.
+func (p *progressAccumulator) Stat() accumulatorStat {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return accumulatorStat{
+		TotalBytes:         p.totalBytes,
+		TotalObjects:       p.totalObjects,
+		TransferredBytes:   p.transferredBytes,
+		TransferredObjects: p.transferredObjects,
+	}
+}
+
+// Percent of the bytes transferred, of objects if all are empty.
+func (s accumulatorStat) Percent() float64 {
+	if s.TotalBytes > 0 {
+		return float64(s.TransferredBytes) * 100 / float64(s.TotalBytes)
+	}
+	if s.TotalObjects > 0 {
+		return float64(s.TransferredObjects) * 100 / float64(s.TotalObjects)
+	}
+	return 0
+}
+
+// progressAccumulatorReader - reports bytes read of an object to its progressAccumulator.
+type progressAccumulatorReader struct {
+	io.ReadSeeker
+	url      string
+	progress *progressAccumulator
+}
+
+// NewProxyReader reports bytes read from r as progress of object url.
+func (p *progressAccumulator) NewProxyReader(url string, r io.ReadSeeker) io.ReadSeeker {
+	return &progressAccumulatorReader{r, url, p}
+}
+
+// Read implements Reader, reporting bytes read.
+func (r *progressAccumulatorReader) Read(b []byte) (n int, err error) {
+	n, err = r.ReadSeeker.Read(b)
+	if n > 0 {
+		r.progress.Add(r.url, int64(n))
+	}
+	return
+}
+
+// Seek implements Seeker, the offset sought is reported transferred.
+func (r *progressAccumulatorReader) Seek(offset int64, whence int) (n int64, err error) {
+	n, err = r.ReadSeeker.Seek(offset, whence)
+	if err != nil {
+		return
+	}
+	r.progress.Set(r.url, n)
+	return
+}
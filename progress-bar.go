@@ -17,7 +17,6 @@
 package main
 
 import (
-	"io"
 	"runtime"
 	"strings"
 	"time"
@@ -38,35 +37,10 @@ const (
 	pbBarPutError
 	pbBarGetError
 	pbBarSetCaption
+	pbBarResume
+	pbBarAddTotal
 )
 
-// proxyReader progress bar proxy reader for barSend inherits io.ReadCloser.
-type proxyReader struct {
-	io.ReadSeeker
-	bar *barSend
-}
-
-// Read proxy Read sends progress for each Read operation.
-func (r *proxyReader) Read(p []byte) (n int, err error) {
-	n, err = r.ReadSeeker.Read(p)
-	if err != nil {
-		if err != io.EOF {
-			return
-		}
-	}
-	r.bar.Progress(int64(n))
-	return
-}
-
-func (r *proxyReader) Seek(offset int64, whence int) (n int64, err error) {
-	n, err = r.ReadSeeker.Seek(offset, whence)
-	if err != nil {
-		return
-	}
-	r.bar.Progress(n)
-	return
-}
-
 // barMsg progress bar message for a given operation.
 type barMsg struct {
 	Op  pbBar
@@ -79,12 +53,7 @@ type barSend struct {
 	finishCh <-chan bool
 }
 
-// Instantiate a new progress bar proxy reader.
-func (b *barSend) NewProxyReader(r io.ReadSeeker) *proxyReader {
-	return &proxyReader{r, b}
-}
-
-// Progress send current progress message.
+// Progress send current progress message, a client.ProgressFunc reporting transfers to the bar.
 func (b barSend) Progress(progress int64) {
 	b.opCh <- barMsg{Op: pbBarProgress, Arg: progress}
 }
@@ -104,6 +73,16 @@ func (b *barSend) SetCaption(c string) {
 	b.opCh <- barMsg{Op: pbBarSetCaption, Arg: c}
 }
 
+// Resume starts the progress bar at bytes transferred by an earlier run, before any progress.
+func (b barSend) Resume(bytes int64) {
+	b.opCh <- barMsg{Op: pbBarResume, Arg: bytes}
+}
+
+// AddTotal send message for a change of size of the transfer.
+func (b barSend) AddTotal(size int64) {
+	b.opCh <- barMsg{Op: pbBarAddTotal, Arg: size}
+}
+
 // Finish finishes the progress bar and closes the message channel.
 func (b barSend) Finish() {
 	defer close(b.opCh)
@@ -216,10 +195,17 @@ func newProgressBar(total int64) *barSend {
 					totalBytesRead += msg.Arg.(int64)
 					bar.Add64(msg.Arg.(int64))
 				}
+			case pbBarResume:
+				// Bytes of an earlier run are not counted towards the speed, the bar starts past them.
+				totalBytesRead += msg.Arg.(int64)
+				bar.Set64(totalBytesRead)
+			case pbBarAddTotal:
+				bar.Total += msg.Arg.(int64)
 			case pbBarPutError:
 				// Negates any put error of size from totalBytes.
 				if totalBytesRead > msg.Arg.(int64) {
-					bar.Set64(totalBytesRead - msg.Arg.(int64))
+					totalBytesRead -= msg.Arg.(int64)
+					bar.Set64(totalBytesRead)
 				}
 			case pbBarGetError:
 				// Retains any size transferred but failed.
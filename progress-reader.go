@@ -0,0 +1,305 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/pb"
+	"github.com/olekukonko/ts"
+)
+
+// progressReader tracks progress of a single object transfer, inherits io.ReadSeeker.
+type progressReader struct {
+	io.ReadSeeker
+	caption   string
+	total     int64 // negative if size is unknown, for example stdin.
+	current   int64 // accessed atomically.
+	startTime time.Time
+
+	mutex      *sync.Mutex
+	lastBytes  int64
+	lastSample time.Time
+	cursor     <-chan rune
+}
+
+// newProgressReader - instantiate a new progress reader, total is negative if size is unknown.
+func newProgressReader(caption string, reader io.ReadSeeker, total int64) *progressReader {
+	now := time.Now()
+	return &progressReader{
+		ReadSeeker: reader,
+		caption:    caption,
+		total:      total,
+		startTime:  now,
+		mutex:      new(sync.Mutex),
+		lastSample: now,
+	}
+}
+
+// Read implements io.Reader, accounts for every byte read.
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.ReadSeeker.Read(b)
+	atomic.AddInt64(&p.current, int64(n))
+	return
+}
+
+// Seek implements io.Seeker, progress follows the new offset.
+func (p *progressReader) Seek(offset int64, whence int) (n int64, err error) {
+	n, err = p.ReadSeeker.Seek(offset, whence)
+	if err != nil {
+		return
+	}
+	atomic.StoreInt64(&p.current, n)
+	return
+}
+
+// progressStat container for progress captured at a point in time.
+type progressStat struct {
+	Total        int64
+	Current      int64
+	Percent      float64
+	Speed        float64
+	AverageSpeed float64
+	ETA          time.Duration
+}
+
+// Stat provides current progress, instantaneous speed is measured since the previous Stat.
+func (p *progressReader) Stat() progressStat {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	current := atomic.LoadInt64(&p.current)
+	stat := progressStat{Total: p.total, Current: current}
+	if elapsed := now.Sub(p.startTime).Seconds(); elapsed > 0 {
+		stat.AverageSpeed = float64(current) / elapsed
+	}
+	if elapsed := now.Sub(p.lastSample).Seconds(); elapsed > 0 {
+		stat.Speed = float64(current-p.lastBytes) / elapsed
+	}
+	p.lastBytes = current
+	p.lastSample = now
+	if p.total > 0 {
+		stat.Percent = float64(current) * 100 / float64(p.total)
+		if stat.AverageSpeed > 0 && current < p.total {
+			stat.ETA = time.Duration(float64(p.total-current) / stat.AverageSpeed * float64(time.Second))
+		}
+	}
+	return stat
+}
+
+// progressBarWidth width of the rendered bar excluding caption and counters.
+const progressBarWidth = 20
+
+// formatSpeed - humanized speed.
+func formatSpeed(speed float64) string {
+	speedBox := pb.FormatBytes(int64(speed))
+	if speedBox == "" {
+		return "0 B/s"
+	}
+	return speedBox + "/s"
+}
+
+// Line renders a single progress line, a spinner is rendered when size is unknown.
+func (p *progressReader) Line(width int) string {
+	stat := p.Stat()
+	if p.total < 0 {
+		if p.cursor == nil {
+			p.cursor = cursorAnimate()
+		}
+		counters := fmt.Sprintf(" %c %s  %s", <-p.cursor, pb.FormatBytes(stat.Current), formatSpeed(stat.Speed))
+		return fixateBarCaption(p.caption, getFixedWidth(width, 30)) + counters
+	}
+	filled := int(stat.Percent) * progressBarWidth / 100
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := "[" + strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled) + "]"
+	eta := "--"
+	if stat.ETA > 0 {
+		eta = (stat.ETA / time.Second * time.Second).String()
+	}
+	counters := fmt.Sprintf(" %s %3.0f%% %s / %s  %s (avg %s)  ETA %s", bar, stat.Percent,
+		pb.FormatBytes(stat.Current), pb.FormatBytes(stat.Total), formatSpeed(stat.Speed),
+		formatSpeed(stat.AverageSpeed), eta)
+	return fixateBarCaption(p.caption, getFixedWidth(width, 30)) + counters
+}
+
+// progressMessage container for periodic progress JSON messages.
+type progressMessage struct {
+	Status       string  `json:"status"`
+	Source       string  `json:"source"`
+	Total        int64   `json:"total"`
+	Transferred  int64   `json:"transferred"`
+	Percent      float64 `json:"percent"`
+	Speed        float64 `json:"speed"`
+	AverageSpeed float64 `json:"averageSpeed"`
+	ETA          float64 `json:"eta"`
+}
+
+// String progress message.
+func (p progressMessage) String() string {
+	return fmt.Sprintf("%s: %s / %s", p.Source, pb.FormatBytes(p.Transferred), pb.FormatBytes(p.Total))
+}
+
+// JSON jsonified progress message.
+func (p progressMessage) JSON() string {
+	p.Status = "progress"
+	progressBytes, e := json.Marshal(p)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(progressBytes)
+}
+
+// Message - captures current progress into a progress message.
+func (p *progressReader) Message() progressMessage {
+	stat := p.Stat()
+	return progressMessage{
+		Source:       p.caption,
+		Total:        stat.Total,
+		Transferred:  stat.Current,
+		Percent:      stat.Percent,
+		Speed:        stat.Speed,
+		AverageSpeed: stat.AverageSpeed,
+		ETA:          stat.ETA.Seconds(),
+	}
+}
+
+// progressRenderer renders stacked progress lines of concurrent transfers on a throttled interval.
+type progressRenderer struct {
+	mutex       *sync.Mutex
+	readers     []*progressReader
+	lines       int // number of active lines drawn previously.
+	refreshRate time.Duration
+	doneCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+// newProgressRenderer - instantiate a progress renderer, nothing is rendered in quiet mode.
+func newProgressRenderer() *progressRenderer {
+	// Progress bar speific theme customization.
+	console.SetColor("Bar", color.New(color.FgGreen, color.Bold))
+
+	r := &progressRenderer{
+		mutex:       new(sync.Mutex),
+		refreshRate: time.Millisecond * 125,
+		doneCh:      make(chan struct{}),
+	}
+	if globalJSON {
+		// JSON consumers do not need frequent updates.
+		r.refreshRate = time.Second
+	}
+	if !globalQuiet {
+		go r.renderer()
+	}
+	return r
+}
+
+// NewProxyReader - register a new transfer with the renderer.
+func (r *progressRenderer) NewProxyReader(caption string, reader io.ReadSeeker, total int64) *progressReader {
+	p := newProgressReader(caption, reader, total)
+	r.mutex.Lock()
+	r.readers = append(r.readers, p)
+	r.mutex.Unlock()
+	return p
+}
+
+// Done - render final state of a transfer and stop tracking it.
+func (r *progressRenderer) Done(p *progressReader) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for i, reader := range r.readers {
+		if reader == p {
+			r.readers = append(r.readers[:i], r.readers[i+1:]...)
+			break
+		}
+	}
+	if globalQuiet {
+		return
+	}
+	r.render([]*progressReader{p})
+}
+
+// Stop - stop rendering.
+func (r *progressRenderer) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.doneCh)
+	})
+}
+
+// renderer - renders all active transfers at refresh rate.
+func (r *progressRenderer) renderer() {
+	for {
+		select {
+		case <-r.doneCh:
+			return
+		case <-time.After(r.refreshRate):
+			r.mutex.Lock()
+			r.render(nil)
+			r.mutex.Unlock()
+		}
+	}
+}
+
+// render - redraws active transfers in place, finished transfers are printed once above them.
+func (r *progressRenderer) render(finished []*progressReader) {
+	if globalJSON {
+		for _, p := range append(finished, r.readers...) {
+			printMsg(p.Message())
+		}
+		return
+	}
+	console.Lock()
+	defer console.Unlock()
+	width := getTerminalWidth()
+	// Move cursor up to the beginning of the previously drawn stack.
+	if r.lines > 0 {
+		console.Printf("%c[%dA", 27, r.lines)
+	}
+	for _, p := range finished {
+		console.Print(console.Colorize("Bar", fmt.Sprintf("\r%c[2K%s\n", 27, p.Line(width))))
+	}
+	for _, p := range r.readers {
+		console.Print(console.Colorize("Bar", fmt.Sprintf("\r%c[2K%s\n", 27, p.Line(width))))
+	}
+	// Clear lines left over from transfers which finished since the last render.
+	for i := len(finished) + len(r.readers); i < r.lines; i++ {
+		console.Printf("\r%c[2K\n", 27)
+	}
+	if leftOver := r.lines - len(finished) - len(r.readers); leftOver > 0 {
+		console.Printf("%c[%dA", 27, leftOver)
+	}
+	r.lines = len(r.readers)
+}
+
+// getTerminalWidth - terminal width, falls back to 80 columns.
+func getTerminalWidth() int {
+	size, e := ts.GetSize()
+	if e != nil || size.Col() <= 0 {
+		return 80
+	}
+	return size.Col()
+}
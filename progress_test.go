@@ -0,0 +1,143 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestProgressReader(c *C) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	reader := newProgressReader("object", bytes.NewReader(data), int64(len(data)))
+
+	// Partial read.
+	n, e := io.CopyN(ioutil.Discard, reader, 1234)
+	c.Assert(e, IsNil)
+	stat := reader.Stat()
+	c.Assert(stat.Current, Equals, n)
+	c.Assert(stat.Total, Equals, int64(len(data)))
+
+	// Read till the end.
+	m, e := io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	stat = reader.Stat()
+	c.Assert(stat.Current, Equals, n+m)
+	c.Assert(stat.Current, Equals, int64(len(data)))
+	c.Assert(stat.Percent, Equals, float64(100))
+	c.Assert(stat.ETA, Equals, time.Duration(0))
+
+	// Seek resets progress to the new offset.
+	_, e = reader.Seek(100, 0)
+	c.Assert(e, IsNil)
+	c.Assert(reader.Stat().Current, Equals, int64(100))
+
+	c.Assert(strings.Contains(reader.Line(120), "object"), Equals, true)
+	c.Assert(reader.Message().Transferred, Equals, int64(100))
+}
+
+// progressMessageRenderer - records the progress messages printed.
+type progressMessageRenderer struct {
+	messages []progressMessage
+}
+
+func (r *progressMessageRenderer) Render(msg message) string {
+	if m, ok := msg.(progressMessage); ok {
+		r.messages = append(r.messages, m)
+	}
+	return ""
+}
+
+func (r *progressMessageRenderer) Flush() string { return "" }
+
+func (s *TestSuite) TestProgressRendererJSON(c *C) {
+	renderer := new(progressMessageRenderer)
+	savedJSON, savedRenderer := globalJSON, globalOutputRenderer
+	globalJSON, globalOutputRenderer = true, renderer
+	defer func() { globalJSON, globalOutputRenderer = savedJSON, savedRenderer }()
+
+	// JSON progress is printed in the output format of ‘--output’ as any message.
+	progress := newProgressRenderer()
+	defer progress.Stop()
+	reader := progress.NewProxyReader("object", bytes.NewReader([]byte("data")), 4)
+	_, e := io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	progress.Done(reader)
+	c.Assert(len(renderer.messages) > 0, Equals, true)
+	last := renderer.messages[len(renderer.messages)-1]
+	c.Assert(last.Source, Equals, "object")
+	c.Assert(last.Transferred, Equals, int64(4))
+}
+
+func (s *TestSuite) TestProgressReaderUnknownSize(c *C) {
+	data := []byte("Hello, World")
+	reader := newProgressReader("stdin", bytes.NewReader(data), -1)
+	_, e := io.Copy(ioutil.Discard, reader)
+	c.Assert(e, IsNil)
+	stat := reader.Stat()
+	c.Assert(stat.Current, Equals, int64(len(data)))
+	c.Assert(stat.Percent, Equals, float64(0))
+	c.Assert(strings.Contains(reader.Line(120), "["), Equals, false)
+}
+
+func (s *TestSuite) TestProgressAccumulator(c *C) {
+	const objects = 50
+	const size = 4096
+	progress := newProgressAccumulator(0, 0)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < objects; i++ {
+		progress.AddTotal(size)
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			data := bytes.Repeat([]byte("a"), size)
+			// First attempt fails half way, its progress is taken back on retry.
+			reader := progress.NewProxyReader(url, bytes.NewReader(data))
+			io.CopyN(ioutil.Discard, reader, size/2)
+			c.Check(progress.Retry(url), Equals, int64(size/2))
+			reader = progress.NewProxyReader(url, bytes.NewReader(data))
+			io.Copy(ioutil.Discard, reader)
+			progress.Done(url, size)
+		}("object-" + strconv.Itoa(i))
+	}
+	// Snapshots are consistent while the workers are running.
+	for i := 0; i < 100; i++ {
+		stat := progress.Stat()
+		c.Assert(stat.TransferredBytes <= stat.TotalBytes, Equals, true)
+	}
+	wg.Wait()
+
+	stat := progress.Stat()
+	c.Assert(stat.TotalObjects, Equals, objects)
+	c.Assert(stat.TotalBytes, Equals, int64(objects*size))
+	c.Assert(stat.TransferredObjects, Equals, objects)
+	c.Assert(stat.TransferredBytes, Equals, int64(objects*size))
+
+	// A failed object keeps none of its progress.
+	reader := progress.NewProxyReader("failed", strings.NewReader("partial"))
+	io.Copy(ioutil.Discard, reader)
+	c.Assert(progress.Failed("failed"), Equals, int64(len("partial")))
+	c.Assert(progress.Stat().TransferredBytes, Equals, int64(objects*size))
+}
@@ -0,0 +1,197 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	rbFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of rb.",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "Remove all objects, versions and incomplete uploads of the bucket before removing it.",
+		},
+	}
+)
+
+// remove a bucket or folder.
+var rbCmd = cli.Command{
+	Name:   "rb",
+	Usage:  "Remove a bucket or folder.",
+	Action: mainRemoveBucket,
+	Flags:  append(rbFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET [TARGET...]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Remove an empty bucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} s3/oldbucket
+
+   2. Remove a bucket and everything in it, asks to type the bucket name to confirm.
+      $ mc {{.Name}} --force s3/oldbucket
+
+   3. Remove a bucket and everything in it without asking, in scripts.
+      $ mc {{.Name}} --force --quiet s3/oldbucket
+
+   4. Remove an empty directory.
+      $ mc {{.Name}} /tmp/this/old/dir1
+`,
+}
+
+// removeBucketMessage is container for remove bucket success messages.
+type removeBucketMessage struct {
+	Status string `json:"status"`
+	Bucket string `json:"bucket"`
+}
+
+// String colorized remove bucket message.
+func (s removeBucketMessage) String() string {
+	return console.Colorize("RemoveBucket", "Removed bucket ‘"+s.Bucket+"’ successfully.")
+}
+
+// JSON jsonified remove bucket message.
+func (s removeBucketMessage) JSON() string {
+	removeBucketJSONBytes, err := json.Marshal(s)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(removeBucketJSONBytes)
+}
+
+// Validate command line arguments.
+func checkRemoveBucketSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "rb", 1) // last argument is exit code
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+		target := newRmTarget(arg)
+		if target.pattern != "" {
+			fatalIf(errInvalidArgument().Trace(arg), "Patterns cannot be used to remove buckets.")
+		}
+		// Emptying a prefix and then failing to remove it as a bucket leaves a half done job.
+		if target.isCloud && (target.bucket == "" || strings.Trim(target.prefix, "/") != "") {
+			fatalIf(errInvalidArgument().Trace(arg), "‘"+arg+"’ is not a bucket.")
+		}
+	}
+}
+
+// emptyBucket - remove the incomplete uploads and every object of the bucket of clnt, all versions
+// and delete markers of versioned buckets. Folders are emptied recursively.
+func emptyBucket(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error), targetAlias string) {
+	bucketURL := clnt.GetURL()
+	if bucketURL.Type != client.Object {
+		bucketURL.Path = strings.TrimSuffix(bucketURL.Path, string(bucketURL.Separator)) + string(bucketURL.Separator)
+		rmAll(targetAlias, bucketURL.String(), true, false, 0)
+		return
+	}
+
+	if total := rmStaleUploads(clnt, newClient, targetAlias, 0); total.Uploads > 0 {
+		printMsg(total)
+	}
+
+	// Versioning cannot be switched off again, suspended buckets still hold their old versions.
+	if status, err := clnt.GetBucketVersioning(); err == nil && status != "" {
+		// Every version is older than a cutoff in the future.
+		cutoff := time.Now().Add(24 * time.Hour)
+		printMsg(rmVersions(clnt, targetAlias, true, cutoff, true))
+		return
+	}
+	rmAllBatch(clnt, targetAlias, bucketURL.String())
+}
+
+// removeBucket - remove the bucket of clnt, emptied first with isForce.
+func removeBucket(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error), targetAlias string, isForce bool) *probe.Error {
+	if isForce {
+		emptyBucket(clnt, newClient, targetAlias)
+	}
+	if err := clnt.RemoveBucket(); err != nil {
+		return err.Trace(clnt.GetURL().String())
+	}
+	return nil
+}
+
+// mainRemoveBucket is entry point for rb command.
+func mainRemoveBucket(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'rb' cli arguments.
+	checkRemoveBucketSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("RemoveBucket", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+
+	isForce := ctx.Bool("force")
+	for _, url := range ctx.Args() {
+		target := newRmTarget(url)
+		targetAlias := target.alias
+		clnt, err := newClientFromAlias(targetAlias, target.url)
+		if err != nil {
+			errorIf(err.Trace(url), "Invalid target ‘"+url+"’.")
+			continue
+		}
+
+		bucket := target.bucket
+		if !target.isCloud {
+			bucket = filepath.Base(strings.TrimSuffix(clnt.GetURL().Path, string(clnt.GetURL().Separator)))
+		}
+		// Everything in the bucket is lost for good, ask unless told to be quiet.
+		if isForce && !globalQuiet && !globalDryRun && !confirmBucketName("Remove bucket ‘"+bucket+"’ and everything in it?", bucket) {
+			errorIf(errRbNotConfirmed(bucket), "Unable to remove bucket ‘"+url+"’.")
+			continue
+		}
+
+		newClient := func(urlStr string) (client.Client, *probe.Error) {
+			return newClientFromAlias(targetAlias, urlStr)
+		}
+		if err = removeBucket(clnt, newClient, targetAlias, isForce); err != nil {
+			if _, ok := err.ToGoError().(client.BucketNotEmpty); ok && !isForce {
+				errorIf(err.Trace(url), "Unable to remove bucket ‘"+url+"’, it is not empty. Use --force to remove everything in it as well.")
+				continue
+			}
+			errorIf(err.Trace(url), "Unable to remove bucket ‘"+url+"’.")
+			continue
+		}
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
+		printMsg(removeBucketMessage{Status: "success", Bucket: url})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/mock"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestRemoveBucket(c *C) {
+	isJSON, output := globalJSON, color.Output
+	defer func() { globalJSON, color.Output = isJSON, output }()
+	globalJSON = true
+	color.Output = new(bytes.Buffer)
+
+	store := mock.NewStore(map[string][]byte{
+		"empty":         nil,
+		"full/a.txt":    []byte("a"),
+		"full/dir/b.go": []byte("bb"),
+	})
+	newClient := func(urlStr string) (client.Client, *probe.Error) {
+		return store.New(urlStr)
+	}
+	_, err := store.AddIncompleteUpload("full/big.iso", 1024)
+	c.Assert(err, IsNil)
+
+	// An empty bucket is removed.
+	clnt, err := store.New("https://mock.example/empty")
+	c.Assert(err, IsNil)
+	c.Assert(removeBucket(clnt, newClient, "", false), IsNil)
+	_, err = clnt.Stat()
+	c.Assert(err, NotNil)
+
+	// A bucket with objects is refused without force, nothing is removed.
+	clnt, err = store.New("https://mock.example/full")
+	c.Assert(err, IsNil)
+	err = removeBucket(clnt, newClient, "", false)
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError(), Equals, client.BucketNotEmpty{Bucket: "full"})
+	_, ok := store.Object("full/dir/b.go")
+	c.Assert(ok, Equals, true)
+
+	// Force empties it of objects and incomplete uploads first.
+	c.Assert(removeBucket(clnt, newClient, "", true), IsNil)
+	_, ok = store.Object("full/a.txt")
+	c.Assert(ok, Equals, false)
+	_, err = clnt.Stat()
+	c.Assert(err, NotNil)
+}
+
+func (s *TestSuite) TestRemoveBucketFolder(c *C) {
+	isJSON, output := globalJSON, color.Output
+	defer func() { globalJSON, color.Output = isJSON, output }()
+	globalJSON = true
+	color.Output = new(bytes.Buffer)
+
+	root, e := ioutil.TempDir("", "mc-rb-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	folder := filepath.Join(root, "bucket")
+	c.Assert(os.MkdirAll(filepath.Join(folder, "dir", "sub"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(folder, "dir", "sub", "file"), []byte("x"), 0600), IsNil)
+
+	clnt, err := newClient(folder)
+	c.Assert(err, IsNil)
+	err = removeBucket(clnt, newClient, "", false)
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError(), Equals, client.BucketNotEmpty{Bucket: folder})
+
+	c.Assert(removeBucket(clnt, newClient, "", true), IsNil)
+	_, e = os.Stat(folder)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
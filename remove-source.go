@@ -0,0 +1,180 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// checkRemoveSourceSyntax - sources are removed only of whole copies, one each, ‘--prune-dirs’
+// only prunes what ‘--remove-source’ emptied.
+func checkRemoveSourceSyntax(ctx *cli.Context) {
+	if ctx.Bool("prune-dirs") && !ctx.Bool("remove-source") {
+		fatalIf(errInvalidArgument().Trace(), "Option --prune-dirs can only be used with --remove-source.")
+	}
+	if !ctx.Bool("remove-source") {
+		return
+	}
+	for _, option := range []string{"fan-out", "archive", "extract", "append"} {
+		if ctx.Bool(option) {
+			fatalIf(errInvalidArgument().Trace(), "Option --remove-source cannot be used with --"+option+".")
+		}
+	}
+	if ctx.IsSet("offset") || ctx.IsSet("length") {
+		fatalIf(errInvalidArgument().Trace(), "Option --remove-source cannot be used with --offset and --length.")
+	}
+}
+
+// isEncryptedURL - urlStr on the host of alias is encrypted with a key, its ETag is not the MD5 of its data.
+// A key which can not be looked up counts as encrypted.
+func isEncryptedURL(alias, urlStr string) bool {
+	hostCfg := mustGetHostConfig(alias)
+	if hostCfg == nil {
+		return false
+	}
+	encryptionKey, err := getURLEncryptionKey(alias, *hostCfg, urlStr)
+	return err != nil || encryptionKey != nil
+}
+
+// verifyTransfer - compare the object of targetClnt with that of sourceClnt it was copied from. Sizes always
+// have to match. Where one side is a local file it is compared with the MD5 ETag of the other, objects with
+// both MD5 ETags as by verifyCopy. Encrypted objects and multipart ETags are verified by size only.
+func verifyTransfer(sourceAlias string, sourceClnt client.Client, targetAlias string, targetClnt client.Client) *probe.Error {
+	sourceURL := sourceClnt.GetURL()
+	targetURL := targetClnt.GetURL()
+	source, err := sourceClnt.Stat()
+	if err != nil {
+		return err.Trace(sourceURL.String())
+	}
+	target, err := targetClnt.Stat()
+	if err != nil {
+		return err.Trace(targetURL.String())
+	}
+	if source.Size != target.Size {
+		return probe.NewError(client.ChecksumMismatch{URL: targetURL.String(), Algorithm: "size",
+			Expected: strconv.FormatInt(source.Size, 10), Actual: strconv.FormatInt(target.Size, 10)})
+	}
+	if isEncryptedURL(sourceAlias, sourceURL.String()) || isEncryptedURL(targetAlias, targetURL.String()) {
+		return nil
+	}
+	var expected, path string
+	switch {
+	case sourceURL.Type == client.Filesystem && targetURL.Type == client.Filesystem:
+		return nil
+	case sourceURL.Type == client.Filesystem && md5ETag.MatchString(target.ETag):
+		expected, path = strings.ToLower(target.ETag), sourceURL.Path
+	case targetURL.Type == client.Filesystem && md5ETag.MatchString(source.ETag):
+		expected, path = strings.ToLower(source.ETag), targetURL.Path
+	case md5ETag.MatchString(source.ETag) && md5ETag.MatchString(target.ETag) && !strings.EqualFold(source.ETag, target.ETag):
+		return probe.NewError(client.ChecksumMismatch{URL: targetURL.String(), Algorithm: "etag",
+			Expected: strings.ToLower(source.ETag), Actual: strings.ToLower(target.ETag)})
+	default:
+		return nil
+	}
+	actual, err := fileChecksum(path, "md5")
+	if err != nil {
+		return err.Trace(path)
+	}
+	if actual != expected {
+		return probe.NewError(client.ChecksumMismatch{URL: targetURL.String(), Algorithm: "md5", Expected: expected, Actual: actual})
+	}
+	return nil
+}
+
+// removeVerifiedSource - remove the source of a completed copy once verifyTransfer matched it with the
+// target. A source which does not match is kept. Decompressed copies were verified by gzip itself.
+func removeVerifiedSource(sourceAlias string, sourceClnt client.Client, targetAlias string, targetClnt client.Client, isVerified bool) *probe.Error {
+	if !isVerified {
+		if err := verifyTransfer(sourceAlias, sourceClnt, targetAlias, targetClnt); err != nil {
+			return err.Trace()
+		}
+	}
+	if err := sourceClnt.Remove(false, ""); err != nil {
+		return err.Trace(sourceClnt.GetURL().String())
+	}
+	return nil
+}
+
+// pruneEmptyDirs - remove the folders below root emptied by ‘--remove-source’, deepest first. Root itself
+// is kept, as are folders with anything left in them.
+func pruneEmptyDirs(root string) *probe.Error {
+	var dirs []string
+	e := filepath.Walk(root, func(path string, info os.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+		if info.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if e != nil {
+		return probe.NewError(e).Trace(root)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, e := ioutil.ReadDir(dirs[i])
+		if e != nil {
+			return probe.NewError(e).Trace(dirs[i])
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if e = os.Remove(dirs[i]); e != nil {
+			return probe.NewError(e).Trace(dirs[i])
+		}
+	}
+	return nil
+}
+
+// pruneSourceDirs - prune the local folders among the sources of a session with ‘--prune-dirs’.
+func pruneSourceDirs(session *sessionV6, sources []string) {
+	if !session.Header.CommandBoolFlags["prune-dirs"] {
+		return
+	}
+	for _, source := range sources {
+		_, urlStr, _ := mustExpandAlias(source)
+		sourceURL := client.NewURL(urlStr)
+		if sourceURL.Type != client.Filesystem {
+			continue
+		}
+		if info, e := os.Stat(sourceURL.Path); e != nil || !info.IsDir() {
+			continue
+		}
+		errorIf(pruneEmptyDirs(sourceURL.Path).Trace(source), "Unable to prune emptied folders of ‘"+source+"’.")
+	}
+}
+
+// removeMirroredSource - removeVerifiedSource for an object mirrored by sURLs.
+func removeMirroredSource(sURLs mirrorURLs) *probe.Error {
+	sourceClnt, err := newClientFromAlias(sURLs.SourceAlias, sURLs.SourceContent.URL.String())
+	if err != nil {
+		return err.Trace(sURLs.SourceContent.URL.String())
+	}
+	targetClnt, err := newClientFromAlias(sURLs.TargetAlias, sURLs.TargetContent.URL.String())
+	if err != nil {
+		return err.Trace(sURLs.TargetContent.URL.String())
+	}
+	return removeVerifiedSource(sURLs.SourceAlias, sourceClnt, sURLs.TargetAlias, targetClnt, false).Trace()
+}
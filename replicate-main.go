@@ -0,0 +1,342 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	replicateFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of replicate.",
+		},
+		cli.StringFlag{
+			Name:  "destination",
+			Usage: "ARN of the bucket objects are replicated to, e.g. arn:aws:s3:::backup.",
+		},
+		cli.StringFlag{
+			Name:  "prefix",
+			Usage: "Replicate only objects under this prefix, all objects by default.",
+		},
+		cli.IntFlag{
+			Name:  "priority",
+			Usage: "Priority of the rule, of all rules matching an object the highest applies. Defaults to above all others.",
+		},
+		cli.StringFlag{
+			Name:  "id",
+			Usage: "ID of the rule to add or remove, added rules are numbered by default.",
+		},
+		cli.StringFlag{
+			Name:  "role",
+			Usage: "ARN of the role objects are replicated as, kept from the existing rules if not set.",
+		},
+		cli.StringFlag{
+			Name:  "storage-class",
+			Usage: "Storage class of the replicas, that of the objects by default.",
+		},
+		cli.BoolFlag{
+			Name:  "delete-markers",
+			Usage: "Replicate delete markers as well.",
+		},
+		cli.BoolFlag{
+			Name:  "disable",
+			Usage: "Add the rule disabled.",
+		},
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "Remove all rules.",
+		},
+	}
+)
+
+// Manage bucket replication.
+var replicateCmd = cli.Command{
+	Name:   "replicate",
+	Usage:  "Manage bucket replication rules.",
+	Action: mainReplicate,
+	Flags:  append(replicateFlags, globalFlags...),
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] add --destination ARN TARGET
+   mc {{.Name}} [FLAGS] list TARGET
+   mc {{.Name}} [FLAGS] remove --id ID TARGET
+   mc {{.Name}} [FLAGS] remove --all TARGET
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Replicate all objects of a versioned bucket on Amazon S3 cloud storage to another bucket.
+      $ mc {{.Name}} add --role arn:aws:iam::123456789012:role/replication --destination arn:aws:s3:::backup s3/photos
+
+   2. Replicate logs to a cheaper storage class with a rule of its own, ahead of the other rules.
+      $ mc {{.Name}} add --id logs --prefix logs/ --priority 10 --storage-class STANDARD_IA --destination arn:aws:s3:::archive s3/photos
+
+   3. List replication rules of a bucket.
+      $ mc {{.Name}} list s3/photos
+
+   4. Remove a replication rule, and all of them.
+      $ mc {{.Name}} remove --id logs s3/photos
+      $ mc {{.Name}} remove --all s3/photos
+`,
+}
+
+// replicateMessage is container for replicate command on bucket success messages.
+type replicateMessage struct {
+	Operation string                   `json:"operation"`
+	Status    string                   `json:"status"`
+	Bucket    string                   `json:"bucket"`
+	ID        string                   `json:"id,omitempty"`
+	Role      string                   `json:"role,omitempty"`
+	Rules     []client.ReplicationRule `json:"rules,omitempty"`
+}
+
+// String colorized replicate message, rules are listed as a table.
+func (r replicateMessage) String() string {
+	switch r.Operation {
+	case "add":
+		return console.Colorize("Replicate", "Added replication rule ‘"+r.ID+"’ to ‘"+r.Bucket+"’.")
+	case "remove":
+		if r.ID == "" {
+			return console.Colorize("Replicate", "Removed all replication rules from ‘"+r.Bucket+"’.")
+		}
+		return console.Colorize("Replicate", "Removed replication rule ‘"+r.ID+"’ from ‘"+r.Bucket+"’.")
+	}
+	if len(r.Rules) == 0 {
+		return console.Colorize("Replicate", "Bucket ‘"+r.Bucket+"’ is not replicated.")
+	}
+	header := "Replication rules of ‘" + r.Bucket + "’"
+	if r.Role != "" {
+		header += " as ‘" + r.Role + "’"
+	}
+	lines := []string{console.Colorize("Replicate", header+":")}
+	rows := [][]string{{"ID", "PRIORITY", "STATUS", "PREFIX", "DESTINATION", "STORAGE CLASS", "DELETE MARKERS"}}
+	for _, rule := range r.Rules {
+		status, deleteMarkers := "Disabled", "Disabled"
+		if rule.Enabled {
+			status = "Enabled"
+		}
+		if rule.DeleteMarkers {
+			deleteMarkers = "Enabled"
+		}
+		prefix := rule.Prefix
+		if prefix == "" {
+			prefix = "*"
+		}
+		storageClass := rule.StorageClass
+		if storageClass == "" {
+			storageClass = "-"
+		}
+		rows = append(rows, []string{rule.ID, strconv.Itoa(rule.Priority), status, prefix, rule.Destination, storageClass, deleteMarkers})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, column := range row {
+			if len(column) > widths[i] {
+				widths[i] = len(column)
+			}
+		}
+	}
+	for _, row := range rows {
+		var line string
+		for i, column := range row {
+			line += fmt.Sprintf("%-*s  ", widths[i], column)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON jsonified replicate message.
+func (r replicateMessage) JSON() string {
+	replicateJSONBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(replicateJSONBytes)
+}
+
+// checkReplicateSyntax check for incoming syntax.
+func checkReplicateSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(ctx, "replicate", 1) // last argument is exit code.
+	}
+	switch ctx.Args().First() {
+	case "add":
+		if ctx.String("destination") == "" {
+			fatalIf(errInvalidArgument().Trace(), "Option --destination is required to add a replication rule.")
+		}
+		if ctx.IsSet("priority") && ctx.Int("priority") < 0 {
+			fatalIf(errInvalidArgument().Trace(), "Option --priority cannot be negative.")
+		}
+	case "remove":
+		if (ctx.String("id") == "") == !ctx.Bool("all") {
+			fatalIf(errInvalidArgument().Trace(), "Remove a rule by --id, or all rules with --all.")
+		}
+	case "list":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "replicate", 1) // last argument is exit code.
+	}
+}
+
+// newReplicationRuleID - first of "rule-1", "rule-2" and so on no rule has.
+func newReplicationRuleID(rules []client.ReplicationRule) string {
+	ids := make(map[string]bool)
+	for _, rule := range rules {
+		ids[rule.ID] = true
+	}
+	for i := 1; ; i++ {
+		if id := "rule-" + strconv.Itoa(i); !ids[id] {
+			return id
+		}
+	}
+}
+
+// nextReplicationPriority - priority above that of all rules.
+func nextReplicationPriority(rules []client.ReplicationRule) int {
+	priority := 1
+	for _, rule := range rules {
+		if rule.Priority >= priority {
+			priority = rule.Priority + 1
+		}
+	}
+	return priority
+}
+
+// doAddReplicationRule add rule to the bucket of targetURL, which must be versioned. Its ID and priority
+// are set if empty, the role replaces that of the existing rules if set. Returns the rule added.
+func doAddReplicationRule(targetURL string, rule client.ReplicationRule, isPrioritySet bool, role string) (client.ReplicationRule, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return rule, err.Trace(targetURL)
+	}
+	versioning, err := clnt.GetBucketVersioning()
+	if err != nil {
+		return rule, err.Trace(targetURL)
+	}
+	if versioning != "Enabled" {
+		return rule, errReplicationNeedsVersioning(targetURL).Trace(targetURL)
+	}
+	config, err := clnt.GetBucketReplication()
+	if err != nil {
+		return rule, err.Trace(targetURL)
+	}
+	if rule.ID == "" {
+		rule.ID = newReplicationRuleID(config.Rules)
+	}
+	if !isPrioritySet {
+		rule.Priority = nextReplicationPriority(config.Rules)
+	}
+	if role != "" {
+		config.Role = role
+	}
+	config.Rules = append(config.Rules, rule)
+	return rule, clnt.SetBucketReplication(config).Trace(targetURL)
+}
+
+// doListReplication get replication rules of the bucket of targetURL.
+func doListReplication(targetURL string) (client.ReplicationConfig, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return client.ReplicationConfig{}, err.Trace(targetURL)
+	}
+	config, err := clnt.GetBucketReplication()
+	if err != nil {
+		return client.ReplicationConfig{}, err.Trace(targetURL)
+	}
+	return config, nil
+}
+
+// doRemoveReplicationRule remove the rule id from the bucket of targetURL, an empty id removes all rules.
+func doRemoveReplicationRule(targetURL, id string) *probe.Error {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	config := client.ReplicationConfig{}
+	if id != "" {
+		if config, err = clnt.GetBucketReplication(); err != nil {
+			return err.Trace(targetURL)
+		}
+		var rules []client.ReplicationRule
+		for _, rule := range config.Rules {
+			if rule.ID != id {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == len(config.Rules) {
+			return errReplicationRuleNotFound(targetURL, id).Trace(targetURL)
+		}
+		config.Rules = rules
+	}
+	return clnt.SetBucketReplication(config).Trace(targetURL)
+}
+
+func mainReplicate(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'replicate' cli arguments.
+	checkReplicateSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Replicate", color.New(color.FgGreen, color.Bold))
+
+	operation := ctx.Args().First()
+	targetURL := ctx.Args().Get(1)
+	msg := replicateMessage{Status: "success", Operation: operation, Bucket: targetURL}
+	switch operation {
+	case "add":
+		rule := client.ReplicationRule{
+			ID:            ctx.String("id"),
+			Priority:      ctx.Int("priority"),
+			Prefix:        ctx.String("prefix"),
+			Destination:   ctx.String("destination"),
+			StorageClass:  ctx.String("storage-class"),
+			Enabled:       !ctx.Bool("disable"),
+			DeleteMarkers: ctx.Bool("delete-markers"),
+		}
+		rule, err := doAddReplicationRule(targetURL, rule, ctx.IsSet("priority"), ctx.String("role"))
+		fatalIf(err.Trace(targetURL), "Unable to add replication rule to ‘"+targetURL+"’.")
+		msg.ID = rule.ID
+	case "remove":
+		err := doRemoveReplicationRule(targetURL, ctx.String("id"))
+		fatalIf(err.Trace(targetURL), "Unable to remove replication rules from ‘"+targetURL+"’.")
+		msg.ID = ctx.String("id")
+	case "list":
+		config, err := doListReplication(targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to get replication rules of ‘"+targetURL+"’.")
+		msg.Role = config.Role
+		msg.Rules = config.Rules
+	}
+	if globalDryRun && operation != "list" { // Already printed by the dry run client.
+		return
+	}
+	printMsg(msg)
+}
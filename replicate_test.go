@@ -0,0 +1,113 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+// replicationHandler is an http.Handler storing the replication configuration of a bucket, whose
+// versioning status is versioning.
+type replicationHandler struct {
+	versioning string
+
+	mutex  sync.Mutex
+	config []byte
+}
+
+func (h *replicationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	query := r.URL.Query()
+	if _, ok := query["versioning"]; ok {
+		w.Write([]byte(`<VersioningConfiguration><Status>` + h.versioning + `</Status></VersioningConfiguration>`))
+		return
+	}
+	if _, ok := query["replication"]; !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		h.config, _ = ioutil.ReadAll(r.Body)
+	case "DELETE":
+		h.config = nil
+		w.WriteHeader(http.StatusNoContent)
+	case "GET":
+		if h.config == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<Error><Code>ReplicationConfigurationNotFoundError</Code><Message>The replication configuration was not found</Message></Error>"))
+			return
+		}
+		w.Write(h.config)
+	}
+}
+
+func (s *TestSuite) TestReplicate(c *C) {
+	defer useTempMcConfig(c)()
+	handler := &replicationHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("replicate", hostConfigV7{URL: server.URL, API: "S3v4"}), IsNil)
+	role := "arn:aws:iam::123456789012:role/replication"
+
+	// Replication needs a versioned bucket.
+	_, err := doAddReplicationRule("replicate/photos", client.ReplicationRule{Destination: "arn:aws:s3:::backup", Enabled: true}, false, role)
+	c.Assert(err, Not(IsNil))
+	c.Assert(handler.config, IsNil)
+
+	handler.versioning = "Enabled"
+	rule, err := doAddReplicationRule("replicate/photos", client.ReplicationRule{Destination: "arn:aws:s3:::backup", Enabled: true}, false, role)
+	c.Assert(err, IsNil)
+	c.Assert(rule.ID, Equals, "rule-1")
+	c.Assert(rule.Priority, Equals, 1)
+	logs := client.ReplicationRule{ID: "logs", Prefix: "logs/", Destination: "arn:aws:s3:::archive",
+		StorageClass: "STANDARD_IA", DeleteMarkers: true}
+	rule, err = doAddReplicationRule("replicate/photos", logs, false, "")
+	c.Assert(err, IsNil)
+	c.Assert(rule.Priority, Equals, 2)
+	// IDs are unique.
+	_, err = doAddReplicationRule("replicate/photos", logs, true, "")
+	c.Assert(err, Not(IsNil))
+
+	config, err := doListReplication("replicate/photos")
+	c.Assert(err, IsNil)
+	c.Assert(config.Role, Equals, role)
+	c.Assert(config.Rules, DeepEquals, []client.ReplicationRule{
+		{ID: "rule-1", Priority: 1, Destination: "arn:aws:s3:::backup", Enabled: true},
+		{ID: "logs", Priority: 2, Prefix: "logs/", Destination: "arn:aws:s3:::archive", StorageClass: "STANDARD_IA", DeleteMarkers: true},
+	})
+
+	c.Assert(doRemoveReplicationRule("replicate/photos", "missing"), Not(IsNil))
+	c.Assert(doRemoveReplicationRule("replicate/photos", "rule-1"), IsNil)
+	config, err = doListReplication("replicate/photos")
+	c.Assert(err, IsNil)
+	c.Assert(config.Rules, HasLen, 1)
+	c.Assert(config.Rules[0].ID, Equals, "logs")
+
+	c.Assert(doRemoveReplicationRule("replicate/photos", ""), IsNil)
+	c.Assert(handler.config, IsNil)
+	config, err = doListReplication("replicate/photos")
+	c.Assert(err, IsNil)
+	c.Assert(config.Rules, HasLen, 0)
+}
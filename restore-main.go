@@ -0,0 +1,275 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	restoreFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of restore.",
+		},
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "Restore all archived objects under the prefix.",
+		},
+		cli.IntFlag{
+			Name:  "days",
+			Value: 1,
+			Usage: "Number of days the restored copy is kept.",
+		},
+		cli.StringFlag{
+			Name:  "tier",
+			Value: "standard",
+			Usage: "How fast objects are restored: expedited, standard or bulk. Faster tiers cost more.",
+		},
+		cli.BoolFlag{
+			Name:  "status",
+			Usage: "Show the status of restores instead of initiating them.",
+		},
+	}
+)
+
+// Restore archived objects.
+var restoreCmd = cli.Command{
+	Name:   "restore",
+	Usage:  "Restore objects archived by Amazon Glacier storage classes.",
+	Action: mainRestore,
+	Flags:  append(restoreFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET [TARGET...]
+
+   Objects of storage class GLACIER or DEEP_ARCHIVE are read only once restored, a copy of them is
+   made available for --days. Restores take minutes to hours depending on the tier.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Restore an archived object on Amazon S3 for a week.
+      $ mc {{.Name}} --days 7 s3/archive/2015/ledger.csv
+
+   2. Restore all archived objects under a prefix at the lowest cost.
+      $ mc {{.Name}} --recursive --tier bulk s3/archive/2015/
+
+   3. Check whether the restore of an object completed.
+      $ mc {{.Name}} --status s3/archive/2015/ledger.csv
+`,
+}
+
+// Restore states of archived objects.
+const (
+	restoreNotRequested = "not restored"
+	restoreInProgress   = "in progress"
+	restoreCompleted    = "restored"
+	restoreInitiated    = "initiated"
+)
+
+// restoreMessage is container for restore command success messages.
+type restoreMessage struct {
+	Status       string     `json:"status"`
+	URL          string     `json:"url"`
+	StorageClass string     `json:"storageClass,omitempty"`
+	Restore      string     `json:"restore"`
+	Tier         string     `json:"tier,omitempty"`
+	Days         int        `json:"days,omitempty"`
+	ExpiryDate   *time.Time `json:"expiryDate,omitempty"`
+}
+
+// String colorized restore message.
+func (r restoreMessage) String() string {
+	switch r.Restore {
+	case restoreInitiated:
+		return console.Colorize("Restore", "Restore of ‘"+r.URL+"’ initiated, "+r.Tier+" tier for "+pluralDays(r.Days)+".")
+	case restoreInProgress:
+		return console.Colorize("Restore", "Restore of ‘"+r.URL+"’ is in progress.")
+	case restoreCompleted:
+		return console.Colorize("Restore", "‘"+r.URL+"’ is restored until ‘"+r.ExpiryDate.Format(printDate)+"’.")
+	}
+	return console.Colorize("Restore", "‘"+r.URL+"’ of storage class ‘"+r.StorageClass+"’ is not restored.")
+}
+
+// JSON jsonified restore message.
+func (r restoreMessage) JSON() string {
+	restoreJSONBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(restoreJSONBytes)
+}
+
+// pluralDays - number of days in words.
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return strconv.Itoa(days) + " days"
+}
+
+// parseRestoreTier - parse a restore tier, case insensitive.
+func parseRestoreTier(tier string) (string, *probe.Error) {
+	switch strings.ToLower(tier) {
+	case "expedited":
+		return "Expedited", nil
+	case "standard":
+		return "Standard", nil
+	case "bulk":
+		return "Bulk", nil
+	}
+	return "", errInvalidArgument().Trace(tier)
+}
+
+// isArchiveStorageClass - objects of the storage class need a restore before they are read.
+func isArchiveStorageClass(storageClass string) bool {
+	return storageClass == "GLACIER" || storageClass == "DEEP_ARCHIVE"
+}
+
+// restoreState - restore state of an archived object as stat'ed.
+func restoreState(st *client.Content) string {
+	switch {
+	case !st.RestoreRequested:
+		return restoreNotRequested
+	case st.RestoreOngoing:
+		return restoreInProgress
+	}
+	return restoreCompleted
+}
+
+// checkRestoreSyntax check for incoming syntax.
+func checkRestoreSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "restore", 1) // last argument is exit code.
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+	if ctx.Int("days") < 1 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("days")), "Restored objects must be kept at least one day.")
+	}
+	if _, err := parseRestoreTier(ctx.String("tier")); err != nil {
+		fatalIf(err.Trace(), "Restore tier ‘"+ctx.String("tier")+"’ is not one of expedited, standard or bulk.")
+	}
+	if ctx.Bool("status") && (ctx.IsSet("days") || ctx.IsSet("tier")) {
+		fatalIf(errInvalidArgument().Trace(), "Options --days and --tier cannot be used with --status.")
+	}
+}
+
+// doRestore - restore the archived object of clnt at url, or show its restore status if isStatus.
+// Objects not archived are refused, restores already in progress are reported as such.
+func doRestore(clnt client.Client, url string, isStatus bool, days int, tier string) *probe.Error {
+	st, err := clnt.Stat()
+	if err != nil {
+		return err.Trace(url)
+	}
+	if !isArchiveStorageClass(st.StorageClass) {
+		return probe.NewError(client.NotArchived{URL: url, StorageClass: st.StorageClass})
+	}
+	msg := restoreMessage{Status: "success", URL: url, StorageClass: st.StorageClass, Restore: restoreState(st)}
+	if msg.Restore == restoreCompleted {
+		expiryDate := st.RestoreExpiryDate.Local()
+		msg.ExpiryDate = &expiryDate
+	}
+	if !isStatus {
+		err = clnt.RestoreArchived(days, tier)
+		switch {
+		case err == nil:
+			if globalDryRun { // Already printed by the dry run client.
+				return nil
+			}
+			msg.Restore, msg.Tier, msg.Days, msg.ExpiryDate = restoreInitiated, tier, days, nil
+		case isRestoreInProgress(err):
+			msg.Restore, msg.ExpiryDate = restoreInProgress, nil
+		default:
+			return err.Trace(url)
+		}
+	}
+	printMsg(msg)
+	return nil
+}
+
+// isRestoreInProgress - err tells a restore is already in progress.
+func isRestoreInProgress(err *probe.Error) bool {
+	_, ok := err.ToGoError().(client.RestoreInProgress)
+	return ok
+}
+
+// mainRestore - main handler for mc restore command.
+func mainRestore(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'restore' cli arguments.
+	checkRestoreSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Restore", color.New(color.FgGreen, color.Bold))
+
+	isRecursive := ctx.Bool("recursive")
+	isStatus := ctx.Bool("status")
+	days := ctx.Int("days")
+	tier, _ := parseRestoreTier(ctx.String("tier"))
+
+	for _, targetURL := range ctx.Args() {
+		targetAlias, expandedURL, _ := mustExpandAlias(targetURL)
+		clnt, err := newClientFromAlias(targetAlias, expandedURL)
+		if err != nil {
+			errorIf(err.Trace(targetURL), "Invalid URL ‘"+targetURL+"’.")
+			continue
+		}
+		if !isRecursive {
+			errorIf(doRestore(clnt, targetURL, isStatus, days, tier), "Unable to restore ‘"+targetURL+"’.")
+			continue
+		}
+		doneCh := make(chan struct{})
+		for content := range clnt.List(true, false, doneCh) {
+			if content.Err != nil {
+				errorIf(content.Err.Trace(targetURL), "Unable to list ‘"+targetURL+"’.")
+				break
+			}
+			// Objects not archived are skipped, listings tell storage classes of objects.
+			if content.Type.IsDir() || (content.StorageClass != "" && !isArchiveStorageClass(content.StorageClass)) {
+				continue
+			}
+			objectURL := content.URL.String()
+			objectClnt, err := newClientFromAlias(targetAlias, objectURL)
+			if err != nil {
+				errorIf(err.Trace(objectURL), "Invalid URL ‘"+objectURL+"’.")
+				continue
+			}
+			url := filepath.Join(targetAlias, content.URL.Path)
+			errorIf(doRestore(objectClnt, url, isStatus, days, tier), "Unable to restore ‘"+url+"’.")
+		}
+		close(doneCh)
+	}
+}
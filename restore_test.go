@@ -0,0 +1,137 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	. "gopkg.in/check.v1"
+)
+
+// archiveHandler is an http.Handler serving objects of the storage classes in classes, archived
+// objects are restored once requested, restores of the same object in between fail as in progress.
+type archiveHandler struct {
+	classes map[string]string
+
+	mutex    sync.Mutex
+	restores map[string]string // x-amz-restore of objects whose restore was requested.
+	requests []string          // restore request bodies.
+}
+
+func (h *archiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	class, ok := h.classes[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if _, isRestore := r.URL.Query()["restore"]; isRestore && r.Method == "POST" {
+		var request struct {
+			Days                 int
+			GlacierJobParameters struct{ Tier string }
+		}
+		if e := xml.NewDecoder(r.Body).Decode(&request); e != nil || r.Header.Get("Content-MD5") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, requested := h.restores[r.URL.Path]; requested {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("<Error><Code>RestoreAlreadyInProgress</Code><Message>Object restore is already in progress</Message></Error>"))
+			return
+		}
+		h.restores[r.URL.Path] = `ongoing-request="true"`
+		h.requests = append(h.requests, request.GlacierJobParameters.Tier)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+	w.Header().Set("Content-Length", "0")
+	w.Header().Set("x-amz-storage-class", class)
+	if restore, ok := h.restores[r.URL.Path]; ok {
+		w.Header().Set("x-amz-restore", restore)
+	}
+}
+
+func (s *TestSuite) TestRestore(c *C) {
+	handler := &archiveHandler{
+		classes: map[string]string{
+			"/archive/ledger.csv": "GLACIER",
+			"/archive/recent.csv": "STANDARD_IA",
+		},
+		restores: make(map[string]string),
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	newS3Client := func(object string) client.Client {
+		conf := new(client.Config)
+		conf.HostURL = server.URL + object
+		clnt, err := s3.New(conf)
+		c.Assert(err, IsNil)
+		return clnt
+	}
+
+	var buffer bytes.Buffer
+	savedOutput, savedJSON := color.Output, globalJSON
+	color.Output, globalJSON = &buffer, true
+	defer func() { color.Output, globalJSON = savedOutput, savedJSON }()
+	restore := func(object string, isStatus bool) restoreMessage {
+		buffer.Reset()
+		c.Assert(doRestore(newS3Client(object), object, isStatus, 2, "Bulk"), IsNil)
+		var msg restoreMessage
+		c.Assert(json.Unmarshal([]byte(strings.TrimSpace(buffer.String())), &msg), IsNil)
+		return msg
+	}
+
+	c.Assert(restore("/archive/ledger.csv", true).Restore, Equals, restoreNotRequested)
+	msg := restore("/archive/ledger.csv", false)
+	c.Assert(msg.Restore, Equals, restoreInitiated)
+	c.Assert(msg.Days, Equals, 2)
+	c.Assert(handler.requests, DeepEquals, []string{"Bulk"})
+	// Restores already in progress are no failures.
+	c.Assert(restore("/archive/ledger.csv", false).Restore, Equals, restoreInProgress)
+	c.Assert(restore("/archive/ledger.csv", true).Restore, Equals, restoreInProgress)
+	c.Assert(handler.requests, HasLen, 1)
+
+	handler.restores["/archive/ledger.csv"] = `ongoing-request="false", expiry-date="Fri, 23 Oct 2015 00:00:00 GMT"`
+	msg = restore("/archive/ledger.csv", true)
+	c.Assert(msg.Restore, Equals, restoreCompleted)
+	c.Assert(msg.ExpiryDate.UTC().Format("2006-01-02"), Equals, "2015-10-23")
+
+	// Objects not archived are refused.
+	err := doRestore(newS3Client("/archive/recent.csv"), "/archive/recent.csv", false, 2, "Bulk")
+	c.Assert(err, Not(IsNil))
+	_, ok := err.ToGoError().(client.NotArchived)
+	c.Assert(ok, Equals, true)
+	c.Assert(handler.requests, HasLen, 1)
+
+	tier, err := parseRestoreTier("EXPEDITED")
+	c.Assert(err, IsNil)
+	c.Assert(tier, Equals, "Expedited")
+	_, err = parseRestoreTier("fast")
+	c.Assert(err, Not(IsNil))
+}
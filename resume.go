@@ -0,0 +1,159 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// partialDownload - a download interrupted part way, recorded in the session so that
+// a resume only appends to the part file if the object has not changed meanwhile.
+type partialDownload struct {
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+}
+
+// isResumableDownload - only downloads from object storage to the filesystem resume.
+func isResumableDownload(sourceClnt, targetClnt client.Client) bool {
+	return sourceClnt.GetURL().Type == client.Object && targetClnt.GetURL().Type == client.Filesystem
+}
+
+// prepareDownload - offset the download of source to target path continues from.
+// What an earlier attempt wrote is kept only if the session recorded the same ETag
+// and size for source, otherwise it is discarded and the download starts over.
+func prepareDownload(session *sessionV6, source *client.Content, target string) (int64, *probe.Error) {
+	offset := fs.PartialSize(target)
+	if offset > 0 {
+		partial, ok := session.getPartialDownload(target)
+		if !ok || partial.ETag != source.ETag || partial.Size != source.Size || offset > source.Size {
+			if err := fs.RemovePartial(target); err != nil {
+				return 0, err.Trace(target)
+			}
+			offset = 0
+		}
+	}
+	session.setPartialDownload(target, partialDownload{ETag: source.ETag, Size: source.Size, Offset: offset})
+	return offset, nil
+}
+
+// prepareAppend - with ‘--append’ the download of source continues from the end of a file present at target.
+// The object changed if it was modified after the file was last written, the download starts over then
+// unless ‘--force-append’ is set. A file as large as the object is complete, one larger fails.
+func prepareAppend(session *sessionV6, source *client.Content, target string) *probe.Error {
+	st, e := os.Stat(target)
+	// Nothing to append to, or a download interrupted part way continues instead.
+	if e != nil || !st.Mode().IsRegular() || fs.PartialSize(target) > 0 {
+		return nil
+	}
+	if source.Time.After(st.ModTime()) && !session.Header.CommandBoolFlags["force-append"] {
+		return nil
+	}
+	switch {
+	case st.Size() > source.Size:
+		return errAppendTooLarge(target, st.Size(), source.Size).Trace(target)
+	case st.Size() == source.Size:
+		return probe.NewError(client.NotModified{URL: target})
+	}
+	if err := fs.MakePartial(target); err != nil {
+		return err.Trace(target)
+	}
+	session.setPartialDownload(target, partialDownload{ETag: source.ETag, Size: source.Size, Offset: st.Size()})
+	return nil
+}
+
+// getResumableSource - reader of source continuing from where an earlier download to
+// target stopped. Anything other than a download reads from the beginning.
+func getResumableSource(session *sessionV6, sourceClnt, targetClnt client.Client) (io.ReadSeeker, *probe.Error) {
+	if session == nil || !isResumableDownload(sourceClnt, targetClnt) {
+		return sourceClnt.Get(0, 0, "")
+	}
+	source, err := sourceClnt.Stat()
+	if err != nil {
+		return nil, err.Trace(sourceClnt.GetURL().String())
+	}
+	target := targetClnt.GetURL().Path
+	if session.Header.CommandBoolFlags["append"] {
+		if err = prepareAppend(session, source, target); err != nil {
+			return nil, err.Trace(sourceClnt.GetURL().String())
+		}
+	}
+	offset, err := prepareDownload(session, source, target)
+	if err != nil {
+		return nil, err.Trace(sourceClnt.GetURL().String())
+	}
+	// With ‘--if-changed’ a file present, not part way downloaded, is downloaded again only if the object changed.
+	if offset == 0 && session.Header.CommandBoolFlags["if-changed"] {
+		if etag, modTime, ok := localCopyVersion(target, source); ok {
+			reader, err := sourceClnt.GetIfChanged(etag, modTime)
+			if err != nil {
+				session.removePartialDownload(target)
+				return nil, err.Trace(sourceClnt.GetURL().String())
+			}
+			return reader, nil
+		}
+	}
+	return sourceClnt.Get(offset, 0, "")
+}
+
+// localCopyVersion - ETag and modification time of the file at target, to download source again only if
+// it changed. The MD5 of the file is its ETag only if source has a single PUT ETag, a multipart ETag can never
+// match. False if there is no file at target.
+func localCopyVersion(target string, source *client.Content) (etag string, modTime time.Time, ok bool) {
+	st, e := os.Stat(target)
+	if e != nil || !st.Mode().IsRegular() {
+		return "", time.Time{}, false
+	}
+	if md5ETag.MatchString(source.ETag) {
+		if sum, err := fileChecksum(target, "md5"); err == nil {
+			etag = sum
+		}
+	}
+	return etag, st.ModTime(), true
+}
+
+// finishDownload - forget a completed download, or record how far a failed one got.
+func finishDownload(session *sessionV6, targetClnt client.Client, completed bool) {
+	target := targetClnt.GetURL().Path
+	partial, ok := session.getPartialDownload(target)
+	if !ok {
+		return
+	}
+	if completed {
+		session.removePartialDownload(target)
+		return
+	}
+	partial.Offset = fs.PartialSize(target)
+	session.setPartialDownload(target, partial)
+}
+
+// syncPartialDownloads - record how far each download got, before the session is saved for resume.
+func syncPartialDownloads(session *sessionV6) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	for target, partial := range session.Header.PartialDownloads {
+		partial.Offset = fs.PartialSize(target)
+		session.Header.PartialDownloads[target] = partial
+	}
+}
@@ -0,0 +1,300 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	retentionFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of retention.",
+		},
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "Apply to all objects below the prefix.",
+		},
+		cli.BoolFlag{
+			Name:  "bypass-governance",
+			Usage: "Allow shortening or removing a GOVERNANCE retention.",
+		},
+	}
+)
+
+// Manage object retention and legal hold.
+var retentionCmd = cli.Command{
+	Name:   "retention",
+	Usage:  "Manage retention and legal hold of locked objects.",
+	Action: mainRetention,
+	Flags:  append(retentionFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] set TARGET MODE DATE
+   mc {{.Name}} [FLAGS] legalhold TARGET on|off
+   mc {{.Name}} [FLAGS] info TARGET [TARGET...]
+
+   MODE is one of ‘governance’ or ‘compliance’, DATE is either YYYY-MM-DD or RFC3339 and must be in the future.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Retain an object on Amazon S3 cloud storage in compliance mode until the end of 2016.
+      $ mc {{.Name}} set s3/records/2015/ledger.csv compliance 2016-12-31
+
+   2. Retain all objects below a prefix in governance mode.
+      $ mc {{.Name}} --recursive set s3/records/2015/ governance 2016-06-30T12:00:00Z
+
+   3. Shorten a governance retention of an object.
+      $ mc {{.Name}} --bypass-governance set s3/records/2015/ledger.csv governance 2016-01-31
+
+   4. Place a legal hold on all objects below a prefix.
+      $ mc {{.Name}} --recursive legalhold s3/records/2015/ on
+
+   5. Display retention and legal hold of an object as JSON.
+      $ mc --json {{.Name}} info s3/records/2015/ledger.csv
+`,
+}
+
+// retentionMessage is container for retention command success messages.
+type retentionMessage struct {
+	Operation   string `json:"operation"`
+	Status      string `json:"status"`
+	URL         string `json:"url"`
+	Mode        string `json:"mode,omitempty"`
+	RetainUntil string `json:"retainUntil,omitempty"`
+	LegalHold   string `json:"legalHold,omitempty"`
+}
+
+// String colorized retention message.
+func (r retentionMessage) String() string {
+	switch r.Operation {
+	case "set":
+		return console.Colorize("Retention", "Retention ‘"+r.Mode+"’ until ‘"+r.RetainUntil+"’ set for ‘"+r.URL+"’.")
+	case "legalhold":
+		return console.Colorize("Retention", "Legal hold ‘"+r.LegalHold+"’ set for ‘"+r.URL+"’.")
+	}
+	message := console.Colorize("Retention", "‘"+r.URL+"’: ")
+	if r.Mode == "" {
+		message += "no retention"
+	} else {
+		message += "retention ‘" + r.Mode + "’ until ‘" + r.RetainUntil + "’"
+	}
+	return message + ", legal hold ‘" + r.LegalHold + "’."
+}
+
+// JSON jsonified retention message.
+func (r retentionMessage) JSON() string {
+	retentionJSONBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(retentionJSONBytes)
+}
+
+// parseRetentionMode - parse a retention mode, case insensitive.
+func parseRetentionMode(mode string) (string, *probe.Error) {
+	switch strings.ToUpper(mode) {
+	case "GOVERNANCE", "COMPLIANCE":
+		return strings.ToUpper(mode), nil
+	}
+	return "", errInvalidRetention(mode, "mode should be one of ‘governance’ or ‘compliance’").Trace(mode)
+}
+
+// parseRetainUntil - parse a retain until date, YYYY-MM-DD is midnight UTC. The date must be after now.
+func parseRetainUntil(date string, now time.Time) (time.Time, *probe.Error) {
+	retainUntil, e := time.Parse(time.RFC3339, date)
+	if e != nil {
+		retainUntil, e = time.Parse("2006-01-02", date)
+		if e != nil {
+			return time.Time{}, errInvalidRetention(date, "date should be of the form YYYY-MM-DD or RFC3339").Trace(date)
+		}
+	}
+	if !retainUntil.After(now) {
+		return time.Time{}, errInvalidRetention(date, "date must be in the future").Trace(date)
+	}
+	return retainUntil.UTC(), nil
+}
+
+// parseLegalHold - parse legal hold state, either on or off.
+func parseLegalHold(state string) (bool, *probe.Error) {
+	switch strings.ToLower(state) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, errInvalidRetention(state, "legal hold should be one of ‘on’ or ‘off’").Trace(state)
+}
+
+// checkRetentionSyntax check for incoming syntax.
+func checkRetentionSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "retention", 1) // last argument is exit code.
+	}
+	args := ctx.Args().Tail()
+	switch ctx.Args().First() {
+	case "set":
+		if len(args) != 3 {
+			cli.ShowCommandHelpAndExit(ctx, "retention", 1) // last argument is exit code.
+		}
+		_, err := parseRetentionMode(args[1])
+		fatalIf(err.Trace(args...), "Unable to parse retention mode.")
+		_, err = parseRetainUntil(args[2], time.Now())
+		fatalIf(err.Trace(args...), "Unable to parse retention date.")
+	case "legalhold":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "retention", 1) // last argument is exit code.
+		}
+		_, err := parseLegalHold(args[1])
+		fatalIf(err.Trace(args...), "Unable to parse legal hold.")
+	case "info":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "retention", 1) // last argument is exit code.
+	}
+	if ctx.Bool("bypass-governance") && ctx.Args().First() != "set" {
+		fatalIf(errInvalidArgument().Trace(), "Option --bypass-governance can only be used with ‘set’.")
+	}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+// retentionActions - user facing action of each operation.
+var retentionActions = map[string]string{
+	"set":       "set retention",
+	"legalhold": "set legal hold",
+	"info":      "get retention",
+}
+
+// doRetention calls apply with a client for targetURL, or for every object below targetURL
+// if isRecursive, and prints the message filled in by apply. Failures are reported and skipped.
+func doRetention(targetURL string, isRecursive bool, operation string, apply func(clnt client.Client, msg *retentionMessage) *probe.Error) {
+	targetAlias, expandedURL, _ := mustExpandAlias(targetURL)
+	clnt, err := newClientFromAlias(targetAlias, expandedURL)
+	if err != nil {
+		errorIf(err.Trace(targetURL), "Invalid URL ‘"+targetURL+"’.")
+		return
+	}
+	applyAndPrint := func(clnt client.Client, url string) {
+		msg := retentionMessage{Status: "success", Operation: operation, URL: url}
+		if err := apply(clnt, &msg); err != nil {
+			errorIf(err.Trace(url), "Unable to "+retentionActions[operation]+" for ‘"+url+"’.")
+			return
+		}
+		if globalDryRun && operation != "info" { // Already printed by the dry run client.
+			return
+		}
+		printMsg(msg)
+	}
+	if !isRecursive {
+		applyAndPrint(clnt, targetURL)
+		return
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for content := range clnt.List(true, false, doneCh) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(targetURL), "Unable to list ‘"+targetURL+"’.")
+			return
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		objectURL := content.URL.String()
+		objectClnt, err := newClientFromAlias(targetAlias, objectURL)
+		if err != nil {
+			errorIf(err.Trace(objectURL), "Invalid URL ‘"+objectURL+"’.")
+			continue
+		}
+		applyAndPrint(objectClnt, filepath.Join(targetAlias, content.URL.Path))
+	}
+}
+
+// mainRetention - main handler for mc retention command.
+func mainRetention(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'retention' cli arguments.
+	checkRetentionSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Retention", color.New(color.FgGreen, color.Bold))
+
+	isRecursive := ctx.Bool("recursive")
+	args := ctx.Args().Tail()
+	switch ctx.Args().First() {
+	case "set":
+		mode, _ := parseRetentionMode(args[1])
+		retainUntil, _ := parseRetainUntil(args[2], time.Now())
+		isBypass := ctx.Bool("bypass-governance")
+		doRetention(args[0], isRecursive, "set", func(clnt client.Client, msg *retentionMessage) *probe.Error {
+			msg.Mode = mode
+			msg.RetainUntil = retainUntil.Format(time.RFC3339)
+			return clnt.SetRetention(mode, retainUntil, isBypass)
+		})
+	case "legalhold":
+		enabled, _ := parseLegalHold(args[1])
+		doRetention(args[0], isRecursive, "legalhold", func(clnt client.Client, msg *retentionMessage) *probe.Error {
+			msg.LegalHold = legalHoldState(enabled)
+			return clnt.SetLegalHold(enabled)
+		})
+	case "info":
+		for _, targetURL := range args {
+			doRetention(targetURL, isRecursive, "info", func(clnt client.Client, msg *retentionMessage) *probe.Error {
+				mode, retainUntil, err := clnt.GetRetention()
+				if err != nil {
+					return err.Trace()
+				}
+				enabled, err := clnt.GetLegalHold()
+				if err != nil {
+					return err.Trace()
+				}
+				msg.Mode = mode
+				if mode != "" {
+					msg.RetainUntil = retainUntil.UTC().Format(time.RFC3339)
+				}
+				msg.LegalHold = legalHoldState(enabled)
+				return nil
+			})
+		}
+	}
+}
+
+// legalHoldState - user facing legal hold state.
+func legalHoldState(enabled bool) string {
+	if enabled {
+		return "ON"
+	}
+	return "OFF"
+}
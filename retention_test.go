@@ -0,0 +1,66 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseRetainUntil(c *C) {
+	now := time.Date(2015, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	retainUntil, err := parseRetainUntil("2016-12-31", now)
+	c.Assert(err, IsNil)
+	c.Assert(retainUntil, Equals, time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	retainUntil, err = parseRetainUntil("2015-11-20T14:00:00+02:00", now)
+	c.Assert(err, IsNil)
+	c.Assert(retainUntil, Equals, time.Date(2015, 11, 20, 12, 0, 0, 0, time.UTC))
+
+	// Not in the future.
+	for _, date := range []string{"2015-11-20", "2015-11-20T12:00:00+02:00", "2014-01-01"} {
+		_, err = parseRetainUntil(date, now)
+		c.Assert(err, Not(IsNil))
+	}
+	// Malformed.
+	for _, date := range []string{"", "tomorrow", "31-12-2016", "2016-12-31 00:00:00"} {
+		_, err = parseRetainUntil(date, now)
+		c.Assert(err, Not(IsNil))
+	}
+}
+
+func (s *TestSuite) TestParseRetentionMode(c *C) {
+	mode, err := parseRetentionMode("governance")
+	c.Assert(err, IsNil)
+	c.Assert(mode, Equals, "GOVERNANCE")
+	mode, err = parseRetentionMode("Compliance")
+	c.Assert(err, IsNil)
+	c.Assert(mode, Equals, "COMPLIANCE")
+	_, err = parseRetentionMode("legal")
+	c.Assert(err, Not(IsNil))
+
+	enabled, err := parseLegalHold("ON")
+	c.Assert(err, IsNil)
+	c.Assert(enabled, Equals, true)
+	enabled, err = parseLegalHold("off")
+	c.Assert(err, IsNil)
+	c.Assert(enabled, Equals, false)
+	_, err = parseLegalHold("yes")
+	c.Assert(err, Not(IsNil))
+}
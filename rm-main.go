@@ -17,13 +17,20 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 )
@@ -47,9 +54,21 @@ var (
 			Name:  "incomplete, I",
 			Usage: "Remove an incomplete upload(s).",
 		},
+		cli.StringFlag{
+			Name:  "version-id",
+			Usage: "Permanently remove a specific version of an object.",
+		},
+		cli.BoolFlag{
+			Name:  "versions",
+			Usage: "Permanently remove non-current versions and delete markers, current versions are kept.",
+		},
 		cli.BoolFlag{
-			Name:  "fake",
-			Usage: "Perform a fake remove operation.",
+			Name:  "include-current",
+			Usage: "Remove current versions too with --versions, objects may be removed for good.",
+		},
+		cli.StringFlag{
+			Name:  "older-than",
+			Usage: "Remove only incomplete uploads started, or versions modified, longer ago than this, such as 168h or 90d.",
 		},
 	}
 )
@@ -87,17 +106,61 @@ EXAMPLES:
 
    6. Drop all incomplete uploads recursively matching this prefix.
       $ mc {{.Name}} --incomplete --force --recursive s3/jazz-songs/louis/
+
+   7. Permanently remove a specific version of an object, versions are listed by ‘mc ls --versions’.
+      $ mc {{.Name}} --version-id 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY s3/jazz-songs/louis/file01.mp3
+      Removing the latest delete marker of an object restores it, see ‘mc ls --versions --include-delete-markers’.
+
+   8. Preview what a recursive remove would delete, without removing anything.
+      $ mc {{.Name}} --dry-run --force --recursive s3/jazz-songs/louis/
+
+   9. Drop incomplete uploads recursively matching this prefix, which were started over a week ago.
+      $ mc {{.Name}} --incomplete --force --recursive --older-than 168h s3/jazz-songs/
+
+   10. Remove objects matching a pattern, quote it to keep the shell from expanding it.
+      $ mc {{.Name}} 's3/jazz-songs/tmp/*.part'
+
+   11. Remove everything below folders matching a pattern.
+      $ mc {{.Name}} --force --recursive 's3/jazz-songs/tmp-*'
+
+   12. Remove all objects of a bucket, without asking to confirm the bucket name.
+      $ mc {{.Name}} --force --recursive s3/jazz-songs
+
+   13. Permanently remove non-current versions older than 90 days below a prefix, keeping all current objects.
+      $ mc {{.Name}} --versions --recursive --older-than 90d s3/jazz-songs/louis/
+
+   14. Preview the space removing all non-current versions of an object would reclaim.
+      $ mc {{.Name}} --dry-run --versions s3/jazz-songs/louis/file01.mp3
 `,
 }
 
 // Structured message depending on the type of console.
 type rmMessage struct {
-	Status string `json:"status"`
-	URL    string `json:"url"`
+	Status    string `json:"status"`
+	URL       string `json:"url"`
+	VersionID string `json:"versionId,omitempty"`
+	UploadID  string `json:"uploadId,omitempty"`
+
+	// Set only when the removed version is a delete marker, along with the version it restored.
+	IsDeleteMarker    bool   `json:"isDeleteMarker,omitempty"`
+	RestoredVersionID string `json:"restoredVersionId,omitempty"`
 }
 
 // Colorized message for console printing.
 func (r rmMessage) String() string {
+	if r.RestoredVersionID != "" {
+		return console.Colorize("Remove", fmt.Sprintf("Removed delete marker ‘%s’ version ‘%s’, object is restored to version ‘%s’.",
+			r.URL, r.VersionID, r.RestoredVersionID))
+	}
+	if r.IsDeleteMarker {
+		return console.Colorize("Remove", fmt.Sprintf("Removed delete marker ‘%s’ version ‘%s’.", r.URL, r.VersionID))
+	}
+	if r.VersionID != "" {
+		return console.Colorize("Remove", fmt.Sprintf("Removed ‘%s’ version ‘%s’.", r.URL, r.VersionID))
+	}
+	if r.UploadID != "" {
+		return console.Colorize("Remove", fmt.Sprintf("Removed incomplete upload ‘%s’ of ‘%s’.", r.UploadID, r.URL))
+	}
 	return console.Colorize("Remove", fmt.Sprintf("Removed ‘%s’.", r.URL))
 }
 
@@ -108,52 +171,264 @@ func (r rmMessage) JSON() string {
 	return string(msgBytes)
 }
 
+// rmUploadsTotalMessage - totals of the incomplete uploads removed by ‘--older-than’.
+type rmUploadsTotalMessage struct {
+	Status  string `json:"status"`
+	Uploads int    `json:"uploads"`
+	Size    int64  `json:"size"`
+	DryRun  bool   `json:"dryRun,omitempty"`
+}
+
+// Colorized message for console printing.
+func (r rmUploadsTotalMessage) String() string {
+	if r.DryRun {
+		return console.Colorize("Remove", fmt.Sprintf("Would remove %d incomplete upload(s), reclaiming %s.",
+			r.Uploads, humanize.IBytes(uint64(r.Size))))
+	}
+	return console.Colorize("Remove", fmt.Sprintf("Removed %d incomplete upload(s), reclaiming %s.",
+		r.Uploads, humanize.IBytes(uint64(r.Size))))
+}
+
+// JSON'ified message for scripting.
+func (r rmUploadsTotalMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
 // Validate command line arguments.
 func checkRmSyntax(ctx *cli.Context) {
 	// Set command flags from context.
 	isForce := ctx.Bool("force")
 	isRecursive := ctx.Bool("recursive")
 	isIncomplete := ctx.Bool("incomplete")
+	versionID := ctx.String("version-id")
+	isVersions := ctx.Bool("versions")
 
 	if !ctx.Args().Present() {
 		exitCode := 1
 		cli.ShowCommandHelpAndExit(ctx, "rm", exitCode)
 	}
 
-	if !isRecursive && !isIncomplete {
+	if ctx.String("older-than") != "" {
+		if (!isIncomplete || !isRecursive) && !isVersions {
+			fatalIf(errInvalidArgument().Trace(), "Option --older-than can only be used with --incomplete and --recursive, or with --versions.")
+		}
+		parseOlderThan(ctx.String("older-than"))
+	}
+
+	if ctx.Bool("include-current") && !isVersions {
+		fatalIf(errInvalidArgument().Trace(), "Option --include-current can only be used with --versions.")
+	}
+	if isVersions {
+		if isIncomplete || versionID != "" {
+			fatalIf(errInvalidArgument().Trace(), "Option --versions cannot be used with --incomplete or --version-id.")
+		}
 		for _, url := range ctx.Args() {
+			if newRmTarget(url).pattern != "" {
+				fatalIf(errInvalidArgument().Trace(url), "Option --versions cannot be used with patterns.")
+			}
+		}
+		// Objects stay readable unless current versions are removed as well.
+		if ctx.Bool("include-current") && !isForce {
+			fatalIf(errInvalidArgument().Trace(), "Option --include-current removes objects for good, it needs --force.")
+		}
+		return
+	}
+
+	if versionID != "" {
+		if isRecursive || isIncomplete || len(ctx.Args()) > 1 {
+			fatalIf(errInvalidArgument().Trace(),
+				"Option --version-id removes a single object, it cannot be used with --recursive, --incomplete or multiple targets.")
+		}
+		// Latest version may be a delete marker, object need not exist.
+		return
+	}
+
+	var targets []rmTarget
+	for _, url := range ctx.Args() {
+		target := newRmTarget(url)
+		if target.pattern != "" {
+			if isIncomplete {
+				fatalIf(errInvalidArgument().Trace(url), "Option --incomplete cannot be used with patterns.")
+			}
+			if _, e := path.Match(target.pattern, ""); e != nil {
+				fatalIf(probe.NewError(e).Trace(url), "Invalid pattern ‘"+target.pattern+"’.")
+			}
+		} else if !isRecursive && !isIncomplete {
 			if _, _, err := url2Stat(url); err != nil {
 				fatalIf(err.Trace(url), "Unable to stat.")
 			}
 		}
+		targets = append(targets, target)
+	}
+
+	confirm := confirmBucketRemove
+	if isIncomplete || globalDryRun {
+		// Nothing but incomplete uploads or nothing at all is removed.
+		confirm = func(string) bool { return true }
+	}
+	fatalIf(checkRmTargets(targets, isRecursive, isForce, confirm).Trace(ctx.Args()...), "Unable to remove.")
+}
+
+// rmTarget - target of rm, split into the URL listed and the glob pattern its keys are matched against.
+type rmTarget struct {
+	alias   string
+	url     string
+	pattern string // '/' separated pattern below url, empty if target has no wildcards.
+	// Bucket and the key prefix within it of cloud storage targets, bucket
+	// is empty for targets at the root of an alias.
+	isCloud bool
+	bucket  string
+	prefix  string
+}
+
+// newRmTarget - expand alias of target and split off the glob pattern, starting with
+// the first path element containing a wildcard.
+func newRmTarget(aliasedURL string) rmTarget {
+	alias, urlStr, hostCfg := mustExpandAlias(aliasedURL)
+	target := rmTarget{alias: alias, url: urlStr}
+
+	url := client.NewURL(urlStr)
+	start := 0
+	if url.Host != "" {
+		start = strings.Index(urlStr, url.Host) + len(url.Host)
+	}
+	if i := strings.IndexAny(urlStr[start:], "*?["); i >= 0 {
+		if j := strings.LastIndexAny(urlStr[:start+i], "/"+string(url.Separator)); j >= start {
+			target.url = urlStr[:j+1]
+			target.pattern = strings.Replace(urlStr[j+1:], string(url.Separator), "/", -1)
+		}
+	}
+
+	if hostCfg == nil || url.Type != client.Object {
+		return target
 	}
+	target.isCloud = true
+	key := strings.TrimPrefix(client.NewURL(target.url).Path, client.NewURL(hostCfg.URL).Path)
+	key = strings.TrimPrefix(key, "/")
+	if isURLVirtualHostStyle(url.Host) {
+		target.bucket = strings.SplitN(url.Host, ".", 2)[0]
+		target.prefix = key
+		return target
+	}
+	bucketAndPrefix := strings.SplitN(key, "/", 2)
+	target.bucket = bucketAndPrefix[0]
+	if len(bucketAndPrefix) > 1 {
+		target.prefix = bucketAndPrefix[1]
+	}
+	return target
+}
 
+// checkRmTargets - refuse recursive removes without force and removes spanning more than
+// one bucket, removes from the root of a bucket have to be confirmed without force.
+func checkRmTargets(targets []rmTarget, isRecursive, isForce bool, confirm func(bucket string) bool) *probe.Error {
 	if isRecursive && !isForce {
-		fatalIf(errDummy().Trace(),
-			"Recursive removal requires --force option. Please review carefully before performing this *DANGEROUS* operation.")
+		return errRmNeedsForce().Trace()
+	}
+	buckets := make(map[string]bool)
+	for _, target := range targets {
+		if !target.isCloud {
+			continue
+		}
+		if target.bucket == "" && (isRecursive || target.pattern != "") {
+			return errRmAcrossBuckets(target.url).Trace()
+		}
+		buckets[target.alias+"/"+target.bucket] = true
+		if len(buckets) > 1 {
+			return errRmAcrossBuckets(target.url).Trace()
+		}
+	}
+	if isForce {
+		return nil
+	}
+	for _, target := range targets {
+		if target.isCloud && target.bucket != "" && target.prefix == "" && !confirm(target.bucket) {
+			return errRmNotConfirmed(target.bucket).Trace()
+		}
+	}
+	return nil
+}
+
+// confirmBucketRemove - ask to type the bucket name, never confirmed without a terminal or with --json.
+func confirmBucketRemove(bucket string) bool {
+	return confirmBucketName("Remove from the root of bucket ‘"+bucket+"’?", bucket)
+}
+
+// confirmBucketName - ask question on the terminal, confirmed by typing the bucket name. Never
+// confirmed without a terminal or with ‘--json’.
+func confirmBucketName(question, bucket string) bool {
+	if globalJSON || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	fmt.Printf("%s Type the bucket name to confirm: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == bucket
+}
+
+// isRmGlobMatch - true if key matches pattern, recursive removes also match keys below a matching folder.
+func isRmGlobMatch(key, pattern string, isRecursive bool) bool {
+	if matched, _ := path.Match(pattern, key); matched {
+		return true
+	}
+	if !isRecursive {
+		return false
+	}
+	for i := range key {
+		if key[i] != '/' {
+			continue
+		}
+		if matched, _ := path.Match(pattern, key[:i]); matched {
+			return true
+		}
 	}
+	return false
 }
 
 // Remove a single object.
-func rm(targetAlias, targetURL string, isIncomplete, isFake bool) *probe.Error {
+func rm(targetAlias, targetURL string, isIncomplete bool) *probe.Error {
 	clnt, err := newClientFromAlias(targetAlias, targetURL)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
 
-	if isFake { // It is a fake remove. Return success.
-		return nil
-	}
-
-	if err = clnt.Remove(isIncomplete); err != nil {
+	if err = clnt.Remove(isIncomplete, ""); err != nil {
 		return err.Trace(targetURL)
 	}
 
 	return nil
 }
 
-// Remove all objects recursively.
-func rmAll(targetAlias, targetURL string, isRecursive, isIncomplete, isFake bool) {
+// rmVersion - remove a specific version of an object. Removing the latest version of an object,
+// if it is a delete marker, restores the object to the version before it.
+func rmVersion(targetAlias, targetURL, versionID string) (rmMessage, *probe.Error) {
+	msg := rmMessage{Status: "success", VersionID: versionID}
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return msg, err.Trace(targetURL)
+	}
+	// Versions are looked up only to explain the removal, it is done regardless.
+	if versions, objects, err := listObjectVersions(clnt, false); err == nil && len(objects) == 1 {
+		objectVersions := versions[objects[0]]
+		for i, version := range objectVersions {
+			if version.VersionID != versionID || !version.IsDeleteMarker {
+				continue
+			}
+			msg.IsDeleteMarker = true
+			if i == 0 && len(objectVersions) > 1 && !objectVersions[1].IsDeleteMarker {
+				msg.RestoredVersionID = objectVersions[1].VersionID
+			}
+		}
+	}
+	if err = clnt.Remove(false, versionID); err != nil {
+		return msg, err.Trace(targetURL)
+	}
+	return msg, nil
+}
+
+// Remove all objects recursively, incomplete uploads only if initiated longer than olderThan ago.
+func rmAll(targetAlias, targetURL string, isRecursive, isIncomplete bool, olderThan time.Duration) {
 	// Initialize new client.
 	clnt, err := newClientFromAlias(targetAlias, targetURL)
 	if err != nil {
@@ -161,10 +436,26 @@ func rmAll(targetAlias, targetURL string, isRecursive, isIncomplete, isFake bool
 		return // End of journey.
 	}
 
+	if isIncomplete && olderThan > 0 {
+		newClient := func(urlStr string) (client.Client, *probe.Error) {
+			return newClientFromAlias(targetAlias, urlStr)
+		}
+		printMsg(rmStaleUploads(clnt, newClient, targetAlias, olderThan))
+		return
+	}
+
+	// Objects on cloud storage are removed in batches.
+	if isRecursive && !isIncomplete && clnt.GetURL().Type == client.Object {
+		rmAllBatch(clnt, targetAlias, targetURL)
+		return
+	}
+
 	/* Disable recursion and only list this folder's contents. We
 	perform manual depth-first recursion ourself here. */
 	nonRecursive := false
-	for entry := range clnt.List(nonRecursive, isIncomplete) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for entry := range clnt.List(nonRecursive, isIncomplete, doneCh) {
 		if entry.Err != nil {
 			errorIf(entry.Err.Trace(targetURL), "Unable to list ‘"+targetURL+"’.")
 			return // End of journey.
@@ -176,20 +467,141 @@ func rmAll(targetAlias, targetURL string, isRecursive, isIncomplete, isFake bool
 			url.Path = strings.TrimSuffix(entry.URL.Path, string(entry.URL.Separator)) + string(entry.URL.Separator)
 
 			// Recursively remove contents of this directory.
-			rmAll(targetAlias, url.String(), isRecursive, isIncomplete, isFake)
+			rmAll(targetAlias, url.String(), isRecursive, isIncomplete, olderThan)
 		}
 
 		// Regular type.
-		if err = rm(targetAlias, entry.URL.String(), isIncomplete, isFake); err != nil {
+		if err = rm(targetAlias, entry.URL.String(), isIncomplete); err != nil {
 			errorIf(err.Trace(entry.URL.String()), "Unable to remove ‘"+entry.URL.String()+"’.")
 			continue
 		}
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
 		// Construct user facing message and path.
 		entryPath := filepath.Join(targetAlias, entry.URL.Path)
 		printMsg(rmMessage{Status: "success", URL: entryPath})
 	}
 }
 
+// rmStaleUploads - abort incomplete uploads of clnt initiated longer than olderThan ago, recent
+// uploads are left in progress even if they are to the same object. Returns the totals of the
+// uploads removed, with ‘--dry-run’ the ones that would be.
+func rmStaleUploads(clnt client.Client, newClient func(urlStr string) (client.Client, *probe.Error),
+	targetAlias string, olderThan time.Duration) rmUploadsTotalMessage {
+	uploadsCh := make(chan *client.Content)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		defer close(uploadsCh)
+		for upload := range staleUploads(clnt, newClient, olderThan, doneCh) {
+			if upload.Err != nil {
+				errorIf(upload.Err.Trace(clnt.GetURL().String()), "Unable to list incomplete uploads.")
+				return // End of journey.
+			}
+			uploadsCh <- upload
+		}
+	}()
+
+	total := rmUploadsTotalMessage{DryRun: globalDryRun}
+	for upload := range clnt.RemoveUploads(uploadsCh) {
+		if upload.Err != nil {
+			errorIf(upload.Err.Trace(upload.URL.String()), "Unable to remove incomplete upload of ‘"+upload.URL.String()+"’.")
+			continue
+		}
+		total.Uploads++
+		total.Size += upload.Size
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
+		uploadPath := filepath.Join(targetAlias, upload.URL.Path)
+		printMsg(rmMessage{Status: "success", URL: uploadPath, UploadID: upload.UploadID})
+	}
+	return total
+}
+
+// Remove all objects of a cloud storage target, using multi-object delete
+// instead of a request per object. Buckets listed are removed once emptied.
+func rmAllBatch(clnt client.Client, targetAlias, targetURL string) {
+	var bucketURLs []string
+	objectsCh := make(chan *client.Content)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		defer close(objectsCh)
+		isRecursive := true
+		for entry := range clnt.List(isRecursive, false, doneCh) {
+			if entry.Err != nil {
+				errorIf(entry.Err.Trace(targetURL), "Unable to list ‘"+targetURL+"’.")
+				return // End of journey.
+			}
+			if entry.Type.IsDir() {
+				bucketURLs = append(bucketURLs, entry.URL.String())
+				continue
+			}
+			objectsCh <- entry
+		}
+	}()
+
+	printRemoved(targetAlias, clnt.RemoveBatch(objectsCh))
+
+	for _, bucketURL := range bucketURLs {
+		if err := rm(targetAlias, bucketURL, false); err != nil {
+			errorIf(err.Trace(bucketURL), "Unable to remove ‘"+bucketURL+"’.")
+			continue
+		}
+		if globalDryRun {
+			continue
+		}
+		bucketPath := filepath.Join(targetAlias, client.NewURL(bucketURL).Path)
+		printMsg(rmMessage{Status: "success", URL: bucketPath})
+	}
+}
+
+// Remove objects below targetURL whose keys match the glob pattern, using multi-object
+// delete on cloud storage.
+func rmGlob(targetAlias, targetURL, pattern string, isRecursive bool) {
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		errorIf(err.Trace(targetURL), "Invalid URL ‘"+targetURL+"’.")
+		return // End of journey.
+	}
+
+	objectsCh := make(chan *client.Content)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		defer close(objectsCh)
+		isRecursiveList := true
+		for entry := range clnt.List(isRecursiveList, false, doneCh) {
+			if entry.Err != nil {
+				errorIf(entry.Err.Trace(targetURL), "Unable to list ‘"+targetURL+"’.")
+				return // End of journey.
+			}
+			if entry.Type.IsDir() || !isRmGlobMatch(mirrorKey(targetURL, entry), pattern, isRecursive) {
+				continue
+			}
+			objectsCh <- entry
+		}
+	}()
+	printRemoved(targetAlias, clnt.RemoveBatch(objectsCh))
+}
+
+// printRemoved - print every object removed by a batch, report the ones that failed.
+func printRemoved(targetAlias string, resultCh <-chan *client.Content) {
+	for entry := range resultCh {
+		if entry.Err != nil {
+			errorIf(entry.Err.Trace(entry.URL.String()), "Unable to remove ‘"+entry.URL.String()+"’.")
+			continue
+		}
+		if globalDryRun {
+			continue
+		}
+		entryPath := filepath.Join(targetAlias, entry.URL.Path)
+		printMsg(rmMessage{Status: "success", URL: entryPath})
+	}
+}
+
 // main for rm command.
 func mainRm(ctx *cli.Context) {
 	// Set global flags from context.
@@ -202,21 +614,54 @@ func mainRm(ctx *cli.Context) {
 	isForce := ctx.Bool("force")
 	isIncomplete := ctx.Bool("incomplete")
 	isRecursive := ctx.Bool("recursive")
-	isFake := ctx.Bool("fake")
+	versionID := ctx.String("version-id")
+	isVersions := ctx.Bool("versions")
+	includeCurrent := ctx.Bool("include-current")
+	olderThan := parseOlderThan(ctx.String("older-than"))
 
 	// Set color.
 	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
 
 	// Support multiple targets.
 	for _, url := range ctx.Args() {
-		targetAlias, targetURL, _ := mustExpandAlias(url)
+		target := newRmTarget(url)
+		if target.pattern != "" {
+			rmGlob(target.alias, target.url, target.pattern, isRecursive)
+			continue
+		}
+		targetAlias, targetURL := target.alias, target.url
+		if isVersions {
+			clnt, err := newClientFromAlias(targetAlias, targetURL)
+			if err != nil {
+				errorIf(err.Trace(url), "Invalid URL ‘"+url+"’.")
+				continue
+			}
+			printMsg(rmVersions(clnt, targetAlias, isRecursive, time.Now().Add(-olderThan), includeCurrent))
+			continue
+		}
+		if versionID != "" {
+			msg, err := rmVersion(targetAlias, targetURL, versionID)
+			if err != nil {
+				errorIf(err.Trace(url), "Unable to remove ‘"+url+"’ version ‘"+versionID+"’.")
+				continue
+			}
+			if globalDryRun {
+				continue
+			}
+			msg.URL = url
+			printMsg(msg)
+			continue
+		}
 		if isRecursive && isForce {
-			rmAll(targetAlias, targetURL, isRecursive, isIncomplete, isFake)
+			rmAll(targetAlias, targetURL, isRecursive, isIncomplete, olderThan)
 		} else {
-			if err := rm(targetAlias, targetURL, isIncomplete, isFake); err != nil {
+			if err := rm(targetAlias, targetURL, isIncomplete); err != nil {
 				errorIf(err.Trace(url), "Unable to remove ‘"+url+"’.")
 				continue
 			}
+			if globalDryRun {
+				continue
+			}
 			printMsg(rmMessage{Status: "success", URL: url})
 		}
 	}
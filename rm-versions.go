@@ -0,0 +1,113 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// rmVersionsTotalMessage - totals of the versions removed by ‘--versions’.
+type rmVersionsTotalMessage struct {
+	Status   string `json:"status"`
+	Versions int    `json:"versions"`
+	Size     int64  `json:"size"`
+	DryRun   bool   `json:"dryRun,omitempty"`
+}
+
+// Colorized message for console printing.
+func (r rmVersionsTotalMessage) String() string {
+	if r.DryRun {
+		return console.Colorize("Remove", fmt.Sprintf("Would remove %d version(s), reclaiming %s.",
+			r.Versions, humanize.IBytes(uint64(r.Size))))
+	}
+	return console.Colorize("Remove", fmt.Sprintf("Removed %d version(s), reclaiming %s.",
+		r.Versions, humanize.IBytes(uint64(r.Size))))
+}
+
+// JSON'ified message for scripting.
+func (r rmVersionsTotalMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// selectStaleVersions - versions of an object, listed newest first, last modified before cutoff.
+// The current version is only selected with includeCurrent, except a delete marker that is
+// stale itself and no longer hides any version once the others are removed.
+func selectStaleVersions(versions objectVersions, cutoff time.Time, includeCurrent bool) objectVersions {
+	var stale objectVersions
+	for i, version := range versions {
+		if i == 0 && !includeCurrent {
+			continue
+		}
+		if version.Time.Before(cutoff) {
+			stale = append(stale, version)
+		}
+	}
+	if len(versions) > 0 && !includeCurrent && len(stale) == len(versions)-1 {
+		if current := versions[0]; current.IsDeleteMarker && current.Time.Before(cutoff) {
+			stale = append(stale, current)
+		}
+	}
+	return stale
+}
+
+// rmVersions - permanently remove the versions of the objects of clnt last modified before cutoff,
+// all objects below it with isRecursive. Current versions are kept unless includeCurrent. Returns
+// the totals of the versions removed, with ‘--dry-run’ the ones that would be.
+func rmVersions(clnt client.Client, targetAlias string, isRecursive bool, cutoff time.Time, includeCurrent bool) rmVersionsTotalMessage {
+	total := rmVersionsTotalMessage{DryRun: globalDryRun}
+	versions, objects, err := listObjectVersions(clnt, isRecursive)
+	if err != nil {
+		errorIf(err.Trace(clnt.GetURL().String()), "Unable to list versions.")
+		return total
+	}
+
+	versionsCh := make(chan *client.Content)
+	go func() {
+		defer close(versionsCh)
+		for _, object := range objects {
+			for _, version := range selectStaleVersions(versions[object], cutoff, includeCurrent) {
+				versionsCh <- version
+			}
+		}
+	}()
+
+	for version := range clnt.RemoveBatch(versionsCh) {
+		if version.Err != nil {
+			errorIf(version.Err.Trace(version.URL.String()), "Unable to remove ‘"+version.URL.String()+"’ version ‘"+version.VersionID+"’.")
+			continue
+		}
+		total.Versions++
+		total.Size += version.Size
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
+		versionPath := filepath.Join(targetAlias, version.URL.Path)
+		printMsg(rmMessage{Status: "success", URL: versionPath, VersionID: version.VersionID, IsDeleteMarker: version.IsDeleteMarker})
+	}
+	return total
+}
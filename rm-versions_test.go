@@ -0,0 +1,96 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// versionIDs - version ids of the history of key in handler, newest first.
+func versionIDs(handler *undoHandler, key string) []string {
+	var ids []string
+	for _, version := range handler.history(key) {
+		ids = append(ids, version.versionID)
+	}
+	return ids
+}
+
+func (s *TestSuite) TestRmVersions(c *C) {
+	// Versions are a minute apart from 00:01 on: a1, b1, c's marker, a2, b's marker, a3.
+	histories := map[string][]string{"a": {"a1", "a2", "a3"}, "b": {"b1", "-"}, "c": {"-"}}
+	handler := newUndoHandler(histories, "a", "b", "c")
+	defer useUndoServer(c, handler)()
+	cutoff := time.Date(2015, 10, 1, 0, 4, 30, 0, time.UTC)
+	globalQuiet = true
+	defer func() { globalQuiet = false }()
+
+	// Dry run reports the reclaimable versions, removing none.
+	globalDryRun = true
+	total := rmVersions(mustNewClient(c, "undo/bucket/"), "undo", true, cutoff, false)
+	globalDryRun = false
+	c.Assert(total, DeepEquals, rmVersionsTotalMessage{Versions: 4, Size: 15, DryRun: true})
+	c.Assert(handler.versions, HasLen, 6)
+
+	total = rmVersions(mustNewClient(c, "undo/bucket/"), "undo", true, cutoff, false)
+	c.Assert(total, DeepEquals, rmVersionsTotalMessage{Versions: 4, Size: 15})
+	// Current versions are kept, b's marker still hides it. c's marker hides nothing anymore.
+	c.Assert(versionIDs(handler, "a"), DeepEquals, []string{"a3"})
+	c.Assert(versionIDs(handler, "b"), DeepEquals, []string{"mb1"})
+	c.Assert(versionIDs(handler, "c"), IsNil)
+	c.Assert(handler.current("a"), Equals, "a3")
+}
+
+func (s *TestSuite) TestRmVersionsObject(c *C) {
+	histories := map[string][]string{"a": {"a1", "a2"}, "ab": {"ab1", "ab2"}}
+	handler := newUndoHandler(histories, "a", "ab")
+	defer useUndoServer(c, handler)()
+	globalQuiet = true
+	defer func() { globalQuiet = false }()
+
+	// Only the object named is cleaned up, not others starting with its name.
+	total := rmVersions(mustNewClient(c, "undo/bucket/a"), "undo", false, time.Now(), false)
+	c.Assert(total.Versions, Equals, 1)
+	c.Assert(versionIDs(handler, "a"), DeepEquals, []string{"a2"})
+	c.Assert(versionIDs(handler, "ab"), DeepEquals, []string{"ab2", "ab1"})
+
+	// Current versions are removed only when asked to.
+	total = rmVersions(mustNewClient(c, "undo/bucket/ab"), "undo", false, time.Now(), true)
+	c.Assert(total.Versions, Equals, 2)
+	c.Assert(versionIDs(handler, "ab"), IsNil)
+}
+
+func (s *TestSuite) TestSelectStaleVersions(c *C) {
+	handler := newUndoHandler(map[string][]string{"a": {"a1", "-", "a2"}}, "a")
+	defer useUndoServer(c, handler)()
+	versions, _, err := listObjectVersions(mustNewClient(c, "undo/bucket/a"), false)
+	c.Assert(err, IsNil)
+	cutoff := time.Date(2015, 10, 1, 0, 2, 30, 0, time.UTC)
+	// The delete marker is no longer current, it is removed like any version.
+	stale := selectStaleVersions(versions["/bucket/a"], cutoff, false)
+	c.Assert(stale, HasLen, 2)
+	c.Assert(stale[0].IsDeleteMarker, Equals, true)
+	c.Assert(stale[1].VersionID, Equals, "a1")
+	c.Assert(selectStaleVersions(versions["/bucket/a"], time.Time{}, true), HasLen, 0)
+}
+
+func (s *TestSuite) TestParseOlderThanDays(c *C) {
+	c.Assert(parseOlderThan("90d"), Equals, 90*24*time.Hour)
+	c.Assert(parseOlderThan("168h"), Equals, 168*time.Hour)
+	c.Assert(parseOlderThan(""), Equals, time.Duration(0))
+}
@@ -0,0 +1,175 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// rmGate - outcome of checkRmTargets for targets, along with the buckets asked to confirm.
+func rmGate(c *C, isRecursive, isForce, confirmed bool, urls ...string) (error, []string) {
+	var targets []rmTarget
+	for _, url := range urls {
+		targets = append(targets, newRmTarget(url))
+	}
+	var asked []string
+	err := checkRmTargets(targets, isRecursive, isForce, func(bucket string) bool {
+		asked = append(asked, bucket)
+		return confirmed
+	})
+	if err != nil {
+		return err.ToGoError(), asked
+	}
+	return nil, asked
+}
+
+func (s *TestSuite) TestRmBucketRootGate(c *C) {
+	defer useTempMcConfig(c)()
+	c.Assert(setAlias("rmtest", hostConfigV7{URL: "http://localhost:9000", API: "S3v4"}), IsNil)
+
+	// Recursive removal of a bucket root is blocked without force, nothing to confirm.
+	err, asked := rmGate(c, true, false, true, "rmtest/bucket")
+	c.Assert(err, DeepEquals, errRmNeedsForce().ToGoError())
+	c.Assert(asked, IsNil)
+
+	// Removal from the root of a bucket has to be confirmed without force.
+	err, asked = rmGate(c, false, false, false, "rmtest/bucket/*")
+	c.Assert(err, DeepEquals, errRmNotConfirmed("bucket").ToGoError())
+	c.Assert(asked, DeepEquals, []string{"bucket"})
+	err, _ = rmGate(c, false, false, true, "rmtest/bucket/*")
+	c.Assert(err, IsNil)
+	err, asked = rmGate(c, true, true, false, "rmtest/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(asked, IsNil)
+
+	// Removal below a folder of the bucket is not confirmed.
+	err, asked = rmGate(c, false, false, false, "rmtest/bucket/tmp/*", "rmtest/bucket/a.txt")
+	c.Assert(err, IsNil)
+	c.Assert(asked, IsNil)
+
+	// Removal spanning buckets is refused, even with force.
+	err, _ = rmGate(c, false, true, true, "rmtest/bucket/a.txt", "rmtest/other/a.txt")
+	c.Assert(err, DeepEquals, errRmAcrossBuckets("http://localhost:9000/other/a.txt").ToGoError())
+	err, _ = rmGate(c, true, true, true, "rmtest/")
+	c.Assert(err, DeepEquals, errRmAcrossBuckets("http://localhost:9000/").ToGoError())
+	err, _ = rmGate(c, false, true, true, "rmtest/*")
+	c.Assert(err, DeepEquals, errRmAcrossBuckets("http://localhost:9000/").ToGoError())
+
+	// Without a terminal removal is never confirmed.
+	c.Assert(confirmBucketRemove("bucket"), Equals, false)
+}
+
+func (s *TestSuite) TestRmTargetPattern(c *C) {
+	defer useTempMcConfig(c)()
+	c.Assert(setAlias("rmtest", hostConfigV7{URL: "http://[::1]:9000/s3", API: "S3v4"}), IsNil)
+
+	target := newRmTarget("rmtest/bucket/tmp/*.part")
+	c.Assert(target.url, Equals, "http://[::1]:9000/s3/bucket/tmp/")
+	c.Assert(target.pattern, Equals, "*.part")
+	c.Assert(target.bucket, Equals, "bucket")
+	c.Assert(target.prefix, Equals, "tmp/")
+
+	target = newRmTarget("rmtest/bucket/tmp-*/old/*")
+	c.Assert(target.url, Equals, "http://[::1]:9000/s3/bucket/")
+	c.Assert(target.pattern, Equals, "tmp-*/old/*")
+	c.Assert(target.prefix, Equals, "")
+
+	target = newRmTarget("rmtest/bucket/a.txt")
+	c.Assert(target.url, Equals, "http://[::1]:9000/s3/bucket/a.txt")
+	c.Assert(target.pattern, Equals, "")
+
+	c.Assert(isRmGlobMatch("tmp/a.part", "tmp/*.part", false), Equals, true)
+	c.Assert(isRmGlobMatch("tmp/sub/a.part", "tmp/*.part", false), Equals, false)
+	c.Assert(isRmGlobMatch("tmp-1/sub/a.part", "tmp-*", false), Equals, false)
+	c.Assert(isRmGlobMatch("tmp-1/sub/a.part", "tmp-*", true), Equals, true)
+}
+
+// listFiles - files below root, '/' separated and relative to root.
+func listFiles(c *C, root string) []string {
+	var files []string
+	e := filepath.Walk(root, func(path string, info os.FileInfo, e error) error {
+		if e == nil && !info.IsDir() {
+			files = append(files, filepath.ToSlash(strings.TrimPrefix(path, root+string(filepath.Separator))))
+		}
+		return e
+	})
+	c.Assert(e, IsNil)
+	sort.Strings(files)
+	return files
+}
+
+func (s *TestSuite) TestRmGlob(c *C) {
+	defer useTempMcConfig(c)()
+	root, e := ioutil.TempDir(os.TempDir(), "mc-rm-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"keep.part", "tmp/a.part", "tmp/b.txt", "tmp/sub/c.part", "tmp-1/d.txt", "tmp-1/sub/e.txt"} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600), IsNil)
+	}
+
+	// Only matching keys directly below the folder are removed.
+	target := newRmTarget(filepath.Join(root, "tmp", "*.part"))
+	rmGlob(target.alias, target.url, target.pattern, false)
+	c.Assert(listFiles(c, root), DeepEquals, []string{"keep.part", "tmp-1/d.txt", "tmp-1/sub/e.txt", "tmp/b.txt", "tmp/sub/c.part"})
+
+	// Recursive removes take everything below matching folders.
+	target = newRmTarget(filepath.Join(root, "tmp-*"))
+	rmGlob(target.alias, target.url, target.pattern, true)
+	c.Assert(listFiles(c, root), DeepEquals, []string{"keep.part", "tmp/b.txt", "tmp/sub/c.part"})
+}
+
+func (s *TestSuite) TestRemoveDeleteMarker(c *C) {
+	handler := newUndoHandler(map[string][]string{"object": {"v1", "-", "v2", "-"}}, "object")
+	defer useUndoServer(c, handler)()
+	history := handler.history("object")
+	oldMarker, latestMarker := history[2].versionID, history[0].versionID
+
+	// An older delete marker is just removed.
+	msg, err := rmVersion("undo", mustExpandURL(c, "undo/bucket/object"), oldMarker)
+	c.Assert(err, IsNil)
+	c.Assert(msg.IsDeleteMarker, Equals, true)
+	c.Assert(msg.RestoredVersionID, Equals, "")
+	c.Assert(handler.current("object"), Equals, "-")
+
+	// Removing the latest delete marker restores the object.
+	msg, err = rmVersion("undo", mustExpandURL(c, "undo/bucket/object"), latestMarker)
+	c.Assert(err, IsNil)
+	c.Assert(msg.IsDeleteMarker, Equals, true)
+	c.Assert(msg.RestoredVersionID, Equals, "v2")
+	c.Assert(handler.current("object"), Equals, "v2")
+	c.Assert(strings.Contains(msg.String(), "restored to version ‘v2’"), Equals, true)
+
+	// Removing a version is not explained.
+	msg, err = rmVersion("undo", mustExpandURL(c, "undo/bucket/object"), "v1")
+	c.Assert(err, IsNil)
+	c.Assert(msg.IsDeleteMarker, Equals, false)
+	c.Assert(handler.history("object"), HasLen, 1)
+}
+
+// mustExpandURL - URL with its alias expanded.
+func mustExpandURL(c *C, aliasedURL string) string {
+	_, urlStr, _, err := expandAlias(aliasedURL)
+	c.Assert(err, IsNil)
+	return urlStr
+}
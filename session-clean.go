@@ -0,0 +1,218 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-isatty"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Reasons a session is cleaned up.
+const (
+	sessionMissingData   = "no data"
+	sessionMissingHeader = "no header"
+	sessionUnreadable    = "unreadable"
+	sessionExpired       = "expired"
+)
+
+// sessionSuffixes - suffixes of all files of a session, longest first.
+var sessionSuffixes = []string{".json" + backupFileSuffix, ".json", ".data", ".pid"}
+
+// sessionCleanup - files of a session to be removed, and why.
+type sessionCleanup struct {
+	SessionID string
+	Reason    string
+	Files     []string
+	Size      int64
+}
+
+// cleanSessionMessage container for session clean messages.
+type cleanSessionMessage struct {
+	Status    string `json:"status"`
+	SessionID string `json:"sessionId,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Size      int64  `json:"size"`
+	Sessions  int    `json:"sessions,omitempty"`
+}
+
+// String colorized session clean message, with SessionID empty the total reclaimed.
+func (c cleanSessionMessage) String() string {
+	if c.SessionID == "" {
+		return console.Colorize("ClearSession", fmt.Sprintf("Reclaimed %s from %d session(s).", humanize.IBytes(uint64(c.Size)), c.Sessions))
+	}
+	return console.Colorize("ClearSession", fmt.Sprintf("Session ‘%s’ cleaned (%s), reclaimed %s.", c.SessionID, c.Reason, humanize.IBytes(uint64(c.Size))))
+}
+
+// JSON jsonified session clean message.
+func (c cleanSessionMessage) JSON() string {
+	cleanSessionJSONBytes, e := json.Marshal(c)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(cleanSessionJSONBytes)
+}
+
+// isProcessRunning - process pid is alive, where this cannot be told it is assumed to be.
+func isProcessRunning(pid int) bool {
+	process, e := os.FindProcess(pid)
+	if e != nil {
+		return false
+	}
+	// Finding a process on windows opens it, it exists.
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	e = process.Signal(syscall.Signal(0))
+	return e == nil || e == syscall.EPERM
+}
+
+// isSessionRunning - a live process recorded itself as running the session in pidFile.
+func isSessionRunning(pidFile string) bool {
+	data, e := ioutil.ReadFile(pidFile)
+	if e != nil {
+		return false
+	}
+	pid, e := strconv.Atoi(strings.TrimSpace(string(data)))
+	return e == nil && pid > 0 && isProcessRunning(pid)
+}
+
+// findSessionCleanups - sessions whose header or data file is gone or unreadable, and, if olderThan is set,
+// sessions started longer ago. Sessions still running are never cleaned up.
+func findSessionCleanups(olderThan time.Duration) ([]sessionCleanup, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	entries, e := ioutil.ReadDir(sessionDir)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	// Files of each session, by suffix.
+	sessions := make(map[string]map[string]os.FileInfo)
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		for _, suffix := range sessionSuffixes {
+			if sid := strings.TrimSuffix(entry.Name(), suffix); sid != entry.Name() && sid != "" {
+				if sessions[sid] == nil {
+					sessions[sid] = make(map[string]os.FileInfo)
+				}
+				sessions[sid][suffix] = entry
+				break
+			}
+		}
+	}
+
+	var cleanups []sessionCleanup
+	for sid, files := range sessions {
+		if _, ok := files[".pid"]; ok && isSessionRunning(filepath.Join(sessionDir, sid+".pid")) {
+			continue
+		}
+		_, hasHeader := files[".json"]
+		if _, hasBackup := files[".json"+backupFileSuffix]; hasBackup {
+			hasHeader = true
+		}
+		_, hasData := files[".data"]
+		reason := ""
+		switch {
+		case !hasHeader:
+			reason = sessionMissingHeader
+		case !hasData:
+			reason = sessionMissingData
+		default:
+			header, err := loadSessionV6Header(sid)
+			if err != nil {
+				reason = sessionUnreadable
+			} else if olderThan > 0 && time.Since(header.When) > olderThan {
+				reason = sessionExpired
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		cleanup := sessionCleanup{SessionID: sid, Reason: reason}
+		for _, file := range files {
+			cleanup.Files = append(cleanup.Files, filepath.Join(sessionDir, file.Name()))
+			cleanup.Size += file.Size()
+		}
+		sort.Strings(cleanup.Files)
+		cleanups = append(cleanups, cleanup)
+	}
+	sort.Slice(cleanups, func(i, j int) bool { return cleanups[i].SessionID < cleanups[j].SessionID })
+	return cleanups, nil
+}
+
+// removeSessionCleanup - remove the files of cleanup.
+func removeSessionCleanup(cleanup sessionCleanup) *probe.Error {
+	for _, file := range cleanup.Files {
+		if e := os.Remove(file); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e).Trace(cleanup.SessionID)
+		}
+	}
+	return nil
+}
+
+// confirmSessionClean - ask whether to remove the files of cleanups, without a terminal to ask on nothing is removed.
+func confirmSessionClean(cleanups []sessionCleanup) bool {
+	if globalJSON || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	var size int64
+	for _, cleanup := range cleanups {
+		console.Println(fmt.Sprintf("%s (%s): %s", cleanup.SessionID, cleanup.Reason, strings.Join(cleanup.Files, ", ")))
+		size += cleanup.Size
+	}
+	fmt.Printf("Remove %d session(s), %s? [y/N]: ", len(cleanups), humanize.IBytes(uint64(size)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// cleanSessions - remove orphaned sessions, and with olderThan set expired ones, after confirmation unless forced.
+func cleanSessions(olderThan time.Duration, isForce bool) {
+	cleanups, err := findSessionCleanups(olderThan)
+	fatalIf(err.Trace(), "Unable to scan session folder.")
+	if len(cleanups) > 0 && !isForce && !confirmSessionClean(cleanups) {
+		fatalIf(errDummy().Trace(), "Sessions were not cleaned, confirm or use ‘--force’.")
+	}
+	total := cleanSessionMessage{Status: "success"}
+	for _, cleanup := range cleanups {
+		if err = removeSessionCleanup(cleanup); err != nil {
+			errorIf(err.Trace(cleanup.SessionID), "Unable to clean session ‘"+cleanup.SessionID+"’.")
+			continue
+		}
+		printMsg(cleanSessionMessage{Status: "success", SessionID: cleanup.SessionID, Reason: cleanup.Reason, Size: cleanup.Size})
+		total.Size += cleanup.Size
+		total.Sessions++
+	}
+	printMsg(total)
+}
@@ -0,0 +1,181 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// sessionFlagMessage - a flag of the command saved in a session, changed on resume with ‘--override-flags’.
+type sessionFlagMessage struct {
+	Status    string `json:"status"`
+	SessionID string `json:"sessionId"`
+	Flag      string `json:"flag"`
+	Saved     string `json:"saved"`
+	Given     string `json:"given"`
+}
+
+// String colorized session flag message.
+func (m sessionFlagMessage) String() string {
+	return console.Colorize("SessionFlag", "Session ‘"+m.SessionID+"’ resumes with ‘--"+m.Flag+"’ changed from ‘"+
+		m.Saved+"’ to ‘"+m.Given+"’.")
+}
+
+// JSON jsonified session flag message.
+func (m sessionFlagMessage) JSON() string {
+	sessionFlagJSONBytes, e := json.Marshal(m)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(sessionFlagJSONBytes)
+}
+
+// sessionCommandFlags - flags of the command saved in a session.
+func sessionCommandFlags(commandType string) []cli.Flag {
+	switch commandType {
+	case "cp":
+		return cpFlags
+	case "mirror":
+		return mirrorFlags
+	}
+	return nil
+}
+
+// flagNames - names of flag, the first one is the one saved in sessions.
+func flagNames(f cli.Flag) []string {
+	var name string
+	switch f := f.(type) {
+	case cli.BoolFlag:
+		name = f.Name
+	case cli.IntFlag:
+		name = f.Name
+	case cli.StringFlag:
+		name = f.Name
+	case cli.StringSliceFlag:
+		name = f.Name
+	case cli.DurationFlag:
+		name = f.Name
+	}
+	var names []string
+	for _, n := range strings.Split(name, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// savedFlag - value of the command flag f saved in s and the value given in set, in the same form.
+// Flags not saved in s are compared against their zero value.
+func savedFlag(s *sessionV6, f cli.Flag, name string, value flag.Value) (saved, given string) {
+	switch f.(type) {
+	case cli.BoolFlag:
+		return strconv.FormatBool(s.Header.CommandBoolFlags[name]), value.String()
+	case cli.IntFlag:
+		return strconv.Itoa(s.Header.CommandIntFlags[name]), value.String()
+	case cli.StringSliceFlag:
+		saved = strings.Join(strings.Split(s.Header.CommandStringFlags[name], mirrorExcludeSeparator), ",")
+		return saved, strings.Join(value.(*cli.StringSlice).Value(), ",")
+	case cli.DurationFlag:
+		d, _ := time.ParseDuration(s.Header.CommandStringFlags[name])
+		return d.String(), value.String()
+	}
+	return s.Header.CommandStringFlags[name], value.String()
+}
+
+// overrideFlag - save the value given in set for the command flag f in s.
+func overrideFlag(s *sessionV6, f cli.Flag, name string, value flag.Value) {
+	switch f.(type) {
+	case cli.BoolFlag:
+		s.Header.CommandBoolFlags[name], _ = strconv.ParseBool(value.String())
+	case cli.IntFlag:
+		s.Header.CommandIntFlags[name], _ = strconv.Atoi(value.String())
+	case cli.StringSliceFlag:
+		delete(s.Header.CommandStringFlags, name)
+		setMirrorExcludes(s, value.(*cli.StringSlice).Value())
+	default:
+		s.Header.CommandStringFlags[name] = value.String()
+	}
+}
+
+// checkResumeFlags - compare the command flags given to resume s in args against those saved in it.
+// Flags differing change what is copied and are refused, unless isOverride is set. Overridden flags are
+// saved in s and returned. Global flags are given to resume itself, they only change output and may differ.
+func checkResumeFlags(s *sessionV6, args []string, isOverride bool) ([]sessionFlagMessage, *probe.Error) {
+	set := flag.NewFlagSet(s.Header.CommandType, flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	commandFlags := map[string]cli.Flag{}
+	savedNames := map[string]string{}
+	for _, f := range sessionCommandFlags(s.Header.CommandType) {
+		names := flagNames(f)
+		for _, name := range names {
+			commandFlags[name] = f
+			savedNames[name] = names[0]
+		}
+		// Slices given are appended to the value of the flag, parse into a new one.
+		if sliceFlag, ok := f.(cli.StringSliceFlag); ok {
+			sliceFlag.Value = &cli.StringSlice{}
+			f = sliceFlag
+		}
+		f.Apply(set)
+	}
+	if e := set.Parse(args); e != nil {
+		return nil, probe.NewError(e).Trace(args...)
+	}
+	if set.NArg() > 0 {
+		return nil, errInvalidArgument().Trace(set.Args()...)
+	}
+
+	var changed []sessionFlagMessage
+	set.Visit(func(given *flag.Flag) {
+		f, ok := commandFlags[given.Name]
+		if !ok || savedNames[given.Name] == "help" {
+			return
+		}
+		name := savedNames[given.Name]
+		saved, value := savedFlag(s, f, name, given.Value)
+		if saved == value {
+			return
+		}
+		changed = append(changed, sessionFlagMessage{
+			Status:    "success",
+			SessionID: s.SessionID,
+			Flag:      name,
+			Saved:     saved,
+			Given:     value,
+		})
+		if isOverride {
+			overrideFlag(s, f, name, given.Value)
+		}
+	})
+	if len(changed) > 0 && !isOverride {
+		var names []string
+		for _, c := range changed {
+			names = append(names, "--"+c.Flag)
+		}
+		return nil, errSessionFlagsChanged(s.SessionID, names).Trace(args...)
+	}
+	return changed, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCheckResumeFlags(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	defer func() {
+		c.Assert(session.Close(), IsNil)
+		c.Assert(session.Delete(), IsNil)
+	}()
+	session.Header.CommandType = "mirror"
+	session.Header.CommandBoolFlags["remove"] = true
+	session.Header.CommandStringFlags["object-timeout"] = "30s"
+	setMirrorExcludes(session, []string{"*.log"})
+
+	// Flags as saved, in any form, resume the session.
+	changed, err := checkResumeFlags(session, []string{"--exclude", "*.log", "--remove", "--object-timeout", "0.5m"}, false)
+	c.Assert(err, IsNil)
+	c.Assert(changed, IsNil)
+
+	// A different exclude lists a different set, it is refused without the override.
+	args := []string{"--exclude", "*.log", "--exclude", "*.tmp", "--force"}
+	changed, err = checkResumeFlags(session, args, false)
+	c.Assert(err, NotNil)
+	c.Assert(changed, IsNil)
+	c.Assert(getMirrorExcludes(session), DeepEquals, []string{"*.log"})
+
+	changed, err = checkResumeFlags(session, args, true)
+	c.Assert(err, IsNil)
+	c.Assert(changed, DeepEquals, []sessionFlagMessage{
+		{Status: "success", SessionID: session.SessionID, Flag: "exclude", Saved: "*.log", Given: "*.log,*.tmp"},
+		{Status: "success", SessionID: session.SessionID, Flag: "force", Saved: "false", Given: "true"},
+	})
+	c.Assert(getMirrorExcludes(session), DeepEquals, []string{"*.log", "*.tmp"})
+	c.Assert(session.Header.CommandBoolFlags["force"], Equals, true)
+
+	// Global flags are given to resume itself, not as flags of the command.
+	_, err = checkResumeFlags(session, []string{"--json"}, false)
+	c.Assert(err, NotNil)
+}
+
+func (s *TestSuite) TestRestoreGlobalsKeepsOutput(c *C) {
+	newGlobalsSession := func() *sessionV6 {
+		return &sessionV6{Header: &sessionV6Header{GlobalBoolFlags: map[string]bool{}, GlobalStringFlags: map[string]string{}}}
+	}
+	saved := newGlobalsSession()
+	saved.setGlobals()
+	savedJSON := globalJSON
+	defer func() {
+		saved.restoreGlobals()
+		globalJSON = savedJSON
+	}()
+
+	// A session saved without --json resumes with JSON output if asked for.
+	globalJSON = false
+	session := newGlobalsSession()
+	session.setGlobals()
+	globalJSON = true
+	session.restoreGlobals()
+	c.Assert(globalJSON, Equals, true)
+}
@@ -35,6 +35,18 @@ var (
 			Name:  "help, h",
 			Usage: "Help of session.",
 		},
+		cli.DurationFlag{
+			Name:  "older-than",
+			Usage: "With clean, also remove sessions started longer ago than this duration.",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "With clean, remove without asking for confirmation.",
+		},
+		cli.BoolFlag{
+			Name:  "override-flags",
+			Usage: "With resume, save the flags given after ‘--’ in the session even if they differ from those it was started with.",
+		},
 	}
 )
 
@@ -48,12 +60,13 @@ var sessionCmd = cli.Command{
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} [FLAGS] OPERATION [ARG]
+   mc {{.Name}} [FLAGS] OPERATION [ARG] [-- COMMAND-FLAGS]
 
 OPERATION:
    resume   Resume a previously saved session.
    clear    Clear a previously saved session.
    list     List all previously saved sessions.
+   clean    Remove session files left behind without a header or data, and not in use.
 
 SESSION-ID:
    SESSION - Session can either be $SESSION-ID or "all".
@@ -73,6 +86,12 @@ EXAMPLES:
 
    4. Clear session.
       $ mc {{.Name}} clear all
+
+   5. Remove orphaned session files and sessions older than a week, without asking.
+      $ mc {{.Name}} --older-than 168h --force clean
+
+   6. Resume session, excluding temporary files it was not started to exclude.
+      $ mc {{.Name}} --override-flags resume ygVIpSJs -- --exclude "*.tmp"
 `,
 }
 
@@ -96,7 +115,7 @@ func listSessions() *probe.Error {
 	// sort sessions based on time.
 	sort.Sort(bySessionWhen(bySessions))
 	for _, session := range bySessions {
-		printMsg(session)
+		printMsg(session.Message())
 		session.Close()
 	}
 	return nil
@@ -104,7 +123,7 @@ func listSessions() *probe.Error {
 
 // clearSessionMessage container for clearing session messages.
 type clearSessionMessage struct {
-	Status    string `json:"success"`
+	Status    string `json:"status"`
 	SessionID string `json:"sessionId"`
 }
 
@@ -148,15 +167,27 @@ func clearSession(sid string) {
 	}
 }
 
-func sessionExecute(s *sessionV6) {
+// sessionDryRun prints what resuming the session would do, the session itself is left untouched.
+func sessionDryRun(s *sessionV6) {
 	switch s.Header.CommandType {
 	case "cp":
-		doCopySession(s)
+		doCopyDryRun(copyURLsFromSession(s), isCopiedAtFactory(s.Header.Copied, s.Header.LastCopied))
 	case "mirror":
-		doMirrorSession(s)
+		doMirrorDryRun(mirrorURLsFromSession(s), isCopiedFactory(s.Header.LastCopied))
 	}
 }
 
+// sessionExecute - resume s, returns the summary of the transfer.
+func sessionExecute(s *sessionV6) *transferSummary {
+	switch s.Header.CommandType {
+	case "cp":
+		return doCopySession(s)
+	case "mirror":
+		return doMirrorSession(s)
+	}
+	return newTransferSummary(s.Header.When)
+}
+
 func checkSessionSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) < 1 {
 		cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
@@ -167,10 +198,17 @@ func checkSessionSyntax(ctx *cli.Context) {
 
 	switch strings.TrimSpace(ctx.Args().First()) {
 	case "list":
+	case "clean":
+		if ctx.Duration("older-than") < 0 {
+			fatalIf(errInvalidArgument().Trace(ctx.String("older-than")), "Unable to validate negative duration.")
+		}
 	case "resume":
 		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
 			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
 		}
+		if args := ctx.Args().Tail().Tail(); len(args) > 0 && args[0] != "--" {
+			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Flags of the resumed command are given after ‘--’.")
+		}
 	case "clear":
 		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
 			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
@@ -206,6 +244,8 @@ func mainSession(ctx *cli.Context) {
 	console.SetColor("SessionID", color.New(color.FgYellow, color.Bold))
 	console.SetColor("SessionTime", color.New(color.FgGreen))
 	console.SetColor("ClearSession", color.New(color.FgGreen, color.Bold))
+	console.SetColor("SessionFlag", color.New(color.FgYellow))
+	console.SetColor("Summary", color.New(color.FgCyan, color.Bold))
 
 	if !isSessionDirExists() {
 		fatalIf(createSessionDir().Trace(), "Unable to create session folder.")
@@ -233,9 +273,28 @@ func mainSession(ctx *cli.Context) {
 		s, err := loadSessionV6(sid)
 		fatalIf(err.Trace(sid), "Unable to load session.")
 
+		// Flags given after ‘--’ are checked against those the session was started with.
+		var commandArgs []string
+		if args := ctx.Args().Tail().Tail(); len(args) > 0 {
+			commandArgs = args[1:]
+		}
+		changed, err := checkResumeFlags(s, commandArgs, ctx.Bool("override-flags"))
+		fatalIf(err.Trace(sid), "Unable to resume session with the flags given.")
+		if !globalDryRun {
+			fatalIf(s.markRunning().Trace(sid), "Unable to mark session ‘"+sid+"’ as running.")
+		}
+
 		// Restore the state of global variables from this previous session.
 		s.restoreGlobals()
 
+		for _, msg := range changed {
+			printMsg(msg)
+		}
+		// Overridden flags are saved, resuming it again later keeps them.
+		if len(changed) > 0 && !globalDryRun {
+			s.SaveOrDie()
+		}
+
 		savedCwd, e := os.Getwd()
 		fatalIf(probe.NewError(e), "Unable to determine current working folder.")
 
@@ -244,12 +303,18 @@ func mainSession(ctx *cli.Context) {
 			e = os.Chdir(s.Header.RootPath)
 			fatalIf(probe.NewError(e), "Unable to change working folder to root path while resuming session.")
 		}
-		sessionExecute(s)
-		err = s.Close()
-		fatalIf(err.Trace(), "Unable to close session file properly.")
-
-		err = s.Delete()
-		fatalIf(err.Trace(), "Unable to clear session files properly.")
+		if globalDryRun {
+			// Keep the session as is, a real resume starts where it left off.
+			sessionDryRun(s)
+		} else {
+			summary := sessionExecute(s)
+			err = s.Close()
+			fatalIf(err.Trace(), "Unable to close session file properly.")
+
+			err = s.Delete()
+			fatalIf(err.Trace(), "Unable to clear session files properly.")
+			exitOnFailures(summary)
+		}
 
 		// change folder back to saved path.
 		e = os.Chdir(savedCwd)
@@ -257,5 +322,8 @@ func mainSession(ctx *cli.Context) {
 	// purge a requested pending session, if "all" purge everything.
 	case "clear":
 		clearSession(strings.TrimSpace(ctx.Args().Tail().First()))
+	// remove orphaned and, if asked, old sessions.
+	case "clean":
+		cleanSessions(ctx.Duration("older-than"), ctx.Bool("force"))
 	}
 }
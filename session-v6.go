@@ -22,7 +22,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,14 +35,24 @@ import (
 	"github.com/minio/minio-xl/pkg/quick"
 )
 
+// minSessionVersion - oldest session version sharing the layout of version ‘6’. Sessions of
+// this or any newer version are resumed, fields they miss are set to defaults.
+const minSessionVersion = 5
+
 func migrateSessionV5ToV6() {
 	for _, sid := range getSessionIDs() {
-		sessionV6, err := loadSessionV6(sid)
-		fatalIf(err.Trace(sid), "Unable to load version ‘6’. Migration failed please report this issue at https://github.com/minio/mc/issues.")
-		if sessionV6.Header.Version == "6" { // It is new format.
-			return
+		header, err := loadSessionV6Header(sid)
+		if err != nil && !isSessionDecodeError(err) {
+			fatalIf(err.Trace(sid), "Unable to load version ‘6’. Migration failed please report this issue at https://github.com/minio/mc/issues.")
+		}
+		if err == nil && isSessionCompatible(header) {
+			continue
+		}
+		/*** Remove all session files which cannot be resumed ***/
+		version := "unknown"
+		if header != nil {
+			version = header.Version
 		}
-		/*** Remove all session files older than v6 ***/
 
 		sessionFile, err := getSessionFile(sid)
 		fatalIf(err.Trace(sid), "Unable to get session file.")
@@ -47,12 +60,15 @@ func migrateSessionV5ToV6() {
 		sessionDataFile, err := getSessionDataFile(sid)
 		fatalIf(err.Trace(sid), "Unable to get session data file.")
 
-		console.Println("Removing unsupported session file ‘" + sessionFile + "’ version ‘" + sessionV6.Header.Version + "’.")
+		console.Println("Removing unsupported session file ‘" + sessionFile + "’ version ‘" + version + "’.")
 		if e := os.Remove(sessionFile); e != nil {
-			fatalIf(probe.NewError(e), "Unable to remove version ‘"+sessionV6.Header.Version+"’ session file ‘"+sessionFile+"’.")
+			fatalIf(probe.NewError(e), "Unable to remove version ‘"+version+"’ session file ‘"+sessionFile+"’.")
 		}
-		if e := os.Remove(sessionDataFile); e != nil {
-			fatalIf(probe.NewError(e), "Unable to remove version ‘"+sessionV6.Header.Version+"’ session data file ‘"+sessionDataFile+"’.")
+		if e := os.Remove(sessionDataFile); e != nil && !os.IsNotExist(e) {
+			fatalIf(probe.NewError(e), "Unable to remove version ‘"+version+"’ session data file ‘"+sessionDataFile+"’.")
+		}
+		if e := os.Remove(getBackupFile(sessionFile)); e != nil && !os.IsNotExist(e) {
+			fatalIf(probe.NewError(e), "Unable to remove version ‘"+version+"’ session backup file ‘"+getBackupFile(sessionFile)+"’.")
 		}
 	}
 }
@@ -73,6 +89,53 @@ type sessionV6Header struct {
 	LastCopied         string            `json:"lastCopied"`
 	TotalBytes         int64             `json:"totalBytes"`
 	TotalObjects       int               `json:"totalObjects"`
+	CompletedBytes     int64             `json:"completedBytes"`
+	CompletedObjects   int               `json:"completedObjects"`
+	// Objects copied by their position in the session data file, cp resumes by them.
+	Copied copiedPositions `json:"copied"`
+	// Downloads in progress, keyed by target path.
+	PartialDownloads map[string]partialDownload `json:"partialDownloads,omitempty"`
+	// How far listing the source got, set until all URLs to copy are prepared.
+	Enumeration *enumerationCheckpoint `json:"enumeration,omitempty"`
+}
+
+// copiedPositions - positions in the session data file of the objects copied, all positions below
+// Next and the ones in Done. Objects finish out of order, Done keeps those beyond the first one pending.
+type copiedPositions struct {
+	Next int   `json:"next"`
+	Done []int `json:"done,omitempty"` // sorted.
+}
+
+// add - record position as copied.
+func (p *copiedPositions) add(position int) {
+	if p.has(position) {
+		return
+	}
+	i := sort.SearchInts(p.Done, position)
+	p.Done = append(p.Done, 0)
+	copy(p.Done[i+1:], p.Done[i:])
+	p.Done[i] = position
+	for len(p.Done) > 0 && p.Done[0] == p.Next {
+		p.Done = p.Done[1:]
+		p.Next++
+	}
+	if len(p.Done) == 0 {
+		p.Done = nil
+	}
+}
+
+// has - true if position was copied.
+func (p copiedPositions) has(position int) bool {
+	if position < p.Next {
+		return true
+	}
+	i := sort.SearchInts(p.Done, position)
+	return i < len(p.Done) && p.Done[i] == position
+}
+
+// isEmpty - true if no position was recorded.
+func (p copiedPositions) isEmpty() bool {
+	return p.Next == 0 && len(p.Done) == 0
 }
 
 // sessionMessage container for session messages
@@ -91,71 +154,138 @@ type sessionV6 struct {
 	mutex     *sync.Mutex
 	DataFP    *sessionDataFP
 	sigCh     bool
+	// Progress of the running transfer, saved with the session.
+	progress *progressAccumulator
+	// Set once a session with objects prepared by an earlier run is resumed.
+	resumed bool
 }
 
-// sessionDataFP data file pointer.
+// sessionDataFP data file pointer, reads and writes hold the session mutex.
 type sessionDataFP struct {
 	dirty bool
+	mutex *sync.Mutex
 	*os.File
 }
 
+func (file *sessionDataFP) Read(p []byte) (int, error) {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	return file.File.Read(p)
+}
+
 func (file *sessionDataFP) Write(p []byte) (int, error) {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
 	file.dirty = true
 	return file.File.Write(p)
 }
 
+// Message - session message of the session.
+func (s sessionV6) Message() sessionMessage {
+	return sessionMessage{
+		SessionID:   s.SessionID,
+		Time:        s.Header.When.Local(),
+		CommandType: s.Header.CommandType,
+		CommandArgs: s.Header.CommandArgs,
+	}
+}
+
 // String colorized session message.
-func (s sessionV6) String() string {
+func (s sessionMessage) String() string {
 	message := console.Colorize("SessionID", fmt.Sprintf("%s -> ", s.SessionID))
-	message = message + console.Colorize("SessionTime", fmt.Sprintf("[%s]", s.Header.When.Local().Format(printDate)))
-	message = message + console.Colorize("Command", fmt.Sprintf(" %s %s", s.Header.CommandType, strings.Join(s.Header.CommandArgs, " ")))
+	message = message + console.Colorize("SessionTime", fmt.Sprintf("[%s]", s.Time.Format(printDate)))
+	message = message + console.Colorize("Command", fmt.Sprintf(" %s %s", s.CommandType, strings.Join(s.CommandArgs, " ")))
 	return message
 }
 
 // JSON jsonified session message.
-func (s sessionV6) JSON() string {
-	sessionMsg := sessionMessage{
-		SessionID:   s.SessionID,
-		Time:        s.Header.When.Local(),
-		CommandType: s.Header.CommandType,
-		CommandArgs: s.Header.CommandArgs,
-	}
-	sessionMsg.Status = "success"
-	sessionBytes, e := json.Marshal(sessionMsg)
+func (s sessionMessage) JSON() string {
+	s.Status = "success"
+	sessionBytes, e := json.Marshal(s)
 	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
 
 	return string(sessionBytes)
 }
 
+// isSessionDecodeError - true if err is a session header whose fields do not decode into
+// those of version ‘6’, such as a field of another type written by a newer mc.
+func isSessionDecodeError(err *probe.Error) bool {
+	_, ok := err.ToGoError().(*json.UnmarshalTypeError)
+	return ok
+}
+
+// isSessionCompatible - true if header, of whatever version, can be resumed.
+func isSessionCompatible(header *sessionV6Header) bool {
+	version, e := strconv.Atoi(header.Version)
+	return e == nil && version >= minSessionVersion
+}
+
+// setDefaults - fills in fields missing from a session written by an older mc. Fields of a
+// newer mc are dropped while decoding, header is saved as the current version from now on.
+func (h *sessionV6Header) setDefaults() {
+	h.Version = globalSessionVersion
+	if h.GlobalBoolFlags == nil {
+		h.GlobalBoolFlags = make(map[string]bool)
+	}
+	if h.GlobalIntFlags == nil {
+		h.GlobalIntFlags = make(map[string]int)
+	}
+	if h.GlobalStringFlags == nil {
+		h.GlobalStringFlags = make(map[string]string)
+	}
+	if h.CommandBoolFlags == nil {
+		h.CommandBoolFlags = make(map[string]bool)
+	}
+	if h.CommandIntFlags == nil {
+		h.CommandIntFlags = make(map[string]int)
+	}
+	if h.CommandStringFlags == nil {
+		h.CommandStringFlags = make(map[string]string)
+	}
+}
+
+// loadSessionV6Header - reads the session header as written, unknown fields are ignored.
+func loadSessionV6Header(sid string) (*sessionV6Header, *probe.Error) {
+	sessionFile, err := getSessionFile(sid)
+	if err != nil {
+		return nil, err.Trace(sid)
+	}
+	var header *sessionV6Header
+	err = loadFileWithBackup(sessionFile, func(filename string) *probe.Error {
+		qs, err := quick.New(&sessionV6Header{Version: "5"})
+		if err != nil {
+			return err.Trace(filename)
+		}
+		if err = qs.Load(filename); err != nil {
+			return err.Trace(filename)
+		}
+		header = qs.Data().(*sessionV6Header)
+		return nil
+	})
+	if err != nil {
+		return nil, err.Trace(sid)
+	}
+	return header, nil
+}
+
 // loadSessionV6 - reads session file if exists and re-initiates internal variables
 func loadSessionV6(sid string) (*sessionV6, *probe.Error) {
 	if !isSessionDirExists() {
 		return nil, errInvalidArgument().Trace()
 	}
-	sessionFile, err := getSessionFile(sid)
+	header, err := loadSessionV6Header(sid)
 	if err != nil {
 		return nil, err.Trace(sid)
 	}
-
-	if _, err := os.Stat(sessionFile); err != nil {
-		return nil, probe.NewError(err)
+	if !isSessionCompatible(header) {
+		return nil, errSessionVersion(sid, header.Version).Trace(sid)
 	}
 
 	s := &sessionV6{}
-	s.Header = &sessionV6Header{}
+	s.Header = header
+	s.Header.setDefaults()
 	s.SessionID = sid
-	s.Header.Version = "5"
-	qs, err := quick.New(s.Header)
-	if err != nil {
-		return nil, err.Trace(sid, s.Header.Version)
-	}
-	err = qs.Load(sessionFile)
-	if err != nil {
-		return nil, err.Trace(sid, s.Header.Version)
-	}
-
 	s.mutex = new(sync.Mutex)
-	s.Header = qs.Data().(*sessionV6Header)
 
 	sessionDataFile, err := getSessionDataFile(s.SessionID)
 	if err != nil {
@@ -166,7 +296,7 @@ func loadSessionV6(sid string) (*sessionV6, *probe.Error) {
 	dataFile, e := os.Open(sessionDataFile)
 	fatalIf(probe.NewError(e), "Unable to open session data file \""+sessionDataFile+"\".")
 
-	s.DataFP = &sessionDataFP{false, dataFile}
+	s.DataFP = &sessionDataFP{false, s.mutex, dataFile}
 
 	return s, nil
 }
@@ -175,7 +305,7 @@ func loadSessionV6(sid string) (*sessionV6, *probe.Error) {
 func newSessionV6() *sessionV6 {
 	s := &sessionV6{}
 	s.Header = &sessionV6Header{}
-	s.Header.Version = "6"
+	s.Header.Version = globalSessionVersion
 	// map of command and files copied.
 	s.Header.GlobalBoolFlags = make(map[string]bool)
 	s.Header.GlobalIntFlags = make(map[string]int)
@@ -194,7 +324,8 @@ func newSessionV6() *sessionV6 {
 	dataFile, e := os.Create(sessionDataFile)
 	fatalIf(probe.NewError(e), "Unable to create session data file \""+sessionDataFile+"\".")
 
-	s.DataFP = &sessionDataFP{false, dataFile}
+	s.DataFP = &sessionDataFP{false, s.mutex, dataFile}
+	fatalIf(s.markRunning().Trace(s.SessionID), "Unable to create session pid file.")
 
 	// Capture state of global flags.
 	s.setGlobals()
@@ -202,6 +333,26 @@ func newSessionV6() *sessionV6 {
 	return s
 }
 
+// markRunning - record this process as running the session, ‘session clean’ leaves it alone
+// until the session is closed or the process is gone.
+func (s *sessionV6) markRunning() *probe.Error {
+	sessionPidFile, err := getSessionPidFile(s.SessionID)
+	if err != nil {
+		return err.Trace(s.SessionID)
+	}
+	if e := ioutil.WriteFile(sessionPidFile, []byte(strconv.Itoa(os.Getpid())), 0600); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// unmarkRunning - the session is no longer run by this process.
+func (s *sessionV6) unmarkRunning() {
+	if sessionPidFile, err := getSessionPidFile(s.SessionID); err == nil {
+		os.Remove(sessionPidFile)
+	}
+}
+
 // HasData provides true if this is a session resume, false otherwise.
 func (s sessionV6) HasData() bool {
 	if s.Header.LastCopied == "" {
@@ -217,35 +368,112 @@ func (s *sessionV6) NewDataReader() io.Reader {
 	return io.Reader(s.DataFP)
 }
 
-// NewDataReader provides writer interface to session data file.
+// NewDataReader provides writer interface to session data file, URLs prepared earlier are dropped.
 func (s *sessionV6) NewDataWriter() io.Writer {
-	// DataFP is always intitialized, either via new or load functions.
-	s.DataFP.Seek(0, os.SEEK_SET)
+	return s.ResumeDataWriter(0)
+}
+
+// ResumeDataWriter provides writer interface to session data file, appending after its first size bytes.
+func (s *sessionV6) ResumeDataWriter(size int64) io.Writer {
+	// DataFP is always intitialized, either via new or load functions. Loaded data files are
+	// opened for reading only, reopen them for writing.
+	s.mutex.Lock()
+	if file, e := os.OpenFile(s.DataFP.Name(), os.O_RDWR, 0600); e == nil {
+		s.DataFP.File.Close()
+		s.DataFP.File = file
+	}
+	s.mutex.Unlock()
+	s.DataFP.Truncate(size)
+	s.DataFP.Seek(size, os.SEEK_SET)
 	return io.Writer(s.DataFP)
 }
 
-// Save this session.
+// markCompleted records url as the last copied object, persisted on next Save.
+func (s *sessionV6) markCompleted(url string, size int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Header.LastCopied = url
+	s.Header.CompletedObjects++
+	s.Header.CompletedBytes += size
+}
+
+// markCopied records the object at position of the session data file as copied, persisted on next Save.
+func (s *sessionV6) markCopied(position int, url string, size int64) {
+	s.mutex.Lock()
+	s.Header.Copied.add(position)
+	s.mutex.Unlock()
+
+	s.markCompleted(url, size)
+}
+
+// getPartialDownload returns the download recorded for target path.
+func (s *sessionV6) getPartialDownload(target string) (partialDownload, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	partial, ok := s.Header.PartialDownloads[target]
+	return partial, ok
+}
+
+// setPartialDownload records a download to target path, persisted on next Save.
+func (s *sessionV6) setPartialDownload(target string, partial partialDownload) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Header.PartialDownloads == nil {
+		s.Header.PartialDownloads = make(map[string]partialDownload)
+	}
+	s.Header.PartialDownloads[target] = partial
+}
+
+// removePartialDownload forgets a completed download to target path.
+func (s *sessionV6) removePartialDownload(target string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.Header.PartialDownloads, target)
+}
+
+// Save this session. The data file is synced before the header refers to it, the header replaces
+// the previous one atomically, a save which fails leaves the last good save in place.
 func (s *sessionV6) Save() *probe.Error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	return s.save()
+}
 
+// save - Save with the session mutex held.
+func (s *sessionV6) save() *probe.Error {
 	if s.DataFP.dirty {
-		if err := s.DataFP.Sync(); err != nil {
+		if err := syncFile(s.DataFP.File); err != nil {
 			return probe.NewError(err)
 		}
 		s.DataFP.dirty = false
 	}
-
-	qs, err := quick.New(s.Header)
-	if err != nil {
-		return err.Trace(s.SessionID)
+	if s.progress != nil {
+		stat := s.progress.Stat()
+		s.Header.TotalBytes = stat.TotalBytes
+		s.Header.TotalObjects = stat.TotalObjects
 	}
 
 	sessionFile, err := getSessionFile(s.SessionID)
 	if err != nil {
 		return err.Trace(s.SessionID)
 	}
-	return qs.Save(sessionFile).Trace(sessionFile)
+	return saveFileAtomic(sessionFile, s.Header).Trace(sessionFile)
+}
+
+// SaveOrDie - save this session, exit if it can not be saved. The data file is closed as it is,
+// resuming the session continues from its last good save.
+func (s *sessionV6) SaveOrDie() {
+	if err := s.Save(); err != nil {
+		s.mutex.Lock()
+		s.DataFP.Close()
+		s.unmarkRunning()
+		s.mutex.Unlock()
+		fatalIf(err.Trace(s.SessionID), "Unable to save session, to resume from its last save ‘mc session resume "+s.SessionID+"’.")
+	}
 }
 
 // setGlobals captures the state of global variables into session header.
@@ -255,37 +483,65 @@ func (s *sessionV6) setGlobals() {
 	s.Header.GlobalBoolFlags["debug"] = globalDebug
 	s.Header.GlobalBoolFlags["json"] = globalJSON
 	s.Header.GlobalBoolFlags["noColor"] = globalNoColor
+	s.Header.GlobalBoolFlags["noStatCache"] = globalNoStatCache
+	s.Header.GlobalBoolFlags["noDNSCache"] = globalNoDNSCache
+	s.Header.GlobalStringFlags["connTimeout"] = globalConnTimeout.String()
+	s.Header.GlobalStringFlags["readTimeout"] = globalReadTimeout.String()
+	s.Header.GlobalStringFlags["idleTimeout"] = globalIdleTimeout.String()
+	s.Header.GlobalStringFlags["keepAliveTimeout"] = globalKeepAliveTimeout.String()
+	s.Header.GlobalStringFlags["maxConnsPerHost"] = strconv.Itoa(globalMaxConnsPerHost)
+	// Only the path is saved, keys never end up in session files.
+	s.Header.GlobalStringFlags["encryptKeyFile"] = globalEncryptKeyFile
+	s.Header.GlobalStringFlags["encryptMode"] = globalEncryptMode
+	s.Header.GlobalStringFlags["debugFormat"] = globalDebugFormat
+	s.Header.GlobalStringFlags["audit"] = globalAuditFile
+	s.Header.GlobalStringFlags["output"] = globalOutputFormat
+	s.Header.GlobalStringFlags["userAgentSuffix"] = globalUserAgentSuffix
+	s.Header.GlobalBoolFlags["noCorrelationID"] = globalNoCorrelationID
 }
 
 // RestoreGlobals restores the state of global variables.
 // Used by resumeSession.
 func (s sessionV6) restoreGlobals() {
-	quiet := s.Header.GlobalBoolFlags["quiet"]
-	debug := s.Header.GlobalBoolFlags["debug"]
-	json := s.Header.GlobalBoolFlags["json"]
-	noColor := s.Header.GlobalBoolFlags["noColor"]
-	setGlobals(quiet, debug, json, noColor)
+	// Output flags given to resume are kept, they do not change what is copied.
+	quiet := s.Header.GlobalBoolFlags["quiet"] || globalQuiet
+	debug := s.Header.GlobalBoolFlags["debug"] || globalDebug
+	json := s.Header.GlobalBoolFlags["json"] || globalJSON
+	noColor := s.Header.GlobalBoolFlags["noColor"] || globalNoColor
+	noStatCache := s.Header.GlobalBoolFlags["noStatCache"]
+	noDNSCache := s.Header.GlobalBoolFlags["noDNSCache"]
+	// Sessions saved by older versions carry no timeouts, leave them unset.
+	connTimeout, _ := time.ParseDuration(s.Header.GlobalStringFlags["connTimeout"])
+	readTimeout, _ := time.ParseDuration(s.Header.GlobalStringFlags["readTimeout"])
+	idleTimeout, _ := time.ParseDuration(s.Header.GlobalStringFlags["idleTimeout"])
+	keepAliveTimeout, _ := time.ParseDuration(s.Header.GlobalStringFlags["keepAliveTimeout"])
+	maxConnsPerHost, _ := strconv.Atoi(s.Header.GlobalStringFlags["maxConnsPerHost"])
+	// Dry runs never save a session, keep the current setting.
+	setGlobals(quiet, debug, json, noColor, globalDryRun, noStatCache, noDNSCache, connTimeout, readTimeout, idleTimeout, keepAliveTimeout, maxConnsPerHost)
+	setEncryptKeyFile(s.Header.GlobalStringFlags["encryptKeyFile"])
+	setEncryptMode(s.Header.GlobalStringFlags["encryptMode"])
+	setDebugFormat(s.Header.GlobalStringFlags["debugFormat"])
+	setAuditFile(s.Header.GlobalStringFlags["audit"])
+	setOutput(s.Header.GlobalStringFlags["output"])
+	// Requests of a resumed session carry the correlation id of the resume, not of the command it resumes.
+	setUserAgentSuffix(s.Header.GlobalStringFlags["userAgentSuffix"])
+	setCorrelationID(s.Header.GlobalBoolFlags["noCorrelationID"])
 }
 
-// Close ends this session and removes all associated session files.
+// Close saves this session and closes its data file, it is closed even if the save fails.
 func (s *sessionV6) Close() *probe.Error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if err := s.DataFP.Close(); err != nil {
-		return probe.NewError(err)
+	err := s.save()
+	if e := s.DataFP.Close(); e != nil && err == nil {
+		err = probe.NewError(e)
 	}
-
-	qs, err := quick.New(s.Header)
-	if err != nil {
-		return err.Trace()
-	}
-
-	sessionFile, err := getSessionFile(s.SessionID)
+	s.unmarkRunning()
 	if err != nil {
 		return err.Trace(s.SessionID)
 	}
-	return qs.Save(sessionFile).Trace(sessionFile)
+	return nil
 }
 
 // Delete removes all the session files.
@@ -305,6 +561,7 @@ func (s *sessionV6) Delete() *probe.Error {
 			return probe.NewError(err)
 		}
 	}
+	s.unmarkRunning()
 
 	sessionFile, err := getSessionFile(s.SessionID)
 	if err != nil {
@@ -315,13 +572,29 @@ func (s *sessionV6) Delete() *probe.Error {
 		return probe.NewError(err)
 	}
 
+	// Backup may not exist if the session was saved only once.
+	if err := os.Remove(getBackupFile(sessionFile)); err != nil && !os.IsNotExist(err) {
+		return probe.NewError(err)
+	}
+
 	return nil
 }
 
-// Close a session and exit.
-func (s sessionV6) CloseAndDie() {
-	s.Close()
-	console.Fatalln("Session safely terminated. To resume session ‘mc session resume " + s.SessionID + "’")
+// checkpoint saves the progress of an interrupted session and closes it.
+func (s *sessionV6) checkpoint() *probe.Error {
+	// Remember how far downloads got for resume.
+	syncPartialDownloads(s)
+	return s.Close().Trace(s.SessionID)
+}
+
+// Close a session and exit, with the exit code classifying err if the session stopped on an error.
+func (s sessionV6) CloseAndDie(err *probe.Error) {
+	errorIf(s.checkpoint().Trace(), "Unable to save session ‘"+s.SessionID+"’.")
+	code := exitFailure
+	if err != nil {
+		code = exitCode(err)
+	}
+	console.FatalCodeln(code, "Session safely terminated. To resume session ‘mc session resume "+s.SessionID+"’")
 }
 
 // Create a factory function to simplify checking if an
@@ -346,3 +619,17 @@ func isCopiedFactory(lastCopied string) func(string) bool {
 		return false
 	}
 }
+
+// isCopiedAtFactory - like isCopiedFactory for objects known by their position in the session data file,
+// positions in copied were copied. Sessions which recorded no positions resume after lastCopied.
+func isCopiedAtFactory(copied copiedPositions, lastCopied string) func(position int, sourceURL string) bool {
+	if copied.isEmpty() {
+		isCopied := isCopiedFactory(lastCopied)
+		return func(position int, sourceURL string) bool {
+			return isCopied(sourceURL)
+		}
+	}
+	return func(position int, sourceURL string) bool {
+		return copied.has(position)
+	}
+}
@@ -99,6 +99,17 @@ func getSessionDataFile(sid string) (string, *probe.Error) {
 	return sessionDataFile, nil
 }
 
+// getSessionPidFile - get the file recording the process running a given session.
+func getSessionPidFile(sid string) (string, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+
+	sessionPidFile := filepath.Join(sessionDir, sid+".pid")
+	return sessionPidFile, nil
+}
+
 // getSessionIDs - get all active sessions.
 func getSessionIDs() (sids []string) {
 	sessionDir, err := getSessionDir()
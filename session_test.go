@@ -17,7 +17,18 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
@@ -51,3 +62,299 @@ func (s *TestSuite) TestSession(c *C) {
 	err = savedSession.Delete()
 	c.Assert(err, IsNil)
 }
+
+func (s *TestSuite) TestSessionSaveFailure(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	sessionDir, err := getSessionDir()
+	c.Assert(err, IsNil)
+	session := newSessionV6()
+	defer session.Delete()
+	dataFile, err := getSessionDataFile(session.SessionID)
+	c.Assert(err, IsNil)
+	session.Header.CommandArgs = []string{"saved"}
+	_, e := session.DataFP.Write([]byte("saved\n"))
+	c.Assert(e, IsNil)
+	c.Assert(session.Save(), IsNil)
+
+	savedWrite, savedSync, savedRename := writeFile, syncFile, renameFile
+	defer func() { writeFile, syncFile, renameFile = savedWrite, savedSync, savedRename }()
+	failSync := func(fails func(name string) bool) func(*os.File) error {
+		return func(file *os.File) error {
+			if fails(file.Name()) {
+				return errors.New("sync failed")
+			}
+			return savedSync(file)
+		}
+	}
+	testCases := []struct {
+		stage  string
+		inject func()
+	}{
+		{"data", func() { syncFile = failSync(func(name string) bool { return name == dataFile }) }},
+		{"write", func() { writeFile = func(*os.File, []byte) (int, error) { return 0, errors.New("disk full") } }},
+		{"sync", func() { syncFile = failSync(func(name string) bool { return strings.Contains(name, "$deleteme.") }) }},
+		{"rename", func() { renameFile = func(string, string) error { return errors.New("rename failed") } }},
+		{"folder", func() { syncFile = failSync(func(name string) bool { return name == sessionDir }) }},
+	}
+	data := "saved\n"
+	for _, testCase := range testCases {
+		session.Header.CommandArgs = []string{testCase.stage}
+		_, e = session.DataFP.Write([]byte(testCase.stage + "\n"))
+		c.Assert(e, IsNil)
+		data += testCase.stage + "\n"
+		testCase.inject()
+		c.Assert(session.Save(), NotNil, Commentf("%s", testCase.stage))
+		writeFile, syncFile, renameFile = savedWrite, savedSync, savedRename
+
+		// The session loads as last saved, with its data intact.
+		loaded, err := loadSessionV6(session.SessionID)
+		c.Assert(err, IsNil, Commentf("%s", testCase.stage))
+		c.Assert(loaded.Header.CommandArgs, DeepEquals, []string{"saved"}, Commentf("%s", testCase.stage))
+		loaded.DataFP.Close()
+		contents, e := ioutil.ReadFile(dataFile)
+		c.Assert(e, IsNil)
+		c.Assert(string(contents), Equals, data)
+
+		// A later save succeeds, temporary files of failed saves are gone.
+		session.Header.CommandArgs = []string{"saved"}
+		c.Assert(session.Save(), IsNil, Commentf("%s", testCase.stage))
+		leftovers, e := filepath.Glob(filepath.Join(sessionDir, "$deleteme.*"))
+		c.Assert(e, IsNil)
+		c.Assert(leftovers, HasLen, 0)
+	}
+
+	// A session which fails to save on close is closed all the same, and loads as last saved.
+	session.Header.CommandArgs = []string{"closed"}
+	renameFile = func(string, string) error { return errors.New("rename failed") }
+	c.Assert(session.Close(), NotNil)
+	renameFile = savedRename
+	loaded, err := loadSessionV6(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(loaded.Header.CommandArgs, DeepEquals, []string{"saved"})
+	loaded.DataFP.Close()
+}
+
+func (s *TestSuite) TestSessionInterrupt(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("Interrupt cannot be sent to the own process on windows.")
+	}
+	restore := useTempMcConfig(c)
+	defer restore()
+
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV6()
+	session.Header.CommandType = "cp"
+	dataFP := session.NewDataWriter()
+	for _, url := range []string{"s3/bucket/a", "s3/bucket/b", "s3/bucket/c"} {
+		fmt.Fprintln(dataFP, url)
+	}
+	session.Header.TotalObjects = 3
+	c.Assert(session.Save(), IsNil)
+
+	trapCh := signalTrap(os.Interrupt)
+
+	// First object is copied, the copy loop moves on to the second one.
+	session.markCompleted("s3/bucket/a", 10)
+	c.Assert(session.Save(), IsNil)
+	scanner := bufio.NewScanner(session.NewDataReader())
+	c.Assert(scanner.Scan(), Equals, true)
+	c.Assert(scanner.Scan(), Equals, true)
+	c.Assert(scanner.Text(), Equals, "s3/bucket/b")
+
+	process, e := os.FindProcess(os.Getpid())
+	c.Assert(e, IsNil)
+	c.Assert(process.Signal(os.Interrupt), IsNil)
+	select {
+	case <-trapCh:
+	case <-time.After(5 * time.Second):
+		c.Fatal("Interrupt was not trapped.")
+	}
+	c.Assert(session.checkpoint(), IsNil)
+
+	savedSession, err := loadSessionV6(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(savedSession.Header.LastCopied, Equals, "s3/bucket/a")
+	c.Assert(savedSession.Header.CompletedObjects, Equals, 1)
+	c.Assert(savedSession.Header.CompletedBytes, Equals, int64(10))
+	c.Assert(savedSession.Header.TotalObjects, Equals, 3)
+
+	isCopied := isCopiedFactory(savedSession.Header.LastCopied)
+	c.Assert(isCopied("s3/bucket/a"), Equals, true)
+	c.Assert(isCopied("s3/bucket/b"), Equals, false)
+
+	c.Assert(savedSession.Close(), IsNil)
+	c.Assert(savedSession.Delete(), IsNil)
+}
+
+func (s *TestSuite) TestCopiedPositions(c *C) {
+	var copied copiedPositions
+	c.Assert(copied.isEmpty(), Equals, true)
+	for _, position := range []int{3, 1, 3} {
+		copied.add(position)
+	}
+	c.Assert(copied, DeepEquals, copiedPositions{Next: 0, Done: []int{1, 3}})
+	c.Assert(copied.has(0), Equals, false)
+	c.Assert(copied.has(1), Equals, true)
+	c.Assert(copied.has(2), Equals, false)
+
+	// The first pending position copied moves Next past all copied in a row.
+	copied.add(0)
+	c.Assert(copied, DeepEquals, copiedPositions{Next: 2, Done: []int{3}})
+	copied.add(2)
+	c.Assert(copied, DeepEquals, copiedPositions{Next: 4})
+	c.Assert(copied.has(3), Equals, true)
+	c.Assert(copied.has(4), Equals, false)
+
+	// Positions are used once recorded, sessions without them resume after the last copied.
+	isCopied := isCopiedAtFactory(copied, "s3/bucket/z")
+	c.Assert(isCopied(1, "s3/bucket/z"), Equals, true)
+	c.Assert(isCopied(5, "s3/bucket/z"), Equals, false)
+	isCopied = isCopiedAtFactory(copiedPositions{}, "s3/bucket/b")
+	c.Assert(isCopied(7, "s3/bucket/a"), Equals, true)
+	c.Assert(isCopied(8, "s3/bucket/b"), Equals, true)
+	c.Assert(isCopied(0, "s3/bucket/c"), Equals, false)
+}
+
+// rewriteSessionHeader - applies change to the JSON fields of the saved header of session sid,
+// as if it was written by another mc. Its backup is removed.
+func rewriteSessionHeader(c *C, sid string, change func(fields map[string]interface{})) {
+	sessionFile, err := getSessionFile(sid)
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadFile(sessionFile)
+	c.Assert(e, IsNil)
+	fields := make(map[string]interface{})
+	c.Assert(json.Unmarshal(data, &fields), IsNil)
+	change(fields)
+	data, e = json.Marshal(fields)
+	c.Assert(e, IsNil)
+	c.Assert(ioutil.WriteFile(sessionFile, data, 0600), IsNil)
+	os.Remove(getBackupFile(sessionFile))
+}
+
+func (s *TestSuite) TestSessionCompatibility(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	c.Assert(createSessionDir(), IsNil)
+
+	newSavedSession := func() string {
+		session := newSessionV6()
+		session.Header.CommandType = "cp"
+		session.Header.CommandArgs = []string{"s3/bucket/a", "/tmp/a"}
+		session.Header.CommandStringFlags["storage-class"] = "STANDARD"
+		session.Header.TotalObjects = 1
+		c.Assert(session.Save(), IsNil)
+		c.Assert(session.Close(), IsNil)
+		return session.SessionID
+	}
+
+	// Written by an older mc, without flags which were added since.
+	older := newSavedSession()
+	rewriteSessionHeader(c, older, func(fields map[string]interface{}) {
+		fields["version"] = "5"
+		delete(fields, "cmdStringFlags")
+		delete(fields, "globalIntFlags")
+	})
+	// Written by a newer mc, with fields unknown to this one.
+	newer := newSavedSession()
+	rewriteSessionHeader(c, newer, func(fields map[string]interface{}) {
+		fields["version"] = "7"
+		fields["checksums"] = map[string]string{"s3/bucket/a": "c2FtcGxl"}
+	})
+	// Too old, and with a field of another type.
+	tooOld := newSavedSession()
+	rewriteSessionHeader(c, tooOld, func(fields map[string]interface{}) {
+		fields["version"] = "4"
+	})
+	changedType := newSavedSession()
+	rewriteSessionHeader(c, changedType, func(fields map[string]interface{}) {
+		fields["version"] = "7"
+		fields["totalBytes"] = "1MiB"
+	})
+
+	migrateSession()
+	c.Assert(isSessionExists(older), Equals, true)
+	c.Assert(isSessionExists(newer), Equals, true)
+	c.Assert(isSessionExists(tooOld), Equals, false)
+	c.Assert(isSessionExists(changedType), Equals, false)
+	_, err := loadSessionV6(tooOld)
+	c.Assert(err, NotNil)
+
+	for _, sid := range []string{older, newer} {
+		session, err := loadSessionV6(sid)
+		c.Assert(err, IsNil)
+		c.Assert(session.Header.Version, Equals, globalSessionVersion)
+		c.Assert(session.Header.CommandType, Equals, "cp")
+		c.Assert(session.Header.CommandArgs, DeepEquals, []string{"s3/bucket/a", "/tmp/a"})
+		c.Assert(session.Header.TotalObjects, Equals, 1)
+		// Usable as any session of the current version.
+		session.Header.CommandStringFlags["checksum"] = "CRC32C"
+		session.Header.GlobalIntFlags["retries"] = 1
+		session.markCompleted("s3/bucket/a", 10)
+		c.Assert(session.Save(), IsNil)
+		c.Assert(session.Close(), IsNil)
+
+		session, err = loadSessionV6(sid)
+		c.Assert(err, IsNil)
+		c.Assert(session.Header.LastCopied, Equals, "s3/bucket/a")
+		c.Assert(session.Header.CommandStringFlags["checksum"], Equals, "CRC32C")
+		c.Assert(session.Close(), IsNil)
+		c.Assert(session.Delete(), IsNil)
+	}
+}
+
+func (s *TestSuite) TestSessionClean(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	c.Assert(createSessionDir(), IsNil)
+	sessionDir, err := getSessionDir()
+	c.Assert(err, IsNil)
+
+	newSavedSession := func() *sessionV6 {
+		session := newSessionV6()
+		session.Header.CommandType = "cp"
+		c.Assert(session.Save(), IsNil)
+		c.Assert(session.Close(), IsNil)
+		return session
+	}
+	complete := newSavedSession()
+	noData := newSavedSession()
+	dataFile, err := getSessionDataFile(noData.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(os.Remove(dataFile), IsNil)
+	old := newSavedSession()
+	rewriteSessionHeader(c, old.SessionID, func(fields map[string]interface{}) {
+		fields["time"] = time.Now().Add(-48 * time.Hour)
+	})
+	// Only a data file, as left behind by a crash before the first save.
+	c.Assert(ioutil.WriteFile(filepath.Join(sessionDir, "orphanXY.data"), []byte("abc"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(sessionDir, "orphanXY.pid"), []byte("0"), 0600), IsNil)
+	// A session just started by a running process has no header yet either.
+	running := newSessionV6()
+	defer running.Delete()
+
+	cleanups, err := findSessionCleanups(0)
+	c.Assert(err, IsNil)
+	reasons := make(map[string]string)
+	for _, cleanup := range cleanups {
+		reasons[cleanup.SessionID] = cleanup.Reason
+	}
+	c.Assert(reasons, DeepEquals, map[string]string{noData.SessionID: sessionMissingData, "orphanXY": sessionMissingHeader})
+
+	cleanSessions(24*time.Hour, true)
+	c.Assert(isSessionExists(complete.SessionID), Equals, true)
+	c.Assert(isSessionExists(noData.SessionID), Equals, false)
+	c.Assert(isSessionExists(old.SessionID), Equals, false)
+	entries, e := ioutil.ReadDir(sessionDir)
+	c.Assert(e, IsNil)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	expected := []string{complete.SessionID + ".data", complete.SessionID + ".json", running.SessionID + ".data", running.SessionID + ".pid"}
+	if _, e = os.Stat(filepath.Join(sessionDir, complete.SessionID+".json"+backupFileSuffix)); e == nil {
+		expected = append(expected, complete.SessionID+".json"+backupFileSuffix)
+	}
+	sort.Strings(expected)
+	c.Assert(names, DeepEquals, expected)
+}
@@ -0,0 +1,101 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	shareCleanFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of share clean.",
+		},
+	}
+)
+
+// Remove expired shares.
+var shareClean = cli.Command{
+	Name:   "clean",
+	Usage:  "Remove expired shares.",
+	Action: mainShareClean,
+	Flags:  append(shareCleanFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc share {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc share {{.Name}}
+
+EXAMPLES:
+   1. Remove previously shared uploads and downloads that have expired.
+       $ mc share {{.Name}}
+`,
+}
+
+// shareCleanMessage container for share clean messages.
+type shareCleanMessage struct {
+	Status    string `json:"status"`
+	Uploads   int    `json:"uploads"`
+	Downloads int    `json:"downloads"`
+}
+
+// String colorized share clean message.
+func (s shareCleanMessage) String() string {
+	return console.Colorize("Share", fmt.Sprintf("Removed %d expired upload and %d expired download shares.", s.Uploads, s.Downloads))
+}
+
+// JSON jsonified share clean message.
+func (s shareCleanMessage) JSON() string {
+	s.Status = "success"
+	shareCleanJSONBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(shareCleanJSONBytes)
+}
+
+// validate command-line args.
+func checkShareCleanSyntax(ctx *cli.Context) {
+	if ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "clean", 1) // last argument is exit code.
+	}
+}
+
+// main entry point for share clean.
+func mainShareClean(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// validate command-line args.
+	checkShareCleanSyntax(ctx)
+
+	// Additional command speific theme customization.
+	shareSetColor()
+
+	// Initialize share config folder.
+	initShareConfig()
+
+	uploads, downloads, err := pruneExpiredShares()
+	fatalIf(err.Trace(), "Unable to remove expired shares.")
+
+	printMsg(shareCleanMessage{Uploads: uploads, Downloads: downloads})
+}
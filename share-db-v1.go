@@ -1,5 +1,5 @@
 /*
- * Minio Client (C) 2014, 2015 Minio, Inc.
+ * Minio Client (C) 2015 Minio, Inc.
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
@@ -17,118 +17,70 @@
 package main
 
 import (
-	"os"
-	"sync"
+	"encoding/json"
+	"io/ioutil"
 	"time"
 
 	"github.com/minio/minio-xl/pkg/probe"
-	"github.com/minio/minio-xl/pkg/quick"
 )
 
-// shareEntryV1 - container for each download/upload entries.
+// shareEntryV1 - share entry as persisted by older versions of mc.
 type shareEntryV1 struct {
-	URL         string        `json:"share"` // Object URL.
+	URL         string        `json:"share"`
 	Date        time.Time     `json:"date"`
 	Expiry      time.Duration `json:"expiry"`
-	ContentType string        `json:"contentType,omitempty"` // Only used by upload cmd.
+	ContentType string        `json:"contentType,omitempty"`
 }
 
-// JSON file to persist previously shared uploads.
+// shareDBV1 - share db as persisted by older versions of mc, only used for migration.
 type shareDBV1 struct {
-	Version string `json:"version"`
-	mutex   *sync.Mutex
-
-	// key is unique share URL.
-	Shares map[string]shareEntryV1 `json:"shares"`
-}
-
-// Instantiate a new uploads structure for persistence.
-func newShareDBV1() *shareDBV1 {
-	s := &shareDBV1{
-		Version: "1",
-	}
-	s.Shares = make(map[string]shareEntryV1)
-	s.mutex = &sync.Mutex{}
-	return s
+	Version string                  `json:"version"`
+	Shares  map[string]shareEntryV1 `json:"shares"`
 }
 
-// Set upload info for each share.
-func (s *shareDBV1) Set(objectURL string, shareURL string, expiry time.Duration, contentType string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.Shares[shareURL] = shareEntryV1{
-		URL:         objectURL,
-		Date:        time.Now().UTC(),
-		Expiry:      expiry,
-		ContentType: contentType,
+// migrateShareDBV1ToV2 - upgrade a version 1 share db, all entries are kept.
+func migrateShareDBV1ToV2(data []byte) (map[string]shareEntryV2, *probe.Error) {
+	shareDBV1 := shareDBV1{}
+	if e := json.Unmarshal(data, &shareDBV1); e != nil {
+		return nil, probe.NewError(e)
 	}
-}
-
-// Delete upload info if it exists.
-func (s *shareDBV1) Delete(objectURL string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	delete(s.Shares, objectURL)
-}
-
-// Delete all expired uploads.
-func (s *shareDBV1) deleteAllExpired() {
-	for shareURL, share := range s.Shares {
-		if (share.Expiry - time.Since(share.Date)) <= 0 {
-			// Expired entry. Safe to drop.
-			delete(s.Shares, shareURL)
+	shares := make(map[string]shareEntryV2)
+	for shareURL, entry := range shareDBV1.Shares {
+		shares[shareURL] = shareEntryV2{
+			URL:         entry.URL,
+			Date:        entry.Date,
+			Expiry:      entry.Expiry,
+			ContentType: entry.ContentType,
 		}
 	}
+	return shares, nil
 }
 
-// Load shareDB entries from disk. Any entries held in memory are reset.
-func (s *shareDBV1) Load(filename string) *probe.Error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// Check if the db file exist.
-	if _, err := os.Stat(filename); err != nil {
-		return probe.NewError(err)
+// migrateShareDB - rewrite an older share db file in the current format, all entries
+// including expired ones are kept. Returns false if the file needs no migration.
+func migrateShareDB(filename string) (bool, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return false, probe.NewError(e)
 	}
-
-	// Initialize and load using quick package.
-	qs, err := quick.New(newShareDBV1())
-	if err != nil {
-		return err.Trace(filename)
-	}
-	err = qs.Load(filename)
-	if err != nil {
-		return err.Trace(filename)
+	if shareDBVersion(data) != "1" {
+		return false, nil
 	}
 
-	// Copy map over.
-	for k, v := range qs.Data().(*shareDBV1).Shares {
-		s.Shares[k] = v
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return false, err.Trace(filename)
 	}
+	defer unlock()
 
-	// Filter out expired entries and save changes back to disk.
-	s.deleteAllExpired()
-	s.save(filename)
-
-	return nil
-}
-
-// Persist share uploads to disk.
-func (s shareDBV1) save(filename string) *probe.Error {
-	// Initialize a new quick file.
-	qs, err := quick.New(s)
+	shares, err := loadShares(filename)
 	if err != nil {
-		return err.Trace(filename)
+		return false, err.Trace(filename)
 	}
-	return qs.Save(filename).Trace(filename)
-}
-
-// Persist share uploads to disk.
-func (s shareDBV1) Save(filename string) *probe.Error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	return s.save(filename)
+	db := newShareDBV2()
+	db.Shares = shares
+	if err = db.save(filename); err != nil {
+		return false, err.Trace(filename)
+	}
+	return true, nil
 }
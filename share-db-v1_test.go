@@ -0,0 +1,109 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// shareDBV1Data - share db as written by older versions of mc.
+const shareDBV1Data = `{
+	"version": "1",
+	"shares": {
+		"https://active": {
+			"share": "s3/bucket/active",
+			"date": "2015-12-31T23:00:00Z",
+			"expiry": 604800000000000
+		},
+		"https://expired": {
+			"share": "s3/bucket/expired",
+			"date": "2015-01-01T00:00:00Z",
+			"expiry": 3600000000000
+		},
+		"https://upload": {
+			"share": "s3/bucket/upload",
+			"date": "2015-12-31T23:00:00+05:30",
+			"expiry": 604800000000000,
+			"contentType": "image/jpeg"
+		}
+	}
+}`
+
+func (s *TestSuite) TestShareDBMigrateV1(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "share-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "uploads.json")
+	c.Assert(ioutil.WriteFile(shareFile, []byte(shareDBV1Data), 0600), IsNil)
+
+	// V1 files are readable as is.
+	shares, err := loadShares(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(len(shares), Equals, 3)
+
+	migrated, err := migrateShareDB(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(migrated, Equals, true)
+
+	data, e := ioutil.ReadFile(shareFile)
+	c.Assert(e, IsNil)
+	c.Assert(shareDBVersion(data), Equals, "2")
+
+	// All entries survive, including expired ones.
+	shares, err = loadShares(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(len(shares), Equals, 3)
+	c.Assert(shares["https://expired"].URL, Equals, "s3/bucket/expired")
+	c.Assert(shares["https://expired"].Expiry, Equals, time.Hour)
+	c.Assert(shares["https://upload"].ContentType, Equals, "image/jpeg")
+	c.Assert(shares["https://active"].Date.Equal(time.Date(2015, 12, 31, 23, 0, 0, 0, time.UTC)), Equals, true)
+
+	// Already migrated.
+	migrated, err = migrateShareDB(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(migrated, Equals, false)
+}
+
+func (s *TestSuite) TestShareDBNewerVersion(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "share-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "downloads.json")
+	newerData := []byte(`{"version": "99", "shares": {}, "extra": true}`)
+	c.Assert(ioutil.WriteFile(shareFile, newerData, 0600), IsNil)
+	// A valid older backup must not be restored over the newer file.
+	c.Assert(ioutil.WriteFile(getBackupFile(shareFile), []byte(shareDBV1Data), 0600), IsNil)
+
+	_, err := loadShares(shareFile)
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError().Error(), Matches, ".*Please upgrade mc.*")
+
+	c.Assert(newShareDBV2().Save(shareFile), Not(IsNil))
+	_, err = migrateShareDB(shareFile)
+	c.Assert(err, IsNil)
+
+	data, e := ioutil.ReadFile(shareFile)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, string(newerData))
+}
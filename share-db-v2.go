@@ -0,0 +1,242 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// shareEntryV2 - container for each download/upload entries.
+type shareEntryV2 struct {
+	URL         string        `json:"share"` // Object URL.
+	Date        time.Time     `json:"date"`
+	Expiry      time.Duration `json:"expiry"`
+	ContentType string        `json:"contentType,omitempty"` // Only used by upload cmd.
+	// Conditions of upload shares besides the content type, size range in bytes.
+	MinSize   int64  `json:"minSize,omitempty"`
+	MaxSize   int64  `json:"maxSize,omitempty"`
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// Short link registered with the link shortener, only used by download cmd.
+	ShortURL string `json:"shortURL,omitempty"`
+}
+
+// JSON file to persist previously shared uploads.
+type shareDBV2 struct {
+	Version string `json:"version"`
+	mutex   *sync.Mutex
+
+	// key is unique share URL.
+	Shares map[string]shareEntryV2 `json:"shares"`
+
+	// share URLs deleted since load, not to be merged back from disk.
+	deleted map[string]bool
+}
+
+// Instantiate a new uploads structure for persistence.
+func newShareDBV2() *shareDBV2 {
+	s := &shareDBV2{
+		Version: globalShareDBVersion,
+	}
+	s.Shares = make(map[string]shareEntryV2)
+	s.deleted = make(map[string]bool)
+	s.mutex = &sync.Mutex{}
+	return s
+}
+
+// Set share info for each share, shared now.
+func (s *shareDBV2) Set(shareURL string, share shareEntryV2) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	share.Date = time.Now().UTC()
+	s.Shares[shareURL] = share
+	delete(s.deleted, shareURL)
+}
+
+// Delete upload info if it exists.
+func (s *shareDBV2) Delete(objectURL string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.Shares, objectURL)
+	s.deleted[objectURL] = true
+}
+
+// isShareExpired - share has no time left at now, compared in UTC so entries saved
+// from a different timezone expire at the same instant.
+func isShareExpired(share shareEntryV2, now time.Time) bool {
+	return share.Expiry-now.UTC().Sub(share.Date.UTC()) <= 0
+}
+
+// Delete all expired uploads, returns number of entries deleted.
+func (s *shareDBV2) deleteAllExpired(now time.Time) int {
+	pruned := 0
+	for shareURL, share := range s.Shares {
+		if isShareExpired(share, now) {
+			// Expired entry. Safe to drop.
+			delete(s.Shares, shareURL)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// shareDBVersion - version of a share db file, empty if it can not be read.
+func shareDBVersion(data []byte) string {
+	db := struct {
+		Version string `json:"version"`
+	}{}
+	if e := json.Unmarshal(data, &db); e != nil {
+		return ""
+	}
+	return db.Version
+}
+
+// decodeShareDB - decode share entries of any known version, older versions are migrated.
+func decodeShareDB(filename string, data []byte) (map[string]shareEntryV2, *probe.Error) {
+	switch shareDBVersion(data) {
+	case "1":
+		return migrateShareDBV1ToV2(data)
+	case globalShareDBVersion:
+		db := newShareDBV2()
+		if e := json.Unmarshal(data, db); e != nil {
+			return nil, probe.NewError(e)
+		}
+		return db.Shares, nil
+	case "":
+		return nil, probe.NewError(errors.New("Share database ‘" + filename + "’ is corrupt."))
+	}
+	return nil, errShareDBVersion(filename, shareDBVersion(data)).Trace(filename)
+}
+
+// loadShares - read share entries from disk, recover from backup if the db file is corrupt.
+func loadShares(filename string) (map[string]shareEntryV2, *probe.Error) {
+	// A newer version is never recovered from an older backup, entries would be lost.
+	if data, e := ioutil.ReadFile(filename); e == nil {
+		if version := shareDBVersion(data); version != "" && version != "1" && version != globalShareDBVersion {
+			return nil, errShareDBVersion(filename, version).Trace(filename)
+		}
+	}
+	var shares map[string]shareEntryV2
+	err := loadFileWithBackup(filename, func(filename string) *probe.Error {
+		data, e := ioutil.ReadFile(filename)
+		if e != nil {
+			return probe.NewError(e)
+		}
+		var err *probe.Error
+		shares, err = decodeShareDB(filename, data)
+		return err.Trace(filename)
+	})
+	if err != nil {
+		return nil, err.Trace(filename)
+	}
+	return shares, nil
+}
+
+// Load shareDB entries from disk. Any entries held in memory are reset.
+func (s *shareDBV2) Load(filename string) *probe.Error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	shares, err := loadShares(filename)
+	if err != nil {
+		return err.Trace(filename)
+	}
+
+	// Copy map over.
+	s.Shares = make(map[string]shareEntryV2)
+	s.deleted = make(map[string]bool)
+	for k, v := range shares {
+		s.Shares[k] = v
+	}
+
+	// Filter out expired entries, they are removed from disk by Prune.
+	s.deleteAllExpired(time.Now())
+
+	return nil
+}
+
+// Prune expired entries from disk, returns number of entries pruned.
+func (s *shareDBV2) Prune(filename string) (int, *probe.Error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return 0, err.Trace(filename)
+	}
+	defer unlock()
+
+	// Always prune the latest state on disk, concurrent runs may have added entries.
+	shares, err := loadShares(filename)
+	if err != nil {
+		return 0, err.Trace(filename)
+	}
+	db := newShareDBV2()
+	db.Shares = shares
+	pruned := db.deleteAllExpired(time.Now())
+	if pruned == 0 {
+		return 0, nil
+	}
+	if err = db.save(filename); err != nil {
+		return 0, err.Trace(filename)
+	}
+	return pruned, nil
+}
+
+// Persist share uploads to disk.
+func (s shareDBV2) save(filename string) *probe.Error {
+	return saveFileAtomic(filename, s).Trace(filename)
+}
+
+// Persist share uploads to disk. Entries saved by concurrent runs since load are kept.
+func (s shareDBV2) Save(filename string) *probe.Error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err.Trace(filename)
+	}
+	defer unlock()
+
+	shares, err := loadShares(filename)
+	if err != nil {
+		if !os.IsNotExist(err.ToGoError()) {
+			return err.Trace(filename)
+		}
+		shares = make(map[string]shareEntryV2)
+	}
+	for shareURL := range s.deleted {
+		delete(shares, shareURL)
+	}
+	// Entries held in memory take precedence.
+	for k, v := range s.Shares {
+		shares[k] = v
+	}
+	db := newShareDBV2()
+	db.Shares = shares
+	db.deleteAllExpired(time.Now())
+	return db.save(filename)
+}
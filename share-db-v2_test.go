@@ -0,0 +1,135 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestShareDB - share DB with entries expired, active, and at the expiry boundary relative to now.
+func newTestShareDB(now time.Time) *shareDBV2 {
+	shareDB := newShareDBV2()
+	shareDB.Shares["https://expired"] = shareEntryV2{URL: "s3/bucket/expired", Date: now.Add(-2 * time.Hour), Expiry: time.Hour}
+	shareDB.Shares["https://active"] = shareEntryV2{URL: "s3/bucket/active", Date: now.Add(-30 * time.Minute), Expiry: time.Hour}
+	// Exactly at expiry there is no time left.
+	shareDB.Shares["https://boundary"] = shareEntryV2{URL: "s3/bucket/boundary", Date: now.Add(-time.Hour), Expiry: time.Hour}
+	shareDB.Shares["https://almost"] = shareEntryV2{URL: "s3/bucket/almost", Date: now.Add(-time.Hour + time.Nanosecond), Expiry: time.Hour}
+	// Entries created in other timezones.
+	shareDB.Shares["https://active-ist"] = shareEntryV2{URL: "s3/bucket/active-ist", Date: now.Add(-30 * time.Minute).In(time.FixedZone("IST", 5*3600+1800)), Expiry: time.Hour}
+	shareDB.Shares["https://expired-pst"] = shareEntryV2{URL: "s3/bucket/expired-pst", Date: now.Add(-90 * time.Minute).In(time.FixedZone("PST", -8*3600)), Expiry: time.Hour}
+	return shareDB
+}
+
+func (s *TestSuite) TestShareDBDeleteAllExpired(c *C) {
+	now := time.Date(2015, 12, 31, 23, 30, 0, 0, time.UTC)
+	shareDB := newTestShareDB(now)
+	c.Assert(shareDB.deleteAllExpired(now), Equals, 3)
+	c.Assert(len(shareDB.Shares), Equals, 3)
+	for _, shareURL := range []string{"https://active", "https://almost", "https://active-ist"} {
+		_, ok := shareDB.Shares[shareURL]
+		c.Assert(ok, Equals, true)
+	}
+	c.Assert(shareDB.deleteAllExpired(now), Equals, 0)
+
+	// Local time of now does not matter.
+	shareDB = newTestShareDB(now)
+	c.Assert(shareDB.deleteAllExpired(now.In(time.FixedZone("JST", 9*3600))), Equals, 3)
+}
+
+func (s *TestSuite) TestShareDBPrune(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "share-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "downloads.json")
+	// Write entries as is, Save filters expired entries.
+	c.Assert(saveFileAtomic(shareFile, newTestShareDB(time.Now().UTC())), IsNil)
+
+	pruned, err := newShareDBV2().Prune(shareFile)
+	c.Assert(err, IsNil)
+	// The almost expired entry may have expired by now.
+	c.Assert(pruned >= 3 && pruned <= 4, Equals, true)
+
+	shares, err := loadShares(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(len(shares), Equals, 6-pruned)
+	_, ok := shares["https://active"]
+	c.Assert(ok, Equals, true)
+
+	pruned, err = newShareDBV2().Prune(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(pruned, Equals, 0)
+	_, e = os.Stat(shareFile + lockFileSuffix)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
+
+func (s *TestSuite) TestShareDBConcurrentSave(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "share-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	shareFile := filepath.Join(root, "uploads.json")
+	c.Assert(saveFileAtomic(shareFile, newTestShareDB(time.Now().UTC())), IsNil)
+
+	// Concurrent runs sharing and pruning never lose each others entries.
+	wg := &sync.WaitGroup{}
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			shareDB := newShareDBV2()
+			if err := shareDB.Load(shareFile); err != nil {
+				errs <- err.ToGoError()
+				return
+			}
+			shareDB.Set("https://"+strconv.Itoa(i), shareEntryV2{URL: "s3/bucket/" + strconv.Itoa(i), Expiry: time.Hour})
+			if err := shareDB.Save(shareFile); err != nil {
+				errs <- err.ToGoError()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := newShareDBV2().Prune(shareFile); err != nil {
+				errs <- err.ToGoError()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		c.Assert(e, IsNil)
+	}
+
+	shareDB := newShareDBV2()
+	c.Assert(shareDB.Load(shareFile), IsNil)
+	for i := 0; i < 10; i++ {
+		_, ok := shareDB.Shares["https://"+strconv.Itoa(i)]
+		c.Assert(ok, Equals, true)
+	}
+	_, ok := shareDB.Shares["https://active"]
+	c.Assert(ok, Equals, true)
+	_, ok = shareDB.Shares["https://expired"]
+	c.Assert(ok, Equals, false)
+}
@@ -64,6 +64,10 @@ EXAMPLES:
 
    4. Share all objects under this folder and all its sub-folders with 5 days expiry.
       $ mc share {{.Name}} --recursive --expire=120h s3/backup/
+
+   5. Share this object with a short link, once a link shortener is set with ‘mc config shortener’.
+      $ mc config shortener set https://sho.rt/api/links 9c4e1ab2f7
+      $ mc share {{.Name}} s3/backup/2006-Mar-1/backup.tar.gz
 `,
 }
 
@@ -97,15 +101,15 @@ func checkShareDownloadSyntax(ctx *cli.Context) {
 	}
 }
 
-// doShareURL share files from target.
-func doShareDownloadURL(targetURL string, isRecursive bool, expiry time.Duration) *probe.Error {
+// doShareURL share files from target, registering every share URL with shortener if not nil.
+func doShareDownloadURL(targetURL string, isRecursive bool, expiry time.Duration, shortener *shortenerConfig) *probe.Error {
 	clnt, err := newClient(targetURL)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
 
 	// Load previously saved upload-shares. Add new entries and write it back.
-	shareDB := newShareDBV1()
+	shareDB := newShareDBV2()
 	shareDownloadsFile := getShareDownloadsFile()
 	err = shareDB.Load(shareDownloadsFile)
 	if err != nil {
@@ -114,7 +118,9 @@ func doShareDownloadURL(targetURL string, isRecursive bool, expiry time.Duration
 
 	// Generate share URL for each target.
 	incomplete := false
-	for content := range clnt.List(isRecursive, incomplete) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for content := range clnt.List(isRecursive, incomplete, doneCh) {
 		if content.Err != nil {
 			return content.Err.Trace(clnt.GetURL().String())
 		}
@@ -136,13 +142,13 @@ func doShareDownloadURL(targetURL string, isRecursive bool, expiry time.Duration
 		}
 
 		// Make new entries to shareDB.
-		contentType := "" // Not useful for download shares.
-		shareDB.Set(objectURL, shareURL, expiry, contentType)
+		shortURL := shareShortURL(shortener, shareURL, expiry)
+		shareDB.Set(shareURL, shareEntryV2{URL: objectURL, Expiry: expiry, ShortURL: shortURL})
 		printMsg(shareMesssage{
-			ObjectURL:   objectURL,
-			ShareURL:    shareURL,
-			TimeLeft:    expiry,
-			ContentType: contentType,
+			ObjectURL: objectURL,
+			ShareURL:  shareURL,
+			TimeLeft:  expiry,
+			ShortURL:  shortURL,
 		})
 	}
 
@@ -161,6 +167,10 @@ func mainShareDownload(ctx *cli.Context) {
 	// Initialize share config folder.
 	initShareConfig()
 
+	// Expired shares are pruned on every share command.
+	_, _, err := pruneExpiredShares()
+	fatalIf(err.Trace(), "Unable to remove expired shares.")
+
 	// Additional command speific theme customization.
 	shareSetColor()
 
@@ -173,8 +183,11 @@ func mainShareDownload(ctx *cli.Context) {
 		fatalIf(probe.NewError(e), "Unable to parse expire=‘"+ctx.String("expire")+"’.")
 	}
 
+	shortener, err := getShortener()
+	fatalIf(err.Trace(), "Unable to load link shortener of config ‘"+mustGetMcConfigPath()+"’.")
+
 	for _, targetURL := range ctx.Args() {
-		err := doShareDownloadURL(targetURL, isRecursive, expiry)
+		err := doShareDownloadURL(targetURL, isRecursive, expiry, shortener)
 		fatalIf(err.Trace(targetURL), "Unable to share target ‘"+targetURL+"’.")
 	}
 }
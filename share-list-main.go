@@ -76,7 +76,7 @@ func doShareList(cmd string) *probe.Error {
 	downloadsFile := getShareDownloadsFile()
 
 	// Load previously saved upload-shares.
-	shareDB := newShareDBV1()
+	shareDB := newShareDBV2()
 
 	// if upload - read uploads file.
 	if cmd == "upload" {
@@ -99,6 +99,10 @@ func doShareList(cmd string) *probe.Error {
 			ShareURL:    shareURL,
 			TimeLeft:    share.Expiry - time.Since(share.Date),
 			ContentType: share.ContentType,
+			MinSize:     share.MinSize,
+			MaxSize:     share.MaxSize,
+			KeyPrefix:   share.KeyPrefix,
+			ShortURL:    share.ShortURL,
 		})
 	}
 	return nil
@@ -118,6 +122,10 @@ func mainShareList(ctx *cli.Context) {
 	// Initialize share config folder.
 	initShareConfig()
 
+	// Expired shares are pruned on every share command.
+	_, _, err := pruneExpiredShares()
+	fatalIf(err.Trace(), "Unable to remove expired shares.")
+
 	// List shares.
 	fatalIf(doShareList(ctx.Args().First()).Trace(), "Unable to list previously shared URLs.")
 }
@@ -44,6 +44,8 @@ var shareCmd = cli.Command{
 		shareDownload,
 		shareUpload,
 		shareList,
+		shareRefresh,
+		shareClean,
 	},
 	CustomHelpTemplate: `NAME:
    {{.Name}} - {{.Usage}}
@@ -74,6 +76,18 @@ func migrateShare() {
 		fatalIf(probe.NewError(e), "Unable to delete old ‘"+oldShareFile+"’.")
 		console.Infof("Removed older version of share ‘%s’ file.\n", oldShareFile)
 	}
+
+	// Upgrade uploads and downloads databases written by older versions.
+	for _, shareFile := range []string{getShareUploadsFile(), getShareDownloadsFile()} {
+		if _, e := os.Stat(shareFile); e != nil {
+			continue
+		}
+		migrated, err := migrateShareDB(shareFile)
+		fatalIf(err.Trace(shareFile), "Unable to migrate share database ‘"+shareFile+"’.")
+		if migrated {
+			console.Infof("Successfully migrated ‘%s’ to the latest version.\n", shareFile)
+		}
+	}
 }
 
 // mainShare - main handler for mc share command.
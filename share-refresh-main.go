@@ -0,0 +1,198 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	shareRefreshFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of share refresh.",
+		},
+		cli.StringFlag{
+			Name:  "extend, E",
+			Usage: "Set new expiry in NN[h|m|s], defaults to the expiry the URL was shared with.",
+		},
+		cli.BoolFlag{
+			Name:  "prune",
+			Usage: "Remove shares of objects that no longer exist.",
+		},
+	}
+)
+
+// Regenerate previously shared download URLs.
+var shareRefresh = cli.Command{
+	Name:   "refresh",
+	Usage:  "Regenerate previously shared download URLs.",
+	Action: mainShareRefresh,
+	Flags:  append(shareRefreshFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc share {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc share {{.Name}} [OPTIONS] SHARE-URL [SHARE-URL...]
+
+OPTIONS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Regenerate a shared download URL with the expiry it was shared with.
+      $ mc share {{.Name}} https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz?X-Amz-Algorithm=...
+
+   2. Regenerate a shared download URL expiring in 2 days from now.
+      $ mc share {{.Name}} --extend=48h https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz?X-Amz-Algorithm=...
+
+   3. Regenerate a shared download URL, remove it if its object was deleted meanwhile.
+      $ mc share {{.Name}} --prune https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz?X-Amz-Algorithm=...
+`,
+}
+
+// checkShareRefreshSyntax - validate command-line args.
+func checkShareRefreshSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "refresh", 1) // last argument is exit code.
+	}
+
+	extendArg := ctx.String("extend")
+	if extendArg == "" {
+		return
+	}
+	expiry, e := time.ParseDuration(extendArg)
+	fatalIf(probe.NewError(e), "Unable to parse extend=‘"+extendArg+"’.")
+	if expiry.Seconds() < 1 {
+		fatalIf(errDummy().Trace(expiry.String()), "Expiry cannot be lesser than 1 second.")
+	}
+	if expiry.Seconds() > 604800 {
+		fatalIf(errDummy().Trace(expiry.String()), "Expiry cannot be larger than 7 days.")
+	}
+}
+
+// lookupShareDownload - previously shared download, expired entries not yet pruned from disk are found too.
+func lookupShareDownload(filename, shareURL string) (shareEntryV2, *probe.Error) {
+	shares, err := loadShares(filename)
+	if err != nil {
+		return shareEntryV2{}, err.Trace(filename)
+	}
+	share, ok := shares[shareURL]
+	if !ok {
+		return shareEntryV2{}, errShareNotFound(shareURL).Trace(filename)
+	}
+	return share, nil
+}
+
+// refreshShareDownload - share the object of a previous download share again for expiry. The new
+// share URL replaces the old entry, keeping its object URL. Returns client.PathNotFound if the
+// object no longer exists.
+func refreshShareDownload(filename, shareURL string, share shareEntryV2, clnt client.Client, expiry time.Duration) (string, *probe.Error) {
+	if _, err := clnt.Stat(); err != nil {
+		return "", err.Trace(share.URL)
+	}
+	newShareURL, err := clnt.ShareDownload(expiry)
+	if err != nil {
+		return "", err.Trace(share.URL, "expiry="+expiry.String())
+	}
+
+	shareDB := newShareDBV2()
+	if err = shareDB.Load(filename); err != nil {
+		return "", err.Trace(filename)
+	}
+	shareDB.Delete(shareURL)
+	shareDB.Set(newShareURL, shareEntryV2{URL: share.URL, Expiry: expiry})
+	if err = shareDB.Save(filename); err != nil {
+		return "", err.Trace(filename)
+	}
+	return newShareURL, nil
+}
+
+// removeShareDownload - remove a previous download share from disk.
+func removeShareDownload(filename, shareURL string) *probe.Error {
+	shareDB := newShareDBV2()
+	if err := shareDB.Load(filename); err != nil {
+		return err.Trace(filename)
+	}
+	shareDB.Delete(shareURL)
+	return shareDB.Save(filename).Trace(filename)
+}
+
+// doShareRefresh - regenerate a download share, a zero expiry keeps the expiry it was shared with.
+func doShareRefresh(shareURL string, expiry time.Duration, isPrune bool) *probe.Error {
+	shareDownloadsFile := getShareDownloadsFile()
+	share, err := lookupShareDownload(shareDownloadsFile, shareURL)
+	if err != nil {
+		return err.Trace(shareURL)
+	}
+	if expiry == 0 {
+		expiry = share.Expiry
+	}
+
+	clnt, err := newClient(share.URL)
+	if err != nil {
+		return err.Trace(share.URL)
+	}
+	newShareURL, err := refreshShareDownload(shareDownloadsFile, shareURL, share, clnt, expiry)
+	if err != nil {
+		if _, ok := err.ToGoError().(client.PathNotFound); ok && isPrune {
+			errorIf(err.Trace(shareURL), "Shared object ‘"+share.URL+"’ no longer exists.")
+			console.Infof("Removed share of missing object ‘%s’.\n", share.URL)
+			return removeShareDownload(shareDownloadsFile, shareURL).Trace(shareURL)
+		}
+		return err.Trace(shareURL)
+	}
+	printMsg(shareMesssage{
+		ObjectURL: share.URL,
+		ShareURL:  newShareURL,
+		TimeLeft:  expiry,
+	})
+	return nil
+}
+
+// main for share refresh.
+func mainShareRefresh(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check input arguments.
+	checkShareRefreshSyntax(ctx)
+
+	// Initialize share config folder.
+	initShareConfig()
+
+	// Additional command speific theme customization.
+	shareSetColor()
+
+	// Expired shares are not pruned beforehand, they are the ones to refresh.
+	var expiry time.Duration
+	if ctx.String("extend") != "" {
+		var e error
+		expiry, e = time.ParseDuration(ctx.String("extend"))
+		fatalIf(probe.NewError(e), "Unable to parse extend=‘"+ctx.String("extend")+"’.")
+	}
+	isPrune := ctx.Bool("prune")
+
+	for _, shareURL := range ctx.Args() {
+		err := doShareRefresh(shareURL, expiry, isPrune)
+		fatalIf(err.Trace(shareURL), "Unable to refresh share ‘"+shareURL+"’.")
+	}
+}
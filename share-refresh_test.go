@@ -0,0 +1,94 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestShareRefresh(c *C) {
+	handler := &objectStoreHandler{objects: make(map[string]storedObject)}
+	handler.objects["/bucket/object"] = storedObject{data: []byte("hello world")}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	root, e := ioutil.TempDir(os.TempDir(), "share-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	// Shares as written by earlier runs, the expired ones are still on disk.
+	objectURL := server.URL + "/bucket/object"
+	missingURL := server.URL + "/bucket/missing"
+	shareFile := filepath.Join(root, "downloads.json")
+	shareDB := newShareDBV2()
+	shareDB.Shares["https://expired"] = shareEntryV2{URL: objectURL, Date: time.Now().UTC().Add(-2 * time.Hour), Expiry: time.Hour}
+	shareDB.Shares["https://missing"] = shareEntryV2{URL: missingURL, Date: time.Now().UTC(), Expiry: time.Hour}
+	c.Assert(saveFileAtomic(shareFile, shareDB), IsNil)
+
+	_, err := lookupShareDownload(shareFile, "https://unknown")
+	c.Assert(err, Not(IsNil))
+	share, err := lookupShareDownload(shareFile, "https://expired")
+	c.Assert(err, IsNil)
+	c.Assert(share.URL, Equals, objectURL)
+
+	conf := new(client.Config)
+	conf.HostURL = objectURL
+	conf.AccessKey = "Q3AM3UQ867SPQQA43P2F"
+	conf.SecretKey = "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG"
+	conf.Signature = "S3v4"
+	clnt, err := s3.New(conf)
+	c.Assert(err, IsNil)
+	shareURL, err := refreshShareDownload(shareFile, "https://expired", share, clnt, 2*time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(shareURL, Not(Equals), "https://expired")
+
+	shares, err := loadShares(shareFile)
+	c.Assert(err, IsNil)
+	_, ok := shares["https://expired"]
+	c.Assert(ok, Equals, false)
+	refreshed, ok := shares[shareURL]
+	c.Assert(ok, Equals, true)
+	c.Assert(refreshed.URL, Equals, objectURL)
+	c.Assert(refreshed.Expiry, Equals, 2*time.Hour)
+	c.Assert(time.Since(refreshed.Date) < time.Minute, Equals, true)
+
+	// Shares of objects removed meanwhile are reported, and left alone until pruned.
+	share, err = lookupShareDownload(shareFile, "https://missing")
+	c.Assert(err, IsNil)
+	conf.HostURL = missingURL
+	clnt, err = s3.New(conf)
+	c.Assert(err, IsNil)
+	_, err = refreshShareDownload(shareFile, "https://missing", share, clnt, time.Hour)
+	c.Assert(err, Not(IsNil))
+	_, ok = err.ToGoError().(client.PathNotFound)
+	c.Assert(ok, Equals, true)
+	_, err = lookupShareDownload(shareFile, "https://missing")
+	c.Assert(err, IsNil)
+
+	c.Assert(removeShareDownload(shareFile, "https://missing"), IsNil)
+	shares, err = loadShares(shareFile)
+	c.Assert(err, IsNil)
+	c.Assert(len(shares), Equals, 1)
+	c.Assert(shares[shareURL].URL, Equals, objectURL)
+}
@@ -0,0 +1,123 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// shortenerTimeout - maximum time spent registering a share URL with the link shortener.
+var shortenerTimeout = 10 * time.Second
+
+// shortenerConfig - link shortener service shared download URLs are registered with.
+type shortenerConfig struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+}
+
+// shortenerRequest - posted to the link shortener for every share URL, the short link
+// should stop redirecting once the share expires.
+type shortenerRequest struct {
+	URL    string `json:"url"`
+	Expiry int64  `json:"expiry"` // seconds.
+}
+
+// shortenerResponse - short link redirecting to the share URL, as returned by the link shortener.
+type shortenerResponse struct {
+	ShortURL string `json:"shortURL"`
+}
+
+// checkShortenerURL - only http(s) URLs are accepted, for the link shortener and the short links it returns.
+func checkShortenerURL(urlStr string) *probe.Error {
+	u, e := url.Parse(urlStr)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidShortenerURL(urlStr).Trace()
+	}
+	return nil
+}
+
+// getShortener - link shortener of the config, nil if none is set.
+func getShortener() (*shortenerConfig, *probe.Error) {
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	return mcCfg.Shortener, nil
+}
+
+// shortenShareURL - register shareURL valid for expiry with shortener, returns its short link.
+func shortenShareURL(shortener *shortenerConfig, shareURL string, expiry time.Duration) (string, *probe.Error) {
+	body, e := json.Marshal(shortenerRequest{URL: shareURL, Expiry: int64(expiry.Seconds())})
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	req, e := http.NewRequest("POST", shortener.URL, bytes.NewReader(body))
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if shortener.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+shortener.Token)
+	}
+	httpClient := &http.Client{Timeout: shortenerTimeout}
+	resp, e := httpClient.Do(req)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", probe.NewError(errors.New("Link shortener responded with ‘" + resp.Status + "’."))
+	}
+	var shortened shortenerResponse
+	if e = json.NewDecoder(resp.Body).Decode(&shortened); e != nil {
+		return "", probe.NewError(e)
+	}
+	if checkShortenerURL(shortened.ShortURL) != nil {
+		return "", probe.NewError(errors.New("Link shortener returned no short link."))
+	}
+	return shortened.ShortURL, nil
+}
+
+// shareShortURL - short link of shareURL if a shortener is set. Failing to shorten only
+// warns, the share URL itself is shared then.
+func shareShortURL(shortener *shortenerConfig, shareURL string, expiry time.Duration) string {
+	if shortener == nil {
+		return ""
+	}
+	shortURL, err := shortenShareURL(shortener, shareURL, expiry)
+	if err == nil {
+		return shortURL
+	}
+	err = errShortenerFailed(shortener.URL).Trace(err.ToGoError().Error())
+	if globalJSON {
+		printErrorJSON(err, "Sharing the URL without a short link.", "warning", exitFailure)
+		return ""
+	}
+	console.Errorln(err.ToGoError().Error() + " Sharing the URL without a short link.")
+	return ""
+}
@@ -0,0 +1,99 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	. "gopkg.in/check.v1"
+)
+
+// shortenerHandler - stub link shortener, fails every request if fail is set.
+type shortenerHandler struct {
+	fail     bool
+	requests []shortenerRequest
+}
+
+func (h *shortenerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req shortenerRequest
+	if h.fail || r.Header.Get("Authorization") != "Bearer secret" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.requests = append(h.requests, req)
+	json.NewEncoder(w).Encode(shortenerResponse{ShortURL: "https://sho.rt/abc"})
+}
+
+func (s *TestSuite) TestShareDownloadShortener(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	isJSON, output := globalJSON, color.Output
+	defer func() { globalJSON, color.Output = isJSON, output }()
+	globalJSON = true
+
+	handler := &objectStoreHandler{objects: make(map[string]storedObject)}
+	handler.objects["/bucket/object"] = storedObject{data: []byte("hello world")}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("test", hostConfigV7{
+		URL:       server.URL,
+		AccessKey: "Q3AM3UQ867SPQQA43P2F",
+		SecretKey: "zuf+tfteSlswRu7BJ86wekitnifILbZam1KYY3TG",
+		API:       "S3v4",
+	}), IsNil)
+	shortenerHandler := &shortenerHandler{}
+	shortenerServer := httptest.NewServer(shortenerHandler)
+	defer shortenerServer.Close()
+	shortener := &shortenerConfig{URL: shortenerServer.URL, Token: "secret"}
+	initShareConfig()
+
+	// The short link is shown and stored besides the share URL.
+	buf := new(bytes.Buffer)
+	color.Output = buf
+	c.Assert(doShareDownloadURL("test/bucket/object", false, time.Hour, shortener), IsNil)
+	var msg shareMesssage
+	c.Assert(json.Unmarshal(buf.Bytes(), &msg), IsNil)
+	c.Assert(msg.ShortURL, Equals, "https://sho.rt/abc")
+	c.Assert(shortenerHandler.requests, DeepEquals, []shortenerRequest{{URL: msg.ShareURL, Expiry: 3600}})
+	shares, err := loadShares(getShareDownloadsFile())
+	c.Assert(err, IsNil)
+	c.Assert(shares[msg.ShareURL].ShortURL, Equals, "https://sho.rt/abc")
+
+	buf.Reset()
+	c.Assert(doShareList("download"), IsNil)
+	c.Assert(strings.Contains(buf.String(), `"shortURL":"https://sho.rt/abc"`), Equals, true)
+
+	// A failing shortener only warns, the share URL is shared without a short link.
+	shortenerHandler.fail = true
+	buf.Reset()
+	c.Assert(doShareDownloadURL("test/bucket/object", false, time.Hour, shortener), IsNil)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(len(lines), Equals, 2)
+	c.Assert(strings.Contains(lines[0], `"type":"warning"`), Equals, true)
+	msg = shareMesssage{}
+	c.Assert(json.Unmarshal([]byte(lines[1]), &msg), IsNil)
+	c.Assert(msg.ShortURL, Equals, "")
+	shares, err = loadShares(getShareDownloadsFile())
+	c.Assert(err, IsNil)
+	c.Assert(shares[msg.ShareURL].ShortURL, Equals, "")
+}
@@ -17,12 +17,19 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -36,11 +43,22 @@ var (
 			Name:  "recursive, r",
 			Usage: "Recursively upload any object matching the prefix.",
 		},
+		cli.BoolFlag{
+			Name:  "post",
+			Usage: "Generate form fields for a browser based POST upload instead of ‘curl’ command.",
+		},
+		cli.StringFlag{
+			Name:  "size-range",
+			Usage: "Restrict upload size in MIN-MAX, for example 1KB-10MB.",
+		},
 		shareFlagExpire,
 		shareFlagContentType,
 	}
 )
 
+// Maximum expiry of AWS Signature Version 4 presigned requests and policies.
+const shareMaxExpiryV4 = time.Duration(604800) * time.Second
+
 // Share documents via URL.
 var shareUpload = cli.Command{
 	Name:   "upload",
@@ -68,6 +86,15 @@ EXAMPLES:
 
    4. Generate a curl command to allow upload access to any objects matching the key prefix 'backup/'. Command expires in 2 hours.
       $ mc share {{.Name}} --recursive --expire=2h s3/backup/2007-Mar-2/backup/
+
+   5. Generate an HTML form to upload objects of at most 10MB with a browser to a folder. Form expires in 24 hours.
+      $ mc share {{.Name}} --post --recursive --expire=24h --size-range=1B-10MB s3/backup/2007-Mar-2/
+
+   6. Generate POST form fields as JSON for a single object.
+      $ mc --json share {{.Name}} --post s3/backup/2006-Mar-1/backup.tar.gz
+
+   7. Generate a curl command to allow upload access of any images of at most 10MB to a folder.
+      $ mc share {{.Name}} --recursive --content-type=image/* --size-range=0-10MB s3/backup/2007-Mar-2/
 `,
 }
 
@@ -78,51 +105,108 @@ func checkShareUploadSyntax(ctx *cli.Context) {
 		cli.ShowCommandHelpAndExit(ctx, "upload", 1) // last argument is exit code.
 	}
 
-	// Set command flags from context.
-	isRecursive := ctx.Bool("recursive")
-	expireArg := ctx.String("expire")
-
-	// Parse expiry.
-	expiry := shareDefaultExpiry
-	if expireArg != "" {
-		var e error
-		expiry, e = time.ParseDuration(expireArg)
-		fatalIf(probe.NewError(e), "Unable to parse expire=‘"+expireArg+"’.")
-	}
+	// Conditions are validated before any upload is shared.
+	options := mustGetShareUploadOptions(ctx)
+	expiry := options.Expires
 
-	// Validate expiry.
+	// Validate expiry, the alias of each target may limit it further.
 	if expiry.Seconds() < 1 {
-		fatalIf(errDummy().Trace(expiry.String()),
-			"Expiry cannot be lesser than 1 second.")
+		fatalIf(errDummy().Trace(expiry.String()), "Expiry cannot be lesser than 1 second.")
 	}
-	if expiry.Seconds() > 604800 {
-		fatalIf(errDummy().Trace(expiry.String()),
-			"Expiry cannot be larger than 7 days.")
+	if expiry > shareMaxExpiryV4 {
+		fatalIf(errDummy().Trace(expiry.String()), "Expiry cannot be larger than 7 days.")
 	}
 
 	for _, targetURL := range ctx.Args() {
 		url := client.NewURL(targetURL)
-		if strings.HasSuffix(targetURL, string(url.Separator)) && !isRecursive {
+		if strings.HasSuffix(targetURL, string(url.Separator)) && !options.Recursive {
 			fatalIf(errInvalidArgument().Trace(targetURL),
 				"Use --recursive option to generate curl command for prefixes.")
 		}
+		// Policy is signed according to the signature version of the alias.
+		if _, _, hostCfg := mustExpandAlias(targetURL); hostCfg != nil {
+			err := validateShareUploadExpiry(expiry, hostCfg.API)
+			fatalIf(err.Trace(targetURL, expiry.String()), "Unable to validate expiry for ‘"+targetURL+"’.")
+		}
 	}
 }
 
-// makeCurlCmd constructs curl command-line.
-func makeCurlCmd(key string, isRecursive bool, uploadInfo map[string]string) string {
+// validateShareUploadExpiry - validate expiry against limits of the signature version.
+func validateShareUploadExpiry(expiry time.Duration, api string) *probe.Error {
+	if expiry < time.Second {
+		return errInvalidArgument().Trace(expiry.String())
+	}
+	// Signature version 2 policies carry no upper limit for expiry.
+	if api != "S3v2" && expiry > shareMaxExpiryV4 {
+		return probe.NewError(errors.New("Expiry cannot be larger than 7 days for S3v4 signature.")).Trace(expiry.String(), api)
+	}
+	return nil
+}
+
+// mustGetShareUploadOptions - conditions of upload shares set by flags, exits if no upload could meet them.
+func mustGetShareUploadOptions(ctx *cli.Context) client.ShareUploadOptions {
+	options := client.ShareUploadOptions{
+		Expires:   shareDefaultExpiry,
+		Recursive: ctx.Bool("recursive"),
+		// ‘image/*’ allows any image, as ‘image/’ does.
+		ContentType: strings.TrimSuffix(ctx.String("content-type"), "*"),
+	}
+	if expireArg := ctx.String("expire"); expireArg != "" {
+		var e error
+		options.Expires, e = time.ParseDuration(expireArg)
+		fatalIf(probe.NewError(e), "Unable to parse expire=‘"+expireArg+"’.")
+	}
+	if sizeRange := ctx.String("size-range"); sizeRange != "" {
+		var err *probe.Error
+		options.MinSize, options.MaxSize, err = parseSizeRange(sizeRange)
+		fatalIf(err.Trace(sizeRange), "Unable to parse size-range=‘"+sizeRange+"’.")
+	}
+	fatalIf(options.Validate().Trace(), "Invalid conditions for upload.")
+	return options
+}
+
+// parseSizeRange - parse size range of the form MIN-MAX into bytes.
+func parseSizeRange(sizeRange string) (minSize, maxSize int64, err *probe.Error) {
+	sizes := strings.SplitN(sizeRange, "-", 2)
+	if len(sizes) != 2 {
+		return 0, 0, errInvalidArgument().Trace(sizeRange)
+	}
+	min, e := humanize.ParseBytes(strings.TrimSpace(sizes[0]))
+	if e != nil {
+		return 0, 0, probe.NewError(e).Trace(sizeRange)
+	}
+	max, e := humanize.ParseBytes(strings.TrimSpace(sizes[1]))
+	if e != nil {
+		return 0, 0, probe.NewError(e).Trace(sizeRange)
+	}
+	if max == 0 || min > max {
+		return 0, 0, errInvalidArgument().Trace(sizeRange)
+	}
+	return int64(min), int64(max), nil
+}
+
+// makePostURL constructs the URL which accepts POST uploads.
+func makePostURL(key string, uploadInfo map[string]string) string {
 	URL := client.NewURL(key)
 	postURL := URL.Scheme + URL.SchemeSeparator + URL.Host + string(URL.Separator)
 	if !isBucketVirtualStyle(URL.Host) {
 		postURL = postURL + uploadInfo["bucket"]
 	}
-	postURL += " "
-	curlCommand := "curl " + postURL
+	return postURL
+}
+
+// makeCurlCmd constructs curl command-line.
+func makeCurlCmd(key string, isRecursive bool, uploadInfo map[string]string) string {
+	curlCommand := "curl " + makePostURL(key, uploadInfo) + " "
 	for k, v := range uploadInfo {
 		if k == "key" {
 			key = v
 			continue
 		}
+		// Uploads complete a content type prefix.
+		if k == "Content-Type" && strings.HasSuffix(v, "/") {
+			v += "<TYPE>"
+		}
 		curlCommand += fmt.Sprintf("-F %s=%s ", k, v)
 	}
 	// If key starts with is enabled prefix it with the output.
@@ -135,46 +219,149 @@ func makeCurlCmd(key string, isRecursive bool, uploadInfo map[string]string) str
 	return curlCommand
 }
 
+// makePostFormData constructs POST form fields, key is substituted with the uploaded file name if recursive.
+func makePostFormData(isRecursive bool, uploadInfo map[string]string) map[string]string {
+	formData := make(map[string]string)
+	for k, v := range uploadInfo {
+		formData[k] = v
+	}
+	if isRecursive {
+		formData["key"] = formData["key"] + "${filename}"
+	}
+	return formData
+}
+
+// makePostForm constructs a ready to use HTML form for browser based uploads.
+func makePostForm(postURL string, formData map[string]string) string {
+	var fields []string
+	for k := range formData {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	form := fmt.Sprintf("<form action=\"%s\" method=\"post\" enctype=\"multipart/form-data\">\n", html.EscapeString(postURL))
+	for _, k := range fields {
+		inputType := "hidden"
+		// Uploads complete a content type prefix.
+		if k == "Content-Type" && strings.HasSuffix(formData[k], "/") {
+			inputType = "text"
+		}
+		form += fmt.Sprintf("  <input type=\"%s\" name=\"%s\" value=\"%s\" />\n", inputType, html.EscapeString(k), html.EscapeString(formData[k]))
+	}
+	// File field must be the last field of the form.
+	form += "  <input type=\"file\" name=\"file\" />\n"
+	form += "  <input type=\"submit\" value=\"Upload\" />\n"
+	form += "</form>"
+	return form
+}
+
+// sharePostMessage container for POST form share upload messages.
+type sharePostMessage struct {
+	Status    string            `json:"status"`
+	ObjectURL string            `json:"url"`
+	PostURL   string            `json:"postURL"`
+	FormData  map[string]string `json:"formData"`
+	TimeLeft  time.Duration     `json:"timeLeft"`
+}
+
+// String - Themefied string message for console printing.
+func (s sharePostMessage) String() string {
+	msg := console.Colorize("URL", fmt.Sprintf("URL: %s\n", s.ObjectURL))
+	msg += console.Colorize("Expire", fmt.Sprintf("Expire: %s\n", timeDurationToHumanizedTime(s.TimeLeft)))
+	msg += console.Colorize("Share", fmt.Sprintf("Share:\n%s\n", makePostForm(s.PostURL, s.FormData)))
+	return msg
+}
+
+// JSON - JSONified message for scripting.
+func (s sharePostMessage) JSON() string {
+	s.Status = "success"
+	sharePostMessageBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Failed to marshal into JSON.")
+
+	// Keep policy conditions and URLs readable, see shareMesssage.JSON().
+	sharePostMessageBytes = bytes.Replace(sharePostMessageBytes, []byte("\\u0026"), []byte("&"), -1)
+	sharePostMessageBytes = bytes.Replace(sharePostMessageBytes, []byte("\\u003c"), []byte("<"), -1)
+	sharePostMessageBytes = bytes.Replace(sharePostMessageBytes, []byte("\\u003e"), []byte(">"), -1)
+
+	return string(sharePostMessageBytes)
+}
+
 // save shared URL to disk.
-func saveSharedURL(objectURL string, shareURL string, expiry time.Duration, contentType string) *probe.Error {
+func saveSharedURL(shareURL string, share shareEntryV2) *probe.Error {
 	// Load previously saved upload-shares.
-	shareDB := newShareDBV1()
+	shareDB := newShareDBV2()
 	if err := shareDB.Load(getShareUploadsFile()); err != nil {
 		return err.Trace(getShareUploadsFile())
 	}
 
 	// Make new entries to uploadsDB.
-	shareDB.Set(objectURL, shareURL, expiry, contentType)
+	shareDB.Set(shareURL, share)
 	shareDB.Save(getShareUploadsFile())
 
 	return nil
 }
 
+// newUploadShareEntry - share entry recording the conditions uploads must meet.
+func newUploadShareEntry(objectURL string, options client.ShareUploadOptions, uploadInfo map[string]string) shareEntryV2 {
+	share := shareEntryV2{
+		URL:         objectURL,
+		Expiry:      options.Expires,
+		ContentType: options.ContentType,
+		MinSize:     options.MinSize,
+		MaxSize:     options.MaxSize,
+	}
+	if share.MinSize > 0 && share.MaxSize == 0 {
+		share.MaxSize = client.MaxShareUploadSize
+	}
+	if options.Recursive {
+		share.KeyPrefix = uploadInfo["key"]
+	}
+	return share
+}
+
 // doShareUploadURL uploads files to the target.
-func doShareUploadURL(objectURL string, isRecursive bool, expiry time.Duration, contentType string) *probe.Error {
+func doShareUploadURL(objectURL string, isPost bool, options client.ShareUploadOptions) *probe.Error {
 	clnt, err := newClient(objectURL)
 	if err != nil {
 		return err.Trace(objectURL)
 	}
 
 	// Generate pre-signed access info.
-	uploadInfo, err := clnt.ShareUpload(isRecursive, expiry, contentType)
+	uploadInfo, err := clnt.ShareUpload(options)
 	if err != nil {
-		return err.Trace(objectURL, "expiry="+expiry.String(), "contentType="+contentType)
+		return err.Trace(objectURL, "expiry="+options.Expires.String(), "contentType="+options.ContentType)
+	}
+	share := newUploadShareEntry(objectURL, options, uploadInfo)
+
+	if isPost {
+		postURL := makePostURL(objectURL, uploadInfo)
+		formData := makePostFormData(options.Recursive, uploadInfo)
+		printMsg(sharePostMessage{
+			ObjectURL: objectURL,
+			PostURL:   postURL,
+			FormData:  formData,
+			TimeLeft:  options.Expires,
+		})
+
+		// save shared form to disk.
+		return saveSharedURL(makePostForm(postURL, formData), share)
 	}
 
 	// Generate curl command.
-	curlCmd := makeCurlCmd(objectURL, isRecursive, uploadInfo)
+	curlCmd := makeCurlCmd(objectURL, options.Recursive, uploadInfo)
 
 	printMsg(shareMesssage{
 		ObjectURL:   objectURL,
 		ShareURL:    curlCmd,
-		TimeLeft:    expiry,
-		ContentType: contentType,
+		TimeLeft:    options.Expires,
+		ContentType: share.ContentType,
+		MinSize:     share.MinSize,
+		MaxSize:     share.MaxSize,
+		KeyPrefix:   share.KeyPrefix,
 	})
 
 	// save shared URL to disk.
-	return saveSharedURL(objectURL, curlCmd, expiry, contentType)
+	return saveSharedURL(curlCmd, share)
 }
 
 // main for share upload command.
@@ -188,22 +375,19 @@ func mainShareUpload(ctx *cli.Context) {
 	// Initialize share config folder.
 	initShareConfig()
 
+	// Expired shares are pruned on every share command.
+	_, _, err := pruneExpiredShares()
+	fatalIf(err.Trace(), "Unable to remove expired shares.")
+
 	// Additional command speific theme customization.
 	shareSetColor()
 
 	// Set command flags from context.
-	isRecursive := ctx.Bool("recursive")
-	expireArg := ctx.String("expire")
-	expiry := shareDefaultExpiry
-	contentType := ctx.String("content-type")
-	if expireArg != "" {
-		var e error
-		expiry, e = time.ParseDuration(expireArg)
-		fatalIf(probe.NewError(e), "Unable to parse expire=‘"+expireArg+"’.")
-	}
+	options := mustGetShareUploadOptions(ctx)
+	isPost := ctx.Bool("post")
 
 	for _, targetURL := range ctx.Args() {
-		err := doShareUploadURL(targetURL, isRecursive, expiry, contentType)
+		err := doShareUploadURL(targetURL, isPost, options)
 		fatalIf(err.Trace(targetURL), "Unable to generate curl command for upload ‘"+targetURL+"’.")
 	}
 }
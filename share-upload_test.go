@@ -0,0 +1,94 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseSizeRange(c *C) {
+	minSize, maxSize, err := parseSizeRange("1KB-10MB")
+	c.Assert(err, IsNil)
+	c.Assert(minSize, Equals, int64(1000))
+	c.Assert(maxSize, Equals, int64(10000000))
+
+	minSize, maxSize, err = parseSizeRange("0-1KiB")
+	c.Assert(err, IsNil)
+	c.Assert(minSize, Equals, int64(0))
+	c.Assert(maxSize, Equals, int64(1024))
+
+	for _, sizeRange := range []string{"10MB", "10MB-1KB", "0-0", "abc-1MB", "1KB-"} {
+		_, _, err = parseSizeRange(sizeRange)
+		c.Assert(err, Not(IsNil), Commentf("size-range %s", sizeRange))
+	}
+}
+
+func (s *TestSuite) TestValidateShareUploadExpiry(c *C) {
+	c.Assert(validateShareUploadExpiry(7*24*time.Hour, "S3v4"), IsNil)
+	c.Assert(validateShareUploadExpiry(7*24*time.Hour+time.Second, "S3v4"), Not(IsNil))
+	c.Assert(validateShareUploadExpiry(30*24*time.Hour, "S3v2"), IsNil)
+	c.Assert(validateShareUploadExpiry(time.Millisecond, "S3v2"), Not(IsNil))
+}
+
+func (s *TestSuite) TestMakePostForm(c *C) {
+	uploadInfo := map[string]string{
+		"bucket":    "backup",
+		"key":       "2007-Mar-2/",
+		"policy":    "eyJleHBpcmF0aW9uIjoi",
+		"signature": "sDsRmZsVIK/aGrLRaEf4Nvt5eC4=",
+	}
+	postURL := makePostURL("https://play.minio.io:9000/backup/2007-Mar-2/", uploadInfo)
+	c.Assert(postURL, Equals, "https://play.minio.io:9000/backup")
+
+	formData := makePostFormData(true, uploadInfo)
+	c.Assert(formData["key"], Equals, "2007-Mar-2/${filename}")
+	c.Assert(uploadInfo["key"], Equals, "2007-Mar-2/")
+
+	form := makePostForm(postURL, formData)
+	c.Assert(strings.HasPrefix(form, `<form action="https://play.minio.io:9000/backup" method="post" enctype="multipart/form-data">`), Equals, true)
+	c.Assert(strings.Contains(form, `<input type="hidden" name="key" value="2007-Mar-2/${filename}" />`), Equals, true)
+	c.Assert(strings.Contains(form, `<input type="hidden" name="signature" value="sDsRmZsVIK/aGrLRaEf4Nvt5eC4=" />`), Equals, true)
+	// File field must follow all other fields.
+	c.Assert(strings.Index(form, `name="file"`) > strings.Index(form, `name="signature"`), Equals, true)
+}
+
+func (s *TestSuite) TestUploadShareEntry(c *C) {
+	uploadInfo := map[string]string{"key": "2007-Mar-2/", "Content-Type": "image/"}
+	options := client.ShareUploadOptions{Expires: time.Hour, Recursive: true, ContentType: "image/", MinSize: 1024}
+	c.Assert(options.Validate(), IsNil)
+	share := newUploadShareEntry("s3/backup/2007-Mar-2/", options, uploadInfo)
+	c.Assert(share.ContentType, Equals, "image/")
+	c.Assert(share.MinSize, Equals, int64(1024))
+	c.Assert(share.MaxSize, Equals, int64(client.MaxShareUploadSize))
+	c.Assert(share.KeyPrefix, Equals, "2007-Mar-2/")
+
+	curlCmd := makeCurlCmd("https://play.minio.io:9000/backup/2007-Mar-2/", true, uploadInfo)
+	c.Assert(strings.Contains(curlCmd, "-F Content-Type=image/<TYPE> "), Equals, true)
+	form := makePostForm("https://play.minio.io:9000/backup", uploadInfo)
+	c.Assert(strings.Contains(form, `<input type="text" name="Content-Type" value="image/" />`), Equals, true)
+
+	options.Recursive = false
+	options.MaxSize = 512
+	c.Assert(options.Validate(), Not(IsNil))
+	share = newUploadShareEntry("s3/backup/2007-Mar-2/backup.tar.gz", options, uploadInfo)
+	c.Assert(share.KeyPrefix, Equals, "")
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
@@ -40,7 +41,7 @@ const (
 var (
 	shareFlagContentType = cli.StringFlag{
 		Name:  "content-type, T",
-		Usage: "Speific content-type to allow.",
+		Usage: "Specific content-type to allow, ‘image/*’ allows any image type.",
 	}
 	shareFlagExpire = cli.StringFlag{
 		Name:  "expire, E",
@@ -56,6 +57,11 @@ type shareMesssage struct {
 	ShareURL    string        `json:"share"`
 	TimeLeft    time.Duration `json:"timeLeft"`
 	ContentType string        `json:"contentType,omitempty"` // Only used by upload cmd.
+	// Conditions of upload shares besides the content type, size range in bytes.
+	MinSize   int64  `json:"minSize,omitempty"`
+	MaxSize   int64  `json:"maxSize,omitempty"`
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	ShortURL  string `json:"shortURL,omitempty"` // Only used by download cmd.
 }
 
 // String - Themefied string message for console printing.
@@ -65,6 +71,12 @@ func (s shareMesssage) String() string {
 	if s.ContentType != "" {
 		msg += console.Colorize("Content-type", fmt.Sprintf("Content-Type: %s\n", s.ContentType))
 	}
+	if s.MaxSize > 0 {
+		msg += console.Colorize("Condition", fmt.Sprintf("Size: %s - %s\n", humanize.IBytes(uint64(s.MinSize)), humanize.IBytes(uint64(s.MaxSize))))
+	}
+	if s.KeyPrefix != "" {
+		msg += console.Colorize("Condition", fmt.Sprintf("Key prefix: %s\n", s.KeyPrefix))
+	}
 
 	// Highlight <FILE> specifically. "share upload" sub-commands use this identifier.
 	shareURL := strings.Replace(s.ShareURL, "<FILE>", console.Colorize("File", "<FILE>"), 1)
@@ -72,6 +84,9 @@ func (s shareMesssage) String() string {
 	shareURL = strings.Replace(shareURL, "<NAME>", console.Colorize("File", "<NAME>"), 1)
 
 	msg += console.Colorize("Share", fmt.Sprintf("Share: %s\n", shareURL))
+	if s.ShortURL != "" {
+		msg += console.Colorize("Share", fmt.Sprintf("Short: %s\n", s.ShortURL))
+	}
 
 	return msg
 }
@@ -98,6 +113,7 @@ func shareSetColor() {
 	console.SetColor("URL", color.New(color.FgWhite, color.Bold))
 	console.SetColor("Expire", color.New(color.FgCyan))
 	console.SetColor("Content-type", color.New(color.FgBlue))
+	console.SetColor("Condition", color.New(color.FgBlue))
 	console.SetColor("Share", color.New(color.FgGreen))
 	console.SetColor("File", color.New(color.FgRed, color.Bold))
 
@@ -165,12 +181,12 @@ func isShareDownloadsExists() bool {
 
 // Initialize share uploads file.
 func initShareUploadsFile() *probe.Error {
-	return newShareDBV1().Save(getShareUploadsFile())
+	return newShareDBV2().Save(getShareUploadsFile())
 }
 
 // Initialize share downloads file.
 func initShareDownloadsFile() *probe.Error {
-	return newShareDBV1().Save(getShareDownloadsFile())
+	return newShareDBV2().Save(getShareDownloadsFile())
 }
 
 // Initialize share directory, if not done already.
@@ -196,3 +212,16 @@ func initShareConfig() {
 		console.Infof("Initialized share downloads ‘%s’ file.\n", getShareDownloadsFile())
 	}
 }
+
+// pruneExpiredShares - remove expired entries from share uploads and downloads files.
+func pruneExpiredShares() (uploads int, downloads int, err *probe.Error) {
+	uploads, err = newShareDBV2().Prune(getShareUploadsFile())
+	if err != nil {
+		return 0, 0, err.Trace(getShareUploadsFile())
+	}
+	downloads, err = newShareDBV2().Prune(getShareDownloadsFile())
+	if err != nil {
+		return uploads, 0, err.Trace(getShareDownloadsFile())
+	}
+	return uploads, downloads, nil
+}
@@ -19,27 +19,42 @@ package main
 import (
 	"os"
 	"os/signal"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
 )
 
+// forceQuitWindow - a second signal this soon after the previous one exits
+// right away, without waiting for the session to be saved.
+const forceQuitWindow = 2 * time.Second
+
 // signalTrap traps the registered signals and notifies the caller.
 func signalTrap(sig ...os.Signal) <-chan bool {
 	// channel to notify the caller.
 	trapCh := make(chan bool, 1)
 
-	go func(chan<- bool) {
-		// channel to receive signals.
-		sigCh := make(chan os.Signal, 1)
-		defer close(sigCh)
+	// channel to receive signals.
+	sigCh := make(chan os.Signal, 1)
 
-		// `signal.Notify` registers the given channel to
-		// receive notifications of the specified signals.
-		signal.Notify(sigCh, sig...)
+	// `signal.Notify` registers the given channel to
+	// receive notifications of the specified signals.
+	signal.Notify(sigCh, sig...)
 
+	go func(chan<- bool) {
 		// Wait for the signal.
 		<-sigCh
 
 		// Notify the caller.
 		trapCh <- true
+
+		// Keep trapping while the caller cleans up, quit on a quick repeat.
+		lastSignal := time.Now()
+		for range sigCh {
+			if time.Since(lastSignal) < forceQuitWindow {
+				console.Fatalln("Interrupted again, exiting without saving the session.")
+			}
+			lastSignal = time.Now()
+		}
 	}(trapCh)
 
 	return trapCh
@@ -0,0 +1,55 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// errorBudget - failures tolerated with ‘--skip-errors’ before the whole transfer is aborted.
+// Safe for concurrent use.
+type errorBudget struct {
+	limit    int64
+	failures int64 // updated atomically.
+	abort    context.CancelFunc
+}
+
+// getSessionErrorBudget - budget of ‘--skip-errors’ saved in session, calling abort once exceeded.
+// Nil if not set, every critical failure stops the transfer then.
+func getSessionErrorBudget(session *sessionV6, abort context.CancelFunc) *errorBudget {
+	limit, ok := session.Header.CommandIntFlags["skip-errors"]
+	if !ok {
+		return nil
+	}
+	return &errorBudget{limit: int64(limit), abort: abort}
+}
+
+// fail - account for a failed object, true while failures are tolerated. The first failure beyond the
+// limit aborts the transfer, in flight objects are cancelled.
+func (b *errorBudget) fail() bool {
+	failures := atomic.AddInt64(&b.failures, 1)
+	if failures == b.limit+1 {
+		b.abort()
+	}
+	return failures <= b.limit
+}
+
+// exceeded - more objects failed than tolerated.
+func (b *errorBudget) exceeded() bool {
+	return atomic.LoadInt64(&b.failures) > b.limit
+}
@@ -0,0 +1,178 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// defaultStallGrace - time a transfer may stay below ‘--min-speed’ before it is aborted.
+const defaultStallGrace = 30 * time.Second
+
+// parseMinSpeed - parse a speed such as ‘64KiB’ or ‘64KiB/s’ into bytes per second.
+func parseMinSpeed(minSpeedStr string) (int64, *probe.Error) {
+	minSpeed, e := humanize.ParseBytes(strings.TrimSuffix(strings.TrimSpace(minSpeedStr), "/s"))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	if minSpeed == 0 {
+		return 0, errInvalidArgument().Trace(minSpeedStr)
+	}
+	return int64(minSpeed), nil
+}
+
+// stallSample - bytes read at a point in time.
+type stallSample struct {
+	time  time.Time
+	bytes int64
+}
+
+// stallReader counts bytes read from a transfer source and, if minSpeed is set, aborts the
+// transfer once it stays below minSpeed bytes/s for longer than grace. A transfer which has
+// not moved a byte yet is idle, not stalled, waiting for the first byte is bounded by the
+// read timeout. A watchdog detects stalls while a read is blocked and closes the source, if
+// it can be closed, to unblock it.
+type stallReader struct {
+	io.ReadSeeker
+	url      string
+	minSpeed int64
+	grace    time.Duration
+
+	mutex sync.Mutex
+	// Bytes read within the last grace period.
+	samples []stallSample
+	// Time of the first byte read since the start or the last seek, zero if idle.
+	started time.Time
+	read    int64
+	stalled bool
+	doneCh  chan struct{}
+}
+
+// newStallReader - wrap source of the transfer of url, stall detection is off if minSpeed is not positive.
+func newStallReader(source io.ReadSeeker, url string, minSpeed int64, grace time.Duration) *stallReader {
+	r := &stallReader{ReadSeeker: source, url: url, minSpeed: minSpeed, grace: grace, doneCh: make(chan struct{})}
+	if minSpeed > 0 {
+		go r.watch()
+	}
+	return r
+}
+
+// watch checks for stalls a few times per grace period, until Stop.
+func (r *stallReader) watch() {
+	ticker := time.NewTicker(r.grace / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.doneCh:
+			return
+		case now := <-ticker.C:
+			r.mutex.Lock()
+			stalled := r.isStalled(now)
+			r.mutex.Unlock()
+			if stalled {
+				if closer, ok := r.ReadSeeker.(io.Closer); ok {
+					closer.Close()
+				}
+				return
+			}
+		}
+	}
+}
+
+// isStalled - mark and report a stall, if the transfer moved too few bytes within the last grace period.
+func (r *stallReader) isStalled(now time.Time) bool {
+	if r.stalled || r.minSpeed <= 0 || r.started.IsZero() || now.Sub(r.started) < r.grace {
+		return r.stalled
+	}
+	// Drop samples which left the window.
+	windowStart := now.Add(-r.grace)
+	i := 0
+	for i < len(r.samples) && !r.samples[i].time.After(windowStart) {
+		i++
+	}
+	r.samples = r.samples[i:]
+	var bytes int64
+	for _, sample := range r.samples {
+		bytes += sample.bytes
+	}
+	r.stalled = float64(bytes)/r.grace.Seconds() < float64(r.minSpeed)
+	return r.stalled
+}
+
+// stallError - retryable error a stalled transfer is aborted with.
+func (r *stallReader) stallError() error {
+	return client.Stalled{URL: r.url, MinSpeed: r.minSpeed, Grace: r.grace}
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	r.mutex.Lock()
+	stalled := r.stalled
+	r.mutex.Unlock()
+	if stalled {
+		return 0, r.stallError()
+	}
+
+	n, e := r.ReadSeeker.Read(p)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	if n > 0 {
+		if r.started.IsZero() {
+			r.started = now
+		}
+		r.samples = append(r.samples, stallSample{time: now, bytes: int64(n)})
+		r.read += int64(n)
+	}
+	// Errors of a source closed by the watchdog are reported as the stall.
+	if r.isStalled(now) {
+		return n, r.stallError()
+	}
+	if e == io.EOF {
+		// Nothing left to transfer, the rest is up to the target.
+		r.started = time.Time{}
+	}
+	return n, e
+}
+
+// Seek restarts stall detection, the transfer starts over from offset.
+func (r *stallReader) Seek(offset int64, whence int) (int64, error) {
+	r.mutex.Lock()
+	r.started = time.Time{}
+	r.samples = nil
+	r.mutex.Unlock()
+	return r.ReadSeeker.Seek(offset, whence)
+}
+
+// BytesRead returns bytes read so far, including bytes read before a seek.
+func (r *stallReader) BytesRead() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.read
+}
+
+// Stop stops the watchdog, call once the transfer is done.
+func (r *stallReader) Stop() {
+	close(r.doneCh)
+}
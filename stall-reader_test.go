@@ -0,0 +1,212 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// slowReader delivers a byte per delay, after waiting firstDelay for the first one.
+type slowReader struct {
+	io.ReadSeeker
+	firstDelay time.Duration
+	delay      time.Duration
+	started    bool
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		time.Sleep(r.firstDelay)
+	} else {
+		time.Sleep(r.delay)
+	}
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.ReadSeeker.Read(p)
+}
+
+// pausedReader delivers its data in one read, then blocks until closed.
+type pausedReader struct {
+	io.ReadSeeker
+	delivered bool
+	closeCh   chan struct{}
+}
+
+func (r *pausedReader) Read(p []byte) (int, error) {
+	if !r.delivered {
+		r.delivered = true
+		return r.ReadSeeker.Read(p)
+	}
+	<-r.closeCh
+	return 0, io.ErrClosedPipe
+}
+
+func (r *pausedReader) Close() error {
+	close(r.closeCh)
+	return nil
+}
+
+// readWithin - read all of r, failing the test if it takes longer than limit.
+func readWithin(c *C, r io.Reader, limit time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, e := ioutil.ReadAll(r)
+		errCh <- e
+	}()
+	select {
+	case e := <-errCh:
+		return e
+	case <-time.After(limit):
+		c.Fatalf("read did not finish within %s", limit)
+	}
+	return nil
+}
+
+func (s *TestSuite) TestStallReaderSlow(c *C) {
+	source := &slowReader{ReadSeeker: bytes.NewReader(make([]byte, 1000)), delay: 10 * time.Millisecond}
+	reader := newStallReader(source, "slow", 1000, 100*time.Millisecond)
+	defer reader.Stop()
+
+	e := readWithin(c, reader, 5*time.Second)
+	stalled, ok := e.(client.Stalled)
+	c.Assert(ok, Equals, true, Commentf("%v", e))
+	c.Assert(stalled.URL, Equals, "slow")
+	c.Assert(isRetryable(probe.NewError(e)), Equals, true)
+	c.Assert(reader.BytesRead() > 0, Equals, true)
+}
+
+func (s *TestSuite) TestStallReaderPaused(c *C) {
+	source := &pausedReader{ReadSeeker: bytes.NewReader(make([]byte, 100)), closeCh: make(chan struct{})}
+	reader := newStallReader(source, "paused", 1000, 100*time.Millisecond)
+	defer reader.Stop()
+
+	// Watchdog closes the source to abort the blocked read.
+	e := readWithin(c, reader, 5*time.Second)
+	_, ok := e.(client.Stalled)
+	c.Assert(ok, Equals, true, Commentf("%v", e))
+	c.Assert(reader.BytesRead(), Equals, int64(100))
+}
+
+func (s *TestSuite) TestStallReaderIdle(c *C) {
+	// No byte arrives for longer than the grace period, then the transfer is fast.
+	source := &slowReader{ReadSeeker: bytes.NewReader(make([]byte, 10)), firstDelay: 300 * time.Millisecond}
+	reader := newStallReader(source, "idle", 10, 100*time.Millisecond)
+	defer reader.Stop()
+
+	c.Assert(readWithin(c, reader, 5*time.Second), IsNil)
+	c.Assert(reader.BytesRead(), Equals, int64(10))
+}
+
+func (s *TestSuite) TestStallReaderOff(c *C) {
+	source := &slowReader{ReadSeeker: bytes.NewReader(make([]byte, 20)), delay: 10 * time.Millisecond}
+	reader := newStallReader(source, "off", 0, 50*time.Millisecond)
+	defer reader.Stop()
+
+	c.Assert(readWithin(c, reader, 5*time.Second), IsNil)
+	c.Assert(reader.BytesRead(), Equals, int64(20))
+	c.Assert(isRetryable(probe.NewError(io.ErrUnexpectedEOF)), Equals, false)
+}
+
+func (s *TestSuite) TestParseMinSpeed(c *C) {
+	for input, expected := range map[string]int64{"64KiB": 64 << 10, "1MiB/s": 1 << 20, "500": 500} {
+		minSpeed, err := parseMinSpeed(input)
+		c.Assert(err, IsNil)
+		c.Assert(minSpeed, Equals, expected, Commentf("%s", input))
+	}
+	for _, input := range []string{"fast", "0", "-1KiB"} {
+		_, err := parseMinSpeed(input)
+		c.Assert(err, Not(IsNil), Commentf("%s", input))
+	}
+}
+
+// stallingHandler serves an object, pausing the first download half way.
+type stallingHandler struct {
+	rangeHandler
+	pause   time.Duration
+	stalled bool
+}
+
+func (h *stallingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" || h.stalled {
+		h.rangeHandler.ServeHTTP(w, r)
+		return
+	}
+	h.stalled = true
+	h.ranges = append(h.ranges, r.Header.Get("Range"))
+	w.Header().Set("ETag", "\""+h.etag+"\"")
+	w.Header().Set("Content-Length", strconv.Itoa(len(h.data)))
+	w.Header().Set("Last-Modified", time.Unix(1445000000, 0).UTC().Format(http.TimeFormat))
+	w.Write(h.data[:len(h.data)/2])
+	w.(http.Flusher).Flush()
+	time.Sleep(h.pause)
+}
+
+func (s *TestSuite) TestCopyStallRetried(c *C) {
+	defer useTempMcConfig(c)()
+	handler := &stallingHandler{rangeHandler: rangeHandler{data: bytes.Repeat([]byte("0123456789abcdef"), 4096), etag: "v1"}, pause: time.Second}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("stall", hostConfigV7{URL: server.URL, API: "S3v4"}), IsNil)
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-stall-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	target := filepath.Join(root, "object")
+
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+
+	session := newTestSession()
+	session.Header.CommandIntFlags = map[string]int{"min-speed": 1024}
+	session.Header.CommandStringFlags = map[string]string{"stall-grace": "100ms"}
+	cpURLs := copyURLs{
+		SourceAlias:   "stall",
+		SourceContent: &client.Content{URL: *client.NewURL(server.URL + "/bucket/object"), Size: int64(len(handler.data))},
+		TargetContent: &client.Content{URL: *client.NewURL(target)},
+	}
+	cpQueue := make(chan bool, 1)
+	cpQueue <- true
+	statusCh := make(chan copyURLs, 1)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	doCopy(context.Background(), cpURLs, session, nil, nil, nil, cpQueue, wg, statusCh)
+
+	// Stalled download is aborted and resumed, not skipped.
+	status := <-statusCh
+	c.Assert(status.Error, IsNil)
+	c.Assert(handler.ranges, DeepEquals, []string{"", "bytes=" + strconv.Itoa(len(handler.data)/2) + "-"})
+	data, e := ioutil.ReadFile(target)
+	c.Assert(e, IsNil)
+	c.Assert(bytes.Equal(data, handler.data), Equals, true)
+}
@@ -0,0 +1,226 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// statCacheSize bounds the number of cached Stat results, a cp or mirror
+// of many objects stats every source once and need not keep them all.
+const statCacheSize = 4096
+
+// statCacheEntry is an element of the cache's recency list.
+type statCacheEntry struct {
+	url     string
+	content client.Content
+}
+
+// statCache memoizes successful Stat results by URL for the lifetime of a
+// single command. Failures are never cached, a missing target may be
+// created by the command itself. The least recently used entries are
+// evicted once statCacheSize is reached.
+type statCache struct {
+	mutex    *sync.Mutex
+	size     int
+	recency  *list.List
+	contents map[string]*list.Element
+}
+
+// newStatCache - instantiate an empty stat cache.
+func newStatCache() *statCache {
+	return &statCache{
+		mutex:    new(sync.Mutex),
+		size:     statCacheSize,
+		recency:  list.New(),
+		contents: make(map[string]*list.Element),
+	}
+}
+
+// get - a copy of the cached content of url, callers may modify it freely.
+func (s *statCache) get(url string) (*client.Content, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.contents[url]
+	if !ok {
+		return nil, false
+	}
+	s.recency.MoveToFront(element)
+	content := element.Value.(*statCacheEntry).content
+	return &content, true
+}
+
+func (s *statCache) set(url string, content *client.Content) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.contents[url]; ok {
+		element.Value.(*statCacheEntry).content = *content
+		s.recency.MoveToFront(element)
+		return
+	}
+	s.contents[url] = s.recency.PushFront(&statCacheEntry{url: url, content: *content})
+	for s.recency.Len() > s.size {
+		s.remove(s.recency.Back().Value.(*statCacheEntry).url)
+	}
+}
+
+// remove - drop url, callers hold the mutex.
+func (s *statCache) remove(url string) {
+	if element, ok := s.contents[url]; ok {
+		s.recency.Remove(element)
+		delete(s.contents, url)
+	}
+}
+
+// invalidate - forget url along with the folders above it, a folder's stat
+// changes with its contents.
+func (s *statCache) invalidate(url string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remove(url)
+	for parent := strings.TrimRight(url, "/\\"); parent != ""; {
+		s.remove(parent)
+		s.remove(parent + "/")
+		s.remove(parent + "\\")
+		i := strings.LastIndexAny(parent, "/\\")
+		if i < 0 {
+			break
+		}
+		parent = strings.TrimRight(parent[:i], "/\\")
+	}
+}
+
+// purge - forget everything, for operations removing whole trees.
+func (s *statCache) purge() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.recency.Init()
+	s.contents = make(map[string]*list.Element)
+}
+
+// statCacheClient wraps a client, Stat is answered from the cache where
+// possible. Mutating operations invalidate what they touch.
+type statCacheClient struct {
+	client.Client
+	cache *statCache
+}
+
+// newStatCacheClient - wrap clnt to share cache.
+func newStatCacheClient(clnt client.Client, cache *statCache) client.Client {
+	return statCacheClient{Client: clnt, cache: cache}
+}
+
+func (c statCacheClient) Stat() (*client.Content, *probe.Error) {
+	url := c.GetURL().String()
+	if content, ok := c.cache.get(url); ok {
+		return content, nil
+	}
+	content, err := c.Client.Stat()
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	c.cache.set(url, content)
+	return content, nil
+}
+
+func (c statCacheClient) Put(data io.ReadSeeker, size int64, contentType string, metadata map[string]string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.Put(data, size, contentType, metadata)
+}
+
+func (c statCacheClient) PutStream(data io.Reader, partSize int64, contentType string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.PutStream(data, partSize, contentType)
+}
+
+func (c statCacheClient) Remove(incomplete bool, versionID string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.Remove(incomplete, versionID)
+}
+
+func (c statCacheClient) RemoveBatch(contentCh <-chan *client.Content) <-chan *client.Content {
+	resultCh := make(chan *client.Content)
+	go func() {
+		defer close(resultCh)
+		for content := range c.Client.RemoveBatch(contentCh) {
+			c.cache.invalidate(content.URL.String())
+			resultCh <- content
+		}
+	}()
+	return resultCh
+}
+
+func (c statCacheClient) SetMetadata(contentType, cacheControl string, metadata map[string]string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.SetMetadata(contentType, cacheControl, metadata)
+}
+
+func (c statCacheClient) RestoreVersion(versionID string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.RestoreVersion(versionID)
+}
+
+func (c statCacheClient) RestoreArchived(days int, tier string) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.RestoreArchived(days, tier)
+}
+
+func (c statCacheClient) SetObjectACL(acl client.ACL) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.SetObjectACL(acl)
+}
+
+func (c statCacheClient) SetRetention(mode string, retainUntil time.Time, bypassGovernance bool) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.SetRetention(mode, retainUntil, bypassGovernance)
+}
+
+func (c statCacheClient) SetLegalHold(enabled bool) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.SetLegalHold(enabled)
+}
+
+func (c statCacheClient) Copy(source client.URL, options client.CopyOptions) *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.Copy(source, options)
+}
+
+func (c statCacheClient) WithContext(ctx context.Context) client.Client {
+	return statCacheClient{Client: c.Client.WithContext(ctx), cache: c.cache}
+}
+
+func (c statCacheClient) MakeBucket() *probe.Error {
+	defer c.cache.invalidate(c.GetURL().String())
+	return c.Client.MakeBucket()
+}
+
+func (c statCacheClient) RemoveBucket() *probe.Error {
+	defer c.cache.purge()
+	return c.Client.RemoveBucket()
+}
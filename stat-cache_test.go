@@ -0,0 +1,172 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// countingClient counts Stat calls reaching the wrapped client.
+type countingClient struct {
+	client.Client
+	stats *int32
+}
+
+func (c countingClient) Stat() (*client.Content, *probe.Error) {
+	atomic.AddInt32(c.stats, 1)
+	return c.Client.Stat()
+}
+
+func (s *TestSuite) TestStatCache(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mc-stat-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	object := filepath.Join(root, "object")
+	c.Assert(ioutil.WriteFile(object, []byte("hello"), 0644), IsNil)
+
+	var stats int32
+	cache := newStatCache()
+	newCachedClient := func() client.Client {
+		fsClnt, err := fs.New(object)
+		c.Assert(err, IsNil)
+		return newStatCacheClient(countingClient{Client: fsClnt, stats: &stats}, cache)
+	}
+
+	// Repeated lookups, even through separate clients, stat once.
+	for i := 0; i < 3; i++ {
+		content, err := newCachedClient().Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Size, Equals, int64(5))
+		content.Size = 0 // Callers modifying the result leave the cache intact.
+	}
+	c.Assert(atomic.LoadInt32(&stats), Equals, int32(1))
+
+	// Put invalidates.
+	clnt := newCachedClient()
+	c.Assert(clnt.Put(bytes.NewReader([]byte("hello world")), 11, "", nil), IsNil)
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(11))
+	c.Assert(atomic.LoadInt32(&stats), Equals, int32(2))
+
+	// Remove invalidates, failures are not cached.
+	c.Assert(clnt.Remove(false, ""), IsNil)
+	for i := 0; i < 2; i++ {
+		_, err = newCachedClient().Stat()
+		c.Assert(err, Not(IsNil))
+	}
+	c.Assert(atomic.LoadInt32(&stats), Equals, int32(4))
+}
+
+func (s *TestSuite) TestStatCacheNotShared(c *C) {
+	savedNoStatCache, savedStatCache := globalNoStatCache, globalStatCache
+	defer func() { globalNoStatCache, globalStatCache = savedNoStatCache, savedStatCache }()
+
+	setGlobals(false, false, false, false, false, false, false, 0, 0, 0, 0, 0)
+	first := globalStatCache
+	c.Assert(first, Not(IsNil))
+	first.set("/tmp/object", &client.Content{Size: 5})
+
+	// Every command, or resumed session, starts over.
+	setGlobals(false, false, false, false, false, false, false, 0, 0, 0, 0, 0)
+	c.Assert(globalStatCache, Not(Equals), first)
+	_, ok := globalStatCache.get("/tmp/object")
+	c.Assert(ok, Equals, false)
+
+	// --no-stat-cache leaves clients unwrapped.
+	setGlobals(false, false, false, false, false, true, false, 0, 0, 0, 0, 0)
+	c.Assert(globalStatCache, IsNil)
+	clnt, err := newClientFromAlias("", "/tmp/object")
+	c.Assert(err, IsNil)
+	_, ok = clnt.(statCacheClient)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TestSuite) TestStatCacheInvalidateAndBound(c *C) {
+	cache := newStatCache()
+	cache.set("s3/bucket/", &client.Content{})
+	cache.set("s3/bucket/folder/", &client.Content{})
+	cache.set("s3/bucket/folder/object", &client.Content{Size: 1})
+	cache.set("s3/bucket/other", &client.Content{Size: 2})
+
+	// Writing an object forgets it and the folders above it, siblings stay.
+	cache.invalidate("s3/bucket/folder/object")
+	for _, url := range []string{"s3/bucket/", "s3/bucket/folder/", "s3/bucket/folder/object"} {
+		_, ok := cache.get(url)
+		c.Assert(ok, Equals, false)
+	}
+	_, ok := cache.get("s3/bucket/other")
+	c.Assert(ok, Equals, true)
+
+	// The least recently used entries are evicted beyond the bound.
+	cache.size = 2
+	cache.set("s3/bucket/a", &client.Content{})
+	cache.get("s3/bucket/other")
+	cache.set("s3/bucket/b", &client.Content{})
+	c.Assert(len(cache.contents), Equals, 2)
+	_, ok = cache.get("s3/bucket/a")
+	c.Assert(ok, Equals, false)
+	_, ok = cache.get("s3/bucket/other")
+	c.Assert(ok, Equals, true)
+
+	cache.purge()
+	_, ok = cache.get("s3/bucket/b")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TestSuite) TestStatCacheInvalidatedByObjectChanges(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mc-stat-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	object := filepath.Join(root, "object")
+	c.Assert(ioutil.WriteFile(object, []byte("hello"), 0644), IsNil)
+	fsClnt, err := fs.New(object)
+	c.Assert(err, IsNil)
+	var stats int32
+	clnt := newStatCacheClient(countingClient{Client: fsClnt, stats: &stats}, newStatCache())
+
+	// Whether or not the change succeeds, the next Stat is not answered from the cache.
+	changes := map[string]func() *probe.Error{
+		"RestoreArchived": func() *probe.Error { return clnt.RestoreArchived(1, "") },
+		"SetObjectACL":    func() *probe.Error { return clnt.SetObjectACL(client.ACL{Canned: "private"}) },
+		"SetRetention": func() *probe.Error {
+			return clnt.SetRetention("GOVERNANCE", time.Now().Add(time.Hour), false)
+		},
+		"SetLegalHold": func() *probe.Error { return clnt.SetLegalHold(true) },
+	}
+	for name, change := range changes {
+		_, err = clnt.Stat()
+		c.Assert(err, IsNil)
+		before := atomic.LoadInt32(&stats)
+		change()
+		_, err = clnt.Stat()
+		c.Assert(err, IsNil)
+		c.Assert(atomic.LoadInt32(&stats), Equals, before+1, Commentf("%s", name))
+	}
+}
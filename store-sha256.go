@@ -0,0 +1,103 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// sha256Reader - hashes the data of an upload as it is read. Data read again after a seek back,
+// by a retry, is hashed once. A seek past what was hashed so far, skipping parts uploaded
+// earlier, leaves the hash incomplete.
+type sha256Reader struct {
+	io.ReadSeeker
+	hasher hash.Hash
+	offset int64
+	hashed int64
+}
+
+// newSHA256Reader - hash the data of source, read from its start.
+func newSHA256Reader(source io.ReadSeeker) *sha256Reader {
+	return &sha256Reader{ReadSeeker: source, hasher: sha256.New()}
+}
+
+// Read - hash what has not been hashed before.
+func (r *sha256Reader) Read(p []byte) (int, error) {
+	n, e := r.ReadSeeker.Read(p)
+	if end := r.offset + int64(n); r.offset <= r.hashed && end > r.hashed {
+		r.hasher.Write(p[r.hashed-r.offset : n])
+		r.hashed = end
+	}
+	r.offset += int64(n)
+	return n, e
+}
+
+// Seek - keep track of the offset read next.
+func (r *sha256Reader) Seek(offset int64, whence int) (int64, error) {
+	offset, e := r.ReadSeeker.Seek(offset, whence)
+	if e == nil {
+		r.offset = offset
+	}
+	return offset, e
+}
+
+// Sum - hex SHA256 of the first size bytes, false unless all of them were read.
+func (r *sha256Reader) Sum(size int64) (string, bool) {
+	if r.hashed != size {
+		return "", false
+	}
+	return hex.EncodeToString(r.hasher.Sum(nil)), true
+}
+
+// storeSHA256 - add the SHA256 of the data uploaded with hashReader to the metadata of the object of
+// targetClnt, for ‘--verify’ to check downloads against where ETags are not the MD5 of the data. S3
+// takes metadata before the data, it is replaced once the upload is complete by copying the object
+// onto itself. The source is read once more if the upload skipped parts of it.
+func storeSHA256(sourceClnt, targetClnt client.Client, hashReader *sha256Reader, size int64) *probe.Error {
+	sum, ok := hashReader.Sum(size)
+	if !ok {
+		source, err := sourceClnt.Get(0, 0, "")
+		if err != nil {
+			return err.Trace(sourceClnt.GetURL().String())
+		}
+		hasher := sha256.New()
+		_, e := io.CopyN(hasher, source, size)
+		if closer, ok := source.(io.Closer); ok {
+			closer.Close()
+		}
+		if e != nil {
+			return probe.NewError(e).Trace(sourceClnt.GetURL().String())
+		}
+		sum = hex.EncodeToString(hasher.Sum(nil))
+	}
+	target, err := targetClnt.Stat()
+	if err != nil {
+		return err.Trace(targetClnt.GetURL().String())
+	}
+	metadata := make(map[string]string)
+	for k, v := range target.Metadata {
+		metadata[k] = v
+	}
+	metadata["sha256"] = sum
+	return targetClnt.SetMetadata(target.ContentType, target.CacheControl, metadata).Trace(targetClnt.GetURL().String())
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Status of an object in the ‘--summary-file’.
+const (
+	recordTransferred = "transferred"
+	recordSkipped     = "skipped"
+	recordFailed      = "failed"
+	recordRemoved     = "removed"
+)
+
+// transferRecord - outcome of a single object, one line of the ‘--summary-file’.
+type transferRecord struct {
+	Time     time.Time `json:"time"`
+	URL      string    `json:"url"`
+	Target   string    `json:"target,omitempty"`
+	Size     int64     `json:"size"`
+	Status   string    `json:"status"`
+	Duration float64   `json:"duration"` // seconds.
+	ETag     string    `json:"etag,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// summaryFile appends a JSON line per object to a file. Each record is written as it
+// happens, records of completed objects survive a crash. Safe for concurrent use.
+type summaryFile struct {
+	mutex *sync.Mutex
+	file  *os.File
+}
+
+// getSummaryFilePath - absolute path of the ‘--summary-file’, a resumed session may run elsewhere.
+func getSummaryFilePath(path string) (string, *probe.Error) {
+	absPath, e := filepath.Abs(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return absPath, nil
+}
+
+// openSummaryFile - open the ‘--summary-file’ of session for appending, nil if none is set.
+func openSummaryFile(session *sessionV6) (*summaryFile, *probe.Error) {
+	path := session.Header.CommandStringFlags["summary-file"]
+	if path == "" {
+		return nil, nil
+	}
+	file, e := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return &summaryFile{mutex: new(sync.Mutex), file: file}, nil
+}
+
+// Record - append the outcome of the transfer of source to target, a nil summaryFile records nothing.
+// Write failures are reported as warnings, they do not fail the transfer.
+func (s *summaryFile) Record(status string, source, target *client.Content, duration time.Duration, err *probe.Error) {
+	if s == nil {
+		return
+	}
+	record := transferRecord{
+		Time:     time.Now().UTC(),
+		Status:   status,
+		Duration: duration.Seconds(),
+	}
+	if source != nil {
+		record.URL = source.URL.String()
+		record.Size = source.Size
+		record.ETag = source.ETag
+	}
+	if target != nil {
+		record.Target = target.URL.String()
+		if source == nil {
+			// Removed by mirror, the target is the object.
+			record.URL = record.Target
+			record.Target = ""
+		}
+	}
+	if err != nil {
+		record.Error = err.ToGoError().Error()
+	}
+	recordBytes, e := json.Marshal(record)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// A single write of a whole line, lines of parallel transfers never interleave.
+	_, e = s.file.Write(append(recordBytes, '\n'))
+	errorIf(probe.NewError(e).Trace(s.file.Name()), "Unable to write to summary file.")
+}
+
+// Close - close the file, a nil summaryFile is ignored.
+func (s *summaryFile) Close() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.file.Close()
+}
@@ -0,0 +1,222 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/pb"
+)
+
+// maxSummaryErrors - failed objects listed in a summary, further failures are only counted.
+const maxSummaryErrors = 100
+
+// transferSummary keeps tabs of objects transferred, skipped and failed during a session.
+type transferSummary struct {
+	mutex     *sync.Mutex
+	startTime time.Time
+
+	transferredObjects int
+	transferredBytes   int64
+	skippedObjects     int
+	skippedBytes       int64
+	failedObjects      int
+	failedBytes        int64
+	toleratedObjects   int
+	errors             []summaryError
+	// Error the transfer was aborted on before all objects were attempted, nil otherwise.
+	abortErr *probe.Error
+}
+
+// newTransferSummary - instantiate a new transfer summary of a run beginning at startTime, of a
+// resumed session that is when it resumed rather than when the session began.
+func newTransferSummary(startTime time.Time) *transferSummary {
+	return &transferSummary{
+		mutex:     new(sync.Mutex),
+		startTime: startTime,
+	}
+}
+
+// Transferred - account for an object successfully transferred.
+func (t *transferSummary) Transferred(size int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.transferredObjects++
+	t.transferredBytes += size
+}
+
+// Skipped - account for an object skipped, for example already copied by a previous run of this session.
+func (t *transferSummary) Skipped(size int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.skippedObjects++
+	t.skippedBytes += size
+}
+
+// Failed - account for an object at url which failed to transfer with err.
+func (t *transferSummary) Failed(size int64, url string, err *probe.Error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failedObjects++
+	t.failedBytes += size
+	if len(t.errors) < maxSummaryErrors {
+		failure := summaryError{URL: url}
+		if err != nil {
+			failure.Error = err.ToGoError().Error()
+		}
+		t.errors = append(t.errors, failure)
+	}
+}
+
+// Tolerated - account for a failed object tolerated by ‘--skip-errors’, counted as failed as well.
+func (t *transferSummary) Tolerated() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.toleratedObjects++
+}
+
+// Abort - record the transfer stopped on err, before all objects were attempted.
+func (t *transferSummary) Abort(err *probe.Error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.abortErr == nil {
+		t.abortErr = err
+	}
+}
+
+// abortError - error the transfer was aborted on, nil if all objects were attempted.
+func (t *transferSummary) abortError() *probe.Error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.abortErr
+}
+
+// exitCode - exitPartialFailure if any object failed to transfer, zero otherwise.
+func (t *transferSummary) exitCode() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.failedObjects > 0 {
+		return exitPartialFailure
+	}
+	return 0
+}
+
+// exitOnFailures - exit with exitPartialFailure if some objects failed to transfer, each failure
+// was already reported as it happened.
+func exitOnFailures(summary *transferSummary) {
+	if summary.exitCode() == 0 {
+		return
+	}
+	if globalJSON {
+		fatalWithCode(errPartialFailure(summary.failedObjects).Trace(), "Unable to transfer all objects.", exitPartialFailure)
+	}
+	flushOutput()
+	os.Exit(exitPartialFailure)
+}
+
+// Message - captures current counters into a summary message.
+func (t *transferSummary) Message(session *sessionV6) summaryMessage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	elapsed := time.Since(t.startTime)
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(t.transferredBytes) / elapsed.Seconds()
+	}
+	return summaryMessage{
+		Command:          session.Header.CommandType,
+		TotalObjects:     session.Header.TotalObjects,
+		TotalBytes:       session.Header.TotalBytes,
+		Transferred:      t.transferredObjects,
+		TransferredBytes: t.transferredBytes,
+		Skipped:          t.skippedObjects,
+		SkippedBytes:     t.skippedBytes,
+		Failed:           t.failedObjects,
+		FailedBytes:      t.failedBytes,
+		Tolerated:        t.toleratedObjects,
+		Elapsed:          elapsed,
+		ElapsedSeconds:   elapsed.Seconds(),
+		Speed:            speed,
+		Errors:           append([]summaryError(nil), t.errors...),
+	}
+}
+
+// summaryError container for an object which failed to transfer.
+type summaryError struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// summaryMessage container for transfer summary message.
+type summaryMessage struct {
+	Status           string         `json:"status"`
+	Command          string         `json:"command"`
+	TotalObjects     int            `json:"totalObjects"`
+	TotalBytes       int64          `json:"totalBytes"`
+	Transferred      int            `json:"transferred"`
+	TransferredBytes int64          `json:"transferredBytes"`
+	Skipped          int            `json:"skipped"`
+	SkippedBytes     int64          `json:"skippedBytes"`
+	Failed           int            `json:"failed"`
+	FailedBytes      int64          `json:"failedBytes"`
+	Tolerated        int            `json:"tolerated,omitempty"`
+	Elapsed          time.Duration  `json:"-"`
+	ElapsedSeconds   float64        `json:"elapsedSeconds"`
+	Speed            float64        `json:"speed"`
+	Errors           []summaryError `json:"errors,omitempty"`
+}
+
+// String colorized summary message.
+func (s summaryMessage) String() string {
+	speedBox := pb.FormatBytes(int64(s.Speed))
+	if speedBox == "" {
+		speedBox = "0 MB"
+	} else {
+		speedBox = speedBox + "/s"
+	}
+	message := fmt.Sprintf("Objects: %d/%d, Transferred: %s, Skipped: %d, Failed: %d, Elapsed: %s, Speed: %s",
+		s.Transferred, s.TotalObjects, pb.FormatBytes(s.TransferredBytes), s.Skipped, s.Failed,
+		timeDurationToHumanizedTime(s.Elapsed), speedBox)
+	if s.Tolerated > 0 {
+		message += fmt.Sprintf(", Tolerated: %d", s.Tolerated)
+	}
+	return console.Colorize("Summary", message)
+}
+
+// JSON jsonified summary message.
+func (s summaryMessage) JSON() string {
+	s.Status = "success"
+	summaryBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(summaryBytes)
+}
+
+// printSummary - prints the transfer summary if requested, quiet mode suppresses it.
+func printSummary(session *sessionV6, summary *transferSummary) {
+	if !session.Header.CommandBoolFlags["summary"] || globalQuiet {
+		return
+	}
+	printMsg(summary.Message(session))
+}
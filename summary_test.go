@@ -0,0 +1,88 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestTransferSummary(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-summary-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	target := filepath.Join(root, "target") + string(os.PathSeparator)
+	var sourceURLs []string
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		source := filepath.Join(root, name)
+		c.Assert(ioutil.WriteFile(source, bytes.Repeat([]byte(name), i+1), 0600), IsNil)
+		sourceURLs = append(sourceURLs, source)
+	}
+	// A folder in the way of ‘d’ fails its copy.
+	c.Assert(os.MkdirAll(filepath.Join(target, "d"), 0700), IsNil)
+
+	session := newTestCopySession(c, sourceURLs, target, 1, true)
+	defer session.Delete()
+	msg := doCopySession(session).Message(session)
+	c.Assert(msg.TotalObjects, Equals, 5)
+	c.Assert(msg.TotalBytes, Equals, int64(15))
+	c.Assert(msg.Transferred, Equals, 4)
+	c.Assert(msg.TransferredBytes, Equals, int64(11))
+	c.Assert(msg.Skipped, Equals, 0)
+	c.Assert(msg.Failed, Equals, 1)
+	c.Assert(msg.FailedBytes, Equals, int64(4))
+	c.Assert(msg.Errors, HasLen, 1)
+	c.Assert(msg.Errors[0].URL, Equals, sourceURLs[3])
+	c.Assert(msg.Speed > 0, Equals, true)
+	c.Assert(session.Close(), IsNil)
+
+	// The resumed session began long ago, its totals carry over, the time of this run is measured.
+	c.Assert(os.Remove(filepath.Join(target, "d")), IsNil)
+	resumed, err := loadSessionV6(session.SessionID)
+	c.Assert(err, IsNil)
+	resumed.Header.When = time.Now().Add(-time.Hour)
+	msg = doCopySession(resumed).Message(resumed)
+	c.Assert(msg.TotalObjects, Equals, 5)
+	c.Assert(msg.TotalBytes, Equals, int64(15))
+	c.Assert(msg.Transferred, Equals, 1)
+	c.Assert(msg.TransferredBytes, Equals, int64(4))
+	c.Assert(msg.Skipped, Equals, 4)
+	c.Assert(msg.SkippedBytes, Equals, int64(11))
+	c.Assert(msg.Failed, Equals, 0)
+	c.Assert(msg.TransferredBytes+msg.SkippedBytes+msg.FailedBytes, Equals, msg.TotalBytes)
+	c.Assert(msg.Elapsed < time.Minute, Equals, true, Commentf("%s", msg.Elapsed))
+	c.Assert(msg.Speed > 4/time.Minute.Seconds(), Equals, true)
+	c.Assert(resumed.Close(), IsNil)
+
+	var jsonMsg summaryMessage
+	c.Assert(json.Unmarshal([]byte(msg.JSON()), &jsonMsg), IsNil)
+	c.Assert(jsonMsg.Status, Equals, "success")
+	c.Assert(jsonMsg.Transferred, Equals, 1)
+	c.Assert(jsonMsg.Skipped, Equals, 4)
+	c.Assert(jsonMsg.Failed, Equals, 0)
+}
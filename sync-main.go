@@ -0,0 +1,534 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// sync specific flags.
+var (
+	syncFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of sync.",
+		},
+		cli.StringFlag{
+			Name:  "conflict",
+			Value: syncConflictNewer,
+			Usage: "Resolve objects changed on both sides by policy: newer, larger, source, target, skip or rename.",
+		},
+		cli.BoolFlag{
+			Name:  "propagate-deletes",
+			Usage: "Remove objects removed from the other side since the last sync, unless changed since.",
+		},
+	}
+)
+
+// Reconcile two folders in both directions.
+var syncCmd = cli.Command{
+	Name:   "sync",
+	Usage:  "Reconcile two folders in both directions.",
+	Action: mainSync,
+	Flags:  append(syncFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] SOURCE TARGET
+
+   Objects only on one side are copied to the other, objects changed on one side since the last
+   sync are copied over those of the other side. Objects changed on both sides, or differing in
+   size when the folders are synced for the first time, are conflicts resolved by --conflict:
+      newer   the object modified last wins, neither does if both were modified at the same time.
+      larger  the larger object wins, the newer one if both are of the same size.
+      source  the object of SOURCE wins.
+      target  the object of TARGET wins.
+      skip    both objects are left as they are.
+      rename  both are kept, the newer one by its name and the older one as NAME.conflict on both sides.
+
+   What is in sync is remembered in the config folder. With --propagate-deletes an object removed
+   from one side since the last sync is removed from the other side too, unless it was changed
+   there since. Without it, or before the first sync, the object is copied back.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Keep a local folder and a folder on Amazon S3 cloud storage in sync, the newer object winning.
+      $ mc {{.Name}} ~/Documents s3/mybucket/documents
+
+   2. Keep both versions of notes changed on a laptop and on Amazon S3 cloud storage alike.
+      $ mc {{.Name}} --conflict rename ~/Notes s3/mybucket/notes
+
+   3. Share a folder between two laptops through Amazon S3 cloud storage, removals included.
+      $ mc {{.Name}} --propagate-deletes ~/Shared s3/mybucket/shared
+`,
+}
+
+// Policies resolving objects changed on both sides.
+const (
+	syncConflictNewer  = "newer"
+	syncConflictLarger = "larger"
+	syncConflictSource = "source"
+	syncConflictTarget = "target"
+	syncConflictSkip   = "skip"
+	syncConflictRename = "rename"
+)
+
+// syncConflictSuffix - suffix the older object is kept by with ‘--conflict rename’.
+const syncConflictSuffix = ".conflict"
+
+// Operations performed by sync.
+const (
+	syncCopy   = "copy"   // object copied from source to target.
+	syncRemove = "remove" // object removed from target, it was removed from source.
+	syncSkip   = "skip"   // conflict left as it is.
+)
+
+// syncMessage is container for sync command success messages.
+type syncMessage struct {
+	Status    string `json:"status"`
+	Operation string `json:"operation"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+}
+
+// String colorized sync message.
+func (s syncMessage) String() string {
+	switch s.Operation {
+	case syncCopy:
+		return console.Colorize("Sync", "‘"+s.Source+"’ -> ‘"+s.Target+"’")
+	case syncRemove:
+		return console.Colorize("SyncRemove", "Removed ‘"+s.Target+"’, it was removed from ‘"+s.Source+"’.")
+	case syncSkip:
+		return console.Colorize("SyncConflict", "Skipped ‘"+s.Source+"’ and ‘"+s.Target+"’, both changed.")
+	}
+	return ""
+}
+
+// JSON jsonified sync message.
+func (s syncMessage) JSON() string {
+	s.Status = "success"
+	syncJSONBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(syncJSONBytes)
+}
+
+// checkSyncSyntax - validate command-line input args.
+func checkSyncSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(ctx, "sync", 1) // last argument is exit code.
+	}
+	switch ctx.String("conflict") {
+	case syncConflictNewer, syncConflictLarger, syncConflictSource, syncConflictTarget, syncConflictSkip, syncConflictRename:
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("conflict")), "Unknown conflict policy ‘"+ctx.String("conflict")+"’.")
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
+		}
+		_, content, err := url2Stat(arg)
+		fatalIf(err.Trace(arg), "Unable to stat ‘"+arg+"’.")
+		if !content.Type.IsDir() {
+			fatalIf(errInvalidArgument().Trace(arg), "‘"+arg+"’ is not a folder.")
+		}
+	}
+}
+
+// syncStat - size and modification time of an object once it was synced.
+type syncStat struct {
+	Size int64     `json:"size"`
+	Time time.Time `json:"time"`
+}
+
+// newSyncStat - size and modification time of content.
+func newSyncStat(content *client.Content) syncStat {
+	return syncStat{Size: content.Size, Time: content.Time}
+}
+
+// changed - content is no longer the object synced.
+func (s syncStat) changed(content *client.Content) bool {
+	return s.Size != content.Size || !s.Time.Equal(content.Time)
+}
+
+// syncRecord - an object on both sides once it was synced.
+type syncRecord struct {
+	First  syncStat `json:"first"`
+	Second syncStat `json:"second"`
+}
+
+// syncState - objects in sync after the last sync of two folders, by key relative to them.
+type syncState struct {
+	Version string                `json:"version"`
+	First   string                `json:"first"`
+	Second  string                `json:"second"`
+	Objects map[string]syncRecord `json:"objects"`
+}
+
+// getSyncStateFile - file in the config folder the state of syncing two folders is kept in.
+func getSyncStateFile(firstURL, secondURL string) string {
+	sum := sha256.Sum256([]byte(firstURL + "\n" + secondURL))
+	return filepath.Join(mustGetMcConfigDir(), globalSyncDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// loadSyncState - state of the last sync, empty before the first sync.
+func loadSyncState(filename string) (*syncState, *probe.Error) {
+	state := &syncState{Version: "1", Objects: make(map[string]syncRecord)}
+	data, e := ioutil.ReadFile(filename)
+	if os.IsNotExist(e) {
+		return state, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, state); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if state.Objects == nil {
+		state.Objects = make(map[string]syncRecord)
+	}
+	return state, nil
+}
+
+// save - replace the state file by state.
+func (state *syncState) save(filename string) *probe.Error {
+	if e := os.MkdirAll(filepath.Dir(filename), 0700); e != nil {
+		return probe.NewError(e)
+	}
+	data, e := json.MarshalIndent(state, "", "\t")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return writeFileAtomic(filename, data).Trace(filename)
+}
+
+// syncSide - a folder synced, its objects by key relative to it in ‘/’ separators.
+type syncSide struct {
+	alias    string
+	url      string // expanded, ending in a separator.
+	objects  map[string]*client.Content
+	suffixes map[string]string // of keys, in the separators of the folder.
+}
+
+// listSyncSide - all objects of the folder urlStr.
+func listSyncSide(alias, urlStr string) (*syncSide, *probe.Error) {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	side := &syncSide{alias: alias, url: urlStr, objects: make(map[string]*client.Content), suffixes: make(map[string]string)}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for content := range clnt.List(true, false, doneCh) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(urlStr)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		suffix := strings.TrimPrefix(content.URL.String(), urlStr)
+		key := strings.Replace(suffix, string(content.URL.Separator), "/", -1)
+		side.objects[key] = content
+		side.suffixes[key] = suffix
+	}
+	return side, nil
+}
+
+// urlOf - URL on this side of the object of key on side from, suffixed by ext.
+func (s *syncSide) urlOf(from *syncSide, key, ext string) string {
+	return urlJoinSourcePath(s.url, from.objects[key].URL, from.suffixes[key]+ext)
+}
+
+// syncer - reconciles two folders, collecting what is in sync afterwards.
+type syncer struct {
+	first, second    *syncSide
+	policy           string
+	propagateDeletes bool
+	previous         map[string]syncRecord
+	synced           map[string]syncRecord
+}
+
+// other - side other than side.
+func (s *syncer) other(side *syncSide) *syncSide {
+	if side == s.first {
+		return s.second
+	}
+	return s.first
+}
+
+// record - key is in sync, fromContent on side from and toContent on the other side.
+func (s *syncer) record(key string, from *syncSide, fromContent, toContent *client.Content) {
+	// Nothing was copied for real with ‘--dry-run’.
+	if toContent == nil {
+		return
+	}
+	record := syncRecord{First: newSyncStat(fromContent), Second: newSyncStat(toContent)}
+	if from == s.second {
+		record.First, record.Second = record.Second, record.First
+	}
+	s.synced[key] = record
+}
+
+// copy - copy the object of key from side from to side to, suffixed by ext there. Returns the
+// copy, nil with ‘--dry-run’.
+func (s *syncer) copy(from, to *syncSide, key, ext string) (*client.Content, *probe.Error) {
+	sourceURL := from.objects[key].URL.String()
+	targetURL := to.urlOf(from, key, ext)
+	sourceClnt, err := newClientFromAlias(from.alias, sourceURL)
+	if err != nil {
+		return nil, err.Trace(sourceURL)
+	}
+	targetClnt, err := newClientFromAlias(to.alias, targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	reader, err := sourceClnt.Get(0, 0, "")
+	if err != nil {
+		return nil, err.Trace(sourceURL)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if err = targetClnt.Put(reader, from.objects[key].Size, guessURLContentType(targetURL), nil); err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	if globalDryRun { // Already printed by the dry run client.
+		return nil, nil
+	}
+	printMsg(syncMessage{Operation: syncCopy, Source: sourceURL, Target: targetURL})
+	content, err := targetClnt.Stat()
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	return content, nil
+}
+
+// copyOver - copy the object of key from side from over the other side.
+func (s *syncer) copyOver(from *syncSide, key string) *probe.Error {
+	content, err := s.copy(from, s.other(from), key, "")
+	if err != nil {
+		return err.Trace(key)
+	}
+	s.record(key, from, from.objects[key], content)
+	return nil
+}
+
+// newer - side of the object of key modified last, nil if both were modified at the same time.
+func (s *syncer) newer(key string) *syncSide {
+	first, second := s.first.objects[key], s.second.objects[key]
+	switch {
+	case first.Time.After(second.Time):
+		return s.first
+	case second.Time.After(first.Time):
+		return s.second
+	}
+	return nil
+}
+
+// resolve - resolve the conflict of key changed on both sides by the policy.
+func (s *syncer) resolve(key string) *probe.Error {
+	first, second := s.first.objects[key], s.second.objects[key]
+	var winner *syncSide
+	switch s.policy {
+	case syncConflictSource:
+		winner = s.first
+	case syncConflictTarget:
+		winner = s.second
+	case syncConflictLarger:
+		switch {
+		case first.Size > second.Size:
+			winner = s.first
+		case second.Size > first.Size:
+			winner = s.second
+		default:
+			winner = s.newer(key)
+		}
+	case syncConflictNewer:
+		winner = s.newer(key)
+	case syncConflictRename:
+		// Both are kept, it takes just a name.
+		if winner = s.newer(key); winner == nil {
+			winner = s.first
+		}
+	}
+	if winner == nil {
+		printMsg(syncMessage{Operation: syncSkip, Source: first.URL.String(), Target: second.URL.String()})
+		return nil
+	}
+	loser := s.other(winner)
+	if s.policy == syncConflictRename {
+		kept, err := s.copy(loser, loser, key, syncConflictSuffix)
+		if err != nil {
+			return err.Trace(key)
+		}
+		copied, err := s.copy(loser, winner, key, syncConflictSuffix)
+		if err != nil {
+			return err.Trace(key)
+		}
+		if kept != nil {
+			s.record(key+syncConflictSuffix, loser, kept, copied)
+		}
+	}
+	return s.copyOver(winner, key)
+}
+
+// syncOneSided - the object of key is only on side from, new there or removed from the other side.
+func (s *syncer) syncOneSided(from *syncSide, key string, last syncStat, recorded bool) *probe.Error {
+	content := from.objects[key]
+	if !recorded || !s.propagateDeletes || last.changed(content) {
+		return s.copyOver(from, key)
+	}
+	// Removed from the other side since the last sync and unchanged here since.
+	clnt, err := newClientFromAlias(from.alias, content.URL.String())
+	if err != nil {
+		return err.Trace(content.URL.String())
+	}
+	if err = clnt.Remove(false, ""); err != nil {
+		return err.Trace(content.URL.String())
+	}
+	if !globalDryRun { // Already printed by the dry run client.
+		printMsg(syncMessage{Operation: syncRemove, Source: s.other(from).urlOf(from, key, ""), Target: content.URL.String()})
+	}
+	return nil
+}
+
+// syncKey - reconcile the object of key on both sides.
+func (s *syncer) syncKey(key string) *probe.Error {
+	first, second := s.first.objects[key], s.second.objects[key]
+	record, recorded := s.previous[key]
+	switch {
+	case first != nil && second != nil:
+		firstChanged, secondChanged := true, true
+		switch {
+		case recorded:
+			firstChanged, secondChanged = record.First.changed(first), record.Second.changed(second)
+		case first.Size == second.Size:
+			// Not synced before, objects of the same size are taken to be in sync like mirror does.
+			firstChanged, secondChanged = false, false
+		}
+		switch {
+		case !firstChanged && !secondChanged:
+			s.record(key, s.first, first, second)
+			return nil
+		case !secondChanged:
+			return s.copyOver(s.first, key)
+		case !firstChanged:
+			return s.copyOver(s.second, key)
+		}
+		return s.resolve(key)
+	case first != nil:
+		return s.syncOneSided(s.first, key, record.First, recorded)
+	case second != nil:
+		return s.syncOneSided(s.second, key, record.Second, recorded)
+	}
+	return nil
+}
+
+// doSync - reconcile the folders firstURL and secondURL, resolving conflicts by policy.
+func doSync(firstURL, secondURL, policy string, propagateDeletes bool) *probe.Error {
+	// Both are always folders.
+	if separator := string(client.NewURL(firstURL).Separator); !strings.HasSuffix(firstURL, separator) {
+		firstURL = firstURL + separator
+	}
+	if separator := string(client.NewURL(secondURL).Separator); !strings.HasSuffix(secondURL, separator) {
+		secondURL = secondURL + separator
+	}
+	firstAlias, firstURLFull, _ := mustExpandAlias(firstURL)
+	secondAlias, secondURLFull, _ := mustExpandAlias(secondURL)
+
+	stateFile := getSyncStateFile(firstURLFull, secondURLFull)
+	state, err := loadSyncState(stateFile)
+	if err != nil {
+		return err.Trace(stateFile)
+	}
+	first, err := listSyncSide(firstAlias, firstURLFull)
+	if err != nil {
+		return err.Trace(firstURL)
+	}
+	second, err := listSyncSide(secondAlias, secondURLFull)
+	if err != nil {
+		return err.Trace(secondURL)
+	}
+
+	s := &syncer{
+		first:            first,
+		second:           second,
+		policy:           policy,
+		propagateDeletes: propagateDeletes,
+		previous:         state.Objects,
+		synced:           make(map[string]syncRecord),
+	}
+	var keys []string
+	for key := range first.objects {
+		keys = append(keys, key)
+	}
+	for key := range second.objects {
+		if _, ok := first.objects[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err = s.syncKey(key); err != nil {
+			errorIf(err.Trace(key), "Unable to sync ‘"+key+"’.")
+			// Synced again the next time as it was before.
+			if record, ok := s.previous[key]; ok {
+				s.synced[key] = record
+			}
+		}
+	}
+	if globalDryRun {
+		return nil
+	}
+	state = &syncState{Version: "1", First: firstURLFull, Second: secondURLFull, Objects: s.synced}
+	return state.save(stateFile).Trace(firstURL, secondURL)
+}
+
+func mainSync(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'sync' cli arguments.
+	checkSyncSyntax(ctx)
+
+	// Additional command specific theme customization.
+	console.SetColor("Sync", color.New(color.FgGreen, color.Bold))
+	console.SetColor("SyncRemove", color.New(color.FgRed, color.Bold))
+	console.SetColor("SyncConflict", color.New(color.FgYellow, color.Bold))
+
+	firstURL, secondURL := ctx.Args().Get(0), ctx.Args().Get(1)
+	err := doSync(firstURL, secondURL, ctx.String("conflict"), ctx.Bool("propagate-deletes"))
+	fatalIf(err.Trace(firstURL, secondURL), "Unable to sync ‘"+firstURL+"’ and ‘"+secondURL+"’.")
+}
@@ -0,0 +1,153 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// writeSyncFile - write data to name below dir, modified at modTime.
+func writeSyncFile(c *C, dir, name, data string, modTime time.Time) {
+	path := filepath.Join(dir, name)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(path, []byte(data), 0600), IsNil)
+	c.Assert(os.Chtimes(path, modTime, modTime), IsNil)
+}
+
+// readSyncFile - contents of name below dir, "<missing>" if there is none.
+func readSyncFile(c *C, dir, name string) string {
+	data, e := ioutil.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(e) {
+		return "<missing>"
+	}
+	c.Assert(e, IsNil)
+	return string(data)
+}
+
+func (s *TestSuite) TestSyncConflictPolicies(c *C) {
+	defer useTempMcConfig(c)()
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-sync-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	// The object of the first folder is larger, that of the second newer.
+	older, newer := time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	testCases := []struct {
+		policy         string
+		first, second  string
+		firstConflict  string
+		secondConflict string
+	}{
+		{syncConflictNewer, "newer", "newer", "<missing>", "<missing>"},
+		{syncConflictLarger, "larger first", "larger first", "<missing>", "<missing>"},
+		{syncConflictSource, "larger first", "larger first", "<missing>", "<missing>"},
+		{syncConflictTarget, "newer", "newer", "<missing>", "<missing>"},
+		{syncConflictSkip, "larger first", "newer", "<missing>", "<missing>"},
+		{syncConflictRename, "newer", "newer", "larger first", "larger first"},
+	}
+	for _, testCase := range testCases {
+		first, second := filepath.Join(root, testCase.policy, "first"), filepath.Join(root, testCase.policy, "second")
+		writeSyncFile(c, first, "only-first", "1", older)
+		writeSyncFile(c, second, "dir/only-second", "2", older)
+		writeSyncFile(c, first, "same", "same", older)
+		writeSyncFile(c, second, "same", "same", newer)
+		writeSyncFile(c, first, "both", "larger first", older)
+		writeSyncFile(c, second, "both", "newer", newer)
+
+		c.Assert(doSync(first, second, testCase.policy, false), IsNil)
+		for _, dir := range []string{first, second} {
+			c.Assert(readSyncFile(c, dir, "only-first"), Equals, "1")
+			c.Assert(readSyncFile(c, dir, "dir/only-second"), Equals, "2")
+			c.Assert(readSyncFile(c, dir, "same"), Equals, "same")
+		}
+		c.Assert(readSyncFile(c, first, "both"), Equals, testCase.first, Commentf("policy %s", testCase.policy))
+		c.Assert(readSyncFile(c, second, "both"), Equals, testCase.second, Commentf("policy %s", testCase.policy))
+		c.Assert(readSyncFile(c, first, "both"+syncConflictSuffix), Equals, testCase.firstConflict)
+		c.Assert(readSyncFile(c, second, "both"+syncConflictSuffix), Equals, testCase.secondConflict)
+	}
+
+	// Newer does not tell objects modified at the same time apart, they are skipped.
+	first, second := filepath.Join(root, "same-time", "first"), filepath.Join(root, "same-time", "second")
+	writeSyncFile(c, first, "both", "first", older)
+	writeSyncFile(c, second, "both", "second!", older)
+	c.Assert(doSync(first, second, syncConflictNewer, false), IsNil)
+	c.Assert(readSyncFile(c, first, "both"), Equals, "first")
+	c.Assert(readSyncFile(c, second, "both"), Equals, "second!")
+}
+
+func (s *TestSuite) TestSyncChanges(c *C) {
+	defer useTempMcConfig(c)()
+	savedQuiet := globalQuiet
+	globalQuiet = true
+	defer func() { globalQuiet = savedQuiet }()
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-sync-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	first, second := filepath.Join(root, "first"), filepath.Join(root, "second")
+	c.Assert(os.MkdirAll(second, 0700), IsNil)
+	modTime := time.Now().Add(-time.Hour)
+	for _, name := range []string{"changed", "both", "removed", "removed-changed"} {
+		writeSyncFile(c, first, name, name, modTime)
+	}
+	c.Assert(doSync(first, second, syncConflictTarget, false), IsNil)
+	for _, name := range []string{"changed", "both", "removed", "removed-changed"} {
+		c.Assert(readSyncFile(c, second, name), Equals, name)
+	}
+
+	// Changed on one side only it is copied over, whatever the policy for conflicts.
+	writeSyncFile(c, first, "changed", "changed in first", modTime.Add(time.Minute))
+	// Changed on both sides it is a conflict, even though the first is newer.
+	writeSyncFile(c, first, "both", "changed in first", modTime.Add(2*time.Minute))
+	writeSyncFile(c, second, "both", "in second", modTime.Add(time.Minute))
+	c.Assert(doSync(first, second, syncConflictTarget, false), IsNil)
+	c.Assert(readSyncFile(c, second, "changed"), Equals, "changed in first")
+	c.Assert(readSyncFile(c, first, "both"), Equals, "in second")
+	c.Assert(readSyncFile(c, second, "both"), Equals, "in second")
+
+	// Removals are undone unless propagated.
+	c.Assert(os.Remove(filepath.Join(first, "removed")), IsNil)
+	c.Assert(doSync(first, second, syncConflictNewer, false), IsNil)
+	c.Assert(readSyncFile(c, first, "removed"), Equals, "removed")
+
+	// Propagated, objects changed on the other side since are kept.
+	c.Assert(os.Remove(filepath.Join(first, "removed")), IsNil)
+	c.Assert(os.Remove(filepath.Join(first, "removed-changed")), IsNil)
+	writeSyncFile(c, second, "removed-changed", "changed in second", modTime.Add(time.Minute))
+	c.Assert(doSync(first, second, syncConflictNewer, true), IsNil)
+	c.Assert(readSyncFile(c, second, "removed"), Equals, "<missing>")
+	c.Assert(readSyncFile(c, first, "removed"), Equals, "<missing>")
+	c.Assert(readSyncFile(c, first, "removed-changed"), Equals, "changed in second")
+
+	// In sync, nothing changes any more.
+	c.Assert(doSync(first, second, syncConflictSkip, true), IsNil)
+	for _, name := range []string{"changed", "both", "removed-changed"} {
+		c.Assert(readSyncFile(c, first, name), Equals, readSyncFile(c, second, name))
+	}
+	state, err := loadSyncState(getSyncStateFile(first+string(os.PathSeparator), second+string(os.PathSeparator)))
+	c.Assert(err, IsNil)
+	c.Assert(state.Objects, HasLen, 3)
+}
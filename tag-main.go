@@ -0,0 +1,303 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// S3 tagging limits.
+const (
+	maxObjectTags     = 10
+	maxBucketTags     = 50
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+var (
+	tagFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of tag.",
+		},
+	}
+)
+
+// Manage object and bucket tags.
+var tagCmd = cli.Command{
+	Name:   "tag",
+	Usage:  "Manage tags of objects and buckets.",
+	Action: mainTag,
+	Flags:  append(tagFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] set TARGET KEY=VALUE [KEY=VALUE...]
+   mc {{.Name}} [FLAGS] list TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] remove TARGET [TARGET...]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Set tags on an object on Amazon S3 cloud storage, replacing any existing tags.
+      $ mc {{.Name}} set s3/jukebox/song.ogg genre=jazz year=1959
+
+   2. Set tags on a bucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} set s3/jukebox project=music
+
+   3. List tags of an object as JSON.
+      $ mc --json {{.Name}} list s3/jukebox/song.ogg
+
+   4. Remove all tags of an object.
+      $ mc {{.Name}} remove s3/jukebox/song.ogg
+`,
+}
+
+// tagMessage is container for tag command success messages.
+type tagMessage struct {
+	Operation string            `json:"operation"`
+	Status    string            `json:"status"`
+	Target    string            `json:"target"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// String colorized tag message.
+func (t tagMessage) String() string {
+	switch t.Operation {
+	case "set":
+		return console.Colorize("Tag", "Tags updated successfully for ‘"+t.Target+"’.")
+	case "remove":
+		return console.Colorize("Tag", "Tags removed successfully for ‘"+t.Target+"’.")
+	case "list":
+		if len(t.Tags) == 0 {
+			return console.Colorize("Tag", "No tags found for ‘"+t.Target+"’.")
+		}
+		keys := make([]string, 0, len(t.Tags))
+		for key := range t.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		message := console.Colorize("Tag", "Tags for ‘"+t.Target+"’:")
+		for _, key := range keys {
+			message += "\n" + console.Colorize("TagKey", "   "+key) + "=" + console.Colorize("TagValue", t.Tags[key])
+		}
+		return message
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified tag message.
+func (t tagMessage) JSON() string {
+	if t.Operation == "list" && t.Tags == nil {
+		t.Tags = map[string]string{}
+	}
+	tagJSONBytes, e := json.Marshal(t)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(tagJSONBytes)
+}
+
+// validTagChars - characters allowed by S3 in tag keys and values.
+var validTagChars = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// parseTags - parse KEY=VALUE arguments into a tag set.
+func parseTags(args []string) (map[string]string, *probe.Error) {
+	tags := make(map[string]string)
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalidTag(arg, "tags should be of the form KEY=VALUE").Trace(arg)
+		}
+		if _, ok := tags[kv[0]]; ok {
+			return nil, errInvalidTag(arg, "duplicate tag key").Trace(arg)
+		}
+		tags[kv[0]] = kv[1]
+	}
+	return tags, nil
+}
+
+// validateTags - validate a tag set against S3 count and length limits.
+func validateTags(tags map[string]string, maxTags int) *probe.Error {
+	if len(tags) > maxTags {
+		return errTooManyTags(len(tags), maxTags).Trace()
+	}
+	for key, value := range tags {
+		if key == "" {
+			return errInvalidTag(key, "tag key cannot be empty").Trace()
+		}
+		if utf8.RuneCountInString(key) > maxTagKeyLength {
+			return errInvalidTag(key, "tag key is longer than 128 characters").Trace(key)
+		}
+		if utf8.RuneCountInString(value) > maxTagValueLength {
+			return errInvalidTag(key, "tag value is longer than 256 characters").Trace(key)
+		}
+		if strings.HasPrefix(strings.ToLower(key), "aws:") {
+			return errInvalidTag(key, "tag keys with ‘aws:’ prefix are reserved").Trace(key)
+		}
+		if !validTagChars.MatchString(key) || !validTagChars.MatchString(value) {
+			return errInvalidTag(key, "tag contains unsupported characters").Trace(key)
+		}
+	}
+	return nil
+}
+
+// isBucketRootURL - true if URL resolves to a bucket root and not an object.
+func isBucketRootURL(targetURL client.URL) bool {
+	path := strings.Trim(targetURL.Path, string(targetURL.Separator))
+	return path != "" && !strings.Contains(path, string(targetURL.Separator))
+}
+
+// checkTagSyntax check for incoming syntax.
+func checkTagSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code.
+	}
+	switch ctx.Args().First() {
+	case "set":
+		if len(ctx.Args().Tail()) < 2 {
+			cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code.
+		}
+		tags, err := parseTags(ctx.Args().Tail().Tail())
+		fatalIf(err.Trace(ctx.Args()...), "Unable to parse tags.")
+		fatalIf(validateTags(tags, maxBucketTags).Trace(ctx.Args()...), "Unable to validate tags.")
+	case "list", "remove":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code.
+	}
+	for _, arg := range ctx.Args().Tail() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+// doSetTags do set tags.
+func doSetTags(targetURL string, tags map[string]string) *probe.Error {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	maxTags := maxObjectTags
+	if isBucketRootURL(clnt.GetURL()) {
+		maxTags = maxBucketTags
+	}
+	if err = validateTags(tags, maxTags); err != nil {
+		return err.Trace(targetURL)
+	}
+	if err = clnt.SetTags(tags); err != nil {
+		return err.Trace(targetURL)
+	}
+	return nil
+}
+
+// doListTags do list tags.
+func doListTags(targetURL string) (map[string]string, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	tags, err := clnt.GetTags()
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	return tags, nil
+}
+
+// doRemoveTags do remove tags.
+func doRemoveTags(targetURL string) *probe.Error {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if err = clnt.SetTags(map[string]string{}); err != nil {
+		return err.Trace(targetURL)
+	}
+	return nil
+}
+
+// mainTag - main handler for mc tag command.
+func mainTag(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'tag' cli arguments.
+	checkTagSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Tag", color.New(color.FgGreen, color.Bold))
+	console.SetColor("TagKey", color.New(color.FgCyan, color.Bold))
+	console.SetColor("TagValue", color.New(color.FgWhite))
+
+	switch ctx.Args().First() {
+	case "set":
+		targetURL := ctx.Args().Tail().First()
+		tags, err := parseTags(ctx.Args().Tail().Tail())
+		fatalIf(err.Trace(ctx.Args()...), "Unable to parse tags.")
+		err = doSetTags(targetURL, tags)
+		fatalIf(err.Trace(targetURL), "Unable to set tags for ‘"+targetURL+"’.")
+		if globalDryRun { // Already printed by the dry run client.
+			return
+		}
+		printMsg(tagMessage{
+			Status:    "success",
+			Operation: "set",
+			Target:    targetURL,
+		})
+	case "list":
+		for _, targetURL := range ctx.Args().Tail() {
+			tags, err := doListTags(targetURL)
+			if err != nil {
+				errorIf(err.Trace(targetURL), "Unable to list tags for ‘"+targetURL+"’.")
+				continue
+			}
+			printMsg(tagMessage{
+				Status:    "success",
+				Operation: "list",
+				Target:    targetURL,
+				Tags:      tags,
+			})
+		}
+	case "remove":
+		for _, targetURL := range ctx.Args().Tail() {
+			if err := doRemoveTags(targetURL); err != nil {
+				errorIf(err.Trace(targetURL), "Unable to remove tags for ‘"+targetURL+"’.")
+				continue
+			}
+			if globalDryRun {
+				continue
+			}
+			printMsg(tagMessage{
+				Status:    "success",
+				Operation: "remove",
+				Target:    targetURL,
+			})
+		}
+	}
+}
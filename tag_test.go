@@ -0,0 +1,68 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseTags(c *C) {
+	tags, err := parseTags([]string{"genre=jazz", "year=1959", "empty=", "expr=a=b"})
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"genre": "jazz", "year": "1959", "empty": "", "expr": "a=b"})
+
+	_, err = parseTags([]string{"genre"})
+	c.Assert(err, Not(IsNil))
+
+	_, err = parseTags([]string{"genre=jazz", "genre=blues"})
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *TestSuite) TestValidateTags(c *C) {
+	c.Assert(validateTags(map[string]string{"genre": "jazz", "path": "/a/b", "mail": "a@b.c"}, maxObjectTags), IsNil)
+	c.Assert(validateTags(map[string]string{"名前": "値"}, maxObjectTags), IsNil)
+
+	// Count limits.
+	tags := make(map[string]string)
+	for i := 0; i < maxObjectTags+1; i++ {
+		tags["key"+strconv.Itoa(i)] = "value"
+	}
+	c.Assert(validateTags(tags, maxObjectTags), Not(IsNil))
+	c.Assert(validateTags(tags, maxBucketTags), IsNil)
+
+	// Length limits.
+	c.Assert(validateTags(map[string]string{strings.Repeat("k", maxTagKeyLength): "v"}, maxObjectTags), IsNil)
+	c.Assert(validateTags(map[string]string{strings.Repeat("k", maxTagKeyLength+1): "v"}, maxObjectTags), Not(IsNil))
+	c.Assert(validateTags(map[string]string{"k": strings.Repeat("v", maxTagValueLength)}, maxObjectTags), IsNil)
+	c.Assert(validateTags(map[string]string{"k": strings.Repeat("v", maxTagValueLength+1)}, maxObjectTags), Not(IsNil))
+
+	// Reserved keys and invalid characters.
+	c.Assert(validateTags(map[string]string{"": "v"}, maxObjectTags), Not(IsNil))
+	c.Assert(validateTags(map[string]string{"aws:name": "v"}, maxObjectTags), Not(IsNil))
+	c.Assert(validateTags(map[string]string{"genre": "jazz&blues"}, maxObjectTags), Not(IsNil))
+}
+
+func (s *TestSuite) TestIsBucketRootURL(c *C) {
+	c.Assert(isBucketRootURL(*client.NewURL("https://s3.amazonaws.com/bucket")), Equals, true)
+	c.Assert(isBucketRootURL(*client.NewURL("https://s3.amazonaws.com/bucket/")), Equals, true)
+	c.Assert(isBucketRootURL(*client.NewURL("https://s3.amazonaws.com/bucket/object")), Equals, false)
+	c.Assert(isBucketRootURL(*client.NewURL("https://s3.amazonaws.com/")), Equals, false)
+}
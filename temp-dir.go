@@ -0,0 +1,108 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// globalTempDir - folder of spill files, and of part files of targets in read only folders, set
+// with ‘--temp-dir’. Empty uses the system temp folder, ‘TMPDIR’ if set.
+var globalTempDir string
+
+// spillFiles - temporary files in use, removed if interrupted.
+var spillFiles = struct {
+	sync.Mutex
+	files map[*os.File]bool
+}{files: make(map[*os.File]bool)}
+
+// getTempDir - folder temporary files are written to.
+func getTempDir() string {
+	if globalTempDir != "" {
+		return globalTempDir
+	}
+	return os.TempDir()
+}
+
+// setTempDir - write temporary files to dir, empty uses the system temp folder.
+func setTempDir(dir string) {
+	globalTempDir = dir
+	fs.SetTempDir(getTempDir())
+}
+
+// checkTempDir - dir must be a folder files can be created in.
+func checkTempDir(dir string) *probe.Error {
+	f, e := ioutil.TempFile(dir, "mc-check-")
+	if e != nil {
+		return errInvalidTempDir(dir).Trace(e.Error())
+	}
+	f.Close()
+	if e = os.Remove(f.Name()); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// newSpillFile - temporary file in the temp folder, removed by removeSpillFile or once interrupted.
+func newSpillFile() (*os.File, *probe.Error) {
+	f, e := ioutil.TempFile(getTempDir(), "mc-spill-")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	spillFiles.Lock()
+	spillFiles.files[f] = true
+	spillFiles.Unlock()
+	return f, nil
+}
+
+// removeSpillFile - close and remove the spill file f.
+func removeSpillFile(f *os.File) {
+	spillFiles.Lock()
+	delete(spillFiles.files, f)
+	spillFiles.Unlock()
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// removeSpillFiles - remove all spill files in use.
+func removeSpillFiles() {
+	spillFiles.Lock()
+	defer spillFiles.Unlock()
+	for f := range spillFiles.files {
+		f.Close()
+		os.Remove(f.Name())
+		delete(spillFiles.files, f)
+	}
+}
+
+// trapSpillFiles - remove spill files and exit once interrupted, for commands which are not
+// saved in a session to resume.
+func trapSpillFiles() {
+	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-trapCh
+		removeSpillFiles()
+		console.Fatalln("Interrupted, temporary files removed.")
+	}()
+}
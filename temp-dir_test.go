@@ -0,0 +1,97 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestCheckTempDir(c *C) {
+	root, e := ioutil.TempDir("", "mc-temp-dir-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	file := filepath.Join(root, "file")
+	c.Assert(ioutil.WriteFile(file, nil, 0600), IsNil)
+
+	c.Assert(checkTempDir(root), IsNil)
+	c.Assert(checkTempDir(filepath.Join(root, "missing")), NotNil)
+	c.Assert(checkTempDir(file), NotNil)
+	// Nothing is left behind by the check.
+	names, e := ioutil.ReadDir(root)
+	c.Assert(e, IsNil)
+	c.Assert(len(names), Equals, 1)
+}
+
+func (s *TestSuite) TestCopyExtractSpill(c *C) {
+	restore := useTempMcConfig(c)
+	defer restore()
+	root, e := ioutil.TempDir("", "mc-extract-spill-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	spillDir := filepath.Join(root, "spill")
+	c.Assert(os.MkdirAll(spillDir, 0700), IsNil)
+	setTempDir(spillDir)
+	defer setTempDir("")
+
+	// Spill files present while the member is uploaded are recorded.
+	var mutex sync.Mutex
+	var spilled []string
+	handler := &objectStoreHandler{objects: make(map[string]storedObject)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			names, _ := filepath.Glob(filepath.Join(spillDir, "mc-spill-*"))
+			mutex.Lock()
+			spilled = append(spilled, names...)
+			mutex.Unlock()
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	// Uploaded with a single PUT, the stub does not do multipart uploads.
+	c.Assert(setAlias("tar", hostConfigV7{URL: server.URL, AccessKey: "BKIKJAA5BMMU2RHO6IBB", SecretKey: "V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12", API: "S3v4", DisableMultipart: true}), IsNil)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), extractBufferSize/16+1)
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "large.bin", Size: int64(len(data)), Mode: 0640}), IsNil)
+	_, e = tw.Write(data)
+	c.Assert(e, IsNil)
+	c.Assert(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "small.txt", Size: 5, Mode: 0640}), IsNil)
+	_, e = tw.Write([]byte("small"))
+	c.Assert(e, IsNil)
+	c.Assert(tw.Close(), IsNil)
+
+	c.Assert(doCopyExtract(&archive, "tar/bucket/", 0), IsNil)
+	c.Assert(handler.objects["/bucket/large.bin"].data, DeepEquals, data)
+	c.Assert(handler.objects["/bucket/large.bin"].header.Get("X-Amz-Meta-Mode"), Equals, "0640")
+	c.Assert(handler.objects["/bucket/small.txt"].data, DeepEquals, []byte("small"))
+
+	// The large member was spilled to the temp folder, and removed once uploaded.
+	c.Assert(len(spilled) > 0, Equals, true)
+	names, e := ioutil.ReadDir(spillDir)
+	c.Assert(e, IsNil)
+	c.Assert(len(names), Equals, 0)
+}
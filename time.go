@@ -19,9 +19,83 @@ package main
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 )
 
+// Styles of timestamps in listings, selected with ‘ls --time-style’.
+const (
+	timeStyleDefault  = "default"  // printDate, in local time.
+	timeStyleISO      = "iso"      // RFC3339, in local time.
+	timeStyleFull     = "full"     // with nanoseconds and numeric zone, in local time.
+	timeStyleRelative = "relative" // such as "3 days ago".
+	timeStyleUnix     = "unix"     // seconds since the epoch.
+)
+
+// isValidTimeStyle - style is one of the time styles.
+func isValidTimeStyle(style string) bool {
+	switch style {
+	case timeStyleDefault, timeStyleISO, timeStyleFull, timeStyleRelative, timeStyleUnix:
+		return true
+	}
+	return false
+}
+
+// formatTime - t in globalTimeStyle, relative to now.
+func formatTime(t time.Time) string {
+	return formatTimeStyle(t, globalTimeStyle, time.Now())
+}
+
+// formatTimeStyle - t in the given style, relative times are relative to now. Unknown times
+// are printed as "-", except in the default style.
+func formatTimeStyle(t time.Time, style string, now time.Time) string {
+	if t.IsZero() && style != timeStyleDefault && style != "" {
+		return "-"
+	}
+	switch style {
+	case timeStyleISO:
+		return t.Format(time.RFC3339)
+	case timeStyleFull:
+		return t.Format("2006-01-02 15:04:05.000000000 -0700")
+	case timeStyleRelative:
+		return relativeTime(t, now)
+	case timeStyleUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.Format(printDate)
+}
+
+// relativeTime - t relative to now in its largest unit, such as "3 days ago" or "in 2 hours".
+func relativeTime(t, now time.Time) string {
+	duration := now.Sub(t)
+	isFuture := duration < 0
+	if isFuture {
+		duration = -duration
+	}
+	hTime := timeDurationToHumanizedTime(duration)
+	var count int64
+	var unit string
+	switch {
+	case hTime.Days > 0:
+		count, unit = hTime.Days, "day"
+	case hTime.Hours > 0:
+		count, unit = hTime.Hours, "hour"
+	case hTime.Minutes > 0:
+		count, unit = hTime.Minutes, "minute"
+	case hTime.Seconds > 0:
+		count, unit = hTime.Seconds, "second"
+	default:
+		return "just now"
+	}
+	if count != 1 {
+		unit += "s"
+	}
+	if isFuture {
+		return fmt.Sprintf("in %d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %s ago", count, unit)
+}
+
 // humanizedTime container to capture humanized time.
 type humanizedTime struct {
 	Days    int64 `json:"days,omitempty"`
@@ -0,0 +1,79 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Orders of ‘--order’, objects are transferred in the order they are listed by default.
+const (
+	orderSmallestFirst = "smallest-first"
+	orderLargestFirst  = "largest-first"
+)
+
+// checkTransferOrder - validate the order of ‘--order’.
+func checkTransferOrder(order string) {
+	if order != "" && order != orderSmallestFirst && order != orderLargestFirst {
+		fatalIf(errInvalidArgument().Trace(order), "Option --order is either ‘"+orderSmallestFirst+"’ or ‘"+orderLargestFirst+"’.")
+	}
+}
+
+// sessionDataLine - a prepared entry of the session data file and the bytes it transfers.
+type sessionDataLine struct {
+	line string
+	size int64
+}
+
+// orderedDataWriter writes prepared entries to the session data file in the order of ‘--order’. Without
+// an order entries are written as they are prepared, ordered they are held until all are prepared.
+// The data file keeps the order, a resumed session transfers in the same sequence.
+type orderedDataWriter struct {
+	writer io.Writer
+	order  string
+	lines  []sessionDataLine
+}
+
+// newOrderedDataWriter - entries written to writer in order.
+func newOrderedDataWriter(writer io.Writer, order string) *orderedDataWriter {
+	return &orderedDataWriter{writer: writer, order: order}
+}
+
+// add - add an entry transferring size bytes.
+func (w *orderedDataWriter) add(line string, size int64) {
+	if w.order == "" {
+		fmt.Fprintln(w.writer, line)
+		return
+	}
+	w.lines = append(w.lines, sessionDataLine{line: line, size: size})
+}
+
+// flush - write the entries held, entries of the same size stay in the order they were listed.
+func (w *orderedDataWriter) flush() {
+	sort.SliceStable(w.lines, func(i, j int) bool {
+		if w.order == orderLargestFirst {
+			return w.lines[i].size > w.lines[j].size
+		}
+		return w.lines[i].size < w.lines[j].size
+	})
+	for _, l := range w.lines {
+		fmt.Fprintln(w.writer, l.line)
+	}
+	w.lines = nil
+}
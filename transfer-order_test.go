@@ -0,0 +1,122 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// transferOrderRenderer - records the sources of copy and mirror messages in the order they are printed.
+type transferOrderRenderer struct {
+	sources []string
+}
+
+func (r *transferOrderRenderer) Render(msg message) string {
+	switch m := msg.(type) {
+	case copyMessage:
+		r.sources = append(r.sources, filepath.Base(m.Source))
+	case mirrorMessage:
+		r.sources = append(r.sources, filepath.Base(m.Source))
+	}
+	return ""
+}
+
+func (r *transferOrderRenderer) Flush() string { return "" }
+
+func (s *TestSuite) TestOrderedDataWriter(c *C) {
+	for order, expected := range map[string]string{
+		"":                 "a b c d",
+		orderSmallestFirst: "b d c a",
+		orderLargestFirst:  "a c b d",
+	} {
+		var buffer bytes.Buffer
+		writer := newOrderedDataWriter(&buffer, order)
+		writer.add("a", 30)
+		writer.add("b", 10)
+		writer.add("c", 20)
+		writer.add("d", 10)
+		writer.flush()
+		c.Assert(strings.Fields(buffer.String()), DeepEquals, strings.Fields(expected), Commentf("order %q", order))
+	}
+}
+
+func (s *TestSuite) TestTransferOrder(c *C) {
+	defer useTempMcConfig(c)()
+	renderer := new(transferOrderRenderer)
+	savedQuiet, savedRenderer, savedMaxConns := globalQuiet, globalOutputRenderer, globalMaxConnsPerHost
+	// A single worker transfers objects one at a time, in the order they are dispatched.
+	globalQuiet, globalOutputRenderer, globalMaxConnsPerHost = true, renderer, 1
+	defer func() {
+		globalQuiet, globalOutputRenderer, globalMaxConnsPerHost = savedQuiet, savedRenderer, savedMaxConns
+	}()
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-order-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source") + string(filepath.Separator)
+	// Listed by name, neither smallest nor largest first.
+	sizes := map[string]int{"alpha": 2000, "bravo": 9000, "charlie": 10, "delta": 300, "echo": 5000}
+	c.Assert(os.MkdirAll(source, 0700), IsNil)
+	for name, size := range sizes {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), bytes.Repeat([]byte("x"), size), 0600), IsNil)
+	}
+	smallestFirst := []string{"charlie", "delta", "alpha", "echo", "bravo"}
+	largestFirst := []string{"bravo", "echo", "alpha", "delta", "charlie"}
+
+	// sessionOrder - sources of the session data file, in the order a resumed session transfers them.
+	sessionOrder := func(session *sessionV6) []string {
+		var sources []string
+		scanner := bufio.NewScanner(session.NewDataReader())
+		for scanner.Scan() {
+			var entry struct {
+				SourceContent struct{ URL struct{ Path string } }
+			}
+			c.Assert(json.Unmarshal(scanner.Bytes(), &entry), IsNil)
+			sources = append(sources, filepath.Base(entry.SourceContent.URL.Path))
+		}
+		return sources
+	}
+
+	for order, expected := range map[string][]string{orderSmallestFirst: smallestFirst, orderLargestFirst: largestFirst} {
+		renderer.sources = nil
+		session := newTestCopySession(c, []string{source}, filepath.Join(root, "cp-"+order)+string(filepath.Separator), 1, false)
+		session.Header.CommandBoolFlags["recursive"] = true
+		session.Header.CommandStringFlags["order"] = order
+		c.Assert(doCopySession(session).exitCode(), Equals, 0)
+		c.Assert(renderer.sources, DeepEquals, expected, Commentf("cp --order %s", order))
+		c.Assert(sessionOrder(session), DeepEquals, expected, Commentf("cp --order %s", order))
+		session.Delete()
+
+		renderer.sources = nil
+		session = newSessionV6()
+		session.Header.CommandType = "mirror"
+		session.Header.CommandArgs = []string{source, filepath.Join(root, "mirror-"+order) + string(filepath.Separator)}
+		session.Header.CommandStringFlags["order"] = order
+		c.Assert(doMirrorSession(session).exitCode(), Equals, 0)
+		c.Assert(renderer.sources, DeepEquals, expected, Commentf("mirror --order %s", order))
+		c.Assert(sessionOrder(session), DeepEquals, expected, Commentf("mirror --order %s", order))
+		session.Delete()
+	}
+}
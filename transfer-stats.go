@@ -0,0 +1,217 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/httptracer"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// throughputBounds - upper bounds of the throughput histogram in bytes per second, the last
+// bucket holds everything faster.
+var throughputBounds = []int64{64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20}
+
+// transferStats collects, with ‘--stats’, the throughput of every object transferred and the
+// time requests spent in each phase. Requests of clients created while it is globalTransferStats are timed.
+type transferStats struct {
+	mutex    sync.Mutex
+	objects  []int // objects transferred per throughput bucket.
+	requests int
+	phases   httptracer.Phases // summed over all requests.
+}
+
+// newTransferStats - instantiate empty transfer statistics.
+func newTransferStats() *transferStats {
+	return &transferStats{objects: make([]int, len(throughputBounds)+1)}
+}
+
+// getSessionTransferStats - empty statistics for ‘--stats’ of session, nil without it.
+func getSessionTransferStats(session *sessionV6) *transferStats {
+	if !session.Header.CommandBoolFlags["stats"] {
+		return nil
+	}
+	return newTransferStats()
+}
+
+// RecordPhases - account for the phases of a request.
+func (t *transferStats) RecordPhases(p httptracer.Phases) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.requests++
+	t.phases.DNS += p.DNS
+	t.phases.Connect += p.Connect
+	t.phases.TLS += p.TLS
+	t.phases.FirstByte += p.FirstByte
+	t.phases.Transfer += p.Transfer
+}
+
+// Transferred - account for size bytes of an object transferred in elapsed time. Empty objects
+// tell nothing about throughput and are left out. A nil transferStats ignores it.
+func (t *transferStats) Transferred(size int64, elapsed time.Duration) {
+	if t == nil || size <= 0 {
+		return
+	}
+	bucket := len(throughputBounds)
+	if elapsed > 0 {
+		speed := int64(float64(size) / elapsed.Seconds())
+		for i, bound := range throughputBounds {
+			if speed < bound {
+				bucket = i
+				break
+			}
+		}
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.objects[bucket]++
+}
+
+// Message - captures the statistics into a message.
+func (t *transferStats) Message() transferStatsMessage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	msg := transferStatsMessage{
+		Requests: t.requests,
+		Phases: phaseSeconds{
+			DNS:       t.phases.DNS.Seconds(),
+			Connect:   t.phases.Connect.Seconds(),
+			TLS:       t.phases.TLS.Seconds(),
+			FirstByte: t.phases.FirstByte.Seconds(),
+			Transfer:  t.phases.Transfer.Seconds(),
+		},
+	}
+	var min int64
+	for i, objects := range t.objects {
+		bucket := throughputBucket{Min: min, Objects: objects}
+		if i < len(throughputBounds) {
+			bucket.Max = throughputBounds[i]
+			min = bucket.Max
+		}
+		msg.Objects += objects
+		msg.Throughput = append(msg.Throughput, bucket)
+	}
+	return msg
+}
+
+// printTransferStats - prints the statistics with ‘--stats’, quiet mode suppresses them.
+func printTransferStats(stats *transferStats) {
+	if stats == nil || globalQuiet {
+		return
+	}
+	printMsg(stats.Message())
+}
+
+// throughputBucket - objects transferred at Min up to Max bytes per second, any faster if Max is zero.
+type throughputBucket struct {
+	Min     int64 `json:"min"`
+	Max     int64 `json:"max,omitempty"`
+	Objects int   `json:"objects"`
+}
+
+// phaseSeconds - seconds spent by all requests in each phase.
+type phaseSeconds struct {
+	DNS       float64 `json:"dnsSeconds"`
+	Connect   float64 `json:"connectSeconds"`
+	TLS       float64 `json:"tlsSeconds"`
+	FirstByte float64 `json:"firstByteSeconds"`
+	Transfer  float64 `json:"transferSeconds"`
+}
+
+// transferStatsMessage container for the statistics printed with ‘--stats’.
+type transferStatsMessage struct {
+	Status     string             `json:"status"`
+	Objects    int                `json:"objects"`
+	Throughput []throughputBucket `json:"throughput"`
+	Requests   int                `json:"requests"`
+	Phases     phaseSeconds       `json:"phases"`
+}
+
+// String colorized histogram of throughput, with the buckets from the slowest to the fastest
+// object, followed by the time spent in each phase.
+func (s transferStatsMessage) String() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Throughput of %d objects:", s.Objects))
+	first, last, most := -1, -1, 0
+	for i, bucket := range s.Throughput {
+		if bucket.Objects == 0 {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		if bucket.Objects > most {
+			most = bucket.Objects
+		}
+	}
+	for i := first; first >= 0 && i <= last; i++ {
+		bucket := s.Throughput[i]
+		var label string
+		switch {
+		case bucket.Max == 0:
+			label = fmt.Sprintf(">= %s/s", humanize.IBytes(uint64(bucket.Min)))
+		case bucket.Min == 0:
+			label = fmt.Sprintf("< %s/s", humanize.IBytes(uint64(bucket.Max)))
+		default:
+			label = fmt.Sprintf("%s/s - %s/s", humanize.IBytes(uint64(bucket.Min)), humanize.IBytes(uint64(bucket.Max)))
+		}
+		bar := strings.Repeat("▇", (bucket.Objects*30+most-1)/most)
+		lines = append(lines, fmt.Sprintf("  %-24s %6d %s", label, bucket.Objects, bar))
+	}
+
+	phases := []struct {
+		name    string
+		seconds float64
+	}{
+		{"DNS", s.Phases.DNS},
+		{"Connect", s.Phases.Connect},
+		{"TLS", s.Phases.TLS},
+		{"First byte", s.Phases.FirstByte},
+		{"Transfer", s.Phases.Transfer},
+	}
+	var total float64
+	for _, phase := range phases {
+		total += phase.seconds
+	}
+	lines = append(lines, fmt.Sprintf("Time spent by %d requests:", s.Requests))
+	for _, phase := range phases {
+		var share float64
+		if total > 0 {
+			share = phase.seconds / total * 100
+		}
+		elapsed := time.Duration(phase.seconds * float64(time.Second)).Round(time.Millisecond)
+		lines = append(lines, fmt.Sprintf("  %-10s %12s %5.1f%%", phase.name, elapsed, share))
+	}
+	return console.Colorize("Summary", strings.Join(lines, "\n"))
+}
+
+// JSON jsonified statistics message.
+func (s transferStatsMessage) JSON() string {
+	s.Status = "success"
+	statsBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(statsBytes)
+}
@@ -0,0 +1,141 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// tree specific flags.
+var (
+	treeFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of tree.",
+		},
+		cli.IntFlag{
+			Name:  "depth, d",
+			Usage: "Show only N levels of folders, 0 shows all levels.",
+		},
+		cli.BoolFlag{
+			Name:  "files, f",
+			Usage: "Show files along with folders, the default unless --dirs-only is set.",
+		},
+		cli.BoolFlag{
+			Name:  "dirs-only",
+			Usage: "Show only folders, leaving out files.",
+		},
+	}
+)
+
+// show the tree of files and folders.
+var treeCmd = cli.Command{
+	Name:   "tree",
+	Usage:  "List files and folders in a tree.",
+	Action: mainTree,
+	Flags:  append(treeFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET [TARGET ...]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Show the tree of files and folders of mybucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} s3/mybucket
+
+   2. Show the tree of folders only, two levels deep, of a local folder.
+      $ mc {{.Name}} --dirs-only --depth 2 ~/Photos
+
+   3. Show the tree of mybucket on Amazon S3 cloud storage as a single nested JSON document.
+      $ mc --json {{.Name}} s3/mybucket/backups/
+`,
+}
+
+// checkTreeSyntax - validate all the passed arguments
+func checkTreeSyntax(ctx *cli.Context) {
+	args := ctx.Args()
+	if !ctx.Args().Present() {
+		args = []string{"."}
+	}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(args...), "Unable to validate empty argument.")
+		}
+	}
+	if ctx.Int("depth") < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("depth")), "Depth cannot be negative.")
+	}
+	if ctx.Bool("files") && ctx.Bool("dirs-only") {
+		fatalIf(errInvalidArgument().Trace(), "Options --files and --dirs-only cannot be used together.")
+	}
+	for _, url := range args {
+		_, content, err := url2Stat(url)
+		if err != nil && !isURLPrefixExists(url, false) {
+			fatalIf(err.Trace(url), "Unable to stat ‘"+url+"’.")
+		}
+		if err == nil && !content.Type.IsDir() {
+			fatalIf(errInvalidArgument().Trace(url), "‘"+url+"’ is not a folder.")
+		}
+	}
+}
+
+// mainTree - is a handler for mc tree command
+func mainTree(ctx *cli.Context) {
+	// Additional command speific theme customization.
+	console.SetColor("File", color.New(color.FgWhite))
+	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
+
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'tree' cli arguments.
+	checkTreeSyntax(ctx)
+
+	depth := ctx.Int("depth")
+	dirsOnly := ctx.Bool("dirs-only")
+
+	args := ctx.Args()
+	// mimic operating system tool behavior.
+	if !ctx.Args().Present() {
+		args = []string{"."}
+	}
+
+	for _, targetURL := range args {
+		alias, _, _ := mustExpandAlias(targetURL)
+		newFolderClient := func(urlStr string) (client.Client, *probe.Error) {
+			return newClientFromAlias(alias, urlStr)
+		}
+		// Trees are of folders, list them with their entries.
+		folderURL := targetURL
+		if separator := string(client.NewURL(targetURL).Separator); !strings.HasSuffix(folderURL, separator) {
+			folderURL = folderURL + separator
+		}
+		clnt, err := newClient(folderURL)
+		fatalIf(err.Trace(targetURL), "Unable to initialize target ‘"+targetURL+"’.")
+		doTree(clnt, targetURL, newFolderClient, depth, dirsOnly)
+	}
+}
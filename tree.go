@@ -0,0 +1,298 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Connectors of tree lines.
+const (
+	treeBranch     = "├── "
+	treeLastBranch = "└── "
+	treeIndent     = "│   "
+	treeLastIndent = "    "
+)
+
+// treeEntry - a file or folder of a tree, named relative to its folder. Folders are named with a
+// trailing separator.
+type treeEntry struct {
+	Name  string
+	IsDir bool
+	URL   string
+	Size  int64
+	Time  time.Time
+}
+
+// treePrinter - prints a tree while it is walked.
+type treePrinter interface {
+	// root - the folder the tree is of.
+	root(name string)
+	// file - a file, the last entry of its folder if isLast.
+	file(entry treeEntry, isLast bool)
+	// openFolder - a folder, its entries follow unless it is beyond the depth shown.
+	openFolder(entry treeEntry, isLast, isExpanded bool)
+	// closeFolder - all entries of the last opened folder are printed.
+	closeFolder(isExpanded bool)
+	// done - the whole tree is printed.
+	done(folders, files int)
+}
+
+// treeLineMessage - a line of a tree printed as text.
+type treeLineMessage struct {
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+	IsDir  bool   `json:"isDir"`
+}
+
+// String colorized tree line.
+func (t treeLineMessage) String() string {
+	if t.IsDir {
+		return t.Prefix + console.Colorize("Dir", t.Name)
+	}
+	return t.Prefix + console.Colorize("File", t.Name)
+}
+
+// JSON jsonified tree line, trees are printed as JSON by treeJSONPrinter.
+func (t treeLineMessage) JSON() string {
+	jsonMessageBytes, e := json.Marshal(t)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// treeTextPrinter - prints a tree with box-drawing connectors, a line per entry.
+type treeTextPrinter struct {
+	// indents of the folders on the current branch.
+	indents []string
+}
+
+func (p *treeTextPrinter) root(name string) {
+	printMsg(treeLineMessage{Name: name, IsDir: true})
+}
+
+func (p *treeTextPrinter) line(entry treeEntry, isLast bool) {
+	connector := treeBranch
+	if isLast {
+		connector = treeLastBranch
+	}
+	printMsg(treeLineMessage{Prefix: strings.Join(p.indents, "") + connector, Name: entry.Name, IsDir: entry.IsDir})
+}
+
+func (p *treeTextPrinter) file(entry treeEntry, isLast bool) {
+	p.line(entry, isLast)
+}
+
+func (p *treeTextPrinter) openFolder(entry treeEntry, isLast, isExpanded bool) {
+	p.line(entry, isLast)
+	if !isExpanded {
+		return
+	}
+	indent := treeIndent
+	if isLast {
+		indent = treeLastIndent
+	}
+	p.indents = append(p.indents, indent)
+}
+
+func (p *treeTextPrinter) closeFolder(isExpanded bool) {
+	if isExpanded {
+		p.indents = p.indents[:len(p.indents)-1]
+	}
+}
+
+func (p *treeTextPrinter) done(folders, files int) {
+	console.Println()
+	console.Println(fmt.Sprintf("%d folders, %d files", folders, files))
+}
+
+// treeNode - fields of a file or folder of a tree printed as JSON.
+type treeNode struct {
+	Status string     `json:"status,omitempty"`
+	Name   string     `json:"name"`
+	Type   string     `json:"type"`
+	Size   int64      `json:"size,omitempty"`
+	Time   *time.Time `json:"lastModified,omitempty"`
+}
+
+// treeJSONPrinter - prints a tree as a single JSON document, folders holding their entries in
+// "children". The document is printed while the tree is walked, without holding it.
+type treeJSONPrinter struct {
+	// whether the folders on the current branch have no entries printed yet.
+	isFirst []bool
+}
+
+// marshalNode - node as a JSON object left open if isOpen, for its children to follow.
+func (p *treeJSONPrinter) marshalNode(node treeNode, isOpen bool) string {
+	nodeBytes, e := json.Marshal(node)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	if !isOpen {
+		return string(nodeBytes)
+	}
+	return strings.TrimSuffix(string(nodeBytes), "}") + `,"children":[`
+}
+
+// print - node as the next entry of its folder.
+func (p *treeJSONPrinter) print(entry treeEntry, isOpen bool) {
+	node := treeNode{Name: entry.Name, Type: "file", Size: entry.Size}
+	if entry.IsDir {
+		node.Type = "folder"
+	}
+	if !entry.Time.IsZero() {
+		modTime := entry.Time.Local()
+		node.Time = &modTime
+	}
+	separator := ","
+	if last := len(p.isFirst) - 1; p.isFirst[last] {
+		separator = ""
+		p.isFirst[last] = false
+	}
+	console.Print(separator + p.marshalNode(node, isOpen))
+}
+
+func (p *treeJSONPrinter) root(name string) {
+	console.Print(p.marshalNode(treeNode{Status: "success", Name: name, Type: "folder"}, true))
+	p.isFirst = append(p.isFirst, true)
+}
+
+func (p *treeJSONPrinter) file(entry treeEntry, isLast bool) {
+	p.print(entry, false)
+}
+
+func (p *treeJSONPrinter) openFolder(entry treeEntry, isLast, isExpanded bool) {
+	p.print(entry, isExpanded)
+	if isExpanded {
+		p.isFirst = append(p.isFirst, true)
+	}
+}
+
+func (p *treeJSONPrinter) closeFolder(isExpanded bool) {
+	if isExpanded {
+		p.isFirst = p.isFirst[:len(p.isFirst)-1]
+		console.Print("]}")
+	}
+}
+
+func (p *treeJSONPrinter) done(folders, files int) {
+	console.Println("]}")
+}
+
+// treeWalker - walks a tree a folder at a time, holding only the entries of the folders on the
+// current branch. Recursive listings cannot tell which entry of a folder is its last.
+type treeWalker struct {
+	// newClient - client of each folder walked.
+	newClient func(urlStr string) (client.Client, *probe.Error)
+	// depth - levels of folders shown, 0 shows all.
+	depth int
+	// dirsOnly - files are left out.
+	dirsOnly bool
+	printer  treePrinter
+
+	folders, files int
+}
+
+// listTreeFolder - entries of the folder listed by clnt, folders first, each sorted by name.
+func (t *treeWalker) listTreeFolder(clnt client.Client) []treeEntry {
+	folderURL := clnt.GetURL()
+	separator := string(folderURL.Separator)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	var entries []treeEntry
+	for content := range clnt.List(false, false, doneCh) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(folderURL.String()), "Unable to list folder.")
+			continue
+		}
+		isDir := content.Type.IsDir()
+		if t.dirsOnly && !isDir {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(content.URL.Path, folderURL.Path), separator)
+		if name == "" {
+			// The folder itself.
+			continue
+		}
+		entry := treeEntry{Name: name, IsDir: isDir, Size: content.Size, Time: content.Time}
+		if isDir {
+			entry.Name = name + separator
+			contentURL := content.URL
+			contentURL.Path = strings.TrimSuffix(contentURL.Path, separator) + separator
+			entry.URL = contentURL.String()
+			// Sizes of folders and times of listed prefixes mean nothing.
+			entry.Size, entry.Time = 0, time.Time{}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Sort(treeEntries(entries))
+	return entries
+}
+
+// walk - print the entries of the folder listed by clnt at level, 1 for entries of the root.
+func (t *treeWalker) walk(clnt client.Client, level int) {
+	entries := t.listTreeFolder(clnt)
+	for i, entry := range entries {
+		isLast := i == len(entries)-1
+		if !entry.IsDir {
+			t.files++
+			t.printer.file(entry, isLast)
+			continue
+		}
+		t.folders++
+		isExpanded := t.depth == 0 || level < t.depth
+		t.printer.openFolder(entry, isLast, isExpanded)
+		if isExpanded {
+			folderClnt, err := t.newClient(entry.URL)
+			if err != nil {
+				errorIf(err.Trace(entry.URL), "Unable to initialize folder ‘"+entry.URL+"’.")
+			} else {
+				t.walk(folderClnt, level+1)
+			}
+		}
+		t.printer.closeFolder(isExpanded)
+	}
+}
+
+// doTree - print the tree of the folder listed by clnt, named rootName.
+func doTree(clnt client.Client, rootName string, newClient func(urlStr string) (client.Client, *probe.Error), depth int, dirsOnly bool) {
+	var printer treePrinter = &treeTextPrinter{}
+	if globalJSON {
+		printer = &treeJSONPrinter{}
+	}
+	walker := &treeWalker{newClient: newClient, depth: depth, dirsOnly: dirsOnly, printer: printer}
+	printer.root(rootName)
+	walker.walk(clnt, 1)
+	printer.done(walker.folders, walker.files)
+}
+
+// treeEntries - entries of a folder, folders sort before files.
+type treeEntries []treeEntry
+
+func (t treeEntries) Len() int      { return len(t) }
+func (t treeEntries) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t treeEntries) Less(i, j int) bool {
+	if t[i].IsDir != t[j].IsDir {
+		return t[i].IsDir
+	}
+	return t[i].Name < t[j].Name
+}
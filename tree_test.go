@@ -0,0 +1,109 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// captureTree - tree of root as printed, as JSON if isJSON.
+func captureTree(c *C, root string, depth int, dirsOnly, isJSON bool) string {
+	var buffer bytes.Buffer
+	savedOutput, savedJSON, savedNoColor := color.Output, globalJSON, color.NoColor
+	color.Output, globalJSON, color.NoColor = &buffer, isJSON, true
+	defer func() { color.Output, globalJSON, color.NoColor = savedOutput, savedJSON, savedNoColor }()
+
+	clnt, err := fs.New(root + string(filepath.Separator))
+	c.Assert(err, IsNil)
+	newFolderClient := func(urlStr string) (client.Client, *probe.Error) {
+		return fs.New(urlStr)
+	}
+	doTree(clnt, "root", newFolderClient, depth, dirsOnly)
+	return buffer.String()
+}
+
+func (s *TestSuite) TestTree(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mc-tree-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"a.txt", "b/x.txt", "b/c/y.txt", "z.txt"} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(root, name), []byte("tree"), 0600), IsNil)
+	}
+	c.Assert(os.Mkdir(filepath.Join(root, "d"), 0700), IsNil)
+
+	// Folders are shown before files.
+	c.Assert(captureTree(c, root, 0, false, false), Equals, `root
+├── b/
+│   ├── c/
+│   │   └── y.txt
+│   └── x.txt
+├── d/
+├── a.txt
+└── z.txt
+
+3 folders, 4 files
+`)
+	c.Assert(captureTree(c, root, 1, false, false), Equals, `root
+├── b/
+├── d/
+├── a.txt
+└── z.txt
+
+2 folders, 2 files
+`)
+	c.Assert(captureTree(c, root, 2, true, false), Equals, `root
+├── b/
+│   └── c/
+└── d/
+
+3 folders, 0 files
+`)
+
+	// JSON is a single document, folders holding their entries.
+	type node struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Size     int64  `json:"size"`
+		Children []node `json:"children"`
+	}
+	var tree node
+	c.Assert(json.Unmarshal([]byte(captureTree(c, root, 0, false, true)), &tree), IsNil)
+	c.Assert(tree.Name, Equals, "root")
+	c.Assert(tree.Children, HasLen, 4)
+	c.Assert(tree.Children[0].Name, Equals, "b/")
+	c.Assert(tree.Children[0].Type, Equals, "folder")
+	c.Assert(tree.Children[0].Size, Equals, int64(0))
+	c.Assert(tree.Children[0].Children, HasLen, 2)
+	c.Assert(tree.Children[0].Children[0].Children[0].Name, Equals, "y.txt")
+	c.Assert(tree.Children[0].Children[1].Size, Equals, int64(4))
+	c.Assert(tree.Children[1].Children, HasLen, 0)
+	c.Assert(tree.Children[3].Type, Equals, "file")
+	var shallow node
+	c.Assert(json.Unmarshal([]byte(captureTree(c, root, 1, false, true)), &shallow), IsNil)
+	c.Assert(shallow.Children[0].Children, IsNil)
+}
@@ -18,7 +18,11 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -43,6 +47,10 @@ var (
 		return probe.NewError(errors.New("Invalid target ‘" + URL + "’.")).Untrace()
 	}
 
+	errListingNotSorted = func(URL, key string) *probe.Error {
+		return probe.NewError(errors.New("Listing of ‘" + URL + "’ is not in lexical order at ‘" + key + "’.")).Untrace()
+	}
+
 	errOverWriteNotAllowed = func(URL string) *probe.Error {
 		return probe.NewError(errors.New("Overwrite not allowed for ‘" + URL + "’. Use ‘--force’ to override this behavior."))
 	}
@@ -54,4 +62,216 @@ var (
 	errSourceTargetSame = func(URL string) *probe.Error {
 		return probe.NewError(errors.New("Source and target URL can not be same : " + URL)).Untrace()
 	}
+
+	errInvalidTag = func(tag, reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid tag ‘" + tag + "’, " + reason + ".")).Untrace()
+	}
+
+	errInvalidMetadata = func(key, reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid metadata ‘" + key + "’, " + reason + ".")).Untrace()
+	}
+
+	errInvalidEncryptKey = func(pattern, reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid encryption key ‘" + pattern + "’, " + reason + ".")).Untrace()
+	}
+
+	errInsecureKeyFile = func(filename string) *probe.Error {
+		return probe.NewError(errors.New("Key file ‘" + filename + "’ is accessible by other users, restrict its permissions to 0600.")).Untrace()
+	}
+
+	errBucketNotVersioned = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Versioning was never enabled for the bucket of ‘" + URL + "’, there are no previous versions to restore.")).Untrace()
+	}
+
+	errCacheControlReupload = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Server cannot replace metadata of ‘" + URL + "’ in place, cache control is lost on re-upload.")).Untrace()
+	}
+
+	errTooManyTags = func(count, max int) *probe.Error {
+		return probe.NewError(fmt.Errorf("Too many tags ‘%d’, maximum allowed is ‘%d’.", count, max)).Untrace()
+	}
+
+	errInvalidRetention = func(arg, reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid retention ‘" + arg + "’, " + reason + ".")).Untrace()
+	}
+
+	errInvalidPolicy = func(reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid policy document, " + reason + ".")).Untrace()
+	}
+
+	errInvalidNotifyURL = func(URL, reason string) *probe.Error {
+		return probe.NewError(errors.New("Invalid notify URL ‘" + URL + "’, " + reason + ".")).Untrace()
+	}
+
+	errLockTimeout = func(lockFile string) *probe.Error {
+		return probe.NewError(errors.New("Timed out waiting for lock ‘" + lockFile + "’, remove it if no other mc is running.")).Untrace()
+	}
+
+	errPartialFailure = func(failed int) *probe.Error {
+		return probe.NewError(fmt.Errorf("Failed to transfer ‘%d’ object(s), all others were transferred.", failed)).Untrace()
+	}
+
+	errAtomicTooLarge = func(target string) *probe.Error {
+		return probe.NewError(errors.New("Object ‘" + target + "’ is larger than 5GB, the most a server side copy moves, it can not be copied with ‘--atomic’.")).Untrace()
+	}
+
+	errInvalidChecksumAlgorithm = func(algorithm string) *probe.Error {
+		return probe.NewError(errors.New("Invalid checksum algorithm ‘" + algorithm + "’, choose one of CRC32, CRC32C, SHA1 or SHA256.")).Untrace()
+	}
+
+	errInvalidStorageClass = func(storageClass string) *probe.Error {
+		return probe.NewError(errors.New("Invalid storage class ‘" + storageClass + "’, storage classes are upper case such as ‘STANDARD_IA’.")).Untrace()
+	}
+
+	errInvalidRegion = func(region string) *probe.Error {
+		return probe.NewError(errors.New("Invalid region ‘" + region + "’, regions are lower case such as ‘eu-west-1’.")).Untrace()
+	}
+
+	errUnknownStorageClass = func(storageClass string) *probe.Error {
+		return probe.NewError(errors.New("Storage class ‘" + storageClass + "’ is not known to Amazon S3, the target may still support it.")).Untrace()
+	}
+
+	errFlattenCollision = func(source, target string) *probe.Error {
+		return probe.NewError(errors.New("Unable to flatten ‘" + source + "’, ‘" + target + "’ is the target of another object. Use ‘--on-collision suffix’ to copy it under another name.")).Untrace()
+	}
+
+	errSourceNotBelowBase = func(URL, base string) *probe.Error {
+		return probe.NewError(errors.New("Source ‘" + URL + "’ is not below base ‘" + base + "’.")).Untrace()
+	}
+
+	errRmNeedsForce = func() *probe.Error {
+		return probe.NewError(errors.New("Recursive removal requires --force option. Please review carefully before performing this *DANGEROUS* operation.")).Untrace()
+	}
+
+	errRmNotConfirmed = func(bucket string) *probe.Error {
+		return probe.NewError(errors.New("Removal from the root of bucket ‘" + bucket + "’ was not confirmed, use --force to remove without confirmation.")).Untrace()
+	}
+
+	errRbNotConfirmed = func(bucket string) *probe.Error {
+		return probe.NewError(errors.New("Removal of bucket ‘" + bucket + "’ and everything in it was not confirmed, use --quiet to remove without confirmation.")).Untrace()
+	}
+
+	errTransactionInterrupted = func() *probe.Error {
+		return probe.NewError(errors.New("Copy was interrupted, the objects of the transaction copied so far were removed again.")).Untrace()
+	}
+
+	errRmAcrossBuckets = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Removal of ‘" + URL + "’ spans more than one bucket, remove from one bucket at a time.")).Untrace()
+	}
+
+	errSessionVersion = func(sid, version string) *probe.Error {
+		return probe.NewError(errors.New("Session ‘" + sid + "’ is version ‘" + version + "’, which cannot be resumed.")).Untrace()
+	}
+
+	errTotalTimeout = func(timeout time.Duration) *probe.Error {
+		return probe.NewError(errors.New("Transfer did not complete within " + timeout.String() + ", resume the session to continue.")).Untrace()
+	}
+
+	errClockSkewed = func(endpoint string, offset time.Duration) *probe.Error {
+		return probe.NewError(fmt.Errorf("Local clock is off by %d seconds from ‘%s’.", int64(offset/time.Second), endpoint)).Untrace()
+	}
+
+	errAppendTooLarge = func(target string, size, objectSize int64) *probe.Error {
+		return probe.NewError(fmt.Errorf("Unable to append to ‘%s’, it is larger than the object, %d instead of %d bytes.", target, size, objectSize)).Untrace()
+	}
+
+	errTooManyFailures = func(tolerated int) *probe.Error {
+		return probe.NewError(fmt.Errorf("More than %d object(s) failed to transfer, aborted. Resume the session to continue.", tolerated)).Untrace()
+	}
+
+	errHiddenObjects = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Objects of ‘" + URL + "’ are never hidden, only files are.")).Untrace()
+	}
+
+	errNotAccelerated = func(bucket string) *probe.Error {
+		return probe.NewError(errors.New("Transfer Acceleration is not available to bucket ‘" + bucket + "’, its name has dots.")).Untrace()
+	}
+
+	errArchiveMemberSkipped = func(name, reason string) *probe.Error {
+		return probe.NewError(errors.New("Skipped ‘" + name + "’, " + reason + ".")).Untrace()
+	}
+
+	errConfigPassphraseMissing = func() *probe.Error {
+		return probe.NewError(errors.New("Config is encrypted, set its passphrase in " + configPassphraseEnv + " or enter it on a terminal.")).Untrace()
+	}
+
+	errConfigPassphraseWrong = func() *probe.Error {
+		return probe.NewError(errors.New("Wrong passphrase, the config can not be decrypted with it.")).Untrace()
+	}
+
+	errConfigEncryption = func(reason string) *probe.Error {
+		return probe.NewError(errors.New("Unable to change encryption of the config, " + reason + ".")).Untrace()
+	}
+
+	errShareNotFound = func(shareURL string) *probe.Error {
+		return probe.NewError(errors.New("Share ‘" + shareURL + "’ is not a previously shared download.")).Untrace()
+	}
+
+	errShareDBVersion = func(filename, version string) *probe.Error {
+		return probe.NewError(errors.New("Share database ‘" + filename + "’ is version ‘" + version + "’, newer than supported. Please upgrade mc.")).Untrace()
+	}
+
+	errReplicationNeedsVersioning = func(bucket string) *probe.Error {
+		return probe.NewError(errors.New("Versioning is not enabled for ‘" + bucket + "’, only versioned buckets are replicated. Use ‘mc versioning enable " + bucket + "’.")).Untrace()
+	}
+
+	errReplicationRuleNotFound = func(bucket, id string) *probe.Error {
+		return probe.NewError(errors.New("Bucket ‘" + bucket + "’ has no replication rule ‘" + id + "’.")).Untrace()
+	}
+
+	errSessionFlagsChanged = func(sid string, flags []string) *probe.Error {
+		return probe.NewError(errors.New("Session ‘" + sid + "’ was saved with different " + strings.Join(flags, ", ") +
+			", resuming with them copies a different set. Use ‘--override-flags’ to resume with them anyway.")).Untrace()
+	}
+
+	errInvalidShortenerURL = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Invalid link shortener URL ‘" + URL + "’, only http and https URLs are supported.")).Untrace()
+	}
+
+	errShortenerFailed = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Unable to shorten share URL with link shortener ‘" + URL + "’.")).Untrace()
+	}
+
+	errInvalidTempDir = func(dir string) *probe.Error {
+		return probe.NewError(errors.New("Temp folder ‘" + dir + "’ is not a folder temporary files can be created in.")).Untrace()
+	}
+
+	errNotClientEncrypted = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("Object ‘" + URL + "’ is not client side encrypted, but a key is configured for it.")).Untrace()
+	}
+
+	errInvalidClientEncryption = func(URL, reason string) *probe.Error {
+		return probe.NewError(errors.New("Unable to decrypt client side encrypted object ‘" + URL + "’, " + reason + ".")).Untrace()
+	}
+
+	errRegionMismatch = func(alias, bucket, region, signingRegion string) *probe.Error {
+		return probe.NewError(errors.New("Bucket ‘" + bucket + "’ is in region ‘" + region + "’, requests of alias ‘" + alias +
+			"’ are signed for region ‘" + signingRegion + "’. Update the alias with ‘mc alias set --region " + region + " " + alias + " ...’.")).Untrace()
+	}
+
+	errDownloadIncomplete = func(URL string, read, size int64) *probe.Error {
+		return probe.NewError(fmt.Errorf("Download of ‘%s’ is incomplete, %d of %d bytes were read.", URL, read, size)).Untrace()
+	}
+
+	errRegionFlagMismatch = func(bucket, region, signingRegion string) *probe.Error {
+		return probe.NewError(errors.New("Bucket ‘" + bucket + "’ is in region ‘" + region + "’, requests are signed for region ‘" +
+			signingRegion + "’ set with ‘--region’.")).Untrace()
+	}
+
+	errAliasTestFailed = func(alias string, failed int) *probe.Error {
+		return probe.NewError(fmt.Errorf("%d of the checks of alias ‘%s’ failed.", failed, alias)).Untrace()
+	}
+
+	errInvalidCopyRange = func(offset, length, size int64) *probe.Error {
+		return probe.NewError(fmt.Errorf("Range of %d bytes at offset %d is not within the %d bytes of the file.", length, offset, size)).Untrace()
+	}
+
+	errHardlinksUnsupported = func() *probe.Error {
+		return probe.NewError(errors.New("Hard links can not be told apart on this platform.")).Untrace()
+	}
+
+	errInsufficientFreeSpace = func(dir string, need int64, free, reserve uint64) *probe.Error {
+		return probe.NewError(fmt.Errorf("Not enough free space on ‘%s’, %s are left to transfer and %s to keep free with --min-free, but only %s are free.",
+			dir, humanize.IBytes(uint64(need)), humanize.IBytes(reserve), humanize.IBytes(free))).Untrace()
+	}
 )
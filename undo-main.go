@@ -0,0 +1,302 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	undoFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of undo.",
+		},
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "Undo changes of all objects under the prefix.",
+		},
+		cli.IntFlag{
+			Name:  "last",
+			Value: 1,
+			Usage: "Number of most recent changes to undo.",
+		},
+	}
+)
+
+// Undo the most recent changes of versioned objects.
+var undoCmd = cli.Command{
+	Name:   "undo",
+	Usage:  "Undo the last overwrite or removal of objects in versioned buckets.",
+	Action: mainUndo,
+	Flags:  append(undoFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET
+
+   Every version and delete marker of an object is a change. Removals are undone by removing
+   their delete markers, overwrites by copying the previous version over the current one, all
+   other versions are kept. With --recursive, the most recent changes of all objects under
+   TARGET are undone, whichever objects they belong to.
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Bring back an object removed by mistake.
+      $ mc {{.Name}} s3/backups/db.tar
+
+   2. Undo the last three overwrites of an object.
+      $ mc {{.Name}} --last 3 s3/backups/db.tar
+
+   3. Undo the last ten changes under a prefix, previewing them first.
+      $ mc --dry-run {{.Name}} --recursive --last 10 s3/backups/2015/
+      $ mc {{.Name}} --recursive --last 10 s3/backups/2015/
+`,
+}
+
+// Operations performed by undo.
+const (
+	undoRestore   = "restore"   // previous version copied over the current one.
+	undoUndelete  = "undelete"  // delete markers removed.
+	undoDelete    = "delete"    // object did not exist before, delete marker placed.
+	undoUnchanged = "unchanged" // object was removed before and still is.
+)
+
+// undoMessage is container for undo command success messages.
+type undoMessage struct {
+	Status    string `json:"status"`
+	Operation string `json:"operation"`
+	URL       string `json:"url"`
+	Changes   int    `json:"changes"`
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// String colorized undo message.
+func (u undoMessage) String() string {
+	changes := fmt.Sprintf("%d change(s)", u.Changes)
+	switch u.Operation {
+	case undoRestore:
+		return console.Colorize("Undo", "Undid "+changes+" of ‘"+u.URL+"’, restored version ‘"+u.VersionID+"’.")
+	case undoUndelete:
+		return console.Colorize("Undo", "Undid "+changes+" of ‘"+u.URL+"’, version ‘"+u.VersionID+"’ is current again.")
+	case undoDelete:
+		return console.Colorize("Undo", "Undid "+changes+" of ‘"+u.URL+"’, it did not exist before and is removed.")
+	case undoUnchanged:
+		return console.Colorize("Undo", "Undid "+changes+" of ‘"+u.URL+"’, it was removed before and still is.")
+	}
+	return ""
+}
+
+// JSON jsonified undo message.
+func (u undoMessage) JSON() string {
+	u.Status = "success"
+	undoJSONBytes, e := json.Marshal(u)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(undoJSONBytes)
+}
+
+// checkUndoSyntax - validate command-line input args.
+func checkUndoSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "undo", 1) // last argument is exit code.
+	}
+	if ctx.Int("last") < 1 {
+		fatalIf(errInvalidArgument().Trace(), "Option --last must be at least 1.")
+	}
+}
+
+// objectVersions - versions of an object, newest first.
+type objectVersions []*client.Content
+
+// listObjectVersions - versions of all objects listed by clnt keyed by path, objects in listing
+// order. Without recursive only versions of the object at the URL of clnt are listed.
+func listObjectVersions(clnt client.Client, recursive bool) (map[string]objectVersions, []string, *probe.Error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	targetPath := clnt.GetURL().Path
+	versions := make(map[string]objectVersions)
+	var objects []string
+	for content := range clnt.ListVersions(recursive, doneCh) {
+		if content.Err != nil {
+			return nil, nil, content.Err.Trace(clnt.GetURL().String())
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		// Listing is by prefix, other objects starting with the name of the target are skipped.
+		if !recursive && content.URL.Path != targetPath {
+			continue
+		}
+		if _, ok := versions[content.URL.Path]; !ok {
+			objects = append(objects, content.URL.Path)
+		}
+		versions[content.URL.Path] = append(versions[content.URL.Path], content)
+	}
+	return versions, objects, nil
+}
+
+// undoChange - a version or delete marker of an object.
+type undoChange struct {
+	object string
+	time   time.Time
+}
+
+// undoChanges sorts changes newest first.
+type undoChanges []undoChange
+
+func (u undoChanges) Len() int           { return len(u) }
+func (u undoChanges) Less(i, j int) bool { return u[i].time.After(u[j].time) }
+func (u undoChanges) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+
+// countUndoChanges - number of changes to undo of each object, to undo the last changes of all objects.
+func countUndoChanges(versions map[string]objectVersions, objects []string, last int) map[string]int {
+	var changes undoChanges
+	for _, object := range objects {
+		for _, version := range versions[object] {
+			changes = append(changes, undoChange{object: object, time: version.Time})
+		}
+	}
+	// Stable, so that versions of an object with equal times stay newest first.
+	sort.Stable(changes)
+	if len(changes) > last {
+		changes = changes[:last]
+	}
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.object]++
+	}
+	return counts
+}
+
+// undoObject - undo the count most recent changes of an object.
+func undoObject(clnt client.Client, versions objectVersions, count int) (undoMessage, *probe.Error) {
+	msg := undoMessage{Changes: count}
+	// Versions removed by the undone changes, only delete markers if it is just removals.
+	undone := versions[:count]
+	onlyDeleteMarkers := true
+	for _, version := range undone {
+		onlyDeleteMarkers = onlyDeleteMarkers && version.IsDeleteMarker
+	}
+	switch {
+	case onlyDeleteMarkers && count < len(versions):
+		for _, version := range undone {
+			if err := clnt.Remove(false, version.VersionID); err != nil {
+				return msg, err.Trace(version.VersionID)
+			}
+		}
+		msg.Operation = undoUndelete
+		msg.VersionID = versions[count].VersionID
+	case count == len(versions) || versions[count].IsDeleteMarker:
+		// Object did not exist before the undone changes.
+		if versions[0].IsDeleteMarker {
+			msg.Operation = undoUnchanged
+			break
+		}
+		if err := clnt.Remove(false, ""); err != nil {
+			return msg, err.Trace()
+		}
+		msg.Operation = undoDelete
+	default:
+		if err := clnt.RestoreVersion(versions[count].VersionID); err != nil {
+			return msg, err.Trace(versions[count].VersionID)
+		}
+		msg.Operation = undoRestore
+		msg.VersionID = versions[count].VersionID
+	}
+	return msg, nil
+}
+
+// doUndo - undo the last changes of targetURL, or of all objects under it if recursive.
+func doUndo(targetURL string, recursive bool, last int) *probe.Error {
+	targetAlias, _ := url2Alias(targetURL)
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	status, err := clnt.GetBucketVersioning()
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	// Suspended buckets keep their versions, they can still be undone.
+	if status == "" {
+		return errBucketNotVersioned(targetURL).Trace(targetURL)
+	}
+
+	versions, objects, err := listObjectVersions(clnt, recursive)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if len(objects) == 0 {
+		return probe.NewError(client.PathNotFound{Path: targetURL}).Trace(targetURL)
+	}
+	counts := countUndoChanges(versions, objects, last)
+	for _, object := range objects {
+		count := counts[object]
+		if count == 0 {
+			continue
+		}
+		objectVersions := versions[object]
+		objectURL := objectVersions[0].URL
+		objectPath := filepath.Join(targetAlias, objectURL.Path)
+		objectClnt, err := newClientFromAlias(targetAlias, objectURL.String())
+		if err != nil {
+			errorIf(err.Trace(objectPath), "Invalid URL ‘"+objectPath+"’.")
+			continue
+		}
+		msg, err := undoObject(objectClnt, objectVersions, count)
+		if err != nil {
+			errorIf(err.Trace(objectPath), "Unable to undo changes of ‘"+objectPath+"’.")
+			continue
+		}
+		if globalDryRun { // Already printed by the dry run client.
+			continue
+		}
+		msg.URL = objectPath
+		printMsg(msg)
+	}
+	return nil
+}
+
+func mainUndo(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'undo' cli arguments.
+	checkUndoSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Undo", color.New(color.FgGreen, color.Bold))
+
+	targetURL := ctx.Args().First()
+	err := doUndo(targetURL, ctx.Bool("recursive"), ctx.Int("last"))
+	fatalIf(err.Trace(targetURL), "Unable to undo changes of ‘"+targetURL+"’.")
+}
@@ -0,0 +1,233 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// cacheControlRule - Cache-Control of uploads to keys matching pattern.
+type cacheControlRule struct {
+	pattern string
+	value   string
+}
+
+// cacheControlMap - rules of ‘--cache-control-map’, most specific first.
+type cacheControlMap []cacheControlRule
+
+// parseCacheControlMap - parse a JSON object mapping glob patterns of keys to the Cache-Control
+// of the objects uploaded to them, such as
+//
+//	{"*.html": "max-age=60", "assets/*.js": "max-age=31536000, immutable", "*": "no-cache"}
+//
+// Patterns without a ‘/’ match the name of the object, such as its extension, in any folder.
+func parseCacheControlMap(data []byte) (cacheControlMap, *probe.Error) {
+	var patterns map[string]string
+	if e := json.Unmarshal(data, &patterns); e != nil {
+		return nil, probe.NewError(e)
+	}
+	var rules cacheControlMap
+	for pattern, value := range patterns {
+		if pattern == "" {
+			return nil, errInvalidMetadata(pattern, "pattern cannot be empty").Trace()
+		}
+		if _, e := path.Match(pattern, ""); e != nil {
+			return nil, errInvalidMetadata(pattern, "invalid pattern").Trace(pattern)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errInvalidMetadata(pattern, "value cannot span lines").Trace(pattern)
+		}
+		rules = append(rules, cacheControlRule{pattern: pattern, value: value})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		si, sj := patternSpecificity(rules[i].pattern), patternSpecificity(rules[j].pattern)
+		if si != sj {
+			return si > sj
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules, nil
+}
+
+// loadCacheControlMap - rules of the file of ‘--cache-control-map’.
+func loadCacheControlMap(filename string) (cacheControlMap, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	rules, err := parseCacheControlMap(data)
+	if err != nil {
+		return nil, err.Trace(filename)
+	}
+	return rules, nil
+}
+
+// match - Cache-Control of the most specific rule matching key, empty if none does.
+func (m cacheControlMap) match(key string) string {
+	for _, rule := range m {
+		name := key
+		if !strings.Contains(rule.pattern, "/") {
+			name = path.Base(key)
+		}
+		if matched, _ := path.Match(rule.pattern, name); matched {
+			return rule.value
+		}
+	}
+	return ""
+}
+
+// uploadAttrs - content type, Cache-Control and user metadata of ‘--attr’, set on every upload.
+type uploadAttrs struct {
+	contentType  string
+	cacheControl string
+	metadata     map[string]string
+}
+
+// parseUploadAttrs - parse ‘KEY=VALUE[;KEY=VALUE...]’. ‘Content-Type’ and ‘Cache-Control’ set
+// the respective header, any other key is user metadata, with or without the ‘X-Amz-Meta-’ prefix.
+func parseUploadAttrs(attrs string) (*uploadAttrs, *probe.Error) {
+	parsed := &uploadAttrs{metadata: make(map[string]string)}
+	for _, attr := range strings.Split(attrs, ";") {
+		if strings.TrimSpace(attr) == "" {
+			continue
+		}
+		i := strings.Index(attr, "=")
+		if i < 0 {
+			return nil, errInvalidMetadata(attr, "expected KEY=VALUE").Trace(attrs)
+		}
+		field, value := attr[:i], attr[i+1:]
+		key := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(field)), "x-amz-meta-")
+		if !isHeaderToken(key) {
+			return nil, errInvalidMetadata(field, "not a valid header name").Trace(attrs)
+		}
+		switch {
+		case key == metaContentType:
+			parsed.contentType = value
+		case key == metaCacheControl:
+			parsed.cacheControl = value
+		case key == "expires" || strings.HasPrefix(key, "content-"):
+			return nil, errInvalidMetadata(field, "header cannot be set on upload, use ‘mc meta set’").Trace(attrs)
+		default:
+			parsed.metadata[key] = value
+		}
+	}
+	return parsed, nil
+}
+
+// applyUploadAttrs - contentType and metadata of an upload to key, with the Cache-Control of
+// ‘--cache-control-map’ and the attributes of ‘--attr’ taking precedence, in that order.
+func applyUploadAttrs(key, contentType string, metadata map[string]string) (string, map[string]string) {
+	cacheControl := ""
+	if globalCacheControlMap != nil {
+		cacheControl = globalCacheControlMap.match(key)
+	}
+	attrs := globalUploadAttrs
+	if cacheControl == "" && attrs == nil {
+		return contentType, metadata
+	}
+	applied := make(map[string]string)
+	for k, v := range metadata {
+		applied[k] = v
+	}
+	if attrs != nil {
+		for k, v := range attrs.metadata {
+			applied[k] = v
+		}
+		if attrs.contentType != "" {
+			contentType = attrs.contentType
+		}
+		if attrs.cacheControl != "" {
+			cacheControl = attrs.cacheControl
+		}
+	}
+	if cacheControl != "" {
+		applied[client.MetadataCacheControl] = cacheControl
+	}
+	return contentType, applied
+}
+
+// getSessionUploadAttrs - Cache-Control map and attributes of ‘--cache-control-map’ and ‘--attr’
+// saved in session, loaded once for all objects of the session.
+func getSessionUploadAttrs(session *sessionV6) (cacheControlMap, *uploadAttrs) {
+	return loadUploadAttrs(session.Header.CommandStringFlags["cache-control-map"], session.Header.CommandStringFlags["attr"])
+}
+
+// loadUploadAttrs - Cache-Control map of filename and attributes of attrStr, nil if not given.
+func loadUploadAttrs(filename, attrStr string) (cacheControlMap, *uploadAttrs) {
+	var rules cacheControlMap
+	if filename != "" {
+		var err *probe.Error
+		rules, err = loadCacheControlMap(filename)
+		fatalIf(err.Trace(filename), "Unable to load Cache-Control map ‘"+filename+"’.")
+	}
+	var attrs *uploadAttrs
+	if attrStr != "" {
+		var err *probe.Error
+		attrs, err = parseUploadAttrs(attrStr)
+		fatalIf(err.Trace(attrStr), "Invalid attributes ‘"+attrStr+"’.")
+	}
+	return rules, attrs
+}
+
+// setSessionUploadAttrs - save ‘--cache-control-map’ and ‘--attr’ in session, the map by its absolute path.
+func setSessionUploadAttrs(session *sessionV6, ctx *cli.Context) {
+	if cacheControlFile := ctx.String("cache-control-map"); cacheControlFile != "" {
+		cacheControlPath, e := filepath.Abs(cacheControlFile)
+		fatalIf(probe.NewError(e), "Unable to locate Cache-Control map ‘"+cacheControlFile+"’.")
+		session.Header.CommandStringFlags["cache-control-map"] = cacheControlPath
+	}
+	if attrs := ctx.String("attr"); attrs != "" {
+		session.Header.CommandStringFlags["attr"] = attrs
+	}
+}
+
+// checkUploadAttrsSyntax - validate ‘--cache-control-map’ and ‘--attr’ of cp and mirror.
+func checkUploadAttrsSyntax(cacheControlFile, attrs string) {
+	if cacheControlFile != "" {
+		_, err := loadCacheControlMap(cacheControlFile)
+		fatalIf(err.Trace(cacheControlFile), "Invalid Cache-Control map ‘"+cacheControlFile+"’.")
+	}
+	if attrs != "" {
+		_, err := parseUploadAttrs(attrs)
+		fatalIf(err.Trace(attrs), "Invalid attributes ‘"+attrs+"’.")
+	}
+}
+
+// checkMetadataCharsetSyntax - ‘--metadata-charset’ is a known encoding, without one the metadata
+// of ‘--attr’ has to be printable ASCII.
+func checkMetadataCharsetSyntax(ctx *cli.Context) {
+	charset := ctx.String("metadata-charset")
+	if !client.IsValidMetadataCharset(charset) {
+		fatalIf(errInvalidArgument().Trace(charset), "Metadata charset ‘"+charset+"’ is invalid, must be one of ‘"+
+			client.MetadataCharsetRFC2047+"’, ‘"+client.MetadataCharsetPercent+"’, ‘"+client.MetadataCharsetNone+"’.")
+	}
+	if attrs := ctx.String("attr"); attrs != "" {
+		if parsed, err := parseUploadAttrs(attrs); err == nil {
+			_, err = client.EncodeMetadata(parsed.metadata, charset)
+			fatalIf(err.Trace(attrs), "Invalid attributes ‘"+attrs+"’.")
+		}
+	}
+}
@@ -17,13 +17,15 @@
 package main
 
 import (
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/minio-xl/pkg/probe"
-	"github.com/minio/minio/pkg/contentdb"
 )
 
 func isURLVirtualHostStyle(hostURL string) bool {
@@ -32,6 +34,34 @@ func isURLVirtualHostStyle(hostURL string) bool {
 	return matchS3 || matchGoogle
 }
 
+// isServiceRoot - URL is an object storage host, without a bucket.
+func isServiceRoot(urlStr string) bool {
+	_, urlStr, _ = mustExpandAlias(urlStr)
+	u := client.NewURL(urlStr)
+	if u.Type != client.Object || isURLVirtualHostStyle(u.Host) {
+		return false
+	}
+	return strings.Trim(u.Path, "/") == ""
+}
+
+// isBucketRoot - URL is a bucket of an object storage host, without an object or prefix.
+func isBucketRoot(urlStr string) bool {
+	_, urlStr, hostCfg := mustExpandAlias(urlStr)
+	u := client.NewURL(urlStr)
+	if u.Type != client.Object {
+		return false
+	}
+	if isURLVirtualHostStyle(u.Host) {
+		return strings.Trim(u.Path, "/") == ""
+	}
+	urlPath := u.Path
+	if hostCfg != nil {
+		urlPath = strings.TrimPrefix(urlPath, strings.TrimSuffix(client.NewURL(hostCfg.URL).Path, "/"))
+	}
+	bucket := strings.Trim(urlPath, "/")
+	return bucket != "" && !strings.Contains(bucket, "/")
+}
+
 // urlJoinPath Join a path to existing URL.
 func urlJoinPath(url1, url2 string) string {
 	u1 := client.NewURL(url1)
@@ -39,6 +69,90 @@ func urlJoinPath(url1, url2 string) string {
 	return client.JoinURLs(u1, u2).String()
 }
 
+// urlJoinSourcePath joins relPath, a path relative to source in its own
+// separators, onto targetURL. Keys for object storage targets always use '/',
+// filesystem targets get the native separator, object keys are never altered
+// except when downloaded, see safeLocalPath.
+func urlJoinSourcePath(targetURL string, source client.URL, relPath string) string {
+	relURL := source
+	relURL.Path = relPath
+	target := client.NewURL(targetURL)
+	if source.Type == client.Object && target.Type == client.Filesystem {
+		relURL.Path = safeLocalPath(relPath)
+	}
+	return client.JoinURLs(target, &relURL).String()
+}
+
+// safeLocalPath - relPath of an object key made safe to create below a local folder. Empty, ‘.’
+// and ‘..’ elements are dropped, so that hostile keys never lead outside of the folder, control
+// characters and those reserved on Windows are replaced by ‘_’.
+func safeLocalPath(relPath string) string {
+	var elems []string
+	for _, elem := range strings.FieldsFunc(relPath, isLocalSeparator) {
+		if elem == "." || elem == ".." {
+			continue
+		}
+		elems = append(elems, strings.Map(safeLocalRune, elem))
+	}
+	if len(elems) == 0 {
+		return "_"
+	}
+	return strings.Join(elems, "/")
+}
+
+// isLocalSeparator - reports if r separates the elements of a local path.
+func isLocalSeparator(r rune) bool {
+	return r == '/' || r == filepath.Separator
+}
+
+// safeLocalRune - r, or ‘_’ if r cannot be part of a local file name.
+func safeLocalRune(r rune) rune {
+	if r < 0x20 || r == 0x7f {
+		return '_'
+	}
+	if runtime.GOOS == "windows" && strings.ContainsRune(`<>:"|?*`, r) {
+		return '_'
+	}
+	return r
+}
+
+// downloadName - name of content copied into a folder, the last element of its key, unless the
+// key ends in ‘/’ and the object suggests a file name with its Content-Disposition.
+func downloadName(content *client.Content) string {
+	if content.URL.Type == client.Object && strings.HasSuffix(content.URL.Path, "/") {
+		if name := dispositionFilename(content.ContentDisposition); name != "" {
+			return name
+		}
+	}
+	return urlBase(content.URL)
+}
+
+// dispositionFilename - file name of a Content-Disposition without any folders, empty if none.
+func dispositionFilename(disposition string) string {
+	if disposition == "" {
+		return ""
+	}
+	_, params, e := mime.ParseMediaType(disposition)
+	if e != nil {
+		return ""
+	}
+	name := path.Base(strings.Replace(params["filename"], `\`, "/", -1))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// urlBase returns the last element of the URL path. Object keys are split
+// only at '/'.
+func urlBase(u client.URL) string {
+	urlPath := u.Path
+	if u.Type == client.Filesystem {
+		urlPath = strings.Replace(urlPath, string(u.Separator), "/", -1)
+	}
+	return path.Base(urlPath)
+}
+
 // url2Stat returns stat info for URL.
 func url2Stat(urlStr string) (client client.Client, content *client.Content, err *probe.Error) {
 	client, err = newClient(urlStr)
@@ -76,7 +190,10 @@ func isURLPrefixExists(urlPrefix string, incomplete bool) bool {
 	}
 	isRecursive := true
 	isIncomplete := incomplete
-	for entry := range clnt.List(isRecursive, isIncomplete) {
+	// Only the first entry is needed, stop listing upon return.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for entry := range clnt.List(isRecursive, isIncomplete, doneCh) {
 		if entry.Err != nil {
 			return false
 		}
@@ -89,10 +206,8 @@ func isURLPrefixExists(urlPrefix string, incomplete bool) bool {
 // on failure just return 'application/octet-stream'.
 func guessURLContentType(urlStr string) string {
 	url := client.NewURL(urlStr)
-	extension := strings.TrimPrefix(filepath.Ext(url.Path), ".")
-	contentType, e := contentdb.Lookup(extension)
-	if e != nil {
-		return "application/octet-stream"
+	if contentType := lookupContentType(filepath.Base(url.Path)); contentType != "" {
+		return contentType
 	}
-	return contentType
+	return "application/octet-stream"
 }
@@ -16,7 +16,15 @@
 
 package main
 
-import . "gopkg.in/check.v1"
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
 
 func (s *TestSuite) TestURLJoinPath(c *C) {
 	// Join two URLs
@@ -37,3 +45,52 @@ func (s *TestSuite) TestURLJoinPath(c *C) {
 	url = urlJoinPath(url1, url2)
 	c.Assert(url, Equals, "http://s3.mycompany.io/dev/mybucket/bin/")
 }
+
+func (s *TestSuite) TestURLJoinSourcePath(c *C) {
+	// Windows source, relative path as cut from the listing.
+	source := client.URL{Type: client.Filesystem, Path: `C:\photos\2015\Trip\IMG 01.JPG`, Separator: '\\'}
+	c.Assert(urlBase(source), Equals, "IMG 01.JPG")
+	url := urlJoinSourcePath("https://s3.mycompany.io/photos/", source, `2015\Trip\IMG 01.JPG`)
+	c.Assert(url, Equals, "https://s3.mycompany.io/photos/2015/Trip/IMG 01.JPG")
+	c.Assert(strings.Contains(url, `\`), Equals, false)
+
+	// Object keys are never split or translated at a backslash.
+	source = client.URL{Type: client.Object, Scheme: "https", Host: "s3.aws.amazon.com", Path: `/bucket/dir/a\b`, Separator: '/'}
+	c.Assert(urlBase(source), Equals, `a\b`)
+	url = urlJoinSourcePath("https://s3.mycompany.io/backup", source, `dir/a\b`)
+	c.Assert(url, Equals, `https://s3.mycompany.io/backup/dir/a\b`)
+}
+
+func (s *TestSuite) TestURLJoinSourcePathDownload(c *C) {
+	root, e := ioutil.TempDir("", "mc-download-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	target := root + string(filepath.Separator)
+
+	// Hostile keys never lead outside of the target folder.
+	for key, name := range map[string]string{
+		"../../etc/passwd":  "etc/passwd",
+		"a/../../b":         "a/b",
+		"/./x//y":           "x/y",
+		"../":               "_",
+		"a/b/":              "a/b",
+		"new\nline\x00.txt": "new_line_.txt",
+	} {
+		source := client.URL{Type: client.Object, Scheme: "https", Host: "s3.amazonaws.com", Path: "/bucket/" + key, Separator: '/'}
+		url := urlJoinSourcePath(target, source, key)
+		c.Assert(url, Equals, filepath.Join(root, filepath.FromSlash(name)))
+		c.Assert(strings.HasPrefix(url, target), Equals, true)
+	}
+
+	// Keys ending in ‘/’ are named after their Content-Disposition, if any.
+	content := &client.Content{URL: client.URL{Type: client.Object, Path: "/bucket/reports/2015/", Separator: '/'}}
+	c.Assert(downloadName(content), Equals, "2015")
+	content.ContentDisposition = `attachment; filename="../../summary.pdf"`
+	c.Assert(downloadName(content), Equals, "summary.pdf")
+	cpURLs := makeCopyContentTypeB("s3", content, "", target)
+	c.Assert(cpURLs.TargetContent.URL.String(), Equals, filepath.Join(root, "summary.pdf"))
+
+	// Object storage targets keep keys as they are.
+	source := client.URL{Type: client.Object, Scheme: "https", Host: "s3.amazonaws.com", Path: "/bucket/a/../b", Separator: '/'}
+	c.Assert(urlJoinSourcePath("https://s3.mycompany.io/backup/", source, "a/../b"), Equals, "https://s3.mycompany.io/backup/a/../b")
+}
@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// md5ETag - ETag of an object uploaded with a single PUT, the MD5 of its data. Multipart
+// ETags are the MD5 of the part MD5s followed by ‘-’ and the number of parts.
+var md5ETag = regexp.MustCompile("^[0-9a-fA-F]{32}$")
+
+// downloadChecksum - algorithm and expected checksum to verify a download of source with,
+// empty if there is none. The ETag of an SSE-C encrypted object is not the MD5 of its data.
+func downloadChecksum(source *client.Content, encrypted bool) (algorithm, expected string) {
+	if !encrypted && md5ETag.MatchString(source.ETag) {
+		return "md5", strings.ToLower(source.ETag)
+	}
+	if sum := source.Metadata["sha256"]; sum != "" {
+		return "sha256", strings.ToLower(sum)
+	}
+	return "", ""
+}
+
+// fileChecksum - hex checksum of the file at path.
+func fileChecksum(path, algorithm string) (string, *probe.Error) {
+	var hasher hash.Hash
+	switch algorithm {
+	case "md5":
+		hasher = md5.New()
+	case "sha256":
+		hasher = sha256.New()
+	default:
+		return "", errInvalidArgument().Trace(algorithm)
+	}
+	file, e := os.Open(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	defer file.Close()
+	if _, e = io.Copy(hasher, file); e != nil {
+		return "", probe.NewError(e)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// verifyDownload - compare the file downloaded to target with the object of sourceClnt.
+// A corrupt file is removed so that a retry downloads it again from the start. Objects
+// with a multipart ETag and no sha256 metadata can not be verified and are accepted.
+func verifyDownload(sourceAlias string, sourceClnt client.Client, target string) *probe.Error {
+	sourceURL := sourceClnt.GetURL().String()
+	source, err := sourceClnt.Stat()
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	encrypted := false
+	if hostCfg := mustGetHostConfig(sourceAlias); hostCfg != nil {
+		encryptionKey, err := getURLEncryptionKey(sourceAlias, *hostCfg, sourceURL)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		encrypted = encryptionKey != nil
+	}
+	algorithm, expected := downloadChecksum(source, encrypted)
+	if algorithm == "" {
+		return nil
+	}
+	actual, err := fileChecksum(target, algorithm)
+	if err != nil {
+		return err.Trace(target)
+	}
+	if actual == expected {
+		return nil
+	}
+	if e := os.Remove(target); e != nil {
+		return probe.NewError(e).Trace(target)
+	}
+	return probe.NewError(client.ChecksumMismatch{URL: sourceURL, Algorithm: algorithm, Expected: expected, Actual: actual})
+}
+
+// verifyCopy - compare the object of targetClnt, copied server side, with that of sourceClnt. Sizes
+// always have to match, ETags only if both are the MD5 of the data. Multipart ETags depend on the
+// part sizes, a copy made in other parts, or in one, has another ETag for the same data.
+func verifyCopy(sourceClnt, targetClnt client.Client) *probe.Error {
+	targetURL := targetClnt.GetURL().String()
+	source, err := sourceClnt.Stat()
+	if err != nil {
+		return err.Trace(sourceClnt.GetURL().String())
+	}
+	target, err := targetClnt.Stat()
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if source.Size != target.Size {
+		return probe.NewError(client.ChecksumMismatch{URL: targetURL, Algorithm: "size",
+			Expected: strconv.FormatInt(source.Size, 10), Actual: strconv.FormatInt(target.Size, 10)})
+	}
+	if md5ETag.MatchString(source.ETag) && md5ETag.MatchString(target.ETag) && !strings.EqualFold(source.ETag, target.ETag) {
+		return probe.NewError(client.ChecksumMismatch{URL: targetURL, Algorithm: "etag",
+			Expected: strings.ToLower(source.ETag), Actual: strings.ToLower(target.ETag)})
+	}
+	return nil
+}
+
+// copyVerified - server side copy with copyFn from sourceClnt to targetClnt, verified by verifyCopy.
+// A copy which does not match is made once more before it fails.
+func copyVerified(sourceClnt, targetClnt client.Client, copyFn func() *probe.Error) *probe.Error {
+	for attempt := 1; ; attempt++ {
+		if err := copyFn(); err != nil {
+			return err.Trace()
+		}
+		err := verifyCopy(sourceClnt, targetClnt)
+		if err == nil || attempt >= 2 {
+			return err.Trace()
+		}
+	}
+}
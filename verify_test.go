@@ -0,0 +1,299 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/mc/pkg/client/mock"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// verifyHandler serves a single object with a sha256 in its metadata, the first
+// corrupt GETs return the object with its first byte flipped.
+type verifyHandler struct {
+	rangeHandler
+	sha256  string
+	corrupt int
+}
+
+func (h *verifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.sha256 != "" {
+		w.Header().Set("x-amz-meta-sha256", h.sha256)
+	}
+	h.mutex.Lock()
+	corrupt := r.Method == "GET" && h.corrupt > 0
+	if corrupt {
+		h.corrupt--
+		h.ranges = append(h.ranges, r.Header.Get("Range"))
+	}
+	h.mutex.Unlock()
+	if !corrupt {
+		h.rangeHandler.ServeHTTP(w, r)
+		return
+	}
+	data := append([]byte{}, h.data...)
+	data[0] ^= 0xff
+	w.Header().Set("ETag", "\""+h.etag+"\"")
+	http.ServeContent(w, r, "object", time.Unix(1445000000, 0), bytes.NewReader(data))
+}
+
+func (s *TestSuite) TestVerifyDownload(c *C) {
+	defer useTempMcConfig(c)()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	md5Sum := md5.Sum(data)
+	sha256Sum := sha256.Sum256(data)
+	corrupt := append([]byte{}, data...)
+	corrupt[0] ^= 0xff
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-verify-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	target := filepath.Join(root, "object")
+
+	testCases := []struct {
+		etag      string
+		sha256    string
+		local     []byte
+		algorithm string // empty if verified.
+	}{
+		// Simple ETag is the MD5 of the object.
+		{hex.EncodeToString(md5Sum[:]), "", data, ""},
+		{hex.EncodeToString(md5Sum[:]), "", corrupt, "md5"},
+		// Multipart ETag is not, without a sha256 the download is not verified.
+		{"0123456789abcdef0123456789abcdef-3", "", corrupt, ""},
+		{"0123456789abcdef0123456789abcdef-3", hex.EncodeToString(sha256Sum[:]), data, ""},
+		{"0123456789abcdef0123456789abcdef-3", hex.EncodeToString(sha256Sum[:]), corrupt, "sha256"},
+	}
+	for i, testCase := range testCases {
+		handler := &verifyHandler{rangeHandler: rangeHandler{data: data, etag: testCase.etag}, sha256: testCase.sha256}
+		server := httptest.NewServer(handler)
+		conf := new(client.Config)
+		conf.HostURL = server.URL + "/bucket/object"
+		sourceClnt, err := s3.New(conf)
+		c.Assert(err, IsNil)
+		c.Assert(ioutil.WriteFile(target, testCase.local, 0600), IsNil)
+
+		err = verifyDownload("", sourceClnt, target)
+		server.Close()
+		if testCase.algorithm == "" {
+			c.Assert(err, IsNil, Commentf("Test %d", i+1))
+			_, e = os.Stat(target)
+			c.Assert(e, IsNil, Commentf("Test %d", i+1))
+			continue
+		}
+		c.Assert(err, Not(IsNil), Commentf("Test %d", i+1))
+		mismatch, ok := err.ToGoError().(client.ChecksumMismatch)
+		c.Assert(ok, Equals, true, Commentf("Test %d", i+1))
+		c.Assert(mismatch.Algorithm, Equals, testCase.algorithm, Commentf("Test %d", i+1))
+		c.Assert(isRetryable(err), Equals, true)
+		// Corrupt file is removed.
+		_, e = os.Stat(target)
+		c.Assert(os.IsNotExist(e), Equals, true, Commentf("Test %d", i+1))
+	}
+}
+
+func (s *TestSuite) TestCopyVerifyRetried(c *C) {
+	defer useTempMcConfig(c)()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	md5Sum := md5.Sum(data)
+	handler := &verifyHandler{rangeHandler: rangeHandler{data: data, etag: hex.EncodeToString(md5Sum[:])}, corrupt: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c.Assert(setAlias("verify", hostConfigV7{URL: server.URL, API: "S3v4"}), IsNil)
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-verify-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	target := filepath.Join(root, "object")
+
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+
+	session := newTestSession()
+	session.Header.CommandBoolFlags = map[string]bool{"verify": true}
+	cpURLs := copyURLs{
+		SourceAlias:   "verify",
+		SourceContent: &client.Content{URL: *client.NewURL(server.URL + "/bucket/object"), Size: int64(len(data))},
+		TargetContent: &client.Content{URL: *client.NewURL(target)},
+	}
+	cpQueue := make(chan bool, 1)
+	cpQueue <- true
+	statusCh := make(chan copyURLs, 1)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	doCopy(context.Background(), cpURLs, session, nil, nil, nil, cpQueue, wg, statusCh)
+
+	// Corrupt download is discarded and downloaded again from the start.
+	status := <-statusCh
+	c.Assert(status.Error, IsNil)
+	c.Assert(handler.ranges, DeepEquals, []string{"", ""})
+	downloaded, e := ioutil.ReadFile(target)
+	c.Assert(e, IsNil)
+	c.Assert(bytes.Equal(downloaded, data), Equals, true)
+}
+
+// multipartStatClient - client whose objects carry a multipart ETag.
+type multipartStatClient struct {
+	client.Client
+}
+
+func (m multipartStatClient) Stat() (*client.Content, *probe.Error) {
+	content, err := m.Client.Stat()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	content.ETag = "d41d8cd98f00b204e9800998ecf8427e-3"
+	return content, nil
+}
+
+func (s *TestSuite) TestVerifyCopy(c *C) {
+	store := mock.NewStore(map[string][]byte{
+		"bucket/source": []byte("hello world"),
+		"bucket/same":   []byte("hello world"),
+		"bucket/other":  []byte("HELLO WORLD"),
+		"bucket/short":  []byte("hello"),
+	})
+	newClnt := func(object string) client.Client {
+		clnt, err := store.New("https://mock.example/bucket/" + object)
+		c.Assert(err, IsNil)
+		return clnt
+	}
+	source := newClnt("source")
+
+	// Matching MD5 ETags.
+	c.Assert(verifyCopy(source, newClnt("same")), IsNil)
+	// Same size, other data.
+	err := verifyCopy(source, newClnt("other"))
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError().(client.ChecksumMismatch).Algorithm, Equals, "etag")
+	// Other size.
+	err = verifyCopy(source, newClnt("short"))
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError().(client.ChecksumMismatch).Algorithm, Equals, "size")
+	// Multipart ETags fall back to comparing sizes.
+	c.Assert(verifyCopy(multipartStatClient{source}, newClnt("other")), IsNil)
+	c.Assert(verifyCopy(multipartStatClient{source}, newClnt("short")), NotNil)
+
+	// A mismatch is copied again once, then fails.
+	target := newClnt("target")
+	copies := 0
+	corruptFirst := func() *probe.Error {
+		copies++
+		if copies == 1 {
+			return target.Put(bytes.NewReader([]byte("garbage")), 7, "", nil)
+		}
+		return target.Copy(source.GetURL(), client.CopyOptions{})
+	}
+	c.Assert(copyVerified(source, target, corruptFirst), IsNil)
+	c.Assert(copies, Equals, 2)
+	data, _ := store.Object("bucket/target")
+	c.Assert(string(data), Equals, "hello world")
+
+	copies = 0
+	alwaysCorrupt := func() *probe.Error {
+		copies++
+		return target.Put(bytes.NewReader([]byte("garbage")), 7, "", nil)
+	}
+	c.Assert(copyVerified(source, target, alwaysCorrupt), NotNil)
+	c.Assert(copies, Equals, 2)
+}
+
+func (s *TestSuite) TestCopyStoreSHA256(c *C) {
+	defer useTempMcConfig(c)()
+	// Above the part size, uploaded in parts its ETag is not the MD5 of the data.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 6*1024*1024/16)
+	sha256Sum := sha256.Sum256(data)
+	expected := hex.EncodeToString(sha256Sum[:])
+
+	root, e := ioutil.TempDir(os.TempDir(), "mc-sha256-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	c.Assert(ioutil.WriteFile(source, data, 0600), IsNil)
+	sourceClnt, err := fs.New(source)
+	c.Assert(err, IsNil)
+
+	store := mock.NewStore(map[string][]byte{"archive": nil})
+	targetClnt, err := store.New("https://mock.example/archive/source")
+	c.Assert(err, IsNil)
+
+	session := newTestSession()
+	session.Header.CommandBoolFlags = map[string]bool{"store-sha256": true}
+	cpURLs := copyURLs{
+		SourceContent: &client.Content{URL: *client.NewURL(source), Size: int64(len(data))},
+		TargetContent: &client.Content{URL: targetClnt.GetURL()},
+	}
+	_, _, err = copyObject(cpURLs, session, sourceClnt, targetClnt, "", map[string]string{"owner": "archive"}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	content, err := targetClnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Metadata, DeepEquals, map[string]string{"owner": "archive", "sha256": expected})
+
+	// Downloads are verified against it.
+	target := filepath.Join(root, "target")
+	c.Assert(ioutil.WriteFile(target, data, 0600), IsNil)
+	c.Assert(verifyDownload("", multipartStatClient{targetClnt}, target), IsNil)
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	c.Assert(ioutil.WriteFile(target, corrupt, 0600), IsNil)
+	err = verifyDownload("", multipartStatClient{targetClnt}, target)
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError().(client.ChecksumMismatch).Algorithm, Equals, "sha256")
+
+	// Data read again after a retry is hashed once, skipped data is read from the source again.
+	hashReader := newSHA256Reader(bytes.NewReader(data))
+	_, e = io.CopyN(ioutil.Discard, hashReader, 1024)
+	c.Assert(e, IsNil)
+	_, e = hashReader.Seek(0, 0)
+	c.Assert(e, IsNil)
+	_, e = io.Copy(ioutil.Discard, hashReader)
+	c.Assert(e, IsNil)
+	sum, ok := hashReader.Sum(int64(len(data)))
+	c.Assert(ok, Equals, true)
+	c.Assert(sum, Equals, expected)
+
+	hashReader = newSHA256Reader(bytes.NewReader(data))
+	_, e = hashReader.Seek(1024, 0)
+	c.Assert(e, IsNil)
+	_, e = io.Copy(ioutil.Discard, hashReader)
+	c.Assert(e, IsNil)
+	_, ok = hashReader.Sum(int64(len(data)))
+	c.Assert(ok, Equals, false)
+	c.Assert(targetClnt.SetMetadata("", "", nil), IsNil)
+	c.Assert(storeSHA256(sourceClnt, targetClnt, hashReader, int64(len(data))), IsNil)
+	content, err = targetClnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Metadata["sha256"], Equals, expected)
+}
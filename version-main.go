@@ -19,6 +19,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"runtime"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
@@ -50,6 +52,13 @@ USAGE:
 FLAGS:
   {{range .Flags}}{{.}}
   {{end}}
+EXAMPLES:
+   1. Print version, Go runtime and supported session/share db formats.
+      $ mc {{.Name}}
+
+   2. Print the same information as JSON for tooling.
+      $ mc --json {{.Name}}
+      $ mc --json --version
 `,
 }
 
@@ -62,13 +71,45 @@ type versionMessage struct {
 	} `json:"version"`
 	ReleaseTag string `json:"releaseTag"`
 	CommitID   string `json:"commitID"`
+	GoVersion  string `json:"goVersion"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Formats    struct {
+		Config  string `json:"config"`
+		Session string `json:"session"`
+		ShareDB string `json:"shareDB"`
+	} `json:"formats"`
+}
+
+// newVersionMessage - version of this binary and the on-disk formats it supports.
+func newVersionMessage() versionMessage {
+	verMsg := versionMessage{}
+	verMsg.CommitID = mcCommitID
+	verMsg.ReleaseTag = mcReleaseTag
+	verMsg.Version.Value = mcVersion
+	verMsg.Version.Format = "RFC3339"
+	verMsg.GoVersion = runtime.Version()
+	verMsg.OS = runtime.GOOS
+	verMsg.Arch = runtime.GOARCH
+	verMsg.Formats.Config = globalMCConfigVersion
+	verMsg.Formats.Session = globalSessionVersion
+	verMsg.Formats.ShareDB = globalShareDBVersion
+	return verMsg
+}
+
+// mcAppComments - comments sent in the User-Agent next to mc/mcVersion.
+func mcAppComments() []string {
+	return []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
 }
 
 // Colorized message for console printing.
 func (v versionMessage) String() string {
 	return console.Colorize("Version", fmt.Sprintf("Version: %s\n", v.Version.Value)) +
 		console.Colorize("ReleaseTag", fmt.Sprintf("Release-tag: %s\n", v.ReleaseTag)) +
-		console.Colorize("CommitID", fmt.Sprintf("Commit-id: %s", v.CommitID))
+		console.Colorize("CommitID", fmt.Sprintf("Commit-id: %s\n", v.CommitID)) +
+		console.Colorize("Runtime", fmt.Sprintf("Runtime: %s %s/%s\n", v.GoVersion, v.OS, v.Arch)) +
+		console.Colorize("Formats", fmt.Sprintf("Formats: config v%s, session v%s, share db v%s",
+			v.Formats.Config, v.Formats.Session, v.Formats.ShareDB))
 }
 
 // JSON'ified message for scripting.
@@ -87,12 +128,8 @@ func mainVersion(ctx *cli.Context) {
 	console.SetColor("Version", color.New(color.FgGreen, color.Bold))
 	console.SetColor("ReleaseTag", color.New(color.FgGreen))
 	console.SetColor("CommitID", color.New(color.FgGreen))
+	console.SetColor("Runtime", color.New(color.FgGreen))
+	console.SetColor("Formats", color.New(color.FgGreen))
 
-	verMsg := versionMessage{}
-	verMsg.CommitID = mcCommitID
-	verMsg.ReleaseTag = mcReleaseTag
-	verMsg.Version.Value = mcVersion
-	verMsg.Version.Format = "RFC3339"
-
-	printMsg(verMsg)
+	printMsg(newVersionMessage())
 }
@@ -17,7 +17,9 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
+	"runtime"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -27,3 +29,24 @@ func (s *TestSuite) TestVersion(c *C) {
 	_, err := time.Parse(mcVersion, http.TimeFormat)
 	c.Assert(err, NotNil)
 }
+
+func (s *TestSuite) TestVersionJSON(c *C) {
+	var msg map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(newVersionMessage().JSON()), &msg), IsNil)
+	for _, key := range []string{"status", "version", "releaseTag", "commitID", "goVersion", "os", "arch", "formats"} {
+		_, ok := msg[key]
+		c.Assert(ok, Equals, true, Commentf("missing key %s", key))
+	}
+	c.Assert(msg["goVersion"], Equals, runtime.Version())
+	c.Assert(msg["version"].(map[string]interface{})["value"], Equals, mcVersion)
+
+	// Reported formats are the ones actually written.
+	formats := msg["formats"].(map[string]interface{})
+	c.Assert(formats["config"], Equals, newConfigV7().Version)
+	c.Assert(formats["session"], Equals, newSessionV6().Header.Version)
+	c.Assert(formats["shareDB"], Equals, newShareDBV2().Version)
+
+	// The User-Agent carries the same platform.
+	comments := mcAppComments()
+	c.Assert(comments[len(comments)-2:], DeepEquals, []string{msg["os"].(string), msg["arch"].(string)})
+}
@@ -0,0 +1,163 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+var (
+	versioningFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help of versioning.",
+		},
+	}
+)
+
+// Manage bucket versioning.
+var versioningCmd = cli.Command{
+	Name:   "versioning",
+	Usage:  "Manage bucket versioning.",
+	Action: mainVersioning,
+	Flags:  append(versioningFlags, globalFlags...),
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] enable TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] suspend TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] info TARGET [TARGET...]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Enable versioning on a bucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} enable s3/backups
+
+   2. Suspend versioning on a bucket, existing versions are kept.
+      $ mc {{.Name}} suspend s3/backups
+
+   3. Display versioning state of a bucket.
+      $ mc {{.Name}} info s3/backups
+`,
+}
+
+// versioningMessage is container for versioning command on bucket success messages.
+type versioningMessage struct {
+	Operation string `json:"operation"`
+	Status    string `json:"status"`
+	Bucket    string `json:"bucket"`
+	State     string `json:"versioning"`
+}
+
+// String colorized versioning message.
+func (v versioningMessage) String() string {
+	switch v.Operation {
+	case "enable":
+		return console.Colorize("Versioning", "Versioning enabled for ‘"+v.Bucket+"’.")
+	case "suspend":
+		return console.Colorize("Versioning", "Versioning suspended for ‘"+v.Bucket+"’.")
+	}
+	state := v.State
+	if state == "" {
+		state = "Unversioned"
+	}
+	return console.Colorize("Versioning", "Versioning for ‘"+v.Bucket+"’ is ‘"+state+"’.")
+}
+
+// JSON jsonified versioning message.
+func (v versioningMessage) JSON() string {
+	versioningJSONBytes, e := json.Marshal(v)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(versioningJSONBytes)
+}
+
+// checkVersioningSyntax check for incoming syntax.
+func checkVersioningSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "versioning", 1) // last argument is exit code.
+	}
+	switch ctx.Args().First() {
+	case "enable", "suspend", "info":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "versioning", 1) // last argument is exit code.
+	}
+}
+
+// doSetVersioning enable or suspend versioning.
+func doSetVersioning(targetURL string, enable bool) *probe.Error {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	return clnt.SetBucketVersioning(enable).Trace(targetURL)
+}
+
+// doGetVersioning get versioning state.
+func doGetVersioning(targetURL string) (string, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	state, err := clnt.GetBucketVersioning()
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	return state, nil
+}
+
+func mainVersioning(ctx *cli.Context) {
+	// Set global flags from context.
+	setGlobalsFromContext(ctx)
+
+	// check 'versioning' cli arguments.
+	checkVersioningSyntax(ctx)
+
+	// Additional command speific theme customization.
+	console.SetColor("Versioning", color.New(color.FgGreen, color.Bold))
+
+	operation := ctx.Args().First()
+	for _, targetURL := range ctx.Args().Tail() {
+		msg := versioningMessage{Status: "success", Operation: operation, Bucket: targetURL}
+		switch operation {
+		case "enable", "suspend":
+			if err := doSetVersioning(targetURL, operation == "enable"); err != nil {
+				errorIf(err.Trace(targetURL), "Unable to "+operation+" versioning for ‘"+targetURL+"’.")
+				continue
+			}
+			if globalDryRun { // Already printed by the dry run client.
+				continue
+			}
+		case "info":
+			state, err := doGetVersioning(targetURL)
+			if err != nil {
+				errorIf(err.Trace(targetURL), "Unable to get versioning for ‘"+targetURL+"’.")
+				continue
+			}
+			msg.State = state
+		}
+		printMsg(msg)
+	}
+}